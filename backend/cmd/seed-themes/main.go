@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
 	"os"
 
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/omninudge/backend/internal/config"
 	"github.com/omninudge/backend/internal/database"
 	"github.com/omninudge/backend/internal/models"
@@ -26,23 +28,38 @@ func main() {
 	}
 	defer db.Close()
 
-	ctx := context.Background()
-	themeRepo := models.NewUserThemeRepository(db.Pool)
+	if err := seedPredefinedThemes(context.Background(), db.Pool); err != nil {
+		log.Fatalf("Failed to seed predefined themes: %v", err)
+	}
+
+	log.Println("✅ Successfully seeded all predefined themes!")
+	os.Exit(0)
+}
+
+// Light/dark variants that clients switch between based on OS preference
+var predefinedThemePairs = [][2]string{
+	{"OmniNudge Light", "OmniNudge Dark"},
+}
+
+// seedPredefinedThemes replaces the system user's themes with the predefined set
+// and links the light/dark pairs.
+func seedPredefinedThemes(ctx context.Context, pool *pgxpool.Pool) error {
+	themeRepo := models.NewUserThemeRepository(pool)
 
 	// Create system user (user_id = 0) if not exists
-	_, err = db.Pool.Exec(ctx, `
+	_, err := pool.Exec(ctx, `
 		INSERT INTO users (id, username, password_hash, reddit_id, created_at, last_seen, karma)
 		VALUES (0, 'system', '', 'system', NOW(), NOW(), 0)
 		ON CONFLICT (id) DO NOTHING
 	`)
 	if err != nil {
-		log.Fatalf("Failed to create system user: %v", err)
+		return fmt.Errorf("create system user: %w", err)
 	}
 
 	// Delete existing predefined themes
-	_, err = db.Pool.Exec(ctx, "DELETE FROM user_themes WHERE user_id = 0")
+	_, err = pool.Exec(ctx, "DELETE FROM user_themes WHERE user_id = 0")
 	if err != nil {
-		log.Fatalf("Failed to delete existing predefined themes: %v", err)
+		return fmt.Errorf("delete existing predefined themes: %w", err)
 	}
 
 	// Define the 8 predefined themes
@@ -282,16 +299,25 @@ func main() {
 
 	// Insert all themes
 	log.Printf("Inserting %d predefined themes...", len(themes))
+	idsByName := make(map[string]int, len(themes))
 	for i, theme := range themes {
 		created, err := themeRepo.Create(ctx, &theme)
 		if err != nil {
-			log.Fatalf("Failed to create theme '%s': %v", theme.ThemeName, err)
+			return fmt.Errorf("create theme '%s': %w", theme.ThemeName, err)
 		}
+		idsByName[created.ThemeName] = created.ID
 		log.Printf("[%d/%d] Created theme: %s (ID: %d)", i+1, len(themes), created.ThemeName, created.ID)
 	}
 
-	log.Println("✅ Successfully seeded all predefined themes!")
-	os.Exit(0)
+	// Link light/dark variants
+	for _, pair := range predefinedThemePairs {
+		if err := themeRepo.PairThemes(ctx, idsByName[pair[0]], idsByName[pair[1]]); err != nil {
+			return fmt.Errorf("pair '%s' with '%s': %w", pair[0], pair[1], err)
+		}
+		log.Printf("Paired themes: %s <-> %s", pair[0], pair[1])
+	}
+
+	return nil
 }
 
 func strPtr(s string) *string {
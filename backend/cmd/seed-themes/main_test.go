@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeedPredefinedThemes_PairsLightAndDark(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+	require.NoError(t, seedPredefinedThemes(ctx, db.Pool))
+
+	themeID := func(name string) int {
+		var id int
+		require.NoError(t, db.Pool.QueryRow(ctx, `SELECT id FROM user_themes WHERE user_id = 0 AND theme_name = $1`, name).Scan(&id))
+		return id
+	}
+	lightID := themeID("OmniNudge Light")
+	darkID := themeID("OmniNudge Dark")
+
+	themeRepo := models.NewUserThemeRepository(db.Pool)
+	paired, err := themeRepo.GetPairedTheme(ctx, lightID)
+	require.NoError(t, err)
+	require.NotNil(t, paired)
+	assert.Equal(t, darkID, paired.ID)
+	assert.Equal(t, models.ColorSchemeDark, paired.ColorScheme())
+
+	paired, err = themeRepo.GetPairedTheme(ctx, darkID)
+	require.NoError(t, err)
+	require.NotNil(t, paired)
+	assert.Equal(t, lightID, paired.ID)
+	assert.Equal(t, models.ColorSchemeLight, paired.ColorScheme())
+
+	unpaired, err := themeRepo.GetPairedTheme(ctx, themeID("Midnight"))
+	require.NoError(t, err)
+	assert.Nil(t, unpaired)
+}
@@ -21,6 +21,9 @@ import (
 	"github.com/omninudge/backend/internal/workers"
 )
 
+// serverWriteTimeout caps how long a handler has to write its response
+const serverWriteTimeout = 15 * time.Second
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -68,6 +71,7 @@ func main() {
 	notificationRepo := models.NewNotificationRepository(db.Pool)
 	baselineRepo := models.NewUserBaselineRepository(db.Pool)
 	batchRepo := models.NewNotificationBatchRepository(db.Pool)
+	pushSubscriptionRepo := models.NewPushSubscriptionRepository(db.Pool)
 	slideshowRepo := models.NewSlideshowRepository(db.Pool)
 	redditPostRepo := models.NewRedditPostRepository(db.Pool)
 	feedRepo := models.NewFeedRepository(db.Pool)
@@ -78,15 +82,23 @@ func main() {
 	savedItemsRepo := models.NewSavedItemsRepository(db.Pool)
 	hubSubRepo := models.NewHubSubscriptionRepository(db.Pool)
 	subredditSubRepo := models.NewSubredditSubscriptionRepository(db.Pool)
+	featureFlagRepo := models.NewFeatureFlagRepository(db.Pool)
+	knownBotRepo := models.NewRedditKnownBotRepository(db.Pool)
+	digestRepo := models.NewDigestRepository(db.Pool)
 
 	// Moderation Phase 1 repositories
 	hubBanRepo := models.NewHubBanRepository(db.Pool)
+	banAppealRepo := models.NewBanAppealRepository(db.Pool)
+	automodRepo := models.NewAutomodRepository(db.Pool)
 	removalReasonRepo := models.NewRemovalReasonRepository(db.Pool)
 	removedContentRepo := models.NewRemovedContentRepository(db.Pool)
 	modLogRepo := models.NewModLogRepository(db.Pool)
 
 	// Initialize WebSocket hub
 	hub := websocket.NewHub()
+	if cfg.WebSocket.PingIntervalSeconds > 0 {
+		hub.SetPingInterval(time.Duration(cfg.WebSocket.PingIntervalSeconds) * time.Second)
+	}
 	go hub.Run()
 
 	// Initialize services
@@ -97,17 +109,28 @@ func main() {
 		cfg.JWT.Secret,
 		cfg.Reddit.UserAgent,
 	)
+	featureFlags := services.NewFeatureFlags(featureFlagRepo, services.DefaultFeatureFlagTTL)
+	knownBots := services.NewKnownBots(cfg.Reddit.KnownBots, knownBotRepo, time.Minute)
 	var cache services.Cache = services.NoopCache{}
 	if cfg.Redis.Addr != "" {
 		cache = services.NewRedisCache(cfg.Redis.Addr, cfg.Redis.Password, 2*time.Second)
 	}
-	redditClient := services.NewRedditClient(
-		cfg.Reddit.UserAgent,
+	redditUserAgents := cfg.Reddit.UserAgents
+	if len(redditUserAgents) == 0 {
+		redditUserAgents = []string{cfg.Reddit.UserAgent}
+	}
+	redditClient, err := services.NewRedditClientWithUserAgents(
+		redditUserAgents,
 		cache,
 		time.Duration(cfg.Redis.TTLSeconds)*time.Second,
 		cfg.Reddit.ClientID,
 		cfg.Reddit.ClientSecret,
+		services.WithRedditStaleFallback(time.Duration(cfg.Reddit.StaleFallbackSeconds)*time.Second),
+		services.WithRedditNSFWFilter(cfg.Reddit.FilterNSFW),
 	)
+	if err != nil {
+		log.Fatalf("Invalid Reddit user agent configuration: %v", err)
+	}
 
 	// Initialize notification services
 	notificationService := services.NewNotificationService(
@@ -118,21 +141,60 @@ func main() {
 		userSettingsRepo,
 		postRepo,
 		commentRepo,
+		userRepo,
 		hub,
 	)
+	var pushSender services.PushSender = services.NoopPushSender{}
+	if cfg.Push.VAPIDPrivateKey != "" {
+		vapidSender, err := services.NewVAPIDPushSender(cfg.Push.VAPIDPrivateKey, cfg.Push.VAPIDSubject)
+		if err != nil {
+			log.Fatalf("Invalid web push configuration: %v", err)
+		}
+		pushSender = vapidSender
+		log.Printf("Web push enabled (VAPID public key %s)", vapidSender.PublicKey())
+	} else {
+		log.Println("Web push disabled: VAPID_PRIVATE_KEY is not set")
+	}
+	notificationService.SetPushSender(pushSender, pushSubscriptionRepo)
 	baselineCalculatorService := services.NewBaselineCalculatorService(db.Pool, baselineRepo)
 
 	// Start background workers
 	workerCtx := context.Background()
 	workerManager := workers.NewWorkerManager(notificationService, baselineCalculatorService)
+	if cfg.Digest.Enabled {
+		digestConfig := services.DefaultDigestConfig()
+		digestConfig.InactiveFor = time.Duration(cfg.Digest.InactiveDays) * 24 * time.Hour
+		digestConfig.Cadence = time.Duration(cfg.Digest.CadenceHours) * time.Hour
+		digestConfig.UnsubscribeURL = cfg.Digest.UnsubscribeURL
+		// No mail provider is wired up yet; plug a services.Mailer in here
+		workerManager.SetDigestService(services.NewDigestService(digestRepo, services.NoopMailer{}, digestConfig))
+	}
+	if cfg.HotScore.IntervalMinutes > 0 {
+		workerManager.SetHotScoreWorker(workers.NewHotScoreWorker(
+			postRepo,
+			time.Duration(cfg.HotScore.IntervalMinutes)*time.Minute,
+			cfg.HotScore.WindowHours,
+		))
+	}
+	workerManager.SetExpireBansWorker(workers.NewExpireBansWorker(hubBanRepo, modLogRepo, 5*time.Minute))
 	workerManager.Start(workerCtx)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService, userRepo)
+	authHandler.SetRedditClient(redditClient)
 	settingsHandler := handlers.NewSettingsHandler(userSettingsRepo)
 	postsHandler := handlers.NewPostsHandler(postRepo, hubRepo, userRepo, hubModRepo, feedRepo)
 	commentsHandler := handlers.NewCommentsHandler(commentRepo, postRepo, hubModRepo)
 	redditHandler := handlers.NewRedditHandler(redditClient, redditPostRepo)
+
+	// Bound Reddit proxy requests below the server write timeout so a slow
+	// Reddit response ends in a 504 instead of a dropped connection
+	redditRequestTimeout := time.Duration(cfg.Reddit.RequestTimeoutSeconds) * time.Second
+	if redditRequestTimeout <= 0 || redditRequestTimeout >= serverWriteTimeout {
+		log.Printf("REDDIT_REQUEST_TIMEOUT_SECONDS=%d must be between 1 and %d; using %s",
+			cfg.Reddit.RequestTimeoutSeconds, int(serverWriteTimeout/time.Second)-1, serverWriteTimeout-5*time.Second)
+		redditRequestTimeout = serverWriteTimeout - 5*time.Second
+	}
 	conversationsHandler := handlers.NewConversationsHandler(conversationRepo, messageRepo, userRepo)
 	// Initialize thumbnail service
 	thumbnailService := services.NewThumbnailService()
@@ -143,6 +205,24 @@ func main() {
 	messagesHandler := handlers.NewMessagesHandler(db.Pool, messageRepo, conversationRepo, hub)
 	usersHandler := handlers.NewUsersHandler(userRepo, postRepo, commentRepo, authService, hubModRepo)
 	mediaHandler := handlers.NewMediaHandler(mediaRepo, thumbnailService)
+	if cfg.Media.TranscodeEnabled {
+		ffmpeg := services.NewFFmpegTranscoder(cfg.Media.FFmpegPath, cfg.Media.TranscodeHLS)
+		if ffmpeg.Available() {
+			mediaProcessor := services.NewMediaProcessor(
+				mediaRepo,
+				ffmpeg,
+				services.NewLocalStorageService("./uploads", ""),
+				cfg.Media.TranscodeWorkers,
+				time.Duration(cfg.Media.TranscodeTimeoutSeconds)*time.Second,
+			)
+			mediaHandler.SetMediaProcessor(mediaProcessor)
+			if err := mediaProcessor.ResumePending(context.Background()); err != nil {
+				log.Printf("Failed to resume pending media transcodes: %v", err)
+			}
+		} else {
+			log.Printf("Media transcoding enabled but %q was not found; uploads will not be transcoded", cfg.Media.FFmpegPath)
+		}
+	}
 	hubsHandler := handlers.NewHubsHandler(hubRepo, postRepo, hubModRepo, hubSubRepo)
 	subscriptionsHandler := handlers.NewSubscriptionsHandler(hubSubRepo, subredditSubRepo, hubRepo)
 	moderationHandler := handlers.NewModerationHandler(reportRepo, hubModRepo)
@@ -155,29 +235,69 @@ func main() {
 		postRepo,
 		commentRepo,
 		hubRepo,
+		banAppealRepo,
 	)
+	moderationHandlerV2.SetReportRepository(reportRepo)
+	moderationHandlerV2.SetAutomodRepository(automodRepo)
 	adminHandler := handlers.NewAdminHandler(userRepo, hubModRepo, db.Pool)
-	wsHandler := handlers.NewWebSocketHandler(hub)
+	wsHandler := handlers.NewWebSocketHandler(hub, cfg.CORS.AllowedOrigins)
 	notificationsHandler := handlers.NewNotificationsHandler(notificationRepo)
+	pushSubscriptionsHandler := handlers.NewPushSubscriptionsHandler(pushSubscriptionRepo)
 	searchHandler := handlers.NewSearchHandler(db.Pool)
 	blockingHandler := handlers.NewBlockingHandler(db.Pool, userRepo)
 	slideshowHandler := handlers.NewSlideshowHandler(db.Pool, slideshowRepo, conversationRepo, hub)
 	mediaGalleryHandler := handlers.NewMediaGalleryHandler(db.Pool)
 	userStatusHandler := handlers.NewUserStatusHandler(hub)
 	themesHandler := handlers.NewThemesHandler(themeRepo, themeOverrideRepo, installedThemeRepo, userSettingsRepo, cssSanitizer)
+	themesHandler.SetCSSLimits(services.ThemeCSSLimits{
+		Private: services.CSSLimits{
+			MaxCSSBytes:  cfg.Themes.MaxCSSBytes,
+			MaxRules:     cfg.Themes.MaxCSSRules,
+			MaxVariables: cfg.Themes.MaxCSSVariables,
+		},
+		Public: services.CSSLimits{
+			MaxCSSBytes:  cfg.Themes.PublicMaxCSSBytes,
+			MaxRules:     cfg.Themes.PublicMaxCSSRules,
+			MaxVariables: cfg.Themes.PublicMaxVariables,
+		},
+	})
 	redditCommentsHandler := handlers.NewRedditCommentsHandler(redditCommentRepo)
 	savedItemsHandler := handlers.NewSavedItemsHandler(savedItemsRepo, postRepo, commentRepo, redditCommentRepo, redditClient)
 	feedHandler := handlers.NewFeedHandler(postRepo, hubSubRepo, subredditSubRepo, redditClient)
+	featureFlagsHandler := handlers.NewFeatureFlagsHandler(featureFlagRepo, featureFlags)
+	knownBotsHandler := handlers.NewKnownBotsHandler(knownBotRepo, knownBots)
+	digestHandler := handlers.NewDigestHandler(digestRepo)
 
 	// Inject notification service into handlers
 	postsHandler.SetNotificationService(notificationService)
 	commentsHandler.SetNotificationService(notificationService)
+	moderationHandler.SetNotificationService(notificationService)
+	moderationHandlerV2.SetNotificationService(notificationService)
+
+	// Keep users banned from a hub from posting or commenting there
+	postsHandler.SetHubBanRepository(hubBanRepo)
+	commentsHandler.SetHubBanRepository(hubBanRepo)
+	postsHandler.SetAutomod(automodRepo, modLogRepo)
+	commentsHandler.SetAutomod(automodRepo, modLogRepo)
+
+	// Flag known bot comments in Reddit threads
+	redditHandler.SetKnownBots(knownBots)
+
+	// Cache public hub moderator lists
+	hubsHandler.SetCache(cache)
+	adminHandler.SetCache(cache)
+
+	// Report media processing status on hub posts
+	hubsHandler.SetMediaRepository(mediaRepo)
+	hubsHandler.SetMemberRepository(models.NewHubMemberRepository(db.Pool))
+	hubsHandler.SetHubBanRepository(hubBanRepo)
+	feedHandler.SetMediaRepository(mediaRepo)
 
 	// Setup Gin router
 	router := gin.Default()
 
 	// Apply CORS middleware BEFORE static files
-	router.Use(middleware.CORS())
+	router.Use(middleware.CORS(cfg.CORS.AllowedOrigins))
 
 	// Serve static files with CORS headers
 	router.Static("/uploads", "./uploads")
@@ -212,6 +332,11 @@ func main() {
 			})
 		})
 
+		// Digest email unsubscribe link (token-authenticated). GET only shows a
+		// confirmation page so link scanners can't unsubscribe anyone.
+		api.GET("/digest/unsubscribe", digestHandler.ConfirmUnsubscribe)
+		api.POST("/digest/unsubscribe", digestHandler.Unsubscribe)
+
 		// Auth routes (no auth required)
 		auth := api.Group("/auth")
 		{
@@ -235,15 +360,19 @@ func main() {
 		posts := api.Group("/posts")
 		posts.Use(middleware.AuthOptional(authService))
 		{
+			posts.GET("", postsHandler.GetPostsByIDs)
 			posts.GET("/feed", postsHandler.GetFeed)
+			posts.GET("/by-tags", postsHandler.GetPostsByTags)
 			posts.GET("/:id", postsHandler.GetPost)
 			posts.GET("/:id/comments", commentsHandler.GetComments)
+			posts.GET("/:id/crossposts", postsHandler.GetPostCrossposts)
 		}
 
 		// Public comments routes (no auth required for viewing)
 		comments := api.Group("/comments")
 		comments.Use(middleware.AuthOptional(authService))
 		{
+			comments.GET("", commentsHandler.GetCommentsByIDs)
 			comments.GET("/:id", commentsHandler.GetComment)
 			comments.GET("/:id/replies", commentsHandler.GetCommentReplies)
 		}
@@ -251,13 +380,17 @@ func main() {
 		// Public Reddit routes (no auth required - browsing only)
 		reddit := api.Group("/reddit")
 		reddit.Use(middleware.AuthOptional(authService))
+		reddit.Use(middleware.RequestTimeout(redditRequestTimeout))
 		{
 			reddit.GET("/frontpage", redditHandler.GetFrontPage)
 			reddit.GET("/subreddits/autocomplete", redditHandler.AutocompleteSubreddits)
 			reddit.GET("/subreddits/search", redditHandler.SearchSubreddits)
 			reddit.GET("/r/:subreddit", redditHandler.GetSubredditPosts)
 			reddit.GET("/r/:subreddit/about", redditHandler.GetSubredditAbout)
+			reddit.GET("/r/:subreddit/rules", redditHandler.GetSubredditRules)
 			reddit.GET("/r/:subreddit/moderators", redditHandler.GetSubredditModerators)
+			reddit.GET("/r/:subreddit/flairs", redditHandler.GetSubredditLinkFlairs)
+			reddit.GET("/r/:subreddit/overview", redditHandler.GetSubredditOverview)
 			reddit.GET("/r/:subreddit/media", redditHandler.GetSubredditMedia)
 			revisions := reddit.Group("/r/:subreddit/wiki/revisions")
 			{
@@ -280,11 +413,14 @@ func main() {
 				wiki.GET("/:pagePath/*rest", redditHandler.GetSubredditWikiPage)
 			}
 			reddit.GET("/r/:subreddit/comments/:postId", redditHandler.GetPostComments)
+			reddit.GET("/r/:subreddit/duplicates/:postId", redditHandler.GetDuplicatePosts)
 			reddit.GET("/search", redditHandler.SearchPosts)
+			reddit.GET("/resolve", redditHandler.ResolveRedditLink)
 			reddit.GET("/wiki/:pagePath", redditHandler.GetWikiPage)
 			reddit.GET("/user/:username/about", redditHandler.GetRedditUserAbout)
 			reddit.GET("/user/:username/trophies", redditHandler.GetRedditUserTrophies)
 			reddit.GET("/user/:username/moderated", redditHandler.GetRedditUserModerated)
+			reddit.GET("/user/:username/m/:multi", redditHandler.GetRedditMultireddit)
 			reddit.GET("/user/:username/:section", redditHandler.GetRedditUserListing)
 			reddit.GET("/users/search", redditHandler.SearchRedditUsers)
 
@@ -303,6 +439,8 @@ func main() {
 			hubs.GET("/trending", hubsHandler.GetTrendingHubs)
 			hubs.GET("/:name", hubsHandler.Get)
 			hubs.GET("/:name/posts", hubsHandler.GetPosts)
+			hubs.GET("/:name/moderators", hubsHandler.GetModerators)
+			hubs.GET("/:name/related", hubsHandler.GetRelatedHubs)
 		}
 
 		// Hub subscription check (optional auth)
@@ -320,8 +458,9 @@ func main() {
 			subreddits.GET("/:name/subscription", subscriptionsHandler.CheckSubredditSubscription)
 		}
 
-		// Public user profile routes
+		// Public user profile routes (auth optional, so members see private hub activity)
 		users := api.Group("/users")
+		users.Use(middleware.AuthOptional(authService))
 		{
 			users.GET("/status", userStatusHandler.GetUsersStatus)
 			users.GET("/:username", usersHandler.GetUserProfile)
@@ -329,13 +468,15 @@ func main() {
 			users.GET("/:username/comments", usersHandler.GetUserComments)
 		}
 
-		// Public search routes
+		// Public search routes (auth optional, so members find private hub posts)
 		search := api.Group("/search")
+		search.Use(middleware.AuthOptional(authService))
 		{
 			search.GET("/posts", searchHandler.SearchPosts)
 			search.GET("/comments", searchHandler.SearchComments)
 			search.GET("/users", searchHandler.SearchUsers)
 			search.GET("/hubs", searchHandler.SearchHubs)
+			search.GET("/all", searchHandler.SearchAll)
 		}
 
 		// Protected routes (auth required)
@@ -357,24 +498,34 @@ func main() {
 			themePreviewLimiter := middleware.ThemePreviewRateLimiter()
 			generalLimiter := middleware.GeneralAPIRateLimiter()
 
+			themesMarketplace := middleware.RequireFeature(featureFlags, "themes_marketplace")
+
 			// Predefined themes (public access within protected routes, general rate limit)
 			protected.GET("/themes/predefined", generalLimiter.Middleware(), themesHandler.GetPredefinedThemes)
 
+			// Preview sanitized CSS without saving (preview rate limit)
+			protected.POST("/themes/preview", themePreviewLimiter.Middleware(), themesHandler.PreviewTheme)
+
 			// Browse public themes (preview rate limit)
-			protected.GET("/themes/browse", themePreviewLimiter.Middleware(), themesHandler.BrowseThemes)
+			protected.GET("/themes/browse", themesMarketplace, themePreviewLimiter.Middleware(), themesHandler.BrowseThemes)
 
 			// User's own themes (creation/write operations use stricter limit)
 			protected.POST("/themes", themeCreationLimiter.Middleware(), themesHandler.CreateTheme)
 			protected.GET("/themes/my", generalLimiter.Middleware(), themesHandler.GetMyThemes)
+			protected.POST("/themes/import", themeCreationLimiter.Middleware(), themesHandler.ImportTheme)
 			protected.GET("/themes/:id", themePreviewLimiter.Middleware(), themesHandler.GetTheme)
+			protected.GET("/themes/:id/export", themePreviewLimiter.Middleware(), themesHandler.ExportTheme)
+			protected.GET("/themes/:id/versions", themePreviewLimiter.Middleware(), themesHandler.GetThemeVersions)
 			protected.PUT("/themes/:id", themeCreationLimiter.Middleware(), themesHandler.UpdateTheme)
 			protected.DELETE("/themes/:id", themeCreationLimiter.Middleware(), themesHandler.DeleteTheme)
 
 			// Theme installation & activation (general rate limit)
-			protected.POST("/themes/install", generalLimiter.Middleware(), themesHandler.InstallTheme)
-			protected.DELETE("/themes/install/:themeId", generalLimiter.Middleware(), themesHandler.UninstallTheme)
+			protected.POST("/themes/install", themesMarketplace, generalLimiter.Middleware(), themesHandler.InstallTheme)
+			protected.DELETE("/themes/install/:themeId", themesMarketplace, generalLimiter.Middleware(), themesHandler.UninstallTheme)
 			protected.POST("/themes/active", generalLimiter.Middleware(), themesHandler.SetActiveTheme)
 			protected.GET("/themes/installed", generalLimiter.Middleware(), themesHandler.GetInstalledThemes)
+			protected.GET("/themes/resolve", generalLimiter.Middleware(), themesHandler.ResolveTheme)
+			protected.GET("/themes/effective/:pageName", generalLimiter.Middleware(), themesHandler.GetEffectiveTheme)
 
 			// Per-page theme overrides (Level 4, creation limit for writes)
 			protected.POST("/themes/overrides", themeCreationLimiter.Middleware(), themesHandler.SetPageOverride)
@@ -386,11 +537,13 @@ func main() {
 			protected.POST("/themes/advanced-mode", generalLimiter.Middleware(), themesHandler.SetAdvancedMode)
 
 			// Theme rating & reviews (Phase 2c, general rate limit)
-			protected.POST("/themes/rate", generalLimiter.Middleware(), themesHandler.RateTheme)
+			protected.POST("/themes/rate", themesMarketplace, generalLimiter.Middleware(), themesHandler.RateTheme)
+			protected.GET("/themes/:id/reviews", themesMarketplace, generalLimiter.Middleware(), themesHandler.GetThemeReviews)
 
 			// Protected posts routes (auth required for creating/editing)
 			protected.POST("/posts", postsHandler.CreatePost)
 			protected.PUT("/posts/:id", postsHandler.UpdatePost)
+			protected.GET("/posts/:id/edits", postsHandler.GetPostEdits)
 			protected.DELETE("/posts/:id", postsHandler.DeletePost)
 			protected.POST("/posts/:id/vote", postsHandler.VotePost)
 			protected.POST("/posts/:id/save", savedItemsHandler.SavePost)
@@ -422,13 +575,19 @@ func main() {
 
 			// Protected hub creation and crossposting
 			protected.POST("/hubs", hubsHandler.Create)
+			protected.PUT("/hubs/:name", hubsHandler.Update)
 			protected.GET("/users/me/hubs", hubsHandler.GetUserHubs)
 			protected.POST("/hubs/:name/crosspost", hubsHandler.CrosspostToHub)
+			protected.POST("/hubs/:name/members", hubsHandler.AddMember)
+			protected.DELETE("/hubs/:name/members/:user_id", hubsHandler.RemoveMember)
+			protected.DELETE("/hubs/:name/moderators/:user_id", hubsHandler.RemoveModerator)
+			protected.PUT("/hubs/:name/moderators/:user_id/permissions", hubsHandler.SetModeratorPermissions)
 			protected.POST("/subreddits/:name/crosspost", hubsHandler.CrosspostToSubreddit)
 
 			// Hub subscription routes (auth required)
 			protected.POST("/hubs/:name/subscribe", subscriptionsHandler.SubscribeToHub)
 			protected.DELETE("/hubs/:name/unsubscribe", subscriptionsHandler.UnsubscribeFromHub)
+			protected.POST("/hubs/:name/ban/appeal", moderationHandlerV2.SubmitBanAppeal)
 			protected.GET("/users/me/subscriptions/hubs", subscriptionsHandler.GetUserHubSubscriptions)
 
 			// Subreddit subscription routes (auth required)
@@ -439,23 +598,34 @@ func main() {
 			// Protected conversations routes
 			protected.POST("/conversations", conversationsHandler.CreateConversation)
 			protected.GET("/conversations", conversationsHandler.GetConversations)
+			protected.POST("/conversations/read-all", messagesHandler.MarkAllAsRead)
 			protected.GET("/conversations/:id", conversationsHandler.GetConversation)
 			protected.DELETE("/conversations/:id", conversationsHandler.DeleteConversation)
 
 			// Protected messages routes
 			protected.POST("/messages", messagesHandler.SendMessage)
 			protected.GET("/conversations/:id/messages", messagesHandler.GetMessages)
+			protected.GET("/conversations/:id/messages/search", messagesHandler.SearchMessages)
+			protected.GET("/conversations/:id/export", messagesHandler.ExportConversation)
 			protected.POST("/conversations/:id/read", messagesHandler.MarkAsRead)
+			protected.POST("/conversations/:id/typing", messagesHandler.SendTyping)
 			protected.POST("/messages/:id/read", messagesHandler.MarkSingleMessageAsRead)
+			protected.PUT("/messages/:id", messagesHandler.EditMessage)
+			protected.POST("/messages/:id/reactions", messagesHandler.AddReaction)
+			protected.DELETE("/messages/:id/reactions/:emoji", messagesHandler.RemoveReaction)
 			protected.DELETE("/messages/:id", messagesHandler.DeleteMessage)
 
 			// Slideshow routes
-			protected.POST("/conversations/:id/slideshow", slideshowHandler.StartSlideshow)
-			protected.GET("/conversations/:id/slideshow", slideshowHandler.GetSlideshow)
-			protected.POST("/slideshows/:id/navigate", slideshowHandler.NavigateSlideshow)
-			protected.POST("/slideshows/:id/transfer-control", slideshowHandler.TransferControl)
-			protected.PUT("/slideshows/:id/auto-advance", slideshowHandler.UpdateAutoAdvance)
-			protected.DELETE("/slideshows/:id", slideshowHandler.StopSlideshow)
+			slideshowEnabled := middleware.RequireFeature(featureFlags, "slideshow")
+			protected.POST("/conversations/:id/slideshow", slideshowEnabled, slideshowHandler.StartSlideshow)
+			protected.GET("/conversations/:id/slideshow", slideshowEnabled, slideshowHandler.GetSlideshow)
+			protected.POST("/slideshows/:id/navigate", slideshowEnabled, slideshowHandler.NavigateSlideshow)
+			protected.POST("/slideshows/:id/transfer-control", slideshowEnabled, slideshowHandler.TransferControl)
+			protected.PUT("/slideshows/:id/auto-advance", slideshowEnabled, slideshowHandler.UpdateAutoAdvance)
+			protected.DELETE("/slideshows/:id", slideshowEnabled, slideshowHandler.StopSlideshow)
+			protected.POST("/slideshows/:id/join", slideshowEnabled, slideshowHandler.JoinSlideshow)
+			protected.POST("/slideshows/:id/leave", slideshowEnabled, slideshowHandler.LeaveSlideshow)
+			protected.GET("/slideshows/:id/viewers", slideshowEnabled, slideshowHandler.GetViewers)
 
 			// Media gallery routes
 			protected.GET("/conversations/:id/media", mediaGalleryHandler.GetConversationMedia)
@@ -481,6 +651,10 @@ func main() {
 			protected.POST("/notifications/:id/read", notificationsHandler.MarkAsRead)
 			protected.POST("/notifications/read-all", notificationsHandler.MarkAllAsRead)
 			protected.DELETE("/notifications/:id", notificationsHandler.DeleteNotification)
+			protected.GET("/notifications/preferences", settingsHandler.GetNotificationPreferences)
+			protected.PUT("/notifications/preferences", settingsHandler.UpdateNotificationPreferences)
+			protected.POST("/notifications/push/subscribe", pushSubscriptionsHandler.Subscribe)
+			protected.POST("/notifications/push/unsubscribe", pushSubscriptionsHandler.Unsubscribe)
 
 			// Moderation reports
 			protected.POST("/reports", moderationHandler.CreateReport)
@@ -500,6 +674,8 @@ func main() {
 				hubMod.POST("/hubs/:hub_name/bans", moderationHandlerV2.BanUser)
 				hubMod.DELETE("/hubs/:hub_name/bans/:user_id", moderationHandlerV2.UnbanUser)
 				hubMod.GET("/hubs/:hub_name/bans", moderationHandlerV2.GetBannedUsers)
+				hubMod.GET("/hubs/:hub_name/ban/appeals", moderationHandlerV2.GetBanAppeals)
+				hubMod.POST("/hubs/:hub_name/ban/appeals/:id/resolve", moderationHandlerV2.ResolveBanAppeal)
 
 				// Post moderation
 				hubMod.POST("/posts/:id/remove", moderationHandlerV2.RemovePost)
@@ -508,10 +684,13 @@ func main() {
 				hubMod.POST("/posts/:id/unlock", moderationHandlerV2.UnlockPost)
 				hubMod.POST("/posts/:id/pin", moderationHandlerV2.PinPost)
 				hubMod.POST("/posts/:id/unpin", moderationHandlerV2.UnpinPost)
+				hubMod.PUT("/posts/:id/pin-order", moderationHandlerV2.SetPinOrder)
 
 				// Comment moderation
 				hubMod.POST("/comments/:id/remove", moderationHandlerV2.RemoveComment)
 				hubMod.POST("/comments/:id/approve", moderationHandlerV2.ApproveComment)
+				hubMod.POST("/hubs/:hub_name/users/:userid/remove-all", moderationHandlerV2.RemoveAllUserContent)
+				hubMod.GET("/hubs/:hub_name/removed", moderationHandlerV2.GetRemovedContent)
 
 				// Removal reasons
 				hubMod.POST("/hubs/:hub_name/removal-reasons", moderationHandlerV2.CreateRemovalReason)
@@ -519,6 +698,15 @@ func main() {
 				hubMod.DELETE("/removal-reasons/:id", moderationHandlerV2.DeleteRemovalReason)
 				hubMod.GET("/hubs/:hub_name/removal-reasons", moderationHandlerV2.GetRemovalReasons)
 
+				// Automod
+				hubMod.GET("/hubs/:hub_name/automod", moderationHandlerV2.GetAutomodRules)
+				hubMod.POST("/hubs/:hub_name/automod", moderationHandlerV2.CreateAutomodRule)
+				hubMod.PUT("/hubs/:hub_name/automod/:id", moderationHandlerV2.UpdateAutomodRule)
+				hubMod.DELETE("/hubs/:hub_name/automod/:id", moderationHandlerV2.DeleteAutomodRule)
+
+				// Mod queue
+				hubMod.GET("/hubs/:hub_name/queue", moderationHandlerV2.GetModQueue)
+
 				// Mod log
 				hubMod.GET("/hubs/:hub_name/mod-log", moderationHandlerV2.GetModLog)
 			}
@@ -538,6 +726,19 @@ func main() {
 
 				// Site statistics
 				admin.GET("/stats", adminHandler.GetSiteStats)
+
+				// Post recovery
+				admin.POST("/posts/:id/restore", postsHandler.RestorePost)
+
+				// Feature flags
+				admin.GET("/feature-flags", featureFlagsHandler.ListFlags)
+				admin.PUT("/feature-flags/:name", featureFlagsHandler.SetFlag)
+				admin.DELETE("/feature-flags/:name", featureFlagsHandler.DeleteFlag)
+
+				// Reddit known-bot list
+				admin.GET("/reddit-bots", knownBotsHandler.ListBots)
+				admin.POST("/reddit-bots", knownBotsHandler.AddBot)
+				admin.DELETE("/reddit-bots/:username", knownBotsHandler.RemoveBot)
 			}
 
 			// WebSocket endpoint for real-time messaging
@@ -551,7 +752,7 @@ func main() {
 		Addr:         addr,
 		Handler:      router,
 		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
+		WriteTimeout: serverWriteTimeout,
 		IdleTimeout:  60 * time.Second,
 	}
 
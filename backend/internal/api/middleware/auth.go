@@ -75,20 +75,11 @@ func RequireRole(allowedRoles ...string) gin.HandlerFunc {
 	}
 }
 
-// CORS middleware for handling cross-origin requests
-func CORS() gin.HandlerFunc {
+// CORS middleware for handling cross-origin requests from allowedOrigins
+func CORS(allowedOrigins []string) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		origin := c.GetHeader("Origin")
 
-		// In production, restrict this to your frontend domain
-		allowedOrigins := []string{
-			"http://localhost:3000",
-			"http://localhost:5173",
-			"http://localhost:5174",
-			"http://localhost:5175",
-			"http://localhost:5176",
-		}
-
 		allowed := false
 		for _, o := range allowedOrigins {
 			if origin == o {
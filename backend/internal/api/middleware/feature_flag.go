@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/services"
+)
+
+// RequireFeature hides a route behind a feature flag. When the flag is off for
+// the current user the route responds 404 as if it did not exist.
+// Must run after auth middleware so targeted and percentage rollouts see the user.
+func RequireFeature(flags *services.FeatureFlags, name string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := 0
+		if id, exists := c.Get("user_id"); exists {
+			userID, _ = id.(int)
+		}
+
+		if !flags.IsEnabled(c.Request.Context(), name, userID) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Not found", "feature": name, "feature_disabled": true})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
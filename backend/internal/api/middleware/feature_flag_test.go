@@ -0,0 +1,79 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticFlagStore []*models.FeatureFlag
+
+func (s staticFlagStore) List(ctx context.Context) ([]*models.FeatureFlag, error) {
+	return s, nil
+}
+
+func newFeatureRouter(flags *services.FeatureFlags, userID int) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+	})
+	router.GET("/slideshows", RequireFeature(flags, "slideshow"), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRequireFeature_OffFlagHidesRoute(t *testing.T) {
+	flags := services.NewFeatureFlags(staticFlagStore{
+		{Name: "slideshow", Enabled: false, RolloutPercentage: 100},
+	}, time.Minute)
+
+	w := httptest.NewRecorder()
+	newFeatureRouter(flags, 1).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slideshows", nil))
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), `"feature_disabled":true`)
+}
+
+func TestRequireFeature_OnFlagAllowsRoute(t *testing.T) {
+	flags := services.NewFeatureFlags(staticFlagStore{
+		{Name: "slideshow", Enabled: true, RolloutPercentage: 100},
+	}, time.Minute)
+
+	w := httptest.NewRecorder()
+	newFeatureRouter(flags, 1).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slideshows", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRequireFeature_PercentageRolloutUsesRequestUser(t *testing.T) {
+	flags := services.NewFeatureFlags(staticFlagStore{
+		{Name: "slideshow", Enabled: true, RolloutPercentage: 50},
+	}, time.Minute)
+
+	// Find one user inside and one outside the rollout
+	inside, outside := 0, 0
+	for id := 1; inside == 0 || outside == 0; id++ {
+		if services.RolloutBucket("slideshow", id) < 50 {
+			inside = id
+		} else {
+			outside = id
+		}
+	}
+
+	w := httptest.NewRecorder()
+	newFeatureRouter(flags, inside).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slideshows", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	newFeatureRouter(flags, outside).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slideshows", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
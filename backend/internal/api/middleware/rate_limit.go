@@ -1,19 +1,28 @@
 package middleware
 
 import (
+	"math"
 	"net/http"
+	"strconv"
 	"sync"
 
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
 
+// DefaultWarningThreshold is the fraction of the burst budget a user can consume
+// before responses start carrying the X-RateLimit-Warning header
+const DefaultWarningThreshold = 0.8
+
 // RateLimiter manages rate limiters for users
 type RateLimiter struct {
 	limiters map[int]*rate.Limiter
 	mu       sync.RWMutex
 	limit    rate.Limit
 	burst    int
+
+	// warningThreshold is the consumed fraction of the budget at which soft warnings begin
+	warningThreshold float64
 }
 
 // NewRateLimiter creates a new rate limiter
@@ -21,12 +30,20 @@ type RateLimiter struct {
 // burst: maximum burst size
 func NewRateLimiter(limit rate.Limit, burst int) *RateLimiter {
 	return &RateLimiter{
-		limiters: make(map[int]*rate.Limiter),
-		limit:    limit,
-		burst:    burst,
+		limiters:         make(map[int]*rate.Limiter),
+		limit:            limit,
+		burst:            burst,
+		warningThreshold: DefaultWarningThreshold,
 	}
 }
 
+// WithWarningThreshold overrides the consumed-budget fraction (0-1) at which
+// successful responses start carrying a soft rate-limit warning
+func (rl *RateLimiter) WithWarningThreshold(threshold float64) *RateLimiter {
+	rl.warningThreshold = threshold
+	return rl
+}
+
 // getLimiter returns the rate limiter for a specific user, creating one if it doesn't exist
 func (rl *RateLimiter) getLimiter(userID int) *rate.Limiter {
 	rl.mu.RLock()
@@ -65,7 +82,10 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 
 		limiter := rl.getLimiter(userID.(int))
 
+		c.Header("X-RateLimit-Limit", strconv.Itoa(rl.burst))
+
 		if !limiter.Allow() {
+			c.Header("X-RateLimit-Remaining", "0")
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error": "Rate limit exceeded. Please try again later.",
 			})
@@ -73,10 +93,26 @@ func (rl *RateLimiter) Middleware() gin.HandlerFunc {
 			return
 		}
 
+		// Warn clients once they are in the upper portion of their budget so they can slow down
+		remaining := int(math.Max(0, math.Floor(limiter.Tokens())))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if rl.shouldWarn(remaining) {
+			c.Header("X-RateLimit-Warning", "true")
+		}
+
 		c.Next()
 	}
 }
 
+// shouldWarn reports whether the consumed share of the burst budget has reached the warning threshold
+func (rl *RateLimiter) shouldWarn(remaining int) bool {
+	if rl.burst <= 0 || rl.warningThreshold <= 0 {
+		return false
+	}
+	consumed := float64(rl.burst-remaining) / float64(rl.burst)
+	return consumed >= rl.warningThreshold
+}
+
 // UploadRateLimiter creates a rate limiter specifically for media uploads
 // Allows 10 uploads per minute (10 requests / 60 seconds = ~0.167 requests/second)
 func UploadRateLimiter() *RateLimiter {
@@ -0,0 +1,69 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/time/rate"
+)
+
+func newRateLimitedRouter(rl *RateLimiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", 42)
+	})
+	router.Use(rl.Middleware())
+	router.GET("/", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func TestRateLimiter_WarnsInUpperPortionOfBudget(t *testing.T) {
+	// Effectively no refill during the test so the budget is consumed deterministically
+	router := newRateLimitedRouter(NewRateLimiter(rate.Limit(0.0001), 10))
+
+	for i := 1; i <= 10; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		router.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusOK, w.Code, "request %d should succeed", i)
+		require.Equal(t, "10", w.Header().Get("X-RateLimit-Limit"))
+		if i < 8 {
+			require.Empty(t, w.Header().Get("X-RateLimit-Warning"), "request %d should not warn", i)
+		} else {
+			require.Equal(t, "true", w.Header().Get("X-RateLimit-Warning"), "request %d should warn", i)
+		}
+	}
+}
+
+func TestRateLimiter_HardLimitStillReturns429(t *testing.T) {
+	router := newRateLimitedRouter(NewRateLimiter(rate.Limit(0.0001), 2))
+
+	for i := 0; i < 2; i++ {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+}
+
+func TestRateLimiter_RemainingHeaderCountsDown(t *testing.T) {
+	router := newRateLimitedRouter(NewRateLimiter(rate.Limit(0.0001), 5))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/", nil)
+	router.ServeHTTP(w, req)
+	require.Equal(t, "4", w.Header().Get("X-RateLimit-Remaining"))
+}
@@ -15,6 +15,13 @@ type Config struct {
 	JWT        JWTConfig
 	Redis      RedisConfig
 	Encryption EncryptionConfig
+	Themes     ThemesConfig
+	Digest     DigestConfig
+	Media      MediaConfig
+	HotScore   HotScoreConfig
+	CORS       CORSConfig
+	WebSocket  WebSocketConfig
+	Push       PushConfig
 }
 
 // RedditConfig holds Reddit OAuth configuration
@@ -23,6 +30,20 @@ type RedditConfig struct {
 	ClientSecret string
 	RedirectURI  string
 	UserAgent    string
+	// UserAgents optionally lists several compliant User-Agents to rotate per request.
+	// When empty, UserAgent is used for every request.
+	UserAgents []string
+	// KnownBots are Reddit usernames whose comments are flagged and collapsed by default.
+	// Admins can extend this list at runtime.
+	KnownBots []string
+	// RequestTimeoutSeconds bounds each Reddit-backed API request. Keep it below
+	// the server write timeout so slow upstream calls end in a clean 504.
+	RequestTimeoutSeconds int
+	// StaleFallbackSeconds keeps cached subreddit listings this long past their
+	// TTL so they can be served when Reddit is down. Zero disables it.
+	StaleFallbackSeconds int
+	// FilterNSFW drops over_18 posts from Reddit listings by default.
+	FilterNSFW bool
 }
 
 // JWTConfig holds JWT configuration
@@ -62,6 +83,100 @@ type EncryptionConfig struct {
 	Key string
 }
 
+// Default theme CSS limits, used when the environment doesn't set them
+const (
+	DefaultThemeMaxCSSBytes        = 50 * 1024
+	DefaultThemeMaxCSSRules        = 1000
+	DefaultThemeMaxCSSVariables    = 200
+	DefaultThemePublicMaxCSSBytes  = 20 * 1024
+	DefaultThemePublicMaxCSSRules  = 400
+	DefaultThemePublicMaxVariables = 100
+)
+
+// ThemesConfig holds size and complexity limits for user theme CSS.
+// Public limits apply to themes shared publicly or in the marketplace.
+type ThemesConfig struct {
+	MaxCSSBytes        int
+	MaxCSSRules        int
+	MaxCSSVariables    int
+	PublicMaxCSSBytes  int
+	PublicMaxCSSRules  int
+	PublicMaxVariables int
+}
+
+// DigestConfig controls digest emails sent to inactive users
+type DigestConfig struct {
+	Enabled bool
+	// InactiveDays is how long a user must have been away to get a digest
+	InactiveDays int
+	// CadenceHours is the minimum time between digests to the same user
+	CadenceHours int
+	// UnsubscribeURL is the public URL of the unsubscribe endpoint
+	UnsubscribeURL string
+}
+
+// HotScoreConfig controls the worker that keeps platform post hot scores fresh
+type HotScoreConfig struct {
+	// IntervalMinutes is how often hot scores are recomputed; 0 disables the worker
+	IntervalMinutes int
+	// WindowHours limits recomputation to posts created within this many hours
+	WindowHours int
+}
+
+// MediaConfig controls storage and background processing of uploaded media
+type MediaConfig struct {
+	// TranscodeEnabled turns on transcoding of uploaded videos to H.264/AAC MP4
+	TranscodeEnabled bool
+	FFmpegPath       string
+	// TranscodeHLS additionally produces an HLS rendition
+	TranscodeHLS            bool
+	TranscodeWorkers        int
+	TranscodeTimeoutSeconds int
+}
+
+// CORSConfig lists the browser origins allowed to call the API and open websockets
+type CORSConfig struct {
+	AllowedOrigins []string
+}
+
+// WebSocketConfig controls websocket connection keepalive
+type WebSocketConfig struct {
+	// PingIntervalSeconds is how often clients are pinged; a client that misses
+	// a pong for about this long is disconnected and marked offline
+	PingIntervalSeconds int
+}
+
+// PushConfig holds the VAPID credentials used to send web push notifications.
+// Web push is disabled when VAPIDPrivateKey is empty.
+type PushConfig struct {
+	// VAPIDPrivateKey is the base64url raw P-256 private key; browsers
+	// subscribe with the matching public key
+	VAPIDPrivateKey string
+	// VAPIDSubject is a mailto: or https: contact for push services
+	VAPIDSubject string
+}
+
+// defaultCORSAllowedOrigins are the local frontend dev servers
+var defaultCORSAllowedOrigins = []string{
+	"http://localhost:3000",
+	"http://localhost:5173",
+	"http://localhost:5174",
+	"http://localhost:5175",
+	"http://localhost:5176",
+}
+
+// defaultRedditKnownBots are common Reddit bots whose comments clutter the top of threads
+var defaultRedditKnownBots = []string{
+	"AutoModerator",
+	"RemindMeBot",
+	"RepostSleuthBot",
+	"SaveVideo",
+	"WikiSummarizerBot",
+	"sneakpeekbot",
+	"haikusbot",
+	"B0tRank",
+}
+
 // Load reads configuration from environment variables with sensible defaults
 func Load() (*Config, error) {
 	cfg := &Config{
@@ -83,6 +198,11 @@ func Load() (*Config, error) {
 			ClientSecret: getEnv("REDDIT_CLIENT_SECRET", ""),
 			RedirectURI:  getEnv("REDDIT_REDIRECT_URI", "http://localhost:8080/api/v1/auth/reddit/callback"),
 			UserAgent:    getEnv("REDDIT_USER_AGENT", "OmniNudge:v1.0"),
+			UserAgents:   getEnvAsList("REDDIT_USER_AGENTS", nil),
+			KnownBots:    getEnvAsList("REDDIT_KNOWN_BOTS", defaultRedditKnownBots),
+			RequestTimeoutSeconds: getEnvAsInt("REDDIT_REQUEST_TIMEOUT_SECONDS", 10),
+			StaleFallbackSeconds:  getEnvAsInt("REDDIT_STALE_FALLBACK_SECONDS", 0),
+			FilterNSFW:            getEnvAsBool("REDDIT_FILTER_NSFW", false),
 		},
 		JWT: JWTConfig{
 			Secret: getEnv("JWT_SECRET", "dev-secret-change-in-production"),
@@ -95,6 +215,41 @@ func Load() (*Config, error) {
 		Encryption: EncryptionConfig{
 			Key: getEnv("ENCRYPTION_KEY", "dev-encryption-key-change-me!!"),
 		},
+		Themes: ThemesConfig{
+			MaxCSSBytes:        getEnvAsInt("THEME_MAX_CSS_BYTES", DefaultThemeMaxCSSBytes),
+			MaxCSSRules:        getEnvAsInt("THEME_MAX_CSS_RULES", DefaultThemeMaxCSSRules),
+			MaxCSSVariables:    getEnvAsInt("THEME_MAX_CSS_VARIABLES", DefaultThemeMaxCSSVariables),
+			PublicMaxCSSBytes:  getEnvAsInt("THEME_PUBLIC_MAX_CSS_BYTES", DefaultThemePublicMaxCSSBytes),
+			PublicMaxCSSRules:  getEnvAsInt("THEME_PUBLIC_MAX_CSS_RULES", DefaultThemePublicMaxCSSRules),
+			PublicMaxVariables: getEnvAsInt("THEME_PUBLIC_MAX_CSS_VARIABLES", DefaultThemePublicMaxVariables),
+		},
+		Digest: DigestConfig{
+			Enabled:        getEnvAsBool("DIGEST_ENABLED", false),
+			InactiveDays:   getEnvAsInt("DIGEST_INACTIVE_DAYS", 7),
+			CadenceHours:   getEnvAsInt("DIGEST_CADENCE_HOURS", 168),
+			UnsubscribeURL: getEnv("DIGEST_UNSUBSCRIBE_URL", "http://localhost:8080/api/v1/digest/unsubscribe"),
+		},
+		Media: MediaConfig{
+			TranscodeEnabled:        getEnvAsBool("MEDIA_TRANSCODE_ENABLED", false),
+			FFmpegPath:              getEnv("FFMPEG_PATH", "ffmpeg"),
+			TranscodeHLS:            getEnvAsBool("MEDIA_TRANSCODE_HLS", false),
+			TranscodeWorkers:        getEnvAsInt("MEDIA_TRANSCODE_WORKERS", 2),
+			TranscodeTimeoutSeconds: getEnvAsInt("MEDIA_TRANSCODE_TIMEOUT_SECONDS", 600),
+		},
+		HotScore: HotScoreConfig{
+			IntervalMinutes: getEnvAsInt("HOT_SCORE_INTERVAL_MINUTES", 10),
+			WindowHours:     getEnvAsInt("HOT_SCORE_WINDOW_HOURS", 72),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins: getEnvAsList("ALLOWED_ORIGINS", defaultCORSAllowedOrigins),
+		},
+		WebSocket: WebSocketConfig{
+			PingIntervalSeconds: getEnvAsInt("WS_PING_INTERVAL_SECONDS", 40),
+		},
+		Push: PushConfig{
+			VAPIDPrivateKey: getEnv("VAPID_PRIVATE_KEY", ""),
+			VAPIDSubject:    getEnv("VAPID_SUBJECT", ""),
+		},
 	}
 
 	return cfg, nil
@@ -148,3 +303,18 @@ func getEnvAsInt(key string, defaultValue int) int {
 	}
 	return value
 }
+
+// getEnvAsList reads a comma-separated environment variable or returns a default value
+func getEnvAsList(key string, defaultValue []string) []string {
+	valueStr := getEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+	var values []string
+	for _, item := range strings.Split(valueStr, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
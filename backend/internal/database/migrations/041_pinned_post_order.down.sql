@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_posts_pinned;
+CREATE INDEX idx_posts_pinned ON platform_posts(hub_id, is_pinned) WHERE is_pinned = TRUE;
+
+ALTER TABLE platform_posts
+    DROP COLUMN IF EXISTS pinned_at,
+    DROP COLUMN IF EXISTS pin_order;
+
+ALTER TABLE hubs
+    DROP COLUMN IF EXISTS max_pinned_posts;
@@ -0,0 +1,20 @@
+-- Per-hub pinned post cap and explicit ordering among pinned posts
+ALTER TABLE hubs
+    ADD COLUMN IF NOT EXISTS max_pinned_posts INTEGER NOT NULL DEFAULT 2 CHECK (max_pinned_posts BETWEEN 1 AND 10);
+
+ALTER TABLE platform_posts
+    ADD COLUMN IF NOT EXISTS pin_order INTEGER,
+    ADD COLUMN IF NOT EXISTS pinned_at TIMESTAMPTZ;
+
+-- Backfill ordering for posts pinned before this migration (oldest pin first)
+UPDATE platform_posts p
+SET pin_order = ranked.rn, pinned_at = p.created_at
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY hub_id ORDER BY created_at ASC) AS rn
+    FROM platform_posts
+    WHERE is_pinned = TRUE
+) ranked
+WHERE p.id = ranked.id;
+
+DROP INDEX IF EXISTS idx_posts_pinned;
+CREATE INDEX idx_posts_pinned ON platform_posts(hub_id, pin_order) WHERE is_pinned = TRUE;
@@ -0,0 +1,17 @@
+-- Runtime feature flags with optional gradual rollout
+CREATE TABLE IF NOT EXISTS feature_flags (
+    name VARCHAR(100) PRIMARY KEY,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    rollout_percentage INTEGER NOT NULL DEFAULT 100 CHECK (rollout_percentage BETWEEN 0 AND 100),
+    user_ids INTEGER[] NOT NULL DEFAULT '{}', -- Always enabled for these users when the flag is on
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Existing features gated behind flags start fully enabled
+INSERT INTO feature_flags (name, description, enabled) VALUES
+    ('themes_marketplace', 'Browsing, installing and rating community themes', TRUE),
+    ('slideshow', 'Shared media slideshows in conversations', TRUE)
+ON CONFLICT (name) DO NOTHING;
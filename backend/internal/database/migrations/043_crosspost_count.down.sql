@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_posts_crosspost_origin;
+ALTER TABLE platform_posts DROP COLUMN IF EXISTS crosspost_count;
@@ -0,0 +1,21 @@
+-- Track how many live crossposts point at each platform post
+ALTER TABLE platform_posts
+    ADD COLUMN IF NOT EXISTS crosspost_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_posts_crosspost_origin
+    ON platform_posts(crosspost_origin_post_id)
+    WHERE crosspost_origin_type = 'platform';
+
+-- Backfill from existing crossposts that are neither deleted nor removed
+UPDATE platform_posts origin
+SET crosspost_count = counts.total
+FROM (
+    SELECT crosspost_origin_post_id, COUNT(*) AS total
+    FROM platform_posts
+    WHERE crosspost_origin_type = 'platform'
+      AND crosspost_origin_post_id ~ '^[0-9]+$'
+      AND is_deleted = FALSE
+      AND is_removed = FALSE
+    GROUP BY crosspost_origin_post_id
+) counts
+WHERE origin.id = counts.crosspost_origin_post_id::INTEGER;
@@ -0,0 +1,6 @@
+-- Admin-managed Reddit bot accounts, in addition to the built-in list
+CREATE TABLE IF NOT EXISTS reddit_known_bots (
+    username VARCHAR(50) PRIMARY KEY, -- Stored lowercase
+    added_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,7 @@
+ALTER TABLE user_settings
+DROP COLUMN IF EXISTS digest_unsubscribe_token,
+DROP COLUMN IF EXISTS last_digest_sent_at,
+DROP COLUMN IF EXISTS quiet_hours_end,
+DROP COLUMN IF EXISTS quiet_hours_start;
+
+COMMENT ON COLUMN user_settings.daily_digest IS 'Send daily digest of notifications instead of real-time (future feature)';
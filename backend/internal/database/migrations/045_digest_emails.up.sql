@@ -0,0 +1,12 @@
+-- Digest emails for inactive users who opted in via daily_digest
+ALTER TABLE user_settings
+ADD COLUMN IF NOT EXISTS quiet_hours_start SMALLINT CHECK (quiet_hours_start BETWEEN 0 AND 23),
+ADD COLUMN IF NOT EXISTS quiet_hours_end SMALLINT CHECK (quiet_hours_end BETWEEN 0 AND 23),
+ADD COLUMN IF NOT EXISTS last_digest_sent_at TIMESTAMPTZ,
+ADD COLUMN IF NOT EXISTS digest_unsubscribe_token VARCHAR(64) UNIQUE;
+
+COMMENT ON COLUMN user_settings.quiet_hours_start IS 'UTC hour (0-23) at which quiet hours begin; no emails are sent until quiet_hours_end';
+COMMENT ON COLUMN user_settings.quiet_hours_end IS 'UTC hour (0-23) at which quiet hours end';
+COMMENT ON COLUMN user_settings.last_digest_sent_at IS 'When the last digest email was sent, used to enforce the digest cadence';
+COMMENT ON COLUMN user_settings.digest_unsubscribe_token IS 'Opaque token for the one-click unsubscribe link in digest emails';
+COMMENT ON COLUMN user_settings.daily_digest IS 'Opt in to digest emails summarizing hub activity and unread notifications while inactive';
@@ -0,0 +1,6 @@
+-- Let reporters hear back when a moderator resolves their report
+ALTER TABLE user_settings
+ADD COLUMN notify_report_updates BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMENT ON COLUMN user_settings.notify_report_updates IS 'Notify the user with the outcome (action taken / no action) when a report they filed is resolved';
+COMMENT ON COLUMN notifications.notification_type IS 'Types: post_milestone, post_velocity, comment_milestone, comment_velocity, comment_reply, post_comment, report_action_taken, report_no_action';
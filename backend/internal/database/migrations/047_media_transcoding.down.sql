@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_media_files_storage_url;
+DROP INDEX IF EXISTS idx_media_files_processing;
+
+ALTER TABLE media_files
+DROP COLUMN IF EXISTS processing_error,
+DROP COLUMN IF EXISTS hls_url,
+DROP COLUMN IF EXISTS playable_url,
+DROP COLUMN IF EXISTS processing_status;
@@ -0,0 +1,14 @@
+-- Track async transcoding of uploaded videos into web-friendly renditions
+ALTER TABLE media_files
+ADD COLUMN processing_status VARCHAR(20) NOT NULL DEFAULT 'ready'
+    CHECK (processing_status IN ('processing', 'ready', 'failed')),
+ADD COLUMN playable_url TEXT,
+ADD COLUMN hls_url TEXT,
+ADD COLUMN processing_error TEXT;
+
+CREATE INDEX idx_media_files_processing ON media_files(processing_status) WHERE processing_status = 'processing';
+CREATE INDEX idx_media_files_storage_url ON media_files(storage_url);
+
+COMMENT ON COLUMN media_files.processing_status IS 'processing while a transcode is pending, ready once playable, failed if transcoding gave up';
+COMMENT ON COLUMN media_files.playable_url IS 'H.264/AAC MP4 rendition; clients should prefer it over storage_url when set';
+COMMENT ON COLUMN media_files.hls_url IS 'Optional HLS playlist rendition';
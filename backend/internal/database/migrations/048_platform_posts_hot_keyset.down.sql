@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_platform_posts_all_feed_hot_keyset;
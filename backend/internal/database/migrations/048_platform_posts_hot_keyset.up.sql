@@ -0,0 +1,4 @@
+-- Support keyset pagination of the h/all firehose on (hot_score, id)
+CREATE INDEX IF NOT EXISTS idx_platform_posts_all_feed_hot_keyset
+    ON platform_posts(hot_score DESC, id DESC)
+    WHERE is_deleted = FALSE AND target_subreddit IS NULL;
@@ -0,0 +1,12 @@
+-- Keep the previous title/body of platform posts each time they are edited
+CREATE TABLE post_edit_history (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES platform_posts(id) ON DELETE CASCADE,
+    title TEXT NOT NULL,
+    body TEXT,
+    edited_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_post_edit_history_post ON post_edit_history(post_id, edited_at DESC);
+
+COMMENT ON TABLE post_edit_history IS 'Prior versions of platform posts; edited_at is when the version was replaced';
@@ -0,0 +1,4 @@
+-- Allow senders to edit a message until the recipient has read it
+ALTER TABLE messages
+    ADD COLUMN IF NOT EXISTS is_edited BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS edited_at TIMESTAMP;
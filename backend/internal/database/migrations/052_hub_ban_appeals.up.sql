@@ -0,0 +1,17 @@
+-- Appeals banned users submit to a hub's moderators
+CREATE TABLE IF NOT EXISTS hub_ban_appeals (
+    id SERIAL PRIMARY KEY,
+    ban_id INTEGER REFERENCES hub_bans(id) ON DELETE SET NULL, -- NULL once the ban is lifted
+    hub_id INTEGER NOT NULL REFERENCES hubs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'denied')),
+    resolved_by INTEGER REFERENCES users(id),
+    resolution_note TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    resolved_at TIMESTAMPTZ
+);
+
+-- A ban can only have one open appeal at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_hub_ban_appeals_pending ON hub_ban_appeals(ban_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_hub_ban_appeals_hub ON hub_ban_appeals(hub_id, status, created_at DESC);
@@ -0,0 +1,24 @@
+-- Keyword/regex rules that automatically remove matching posts and comments in a hub
+CREATE TABLE IF NOT EXISTS hub_automod_rules (
+    id SERIAL PRIMARY KEY,
+    hub_id INTEGER NOT NULL REFERENCES hubs(id) ON DELETE CASCADE,
+    pattern TEXT NOT NULL,
+    is_regex BOOLEAN NOT NULL DEFAULT FALSE,
+    action VARCHAR(20) NOT NULL DEFAULT 'remove' CHECK (action IN ('remove')),
+    applies_to VARCHAR(20) NOT NULL DEFAULT 'all' CHECK (applies_to IN ('posts', 'comments', 'all')),
+    created_by INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_hub_automod_rules_hub ON hub_automod_rules(hub_id);
+
+-- Allow logging removals made by automod
+ALTER TABLE mod_logs DROP CONSTRAINT IF EXISTS mod_logs_action_check;
+ALTER TABLE mod_logs ADD CONSTRAINT mod_logs_action_check CHECK (action IN (
+    'ban_user', 'unban_user', 'remove_post', 'approve_post',
+    'remove_comment', 'approve_comment', 'lock_post', 'unlock_post',
+    'pin_post', 'unpin_post', 'add_moderator', 'remove_moderator',
+    'update_removal_reason', 'create_removal_reason', 'delete_removal_reason',
+    'nuke_user', 'automod_remove'
+));
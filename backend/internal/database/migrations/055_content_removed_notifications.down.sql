@@ -0,0 +1,4 @@
+COMMENT ON COLUMN notifications.notification_type IS 'Types: post_milestone, post_velocity, comment_milestone, comment_velocity, comment_reply, post_comment, report_action_taken, report_no_action';
+
+ALTER TABLE user_settings
+DROP COLUMN IF EXISTS notify_content_removed;
@@ -0,0 +1,6 @@
+-- Let authors hear why a moderator removed their post or comment
+ALTER TABLE user_settings
+ADD COLUMN notify_content_removed BOOLEAN NOT NULL DEFAULT TRUE;
+
+COMMENT ON COLUMN user_settings.notify_content_removed IS 'Notify the user with the removal reason when a moderator removes their post or comment';
+COMMENT ON COLUMN notifications.notification_type IS 'Types: post_milestone, post_velocity, comment_milestone, comment_velocity, comment_reply, post_comment, report_action_taken, report_no_action, content_removed';
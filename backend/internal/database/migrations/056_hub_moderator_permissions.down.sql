@@ -0,0 +1,2 @@
+ALTER TABLE hub_moderators DROP CONSTRAINT IF EXISTS hub_moderators_permissions_check;
+ALTER TABLE hub_moderators DROP COLUMN IF EXISTS permissions;
@@ -0,0 +1,11 @@
+-- Scope what each hub moderator may do. Existing moderators keep full powers.
+--   full:   everything below
+--   posts:  remove/approve/lock/pin content, work the mod queue
+--   config: removal reasons and automod rules
+--   access: bans and ban appeals
+ALTER TABLE hub_moderators
+ADD COLUMN IF NOT EXISTS permissions TEXT[] NOT NULL DEFAULT ARRAY['full']::TEXT[];
+
+ALTER TABLE hub_moderators
+ADD CONSTRAINT hub_moderators_permissions_check
+CHECK (permissions <@ ARRAY['full', 'posts', 'config', 'access']::TEXT[]);
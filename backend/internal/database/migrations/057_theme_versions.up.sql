@@ -0,0 +1,11 @@
+-- History of theme versions, one row per bump with an optional changelog note
+CREATE TABLE IF NOT EXISTS theme_versions (
+    id SERIAL PRIMARY KEY,
+    theme_id INTEGER NOT NULL REFERENCES user_themes(id) ON DELETE CASCADE,
+    version VARCHAR(20) NOT NULL,
+    changelog TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE(theme_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_theme_versions_theme ON theme_versions(theme_id, created_at DESC);
@@ -0,0 +1,2 @@
+ALTER TABLE user_themes DROP CONSTRAINT IF EXISTS user_themes_paired_theme_check;
+ALTER TABLE user_themes DROP COLUMN IF EXISTS paired_theme_id;
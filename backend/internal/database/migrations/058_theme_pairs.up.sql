@@ -0,0 +1,11 @@
+-- Links a theme to its light/dark counterpart so clients can follow the OS color scheme
+ALTER TABLE user_themes
+    ADD COLUMN IF NOT EXISTS paired_theme_id INTEGER REFERENCES user_themes(id) ON DELETE SET NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'user_themes_paired_theme_check') THEN
+        ALTER TABLE user_themes
+            ADD CONSTRAINT user_themes_paired_theme_check CHECK (paired_theme_id IS NULL OR paired_theme_id <> id);
+    END IF;
+END $$;
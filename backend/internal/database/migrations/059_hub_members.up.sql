@@ -0,0 +1,19 @@
+-- Invite list for private hubs. Only the creator, moderators and members can
+-- read or post in a private hub.
+CREATE TABLE IF NOT EXISTS hub_members (
+    hub_id INTEGER NOT NULL REFERENCES hubs(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    added_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (hub_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_hub_members_user ON hub_members(user_id);
+
+-- Subscribers were treated as members of private hubs until now; keep their access
+INSERT INTO hub_members (hub_id, user_id, created_at)
+SELECT hs.hub_id, hs.user_id, COALESCE(hs.subscribed_at, NOW())
+FROM hub_subscriptions hs
+JOIN hubs h ON h.id = hs.hub_id
+WHERE h.type = 'private'
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,2 @@
+ALTER TABLE hubs DROP COLUMN IF EXISTS banner_url;
+ALTER TABLE hubs DROP COLUMN IF EXISTS sidebar_md;
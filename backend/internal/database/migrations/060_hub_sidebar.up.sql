@@ -0,0 +1,3 @@
+-- Sidebar text and banner image editable by a hub's owner and moderators
+ALTER TABLE hubs ADD COLUMN IF NOT EXISTS sidebar_md TEXT;
+ALTER TABLE hubs ADD COLUMN IF NOT EXISTS banner_url TEXT;
@@ -0,0 +1 @@
+ALTER TABLE hub_moderators DROP COLUMN IF EXISTS added_at;
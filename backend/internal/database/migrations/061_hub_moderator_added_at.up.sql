@@ -0,0 +1,2 @@
+-- Record when each moderator was added. Existing moderators get the migration time.
+ALTER TABLE hub_moderators ADD COLUMN IF NOT EXISTS added_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
@@ -0,0 +1,9 @@
+COMMENT ON COLUMN notifications.notification_type IS 'Types: post_milestone, post_velocity, comment_milestone, comment_velocity, comment_reply, post_comment, report_action_taken, report_no_action, content_removed';
+
+DROP INDEX IF EXISTS idx_notification_batches_digest;
+
+ALTER TABLE notification_batches
+DROP COLUMN IF EXISTS cadence;
+
+ALTER TABLE user_settings
+DROP COLUMN IF EXISTS notification_cadence;
@@ -0,0 +1,16 @@
+-- Let users collect trending (velocity) notifications into an hourly or daily digest
+ALTER TABLE user_settings
+ADD COLUMN notification_cadence VARCHAR(10) NOT NULL DEFAULT 'immediate'
+CHECK (notification_cadence IN ('immediate', 'hourly', 'daily'));
+
+-- Digest batches record the cadence they were queued under; regular 15-minute batches leave it NULL
+ALTER TABLE notification_batches
+ADD COLUMN cadence VARCHAR(10);
+
+CREATE INDEX idx_notification_batches_digest
+    ON notification_batches(cadence, scheduled_for)
+    WHERE status = 'pending' AND cadence IS NOT NULL;
+
+COMMENT ON COLUMN user_settings.notification_cadence IS 'How often batchable notifications are delivered: immediate, hourly or daily digest';
+COMMENT ON COLUMN notification_batches.cadence IS 'Digest cadence (hourly, daily) the batch is waiting for; NULL for regular batches';
+COMMENT ON COLUMN notifications.notification_type IS 'Types: post_milestone, post_velocity, comment_milestone, comment_velocity, comment_reply, post_comment, report_action_taken, report_no_action, content_removed, notification_digest';
@@ -0,0 +1,6 @@
+-- Per-category mutes (reply, mention, mod_action, vote -> enabled). Categories
+-- missing from the object are enabled, so the empty default means all-on.
+ALTER TABLE user_settings
+ADD COLUMN notification_preferences JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+COMMENT ON COLUMN user_settings.notification_preferences IS 'Notification category -> enabled; missing categories are enabled';
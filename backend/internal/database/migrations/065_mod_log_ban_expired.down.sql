@@ -0,0 +1,9 @@
+DELETE FROM mod_logs WHERE action = 'ban_expired';
+ALTER TABLE mod_logs DROP CONSTRAINT IF EXISTS mod_logs_action_check;
+ALTER TABLE mod_logs ADD CONSTRAINT mod_logs_action_check CHECK (action IN (
+    'ban_user', 'unban_user', 'remove_post', 'approve_post',
+    'remove_comment', 'approve_comment', 'lock_post', 'unlock_post',
+    'pin_post', 'unpin_post', 'add_moderator', 'remove_moderator',
+    'update_removal_reason', 'create_removal_reason', 'delete_removal_reason',
+    'nuke_user', 'automod_remove'
+));
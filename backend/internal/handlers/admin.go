@@ -7,6 +7,7 @@ import (
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
 	"github.com/gin-gonic/gin"
 )
 
@@ -15,6 +16,7 @@ type AdminHandler struct {
 	userRepo   *models.UserRepository
 	hubModRepo *models.HubModeratorRepository
 	pool       *pgxpool.Pool
+	cache      services.Cache
 }
 
 // NewAdminHandler creates a new admin handler
@@ -26,6 +28,11 @@ func NewAdminHandler(userRepo *models.UserRepository, hubModRepo *models.HubMode
 	}
 }
 
+// SetCache lets moderator changes invalidate cached hub moderator lists (called after initialization)
+func (h *AdminHandler) SetCache(cache services.Cache) {
+	h.cache = cache
+}
+
 // PromoteUser handles POST /api/v1/admin/users/:id/role
 func (h *AdminHandler) PromoteUser(c *gin.Context) {
 	targetID, err := strconv.Atoi(c.Param("id"))
@@ -264,9 +271,10 @@ func (h *AdminHandler) RemoveHubModerator(c *gin.Context) {
 	}
 
 	if err := h.hubModRepo.RemoveModerator(c.Request.Context(), hubID, userID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove moderator", "details": err.Error()})
+		respondRemoveModeratorError(c, err)
 		return
 	}
+	invalidateModeratorsCache(c.Request.Context(), h.cache, hubID)
 
 	c.JSON(http.StatusOK, gin.H{"message": "Moderator removed"})
 }
@@ -12,8 +12,9 @@ import (
 
 // AuthHandler handles authentication endpoints
 type AuthHandler struct {
-	authService *services.AuthService
-	userRepo    *models.UserRepository
+	authService  *services.AuthService
+	userRepo     *models.UserRepository
+	redditClient *services.RedditClient
 }
 
 // NewAuthHandler creates a new auth handler
@@ -24,6 +25,12 @@ func NewAuthHandler(authService *services.AuthService, userRepo *models.UserRepo
 	}
 }
 
+// SetRedditClient lets the Reddit callback hand the user's OAuth token to the
+// Reddit client so their personalized listings can be fetched.
+func (h *AuthHandler) SetRedditClient(client *services.RedditClient) {
+	h.redditClient = client
+}
+
 // RedditLogin initiates the Reddit OAuth flow
 func (h *AuthHandler) RedditLogin(c *gin.Context) {
 	state, err := h.authService.GenerateState()
@@ -99,6 +106,9 @@ func (h *AuthHandler) RedditCallback(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create/update user: " + err.Error()})
 		return
 	}
+	if h.redditClient != nil {
+		h.redditClient.SetUserToken(user.ID, token.AccessToken, token.Expiry)
+	}
 
 	// Generate JWT
 	redditID := ""
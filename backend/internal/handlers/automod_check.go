@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"context"
+	"log"
+
+	"github.com/omninudge/backend/internal/models"
+)
+
+// applyAutomod checks freshly created content against the hub's automod rules
+// and, on a match, removes it and logs automod_remove on behalf of the rule's
+// creator. appliesTo is "posts" or "comments"; remove marks the content removed.
+// The content already exists, so failures are logged rather than returned.
+// A nil repository disables automod. Returns whether the content was removed.
+func applyAutomod(
+	ctx context.Context,
+	automod *models.AutomodRepository,
+	modLogs *models.ModLogRepository,
+	hubID int,
+	appliesTo, targetType string,
+	targetID int,
+	content string,
+	remove func(ctx context.Context, id, moderatorID int) error,
+) bool {
+	if automod == nil {
+		return false
+	}
+
+	rules, err := automod.GetByHub(ctx, hubID, appliesTo)
+	if err != nil {
+		log.Printf("Failed to load automod rules for hub %d: %v", hubID, err)
+		return false
+	}
+
+	matched, ruleID := models.AutomodRules(rules).Evaluate(content)
+	if !matched {
+		return false
+	}
+
+	var rule *models.AutomodRule
+	for _, candidate := range rules {
+		if candidate.ID == ruleID {
+			rule = candidate
+			break
+		}
+	}
+
+	if err := remove(ctx, targetID, rule.CreatedBy); err != nil {
+		log.Printf("Failed to automod-remove %s %d in hub %d: %v", targetType, targetID, hubID, err)
+		return false
+	}
+
+	if modLogs != nil {
+		_, _ = modLogs.Log(ctx, hubID, rule.CreatedBy, "automod_remove", targetType, targetID, models.JSONB{
+			"rule_id": rule.ID,
+			"pattern": rule.Pattern,
+		})
+	}
+	return true
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomod_RemovesMatchingPostsAndComments(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	modLogRepo := models.NewModLogRepository(db.Pool)
+	automodRepo := models.NewAutomodRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("automodmod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	user := &models.User{Username: fmt.Sprintf("automoduser_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hub := &models.Hub{Name: fmt.Sprintf("automodhub_%d", suffix), ContentOptions: "any", CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	moderationHandler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		modLogRepo,
+		modRepo,
+		postRepo,
+		commentRepo,
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	moderationHandler.SetAutomodRepository(automodRepo)
+	postsHandler := NewPostsHandler(postRepo, hubRepo, userRepo, modRepo, models.NewFeedRepository(db.Pool))
+	postsHandler.SetAutomod(automodRepo, modLogRepo)
+	commentsHandler := NewCommentsHandler(commentRepo, postRepo, modRepo)
+	commentsHandler.SetAutomod(automodRepo, modLogRepo)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/mod/hubs/:hub_name/automod", authMiddleware(mod.ID), moderationHandler.CreateAutomodRule)
+	router.POST("/posts", authMiddleware(user.ID), postsHandler.CreatePost)
+	router.POST("/posts/:id/comments", authMiddleware(user.ID), commentsHandler.CreateComment)
+
+	post := func(path string, payload interface{}) *httptest.ResponseRecorder {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	createdID := func(w *httptest.ResponseRecorder) int {
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var created struct {
+			ID int `json:"id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		return created.ID
+	}
+	isRemoved := func(table string, id int) bool {
+		var removed bool
+		require.NoError(t, db.Pool.QueryRow(ctx, fmt.Sprintf(`SELECT is_removed FROM %s WHERE id = $1`, table), id).Scan(&removed))
+		return removed
+	}
+
+	rulesPath := fmt.Sprintf("/mod/hubs/%s/automod", hub.Name)
+	w := post(rulesPath, gin.H{"pattern": "(unclosed", "is_regex": true})
+	assert.Equal(t, http.StatusBadRequest, w.Code, "invalid regex should be rejected at create time")
+
+	createdID(post(rulesPath, gin.H{"pattern": "cheap pills", "applies_to": "posts"}))
+	createdID(post(rulesPath, gin.H{"pattern": `https?://spam\.example`, "is_regex": true}))
+
+	w = post("/posts", gin.H{"title": "Cheap Pills today", "hub_id": hub.ID, "post_type": "text"})
+	spamPost := createdID(w)
+	assert.Contains(t, w.Body.String(), `"is_removed":true`, "the response reflects the automod removal")
+	cleanPost := createdID(post("/posts", gin.H{"title": "Weekly discussion", "hub_id": hub.ID, "post_type": "text"}))
+
+	// The literal rule only applies to posts
+	pillsComment := createdID(post(fmt.Sprintf("/posts/%d/comments", cleanPost), gin.H{"body": "cheap pills"}))
+	linkComment := createdID(post(fmt.Sprintf("/posts/%d/comments", cleanPost), gin.H{"body": "visit http://spam.example now"}))
+
+	assert.True(t, isRemoved("platform_posts", spamPost))
+	assert.False(t, isRemoved("platform_posts", cleanPost))
+	assert.False(t, isRemoved("post_comments", pillsComment))
+	assert.True(t, isRemoved("post_comments", linkComment))
+
+	logs, err := modLogRepo.GetByHubFiltered(ctx, hub.ID, "automod_remove", nil, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	for _, log := range logs {
+		assert.Equal(t, mod.ID, log.ModeratorID, "automod removals are attributed to the rule's creator")
+	}
+}
@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type batchLookupTestEnv struct {
+	db          *database.DB
+	postRepo    *models.PlatformPostRepository
+	commentRepo *models.PostCommentRepository
+	author      *models.User
+	viewer      *models.User
+	publicHub   *models.Hub
+	privateHub  *models.Hub
+}
+
+func setupBatchLookupTest(t *testing.T) (*batchLookupTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	suffix := time.Now().UnixNano()
+
+	env := &batchLookupTestEnv{
+		db:          db,
+		postRepo:    models.NewPlatformPostRepository(db.Pool),
+		commentRepo: models.NewPostCommentRepository(db.Pool),
+		author:      &models.User{Username: fmt.Sprintf("batch_author_%d", suffix), PasswordHash: "hash"},
+		viewer:      &models.User{Username: fmt.Sprintf("batch_viewer_%d", suffix), PasswordHash: "hash"},
+	}
+	require.NoError(t, userRepo.Create(ctx, env.author))
+	require.NoError(t, userRepo.Create(ctx, env.viewer))
+
+	env.publicHub = &models.Hub{Name: fmt.Sprintf("batch_public_%d", suffix), Type: "public", CreatedBy: &env.author.ID}
+	env.privateHub = &models.Hub{Name: fmt.Sprintf("batch_private_%d", suffix), Type: "private", CreatedBy: &env.author.ID}
+	require.NoError(t, hubRepo.Create(ctx, env.publicHub))
+	require.NoError(t, hubRepo.Create(ctx, env.privateHub))
+
+	gin.SetMode(gin.TestMode)
+	return env, func() { db.Close() }
+}
+
+func (env *batchLookupTestEnv) createPost(t *testing.T, hub *models.Hub, title string) *models.PlatformPost {
+	t.Helper()
+	post := &models.PlatformPost{AuthorID: env.author.ID, HubID: &hub.ID, Title: title}
+	require.NoError(t, env.postRepo.Create(context.Background(), post))
+	return post
+}
+
+func (env *batchLookupTestEnv) createComment(t *testing.T, postID int, body string) *models.PostComment {
+	t.Helper()
+	comment := &models.PostComment{PostID: postID, UserID: env.author.ID, Body: body}
+	require.NoError(t, env.commentRepo.Create(context.Background(), comment))
+	return comment
+}
+
+// get requests path as the viewer and decodes the response into out
+func (env *batchLookupTestEnv) get(t *testing.T, register func(*gin.Engine), path string, out interface{}) int {
+	t.Helper()
+	router := gin.New()
+	router.Use(authMiddleware(env.viewer.ID))
+	register(router)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	if w.Code == http.StatusOK && out != nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), out))
+	}
+	return w.Code
+}
+
+func TestGetPostsByIDs_ReturnsVisibleSubsetWithVotes(t *testing.T) {
+	env, cleanup := setupBatchLookupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	voted := env.createPost(t, env.publicHub, "voted")
+	plain := env.createPost(t, env.publicHub, "plain")
+	removed := env.createPost(t, env.publicHub, "removed")
+	private := env.createPost(t, env.privateHub, "private")
+	nsfw := env.createPost(t, env.publicHub, "nsfw")
+
+	up := true
+	require.NoError(t, env.postRepo.Vote(ctx, voted.ID, env.viewer.ID, &up))
+	require.NoError(t, env.postRepo.MarkAsRemoved(ctx, removed.ID, env.author.ID))
+	_, err := env.db.Pool.Exec(ctx, `UPDATE platform_posts SET nsfw = TRUE WHERE id = $1`, nsfw.ID)
+	require.NoError(t, err)
+
+	handler := NewPostsHandler(env.postRepo, nil, nil, nil, nil)
+	register := func(r *gin.Engine) { r.GET("/posts", handler.GetPostsByIDs) }
+
+	var response struct {
+		Posts []models.PlatformPost `json:"posts"`
+	}
+	path := fmt.Sprintf("/posts?ids=%d,%d,%d,%d,%d,999999999", plain.ID, removed.ID, voted.ID, private.ID, nsfw.ID)
+	require.Equal(t, http.StatusOK, env.get(t, register, path, &response))
+
+	require.Len(t, response.Posts, 2)
+	assert.Equal(t, plain.ID, response.Posts[0].ID, "requested order is kept")
+	assert.Nil(t, response.Posts[0].UserVote)
+	assert.Equal(t, voted.ID, response.Posts[1].ID)
+	require.NotNil(t, response.Posts[1].UserVote)
+	assert.Equal(t, 1, *response.Posts[1].UserVote)
+	assert.Equal(t, env.publicHub.Name, response.Posts[1].HubName)
+
+	// NSFW posts are included on request, and members see private hub posts
+	require.NoError(t, models.NewHubMemberRepository(env.db.Pool).AddMember(ctx, env.privateHub.ID, env.viewer.ID, env.author.ID))
+	path = fmt.Sprintf("/posts?ids=%d,%d&include_nsfw=true", private.ID, nsfw.ID)
+	require.Equal(t, http.StatusOK, env.get(t, register, path, &response))
+	require.Len(t, response.Posts, 2)
+	assert.Equal(t, private.ID, response.Posts[0].ID)
+	assert.Equal(t, nsfw.ID, response.Posts[1].ID)
+}
+
+func TestGetCommentsByIDs_ReturnsVisibleSubsetWithVotes(t *testing.T) {
+	env, cleanup := setupBatchLookupTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	post := env.createPost(t, env.publicHub, "thread")
+	privatePost := env.createPost(t, env.privateHub, "private thread")
+	voted := env.createComment(t, post.ID, "voted")
+	removed := env.createComment(t, post.ID, "removed")
+	hidden := env.createComment(t, privatePost.ID, "hidden")
+
+	down := false
+	require.NoError(t, env.commentRepo.Vote(ctx, voted.ID, env.viewer.ID, &down))
+	require.NoError(t, env.commentRepo.MarkAsRemoved(ctx, removed.ID, env.author.ID))
+
+	handler := NewCommentsHandler(env.commentRepo, env.postRepo, nil)
+	register := func(r *gin.Engine) { r.GET("/comments", handler.GetCommentsByIDs) }
+
+	var response struct {
+		Comments []models.PostComment `json:"comments"`
+	}
+	path := fmt.Sprintf("/comments?ids=%d,%d,%d", hidden.ID, removed.ID, voted.ID)
+	require.Equal(t, http.StatusOK, env.get(t, register, path, &response))
+
+	require.Len(t, response.Comments, 1)
+	assert.Equal(t, voted.ID, response.Comments[0].ID)
+	require.NotNil(t, response.Comments[0].UserVote)
+	assert.Equal(t, -1, *response.Comments[0].UserVote)
+}
+
+func TestBatchLookup_ValidatesIDs(t *testing.T) {
+	handler := NewPostsHandler(nil, nil, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts", handler.GetPostsByIDs)
+
+	tooMany := "1"
+	for i := 2; i <= maxBatchLookupIDs+1; i++ {
+		tooMany += fmt.Sprintf(",%d", i)
+	}
+	for _, query := range []string{"", "ids=", "ids=1,abc", "ids=0", "ids=" + tooMany} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
@@ -1,6 +1,8 @@
 package handlers
 
 import (
+	"errors"
+	"log"
 	"net/http"
 	"strconv"
 
@@ -15,6 +17,9 @@ type CommentsHandler struct {
 	postRepo     *models.PlatformPostRepository
 	modRepo      *models.HubModeratorRepository
 	notifService *services.NotificationService
+	hubBanRepo   *models.HubBanRepository
+	automodRepo  *models.AutomodRepository
+	modLogRepo   *models.ModLogRepository
 }
 
 // NewCommentsHandler creates a new comments handler
@@ -31,6 +36,17 @@ func (h *CommentsHandler) SetNotificationService(notifService *services.Notifica
 	h.notifService = notifService
 }
 
+// SetHubBanRepository enables blocking banned users from commenting in hubs
+func (h *CommentsHandler) SetHubBanRepository(hubBanRepo *models.HubBanRepository) {
+	h.hubBanRepo = hubBanRepo
+}
+
+// SetAutomod enables automod rules on new comments in hub posts
+func (h *CommentsHandler) SetAutomod(automodRepo *models.AutomodRepository, modLogRepo *models.ModLogRepository) {
+	h.automodRepo = automodRepo
+	h.modLogRepo = modLogRepo
+}
+
 // CreateCommentRequest represents the request body for creating a comment
 type CreateCommentRequest struct {
 	Body            string `json:"body" binding:"required,min=1"`
@@ -57,7 +73,10 @@ func (h *CommentsHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
-	// Verify post exists
+	if !requirePostAccess(c, h.postRepo, postID) {
+		return
+	}
+
 	post, err := h.postRepo.GetByID(c.Request.Context(), postID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get post", "details": err.Error()})
@@ -68,15 +87,20 @@ func (h *CommentsHandler) CreateComment(c *gin.Context) {
 		return
 	}
 
+	if post.HubID != nil && rejectIfHubBanned(c, h.hubBanRepo, *post.HubID, userID.(int)) {
+		return
+	}
+
 	var req CreateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
 	// If replying to a comment, verify parent comment exists
+	var parentComment *models.PostComment
 	if req.ParentCommentID != nil {
-		parentComment, err := h.commentRepo.GetByID(c.Request.Context(), *req.ParentCommentID)
+		parentComment, err = h.commentRepo.GetByID(c.Request.Context(), *req.ParentCommentID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get parent comment", "details": err.Error()})
 			return
@@ -110,14 +134,19 @@ func (h *CommentsHandler) CreateComment(c *gin.Context) {
 	comment.Score++
 	comment.Upvotes++
 
-	// Trigger notification for comment reply if parent exists and service is available
-	if h.notifService != nil && req.ParentCommentID != nil {
-		go func() {
-			parentComment, err := h.commentRepo.GetByID(c.Request.Context(), *req.ParentCommentID)
-			if err == nil && parentComment != nil {
-				_ = h.notifService.NotifyCommentReply(c.Request.Context(), comment.ID, parentComment.UserID, userID.(int))
+	removed := post.HubID != nil && applyAutomod(c.Request.Context(), h.automodRepo, h.modLogRepo, *post.HubID, models.AutomodAppliesToComments, "comment", comment.ID, comment.Body, h.commentRepo.MarkAsRemoved)
+
+	// Removed comments notify nobody. The reply notification goes out first so a
+	// parent author who is also mentioned isn't notified twice.
+	if h.notifService != nil && !removed {
+		if parentComment != nil {
+			if err := h.notifService.NotifyCommentReply(c.Request.Context(), comment.ID, parentComment.UserID, userID.(int)); err != nil {
+				log.Printf("Failed to notify reply to comment %d: %v", parentComment.ID, err)
 			}
-		}()
+		}
+		if err := h.notifService.NotifyMentions(c.Request.Context(), "comment", comment.ID, userID.(int), comment.Body); err != nil {
+			log.Printf("Failed to notify mentions in comment %d: %v", comment.ID, err)
+		}
 	}
 
 	fullComment, err := h.commentRepo.GetByID(c.Request.Context(), comment.ID)
@@ -154,6 +183,17 @@ func (h *CommentsHandler) GetComments(c *gin.Context) {
 		}
 	}
 
+	// Threaded mode pages top-level comments and replies by cursor; the flat
+	// offset listing below is kept for existing clients.
+	if c.Query("cursor") != "" || c.Query("threaded") == "true" {
+		h.getThreadedComments(c, postID, sortBy, limit, userIDPtr)
+		return
+	}
+
+	if !requirePostAccess(c, h.postRepo, postID) {
+		return
+	}
+
 	comments, err := h.commentRepo.GetByPostID(c.Request.Context(), postID, sortBy, limit, offset, userIDPtr)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments", "details": err.Error()})
@@ -190,10 +230,54 @@ func (h *CommentsHandler) GetComment(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
 		return
 	}
+	if !requirePostAccess(c, h.postRepo, comment.PostID) {
+		return
+	}
 
 	c.JSON(http.StatusOK, comment)
 }
 
+// requireCommentAccess writes a 404 (or 500) response and returns false when
+// the comment doesn't exist or the current user can't access its post
+func (h *CommentsHandler) requireCommentAccess(c *gin.Context, commentID int) bool {
+	comment, err := h.commentRepo.GetByID(c.Request.Context(), commentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comment", "details": err.Error()})
+		return false
+	}
+	if comment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return false
+	}
+	return requirePostAccess(c, h.postRepo, comment.PostID)
+}
+
+// GetCommentsByIDs handles GET /api/v1/comments?ids=1,2,3
+// Resolves several comments in one query. Comments the viewer can't see are omitted.
+func (h *CommentsHandler) GetCommentsByIDs(c *gin.Context) {
+	ids, err := parseIDList(c.Query("ids"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
+
+	var userIDPtr *int
+	if userID, ok := c.Get("user_id"); ok {
+		if uid, ok := userID.(int); ok {
+			userIDPtr = &uid
+		}
+	}
+
+	comments, err := h.commentRepo.GetVisibleByIDs(c.Request.Context(), ids, userIDPtr, includeNSFW)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"comments": comments})
+}
+
 // GetCommentReplies handles GET /api/v1/comments/:id/replies
 func (h *CommentsHandler) GetCommentReplies(c *gin.Context) {
 	commentID, err := strconv.Atoi(c.Param("id"))
@@ -219,6 +303,10 @@ func (h *CommentsHandler) GetCommentReplies(c *gin.Context) {
 		}
 	}
 
+	if !h.requireCommentAccess(c, commentID) {
+		return
+	}
+
 	replies, err := h.commentRepo.GetReplies(c.Request.Context(), commentID, sortBy, limit, offset, userIDPtr)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get replies", "details": err.Error()})
@@ -266,11 +354,11 @@ func (h *CommentsHandler) UpdateComment(c *gin.Context) {
 		return
 	}
 
-	// Hub mod check
+	// Hub mod check (comments fall under the posts scope)
 	isHubMod := false
 	if h.modRepo != nil {
 		if post, _ := h.postRepo.GetByID(c.Request.Context(), existingComment.PostID); post != nil && post.HubID != nil {
-			if ok, err := h.modRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int)); err == nil {
+			if ok, err := h.modRepo.HasPermission(c.Request.Context(), *post.HubID, userID.(int), models.ModPermPosts); err == nil {
 				isHubMod = ok
 			}
 		}
@@ -284,7 +372,7 @@ func (h *CommentsHandler) UpdateComment(c *gin.Context) {
 
 	var req UpdateCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -328,11 +416,11 @@ func (h *CommentsHandler) DeleteComment(c *gin.Context) {
 		return
 	}
 
-	// Hub mod check
+	// Hub mod check (comments fall under the posts scope)
 	isHubMod := false
 	if h.modRepo != nil {
 		if post, _ := h.postRepo.GetByID(c.Request.Context(), existingComment.PostID); post != nil && post.HubID != nil {
-			if ok, err := h.modRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int)); err == nil {
+			if ok, err := h.modRepo.HasPermission(c.Request.Context(), *post.HubID, userID.(int), models.ModPermPosts); err == nil {
 				isHubMod = ok
 			}
 		}
@@ -394,7 +482,7 @@ func (h *CommentsHandler) UpdateCommentPreferences(c *gin.Context) {
 
 	var req UpdateCommentPreferencesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -425,11 +513,20 @@ func (h *CommentsHandler) VoteComment(c *gin.Context) {
 		IsUpvote *bool `json:"is_upvote"` // true=upvote, false=downvote, null=remove
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
-	if err := h.commentRepo.Vote(c.Request.Context(), commentID, userID.(int), req.IsUpvote); err != nil {
+	if !h.requireCommentAccess(c, commentID) {
+		return
+	}
+
+	result, err := h.commentRepo.VoteWithResult(c.Request.Context(), commentID, userID.(int), req.IsUpvote)
+	if errors.Is(err, models.ErrVoteTargetNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to vote on comment", "details": err.Error()})
 		return
 	}
@@ -440,11 +537,20 @@ func (h *CommentsHandler) VoteComment(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get updated comment", "details": err.Error()})
 		return
 	}
+	if comment == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Comment not found"})
+		return
+	}
+
+	// Report the counts from the vote transaction rather than the re-read,
+	// which may already include other users' concurrent votes
+	comment.Score, comment.Upvotes, comment.Downvotes = result.Score, result.Upvotes, result.Downvotes
+	comment.UserVote = &result.UserVote
 
 	// Trigger notification check if this was an upvote and service is available
 	if h.notifService != nil && req.IsUpvote != nil && *req.IsUpvote {
 		go func() {
-			_ = h.notifService.CheckAndNotifyVote(c.Request.Context(), "comment", commentID, comment.UserID, comment.Upvotes)
+			_ = h.notifService.CheckAndNotifyVote(c.Request.Context(), "comment", commentID, comment.UserID, result.Upvotes)
 		}()
 	}
 
@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+)
+
+const (
+	defaultThreadChildLimit = 3
+	maxThreadChildLimit     = 20
+	defaultThreadDepth      = 2
+	// maxThreadDepth matches the depth cap applied when comments are created
+	maxThreadDepth = 10
+)
+
+// CommentNode is a comment in a threaded response. Replies holds the first page
+// of its children; when more exist, ChildrenCursor loads the rest through the
+// same comments endpoint. A comment past the depth limit has no inline replies,
+// only ReplyCount and ChildrenCursor, so the client can expand it on demand.
+type CommentNode struct {
+	*models.PostComment
+	ReplyCount     int            `json:"reply_count"`
+	Replies        []*CommentNode `json:"replies,omitempty"`
+	ChildrenCursor string         `json:"children_cursor,omitempty"`
+}
+
+// getThreadedComments serves the cursor-paginated form of GET /posts/:id/comments.
+// Without a cursor it returns the first page of top-level comments; with one it
+// returns the next page of siblings under that cursor's parent, so the same call
+// loads more top-level comments or more replies to a single comment.
+func (h *CommentsHandler) getThreadedComments(c *gin.Context, postID int, sortBy string, limit int, userID *int) {
+	cursor := &models.CommentCursor{Sort: models.NormalizeCommentSort(sortBy)}
+	if raw := c.Query("cursor"); raw != "" {
+		decoded, err := models.DecodeCommentCursor(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
+		cursor = decoded
+	}
+
+	childLimit, _ := strconv.Atoi(c.DefaultQuery("child_limit", strconv.Itoa(defaultThreadChildLimit)))
+	if childLimit < 0 || childLimit > maxThreadChildLimit {
+		childLimit = defaultThreadChildLimit
+	}
+	depth, _ := strconv.Atoi(c.DefaultQuery("depth", strconv.Itoa(defaultThreadDepth)))
+	if depth < 0 || depth > maxThreadDepth {
+		depth = defaultThreadDepth
+	}
+
+	if !requirePostAccess(c, h.postRepo, postID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	page, err := h.commentRepo.GetSiblingPage(ctx, postID, cursor, limit+1, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get comments", "details": err.Error()})
+		return
+	}
+
+	var nextCursor *string
+	if len(page) > limit {
+		page = page[:limit]
+		encoded := models.CursorAfter(page[len(page)-1], cursor.Sort).Encode()
+		nextCursor = &encoded
+	}
+
+	nodes := make([]*CommentNode, 0, len(page))
+	for _, comment := range page {
+		nodes = append(nodes, &CommentNode{PostComment: comment})
+	}
+	if err := h.expandCommentNodes(ctx, nodes, cursor.Sort, childLimit, depth, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get replies", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comments":    nodes,
+		"next_cursor": nextCursor,
+		"parent_id":   cursor.ParentID,
+		"limit":       limit,
+		"child_limit": childLimit,
+		"depth":       depth,
+		"sort":        cursor.Sort,
+	})
+}
+
+// expandCommentNodes attaches up to childLimit replies to each node, level by
+// level, for depth levels. Each level costs two queries regardless of how many
+// comments it holds.
+func (h *CommentsHandler) expandCommentNodes(ctx context.Context, nodes []*CommentNode, sortBy string, childLimit, depth int, userID *int) error {
+	for level := 0; len(nodes) > 0; level++ {
+		ids := make([]int, 0, len(nodes))
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+		}
+		counts, err := h.commentRepo.CountVisibleReplies(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		var parentIDs []int
+		for _, node := range nodes {
+			node.ReplyCount = counts[node.ID]
+			if node.ReplyCount > 0 {
+				parentIDs = append(parentIDs, node.ID)
+			}
+		}
+
+		replies := map[int][]*models.PostComment{}
+		if level < depth {
+			replies, err = h.commentRepo.GetFirstRepliesBatch(ctx, parentIDs, sortBy, childLimit, userID)
+			if err != nil {
+				return err
+			}
+		}
+
+		var next []*CommentNode
+		for _, node := range nodes {
+			for _, reply := range replies[node.ID] {
+				child := &CommentNode{PostComment: reply}
+				node.Replies = append(node.Replies, child)
+				next = append(next, child)
+			}
+			if node.ReplyCount > len(node.Replies) {
+				parentID := node.ID
+				childCursor := &models.CommentCursor{ParentID: &parentID, Sort: models.NormalizeCommentSort(sortBy)}
+				if n := len(node.Replies); n > 0 {
+					childCursor = models.CursorAfter(node.Replies[n-1].PostComment, sortBy)
+				}
+				node.ChildrenCursor = childCursor.Encode()
+			}
+		}
+		nodes = next
+	}
+	return nil
+}
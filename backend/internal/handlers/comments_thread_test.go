@@ -0,0 +1,204 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type threadTestEnv struct {
+	commentRepo *models.PostCommentRepository
+	router      *gin.Engine
+	userID      int
+	postID      int
+}
+
+type threadResponse struct {
+	Comments   []threadNode `json:"comments"`
+	NextCursor *string      `json:"next_cursor"`
+}
+
+type threadNode struct {
+	ID             int          `json:"id"`
+	ReplyCount     int          `json:"reply_count"`
+	Replies        []threadNode `json:"replies"`
+	ChildrenCursor string       `json:"children_cursor"`
+}
+
+func setupThreadTest(t *testing.T) (*threadTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	user := &models.User{Username: fmt.Sprintf("threader_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hub := &models.Hub{Name: fmt.Sprintf("threadhub_%d", suffix), CreatedBy: &user.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	post := &models.PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "Megathread"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	handler := NewCommentsHandler(commentRepo, postRepo, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts/:id/comments", handler.GetComments)
+
+	env := &threadTestEnv{commentRepo: commentRepo, router: router, userID: user.ID, postID: post.ID}
+	return env, func() { db.Close() }
+}
+
+func (env *threadTestEnv) comment(t *testing.T, parentID *int, body string) *models.PostComment {
+	t.Helper()
+	comment := &models.PostComment{PostID: env.postID, UserID: env.userID, ParentCommentID: parentID, Body: body}
+	require.NoError(t, env.commentRepo.Create(context.Background(), comment))
+	return comment
+}
+
+func (env *threadTestEnv) fetch(t *testing.T, query url.Values) threadResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	path := fmt.Sprintf("/posts/%d/comments?%s", env.postID, query.Encode())
+	env.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response threadResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func TestGetComments_SiblingPagesReturnEachTopLevelCommentOnce(t *testing.T) {
+	env, cleanup := setupThreadTest(t)
+	defer cleanup()
+
+	var created []int
+	for i := 0; i < 7; i++ {
+		top := env.comment(t, nil, fmt.Sprintf("top %d", i))
+		created = append(created, top.ID)
+		env.comment(t, &top.ID, "reply")
+	}
+
+	seen := map[int]int{}
+	query := url.Values{"threaded": {"true"}, "limit": {"3"}, "sort": {"new"}}
+	pages := 0
+	for {
+		page := env.fetch(t, query)
+		pages++
+		for _, node := range page.Comments {
+			seen[node.ID]++
+			assert.Len(t, node.Replies, 1, "replies are inlined but never listed as top-level")
+		}
+		if page.NextCursor == nil {
+			break
+		}
+		query = url.Values{"cursor": {*page.NextCursor}, "limit": {"3"}}
+	}
+
+	assert.Equal(t, 3, pages)
+	require.Len(t, seen, len(created))
+	for _, id := range created {
+		assert.Equal(t, 1, seen[id], "comment %d", id)
+	}
+}
+
+func TestGetComments_ChildrenFetchedIndependentlyViaChildCursor(t *testing.T) {
+	env, cleanup := setupThreadTest(t)
+	defer cleanup()
+
+	parent := env.comment(t, nil, "busy parent")
+	var replyIDs []int
+	for i := 0; i < 5; i++ {
+		replyIDs = append(replyIDs, env.comment(t, &parent.ID, fmt.Sprintf("reply %d", i)).ID)
+	}
+	quiet := env.comment(t, nil, "quiet parent")
+
+	page := env.fetch(t, url.Values{"threaded": {"true"}, "sort": {"old"}, "child_limit": {"2"}})
+	require.Len(t, page.Comments, 2)
+	busy := page.Comments[0]
+	require.Equal(t, parent.ID, busy.ID)
+	assert.Equal(t, 5, busy.ReplyCount)
+	assert.Equal(t, []int{replyIDs[0], replyIDs[1]}, []int{busy.Replies[0].ID, busy.Replies[1].ID})
+	require.NotEmpty(t, busy.ChildrenCursor)
+	assert.Equal(t, quiet.ID, page.Comments[1].ID)
+	assert.Empty(t, page.Comments[1].ChildrenCursor)
+
+	// Following the child cursor loads the remaining replies of that comment only
+	children := env.fetch(t, url.Values{"cursor": {busy.ChildrenCursor}, "limit": {"10"}})
+	var ids []int
+	for _, node := range children.Comments {
+		ids = append(ids, node.ID)
+	}
+	assert.Equal(t, replyIDs[2:], ids)
+	assert.Nil(t, children.NextCursor)
+}
+
+func TestGetComments_DepthLimitCollapsesDeeperReplies(t *testing.T) {
+	env, cleanup := setupThreadTest(t)
+	defer cleanup()
+
+	top := env.comment(t, nil, "top")
+	child := env.comment(t, &top.ID, "child")
+	grandchild := env.comment(t, &child.ID, "grandchild")
+
+	page := env.fetch(t, url.Values{"threaded": {"true"}, "depth": {"1"}})
+	require.Len(t, page.Comments, 1)
+	require.Len(t, page.Comments[0].Replies, 1)
+
+	collapsed := page.Comments[0].Replies[0]
+	assert.Equal(t, child.ID, collapsed.ID)
+	assert.Equal(t, 1, collapsed.ReplyCount)
+	assert.Empty(t, collapsed.Replies)
+	require.NotEmpty(t, collapsed.ChildrenCursor)
+
+	deeper := env.fetch(t, url.Values{"cursor": {collapsed.ChildrenCursor}})
+	require.Len(t, deeper.Comments, 1)
+	assert.Equal(t, grandchild.ID, deeper.Comments[0].ID)
+}
+
+func TestGetComments_RejectsInvalidCursor(t *testing.T) {
+	handler := NewCommentsHandler(nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts/:id/comments", handler.GetComments)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/1/comments?cursor=not-a-cursor", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCommentCursor_RoundTrip(t *testing.T) {
+	parentID := 42
+	original := models.CursorAfter(&models.PostComment{
+		ID:              7,
+		ParentCommentID: &parentID,
+		Score:           -3,
+		CreatedAt:       time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.UTC),
+	}, "best")
+
+	decoded, err := models.DecodeCommentCursor(original.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, "top", decoded.Sort)
+	assert.Equal(t, parentID, *decoded.ParentID)
+	assert.Equal(t, 7, decoded.ID)
+	assert.Equal(t, -3, decoded.Score)
+	assert.True(t, original.CreatedAt.Equal(decoded.CreatedAt))
+}
@@ -52,7 +52,7 @@ func (h *ConversationsHandler) CreateConversation(c *gin.Context) {
 
 	var req CreateConversationRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -107,6 +107,17 @@ func (h *ConversationsHandler) GetConversations(c *gin.Context) {
 		return
 	}
 
+	// Unread counts for the whole page come from one query
+	convIDs := make([]int, 0, len(conversations))
+	for _, conv := range conversations {
+		convIDs = append(convIDs, conv.ID)
+	}
+	unreadCounts, err := h.messageRepo.UnreadCountsByConversation(c.Request.Context(), userID.(int), convIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get unread counts", "details": err.Error()})
+		return
+	}
+
 	// Enrich conversations with other user info, latest message, and unread count
 	var enriched []*ConversationWithDetails
 	for _, conv := range conversations {
@@ -127,11 +138,7 @@ func (h *ConversationsHandler) GetConversations(c *gin.Context) {
 			details.LatestMessage = latestMsg
 		}
 
-		// Get unread count
-		unreadCount, err := h.messageRepo.GetUnreadCount(c.Request.Context(), conv.ID, userID.(int))
-		if err == nil {
-			details.UnreadCount = unreadCount
-		}
+		details.UnreadCount = unreadCounts[conv.ID]
 
 		enriched = append(enriched, details)
 	}
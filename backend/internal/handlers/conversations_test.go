@@ -293,6 +293,80 @@ func TestGetConversations_WithMessages(t *testing.T) {
 	assert.NotNil(t, convMap["unread_count"])
 }
 
+func TestGetConversations_UnreadCounts(t *testing.T) {
+	handler, db, user1ID, user2ID, cleanup := setupConversationsHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userRepo := models.NewUserRepository(db.Pool)
+	user3 := &models.User{
+		Username:     uniqueConversationsUsername("user3"),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, userRepo.Create(ctx, user3))
+
+	convRepo := models.NewConversationRepository(db.Pool)
+	convA, err := convRepo.Create(ctx, user1ID, user2ID)
+	require.NoError(t, err)
+	convB, err := convRepo.Create(ctx, user1ID, user3.ID)
+	require.NoError(t, err)
+
+	messageRepo := models.NewMessageRepository(db.Pool)
+	send := func(convID, senderID, recipientID int, read bool) {
+		msg := &models.Message{
+			ConversationID:    convID,
+			SenderID:          senderID,
+			RecipientID:       recipientID,
+			EncryptedContent:  "hello",
+			MessageType:       "text",
+			EncryptionVersion: "v1",
+		}
+		require.NoError(t, messageRepo.Create(ctx, msg))
+		if read {
+			require.NoError(t, messageRepo.MarkAsRead(ctx, msg.ID))
+		}
+	}
+
+	// Conversation A: 2 unread + 1 read to user1, plus user1's own unread message to user2
+	send(convA.ID, user2ID, user1ID, false)
+	send(convA.ID, user2ID, user1ID, false)
+	send(convA.ID, user2ID, user1ID, true)
+	send(convA.ID, user1ID, user2ID, false)
+	// Conversation B: everything already read
+	send(convB.ID, user3.ID, user1ID, true)
+
+	counts, err := messageRepo.UnreadCountsByConversation(ctx, user1ID, []int{convA.ID, convB.ID})
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{convA.ID: 2}, counts)
+
+	router := gin.Default()
+	router.GET("/conversations", func(c *gin.Context) {
+		c.Set("user_id", user1ID)
+		handler.GetConversations(c)
+	})
+
+	req := httptest.NewRequest("GET", "/conversations", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Conversations []struct {
+			ID          int `json:"id"`
+			UnreadCount int `json:"unread_count"`
+		} `json:"conversations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Conversations, 2)
+
+	got := make(map[int]int)
+	for _, conv := range response.Conversations {
+		got[conv.ID] = conv.UnreadCount
+	}
+	assert.Equal(t, map[int]int{convA.ID: 2, convB.ID: 0}, got)
+}
+
 func TestGetConversations_Pagination(t *testing.T) {
 	handler, db, user1ID, _, cleanup := setupConversationsHandlerTest(t)
 	defer cleanup()
@@ -0,0 +1,76 @@
+package handlers
+
+import (
+	"html/template"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+)
+
+// DigestHandler handles digest email endpoints.
+type DigestHandler struct {
+	digestRepo *models.DigestRepository
+}
+
+// NewDigestHandler creates a new digest handler
+func NewDigestHandler(digestRepo *models.DigestRepository) *DigestHandler {
+	return &DigestHandler{digestRepo: digestRepo}
+}
+
+var unsubscribeConfirmPage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe from OmniNudge digests</title></head>
+<body>
+<p>Stop receiving OmniNudge digest emails?</p>
+<form method="POST">
+<input type="hidden" name="token" value="{{.}}">
+<button type="submit">Unsubscribe</button>
+</form>
+</body>
+</html>
+`))
+
+// ConfirmUnsubscribe handles GET /api/v1/digest/unsubscribe?token=...
+// It only renders a page that asks the user to confirm, since mail scanners
+// and link previews follow GET links; the form posts back to Unsubscribe.
+func (h *DigestHandler) ConfirmUnsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Status(http.StatusOK)
+	if err := unsubscribeConfirmPage.Execute(c.Writer, token); err != nil {
+		c.Error(err)
+	}
+}
+
+// Unsubscribe handles POST /api/v1/digest/unsubscribe
+// The token comes from the link in each digest email, either in the query
+// (one-click unsubscribe from mail clients) or the confirmation form, so no
+// login is required.
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+
+	ok, err := h.digestRepo.Unsubscribe(c.Request.Context(), token)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Unknown unsubscribe token"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "You have been unsubscribed from digest emails"})
+}
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDigestConfirmUnsubscribe_OnlyRendersConfirmation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	// A nil repository would panic if the GET tried to unsubscribe
+	handler := NewDigestHandler(nil)
+	router := gin.New()
+	router.GET("/digest/unsubscribe", handler.ConfirmUnsubscribe)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/digest/unsubscribe?token=abc%22%3E", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, w.Body.String(), `<form method="POST">`)
+	assert.Contains(t, w.Body.String(), `value="abc&#34;&gt;"`, "the token is escaped")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/digest/unsubscribe", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
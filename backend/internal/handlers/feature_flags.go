@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+)
+
+var featureFlagNamePattern = regexp.MustCompile(`^[a-z0-9_]{1,100}$`)
+
+// FeatureFlagsHandler handles admin management of feature flags
+type FeatureFlagsHandler struct {
+	flagRepo *models.FeatureFlagRepository
+	flags    *services.FeatureFlags
+}
+
+// NewFeatureFlagsHandler creates a new feature flags handler
+func NewFeatureFlagsHandler(flagRepo *models.FeatureFlagRepository, flags *services.FeatureFlags) *FeatureFlagsHandler {
+	return &FeatureFlagsHandler{
+		flagRepo: flagRepo,
+		flags:    flags,
+	}
+}
+
+// ListFlags handles GET /api/v1/admin/feature-flags
+func (h *FeatureFlagsHandler) ListFlags(c *gin.Context) {
+	flags, err := h.flagRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feature flags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"flags": flags})
+}
+
+// SetFlag handles PUT /api/v1/admin/feature-flags/:name
+// Creates the flag if it does not exist yet
+func (h *FeatureFlagsHandler) SetFlag(c *gin.Context) {
+	name := c.Param("name")
+	if !featureFlagNamePattern.MatchString(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Flag names must be 1-100 lowercase letters, digits or underscores"})
+		return
+	}
+
+	var req struct {
+		Enabled           bool    `json:"enabled"`
+		Description       *string `json:"description"`
+		RolloutPercentage *int    `json:"rollout_percentage" binding:"omitempty,min=0,max=100"`
+		UserIDs           []int32 `json:"user_ids"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request", err)
+		return
+	}
+
+	rollout := 100
+	if req.RolloutPercentage != nil {
+		rollout = *req.RolloutPercentage
+	}
+
+	adminID := c.GetInt("user_id")
+	flag, err := h.flagRepo.Upsert(c.Request.Context(), &models.FeatureFlag{
+		Name:              name,
+		Description:       req.Description,
+		Enabled:           req.Enabled,
+		RolloutPercentage: rollout,
+		UserIDs:           req.UserIDs,
+		UpdatedBy:         &adminID,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save feature flag"})
+		return
+	}
+
+	h.flags.Invalidate()
+	c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFlag handles DELETE /api/v1/admin/feature-flags/:name
+// Deleted flags are treated as off by route guards
+func (h *FeatureFlagsHandler) DeleteFlag(c *gin.Context) {
+	name := c.Param("name")
+
+	existing, err := h.flagRepo.GetByName(c.Request.Context(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feature flag"})
+		return
+	}
+	if existing == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Feature flag not found"})
+		return
+	}
+
+	if err := h.flagRepo.Delete(c.Request.Context(), name); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete feature flag"})
+		return
+	}
+
+	h.flags.Invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Feature flag deleted", "name": name})
+}
@@ -18,6 +18,7 @@ type FeedHandler struct {
 	hubSubRepo       *models.HubSubscriptionRepository
 	subredditSubRepo *models.SubredditSubscriptionRepository
 	redditClient     *services.RedditClient
+	mediaRepo        *models.MediaFileRepository
 }
 
 // NewFeedHandler creates a new feed handler
@@ -35,13 +36,44 @@ func NewFeedHandler(
 	}
 }
 
+// SetMediaRepository enables media processing status on hub posts (called after initialization)
+func (h *FeedHandler) SetMediaRepository(mediaRepo *models.MediaFileRepository) {
+	h.mediaRepo = mediaRepo
+}
+
 // CombinedFeedItem represents a post in the combined feed
 type CombinedFeedItem struct {
-	Source string      `json:"source"` // "hub" or "reddit"
-	Post   interface{} `json:"post"`
-	Score  int         `json:"score"`
+	Source     string          `json:"source"` // "hub" or "reddit"
+	Post       interface{}     `json:"post"`
+	Score      int             `json:"score"`
+	Provenance *FeedProvenance `json:"provenance,omitempty"` // Only set when include_provenance=true
+}
+
+// Feed provenance reasons explain why an item appears in the home feed
+const (
+	ProvenanceSubscribedHub       = "subscribed_hub"
+	ProvenanceSubscribedSubreddit = "subscribed_subreddit"
+	ProvenancePopular             = "popular"
+)
+
+// FeedProvenance describes why a feed item was included and which
+// subscription it came from
+type FeedProvenance struct {
+	Reason    string  `json:"reason"`
+	HubID     *int    `json:"hub_id,omitempty"`
+	HubName   string  `json:"hub_name,omitempty"`
+	Subreddit *string `json:"subreddit,omitempty"`
+}
+
+// feedReasons records the reason each source of posts was fetched for,
+// so provenance can be attached during the merge without extra queries
+type feedReasons struct {
+	hub    string
+	reddit string
 }
 
+var popularFeedReasons = feedReasons{hub: ProvenancePopular, reddit: ProvenancePopular}
+
 // GetHomeFeed returns combined hub + Reddit posts
 // If authenticated: returns posts from subscribed hubs + subscribed subreddits
 // If unauthenticated: returns popular posts from all hubs + r/popular
@@ -76,11 +108,19 @@ func (h *FeedHandler) GetHomeFeed(c *gin.Context) {
 		redditTimeFilter = mapTimeRangeKeyToReddit(timeRangeKey)
 	}
 
+	includeProvenance := false
+	if provenanceParam := c.Query("include_provenance"); provenanceParam != "" {
+		if parsed, err := strconv.ParseBool(provenanceParam); err == nil {
+			includeProvenance = parsed
+		}
+	}
+
 	// Check if user is authenticated
 	userID, authenticated := c.Get("user_id")
 
 	var hubPosts []*models.PlatformPost
 	var redditPosts []services.RedditPost
+	reasons := popularFeedReasons
 
 	includeReddit := !omniOnly
 	if authenticated {
@@ -95,9 +135,10 @@ func (h *FeedHandler) GetHomeFeed(c *gin.Context) {
 				startTime,
 				endTime,
 				redditTimeFilter,
+				&uidInt,
 			)
 		} else {
-			hubPosts, redditPosts, err = h.fetchSubscribedFeeds(
+			hubPosts, redditPosts, reasons, err = h.fetchSubscribedFeeds(
 				c.Request.Context(),
 				uidInt,
 				sortBy,
@@ -118,6 +159,7 @@ func (h *FeedHandler) GetHomeFeed(c *gin.Context) {
 			startTime,
 			endTime,
 			redditTimeFilter,
+			nil,
 		)
 	}
 
@@ -126,8 +168,16 @@ func (h *FeedHandler) GetHomeFeed(c *gin.Context) {
 		return
 	}
 
+	if err := attachMediaStatus(c.Request.Context(), h.mediaRepo, hubPosts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed", "details": err.Error()})
+		return
+	}
+
 	// Merge and sort by score
 	combined := h.mergeAndSortPosts(hubPosts, redditPosts, sortBy, limit)
+	if includeProvenance {
+		attachProvenance(combined, reasons)
+	}
 
 	response := gin.H{
 		"posts":     combined,
@@ -157,50 +207,52 @@ func (h *FeedHandler) fetchSubscribedFeeds(
 	includeReddit bool,
 	startTime, endTime *time.Time,
 	redditTimeFilter string,
-) ([]*models.PlatformPost, []services.RedditPost, error) {
+) ([]*models.PlatformPost, []services.RedditPost, feedReasons, error) {
+	reasons := feedReasons{hub: ProvenanceSubscribedHub, reddit: ProvenanceSubscribedSubreddit}
+
 	// Fetch subscribed hub IDs
 	subscribedHubIDs, err := h.hubSubRepo.GetSubscribedHubIDs(ctx, userID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, reasons, err
 	}
 
 	// Fetch posts from subscribed hubs (or popular if no subscriptions)
 	var hubPosts []*models.PlatformPost
 	if len(subscribedHubIDs) > 0 {
-		hubPosts, err = h.postRepo.GetPopularFeed(ctx, subscribedHubIDs, sortBy, limit, 0, startTime, endTime)
+		hubPosts, err = h.postRepo.GetPopularFeed(ctx, subscribedHubIDs, sortBy, limit, 0, startTime, endTime, &userID)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, reasons, err
 		}
 	} else {
 		hubPosts = []*models.PlatformPost{}
 	}
 
 	if !includeReddit {
-		return hubPosts, []services.RedditPost{}, nil
+		return hubPosts, []services.RedditPost{}, reasons, nil
 	}
 
 	// Fetch subscribed subreddits
 	subredditSubs, err := h.subredditSubRepo.GetUserSubscriptions(ctx, userID)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, reasons, err
 	}
 
 	var redditPosts []services.RedditPost
 	if len(subredditSubs) == 0 {
-		return hubPosts, []services.RedditPost{}, nil
+		return hubPosts, []services.RedditPost{}, reasons, nil
 	} else {
 		// Fetch from subscribed subreddits
 		// For now, fetch from first subscribed subreddit (TODO: implement multi-subreddit fetch)
-		listing, err := h.redditClient.GetSubredditPosts(ctx, subredditSubs[0].SubredditName, sortBy, redditTimeFilter, limit, "")
+		listing, err := h.redditClient.GetSubredditPosts(ctx, subredditSubs[0].SubredditName, sortBy, redditTimeFilter, limit, "", "")
 		if err != nil {
 			// Non-fatal: continue with hub posts only
-			return hubPosts, []services.RedditPost{}, nil
+			return hubPosts, []services.RedditPost{}, reasons, nil
 		}
 		redditPosts = extractRedditPosts(listing)
 		redditPosts = filterRedditPostsByTimeRange(redditPosts, startTime, endTime)
 	}
 
-	return hubPosts, redditPosts, nil
+	return hubPosts, redditPosts, reasons, nil
 }
 
 // fetchPopularFeeds fetches popular posts from all hubs and r/popular
@@ -211,9 +263,10 @@ func (h *FeedHandler) fetchPopularFeeds(
 	includeReddit bool,
 	startTime, endTime *time.Time,
 	redditTimeFilter string,
+	viewerID *int,
 ) ([]*models.PlatformPost, []services.RedditPost, error) {
 	// Fetch popular hub posts (empty subscribedHubIDs returns all popular)
-	hubPosts, err := h.postRepo.GetPopularFeed(ctx, []int{}, sortBy, limit, 0, startTime, endTime)
+	hubPosts, err := h.postRepo.GetPopularFeed(ctx, []int{}, sortBy, limit, 0, startTime, endTime, viewerID)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -223,7 +276,7 @@ func (h *FeedHandler) fetchPopularFeeds(
 	}
 
 	// Fetch r/popular
-	listing, err := h.redditClient.GetSubredditPosts(ctx, "popular", sortBy, redditTimeFilter, limit, "")
+	listing, err := h.redditClient.GetSubredditPosts(ctx, "popular", sortBy, redditTimeFilter, limit, "", "")
 	if err != nil {
 		// Non-fatal: continue with hub posts only
 		return hubPosts, []services.RedditPost{}, nil
@@ -273,6 +326,27 @@ func (h *FeedHandler) mergeAndSortPosts(hubPosts []*models.PlatformPost, redditP
 	return combined
 }
 
+// attachProvenance sets the provenance of each merged item from the reason its
+// source was fetched for and the hub or subreddit already present on the post
+func attachProvenance(items []CombinedFeedItem, reasons feedReasons) {
+	for i := range items {
+		switch post := items[i].Post.(type) {
+		case *models.PlatformPost:
+			items[i].Provenance = &FeedProvenance{
+				Reason:  reasons.hub,
+				HubID:   post.HubID,
+				HubName: post.HubName,
+			}
+		case services.RedditPost:
+			subreddit := post.Subreddit
+			items[i].Provenance = &FeedProvenance{
+				Reason:    reasons.reddit,
+				Subreddit: &subreddit,
+			}
+		}
+	}
+}
+
 // extractRedditPosts extracts RedditPost slice from RedditListing
 func extractRedditPosts(listing *services.RedditListing) []services.RedditPost {
 	if listing == nil || listing.Data.Children == nil {
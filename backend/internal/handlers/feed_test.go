@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetHomeFeed_SubscribedHubProvenance(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	hubSubRepo := models.NewHubSubscriptionRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	user := &models.User{Username: fmt.Sprintf("feeduser_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hub := &models.Hub{Name: fmt.Sprintf("feedhub_%d", suffix), CreatedBy: &user.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, hubSubRepo.Subscribe(ctx, user.ID, hub.ID))
+
+	post := &models.PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "Subscribed post"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	handler := NewFeedHandler(postRepo, hubSubRepo, models.NewSubredditSubscriptionRepository(db.Pool), nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/feed", authMiddleware(user.ID), handler.GetHomeFeed)
+
+	req := httptest.NewRequest(http.MethodGet, "/feed?omni_only=true&sort=new&include_provenance=true", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Posts []struct {
+			Source     string          `json:"source"`
+			Post       json.RawMessage `json:"post"`
+			Provenance *FeedProvenance `json:"provenance"`
+		} `json:"posts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Posts, 1)
+
+	item := response.Posts[0]
+	assert.Equal(t, "hub", item.Source)
+	require.NotNil(t, item.Provenance)
+	assert.Equal(t, ProvenanceSubscribedHub, item.Provenance.Reason)
+	require.NotNil(t, item.Provenance.HubID)
+	assert.Equal(t, hub.ID, *item.Provenance.HubID)
+	assert.Equal(t, hub.Name, item.Provenance.HubName)
+
+	// Provenance is omitted unless requested
+	req = httptest.NewRequest(http.MethodGet, "/feed?omni_only=true&sort=new", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), `"provenance"`)
+}
+
+func TestAttachProvenance_CarriesSourcePerItem(t *testing.T) {
+	hubID := 7
+	hubPosts := []*models.PlatformPost{{ID: 1, HubID: &hubID, HubName: "golang", Score: 5}}
+	redditPosts := []services.RedditPost{{ID: "abc", Subreddit: "programming", Score: 10}}
+
+	handler := &FeedHandler{}
+	combined := handler.mergeAndSortPosts(hubPosts, redditPosts, "top", 10)
+	attachProvenance(combined, feedReasons{hub: ProvenanceSubscribedHub, reddit: ProvenanceSubscribedSubreddit})
+
+	require.Len(t, combined, 2)
+
+	reddit := combined[0]
+	require.NotNil(t, reddit.Provenance)
+	assert.Equal(t, ProvenanceSubscribedSubreddit, reddit.Provenance.Reason)
+	require.NotNil(t, reddit.Provenance.Subreddit)
+	assert.Equal(t, "programming", *reddit.Provenance.Subreddit)
+	assert.Nil(t, reddit.Provenance.HubID)
+
+	hub := combined[1]
+	require.NotNil(t, hub.Provenance)
+	assert.Equal(t, ProvenanceSubscribedHub, hub.Provenance.Reason)
+	assert.Equal(t, hubID, *hub.Provenance.HubID)
+	assert.Equal(t, "golang", hub.Provenance.HubName)
+	assert.Nil(t, hub.Provenance.Subreddit)
+}
+
+func TestAttachProvenance_PopularFeed(t *testing.T) {
+	redditPosts := []services.RedditPost{{ID: "xyz", Subreddit: "pics", Score: 1}}
+
+	handler := &FeedHandler{}
+	combined := handler.mergeAndSortPosts(nil, redditPosts, "hot", 10)
+	attachProvenance(combined, popularFeedReasons)
+
+	require.Len(t, combined, 1)
+	assert.Equal(t, ProvenancePopular, combined[0].Provenance.Reason)
+	assert.Equal(t, "pics", *combined[0].Provenance.Subreddit)
+}
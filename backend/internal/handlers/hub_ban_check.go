@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+)
+
+// rejectIfHubBanned responds 403 with the ban reason and returns true when the
+// user has an active (unexpired) ban in the hub. A nil repository disables the check.
+func rejectIfHubBanned(c *gin.Context, bans *models.HubBanRepository, hubID, userID int) bool {
+	if bans == nil {
+		return false
+	}
+
+	banned, err := bans.IsUserBanned(c.Request.Context(), hubID, userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check ban status", "details": err.Error()})
+		return true
+	}
+	if !banned {
+		return false
+	}
+
+	response := gin.H{"error": "You are banned from this hub"}
+	if ban, err := bans.GetBanByUser(c.Request.Context(), hubID, userID); err == nil && ban != nil {
+		response["reason"] = ban.Reason
+		response["ban_type"] = ban.BanType
+		if ban.ExpiresAt != nil {
+			response["expires_at"] = ban.ExpiresAt
+		}
+	}
+	c.JSON(http.StatusForbidden, response)
+	return true
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hubBanCheckEnv struct {
+	banRepo *models.HubBanRepository
+	router  *gin.Engine
+	hubID   int
+	hubName string
+	postID  int
+	modID   int
+	userID  int
+}
+
+func setupHubBanCheckTest(t *testing.T) (*hubBanCheckEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	banRepo := models.NewHubBanRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("banchkmod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	user := &models.User{Username: fmt.Sprintf("banchkuser_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hub := &models.Hub{Name: fmt.Sprintf("banchkhub_%d", suffix), ContentOptions: "any", CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	post := &models.PlatformPost{AuthorID: mod.ID, HubID: &hub.ID, Title: "Discussion"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	postsHandler := NewPostsHandler(postRepo, hubRepo, userRepo, modRepo, models.NewFeedRepository(db.Pool))
+	postsHandler.SetHubBanRepository(banRepo)
+	commentsHandler := NewCommentsHandler(commentRepo, postRepo, modRepo)
+	commentsHandler.SetHubBanRepository(banRepo)
+	hubsHandler := NewHubsHandler(hubRepo, postRepo, modRepo, models.NewHubSubscriptionRepository(db.Pool))
+	hubsHandler.SetHubBanRepository(banRepo)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/posts", authMiddleware(user.ID), postsHandler.CreatePost)
+	router.POST("/posts/:id/comments", authMiddleware(user.ID), commentsHandler.CreateComment)
+	router.POST("/hubs/:name/crosspost", authMiddleware(user.ID), hubsHandler.CrosspostToHub)
+
+	env := &hubBanCheckEnv{
+		banRepo: banRepo,
+		router:  router,
+		hubID:   hub.ID,
+		hubName: hub.Name,
+		postID:  post.ID,
+		modID:   mod.ID,
+		userID:  user.ID,
+	}
+	return env, func() { db.Close() }
+}
+
+func (env *hubBanCheckEnv) post(t *testing.T, path string, payload map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func (env *hubBanCheckEnv) createPost(t *testing.T) *httptest.ResponseRecorder {
+	return env.post(t, "/posts", map[string]interface{}{
+		"title":     "Hello hub",
+		"body":      "Post body",
+		"hub_id":    env.hubID,
+		"post_type": "text",
+	})
+}
+
+func (env *hubBanCheckEnv) createComment(t *testing.T) *httptest.ResponseRecorder {
+	return env.post(t, fmt.Sprintf("/posts/%d/comments", env.postID), map[string]interface{}{"body": "Nice post"})
+}
+
+func (env *hubBanCheckEnv) crosspost(t *testing.T) *httptest.ResponseRecorder {
+	path := fmt.Sprintf("/hubs/%s/crosspost?origin_type=platform&origin_post_id=%d", env.hubName, env.postID)
+	return env.post(t, path, map[string]interface{}{"title": "Worth a look"})
+}
+
+func TestHubBanCheck_BannedUserIsRejected(t *testing.T) {
+	env, cleanup := setupHubBanCheckTest(t)
+	defer cleanup()
+
+	_, err := env.banRepo.BanUser(context.Background(), env.hubID, env.userID, env.modID, "Repeated spam", "", "permanent", nil)
+	require.NoError(t, err)
+
+	for name, w := range map[string]*httptest.ResponseRecorder{
+		"post":      env.createPost(t),
+		"comment":   env.createComment(t),
+		"crosspost": env.crosspost(t),
+	} {
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s: %s", name, w.Body.String())
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "Repeated spam", response["reason"], name)
+	}
+}
+
+func TestHubBanCheck_UnbannedUserSucceeds(t *testing.T) {
+	env, cleanup := setupHubBanCheckTest(t)
+	defer cleanup()
+
+	w := env.createPost(t)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = env.createComment(t)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = env.crosspost(t)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestHubBanCheck_ExpiredBanAllowsPosting(t *testing.T) {
+	env, cleanup := setupHubBanCheckTest(t)
+	defer cleanup()
+
+	expired := time.Now().Add(-time.Minute)
+	_, err := env.banRepo.BanUser(context.Background(), env.hubID, env.userID, env.modID, "Cool off", "", "temporary", &expired)
+	require.NoError(t, err)
+
+	w := env.createPost(t)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = env.createComment(t)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
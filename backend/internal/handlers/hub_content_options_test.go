@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHubContentOptions_EnforcedOnPostsAndCrossposts(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	author := env.createUser(t, "contentopts_author")
+	hubs := map[string]*models.Hub{}
+	for _, options := range []string{"any", "text_only", "links_only"} {
+		hub := &models.Hub{Name: fmt.Sprintf("contentopts_%s_%d", options, env.suffix), ContentOptions: options, CreatedBy: &author.ID}
+		require.NoError(t, env.hubRepo.Create(ctx, hub))
+		hubs[options] = hub
+	}
+
+	posts := NewPostsHandler(env.postRepo, env.hubRepo, env.userRepo, env.modRepo, nil)
+	router := gin.New()
+	router.Use(authMiddleware(author.ID))
+	router.POST("/posts", posts.CreatePost)
+	router.POST("/hubs/:name/crosspost", env.handler.CrosspostToHub)
+
+	send := func(path string, payload gin.H) *httptest.ResponseRecorder {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	textPost := gin.H{"title": "Just text", "body": "Thoughts"}
+	linkPost := gin.H{"title": "A link", "media_url": "https://example.com/article"}
+	// A body-only post labelled as a link still has no URL
+	urlLessLink := gin.H{"title": "No URL", "body": "Thoughts", "post_type": "link"}
+
+	tests := []struct {
+		options string
+		payload gin.H
+		allowed bool
+	}{
+		{"any", textPost, true},
+		{"any", linkPost, true},
+		{"text_only", textPost, true},
+		{"text_only", linkPost, false},
+		{"text_only", urlLessLink, false},
+		{"links_only", linkPost, true},
+		{"links_only", textPost, false},
+		{"links_only", urlLessLink, false},
+	}
+	for _, tt := range tests {
+		hub := hubs[tt.options]
+		want := http.StatusBadRequest
+		if tt.allowed {
+			want = http.StatusCreated
+		}
+
+		payload := gin.H{"hub_id": hub.ID}
+		for k, v := range tt.payload {
+			payload[k] = v
+		}
+		w := send("/posts", payload)
+		assert.Equal(t, want, w.Code, "post %v to %s: %s", tt.payload, tt.options, w.Body.String())
+
+		if tt.payload["post_type"] != nil {
+			continue
+		}
+		w = send(fmt.Sprintf("/hubs/%s/crosspost?origin_type=platform&origin_post_id=1", hub.Name), tt.payload)
+		assert.Equal(t, want, w.Code, "crosspost %v to %s: %s", tt.payload, tt.options, w.Body.String())
+	}
+}
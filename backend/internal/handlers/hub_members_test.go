@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// hubMemberRequest performs a request against the hub member routes as userID
+func (env *hubModeratorsTestEnv) hubMemberRequest(t *testing.T, userID int, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.Use(authMiddleware(userID))
+	router.GET("/hubs/:name/posts", env.handler.GetPosts)
+	router.POST("/hubs/:name/crosspost", env.handler.CrosspostToHub)
+	router.POST("/hubs/:name/members", env.handler.AddMember)
+	router.DELETE("/hubs/:name/members/:user_id", env.handler.RemoveMember)
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		require.NoError(t, err)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func (env *hubModeratorsTestEnv) crosspost(t *testing.T, userID int, hubName string) *httptest.ResponseRecorder {
+	t.Helper()
+	path := fmt.Sprintf("/hubs/%s/crosspost?origin_type=platform&origin_post_id=1", hubName)
+	return env.hubMemberRequest(t, userID, http.MethodPost, path, CrosspostRequest{Title: "Shared"})
+}
+
+func TestPrivateHub_NonMemberBlockedUntilInvited(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "members_owner")
+	mod := env.createUser(t, "members_mod")
+	outsider := env.createUser(t, "members_outsider")
+	hub := env.createHub(t, "members_private", "private", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	postsPath := fmt.Sprintf("/hubs/%s/posts", hub.Name)
+	w := env.hubMemberRequest(t, outsider.ID, http.MethodGet, postsPath, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = env.crosspost(t, outsider.ID, hub.Name)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	// Subscribing does not grant access to a private hub
+	require.NoError(t, env.subRepo.Subscribe(ctx, outsider.ID, hub.ID))
+	w = env.hubMemberRequest(t, outsider.ID, http.MethodGet, postsPath, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	// Only moderators may invite
+	membersPath := fmt.Sprintf("/hubs/%s/members", hub.Name)
+	w = env.hubMemberRequest(t, outsider.ID, http.MethodPost, membersPath, gin.H{"user_id": outsider.ID})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = env.hubMemberRequest(t, mod.ID, http.MethodPost, membersPath, gin.H{"user_id": outsider.ID})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	isMember, err := env.memberRepo.IsMember(ctx, hub.ID, outsider.ID)
+	require.NoError(t, err)
+	assert.True(t, isMember)
+
+	w = env.hubMemberRequest(t, outsider.ID, http.MethodGet, postsPath, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = env.crosspost(t, outsider.ID, hub.Name)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = env.hubMemberRequest(t, mod.ID, http.MethodPost, membersPath, gin.H{"user_id": 999999999})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+
+	// Removing the member revokes access again
+	w = env.hubMemberRequest(t, mod.ID, http.MethodDelete, fmt.Sprintf("%s/%d", membersPath, outsider.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = env.hubMemberRequest(t, outsider.ID, http.MethodGet, postsPath, nil)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	w = env.hubMemberRequest(t, mod.ID, http.MethodDelete, fmt.Sprintf("%s/%d", membersPath, outsider.ID), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestPrivateHub_ModeratorsAndCreatorHaveAccess(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	owner := env.createUser(t, "members_creator")
+	mod := env.createUser(t, "members_access_mod")
+	hub := env.createHub(t, "members_access", "private", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(context.Background(), hub.ID, mod.ID))
+
+	for _, userID := range []int{owner.ID, mod.ID} {
+		w := env.hubMemberRequest(t, userID, http.MethodGet, fmt.Sprintf("/hubs/%s/posts", hub.Name), nil)
+		assert.Equal(t, http.StatusOK, w.Code, "user %d: %s", userID, w.Body.String())
+	}
+}
+
+func TestPublicHub_UnaffectedByMembership(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	owner := env.createUser(t, "members_public_owner")
+	visitor := env.createUser(t, "members_public_visitor")
+	hub := env.createHub(t, "members_public", "public", owner.ID)
+
+	w := env.hubMemberRequest(t, visitor.ID, http.MethodGet, fmt.Sprintf("/hubs/%s/posts", hub.Name), nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = env.crosspost(t, visitor.ID, hub.Name)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = env.hubMemberRequest(t, owner.ID, http.MethodPost, fmt.Sprintf("/hubs/%s/members", hub.Name), gin.H{"user_id": visitor.ID})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	isMember, err := env.memberRepo.IsMember(context.Background(), hub.ID, visitor.ID)
+	require.NoError(t, err)
+	assert.False(t, isMember)
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type hubModeratorsTestEnv struct {
+	handler    *HubsHandler
+	userRepo   *models.UserRepository
+	hubRepo    *models.HubRepository
+	modRepo    *models.HubModeratorRepository
+	postRepo   *models.PlatformPostRepository
+	subRepo    *models.HubSubscriptionRepository
+	memberRepo *models.HubMemberRepository
+	suffix     int64
+}
+
+func setupHubModeratorsTest(t *testing.T) (*hubModeratorsTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	require.NoError(t, db.Migrate(context.Background()))
+
+	env := &hubModeratorsTestEnv{
+		userRepo:   models.NewUserRepository(db.Pool),
+		hubRepo:    models.NewHubRepository(db.Pool),
+		modRepo:    models.NewHubModeratorRepository(db.Pool),
+		postRepo:   models.NewPlatformPostRepository(db.Pool),
+		subRepo:    models.NewHubSubscriptionRepository(db.Pool),
+		memberRepo: models.NewHubMemberRepository(db.Pool),
+		suffix:     time.Now().UnixNano(),
+	}
+	env.handler = NewHubsHandler(env.hubRepo, env.postRepo, env.modRepo, env.subRepo)
+	env.handler.SetMemberRepository(env.memberRepo)
+	gin.SetMode(gin.TestMode)
+	return env, func() { db.Close() }
+}
+
+func (env *hubModeratorsTestEnv) createUser(t *testing.T, name string) *models.User {
+	t.Helper()
+	user := &models.User{Username: fmt.Sprintf("%s_%d", name, env.suffix), PasswordHash: "hash"}
+	require.NoError(t, env.userRepo.Create(context.Background(), user))
+	return user
+}
+
+func (env *hubModeratorsTestEnv) createHub(t *testing.T, name, hubType string, creatorID int) *models.Hub {
+	t.Helper()
+	hub := &models.Hub{Name: fmt.Sprintf("%s_%d", name, env.suffix), Type: hubType, CreatedBy: &creatorID}
+	require.NoError(t, env.hubRepo.Create(context.Background(), hub))
+	return hub
+}
+
+// listModerators requests the moderator list as userID, or anonymously when userID is 0
+func (env *hubModeratorsTestEnv) listModerators(t *testing.T, hubName string, userID int) (int, []string) {
+	t.Helper()
+	router := gin.New()
+	if userID != 0 {
+		router.Use(authMiddleware(userID))
+	}
+	router.GET("/hubs/:name/moderators", env.handler.GetModerators)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hubs/"+hubName+"/moderators", nil))
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+
+	var response struct {
+		Moderators []map[string]interface{} `json:"moderators"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	usernames := make([]string, 0, len(response.Moderators))
+	for _, mod := range response.Moderators {
+		usernames = append(usernames, mod["username"].(string))
+	}
+	return w.Code, usernames
+}
+
+func TestGetHubModerators_ReflectsAddedAndRemovedModerators(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "modlist_owner")
+	alice := env.createUser(t, "modlist_alice")
+	bob := env.createUser(t, "modlist_bob")
+	hub := env.createHub(t, "modlist_public", "public", owner.ID)
+
+	code, mods := env.listModerators(t, hub.Name, 0)
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, mods)
+
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, alice.ID))
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, bob.ID))
+	_, mods = env.listModerators(t, hub.Name, 0)
+	assert.Equal(t, []string{alice.Username, bob.Username}, mods)
+
+	require.NoError(t, env.modRepo.RemoveModerator(ctx, hub.ID, alice.ID))
+	_, mods = env.listModerators(t, hub.Name, 0)
+	assert.Equal(t, []string{bob.Username}, mods)
+
+	code, _ = env.listModerators(t, "no_such_hub", 0)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestGetHubModerators_PrivateHubRestrictedToMembers(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "privmods_owner")
+	mod := env.createUser(t, "privmods_mod")
+	member := env.createUser(t, "privmods_member")
+	outsider := env.createUser(t, "privmods_outsider")
+	hub := env.createHub(t, "privmods", "private", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+	require.NoError(t, env.memberRepo.AddMember(ctx, hub.ID, member.ID, owner.ID))
+
+	for _, userID := range []int{owner.ID, mod.ID, member.ID} {
+		code, mods := env.listModerators(t, hub.Name, userID)
+		require.Equal(t, http.StatusOK, code, "user %d", userID)
+		assert.Equal(t, []string{mod.Username}, mods)
+	}
+
+	code, _ := env.listModerators(t, hub.Name, outsider.ID)
+	assert.Equal(t, http.StatusForbidden, code)
+	code, _ = env.listModerators(t, hub.Name, 0)
+	assert.Equal(t, http.StatusForbidden, code)
+}
+
+func TestGetHubModerators_ServesFromCache(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "cachedmods_owner")
+	mod := env.createUser(t, "cachedmods_mod")
+	hub := env.createHub(t, "cachedmods", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, owner.ID))
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	cache := &mockRedditCache{}
+	env.handler.SetCache(cache)
+
+	_, mods := env.listModerators(t, hub.Name, 0)
+	require.Equal(t, []string{mod.Username, owner.Username}, mods)
+
+	// Within the TTL the cached list is served even though the table changed
+	require.NoError(t, env.modRepo.RemoveModerator(ctx, hub.ID, mod.ID))
+	_, mods = env.listModerators(t, hub.Name, 0)
+	assert.Equal(t, []string{mod.Username, owner.Username}, mods)
+
+	// Changes made through the handlers drop the cached list
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+	router := gin.New()
+	router.DELETE("/hubs/:name/moderators/:user_id", authMiddleware(owner.ID), env.handler.RemoveModerator)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/hubs/%s/moderators/%d", hub.Name, mod.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	_, mods = env.listModerators(t, hub.Name, 0)
+	assert.Equal(t, []string{owner.Username}, mods)
+}
+
+func TestGetHubModerators_IncludesCreatorOfNewHub(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	creator := env.createUser(t, "newhubmods_creator")
+	hubName := fmt.Sprintf("newhubmods_%d", env.suffix)
+
+	router := gin.New()
+	router.POST("/hubs", authMiddleware(creator.ID), env.handler.Create)
+	router.GET("/hubs/:name/moderators", env.handler.GetModerators)
+
+	body, err := json.Marshal(gin.H{"name": hubName})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/hubs", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Public hubs list their moderators without auth
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hubs/"+hubName+"/moderators", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Moderators []struct {
+			ID       int       `json:"id"`
+			Username string    `json:"username"`
+			AddedAt  time.Time `json:"added_at"`
+		} `json:"moderators"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Moderators, 1)
+	assert.Equal(t, creator.ID, response.Moderators[0].ID)
+	assert.Equal(t, creator.Username, response.Moderators[0].Username)
+	assert.WithinDuration(t, time.Now(), response.Moderators[0].AddedAt, time.Minute)
+}
+
+// removeModerator sends DELETE /hubs/:name/moderators/:user_id as userID with the given role
+func (env *hubModeratorsTestEnv) removeModerator(t *testing.T, userID int, role, hubName string, modID int) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	})
+	router.DELETE("/hubs/:name/moderators/:user_id", env.handler.RemoveModerator)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/hubs/%s/moderators/%d", hubName, modID), nil))
+	return w
+}
+
+func TestRemoveModerator_OwnerRemovesSecondaryModerator(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "demote_owner")
+	mod := env.createUser(t, "demote_mod")
+	hub := env.createHub(t, "demote", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, owner.ID))
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	// Other moderators can't demote each other
+	w := env.removeModerator(t, mod.ID, "user", hub.Name, owner.ID)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = env.removeModerator(t, owner.ID, "user", hub.Name, mod.ID)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	isMod, err := env.modRepo.IsModerator(ctx, hub.ID, mod.ID)
+	require.NoError(t, err)
+	assert.False(t, isMod)
+
+	w = env.removeModerator(t, owner.ID, "user", hub.Name, mod.ID)
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
+
+func TestRemoveModerator_RejectsRemovingSoleModerator(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "solemod_owner")
+	admin := env.createUser(t, "solemod_admin")
+	hub := env.createHub(t, "solemod", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, owner.ID))
+
+	for _, w := range []*httptest.ResponseRecorder{
+		env.removeModerator(t, owner.ID, "user", hub.Name, owner.ID),
+		env.removeModerator(t, admin.ID, "admin", hub.Name, owner.ID),
+	} {
+		assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	}
+
+	isMod, err := env.modRepo.IsModerator(ctx, hub.ID, owner.ID)
+	require.NoError(t, err)
+	assert.True(t, isMod)
+	assert.ErrorIs(t, env.modRepo.RemoveModerator(ctx, hub.ID, owner.ID), models.ErrLastModerator)
+}
+
+// setPermissions sends PUT /hubs/:name/moderators/:user_id/permissions as userID with the given role
+func (env *hubModeratorsTestEnv) setPermissions(t *testing.T, userID int, role, hubName string, modID int, perms []string) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("role", role)
+		c.Next()
+	})
+	router.PUT("/hubs/:name/moderators/:user_id/permissions", env.handler.SetModeratorPermissions)
+
+	body, err := json.Marshal(map[string]interface{}{"permissions": perms})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, fmt.Sprintf("/hubs/%s/moderators/%d/permissions", hubName, modID), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestSetModeratorPermissions_OwnerAndAdminOnly(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "modperm_owner")
+	mod := env.createUser(t, "modperm_mod")
+	admin := env.createUser(t, "modperm_admin")
+	outsider := env.createUser(t, "modperm_outsider")
+	hub := env.createHub(t, "modperm", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, owner.ID))
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	// Moderators can't change their own or each other's scopes
+	w := env.setPermissions(t, mod.ID, "user", hub.Name, mod.ID, []string{models.ModPermFull})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = env.setPermissions(t, owner.ID, "user", hub.Name, mod.ID, []string{models.ModPermPosts})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	canBan, err := env.modRepo.HasPermission(ctx, hub.ID, mod.ID, models.ModPermAccess)
+	require.NoError(t, err)
+	assert.False(t, canBan)
+
+	w = env.setPermissions(t, admin.ID, "admin", hub.Name, mod.ID, []string{models.ModPermPosts, models.ModPermAccess})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	canBan, err = env.modRepo.HasPermission(ctx, hub.ID, mod.ID, models.ModPermAccess)
+	require.NoError(t, err)
+	assert.True(t, canBan)
+
+	w = env.setPermissions(t, owner.ID, "user", hub.Name, mod.ID, []string{"everything"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = env.setPermissions(t, owner.ID, "user", hub.Name, mod.ID, []string{})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w = env.setPermissions(t, owner.ID, "user", hub.Name, outsider.ID, []string{models.ModPermPosts})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRelatedHubs_RanksByOverlappingSubscribers(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "related_owner")
+	golang := env.createHub(t, "related_golang", "public", owner.ID)
+	rust := env.createHub(t, "related_rust", "public", owner.ID)
+	python := env.createHub(t, "related_python", "public", owner.ID)
+	cooking := env.createHub(t, "related_cooking", "public", owner.ID)
+	secret := env.createHub(t, "related_secret", "private", owner.ID)
+
+	// Three golang subscribers also follow rust, one follows python, none cook
+	subscriptions := map[string][]int{
+		"a": {golang.ID, rust.ID, python.ID, secret.ID},
+		"b": {golang.ID, rust.ID},
+		"c": {golang.ID, rust.ID, secret.ID},
+		"d": {cooking.ID, python.ID},
+	}
+	for name, hubIDs := range subscriptions {
+		user := env.createUser(t, "related_sub_"+name)
+		for _, hubID := range hubIDs {
+			require.NoError(t, env.subRepo.Subscribe(ctx, user.ID, hubID))
+		}
+	}
+
+	router := gin.New()
+	router.GET("/hubs/:name/related", env.handler.GetRelatedHubs)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hubs/"+golang.Name+"/related", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Hubs []struct {
+			Name              string `json:"name"`
+			SharedSubscribers int    `json:"shared_subscribers"`
+		} `json:"hubs"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	// The hub itself, private hubs and hubs without shared subscribers are left out
+	require.Len(t, response.Hubs, 2)
+	assert.Equal(t, rust.Name, response.Hubs[0].Name)
+	assert.Equal(t, 3, response.Hubs[0].SharedSubscribers)
+	assert.Equal(t, python.Name, response.Hubs[1].Name)
+	assert.Equal(t, 1, response.Hubs[1].SharedSubscribers)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/hubs/no_such_hub/related", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// updateHub sends PUT /hubs/:name as userID
+func (env *hubModeratorsTestEnv) updateHub(t *testing.T, userID int, hubName string, body gin.H) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.PUT("/hubs/:name", authMiddleware(userID), env.handler.Update)
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, "/hubs/"+hubName, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestUpdateHub_ModeratorEditsSettings(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "settings_owner")
+	mod := env.createUser(t, "settings_mod")
+	hub := env.createHub(t, "settings", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	w := env.updateHub(t, mod.ID, hub.Name, gin.H{
+		"title":           "Settings Hub",
+		"description":     "All about settings",
+		"content_options": "text_only",
+		"sidebar_md":      "## Rules\n1. Be kind",
+		"banner_url":      "https://example.com/banner.png",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Hub map[string]interface{} `json:"hub"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "## Rules\n1. Be kind", response.Hub["sidebar_md"])
+	assert.Equal(t, "https://example.com/banner.png", response.Hub["banner_url"])
+
+	updated, err := env.hubRepo.GetByName(ctx, hub.Name)
+	require.NoError(t, err)
+	require.NotNil(t, updated.Title)
+	assert.Equal(t, "Settings Hub", *updated.Title)
+	require.NotNil(t, updated.Description)
+	assert.Equal(t, "All about settings", *updated.Description)
+	assert.Equal(t, "text_only", updated.ContentOptions)
+	require.NotNil(t, updated.SidebarMD)
+	require.NotNil(t, updated.BannerURL)
+
+	// Omitted fields are kept and empty strings clear
+	w = env.updateHub(t, owner.ID, hub.Name, gin.H{"banner_url": ""})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	updated, err = env.hubRepo.GetByName(ctx, hub.Name)
+	require.NoError(t, err)
+	assert.Nil(t, updated.BannerURL)
+	require.NotNil(t, updated.SidebarMD)
+	assert.Equal(t, "text_only", updated.ContentOptions)
+}
+
+func TestUpdateHub_RejectsNonModerators(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "settings_nonmod_owner")
+	outsider := env.createUser(t, "settings_outsider")
+	postsMod := env.createUser(t, "settings_postsmod")
+	hub := env.createHub(t, "settings_nonmod", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, postsMod.ID))
+	_, err := env.modRepo.SetPermissions(ctx, hub.ID, postsMod.ID, []string{models.ModPermPosts})
+	require.NoError(t, err)
+
+	for _, userID := range []int{outsider.ID, postsMod.ID} {
+		w := env.updateHub(t, userID, hub.Name, gin.H{"title": "Hijacked"})
+		assert.Equal(t, http.StatusForbidden, w.Code, "user %d: %s", userID, w.Body.String())
+	}
+
+	unchanged, err := env.hubRepo.GetByName(ctx, hub.Name)
+	require.NoError(t, err)
+	assert.Nil(t, unchanged.Title)
+
+	w := env.updateHub(t, owner.ID, "no_such_hub", gin.H{"title": "Missing"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdateHub_RejectsInvalidValues(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	owner := env.createUser(t, "settings_invalid_owner")
+	hub := env.createHub(t, "settings_invalid", "public", owner.ID)
+
+	w := env.updateHub(t, owner.ID, hub.Name, gin.H{"content_options": "images_only"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = env.updateHub(t, owner.ID, hub.Name, gin.H{"banner_url": "javascript:alert(1)"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	unchanged, err := env.hubRepo.GetByName(context.Background(), hub.Name)
+	require.NoError(t, err)
+	assert.Equal(t, "any", unchanged.ContentOptions)
+	assert.Nil(t, unchanged.BannerURL)
+}
+
+func TestUpdateHub_MaxPinnedPosts(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "pins_owner")
+	hub := env.createHub(t, "pins", "public", owner.ID)
+
+	w := env.updateHub(t, owner.ID, hub.Name, gin.H{"max_pinned_posts": 5})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	updated, err := env.hubRepo.GetByName(ctx, hub.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 5, updated.MaxPinnedPosts)
+
+	for _, invalid := range []int{0, 11, -1} {
+		w = env.updateHub(t, owner.ID, hub.Name, gin.H{"max_pinned_posts": invalid})
+		assert.Equal(t, http.StatusBadRequest, w.Code, "max_pinned_posts=%d", invalid)
+	}
+
+	// Other edits leave the cap alone
+	w = env.updateHub(t, owner.ID, hub.Name, gin.H{"title": "Pins"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	updated, err = env.hubRepo.GetByName(ctx, hub.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 5, updated.MaxPinnedPosts)
+}
@@ -1,21 +1,36 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
 )
 
+// hubModeratorsCacheTTL bounds how stale the public moderator list can be
+// after a moderator is added or removed
+const hubModeratorsCacheTTL = 30 * time.Second
+
 // HubsHandler handles hub CRUD
 type HubsHandler struct {
 	hubRepo    *models.HubRepository
 	postRepo   *models.PlatformPostRepository
 	modRepo    *models.HubModeratorRepository
 	hubSubRepo *models.HubSubscriptionRepository
+	cache      services.Cache
+	mediaRepo  *models.MediaFileRepository
+	memberRepo *models.HubMemberRepository
+	hubBanRepo *models.HubBanRepository
 }
 
 // NewHubsHandler creates a new handler
@@ -28,6 +43,27 @@ func NewHubsHandler(hubRepo *models.HubRepository, postRepo *models.PlatformPost
 	}
 }
 
+// SetCache enables caching of public hub moderator lists (called after initialization)
+func (h *HubsHandler) SetCache(cache services.Cache) {
+	h.cache = cache
+}
+
+// SetMediaRepository enables media processing status on hub posts (called after initialization)
+func (h *HubsHandler) SetMediaRepository(mediaRepo *models.MediaFileRepository) {
+	h.mediaRepo = mediaRepo
+}
+
+// SetMemberRepository enables the member list of private hubs (called after initialization).
+// Without it only a private hub's creator and moderators can access it.
+func (h *HubsHandler) SetMemberRepository(memberRepo *models.HubMemberRepository) {
+	h.memberRepo = memberRepo
+}
+
+// SetHubBanRepository enables blocking banned users from crossposting into hubs
+func (h *HubsHandler) SetHubBanRepository(hubBanRepo *models.HubBanRepository) {
+	h.hubBanRepo = hubBanRepo
+}
+
 // CreateHubRequest payload
 type CreateHubRequest struct {
 	Name           string  `json:"name" binding:"required,max=100"`
@@ -47,7 +83,7 @@ func (h *HubsHandler) Create(c *gin.Context) {
 
 	var req CreateHubRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -82,7 +118,7 @@ func (h *HubsHandler) Create(c *gin.Context) {
 	if req.ContentOptions == "" {
 		req.ContentOptions = "any"
 	}
-	if req.ContentOptions != "any" && req.ContentOptions != "links_only" && req.ContentOptions != "text_only" {
+	if !validHubContentOptions(req.ContentOptions) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Content options must be 'any', 'links_only', or 'text_only'"})
 		return
 	}
@@ -109,6 +145,124 @@ func (h *HubsHandler) Create(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"hub": hubResponse(hub)})
 }
 
+// validHubContentOptions reports whether options is an allowed content_options value
+func validHubContentOptions(options string) bool {
+	return options == "any" || options == "links_only" || options == "text_only"
+}
+
+// hubContentViolation returns why a post doesn't fit a hub's content_options,
+// or "" when it's allowed. Posts with a media URL count as link posts.
+func hubContentViolation(contentOptions, postType string, mediaURL *string) string {
+	hasURL := mediaURL != nil && strings.TrimSpace(*mediaURL) != ""
+	switch contentOptions {
+	case "text_only":
+		if hasURL || postType == "link" {
+			return "This hub only accepts text posts"
+		}
+	case "links_only":
+		if !hasURL {
+			return "This hub only accepts link posts with a URL"
+		}
+	}
+	return ""
+}
+
+// UpdateHubRequest payload. Omitted fields are left unchanged; an empty string
+// clears the title, description, sidebar or banner.
+type UpdateHubRequest struct {
+	Title          *string `json:"title"`
+	Description    *string `json:"description"`
+	ContentOptions *string `json:"content_options"`
+	SidebarMD      *string `json:"sidebar_md"`
+	BannerURL      *string `json:"banner_url"`
+	MaxPinnedPosts *int    `json:"max_pinned_posts"`
+}
+
+// Update handles PUT /api/v1/hubs/:name
+// Edits a hub's settings. Requires the creator, an admin, or a moderator with
+// the config permission.
+func (h *HubsHandler) Update(c *gin.Context) {
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hub", "details": err.Error()})
+		return
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+
+	allowed, err := h.canManageHub(c, hub, models.ModPermConfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check moderator status", "details": err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can edit hub settings"})
+		return
+	}
+
+	var req UpdateHubRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
+	if req.Title != nil {
+		title := strings.TrimSpace(*req.Title)
+		if len(title) > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Title must be 500 characters or less"})
+			return
+		}
+		hub.Title = stringPtrOrNil(title)
+	}
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len(description) > 500 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Description must be less than 500 characters"})
+			return
+		}
+		hub.Description = stringPtrOrNil(description)
+	}
+	if req.ContentOptions != nil {
+		if !validHubContentOptions(*req.ContentOptions) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Content options must be 'any', 'links_only', or 'text_only'"})
+			return
+		}
+		hub.ContentOptions = *req.ContentOptions
+	}
+	if req.SidebarMD != nil {
+		sidebar := strings.TrimSpace(*req.SidebarMD)
+		if len(sidebar) > 10000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Sidebar must be 10000 characters or less"})
+			return
+		}
+		hub.SidebarMD = stringPtrOrNil(sidebar)
+	}
+	if req.BannerURL != nil {
+		bannerURL := strings.TrimSpace(*req.BannerURL)
+		if bannerURL != "" && !strings.HasPrefix(bannerURL, "http://") && !strings.HasPrefix(bannerURL, "https://") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Banner URL must be a valid HTTP(S) URL"})
+			return
+		}
+		hub.BannerURL = stringPtrOrNil(bannerURL)
+	}
+	if req.MaxPinnedPosts != nil {
+		if *req.MaxPinnedPosts < models.MinHubPinnedPosts || *req.MaxPinnedPosts > models.MaxHubPinnedPosts {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("max_pinned_posts must be between %d and %d", models.MinHubPinnedPosts, models.MaxHubPinnedPosts)})
+			return
+		}
+		hub.MaxPinnedPosts = *req.MaxPinnedPosts
+	}
+
+	if err := h.hubRepo.Update(c.Request.Context(), hub); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update hub", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hub": hubResponse(hub)})
+}
+
 // Get handles GET /api/v1/hubs/:name
 func (h *HubsHandler) Get(c *gin.Context) {
 	name := c.Param("name")
@@ -124,12 +278,19 @@ func (h *HubsHandler) Get(c *gin.Context) {
 	response := hubResponse(hub)
 
 	if h.modRepo != nil {
-		moderators, err := h.modRepo.GetModeratorsForHub(c.Request.Context(), hub.ID)
+		canView, err := h.canAccessHub(c, hub)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load moderators", "details": err.Error()})
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check hub membership", "details": err.Error()})
 			return
 		}
-		response["moderators"] = moderatorsResponse(moderators)
+		if canView {
+			moderators, err := h.modRepo.GetModerators(c.Request.Context(), hub.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load moderators", "details": err.Error()})
+				return
+			}
+			response["moderators"] = moderatorsResponse(moderators)
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"hub": response})
@@ -168,6 +329,9 @@ func (h *HubsHandler) GetPosts(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
 		return
 	}
+	if !h.requireHubAccess(c, hub) {
+		return
+	}
 
 	sortBy := c.DefaultQuery("sort", "new")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
@@ -194,6 +358,10 @@ func (h *HubsHandler) GetPosts(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts", "details": err.Error()})
 		return
 	}
+	if err := attachMediaStatus(c.Request.Context(), h.mediaRepo, posts); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts", "details": err.Error()})
+		return
+	}
 
 	response := gin.H{
 		"hub":    name,
@@ -226,7 +394,7 @@ func (h *HubsHandler) AddModerator(c *gin.Context) {
 		UserID int `json:"user_id" binding:"required"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -239,10 +407,341 @@ func (h *HubsHandler) AddModerator(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add moderator", "details": err.Error()})
 		return
 	}
+	invalidateModeratorsCache(c.Request.Context(), h.cache, hub.ID)
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Moderator added"})
 }
 
+// RemoveModerator handles DELETE /api/v1/hubs/:name/moderators/:user_id
+// Only the hub's creator or an admin can demote a moderator.
+func (h *HubsHandler) RemoveModerator(c *gin.Context) {
+	if h.modRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Mod repo not configured"})
+		return
+	}
+
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hub", "details": err.Error()})
+		return
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	if c.GetString("role") != "admin" && (hub.CreatedBy == nil || *hub.CreatedBy != userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the hub owner can remove moderators"})
+		return
+	}
+
+	modID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	if err := h.modRepo.RemoveModerator(c.Request.Context(), hub.ID, modID); err != nil {
+		respondRemoveModeratorError(c, err)
+		return
+	}
+	invalidateModeratorsCache(c.Request.Context(), h.cache, hub.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Moderator removed"})
+}
+
+// SetModeratorPermissions handles PUT /api/v1/hubs/:name/moderators/:user_id/permissions
+// Replaces a moderator's permission scopes. Only the hub's creator or an admin can do this.
+func (h *HubsHandler) SetModeratorPermissions(c *gin.Context) {
+	if h.modRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Mod repo not configured"})
+		return
+	}
+
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hub", "details": err.Error()})
+		return
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+
+	userID := c.GetInt("user_id")
+	if c.GetString("role") != "admin" && (hub.CreatedBy == nil || *hub.CreatedBy != userID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the hub owner can change moderator permissions"})
+		return
+	}
+
+	modID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	var req struct {
+		Permissions []string `json:"permissions" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
+	updated, err := h.modRepo.SetPermissions(c.Request.Context(), hub.ID, modID, req.Permissions)
+	if errors.Is(err, models.ErrInvalidModPermission) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update moderator permissions", "details": err.Error()})
+		return
+	}
+	if !updated {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a moderator of this hub"})
+		return
+	}
+	invalidateModeratorsCache(c.Request.Context(), h.cache, hub.ID)
+
+	c.JSON(http.StatusOK, gin.H{"message": "Moderator permissions updated", "permissions": req.Permissions})
+}
+
+// respondRemoveModeratorError maps HubModeratorRepository.RemoveModerator errors to responses
+func respondRemoveModeratorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotModerator):
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a moderator of this hub"})
+	case errors.Is(err, models.ErrLastModerator):
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last moderator of a hub"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove moderator", "details": err.Error()})
+	}
+}
+
+// GetModerators handles GET /api/v1/hubs/:name/moderators
+// Moderators of private hubs are only listed to the hub's members.
+func (h *HubsHandler) GetModerators(c *gin.Context) {
+	name := c.Param("name")
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), name)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hub", "details": err.Error()})
+		return
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+
+	canView, err := h.canAccessHub(c, hub)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check hub membership", "details": err.Error()})
+		return
+	}
+	if !canView {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only members of this private hub can see its moderators"})
+		return
+	}
+
+	moderators, err := h.cachedModerators(c.Request.Context(), hub.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load moderators", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"hub":        hub.Name,
+		"moderators": moderatorsResponse(moderators),
+	})
+}
+
+// cachedModerators returns a hub's moderators, served from the cache when one is configured
+func (h *HubsHandler) cachedModerators(ctx context.Context, hubID int) ([]models.HubModerator, error) {
+	cacheKey := hubModeratorsCacheKey(hubID)
+	if h.cache != nil {
+		if cached, ok, err := h.cache.Get(ctx, cacheKey); err == nil && ok {
+			var moderators []models.HubModerator
+			if err := json.Unmarshal([]byte(cached), &moderators); err == nil {
+				return moderators, nil
+			}
+		}
+	}
+
+	moderators, err := h.modRepo.GetModerators(ctx, hubID)
+	if err != nil {
+		return nil, err
+	}
+
+	if h.cache != nil {
+		if data, err := json.Marshal(moderators); err == nil {
+			if err := h.cache.Set(ctx, cacheKey, string(data), hubModeratorsCacheTTL); err != nil {
+				log.Printf("Failed to cache moderators for hub %d: %v", hubID, err)
+			}
+		}
+	}
+	return moderators, nil
+}
+
+func hubModeratorsCacheKey(hubID int) string {
+	return fmt.Sprintf("hubmods:%d", hubID)
+}
+
+// invalidateModeratorsCache drops a hub's cached moderator list after its
+// moderators or their permissions change. A nil cache is a no-op.
+func invalidateModeratorsCache(ctx context.Context, cache services.Cache, hubID int) {
+	if cache == nil {
+		return
+	}
+	if err := cache.Delete(ctx, hubModeratorsCacheKey(hubID)); err != nil {
+		log.Printf("Failed to invalidate cached moderators for hub %d: %v", hubID, err)
+	}
+}
+
+// canAccessHub reports whether the current user may read and post in a hub.
+// Anyone can for public hubs; private hubs are limited to their creator,
+// moderators, members and site admins.
+func (h *HubsHandler) canAccessHub(c *gin.Context, hub *models.Hub) (bool, error) {
+	if hub.Type != "private" || c.GetString("role") == "admin" {
+		return true, nil
+	}
+
+	userIDVal, exists := c.Get("user_id")
+	if !exists {
+		return false, nil
+	}
+	userID := userIDVal.(int)
+	if hub.CreatedBy != nil && *hub.CreatedBy == userID {
+		return true, nil
+	}
+
+	isMod, err := h.modRepo.IsModerator(c.Request.Context(), hub.ID, userID)
+	if err != nil || isMod {
+		return isMod, err
+	}
+	if h.memberRepo == nil {
+		return false, nil
+	}
+	return h.memberRepo.IsMember(c.Request.Context(), hub.ID, userID)
+}
+
+// requireHubAccess writes a 403 (or 500) response and returns false when the
+// current user can't access hub.
+func (h *HubsHandler) requireHubAccess(c *gin.Context, hub *models.Hub) bool {
+	allowed, err := h.canAccessHub(c, hub)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check hub membership", "details": err.Error()})
+		return false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This hub is private"})
+		return false
+	}
+	return true
+}
+
+// AddMember handles POST /api/v1/hubs/:name/members
+// Invites a user to a private hub. Requires the creator, an admin, or a
+// moderator with the access permission.
+func (h *HubsHandler) AddMember(c *gin.Context) {
+	hub, ok := h.memberManagedHub(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		UserID int `json:"user_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
+	if err := h.memberRepo.AddMember(c.Request.Context(), hub.ID, req.UserID, c.GetInt("user_id")); err != nil {
+		if errors.Is(err, models.ErrHubMemberUserNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add member", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Member added"})
+}
+
+// RemoveMember handles DELETE /api/v1/hubs/:name/members/:user_id
+func (h *HubsHandler) RemoveMember(c *gin.Context) {
+	hub, ok := h.memberManagedHub(c)
+	if !ok {
+		return
+	}
+
+	memberID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	removed, err := h.memberRepo.RemoveMember(c.Request.Context(), hub.ID, memberID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove member", "details": err.Error()})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "User is not a member of this hub"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed"})
+}
+
+// memberManagedHub loads the private hub named in the path and checks that the
+// current user may manage its members, writing an error response otherwise.
+func (h *HubsHandler) memberManagedHub(c *gin.Context) (*models.Hub, bool) {
+	if h.memberRepo == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Member repo not configured"})
+		return nil, false
+	}
+
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hub", "details": err.Error()})
+		return nil, false
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return nil, false
+	}
+	if hub.Type != "private" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Only private hubs have a member list"})
+		return nil, false
+	}
+
+	allowed, err := h.canManageHub(c, hub, models.ModPermAccess)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check moderator status", "details": err.Error()})
+		return nil, false
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can manage hub members"})
+		return nil, false
+	}
+
+	return hub, true
+}
+
+// canManageHub reports whether the current user is an admin, the hub's creator,
+// or a moderator holding perm
+func (h *HubsHandler) canManageHub(c *gin.Context, hub *models.Hub, perm string) (bool, error) {
+	userID := c.GetInt("user_id")
+	if c.GetString("role") == "admin" || (hub.CreatedBy != nil && *hub.CreatedBy == userID) {
+		return true, nil
+	}
+	if h.modRepo == nil {
+		return false, nil
+	}
+	return h.modRepo.HasPermission(c.Request.Context(), hub.ID, userID, perm)
+}
+
 // GetUserHubs handles GET /api/v1/users/me/hubs - returns hubs user can post to
 func (h *HubsHandler) GetUserHubs(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -297,10 +796,21 @@ func (h *HubsHandler) CrosspostToHub(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
 		return
 	}
+	if !h.requireHubAccess(c, hub) {
+		return
+	}
+	if rejectIfHubBanned(c, h.hubBanRepo, hub.ID, userID.(int)) {
+		return
+	}
 
 	var req CrosspostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
+	if violation := hubContentViolation(hub.ContentOptions, "", req.MediaURL); violation != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": violation})
 		return
 	}
 
@@ -392,6 +902,15 @@ func hubResponse(h *models.Hub) gin.H {
 	if h.CreatedBy != nil {
 		response["owner_id"] = *h.CreatedBy
 	}
+	if h.SidebarMD != nil {
+		response["sidebar_md"] = *h.SidebarMD
+	}
+	if h.BannerURL != nil {
+		response["banner_url"] = *h.BannerURL
+	}
+	if h.MaxPinnedPosts > 0 {
+		response["max_pinned_posts"] = h.MaxPinnedPosts
+	}
 
 	return response
 }
@@ -404,12 +923,13 @@ func hubsResponse(hubs []*models.Hub) []gin.H {
 	return out
 }
 
-func moderatorsResponse(mods []models.HubModeratorUser) []gin.H {
+func moderatorsResponse(mods []models.HubModerator) []gin.H {
 	out := make([]gin.H, len(mods))
 	for i, mod := range mods {
 		item := gin.H{
 			"id":       mod.UserID,
 			"username": mod.Username,
+			"added_at": mod.AddedAt,
 		}
 		if mod.AvatarURL != nil {
 			item["avatar_url"] = *mod.AvatarURL
@@ -436,7 +956,7 @@ func (h *HubsHandler) CrosspostToSubreddit(c *gin.Context) {
 
 	var req CrosspostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -537,6 +1057,7 @@ func (h *HubsHandler) GetPopularFeed(c *gin.Context) {
 		offset,
 		startTime,
 		endTime,
+		optionalViewerID(c),
 	)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed", "details": err.Error()})
@@ -557,7 +1078,10 @@ func (h *HubsHandler) GetPopularFeed(c *gin.Context) {
 }
 
 // GetAllFeed handles GET /api/v1/hubs/h/all (public)
-// Returns global firehose (includes everything, no filtering)
+// Returns global firehose (no subscription filtering; private hubs the viewer
+// can't access and removed posts are left out)
+// Passing after_id and after_hot_score (taken from the last post of the previous
+// page) switches the hot sort to keyset pagination.
 func (h *HubsHandler) GetAllFeed(c *gin.Context) {
 	sortBy := c.DefaultQuery("sort", "hot")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
@@ -566,13 +1090,23 @@ func (h *HubsHandler) GetAllFeed(c *gin.Context) {
 		limit = 25
 	}
 
+	if afterIDParam := c.Query("after_id"); afterIDParam != "" {
+		h.getAllFeedAfter(c, sortBy, afterIDParam, limit)
+		return
+	}
+
 	startTime, endTime, timeRangeKey, err := parseTopTimeRange(c, sortBy)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	posts, err := h.postRepo.GetAllFeed(c.Request.Context(), sortBy, limit, offset, startTime, endTime)
+	var posts []*models.PlatformPost
+	if sortBy == "rising" {
+		posts, err = h.postRepo.GetRisingFeed(c.Request.Context(), models.DefaultRisingWindowHours, limit, offset, optionalViewerID(c))
+	} else {
+		posts, err = h.postRepo.GetAllFeed(c.Request.Context(), sortBy, limit, offset, startTime, endTime, optionalViewerID(c))
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed", "details": err.Error()})
 		return
@@ -591,6 +1125,42 @@ func (h *HubsHandler) GetAllFeed(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// getAllFeedAfter serves a keyset-paginated page of the global firehose
+func (h *HubsHandler) getAllFeedAfter(c *gin.Context, sortBy, afterIDParam string, limit int) {
+	afterID, err := strconv.Atoi(afterIDParam)
+	if err != nil || afterID < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after_id must be a positive integer"})
+		return
+	}
+	afterHotScore, err := strconv.ParseFloat(c.Query("after_hot_score"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after_hot_score is required with after_id"})
+		return
+	}
+
+	posts, err := h.postRepo.GetAllFeedAfter(c.Request.Context(), sortBy, afterHotScore, afterID, limit, optionalViewerID(c))
+	if err != nil {
+		if errors.Is(err, models.ErrUnsupportedKeysetSort) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch feed", "details": err.Error()})
+		return
+	}
+
+	response := gin.H{
+		"posts": posts,
+		"limit": limit,
+		"sort":  sortBy,
+	}
+	if len(posts) == limit {
+		last := posts[len(posts)-1]
+		response["next_cursor"] = gin.H{"after_id": last.ID, "after_hot_score": last.HotScore}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // SearchHubs handles GET /api/v1/hubs/search?q=cats (autocomplete)
 func (h *HubsHandler) SearchHubs(c *gin.Context) {
 	query := c.Query("q")
@@ -617,6 +1187,42 @@ func (h *HubsHandler) SearchHubs(c *gin.Context) {
 	})
 }
 
+// GetRelatedHubs handles GET /api/v1/hubs/:name/related
+// Suggests hubs that share the most subscribers with this one.
+func (h *HubsHandler) GetRelatedHubs(c *gin.Context) {
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch hub", "details": err.Error()})
+		return
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !h.requireHubAccess(c, hub) {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	if limit < 1 || limit > 25 {
+		limit = 5
+	}
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
+
+	related, err := h.hubRepo.GetRelatedHubs(c.Request.Context(), hub.ID, limit, includeNSFW)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch related hubs", "details": err.Error()})
+		return
+	}
+
+	out := make([]gin.H, len(related))
+	for i, r := range related {
+		out[i] = hubResponse(&r.Hub)
+		out[i]["shared_subscribers"] = r.SharedSubscribers
+	}
+	c.JSON(http.StatusOK, gin.H{"hubs": out})
+}
+
 // GetTrendingHubs handles GET /api/v1/hubs/trending (popular hubs)
 func (h *HubsHandler) GetTrendingHubs(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+)
+
+// Reddit usernames are 3-20 characters of letters, digits, underscores and hyphens
+var redditUsernamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]{3,20}$`)
+
+// KnownBotsHandler handles admin management of the Reddit known-bot list
+type KnownBotsHandler struct {
+	botRepo   *models.RedditKnownBotRepository
+	knownBots *services.KnownBots
+}
+
+// NewKnownBotsHandler creates a new known bots handler
+func NewKnownBotsHandler(botRepo *models.RedditKnownBotRepository, knownBots *services.KnownBots) *KnownBotsHandler {
+	return &KnownBotsHandler{
+		botRepo:   botRepo,
+		knownBots: knownBots,
+	}
+}
+
+// ListBots handles GET /api/v1/admin/reddit-bots
+// Returns admin-added bots; the built-in list comes from configuration
+func (h *KnownBotsHandler) ListBots(c *gin.Context) {
+	bots, err := h.botRepo.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch known bots"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"bots": bots})
+}
+
+// AddBot handles POST /api/v1/admin/reddit-bots
+func (h *KnownBotsHandler) AddBot(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request", err)
+		return
+	}
+	if !redditUsernamePattern.MatchString(req.Username) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Reddit username"})
+		return
+	}
+
+	bot, err := h.botRepo.Add(c.Request.Context(), req.Username, c.GetInt("user_id"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add known bot"})
+		return
+	}
+
+	h.knownBots.Invalidate()
+	c.JSON(http.StatusCreated, bot)
+}
+
+// RemoveBot handles DELETE /api/v1/admin/reddit-bots/:username
+func (h *KnownBotsHandler) RemoveBot(c *gin.Context) {
+	removed, err := h.botRepo.Remove(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove known bot"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Known bot not found"})
+		return
+	}
+
+	h.knownBots.Invalidate()
+	c.JSON(http.StatusOK, gin.H{"message": "Known bot removed"})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -22,6 +23,7 @@ const (
 type MediaHandler struct {
 	mediaRepo        *models.MediaFileRepository
 	thumbnailService *services.ThumbnailService
+	processor        *services.MediaProcessor
 }
 
 // NewMediaHandler creates a new media handler
@@ -32,6 +34,11 @@ func NewMediaHandler(mediaRepo *models.MediaFileRepository, thumbnailService *se
 	}
 }
 
+// SetMediaProcessor enables background transcoding of uploaded videos (called after initialization)
+func (h *MediaHandler) SetMediaProcessor(processor *services.MediaProcessor) {
+	h.processor = processor
+}
+
 // UploadMedia handles POST /api/v1/media/upload
 func (h *MediaHandler) UploadMedia(c *gin.Context) {
 	// Get user ID from context
@@ -139,10 +146,57 @@ func (h *MediaHandler) UploadMedia(c *gin.Context) {
 		}
 	}
 
+	// Videos are transcoded to a web-friendly rendition in the background
+	transcode := h.processor.NeedsProcessing(contentType, safeName)
+	if transcode {
+		media.ProcessingStatus = models.MediaStatusProcessing
+	}
+
 	if err := h.mediaRepo.Create(c.Request.Context(), media); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save media record", "details": err.Error()})
 		return
 	}
 
+	if transcode {
+		h.processor.Enqueue(media)
+	}
+
 	c.JSON(http.StatusCreated, media)
 }
+
+// attachMediaStatus marks posts whose media is an upload with its processing
+// status, and points finished videos at their transcoded rendition
+func attachMediaStatus(ctx context.Context, mediaRepo *models.MediaFileRepository, posts []*models.PlatformPost) error {
+	if mediaRepo == nil {
+		return nil
+	}
+
+	var urls []string
+	for _, post := range posts {
+		if post.MediaURL != nil && *post.MediaURL != "" {
+			urls = append(urls, *post.MediaURL)
+		}
+	}
+	if len(urls) == 0 {
+		return nil
+	}
+
+	statuses, err := mediaRepo.GetStatusByURLs(ctx, urls)
+	if err != nil {
+		return err
+	}
+	for _, post := range posts {
+		if post.MediaURL == nil {
+			continue
+		}
+		status, ok := statuses[*post.MediaURL]
+		if !ok {
+			continue
+		}
+		post.MediaStatus = &status.Status
+		if status.Status == models.MediaStatusReady && status.PlayableURL != nil {
+			post.MediaURL = status.PlayableURL
+		}
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateComment_NotifiesMentionedUsersOnce(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	notifRepo := models.NewNotificationRepository(db.Pool)
+	settingsRepo := models.NewUserSettingsRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	createUser := func(name string) *models.User {
+		user := &models.User{Username: fmt.Sprintf("%s_%d", name, suffix), PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		return user
+	}
+	author := createUser("mention_author")
+	alice := createUser("mention_alice")
+	blocker := createUser("mention_blocker")
+	muted := createUser("mention_muted")
+
+	_, err = db.Pool.Exec(ctx, `INSERT INTO blocked_users (blocker_id, blocked_id) VALUES ($1, $2)`, blocker.ID, author.ID)
+	require.NoError(t, err)
+	_, err = settingsRepo.UpdateNotificationPreferences(ctx, muted.ID, models.NotificationPreferences{models.NotificationCategoryMention: false})
+	require.NoError(t, err)
+
+	hub := &models.Hub{Name: fmt.Sprintf("mentionhub_%d", suffix), CreatedBy: &author.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	post := &models.PlatformPost{AuthorID: author.ID, HubID: &hub.ID, Title: "Mentions"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	notifService := services.NewNotificationService(
+		db.Pool,
+		notifRepo,
+		models.NewUserBaselineRepository(db.Pool),
+		models.NewNotificationBatchRepository(db.Pool),
+		settingsRepo,
+		postRepo,
+		commentRepo,
+		userRepo,
+		nil,
+	)
+	handler := NewCommentsHandler(commentRepo, postRepo, models.NewHubModeratorRepository(db.Pool))
+	handler.SetNotificationService(notifService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/posts/:id/comments", authMiddleware(author.ID), handler.CreateComment)
+
+	body := fmt.Sprintf("@%s, have you seen this? cc @%s @%s @%s @%s @mention_nobody_%d\n```\n@%s\n```",
+		alice.Username, alice.Username, author.Username, blocker.Username, muted.Username, suffix, alice.Username)
+	payload, err := json.Marshal(gin.H{"body": body})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%d/comments", post.ID), bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var comment models.PostComment
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &comment))
+
+	notifs, err := notifRepo.GetByUserID(ctx, alice.ID, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "mention", notifs[0].NotificationType)
+	require.NotNil(t, notifs[0].ContentID)
+	assert.Equal(t, comment.ID, *notifs[0].ContentID)
+	require.NotNil(t, notifs[0].ActorID)
+	assert.Equal(t, author.ID, *notifs[0].ActorID)
+
+	// Self-mentions, users who blocked the author and muted users get nothing
+	for _, userID := range []int{author.ID, blocker.ID, muted.ID} {
+		notifs, err := notifRepo.GetByUserID(ctx, userID, 10, 0, false)
+		require.NoError(t, err)
+		assert.Empty(t, notifs, "user %d", userID)
+	}
+}
+
+func TestCreateComment_MentionsSkipRemovedCommentsAndReplyRecipients(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	notifRepo := models.NewNotificationRepository(db.Pool)
+	automodRepo := models.NewAutomodRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	createUser := func(name string) *models.User {
+		user := &models.User{Username: fmt.Sprintf("%s_%d", name, suffix), PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		return user
+	}
+	owner := createUser("dedupe_owner")
+	parentAuthor := createUser("dedupe_parent")
+	replier := createUser("dedupe_replier")
+	bystander := createUser("dedupe_bystander")
+
+	hub := &models.Hub{Name: fmt.Sprintf("dedupehub_%d", suffix), CreatedBy: &owner.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, automodRepo.Create(ctx, &models.AutomodRule{HubID: hub.ID, Pattern: "forbidden", CreatedBy: owner.ID}))
+	post := &models.PlatformPost{AuthorID: owner.ID, HubID: &hub.ID, Title: "Dedupe"}
+	require.NoError(t, postRepo.Create(ctx, post))
+	parent := &models.PostComment{PostID: post.ID, UserID: parentAuthor.ID, Body: "First"}
+	require.NoError(t, commentRepo.Create(ctx, parent))
+
+	notifService := services.NewNotificationService(
+		db.Pool,
+		notifRepo,
+		models.NewUserBaselineRepository(db.Pool),
+		models.NewNotificationBatchRepository(db.Pool),
+		models.NewUserSettingsRepository(db.Pool),
+		postRepo,
+		commentRepo,
+		userRepo,
+		nil,
+	)
+	handler := NewCommentsHandler(commentRepo, postRepo, models.NewHubModeratorRepository(db.Pool))
+	handler.SetNotificationService(notifService)
+	handler.SetAutomod(automodRepo, models.NewModLogRepository(db.Pool))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/posts/:id/comments", authMiddleware(replier.ID), handler.CreateComment)
+	comment := func(payload gin.H) {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/posts/%d/comments", post.ID), bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	}
+
+	// Mentioning the author being replied to only yields the reply notification
+	comment(gin.H{"body": "Agreed, @" + parentAuthor.Username, "parent_comment_id": parent.ID})
+	notifs, err := notifRepo.GetByUserID(ctx, parentAuthor.ID, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "comment_reply", notifs[0].NotificationType)
+
+	// A comment automod removes doesn't notify anyone it mentions
+	comment(gin.H{"body": "forbidden words for @" + bystander.Username})
+	notifs, err = notifRepo.GetByUserID(ctx, bystander.ID, 10, 0, false)
+	require.NoError(t, err)
+	assert.Empty(t, notifs)
+}
+
+func TestCreatePost_UnknownMentionDoesNotDropLaterMentions(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	notifRepo := models.NewNotificationRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	author := &models.User{Username: fmt.Sprintf("unknown_author_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, author))
+	bob := &models.User{Username: fmt.Sprintf("unknown_bob_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, bob))
+	post := &models.PlatformPost{AuthorID: author.ID, Title: "Unknown first"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	notifService := services.NewNotificationService(
+		db.Pool,
+		notifRepo,
+		models.NewUserBaselineRepository(db.Pool),
+		models.NewNotificationBatchRepository(db.Pool),
+		models.NewUserSettingsRepository(db.Pool),
+		postRepo,
+		commentRepo,
+		userRepo,
+		nil,
+	)
+
+	text := fmt.Sprintf("@mention_nobody_%d and @%s", suffix, bob.Username)
+	require.NoError(t, notifService.NotifyMentions(ctx, "post", post.ID, author.ID, text))
+
+	notifs, err := notifRepo.GetByUserID(ctx, bob.ID, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "mention", notifs[0].NotificationType)
+}
+
+func TestCreatePost_MentionsSkipUsersOutsidePrivateHub(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	notifRepo := models.NewNotificationRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	createUser := func(name string) *models.User {
+		user := &models.User{Username: fmt.Sprintf("%s_%d", name, suffix), PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		return user
+	}
+	owner := createUser("private_owner")
+	member := createUser("private_member")
+	outsider := createUser("private_outsider")
+
+	hub := &models.Hub{Name: fmt.Sprintf("privatementions_%d", suffix), Type: "private", CreatedBy: &owner.ID}
+	require.NoError(t, models.NewHubRepository(db.Pool).Create(ctx, hub))
+	require.NoError(t, models.NewHubMemberRepository(db.Pool).AddMember(ctx, hub.ID, member.ID, owner.ID))
+	post := &models.PlatformPost{AuthorID: owner.ID, HubID: &hub.ID, Title: "Members only"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	notifService := services.NewNotificationService(
+		db.Pool,
+		notifRepo,
+		models.NewUserBaselineRepository(db.Pool),
+		models.NewNotificationBatchRepository(db.Pool),
+		models.NewUserSettingsRepository(db.Pool),
+		postRepo,
+		commentRepo,
+		userRepo,
+		nil,
+	)
+
+	text := fmt.Sprintf("@%s and @%s", outsider.Username, member.Username)
+	require.NoError(t, notifService.NotifyMentions(ctx, "post", post.ID, owner.ID, text))
+
+	notifs, err := notifRepo.GetByUserID(ctx, member.ID, 10, 0, false)
+	require.NoError(t, err)
+	assert.Len(t, notifs, 1)
+	notifs, err = notifRepo.GetByUserID(ctx, outsider.ID, 10, 0, false)
+	require.NoError(t, err)
+	assert.Empty(t, notifs, "users who can't see the hub aren't told about the post")
+}
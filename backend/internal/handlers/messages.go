@@ -1,22 +1,45 @@
 package handlers
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/omninudge/backend/internal/models"
 	"github.com/omninudge/backend/internal/websocket"
 )
 
+const (
+	// typingDebounce is the minimum gap between typing events relayed for the same user and conversation
+	typingDebounce = 800 * time.Millisecond
+	// typingTTL is how long a recipient should show the indicator without a fresh event
+	typingTTL = 5 * time.Second
+)
+
+type typingKey struct {
+	conversationID int
+	userID         int
+}
+
 // MessagesHandler handles HTTP requests for messages
 type MessagesHandler struct {
 	pool             *pgxpool.Pool
 	messageRepo      *models.MessageRepository
 	conversationRepo *models.ConversationRepository
 	hub              HubInterface
+
+	typingMu   sync.Mutex
+	lastTyping map[typingKey]time.Time
 }
 
 // HubInterface defines the methods we need from the WebSocket hub
@@ -37,6 +60,7 @@ func NewMessagesHandler(
 		messageRepo:      messageRepo,
 		conversationRepo: conversationRepo,
 		hub:              hub,
+		lastTyping:       make(map[typingKey]time.Time),
 	}
 }
 
@@ -67,7 +91,7 @@ func (h *MessagesHandler) SendMessage(c *gin.Context) {
 
 	var req SendMessageRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -240,6 +264,19 @@ func (h *MessagesHandler) GetMessages(c *gin.Context) {
 		return
 	}
 
+	messageIDs := make([]int, 0, len(messages))
+	for _, message := range messages {
+		messageIDs = append(messageIDs, message.ID)
+	}
+	reactions, err := h.messageRepo.GetReactions(c.Request.Context(), messageIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reactions", "details": err.Error()})
+		return
+	}
+	for _, message := range messages {
+		message.Reactions = reactions[message.ID]
+	}
+
 	// Mark undelivered messages as delivered for this recipient and notify senders
 	if h.hub != nil {
 		delivered, err := h.messageRepo.MarkUndeliveredAsDelivered(c.Request.Context(), conversationID, userID.(int))
@@ -264,6 +301,184 @@ func (h *MessagesHandler) GetMessages(c *gin.Context) {
 	})
 }
 
+// SearchMessages handles GET /api/v1/conversations/:id/messages/search
+// Filters the caller's visible messages by type and an RFC3339 sent_at range (from/to).
+func (h *MessagesHandler) SearchMessages(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	conversationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	filters := models.MessageSearchFilters{
+		MessageType: strings.ToLower(strings.TrimSpace(c.Query("type"))),
+	}
+	if filters.MessageType != "" {
+		validTypes := map[string]bool{"text": true, "image": true, "video": true, "audio": true, "file": true}
+		if !validTypes[filters.MessageType] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message type. Must be: text, image, video, audio, or file"})
+			return
+		}
+	}
+
+	for _, bound := range []struct {
+		param string
+		dest  **time.Time
+	}{{"from", &filters.From}, {"to", &filters.To}} {
+		raw := strings.TrimSpace(c.Query(bound.param))
+		if raw == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid %s time. Must be RFC3339", bound.param)})
+			return
+		}
+		parsed = parsed.UTC()
+		*bound.dest = &parsed
+	}
+	if filters.From != nil && filters.To != nil && filters.To.Before(*filters.From) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to must not be before from"})
+		return
+	}
+
+	conversation, err := h.conversationRepo.GetByID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversation", "details": err.Error()})
+		return
+	}
+
+	if conversation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	if !conversation.IsParticipant(userID.(int)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a participant in this conversation"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	filters.Limit = limit
+	filters.Offset = offset
+
+	messages, err := h.messageRepo.SearchInConversation(c.Request.Context(), conversationID, userID.(int), filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search messages", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"messages": messages,
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
+
+// ExportConversation handles GET /api/v1/conversations/:id/export
+// Streams the requesting participant's view of the conversation as JSON (default) or NDJSON.
+// Message bodies stay end-to-end encrypted; clients decrypt the export with their own keys.
+func (h *MessagesHandler) ExportConversation(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	conversationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", "json"))
+	if format != "json" && format != "ndjson" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid format. Must be 'json' or 'ndjson'"})
+		return
+	}
+
+	conversation, err := h.conversationRepo.GetByID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversation", "details": err.Error()})
+		return
+	}
+
+	if conversation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	if !conversation.IsParticipant(userID.(int)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a participant in this conversation"})
+		return
+	}
+
+	filename := fmt.Sprintf("conversation-%d.%s", conversationID, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if format == "ndjson" {
+		c.Header("Content-Type", "application/x-ndjson")
+	} else {
+		c.Header("Content-Type", "application/json")
+	}
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+
+	if format == "json" {
+		header, _ := json.Marshal(gin.H{
+			"conversation_id": conversationID,
+			"exported_by":     userID.(int),
+			"exported_at":     time.Now().UTC(),
+		})
+		// Reopen the header object so messages can be streamed into it
+		_, _ = c.Writer.Write(header[:len(header)-1])
+		_, _ = c.Writer.WriteString(`,"messages":[`)
+	}
+
+	err = h.messageRepo.StreamByConversationID(c.Request.Context(), conversationID, userID.(int), func(message *models.Message) error {
+		if format == "json" && count > 0 {
+			if _, err := c.Writer.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if err := encoder.Encode(message); err != nil {
+			return err
+		}
+		count++
+		if count%exportFlushInterval == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the best we can do is stop the stream and surface the error
+		_ = c.Error(err)
+		return
+	}
+
+	if format == "json" {
+		_, _ = c.Writer.WriteString(fmt.Sprintf(`],"message_count":%d}`, count))
+	}
+	c.Writer.Flush()
+}
+
+// exportFlushInterval controls how many exported messages are buffered between flushes
+const exportFlushInterval = 100
+
 // MarkAsRead handles POST /api/v1/conversations/:id/read
 func (h *MessagesHandler) MarkAsRead(c *gin.Context) {
 	// Get user ID from context
@@ -363,6 +578,67 @@ func (h *MessagesHandler) MarkAsRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Messages marked as read"})
 }
 
+// readAllBatchSize caps how many message IDs go into one messages_read event
+const readAllBatchSize = 200
+
+// MarkAllAsRead handles POST /api/v1/conversations/read-all
+// Marks every message addressed to the user as read, in all of their conversations.
+func (h *MessagesHandler) MarkAllAsRead(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+	readerID := userID.(int)
+
+	affected, err := h.messageRepo.MarkAllReadForUser(c.Request.Context(), readerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark messages as read", "details": err.Error()})
+		return
+	}
+
+	// Each sender gets one event per batch rather than one per message,
+	// then a single conversation_read per conversation
+	markedCount := 0
+	for _, conv := range affected {
+		markedCount += conv.MarkedCount
+		if h.hub == nil {
+			continue
+		}
+
+		for start := 0; start < len(conv.MessageIDs); start += readAllBatchSize {
+			end := start + readAllBatchSize
+			if end > len(conv.MessageIDs) {
+				end = len(conv.MessageIDs)
+			}
+			h.hub.Broadcast(&websocket.Message{
+				RecipientID: conv.SenderID,
+				Type:        "messages_read",
+				Payload: gin.H{
+					"message_ids":     conv.MessageIDs[start:end],
+					"conversation_id": conv.ConversationID,
+					"reader_id":       readerID,
+				},
+			})
+		}
+
+		h.hub.Broadcast(&websocket.Message{
+			RecipientID: conv.SenderID,
+			Type:        "conversation_read",
+			Payload: gin.H{
+				"conversation_id": conv.ConversationID,
+				"reader_id":       readerID,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":            "All messages marked as read",
+		"marked_count":       markedCount,
+		"conversation_count": len(affected),
+	})
+}
+
 // MarkSingleMessageAsRead handles POST /api/v1/messages/:id/read
 func (h *MessagesHandler) MarkSingleMessageAsRead(c *gin.Context) {
 	// Get user ID from context
@@ -424,6 +700,330 @@ func (h *MessagesHandler) MarkSingleMessageAsRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Message marked as read"})
 }
 
+// TypingRequest represents the optional request body for a typing indicator
+type TypingRequest struct {
+	IsTyping *bool `json:"is_typing,omitempty"` // Defaults to true
+}
+
+// SendTyping handles POST /api/v1/conversations/:id/typing
+// Relays a typing indicator to the other participant. Nothing is persisted; repeated
+// "is typing" events inside typingDebounce are dropped, while "stopped typing" always goes through.
+func (h *MessagesHandler) SendTyping(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	conversationID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid conversation ID"})
+		return
+	}
+
+	var req TypingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondBindingError(c, "Invalid request body", err)
+			return
+		}
+	}
+	isTyping := req.IsTyping == nil || *req.IsTyping
+
+	conversation, err := h.conversationRepo.GetByID(c.Request.Context(), conversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get conversation", "details": err.Error()})
+		return
+	}
+
+	if conversation == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	if !conversation.IsParticipant(userID.(int)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a participant in this conversation"})
+		return
+	}
+
+	now := time.Now()
+	if !h.allowTyping(typingKey{conversationID: conversationID, userID: userID.(int)}, isTyping, now) {
+		c.JSON(http.StatusOK, gin.H{"debounced": true})
+		return
+	}
+
+	recipientID := conversation.GetOtherUserID(userID.(int))
+	if h.hub != nil && h.hub.IsUserOnline(recipientID) {
+		h.hub.Broadcast(&websocket.Message{
+			RecipientID: recipientID,
+			Type:        "typing",
+			Payload: gin.H{
+				"conversation_id": conversationID,
+				"user_id":         userID.(int),
+				"is_typing":       isTyping,
+				"expires_at":      now.Add(typingTTL).UTC(),
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"debounced": false})
+}
+
+// allowTyping reports whether a typing event should be relayed and records it.
+// Stop events always pass and clear the debounce state for the key.
+func (h *MessagesHandler) allowTyping(key typingKey, isTyping bool, now time.Time) bool {
+	h.typingMu.Lock()
+	defer h.typingMu.Unlock()
+
+	if !isTyping {
+		delete(h.lastTyping, key)
+		return true
+	}
+
+	if last, ok := h.lastTyping[key]; ok && now.Sub(last) < typingDebounce {
+		return false
+	}
+
+	// Drop entries nobody has refreshed recently so the map can't grow without bound
+	if len(h.lastTyping) >= 1024 {
+		for k, last := range h.lastTyping {
+			if now.Sub(last) > typingTTL {
+				delete(h.lastTyping, k)
+			}
+		}
+	}
+	h.lastTyping[key] = now
+	return true
+}
+
+// EditMessageRequest represents the request body for editing a message
+type EditMessageRequest struct {
+	EncryptedContent       string  `json:"encrypted_content" binding:"required"`
+	SenderEncryptedContent *string `json:"sender_encrypted_content,omitempty"`
+}
+
+// EditMessage handles PUT /api/v1/messages/:id
+// Only the sender may edit, and only until the recipient has read the message.
+func (h *MessagesHandler) EditMessage(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return
+	}
+
+	var req EditMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+	if strings.TrimSpace(req.EncryptedContent) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message content is required"})
+		return
+	}
+
+	message, err := h.messageRepo.GetByID(c.Request.Context(), messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get message", "details": err.Error()})
+		return
+	}
+
+	if message.SenderID != userID.(int) || message.DeletedForSender {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only edit messages you sent"})
+		return
+	}
+
+	if message.ReadAt != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "Message has already been read and can no longer be edited"})
+		return
+	}
+
+	err = h.messageRepo.UpdateContent(c.Request.Context(), messageID, userID.(int), req.EncryptedContent, req.SenderEncryptedContent)
+	if err != nil {
+		if errors.Is(err, models.ErrMessageNotEditable) {
+			// The recipient read it between the check above and the update
+			c.JSON(http.StatusConflict, gin.H{"error": "Message has already been read and can no longer be edited"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to edit message", "details": err.Error()})
+		return
+	}
+
+	updated, err := h.messageRepo.GetByID(c.Request.Context(), messageID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get message", "details": err.Error()})
+		return
+	}
+
+	// Let the recipient replace the message in place if they're online
+	if h.hub != nil && !updated.DeletedForRecipient && h.hub.IsUserOnline(updated.RecipientID) {
+		h.hub.Broadcast(&websocket.Message{
+			RecipientID: updated.RecipientID,
+			Type:        "message_edited",
+			Payload:     updated,
+		})
+	}
+
+	c.JSON(http.StatusOK, updated)
+}
+
+// maxReactionEmojiBytes matches the message_reactions.emoji column width
+const maxReactionEmojiBytes = 32
+
+// ReactionRequest represents the request body for reacting to a message
+type ReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required"`
+}
+
+// AddReaction handles POST /api/v1/messages/:id/reactions
+// Adding the same emoji twice is idempotent and returns 200 instead of 201.
+func (h *MessagesHandler) AddReaction(c *gin.Context) {
+	var req ReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
+	emoji, ok := normalizeReactionEmoji(req.Emoji)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emoji"})
+		return
+	}
+
+	message, userID, ok := h.reactionTarget(c)
+	if !ok {
+		return
+	}
+
+	added, err := h.messageRepo.AddReaction(c.Request.Context(), message.ID, userID, emoji)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add reaction", "details": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if added {
+		status = http.StatusCreated
+	}
+	h.respondWithReactions(c, status, message, userID, emoji, "added", added)
+}
+
+// RemoveReaction handles DELETE /api/v1/messages/:id/reactions/:emoji
+func (h *MessagesHandler) RemoveReaction(c *gin.Context) {
+	emoji, ok := normalizeReactionEmoji(c.Param("emoji"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid emoji"})
+		return
+	}
+
+	message, userID, ok := h.reactionTarget(c)
+	if !ok {
+		return
+	}
+
+	removed, err := h.messageRepo.RemoveReaction(c.Request.Context(), message.ID, userID, emoji)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reaction", "details": err.Error()})
+		return
+	}
+
+	h.respondWithReactions(c, http.StatusOK, message, userID, emoji, "removed", removed)
+}
+
+// reactionTarget loads the message from the :id param and checks the caller can react to it.
+// It writes the error response and returns ok=false when they can't.
+func (h *MessagesHandler) reactionTarget(c *gin.Context) (*models.Message, int, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return nil, 0, false
+	}
+
+	messageID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message ID"})
+		return nil, 0, false
+	}
+
+	message, err := h.messageRepo.GetByID(c.Request.Context(), messageID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Message not found"})
+			return nil, 0, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get message", "details": err.Error()})
+		return nil, 0, false
+	}
+
+	if !message.IsVisibleToUser(userID.(int)) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not a participant in this conversation"})
+		return nil, 0, false
+	}
+
+	return message, userID.(int), true
+}
+
+// respondWithReactions replies with the message's current reactions and, when
+// the reaction actually changed, tells the other participant over the websocket.
+func (h *MessagesHandler) respondWithReactions(c *gin.Context, status int, message *models.Message, userID int, emoji, action string, changed bool) {
+	reactions, err := h.messageRepo.GetReactions(c.Request.Context(), []int{message.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get reactions", "details": err.Error()})
+		return
+	}
+	current := reactions[message.ID]
+	if current == nil {
+		current = []models.MessageReaction{}
+	}
+
+	otherUserID := message.SenderID
+	if userID == message.SenderID {
+		otherUserID = message.RecipientID
+	}
+	if changed && h.hub != nil && h.hub.IsUserOnline(otherUserID) {
+		h.hub.Broadcast(&websocket.Message{
+			RecipientID: otherUserID,
+			Type:        "message_reaction",
+			Payload: gin.H{
+				"message_id":      message.ID,
+				"conversation_id": message.ConversationID,
+				"user_id":         userID,
+				"emoji":           emoji,
+				"action":          action,
+				"reactions":       current,
+			},
+		})
+	}
+
+	c.JSON(status, gin.H{
+		"message_id": message.ID,
+		"reactions":  current,
+	})
+}
+
+// normalizeReactionEmoji trims the emoji and rejects empty, oversized or whitespace-containing values
+func normalizeReactionEmoji(raw string) (string, bool) {
+	emoji := strings.TrimSpace(raw)
+	if emoji == "" || len(emoji) > maxReactionEmojiBytes || !utf8.ValidString(emoji) {
+		return "", false
+	}
+	for _, r := range emoji {
+		if unicode.IsSpace(r) || unicode.IsControl(r) {
+			return "", false
+		}
+	}
+	return emoji, true
+}
+
 // DeleteMessage handles DELETE /api/v1/messages/:id
 func (h *MessagesHandler) DeleteMessage(c *gin.Context) {
 	// Get user ID from context
@@ -433,12 +1033,13 @@ func (h *MessagesHandler) DeleteMessage(c *gin.Context) {
 		return
 	}
 
+	// "me" and "self" both hide the message for the caller only
 	deleteScope := strings.ToLower(strings.TrimSpace(c.DefaultQuery("delete_for", "self")))
-	if deleteScope == "" {
+	if deleteScope == "" || deleteScope == "me" {
 		deleteScope = "self"
 	}
 	if deleteScope != "self" && deleteScope != "both" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delete_for value. Must be 'self' or 'both'"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delete_for value. Must be 'me', 'self' or 'both'"})
 		return
 	}
 
@@ -475,21 +1076,17 @@ func (h *MessagesHandler) DeleteMessage(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message for both users", "details": err.Error()})
 			return
 		}
-	} else {
-		// Soft delete for this user
-		if err := h.messageRepo.SoftDeleteForUser(c.Request.Context(), messageID, userID.(int)); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message", "details": err.Error()})
-			return
-		}
-	}
-
-	// Attempt hard delete if both users have deleted
-	// (This will silently fail if not both deleted, which is fine)
-	_ = h.messageRepo.HardDelete(c.Request.Context(), messageID)
+		_ = h.messageRepo.HardDelete(c.Request.Context(), messageID)
 
-	if deleteScope == "both" {
 		c.JSON(http.StatusOK, gin.H{"message": "Message deleted for both users"})
-	} else {
-		c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
+		return
 	}
+
+	// Hide for this user only; hard-deleted once the other participant has hidden it too
+	if _, err := h.messageRepo.HideForUser(c.Request.Context(), messageID, userID.(int)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete message", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Message deleted successfully"})
 }
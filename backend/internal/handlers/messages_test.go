@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -349,6 +350,115 @@ func TestGetMessages_NotParticipant(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
 
+// createSearchFixtures seeds one text and two image messages with fixed sent_at values
+func createSearchFixtures(t *testing.T, db *database.Database, convID, senderID, recipientID int) (text, oldImage, newImage *models.Message) {
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+	base := time.Date(2024, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	create := func(messageType string, sentAt time.Time) *models.Message {
+		msg := &models.Message{
+			ConversationID:    convID,
+			SenderID:          senderID,
+			RecipientID:       recipientID,
+			EncryptedContent:  "ciphertext",
+			MessageType:       messageType,
+			EncryptionVersion: "v1",
+		}
+		require.NoError(t, messageRepo.Create(ctx, msg))
+		_, err := db.Pool.Exec(ctx, `UPDATE messages SET sent_at = $2 WHERE id = $1`, msg.ID, sentAt)
+		require.NoError(t, err)
+		return msg
+	}
+
+	text = create("text", base)
+	oldImage = create("image", base.Add(-10*24*time.Hour))
+	newImage = create("image", base.Add(-2*24*time.Hour))
+	return text, oldImage, newImage
+}
+
+func searchMessageIDs(t *testing.T, handler *MessagesHandler, userID, convID int, query string) (int, []int) {
+	router := gin.Default()
+	router.GET("/conversations/:id/messages/search", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.SearchMessages(c)
+	})
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/conversations/%d/messages/search?%s", convID, query), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		return w.Code, nil
+	}
+
+	var response struct {
+		Messages []models.Message `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	ids := make([]int, 0, len(response.Messages))
+	for _, m := range response.Messages {
+		ids = append(ids, m.ID)
+	}
+	return w.Code, ids
+}
+
+func TestSearchMessages_TypeFilter(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	_, oldImage, newImage := createSearchFixtures(t, db, convID, user1ID, user2ID)
+
+	code, ids := searchMessageIDs(t, handler, user1ID, convID, "type=image")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int{newImage.ID, oldImage.ID}, ids, "only images, newest first")
+
+	code, ids = searchMessageIDs(t, handler, user2ID, convID, "type=image&limit=1&offset=1")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int{oldImage.ID}, ids)
+
+	code, _ = searchMessageIDs(t, handler, user1ID, convID, "type=gif")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestSearchMessages_DateRange(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	text, _, newImage := createSearchFixtures(t, db, convID, user1ID, user2ID)
+
+	code, ids := searchMessageIDs(t, handler, user1ID, convID, "from=2024-03-05T00:00:00Z&to=2024-03-10T12:00:00Z")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int{text.ID, newImage.ID}, ids, "range bounds are inclusive")
+
+	code, ids = searchMessageIDs(t, handler, user1ID, convID, "type=image&from=2024-03-05T00:00:00Z")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []int{newImage.ID}, ids)
+
+	code, _ = searchMessageIDs(t, handler, user1ID, convID, "from=last-week")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = searchMessageIDs(t, handler, user1ID, convID, "from=2024-03-10T00:00:00Z&to=2024-03-01T00:00:00Z")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestSearchMessages_NotParticipant(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	createSearchFixtures(t, db, convID, user1ID, user2ID)
+
+	userRepo := models.NewUserRepository(db.Pool)
+	outsider := &models.User{
+		Username:     uniqueMessagesUsername("search_outsider"),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, userRepo.Create(context.Background(), outsider))
+
+	code, ids := searchMessageIDs(t, handler, outsider.ID, convID, "type=image")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.Empty(t, ids)
+}
+
 func TestMarkMessagesAsRead(t *testing.T) {
 	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
 	defer cleanup()
@@ -429,6 +539,325 @@ func TestMarkMessagesAsRead_NotParticipant(t *testing.T) {
 	assert.Equal(t, http.StatusForbidden, w.Code)
 }
 
+func TestSendTyping_BroadcastsToOtherParticipantOnly(t *testing.T) {
+	handler, _, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	hub.onlineUsers[user1ID] = true
+	hub.onlineUsers[user2ID] = true
+
+	router := gin.Default()
+	router.POST("/conversations/:id/typing", func(c *gin.Context) {
+		c.Set("user_id", user1ID)
+		handler.SendTyping(c)
+	})
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", fmt.Sprintf("/conversations/%d/typing", convID), bytes.NewBufferString(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := send("")
+	assert.Equal(t, http.StatusOK, w.Code, "Response body: %s", w.Body.String())
+
+	require.Len(t, hub.broadcastCalls, 1)
+	event := hub.broadcastCalls[0]
+	assert.Equal(t, "typing", event.Type)
+	assert.Equal(t, user2ID, event.RecipientID, "typing event must go to the other participant, not back to the sender")
+	payload, ok := event.Payload.(gin.H)
+	require.True(t, ok)
+	assert.Equal(t, convID, payload["conversation_id"])
+	assert.Equal(t, user1ID, payload["user_id"])
+	assert.Equal(t, true, payload["is_typing"])
+
+	// A burst of keystrokes is debounced server-side
+	w = send(`{"is_typing": true}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"debounced":true`)
+	assert.Len(t, hub.broadcastCalls, 1)
+
+	// Stopping is always relayed
+	w = send(`{"is_typing": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, hub.broadcastCalls, 2)
+	assert.Equal(t, user2ID, hub.broadcastCalls[1].RecipientID)
+	assert.Equal(t, false, hub.broadcastCalls[1].Payload.(gin.H)["is_typing"])
+
+	for _, call := range hub.broadcastCalls {
+		assert.NotEqual(t, user1ID, call.RecipientID)
+	}
+}
+
+func TestSendTyping_NotParticipant(t *testing.T) {
+	handler, db, _, _, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	userRepo := models.NewUserRepository(db.Pool)
+	outsider := &models.User{
+		Username:     uniqueMessagesUsername("outsider"),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, userRepo.Create(context.Background(), outsider))
+
+	router := gin.Default()
+	router.POST("/conversations/:id/typing", func(c *gin.Context) {
+		c.Set("user_id", outsider.ID)
+		handler.SendTyping(c)
+	})
+
+	req := httptest.NewRequest("POST", fmt.Sprintf("/conversations/%d/typing", convID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, hub.broadcastCalls, 0)
+}
+
+func newReactionRouter(handler *MessagesHandler, userID int) *gin.Engine {
+	router := gin.Default()
+	router.POST("/messages/:id/reactions", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.AddReaction(c)
+	})
+	router.DELETE("/messages/:id/reactions/:emoji", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.RemoveReaction(c)
+	})
+	return router
+}
+
+func postReaction(router *gin.Engine, messageID int, emoji string) *httptest.ResponseRecorder {
+	bodyJSON, _ := json.Marshal(map[string]string{"emoji": emoji})
+	req := httptest.NewRequest("POST", fmt.Sprintf("/messages/%d/reactions", messageID), bytes.NewBuffer(bodyJSON))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestMessageReactions_AddDuplicateRemove(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	hub.onlineUsers[user1ID] = true
+
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+	message := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "react to me",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(ctx, message))
+
+	router := newReactionRouter(handler, user2ID)
+
+	// Add
+	w := postReaction(router, message.ID, "👍")
+	assert.Equal(t, http.StatusCreated, w.Code, "Response body: %s", w.Body.String())
+	require.Len(t, hub.broadcastCalls, 1)
+	assert.Equal(t, "message_reaction", hub.broadcastCalls[0].Type)
+	assert.Equal(t, user1ID, hub.broadcastCalls[0].RecipientID, "reaction goes to the other participant")
+
+	// Duplicate is idempotent and not re-broadcast
+	w = postReaction(router, message.ID, "👍")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, hub.broadcastCalls, 1)
+
+	reactions, err := messageRepo.GetReactions(ctx, []int{message.ID})
+	require.NoError(t, err)
+	require.Len(t, reactions[message.ID], 1)
+	assert.Equal(t, models.MessageReaction{Emoji: "👍", Count: 1, UserIDs: []int{user2ID}}, reactions[message.ID][0])
+
+	// Reactions show up in GetMessages
+	messagesRouter := gin.Default()
+	messagesRouter.GET("/conversations/:id/messages", func(c *gin.Context) {
+		c.Set("user_id", user1ID)
+		handler.GetMessages(c)
+	})
+	req := httptest.NewRequest("GET", fmt.Sprintf("/conversations/%d/messages", convID), nil)
+	w = httptest.NewRecorder()
+	messagesRouter.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var listed struct {
+		Messages []models.Message `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Messages, 1)
+	require.Len(t, listed.Messages[0].Reactions, 1)
+	assert.Equal(t, 1, listed.Messages[0].Reactions[0].Count)
+
+	// Remove
+	req = httptest.NewRequest("DELETE", fmt.Sprintf("/messages/%d/reactions/%s", message.ID, url.PathEscape("👍")), nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, "Response body: %s", w.Body.String())
+	require.Len(t, hub.broadcastCalls, 2)
+	assert.Equal(t, "removed", hub.broadcastCalls[1].Payload.(gin.H)["action"])
+
+	reactions, err = messageRepo.GetReactions(ctx, []int{message.ID})
+	require.NoError(t, err)
+	assert.Empty(t, reactions[message.ID])
+}
+
+func TestMessageReactions_NotParticipant(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+	message := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "private",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(ctx, message))
+
+	outsider := &models.User{
+		Username:     uniqueMessagesUsername("reaction_outsider"),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, models.NewUserRepository(db.Pool).Create(ctx, outsider))
+
+	w := postReaction(newReactionRouter(handler, outsider.ID), message.ID, "🔥")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Len(t, hub.broadcastCalls, 0)
+
+	reactions, err := messageRepo.GetReactions(ctx, []int{message.ID})
+	require.NoError(t, err)
+	assert.Empty(t, reactions[message.ID])
+}
+
+func newEditMessageRequest(t *testing.T, messageID int, content string) *http.Request {
+	bodyJSON, err := json.Marshal(map[string]interface{}{"encrypted_content": content})
+	require.NoError(t, err)
+	req := httptest.NewRequest("PUT", fmt.Sprintf("/messages/%d", messageID), bytes.NewBuffer(bodyJSON))
+	req.Header.Set("Content-Type", "application/json")
+	return req
+}
+
+func TestEditMessage_SenderEditsUnread(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	hub.onlineUsers[user2ID] = true
+
+	messageRepo := models.NewMessageRepository(db.Pool)
+	message := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "original",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(context.Background(), message))
+
+	router := gin.Default()
+	router.PUT("/messages/:id", func(c *gin.Context) {
+		c.Set("user_id", user1ID)
+		handler.EditMessage(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newEditMessageRequest(t, message.ID, "edited"))
+
+	assert.Equal(t, http.StatusOK, w.Code, "Response body: %s", w.Body.String())
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "edited", response["encrypted_content"])
+	assert.Equal(t, true, response["is_edited"])
+	assert.NotNil(t, response["edited_at"])
+
+	stored, err := messageRepo.GetByID(context.Background(), message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "edited", stored.EncryptedContent)
+	assert.True(t, stored.IsEdited)
+	require.NotNil(t, stored.EditedAt)
+
+	require.Len(t, hub.broadcastCalls, 1)
+	assert.Equal(t, "message_edited", hub.broadcastCalls[0].Type)
+	assert.Equal(t, user2ID, hub.broadcastCalls[0].RecipientID)
+}
+
+func TestEditMessage_NotSender(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	messageRepo := models.NewMessageRepository(db.Pool)
+	message := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "original",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(context.Background(), message))
+
+	router := gin.Default()
+	router.PUT("/messages/:id", func(c *gin.Context) {
+		c.Set("user_id", user2ID) // Recipient tries to edit
+		handler.EditMessage(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newEditMessageRequest(t, message.ID, "tampered"))
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	stored, err := messageRepo.GetByID(context.Background(), message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "original", stored.EncryptedContent)
+	assert.False(t, stored.IsEdited)
+	assert.Len(t, hub.broadcastCalls, 0)
+}
+
+func TestEditMessage_AlreadyRead(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	hub.onlineUsers[user2ID] = true
+
+	messageRepo := models.NewMessageRepository(db.Pool)
+	message := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "original",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(context.Background(), message))
+	require.NoError(t, messageRepo.MarkAsRead(context.Background(), message.ID))
+
+	router := gin.Default()
+	router.PUT("/messages/:id", func(c *gin.Context) {
+		c.Set("user_id", user1ID)
+		handler.EditMessage(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newEditMessageRequest(t, message.ID, "too late"))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	stored, err := messageRepo.GetByID(context.Background(), message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "original", stored.EncryptedContent)
+	assert.Len(t, hub.broadcastCalls, 0)
+}
+
 func TestDeleteMessage(t *testing.T) {
 	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
 	defer cleanup()
@@ -460,6 +889,93 @@ func TestDeleteMessage(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 }
 
+func TestDeleteMessage_DeleteForMeHidesPerUserThenHardDeletes(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+	msg := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "hide me",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(ctx, msg))
+
+	deleteAs := func(userID int) int {
+		router := gin.Default()
+		router.DELETE("/messages/:id", func(c *gin.Context) {
+			c.Set("user_id", userID)
+			handler.DeleteMessage(c)
+		})
+		req := httptest.NewRequest("DELETE", fmt.Sprintf("/messages/%d?delete_for=me", msg.ID), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	visibleTo := func(userID int) bool {
+		messages, err := messageRepo.GetByConversationID(ctx, convID, userID, 50, 0)
+		require.NoError(t, err)
+		for _, m := range messages {
+			if m.ID == msg.ID {
+				return true
+			}
+		}
+		return false
+	}
+	exists := func() bool {
+		var found bool
+		require.NoError(t, db.Pool.QueryRow(ctx, `SELECT EXISTS(SELECT 1 FROM messages WHERE id = $1)`, msg.ID).Scan(&found))
+		return found
+	}
+
+	// Sender hides it: still stored and visible to the recipient only
+	assert.Equal(t, http.StatusOK, deleteAs(user1ID))
+	assert.True(t, exists())
+	assert.False(t, visibleTo(user1ID))
+	assert.True(t, visibleTo(user2ID))
+
+	// Recipient hides it too: nobody can see it any more, so it is removed
+	assert.Equal(t, http.StatusOK, deleteAs(user2ID))
+	assert.False(t, exists())
+}
+
+func TestMessageRepository_HideForUserRecipientFirst(t *testing.T) {
+	_, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+	msg := &models.Message{
+		ConversationID:    convID,
+		SenderID:          user1ID,
+		RecipientID:       user2ID,
+		EncryptedContent:  "hide me first",
+		MessageType:       "text",
+		EncryptionVersion: "v1",
+	}
+	require.NoError(t, messageRepo.Create(ctx, msg))
+
+	hardDeleted, err := messageRepo.HideForUser(ctx, msg.ID, user2ID)
+	require.NoError(t, err)
+	assert.False(t, hardDeleted)
+
+	// Hiding twice is a no-op for the other participant's copy
+	hardDeleted, err = messageRepo.HideForUser(ctx, msg.ID, user2ID)
+	require.NoError(t, err)
+	assert.False(t, hardDeleted)
+
+	hardDeleted, err = messageRepo.HideForUser(ctx, msg.ID, user1ID)
+	require.NoError(t, err)
+	assert.True(t, hardDeleted)
+
+	_, err = messageRepo.HideForUser(ctx, msg.ID, user1ID)
+	assert.Error(t, err, "hiding an already removed message should fail")
+}
+
 func TestDeleteMessage_NotParticipant(t *testing.T) {
 	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
 	defer cleanup()
@@ -803,3 +1319,268 @@ func TestMarkMessagesAsRead_SendsIndividualEvents(t *testing.T) {
 	assert.Equal(t, 3, readEvents)
 	assert.Equal(t, 1, conversationReadEvents)
 }
+
+func TestExportConversation_MatchesVisibleMessages(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+
+	var created []*models.Message
+	for i := 0; i < 4; i++ {
+		msg := &models.Message{
+			ConversationID:    convID,
+			SenderID:          user1ID,
+			RecipientID:       user2ID,
+			EncryptedContent:  fmt.Sprintf("ciphertext%d", i),
+			MessageType:       "text",
+			EncryptionVersion: "v1",
+		}
+		require.NoError(t, messageRepo.Create(ctx, msg))
+		created = append(created, msg)
+	}
+
+	// Deleted for both participants: must not be exported
+	require.NoError(t, messageRepo.SoftDeleteForBoth(ctx, created[1].ID))
+
+	router := gin.Default()
+	router.GET("/conversations/:id/export", func(c *gin.Context) {
+		c.Set("user_id", user2ID)
+		handler.ExportConversation(c)
+	})
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/conversations/%d/export", convID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), "attachment")
+
+	var response struct {
+		ConversationID int               `json:"conversation_id"`
+		MessageCount   int               `json:"message_count"`
+		Messages       []*models.Message `json:"messages"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	assert.Equal(t, convID, response.ConversationID)
+	assert.Equal(t, 3, response.MessageCount)
+	require.Len(t, response.Messages, 3)
+	for _, msg := range response.Messages {
+		assert.NotEqual(t, created[1].ID, msg.ID)
+		assert.Equal(t, "v1", msg.EncryptionVersion)
+	}
+	assert.Equal(t, "ciphertext0", response.Messages[0].EncryptedContent)
+}
+
+func TestExportConversation_NDJSON(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	messageRepo := models.NewMessageRepository(db.Pool)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, messageRepo.Create(ctx, &models.Message{
+			ConversationID:    convID,
+			SenderID:          user1ID,
+			RecipientID:       user2ID,
+			EncryptedContent:  fmt.Sprintf("ciphertext%d", i),
+			MessageType:       "text",
+			EncryptionVersion: "v1",
+		}))
+	}
+
+	router := gin.Default()
+	router.GET("/conversations/:id/export", func(c *gin.Context) {
+		c.Set("user_id", user1ID)
+		handler.ExportConversation(c)
+	})
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/conversations/%d/export?format=ndjson", convID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	lines := bytes.Split(bytes.TrimSpace(w.Body.Bytes()), []byte("\n"))
+	require.Len(t, lines, 2)
+	var first models.Message
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	assert.Equal(t, "ciphertext0", first.EncryptedContent)
+}
+
+func TestExportConversation_NotParticipant(t *testing.T) {
+	handler, db, _, _, convID, _, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userRepo := models.NewUserRepository(db.Pool)
+	outsider := &models.User{
+		Username:     uniqueMessagesUsername("outsider"),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, userRepo.Create(ctx, outsider))
+
+	router := gin.Default()
+	router.GET("/conversations/:id/export", func(c *gin.Context) {
+		c.Set("user_id", outsider.ID)
+		handler.ExportConversation(c)
+	})
+
+	req := httptest.NewRequest("GET", fmt.Sprintf("/conversations/%d/export", convID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestMarkAllConversationsAsRead(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userRepo := models.NewUserRepository(db.Pool)
+	convRepo := models.NewConversationRepository(db.Pool)
+	messageRepo := models.NewMessageRepository(db.Pool)
+
+	user3 := &models.User{Username: uniqueMessagesUsername("user3"), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, user3))
+	otherConv, err := convRepo.Create(ctx, user3.ID, user2ID)
+	require.NoError(t, err)
+	// A conversation user2 isn't part of must be left alone
+	unrelatedConv, err := convRepo.Create(ctx, user1ID, user3.ID)
+	require.NoError(t, err)
+
+	send := func(conversationID, senderID, recipientID int) int {
+		message := &models.Message{
+			ConversationID:    conversationID,
+			SenderID:          senderID,
+			RecipientID:       recipientID,
+			EncryptedContent:  "encrypted",
+			MessageType:       "text",
+			EncryptionVersion: "v1",
+		}
+		require.NoError(t, messageRepo.Create(ctx, message))
+		return message.ID
+	}
+
+	expected := map[int][]int{}
+	for i := 0; i < 3; i++ {
+		expected[convID] = append(expected[convID], send(convID, user1ID, user2ID))
+	}
+	for i := 0; i < 2; i++ {
+		expected[otherConv.ID] = append(expected[otherConv.ID], send(otherConv.ID, user3.ID, user2ID))
+	}
+	// user2's own outgoing message stays unread for its recipient
+	send(convID, user2ID, user1ID)
+	send(unrelatedConv.ID, user1ID, user3.ID)
+
+	router := gin.Default()
+	router.POST("/conversations/read-all", func(c *gin.Context) {
+		c.Set("user_id", user2ID)
+		handler.MarkAllAsRead(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/conversations/read-all", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(5), response["marked_count"])
+	assert.Equal(t, float64(2), response["conversation_count"])
+
+	for _, id := range []int{convID, otherConv.ID} {
+		count, err := messageRepo.GetUnreadCount(ctx, id, user2ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count, "conversation %d", id)
+	}
+	count, err := messageRepo.GetUnreadCount(ctx, convID, user1ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	count, err = messageRepo.GetUnreadCount(ctx, unrelatedConv.ID, user3.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Each sender gets one batched messages_read event plus a conversation_read
+	readIDs := map[int][]int{}
+	senders := map[int]int{}
+	for _, call := range hub.broadcastCalls {
+		payload := call.Payload.(gin.H)
+		conversationID := payload["conversation_id"].(int)
+		assert.Equal(t, user2ID, payload["reader_id"])
+		switch call.Type {
+		case "messages_read":
+			readIDs[conversationID] = append(readIDs[conversationID], payload["message_ids"].([]int)...)
+			senders[conversationID] = call.RecipientID
+		case "conversation_read":
+		default:
+			t.Fatalf("unexpected event %s", call.Type)
+		}
+	}
+	require.Len(t, hub.broadcastCalls, 4)
+	for conversationID, ids := range expected {
+		assert.ElementsMatch(t, ids, readIDs[conversationID])
+	}
+	assert.Equal(t, user1ID, senders[convID])
+	assert.Equal(t, user3.ID, senders[otherConv.ID])
+}
+
+func TestMarkAllConversationsAsRead_ThreeConversations(t *testing.T) {
+	handler, db, user1ID, user2ID, convID, hub, cleanup := setupMessagesHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userRepo := models.NewUserRepository(db.Pool)
+	convRepo := models.NewConversationRepository(db.Pool)
+	messageRepo := models.NewMessageRepository(db.Pool)
+
+	senders := map[int]int{convID: user1ID}
+	for i := 0; i < 2; i++ {
+		sender := &models.User{Username: uniqueMessagesUsername(fmt.Sprintf("sender%d", i)), PasswordHash: "test_hash"}
+		require.NoError(t, userRepo.Create(ctx, sender))
+		conv, err := convRepo.Create(ctx, sender.ID, user2ID)
+		require.NoError(t, err)
+		senders[conv.ID] = sender.ID
+	}
+
+	for conversationID, senderID := range senders {
+		for i := 0; i < 2; i++ {
+			require.NoError(t, messageRepo.Create(ctx, &models.Message{
+				ConversationID:    conversationID,
+				SenderID:          senderID,
+				RecipientID:       user2ID,
+				EncryptedContent:  "encrypted",
+				MessageType:       "text",
+				EncryptionVersion: "v1",
+			}))
+		}
+	}
+
+	router := gin.Default()
+	router.POST("/conversations/read-all", func(c *gin.Context) {
+		c.Set("user_id", user2ID)
+		handler.MarkAllAsRead(c)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/conversations/read-all", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	for conversationID := range senders {
+		count, err := messageRepo.GetUnreadCount(ctx, conversationID, user2ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, count, "conversation %d", conversationID)
+	}
+
+	conversationReads := map[int]int{}
+	for _, call := range hub.broadcastCalls {
+		if call.Type != "conversation_read" {
+			continue
+		}
+		conversationReads[call.Payload.(gin.H)["conversation_id"].(int)] = call.RecipientID
+	}
+	assert.Equal(t, senders, conversationReads, "one conversation_read per conversation, sent to its sender")
+}
@@ -1,17 +1,20 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
 )
 
 // ModerationHandler handles moderation reports
 type ModerationHandler struct {
-	reportRepo *models.ReportRepository
-	modRepo    *models.HubModeratorRepository
+	reportRepo   *models.ReportRepository
+	modRepo      *models.HubModeratorRepository
+	notifService *services.NotificationService
 }
 
 // NewModerationHandler creates a moderation handler
@@ -22,6 +25,11 @@ func NewModerationHandler(reportRepo *models.ReportRepository, modRepo *models.H
 	}
 }
 
+// SetNotificationService enables notifying reporters when their reports are resolved (called after initialization)
+func (h *ModerationHandler) SetNotificationService(notifService *services.NotificationService) {
+	h.notifService = notifService
+}
+
 // CreateReportRequest payload
 type CreateReportRequest struct {
 	TargetType string `json:"target_type" binding:"required"` // post, comment, user, message
@@ -111,10 +119,22 @@ func (h *ModerationHandler) UpdateReportStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.reportRepo.UpdateStatus(c.Request.Context(), id, req.Status); err != nil {
+	report, previousStatus, err := h.reportRepo.Resolve(c.Request.Context(), id, req.Status)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update status", "details": err.Error()})
 		return
 	}
+	if report == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		return
+	}
+
+	// Let the reporter know the outcome, once per actual status change
+	if h.notifService != nil && previousStatus != report.Status {
+		if err := h.notifService.NotifyReportResolved(c.Request.Context(), report.ReporterID, report.Status); err != nil {
+			log.Printf("Failed to notify reporter %d about report %d: %v", report.ReporterID, report.ID, err)
+		}
+	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Status updated"})
 }
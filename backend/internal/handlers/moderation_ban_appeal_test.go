@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type banAppealTestEnv struct {
+	handler    *ModerationHandlerV2
+	hubBanRepo *models.HubBanRepository
+	router     *gin.Engine
+	hubID      int
+	hubName    string
+	modID      int
+	bannedID   int
+}
+
+func setupBanAppealTest(t *testing.T) (*banAppealTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	hubBanRepo := models.NewHubBanRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("appealmod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	banned := &models.User{Username: fmt.Sprintf("appealer_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, banned))
+
+	hub := &models.Hub{Name: fmt.Sprintf("appealhub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	_, err = hubBanRepo.BanUser(ctx, hub.ID, banned.ID, mod.ID, "spam", "", "permanent", nil)
+	require.NoError(t, err)
+
+	env := &banAppealTestEnv{
+		hubBanRepo: hubBanRepo,
+		hubID:      hub.ID,
+		hubName:    hub.Name,
+		modID:      mod.ID,
+		bannedID:   banned.ID,
+	}
+	env.handler = NewModerationHandlerV2(
+		hubBanRepo,
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		models.NewModLogRepository(db.Pool),
+		modRepo,
+		models.NewPlatformPostRepository(db.Pool),
+		models.NewPostCommentRepository(db.Pool),
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+
+	gin.SetMode(gin.TestMode)
+	env.router = gin.New()
+	env.router.POST("/hubs/:name/ban/appeal", authMiddleware(banned.ID), env.handler.SubmitBanAppeal)
+	env.router.GET("/mod/hubs/:hub_name/ban/appeals", authMiddleware(mod.ID), env.handler.GetBanAppeals)
+	env.router.POST("/mod/hubs/:hub_name/ban/appeals/:id/resolve", authMiddleware(mod.ID), env.handler.ResolveBanAppeal)
+
+	return env, func() { db.Close() }
+}
+
+func (env *banAppealTestEnv) do(t *testing.T, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestBanAppeal_SubmitListApproveUnbans(t *testing.T) {
+	env, cleanup := setupBanAppealTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	// Submit
+	w := env.do(t, "POST", fmt.Sprintf("/hubs/%s/ban/appeal", env.hubName), gin.H{"message": "I won't spam again"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var submitted models.BanAppeal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &submitted))
+	assert.Equal(t, models.BanAppealPending, submitted.Status)
+	assert.Equal(t, env.bannedID, submitted.UserID)
+	require.NotNil(t, submitted.BanID)
+
+	// A second appeal while the first is open is rejected
+	w = env.do(t, "POST", fmt.Sprintf("/hubs/%s/ban/appeal", env.hubName), gin.H{"message": "please"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	// List
+	w = env.do(t, "GET", fmt.Sprintf("/mod/hubs/%s/ban/appeals", env.hubName), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var listed struct {
+		Appeals []models.BanAppeal `json:"appeals"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Appeals, 1)
+	assert.Equal(t, submitted.ID, listed.Appeals[0].ID)
+	assert.Equal(t, "I won't spam again", listed.Appeals[0].Message)
+
+	// Approve
+	w = env.do(t, "POST", fmt.Sprintf("/mod/hubs/%s/ban/appeals/%d/resolve", env.hubName, submitted.ID), gin.H{"decision": "approve"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var resolved models.BanAppeal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resolved))
+	assert.Equal(t, models.BanAppealApproved, resolved.Status)
+	require.NotNil(t, resolved.ResolvedBy)
+	assert.Equal(t, env.modID, *resolved.ResolvedBy)
+
+	banned, err := env.hubBanRepo.IsUserBanned(ctx, env.hubID, env.bannedID)
+	require.NoError(t, err)
+	assert.False(t, banned, "approving the appeal should lift the ban")
+
+	// The appeal is kept for the record but no longer pending
+	w = env.do(t, "GET", fmt.Sprintf("/mod/hubs/%s/ban/appeals", env.hubName), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	assert.Empty(t, listed.Appeals)
+
+	w = env.do(t, "GET", fmt.Sprintf("/mod/hubs/%s/ban/appeals?status=approved", env.hubName), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &listed))
+	require.Len(t, listed.Appeals, 1)
+	assert.Nil(t, listed.Appeals[0].BanID)
+
+	// Resolving twice conflicts
+	w = env.do(t, "POST", fmt.Sprintf("/mod/hubs/%s/ban/appeals/%d/resolve", env.hubName, submitted.ID), gin.H{"decision": "deny"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestBanAppeal_DenyKeepsBan(t *testing.T) {
+	env, cleanup := setupBanAppealTest(t)
+	defer cleanup()
+
+	w := env.do(t, "POST", fmt.Sprintf("/hubs/%s/ban/appeal", env.hubName), gin.H{"message": "let me back in"})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var submitted models.BanAppeal
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &submitted))
+
+	w = env.do(t, "POST", fmt.Sprintf("/mod/hubs/%s/ban/appeals/%d/resolve", env.hubName, submitted.ID), gin.H{"decision": "deny", "note": "repeat offender"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	banned, err := env.hubBanRepo.IsUserBanned(context.Background(), env.hubID, env.bannedID)
+	require.NoError(t, err)
+	assert.True(t, banned)
+
+	// A denied appeal frees the ban for a new one
+	w = env.do(t, "POST", fmt.Sprintf("/hubs/%s/ban/appeal", env.hubName), gin.H{"message": "one more try"})
+	assert.Equal(t, http.StatusCreated, w.Code)
+}
+
+func TestBanAppeal_RequiresActiveBan(t *testing.T) {
+	env, cleanup := setupBanAppealTest(t)
+	defer cleanup()
+
+	require.NoError(t, env.hubBanRepo.UnbanUser(context.Background(), env.hubID, env.bannedID))
+
+	w := env.do(t, "POST", fmt.Sprintf("/hubs/%s/ban/appeal", env.hubName), gin.H{"message": "not banned"})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func (env *pinTestEnv) createCrosspost(t *testing.T, origin *models.PlatformPost) *models.PlatformPost {
+	t.Helper()
+	originType := "platform"
+	originID := strconv.Itoa(origin.ID)
+	crosspost := &models.PlatformPost{
+		AuthorID:              env.modID,
+		HubID:                 &env.hubID,
+		Title:                 "Crosspost of " + origin.Title,
+		CrosspostOriginType:   &originType,
+		CrosspostOriginPostID: &originID,
+	}
+	require.NoError(t, env.postRepo.Create(context.Background(), crosspost))
+	return crosspost
+}
+
+func (env *pinTestEnv) crosspostCount(t *testing.T, postID int) int {
+	t.Helper()
+	post, err := env.postRepo.GetByID(context.Background(), postID)
+	require.NoError(t, err)
+	require.NotNil(t, post)
+	return post.CrosspostCount
+}
+
+func TestRemoveCrosspost_DecrementsOriginCountAndApproveRestores(t *testing.T) {
+	env, cleanup := setupPinTest(t, 1)
+	defer cleanup()
+	env.router.POST("/mod/posts/:id/remove", authMiddleware(env.modID), env.handler.RemovePost)
+	env.router.POST("/mod/posts/:id/approve", authMiddleware(env.modID), env.handler.ApprovePost)
+
+	origin := env.posts[0]
+	crosspost := env.createCrosspost(t, origin)
+	other := env.createCrosspost(t, origin)
+	require.Equal(t, 2, env.crosspostCount(t, origin.ID))
+
+	w := env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/remove", crosspost.ID), map[string]string{"custom_reason": "spam"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, env.crosspostCount(t, origin.ID))
+
+	// Removed crossposts drop out of the origin's listing
+	listed, err := env.postRepo.GetCrossposts(context.Background(), origin.ID, &env.modID, true, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, other.ID, listed[0].ID)
+
+	// Removing twice must not double count
+	w = env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/remove", crosspost.ID), map[string]string{})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 1, env.crosspostCount(t, origin.ID))
+
+	w = env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/approve", crosspost.ID), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, 2, env.crosspostCount(t, origin.ID))
+
+	listed, err = env.postRepo.GetCrossposts(context.Background(), origin.ID, &env.modID, true, 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, listed, 2)
+}
+
+func TestRemoveCrosspost_OriginDeleted(t *testing.T) {
+	env, cleanup := setupPinTest(t, 1)
+	defer cleanup()
+	env.router.POST("/mod/posts/:id/remove", authMiddleware(env.modID), env.handler.RemovePost)
+
+	origin := env.posts[0]
+	crosspost := env.createCrosspost(t, origin)
+	require.NoError(t, env.postRepo.SoftDelete(context.Background(), origin.ID))
+
+	w := env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/remove", crosspost.ID), map[string]string{})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
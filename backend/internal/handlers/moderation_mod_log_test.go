@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetModLog_FiltersByActionAndModerator(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	modLogRepo := models.NewModLogRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	modA := &models.User{Username: fmt.Sprintf("logmoda_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, modA))
+	modB := &models.User{Username: fmt.Sprintf("logmodb_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, modB))
+	target := &models.User{Username: fmt.Sprintf("logtarget_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, target))
+
+	hub := &models.Hub{Name: fmt.Sprintf("loghub_%d", suffix), CreatedBy: &modA.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, modA.ID))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, modB.ID))
+
+	logEntry := func(modID int, action, targetType string) {
+		_, err := modLogRepo.Log(ctx, hub.ID, modID, action, targetType, target.ID, nil)
+		require.NoError(t, err)
+	}
+	logEntry(modA.ID, "ban_user", "user")
+	logEntry(modA.ID, "ban_user", "user")
+	logEntry(modA.ID, "remove_post", "post")
+	logEntry(modB.ID, "ban_user", "user")
+	logEntry(modB.ID, "remove_comment", "comment")
+
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		modLogRepo,
+		modRepo,
+		models.NewPlatformPostRepository(db.Pool),
+		models.NewPostCommentRepository(db.Pool),
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/mod/hubs/:hub_name/mod-log", authMiddleware(modA.ID), handler.GetModLog)
+
+	getLogs := func(query string) []models.ModLog {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/mod/hubs/%s/mod-log%s", hub.Name, query), nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Logs []models.ModLog `json:"logs"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Logs
+	}
+
+	assert.Len(t, getLogs(""), 5, "no filters should return everything")
+
+	bans := getLogs("?action=ban_user")
+	assert.Len(t, bans, 3)
+	for _, log := range bans {
+		assert.Equal(t, "ban_user", log.Action)
+	}
+
+	byModB := getLogs(fmt.Sprintf("?mod_id=%d", modB.ID))
+	assert.Len(t, byModB, 2)
+	for _, log := range byModB {
+		assert.Equal(t, modB.ID, log.ModeratorID)
+	}
+
+	bansByModA := getLogs(fmt.Sprintf("?action=ban_user&mod_id=%d", modA.ID))
+	assert.Len(t, bansByModA, 2)
+	for _, log := range bansByModA {
+		assert.Equal(t, "ban_user", log.Action)
+		assert.Equal(t, modA.ID, log.ModeratorID)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/mod/hubs/%s/mod-log?mod_id=abc", hub.Name), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
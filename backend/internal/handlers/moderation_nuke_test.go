@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveAllUserContent_RemovesPostsAndCommentsInHub(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	removedRepo := models.NewRemovedContentRepository(db.Pool)
+	modLogRepo := models.NewModLogRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("nukemod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	spammer := &models.User{Username: fmt.Sprintf("nukespam_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, spammer))
+
+	hub := &models.Hub{Name: fmt.Sprintf("nukehub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+	otherHub := &models.Hub{Name: fmt.Sprintf("nukeother_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, otherHub))
+
+	createPost := func(authorID, hubID int) int {
+		post := &models.PlatformPost{AuthorID: authorID, HubID: &hubID, Title: "post"}
+		require.NoError(t, postRepo.Create(ctx, post))
+		return post.ID
+	}
+	createComment := func(postID, userID int) int {
+		comment := &models.PostComment{PostID: postID, UserID: userID, Body: "comment"}
+		require.NoError(t, commentRepo.Create(ctx, comment))
+		return comment.ID
+	}
+
+	spamPosts := []int{createPost(spammer.ID, hub.ID), createPost(spammer.ID, hub.ID), createPost(spammer.ID, hub.ID)}
+	modPost := createPost(mod.ID, hub.ID)
+	spamComments := []int{createComment(modPost, spammer.ID), createComment(spamPosts[0], spammer.ID)}
+	modComment := createComment(modPost, mod.ID)
+
+	// Content elsewhere is out of scope
+	otherHubPost := createPost(spammer.ID, otherHub.ID)
+	otherHubComment := createComment(otherHubPost, spammer.ID)
+
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		removedRepo,
+		modLogRepo,
+		modRepo,
+		postRepo,
+		commentRepo,
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/mod/hubs/:hub_name/users/:userid/remove-all", authMiddleware(mod.ID), handler.RemoveAllUserContent)
+
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/mod/hubs/%s/users/%d/remove-all", hub.Name, spammer.ID), nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(3), response["posts_removed"])
+	assert.Equal(t, float64(2), response["comments_removed"])
+
+	isRemoved := func(table string, id int) bool {
+		var removed bool
+		require.NoError(t, db.Pool.QueryRow(ctx, fmt.Sprintf(`SELECT is_removed FROM %s WHERE id = $1`, table), id).Scan(&removed))
+		return removed
+	}
+	for _, id := range spamPosts {
+		assert.True(t, isRemoved("platform_posts", id), "post %d", id)
+		tracked, err := removedRepo.IsContentRemoved(ctx, "post", id)
+		require.NoError(t, err)
+		assert.True(t, tracked, "post %d should be tracked in removed_content", id)
+	}
+	for _, id := range spamComments {
+		assert.True(t, isRemoved("post_comments", id), "comment %d", id)
+		tracked, err := removedRepo.IsContentRemoved(ctx, "comment", id)
+		require.NoError(t, err)
+		assert.True(t, tracked, "comment %d should be tracked in removed_content", id)
+	}
+	assert.False(t, isRemoved("platform_posts", modPost))
+	assert.False(t, isRemoved("post_comments", modComment))
+	assert.False(t, isRemoved("platform_posts", otherHubPost))
+	assert.False(t, isRemoved("post_comments", otherHubComment))
+
+	logs, err := modLogRepo.GetByHub(ctx, hub.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "nuke_user", logs[0].Action)
+	assert.Equal(t, spammer.ID, logs[0].TargetID)
+	assert.Equal(t, float64(3), logs[0].Details["posts_removed"])
+	assert.Equal(t, float64(2), logs[0].Details["comments_removed"])
+
+	// Running it again finds nothing left to remove
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/mod/hubs/%s/users/%d/remove-all", hub.Name, spammer.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response["posts_removed"])
+	assert.Equal(t, float64(0), response["comments_removed"])
+}
+
+func TestRemoveAllUserContent_RequiresModerator(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	owner := &models.User{Username: fmt.Sprintf("nukeowner_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, owner))
+	user := &models.User{Username: fmt.Sprintf("nukeuser_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, user))
+	hub := &models.Hub{Name: fmt.Sprintf("nukeperm_%d", suffix), CreatedBy: &owner.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	post := &models.PlatformPost{AuthorID: owner.ID, HubID: &hub.ID, Title: "keep me"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		models.NewModLogRepository(db.Pool),
+		models.NewHubModeratorRepository(db.Pool),
+		postRepo,
+		models.NewPostCommentRepository(db.Pool),
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/mod/hubs/:hub_name/users/:userid/remove-all", authMiddleware(user.ID), handler.RemoveAllUserContent)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/mod/hubs/%s/users/%d/remove-all", hub.Name, owner.ID), nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	var removed bool
+	require.NoError(t, db.Pool.QueryRow(ctx, `SELECT is_removed FROM platform_posts WHERE id = $1`, post.ID).Scan(&removed))
+	assert.False(t, removed)
+}
+
+func TestRemoveAllUserContent_ProtectsOwnerAndModerators(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	createUser := func(name string) *models.User {
+		user := &models.User{Username: fmt.Sprintf("%s_%d", name, suffix), PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		return user
+	}
+	owner := createUser("nukeprotowner")
+	mod := createUser("nukeprotmod")
+	otherMod := createUser("nukeprotother")
+
+	hub := &models.Hub{Name: fmt.Sprintf("nukeprot_%d", suffix), CreatedBy: &owner.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	for _, id := range []int{owner.ID, mod.ID, otherMod.ID} {
+		require.NoError(t, modRepo.AddModerator(ctx, hub.ID, id))
+	}
+
+	for _, authorID := range []int{owner.ID, otherMod.ID} {
+		post := &models.PlatformPost{AuthorID: authorID, HubID: &hub.ID, Title: "mod post"}
+		require.NoError(t, postRepo.Create(ctx, post))
+	}
+
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		models.NewModLogRepository(db.Pool),
+		modRepo,
+		postRepo,
+		models.NewPostCommentRepository(db.Pool),
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	gin.SetMode(gin.TestMode)
+	nuke := func(actorID, targetID int) int {
+		router := gin.New()
+		router.POST("/mod/hubs/:hub_name/users/:userid/remove-all", authMiddleware(actorID), handler.RemoveAllUserContent)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, fmt.Sprintf("/mod/hubs/%s/users/%d/remove-all", hub.Name, targetID), nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, nuke(mod.ID, owner.ID), "moderators can't remove the owner's content")
+	assert.Equal(t, http.StatusForbidden, nuke(mod.ID, otherMod.ID), "moderators can't remove another moderator's content")
+	assert.Equal(t, http.StatusOK, nuke(owner.ID, otherMod.ID), "the owner can remove a moderator's content")
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type modPermissionsTestEnv struct {
+	handler     *ModerationHandlerV2
+	modRepo     *models.HubModeratorRepository
+	postRepo    *models.PlatformPostRepository
+	commentRepo *models.PostCommentRepository
+	hubRepo     *models.HubRepository
+	hub         *models.Hub
+	authorID    int
+	targetID    int
+	userRepo    *models.UserRepository
+	suffix      int64
+}
+
+func setupModPermissionsTest(t *testing.T) (*modPermissionsTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	env := &modPermissionsTestEnv{
+		modRepo:     models.NewHubModeratorRepository(db.Pool),
+		postRepo:    models.NewPlatformPostRepository(db.Pool),
+		commentRepo: models.NewPostCommentRepository(db.Pool),
+		hubRepo:     models.NewHubRepository(db.Pool),
+		userRepo:    models.NewUserRepository(db.Pool),
+		suffix:      time.Now().UnixNano(),
+	}
+	hubRepo := env.hubRepo
+
+	env.authorID = env.createUser(t, "permauthor")
+	env.targetID = env.createUser(t, "permtarget")
+	env.hub = &models.Hub{Name: fmt.Sprintf("permhub_%d", env.suffix), CreatedBy: &env.authorID}
+	require.NoError(t, hubRepo.Create(ctx, env.hub))
+
+	env.handler = NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		models.NewModLogRepository(db.Pool),
+		env.modRepo,
+		env.postRepo,
+		env.commentRepo,
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+
+	return env, func() { db.Close() }
+}
+
+func (env *modPermissionsTestEnv) createUser(t *testing.T, name string) int {
+	t.Helper()
+	user := &models.User{Username: fmt.Sprintf("%s_%d", name, env.suffix), PasswordHash: "hash"}
+	require.NoError(t, env.userRepo.Create(context.Background(), user))
+	return user.ID
+}
+
+func (env *modPermissionsTestEnv) router(userID int, role string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		if role != "" {
+			c.Set("role", role)
+		}
+		c.Next()
+	})
+	router.POST("/mod/posts/:id/remove", env.handler.RemovePost)
+	router.POST("/mod/hubs/:hub_name/bans", env.handler.BanUser)
+	return router
+}
+
+func (env *modPermissionsTestEnv) do(t *testing.T, router *gin.Engine, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func (env *modPermissionsTestEnv) removePost(t *testing.T, router *gin.Engine) *httptest.ResponseRecorder {
+	post := &models.PlatformPost{AuthorID: env.authorID, HubID: &env.hub.ID, Title: "off topic"}
+	require.NoError(t, env.postRepo.Create(context.Background(), post))
+	return env.do(t, router, fmt.Sprintf("/mod/posts/%d/remove", post.ID), gin.H{})
+}
+
+func (env *modPermissionsTestEnv) banUser(t *testing.T, router *gin.Engine) *httptest.ResponseRecorder {
+	return env.do(t, router, fmt.Sprintf("/mod/hubs/%s/bans", env.hub.Name), gin.H{
+		"user_id":  env.targetID,
+		"ban_type": "permanent",
+	})
+}
+
+func TestModPermissions_PostsScopeCannotBan(t *testing.T) {
+	env, cleanup := setupModPermissionsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	modID := env.createUser(t, "postsmod")
+	require.NoError(t, env.modRepo.AddModerator(ctx, env.hub.ID, modID))
+	updated, err := env.modRepo.SetPermissions(ctx, env.hub.ID, modID, []string{models.ModPermPosts})
+	require.NoError(t, err)
+	require.True(t, updated)
+
+	router := env.router(modID, "user")
+
+	w := env.removePost(t, router)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = env.banUser(t, router)
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+}
+
+func TestModPermissions_FullScopeByDefault(t *testing.T) {
+	env, cleanup := setupModPermissionsTest(t)
+	defer cleanup()
+
+	modID := env.createUser(t, "fullmod")
+	require.NoError(t, env.modRepo.AddModerator(context.Background(), env.hub.ID, modID))
+
+	router := env.router(modID, "user")
+
+	w := env.removePost(t, router)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = env.banUser(t, router)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestModPermissions_AdminHasEveryPermission(t *testing.T) {
+	env, cleanup := setupModPermissionsTest(t)
+	defer cleanup()
+
+	adminID := env.createUser(t, "permadmin")
+	router := env.router(adminID, "admin")
+
+	w := env.removePost(t, router)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = env.banUser(t, router)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestModPermissions_DeletingContentNeedsPostsScope(t *testing.T) {
+	env, cleanup := setupModPermissionsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postsHandler := NewPostsHandler(env.postRepo, env.hubRepo, env.userRepo, env.modRepo, nil)
+	commentsHandler := NewCommentsHandler(env.commentRepo, env.postRepo, env.modRepo)
+
+	deleteAs := func(modID int) (postStatus, commentStatus int) {
+		post := &models.PlatformPost{AuthorID: env.authorID, HubID: &env.hub.ID, Title: "spam"}
+		require.NoError(t, env.postRepo.Create(ctx, post))
+		comment := &models.PostComment{PostID: post.ID, UserID: env.authorID, Body: "spam"}
+		require.NoError(t, env.commentRepo.Create(ctx, comment))
+
+		router := env.router(modID, "user")
+		router.DELETE("/posts/:id", postsHandler.DeletePost)
+		router.DELETE("/comments/:id", commentsHandler.DeleteComment)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/comments/%d", comment.ID), nil))
+		commentStatus = w.Code
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/posts/%d", post.ID), nil))
+		return w.Code, commentStatus
+	}
+
+	configMod := env.createUser(t, "configmod")
+	require.NoError(t, env.modRepo.AddModerator(ctx, env.hub.ID, configMod))
+	_, err := env.modRepo.SetPermissions(ctx, env.hub.ID, configMod, []string{models.ModPermConfig})
+	require.NoError(t, err)
+	postStatus, commentStatus := deleteAs(configMod)
+	assert.Equal(t, http.StatusForbidden, postStatus)
+	assert.Equal(t, http.StatusForbidden, commentStatus)
+
+	postsMod := env.createUser(t, "contentmod")
+	require.NoError(t, env.modRepo.AddModerator(ctx, env.hub.ID, postsMod))
+	_, err = env.modRepo.SetPermissions(ctx, env.hub.ID, postsMod, []string{models.ModPermPosts})
+	require.NoError(t, err)
+	postStatus, commentStatus = deleteAs(postsMod)
+	assert.Equal(t, http.StatusOK, postStatus)
+	assert.Equal(t, http.StatusOK, commentStatus)
+}
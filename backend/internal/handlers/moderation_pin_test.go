@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pinTestEnv struct {
+	handler    *ModerationHandlerV2
+	postRepo   *models.PlatformPostRepository
+	modLogRepo *models.ModLogRepository
+	router     *gin.Engine
+	hubID      int
+	modID      int
+	posts      []*models.PlatformPost
+}
+
+func setupPinTest(t *testing.T, numPosts int) (*pinTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("pinmod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+
+	hub := &models.Hub{Name: fmt.Sprintf("pinhub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	env := &pinTestEnv{postRepo: postRepo, modLogRepo: models.NewModLogRepository(db.Pool), hubID: hub.ID, modID: mod.ID}
+	for i := 0; i < numPosts; i++ {
+		post := &models.PlatformPost{AuthorID: mod.ID, HubID: &hub.ID, Title: fmt.Sprintf("Post %d", i)}
+		require.NoError(t, postRepo.Create(ctx, post))
+		env.posts = append(env.posts, post)
+	}
+
+	env.handler = NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		env.modLogRepo,
+		modRepo,
+		postRepo,
+		models.NewPostCommentRepository(db.Pool),
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+
+	gin.SetMode(gin.TestMode)
+	env.router = gin.New()
+	env.router.POST("/mod/posts/:id/pin", authMiddleware(mod.ID), env.handler.PinPost)
+	env.router.PUT("/mod/posts/:id/pin-order", authMiddleware(mod.ID), env.handler.SetPinOrder)
+
+	return env, func() { db.Close() }
+}
+
+func (env *pinTestEnv) do(t *testing.T, method, path string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPinPost_EnforcesHubPinCap(t *testing.T) {
+	env, cleanup := setupPinTest(t, 3)
+	defer cleanup()
+
+	// Hubs default to two pinned posts
+	for _, post := range env.posts[:2] {
+		w := env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/pin", post.ID), nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	}
+
+	w := env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/pin", env.posts[2].ID), nil)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), "maximum number of pinned posts")
+
+	third, err := env.postRepo.GetByID(context.Background(), env.posts[2].ID)
+	require.NoError(t, err)
+	assert.False(t, third.IsPinned)
+}
+
+func TestPinnedPostsLeadHubFeedInPinOrder(t *testing.T) {
+	env, cleanup := setupPinTest(t, 4)
+	defer cleanup()
+
+	first, second := env.posts[0], env.posts[1]
+	require.Equal(t, http.StatusOK, env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/pin", first.ID), nil).Code)
+	require.Equal(t, http.StatusOK, env.do(t, http.MethodPost, fmt.Sprintf("/mod/posts/%d/pin", second.ID), nil).Code)
+
+	// Move the second pin to the top
+	w := env.do(t, http.MethodPut, fmt.Sprintf("/mod/posts/%d/pin-order", second.ID), map[string]int{"pin_order": 1})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	logs, err := env.modLogRepo.GetByHubFiltered(context.Background(), env.hubID, "set_pin_order", nil, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1, "reordering is logged separately from pinning")
+	assert.Equal(t, second.ID, logs[0].TargetID)
+
+	posts, err := env.postRepo.GetByHubWithUser(context.Background(), env.hubID, "new", 10, 0, nil, nil, nil)
+	require.NoError(t, err)
+	require.Len(t, posts, 4)
+
+	assert.Equal(t, second.ID, posts[0].ID)
+	assert.True(t, posts[0].IsPinned)
+	require.NotNil(t, posts[0].PinOrder)
+	assert.Equal(t, 1, *posts[0].PinOrder)
+
+	assert.Equal(t, first.ID, posts[1].ID)
+	assert.True(t, posts[1].IsPinned)
+	require.NotNil(t, posts[1].PinOrder)
+	assert.Equal(t, 2, *posts[1].PinOrder)
+
+	// Remaining posts follow in normal "new" order and are not flagged
+	assert.False(t, posts[2].IsPinned)
+	assert.False(t, posts[3].IsPinned)
+	assert.Equal(t, env.posts[3].ID, posts[2].ID)
+}
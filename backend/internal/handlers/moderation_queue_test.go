@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetModQueue_ListsReportedContentByReportCount(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	reportRepo := models.NewReportRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("queuemod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	author := &models.User{Username: fmt.Sprintf("queueauthor_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, author))
+
+	reporters := make([]int, 3)
+	for i := range reporters {
+		reporter := &models.User{Username: fmt.Sprintf("queuereporter%d_%d", i, suffix), PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, reporter))
+		reporters[i] = reporter.ID
+	}
+
+	hub := &models.Hub{Name: fmt.Sprintf("queuehub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	createPost := func(title string) int {
+		post := &models.PlatformPost{AuthorID: author.ID, HubID: &hub.ID, Title: title}
+		require.NoError(t, postRepo.Create(ctx, post))
+		return post.ID
+	}
+	report := func(targetType string, targetID, times int) {
+		for i := 0; i < times; i++ {
+			require.NoError(t, reportRepo.Create(ctx, &models.Report{
+				ReporterID: reporters[i],
+				TargetType: targetType,
+				TargetID:   targetID,
+				Reason:     "spam",
+			}))
+		}
+	}
+
+	mostReported := createPost("Reported three times")
+	onceReported := createPost("Reported once")
+	comment := &models.PostComment{PostID: onceReported, UserID: author.ID, Body: "reported comment"}
+	require.NoError(t, commentRepo.Create(ctx, comment))
+	removedPost := createPost("Already removed")
+	unreported := createPost("Nobody minds this one")
+
+	report("post", mostReported, 3)
+	report("post", onceReported, 1)
+	report("comment", comment.ID, 2)
+	report("post", removedPost, 3)
+	require.NoError(t, postRepo.MarkAsRemoved(ctx, removedPost, mod.ID))
+
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		models.NewRemovalReasonRepository(db.Pool),
+		models.NewRemovedContentRepository(db.Pool),
+		models.NewModLogRepository(db.Pool),
+		modRepo,
+		postRepo,
+		commentRepo,
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	handler.SetReportRepository(reportRepo)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/mod/hubs/:hub_name/queue", authMiddleware(mod.ID), handler.GetModQueue)
+
+	getQueue := func(query string) []models.ModQueueItem {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/mod/hubs/%s/queue%s", hub.Name, query), nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Items []models.ModQueueItem `json:"items"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Items
+	}
+
+	items := getQueue("")
+	require.Len(t, items, 3)
+	assert.Equal(t, "post", items[0].ContentType)
+	assert.Equal(t, mostReported, items[0].ContentID)
+	assert.Equal(t, 3, items[0].ReportCount)
+	assert.Equal(t, "comment", items[1].ContentType)
+	assert.Equal(t, comment.ID, items[1].ContentID)
+	assert.Equal(t, onceReported, items[1].PostID)
+	assert.Equal(t, 2, items[1].ReportCount)
+	assert.Equal(t, "post", items[2].ContentType)
+	assert.Equal(t, onceReported, items[2].ContentID)
+	assert.Equal(t, 1, items[2].ReportCount)
+	for _, item := range items {
+		assert.Equal(t, author.Username, item.AuthorUsername)
+		if item.ContentType == "post" {
+			assert.NotEqual(t, removedPost, item.ContentID, "removed content should leave the queue")
+			assert.NotEqual(t, unreported, item.ContentID)
+		}
+	}
+
+	posts := getQueue("?type=posts")
+	require.Len(t, posts, 2)
+	assert.Equal(t, mostReported, posts[0].ContentID)
+	assert.Equal(t, onceReported, posts[1].ContentID)
+
+	comments := getQueue("?type=comments")
+	require.Len(t, comments, 1)
+	assert.Equal(t, comment.ID, comments[0].ContentID)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/mod/hubs/%s/queue?type=users", hub.Name), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
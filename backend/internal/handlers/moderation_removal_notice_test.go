@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type removalNoticeTestEnv struct {
+	postRepo     *models.PlatformPostRepository
+	commentRepo  *models.PostCommentRepository
+	notifRepo    *models.NotificationRepository
+	settingsRepo *models.UserSettingsRepository
+	router       *gin.Engine
+	hubID        int
+	authorID     int
+	reason       *models.RemovalReason
+}
+
+func setupRemovalNoticeTest(t *testing.T) (*removalNoticeTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	removalReasonRepo := models.NewRemovalReasonRepository(db.Pool)
+
+	env := &removalNoticeTestEnv{
+		postRepo:     models.NewPlatformPostRepository(db.Pool),
+		commentRepo:  models.NewPostCommentRepository(db.Pool),
+		notifRepo:    models.NewNotificationRepository(db.Pool),
+		settingsRepo: models.NewUserSettingsRepository(db.Pool),
+	}
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("noticemod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	author := &models.User{Username: fmt.Sprintf("noticeauthor_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, author))
+	env.authorID = author.ID
+
+	hub := &models.Hub{Name: fmt.Sprintf("noticehub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+	env.hubID = hub.ID
+
+	env.reason, err = removalReasonRepo.Create(ctx, hub.ID, mod.ID, "Rule 2: No self-promotion", "Please keep promotion to the weekly thread.")
+	require.NoError(t, err)
+
+	notifService := services.NewNotificationService(
+		db.Pool,
+		env.notifRepo,
+		models.NewUserBaselineRepository(db.Pool),
+		models.NewNotificationBatchRepository(db.Pool),
+		env.settingsRepo,
+		env.postRepo,
+		env.commentRepo,
+		userRepo,
+		nil,
+	)
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		removalReasonRepo,
+		models.NewRemovedContentRepository(db.Pool),
+		models.NewModLogRepository(db.Pool),
+		modRepo,
+		env.postRepo,
+		env.commentRepo,
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	handler.SetNotificationService(notifService)
+
+	gin.SetMode(gin.TestMode)
+	env.router = gin.New()
+	env.router.POST("/mod/posts/:id/remove", authMiddleware(mod.ID), handler.RemovePost)
+	env.router.POST("/mod/comments/:id/remove", authMiddleware(mod.ID), handler.RemoveComment)
+
+	return env, func() { db.Close() }
+}
+
+func (env *removalNoticeTestEnv) createPost(t *testing.T) int {
+	t.Helper()
+	post := &models.PlatformPost{AuthorID: env.authorID, HubID: &env.hubID, Title: "Check out my shop"}
+	require.NoError(t, env.postRepo.Create(context.Background(), post))
+	return post.ID
+}
+
+func (env *removalNoticeTestEnv) remove(t *testing.T, path string, reasonID *int) {
+	t.Helper()
+	body, err := json.Marshal(map[string]interface{}{"removal_reason_id": reasonID})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func (env *removalNoticeTestEnv) notifications(t *testing.T) []*models.Notification {
+	t.Helper()
+	notifs, err := env.notifRepo.GetByUserID(context.Background(), env.authorID, 50, 0, false)
+	require.NoError(t, err)
+	return notifs
+}
+
+func TestRemoveContent_NotifiesAuthorWithReason(t *testing.T) {
+	env, cleanup := setupRemovalNoticeTest(t)
+	defer cleanup()
+
+	postID := env.createPost(t)
+	env.remove(t, fmt.Sprintf("/mod/posts/%d/remove", postID), &env.reason.ID)
+
+	notifs := env.notifications(t)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "content_removed", notifs[0].NotificationType)
+	assert.Contains(t, notifs[0].Message, env.reason.Title)
+	assert.Contains(t, notifs[0].Message, env.reason.Message)
+	require.NotNil(t, notifs[0].ContentType)
+	assert.Equal(t, "post", *notifs[0].ContentType)
+	require.NotNil(t, notifs[0].ContentID)
+	assert.Equal(t, postID, *notifs[0].ContentID)
+	assert.Nil(t, notifs[0].ActorID, "the removing moderator is not exposed")
+
+	comment := &models.PostComment{PostID: env.createPost(t), UserID: env.authorID, Body: "buy now"}
+	require.NoError(t, env.commentRepo.Create(context.Background(), comment))
+	env.remove(t, fmt.Sprintf("/mod/comments/%d/remove", comment.ID), &env.reason.ID)
+
+	notifs = env.notifications(t)
+	require.Len(t, notifs, 2)
+	assert.Equal(t, "content_removed", notifs[0].NotificationType)
+	require.NotNil(t, notifs[0].ContentType)
+	assert.Equal(t, "comment", *notifs[0].ContentType)
+	assert.Contains(t, notifs[0].Message, env.reason.Message)
+}
+
+func TestRemoveContent_NoNotificationWithoutReason(t *testing.T) {
+	env, cleanup := setupRemovalNoticeTest(t)
+	defer cleanup()
+
+	env.remove(t, fmt.Sprintf("/mod/posts/%d/remove", env.createPost(t)), nil)
+
+	assert.Empty(t, env.notifications(t))
+}
+
+func TestRemoveContent_NoNotificationWhenDisabled(t *testing.T) {
+	env, cleanup := setupRemovalNoticeTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	settings, err := env.settingsRepo.CreateDefault(ctx, env.authorID)
+	require.NoError(t, err)
+	assert.True(t, settings.NotifyContentRemoved, "removal notices are on by default")
+	settings.NotifyContentRemoved = false
+	_, err = env.settingsRepo.Update(ctx, settings)
+	require.NoError(t, err)
+
+	env.remove(t, fmt.Sprintf("/mod/posts/%d/remove", env.createPost(t)), &env.reason.ID)
+
+	assert.Empty(t, env.notifications(t))
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetRemovedContent_ListsRemovalsWithReasonsAndPaginates(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	removalReasonRepo := models.NewRemovalReasonRepository(db.Pool)
+	removedRepo := models.NewRemovedContentRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("removedmod_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	author := &models.User{Username: fmt.Sprintf("removedauthor_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, author))
+
+	hub := &models.Hub{Name: fmt.Sprintf("removedhub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, modRepo.AddModerator(ctx, hub.ID, mod.ID))
+
+	reason, err := removalReasonRepo.Create(ctx, hub.ID, mod.ID, "Rule 1: Be civil", "Personal attacks are not allowed.")
+	require.NoError(t, err)
+
+	var postIDs []int
+	for i := 0; i < 3; i++ {
+		post := &models.PlatformPost{AuthorID: author.ID, HubID: &hub.ID, Title: fmt.Sprintf("removed %d", i)}
+		require.NoError(t, postRepo.Create(ctx, post))
+		require.NoError(t, postRepo.MarkAsRemoved(ctx, post.ID, mod.ID))
+		postIDs = append(postIDs, post.ID)
+	}
+	comment := &models.PostComment{PostID: postIDs[0], UserID: author.ID, Body: "rude"}
+	require.NoError(t, commentRepo.Create(ctx, comment))
+	require.NoError(t, commentRepo.MarkAsRemoved(ctx, comment.ID, mod.ID))
+
+	_, err = removedRepo.RemoveContent(ctx, "post", postIDs[0], &hub.ID, mod.ID, &reason.ID, "", "second strike")
+	require.NoError(t, err)
+	_, err = removedRepo.RemoveContent(ctx, "post", postIDs[1], &hub.ID, mod.ID, nil, "Duplicate post", "")
+	require.NoError(t, err)
+	_, err = removedRepo.RemoveContent(ctx, "post", postIDs[2], &hub.ID, mod.ID, nil, "", "")
+	require.NoError(t, err)
+	_, err = removedRepo.RemoveContent(ctx, "comment", comment.ID, &hub.ID, mod.ID, &reason.ID, "", "")
+	require.NoError(t, err)
+
+	handler := NewModerationHandlerV2(
+		models.NewHubBanRepository(db.Pool),
+		removalReasonRepo,
+		removedRepo,
+		models.NewModLogRepository(db.Pool),
+		modRepo,
+		postRepo,
+		commentRepo,
+		hubRepo,
+		models.NewBanAppealRepository(db.Pool),
+	)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/mod/hubs/:hub_name/removed", authMiddleware(mod.ID), handler.GetRemovedContent)
+
+	list := func(query string) []models.RemovedContent {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/mod/hubs/%s/removed%s", hub.Name, query), nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Removed []models.RemovedContent `json:"removed"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Removed
+	}
+
+	all := list("")
+	require.Len(t, all, 4)
+	for _, removed := range all {
+		assert.Equal(t, mod.ID, removed.RemovedBy)
+		assert.Equal(t, mod.Username, removed.RemovedByName)
+		assert.False(t, removed.RemovedAt.IsZero())
+	}
+
+	posts := list("?type=posts")
+	require.Len(t, posts, 3)
+	byID := map[int]models.RemovedContent{}
+	for _, removed := range posts {
+		assert.Equal(t, "post", removed.ContentType)
+		byID[removed.ContentID] = removed
+	}
+	withReason := byID[postIDs[0]]
+	require.NotNil(t, withReason.RemovalReasonID)
+	assert.Equal(t, reason.ID, *withReason.RemovalReasonID)
+	assert.Equal(t, "Rule 1: Be civil", withReason.ReasonTitle)
+	assert.Equal(t, "Personal attacks are not allowed.", withReason.ReasonMessage)
+	assert.Equal(t, "second strike", withReason.ModNote)
+	assert.Equal(t, "Duplicate post", byID[postIDs[1]].CustomReason)
+	assert.Nil(t, byID[postIDs[2]].RemovalReasonID)
+	assert.Empty(t, byID[postIDs[2]].ReasonTitle)
+
+	comments := list("?type=comments")
+	require.Len(t, comments, 1)
+	assert.Equal(t, comment.ID, comments[0].ContentID)
+	assert.Equal(t, "Rule 1: Be civil", comments[0].ReasonTitle)
+
+	// Pages walk every removal exactly once
+	first := list("?limit=2&offset=0")
+	second := list("?limit=2&offset=2")
+	third := list("?limit=2&offset=4")
+	require.Len(t, first, 2)
+	require.Len(t, second, 2)
+	assert.Empty(t, third)
+	seen := map[int]bool{}
+	for _, removed := range append(first, second...) {
+		assert.False(t, seen[removed.ID], "removal %d returned twice", removed.ID)
+		seen[removed.ID] = true
+	}
+	assert.Len(t, seen, 4)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/mod/hubs/%s/removed?type=users", hub.Name), nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
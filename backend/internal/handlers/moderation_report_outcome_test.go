@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type reportOutcomeTestEnv struct {
+	reportRepo   *models.ReportRepository
+	notifRepo    *models.NotificationRepository
+	settingsRepo *models.UserSettingsRepository
+	userRepo     *models.UserRepository
+	router       *gin.Engine
+	suffix       int64
+}
+
+func setupReportOutcomeTest(t *testing.T) (*reportOutcomeTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	env := &reportOutcomeTestEnv{
+		reportRepo:   models.NewReportRepository(db.Pool),
+		notifRepo:    models.NewNotificationRepository(db.Pool),
+		settingsRepo: models.NewUserSettingsRepository(db.Pool),
+		userRepo:     models.NewUserRepository(db.Pool),
+		suffix:       time.Now().UnixNano(),
+	}
+
+	notifService := services.NewNotificationService(
+		db.Pool,
+		env.notifRepo,
+		models.NewUserBaselineRepository(db.Pool),
+		models.NewNotificationBatchRepository(db.Pool),
+		env.settingsRepo,
+		models.NewPlatformPostRepository(db.Pool),
+		models.NewPostCommentRepository(db.Pool),
+		models.NewUserRepository(db.Pool),
+		nil,
+	)
+	handler := NewModerationHandler(env.reportRepo, models.NewHubModeratorRepository(db.Pool))
+	handler.SetNotificationService(notifService)
+
+	gin.SetMode(gin.TestMode)
+	env.router = gin.New()
+	env.router.POST("/mod/reports/:id/status", handler.UpdateReportStatus)
+
+	return env, func() { db.Close() }
+}
+
+func (env *reportOutcomeTestEnv) createReporter(t *testing.T, name string) int {
+	t.Helper()
+	user := &models.User{Username: fmt.Sprintf("%s_%d", name, env.suffix), PasswordHash: "hash"}
+	require.NoError(t, env.userRepo.Create(context.Background(), user))
+	return user.ID
+}
+
+func (env *reportOutcomeTestEnv) fileReport(t *testing.T, reporterID int) int {
+	t.Helper()
+	report := &models.Report{ReporterID: reporterID, TargetType: "post", TargetID: 1, Reason: "spam"}
+	require.NoError(t, env.reportRepo.Create(context.Background(), report))
+	return report.ID
+}
+
+func (env *reportOutcomeTestEnv) resolve(t *testing.T, reportID int, status string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"status": status})
+	req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/mod/reports/%d/status", reportID), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func (env *reportOutcomeTestEnv) notifications(t *testing.T, userID int) []*models.Notification {
+	t.Helper()
+	notifs, err := env.notifRepo.GetByUserID(context.Background(), userID, 50, 0, false)
+	require.NoError(t, err)
+	return notifs
+}
+
+func TestUpdateReportStatus_NotifiesReporterWithOutcome(t *testing.T) {
+	env, cleanup := setupReportOutcomeTest(t)
+	defer cleanup()
+
+	actioned := env.createReporter(t, "reporter_actioned")
+	dismissed := env.createReporter(t, "reporter_dismissed")
+	env.resolve(t, env.fileReport(t, actioned), "reviewed")
+	env.resolve(t, env.fileReport(t, dismissed), "dismissed")
+
+	notifs := env.notifications(t, actioned)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "report_action_taken", notifs[0].NotificationType)
+	assert.Contains(t, notifs[0].Message, "action was taken")
+	// Nothing about who moderated or what was reported is exposed
+	assert.Nil(t, notifs[0].ActorID)
+	assert.Nil(t, notifs[0].ContentType)
+	assert.Nil(t, notifs[0].ContentID)
+
+	notifs = env.notifications(t, dismissed)
+	require.Len(t, notifs, 1)
+	assert.Equal(t, "report_no_action", notifs[0].NotificationType)
+	assert.Contains(t, notifs[0].Message, "no action was needed")
+}
+
+func TestUpdateReportStatus_AggregatesReportsResolvedTogether(t *testing.T) {
+	env, cleanup := setupReportOutcomeTest(t)
+	defer cleanup()
+
+	reporter := env.createReporter(t, "reporter_busy")
+	for i := 0; i < 3; i++ {
+		env.resolve(t, env.fileReport(t, reporter), "reviewed")
+	}
+	// Re-saving the same status is not a new outcome
+	reportID := env.fileReport(t, reporter)
+	env.resolve(t, reportID, "dismissed")
+	env.resolve(t, reportID, "dismissed")
+
+	byType := map[string]*models.Notification{}
+	for _, n := range env.notifications(t, reporter) {
+		require.Nil(t, byType[n.NotificationType], "duplicate %s notification", n.NotificationType)
+		byType[n.NotificationType] = n
+	}
+	require.Len(t, byType, 2)
+	assert.Equal(t, 3, *byType["report_action_taken"].MilestoneCount)
+	assert.Contains(t, byType["report_action_taken"].Message, "reviewed 3 of them")
+	assert.Contains(t, byType["report_no_action"].Message, "reviewed it")
+}
+
+func TestUpdateReportStatus_RespectsOptOut(t *testing.T) {
+	env, cleanup := setupReportOutcomeTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	reporter := env.createReporter(t, "reporter_quiet")
+	settings, err := env.settingsRepo.CreateDefault(ctx, reporter)
+	require.NoError(t, err)
+	assert.True(t, settings.NotifyReportUpdates, "report updates are on by default")
+	settings.NotifyReportUpdates = false
+	_, err = env.settingsRepo.Update(ctx, settings)
+	require.NoError(t, err)
+
+	env.resolve(t, env.fileReport(t, reporter), "reviewed")
+	assert.Empty(t, env.notifications(t, reporter))
+}
@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
 )
 
 type ModerationHandlerV2 struct {
@@ -18,6 +23,10 @@ type ModerationHandlerV2 struct {
 	postRepo             *models.PlatformPostRepository
 	commentRepo          *models.PostCommentRepository
 	hubRepo              *models.HubRepository
+	banAppealRepo        *models.BanAppealRepository
+	reportRepo           *models.ReportRepository
+	automodRepo          *models.AutomodRepository
+	notifService         *services.NotificationService
 }
 
 func NewModerationHandlerV2(
@@ -29,6 +38,7 @@ func NewModerationHandlerV2(
 	postRepo *models.PlatformPostRepository,
 	commentRepo *models.PostCommentRepository,
 	hubRepo *models.HubRepository,
+	banAppealRepo *models.BanAppealRepository,
 ) *ModerationHandlerV2 {
 	return &ModerationHandlerV2{
 		hubBanRepo:         hubBanRepo,
@@ -39,9 +49,25 @@ func NewModerationHandlerV2(
 		postRepo:           postRepo,
 		commentRepo:        commentRepo,
 		hubRepo:            hubRepo,
+		banAppealRepo:      banAppealRepo,
 	}
 }
 
+// SetReportRepository enables the hub modqueue
+func (h *ModerationHandlerV2) SetReportRepository(reportRepo *models.ReportRepository) {
+	h.reportRepo = reportRepo
+}
+
+// SetAutomodRepository enables managing hub automod rules
+func (h *ModerationHandlerV2) SetAutomodRepository(automodRepo *models.AutomodRepository) {
+	h.automodRepo = automodRepo
+}
+
+// SetNotificationService enables telling authors why their content was removed
+func (h *ModerationHandlerV2) SetNotificationService(notifService *services.NotificationService) {
+	h.notifService = notifService
+}
+
 // ===== USER BANS =====
 
 // BanUser - POST /api/v1/mod/hubs/:hubname/ban
@@ -55,7 +81,7 @@ func (h *ModerationHandlerV2) BanUser(c *gin.Context) {
 	hubName := c.Param("hub_name")
 
 	// Get hub ID and check if user is a moderator
-	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int))
+	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int), models.ModPermAccess)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -131,7 +157,7 @@ func (h *ModerationHandlerV2) UnbanUser(c *gin.Context) {
 		return
 	}
 
-	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int))
+	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int), models.ModPermAccess)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -167,7 +193,7 @@ func (h *ModerationHandlerV2) GetBannedUsers(c *gin.Context) {
 
 	hubName := c.Param("hub_name")
 
-	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int))
+	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int), models.ModPermAccess)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -190,6 +216,184 @@ func (h *ModerationHandlerV2) GetBannedUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"bans": bans})
 }
 
+// ===== BAN APPEALS =====
+
+// maxBanAppealLength caps the message a banned user can send with an appeal
+const maxBanAppealLength = 2000
+
+// SubmitBanAppeal - POST /api/v1/hubs/:name/ban/appeal
+// Lets a banned user ask the hub's moderators to lift their ban.
+func (h *ModerationHandlerV2) SubmitBanAppeal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req struct {
+		Message string `json:"message" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	message := strings.TrimSpace(req.Message)
+	if message == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Appeal message is required"})
+		return
+	}
+	if len(message) > maxBanAppealLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Appeal message is too long"})
+		return
+	}
+
+	hub, err := h.hubRepo.GetByName(c.Request.Context(), c.Param("name"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hub == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+
+	ban, err := h.hubBanRepo.GetBanByUser(c.Request.Context(), hub.ID, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if ban == nil || (ban.ExpiresAt != nil && !ban.ExpiresAt.After(time.Now())) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "You are not banned from this hub"})
+		return
+	}
+
+	appeal, err := h.banAppealRepo.Create(c.Request.Context(), ban, message)
+	if err != nil {
+		if errors.Is(err, models.ErrBanAppealPending) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You already have a pending appeal for this ban"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+// GetBanAppeals - GET /api/v1/mod/hubs/:hub_name/ban/appeals?status=pending
+// status may be pending (default), approved, denied or all.
+func (h *ModerationHandlerV2) GetBanAppeals(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	status := strings.ToLower(c.DefaultQuery("status", models.BanAppealPending))
+	switch status {
+	case models.BanAppealPending, models.BanAppealApproved, models.BanAppealDenied:
+	case "all":
+		status = ""
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Must be pending, approved, denied or all"})
+		return
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermAccess)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can view ban appeals"})
+		return
+	}
+
+	appeals, err := h.banAppealRepo.ListByHub(c.Request.Context(), hubID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"appeals": appeals})
+}
+
+// ResolveBanAppeal - POST /api/v1/mod/hubs/:hub_name/ban/appeals/:id/resolve
+// Approving an appeal also lifts the ban, in the same transaction.
+func (h *ModerationHandlerV2) ResolveBanAppeal(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	appealID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID"})
+		return
+	}
+
+	var req struct {
+		Decision string  `json:"decision" binding:"required,oneof=approve deny"`
+		Note     *string `json:"note"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermAccess)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can resolve ban appeals"})
+		return
+	}
+
+	existing, err := h.banAppealRepo.GetByID(c.Request.Context(), appealID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if existing == nil || existing.HubID != hubID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Appeal not found"})
+		return
+	}
+
+	status := models.BanAppealDenied
+	if req.Decision == "approve" {
+		status = models.BanAppealApproved
+	}
+
+	appeal, err := h.banAppealRepo.Resolve(c.Request.Context(), appealID, userID.(int), status, req.Note)
+	if err != nil {
+		if errors.Is(err, models.ErrBanAppealResolved) {
+			c.JSON(http.StatusConflict, gin.H{"error": "Appeal has already been resolved"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if status == models.BanAppealApproved {
+		_, _ = h.modLogRepo.Log(c.Request.Context(), hubID, userID.(int), "unban_user", "user", appeal.UserID, models.JSONB{
+			"appeal_id": appeal.ID,
+		})
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
+
 // ===== CONTENT REMOVAL =====
 
 // RemovePost - POST /api/v1/mod/posts/:id/remove
@@ -233,7 +437,7 @@ func (h *ModerationHandlerV2) RemovePost(c *gin.Context) {
 	}
 
 	// Check moderator permission
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -263,6 +467,8 @@ func (h *ModerationHandlerV2) RemovePost(c *gin.Context) {
 		"custom_reason":     req.CustomReason,
 	})
 
+	h.notifyRemovalReason(c, *post.HubID, post.AuthorID, "post", postID, req.RemovalReasonID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Post removed successfully"})
 }
 
@@ -296,7 +502,7 @@ func (h *ModerationHandlerV2) ApprovePost(c *gin.Context) {
 	}
 
 	// Check moderator permission
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -370,7 +576,7 @@ func (h *ModerationHandlerV2) RemoveComment(c *gin.Context) {
 	}
 
 	// Check moderator permission
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -400,6 +606,8 @@ func (h *ModerationHandlerV2) RemoveComment(c *gin.Context) {
 		"custom_reason":     req.CustomReason,
 	})
 
+	h.notifyRemovalReason(c, *post.HubID, comment.UserID, "comment", commentID, req.RemovalReasonID)
+
 	c.JSON(http.StatusOK, gin.H{"message": "Comment removed successfully"})
 }
 
@@ -440,7 +648,7 @@ func (h *ModerationHandlerV2) ApproveComment(c *gin.Context) {
 	}
 
 	// Check moderator permission
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -466,6 +674,65 @@ func (h *ModerationHandlerV2) ApproveComment(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Comment approved successfully"})
 }
 
+// RemoveAllUserContent - POST /api/v1/mod/hubs/:hub_name/users/:userid/remove-all
+// Removes every post and comment the user has in the hub, e.g. after a spam run.
+func (h *ModerationHandlerV2) RemoveAllUserContent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	targetUserID, err := strconv.Atoi(c.Param("userid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermPosts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can remove content"})
+		return
+	}
+
+	// Moderators can't wipe out the owner or a fellow moderator; the owner and admins can
+	protected, err := h.isProtectedTarget(c, hubID, userID.(int), targetUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if protected {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the hub owner or an admin can remove a moderator's content"})
+		return
+	}
+
+	postCount, commentCount, err := h.postRepo.MarkAuthorContentRemoved(c.Request.Context(), hubID, targetUserID, userID.(int))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Log the action once rather than per item
+	_, _ = h.modLogRepo.Log(c.Request.Context(), hubID, userID.(int), "nuke_user", "user", targetUserID, models.JSONB{
+		"posts_removed":    postCount,
+		"comments_removed": commentCount,
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "User content removed successfully",
+		"posts_removed":    postCount,
+		"comments_removed": commentCount,
+	})
+}
+
 // ===== POST MODERATION (LOCK/PIN) =====
 
 // LockPost - POST /api/v1/mod/posts/:id/lock
@@ -496,7 +763,7 @@ func (h *ModerationHandlerV2) LockPost(c *gin.Context) {
 		return
 	}
 
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -545,7 +812,7 @@ func (h *ModerationHandlerV2) UnlockPost(c *gin.Context) {
 		return
 	}
 
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -594,7 +861,7 @@ func (h *ModerationHandlerV2) PinPost(c *gin.Context) {
 		return
 	}
 
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -604,17 +871,101 @@ func (h *ModerationHandlerV2) PinPost(c *gin.Context) {
 		return
 	}
 
+	// Optional explicit position among the hub's pinned posts
+	var req struct {
+		PinOrder *int `json:"pin_order"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
 	err = h.postRepo.PinPost(c.Request.Context(), postID)
+	if errors.Is(err, models.ErrPinLimitReached) {
+		c.JSON(http.StatusConflict, gin.H{"error": "This hub already has the maximum number of pinned posts. Unpin a post first."})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	_, _ = h.modLogRepo.Log(c.Request.Context(), *post.HubID, userID.(int), "pin_post", "post", postID, models.JSONB{})
+	if req.PinOrder != nil {
+		if err := h.postRepo.SetPinOrder(c.Request.Context(), postID, *req.PinOrder); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	_, _ = h.modLogRepo.Log(c.Request.Context(), *post.HubID, userID.(int), "pin_post", "post", postID, models.JSONB{
+		"pin_order": req.PinOrder,
+	})
 
 	c.JSON(http.StatusOK, gin.H{"message": "Post pinned successfully"})
 }
 
+// SetPinOrder - PUT /api/v1/mod/posts/:id/pin-order
+func (h *ModerationHandlerV2) SetPinOrder(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	var req struct {
+		PinOrder int `json:"pin_order" binding:"required,min=1"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request body", err)
+		return
+	}
+
+	post, err := h.postRepo.GetByID(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if post == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if post.HubID == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot reorder posts without a hub"})
+		return
+	}
+	if !post.IsPinned {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Post is not pinned"})
+		return
+	}
+
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can reorder pinned posts"})
+		return
+	}
+
+	if err := h.postRepo.SetPinOrder(c.Request.Context(), postID, req.PinOrder); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	_, _ = h.modLogRepo.Log(c.Request.Context(), *post.HubID, userID.(int), "set_pin_order", "post", postID, models.JSONB{
+		"pin_order": req.PinOrder,
+	})
+
+	c.JSON(http.StatusOK, gin.H{"message": "Pin order updated successfully"})
+}
+
 // UnpinPost - POST /api/v1/mod/posts/:id/unpin
 func (h *ModerationHandlerV2) UnpinPost(c *gin.Context) {
 	userID, exists := c.Get("user_id")
@@ -643,7 +994,7 @@ func (h *ModerationHandlerV2) UnpinPost(c *gin.Context) {
 		return
 	}
 
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), *post.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, *post.HubID, userID.(int), models.ModPermPosts)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -676,7 +1027,7 @@ func (h *ModerationHandlerV2) CreateRemovalReason(c *gin.Context) {
 
 	hubName := c.Param("hub_name")
 
-	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int))
+	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int), models.ModPermConfig)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -739,7 +1090,7 @@ func (h *ModerationHandlerV2) UpdateRemovalReason(c *gin.Context) {
 	}
 
 	// Check moderator permission
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), existingReason.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, existingReason.HubID, userID.(int), models.ModPermConfig)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -798,7 +1149,7 @@ func (h *ModerationHandlerV2) DeleteRemovalReason(c *gin.Context) {
 	}
 
 	// Check moderator permission
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), existingReason.HubID, userID.(int))
+	isMod, err := h.hasModPermission(c, existingReason.HubID, userID.(int), models.ModPermConfig)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -829,7 +1180,7 @@ func (h *ModerationHandlerV2) GetRemovalReasons(c *gin.Context) {
 
 	hubName := c.Param("hub_name")
 
-	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int))
+	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int), models.ModPermConfig)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -852,9 +1203,288 @@ func (h *ModerationHandlerV2) GetRemovalReasons(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"removal_reasons": reasons})
 }
 
+// ===== REMOVED CONTENT =====
+
+// GetRemovedContent - GET /api/v1/mod/hubs/:hub_name/removed
+// Lists what has been removed in the hub, with reason, mod note and removing moderator.
+func (h *ModerationHandlerV2) GetRemovedContent(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var contentType string
+	switch strings.ToLower(c.DefaultQuery("type", "all")) {
+	case "posts":
+		contentType = "post"
+	case "comments":
+		contentType = "comment"
+	case "all":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type. Must be posts, comments or all"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermPosts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can view removed content"})
+		return
+	}
+
+	removed, err := h.removedContentRepo.GetByHub(c.Request.Context(), hubID, contentType, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"removed": removed, "limit": limit, "offset": offset})
+}
+
+// ===== AUTOMOD =====
+
+type automodRuleRequest struct {
+	Pattern   string `json:"pattern" binding:"required"`
+	IsRegex   bool   `json:"is_regex"`
+	Action    string `json:"action"`
+	AppliesTo string `json:"applies_to"`
+}
+
+// GetAutomodRules - GET /api/v1/mod/hubs/:hub_name/automod
+func (h *ModerationHandlerV2) GetAutomodRules(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermConfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can view automod rules"})
+		return
+	}
+
+	rules, err := h.automodRepo.GetByHub(c.Request.Context(), hubID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+// CreateAutomodRule - POST /api/v1/mod/hubs/:hub_name/automod
+func (h *ModerationHandlerV2) CreateAutomodRule(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermConfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can create automod rules"})
+		return
+	}
+
+	var req automodRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule := &models.AutomodRule{
+		HubID:     hubID,
+		Pattern:   req.Pattern,
+		IsRegex:   req.IsRegex,
+		Action:    req.Action,
+		AppliesTo: req.AppliesTo,
+		CreatedBy: userID.(int),
+	}
+	if err := h.automodRepo.Create(c.Request.Context(), rule); err != nil {
+		respondAutomodRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// UpdateAutomodRule - PUT /api/v1/mod/hubs/:hub_name/automod/:id
+func (h *ModerationHandlerV2) UpdateAutomodRule(c *gin.Context) {
+	rule, ok := h.loadHubAutomodRule(c, "update")
+	if !ok {
+		return
+	}
+
+	var req automodRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rule.Pattern = req.Pattern
+	rule.IsRegex = req.IsRegex
+	rule.Action = req.Action
+	rule.AppliesTo = req.AppliesTo
+	if err := h.automodRepo.Update(c.Request.Context(), rule); err != nil {
+		respondAutomodRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+// DeleteAutomodRule - DELETE /api/v1/mod/hubs/:hub_name/automod/:id
+func (h *ModerationHandlerV2) DeleteAutomodRule(c *gin.Context) {
+	rule, ok := h.loadHubAutomodRule(c, "delete")
+	if !ok {
+		return
+	}
+
+	if err := h.automodRepo.Delete(c.Request.Context(), rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Automod rule deleted successfully"})
+}
+
+// loadHubAutomodRule resolves the :id rule within :hub_name after checking the
+// caller moderates the hub. It writes the error response and returns false on failure.
+func (h *ModerationHandlerV2) loadHubAutomodRule(c *gin.Context, verb string) (*models.AutomodRule, bool) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return nil, false
+	}
+
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+		return nil, false
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermConfig)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return nil, false
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can " + verb + " automod rules"})
+		return nil, false
+	}
+
+	rule, err := h.automodRepo.GetByID(c.Request.Context(), ruleID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	if rule == nil || rule.HubID != hubID {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Automod rule not found"})
+		return nil, false
+	}
+
+	return rule, true
+}
+
+func respondAutomodRuleError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrInvalidAutomodPattern) || errors.Is(err, models.ErrInvalidAutomodRule) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// ===== MOD QUEUE =====
+
+// GetModQueue - GET /api/v1/mod/hubs/:hub_name/queue
+// Lists reported posts and comments in the hub that are still up, most reported first.
+func (h *ModerationHandlerV2) GetModQueue(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	contentType := strings.ToLower(c.DefaultQuery("type", "all"))
+	if contentType != "all" && contentType != "posts" && contentType != "comments" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid type. Must be posts, comments or all"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	hubID, isMod, err := h.checkModeratorPermission(c, c.Param("hub_name"), userID.(int), models.ModPermPosts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	if hubID == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Hub not found"})
+		return
+	}
+	if !isMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only moderators can view the mod queue"})
+		return
+	}
+
+	items, err := h.reportRepo.GetModQueue(c.Request.Context(), hubID, contentType, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": items, "limit": limit, "offset": offset})
+}
+
 // ===== MOD LOG =====
 
 // GetModLog - GET /api/v1/mod/hubs/:hubname/logs
+// Supports ?action=ban_user and ?mod_id=123 filters.
 func (h *ModerationHandlerV2) GetModLog(c *gin.Context) {
 	userID, exists := c.Get("user_id")
 	if !exists {
@@ -864,7 +1494,7 @@ func (h *ModerationHandlerV2) GetModLog(c *gin.Context) {
 
 	hubName := c.Param("hub_name")
 
-	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int))
+	hubID, isMod, err := h.checkModeratorPermission(c, hubName, userID.(int), "")
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -885,7 +1515,18 @@ func (h *ModerationHandlerV2) GetModLog(c *gin.Context) {
 		limit = 100
 	}
 
-	logs, err := h.modLogRepo.GetByHub(c.Request.Context(), hubID, limit, offset)
+	action := c.Query("action")
+	var modID *int
+	if raw := c.Query("mod_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid mod_id"})
+			return
+		}
+		modID = &id
+	}
+
+	logs, err := h.modLogRepo.GetByHubFiltered(c.Request.Context(), hubID, action, modID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -896,9 +1537,32 @@ func (h *ModerationHandlerV2) GetModLog(c *gin.Context) {
 
 // ===== HELPER METHODS =====
 
-// checkModeratorPermission checks if a user is a moderator of a hub and returns the hub ID
-// Admins have full moderation powers on all hubs without being listed as moderators
-func (h *ModerationHandlerV2) checkModeratorPermission(c *gin.Context, hubName string, userID int) (int, bool, error) {
+// notifyRemovalReason sends the author a content_removed notification quoting
+// the hub's removal reason. Removals without a reason (or with one from another
+// hub) send nothing. Failures are logged since the removal has already happened.
+func (h *ModerationHandlerV2) notifyRemovalReason(c *gin.Context, hubID, authorID int, contentType string, contentID int, reasonID *int) {
+	if h.notifService == nil || reasonID == nil {
+		return
+	}
+
+	reason, err := h.removalReasonRepo.GetByID(c.Request.Context(), *reasonID)
+	if err != nil {
+		log.Printf("Failed to load removal reason %d: %v", *reasonID, err)
+		return
+	}
+	if reason == nil || reason.HubID != hubID {
+		return
+	}
+
+	if err := h.notifService.NotifyContentRemoved(c.Request.Context(), authorID, contentType, contentID, reason); err != nil {
+		log.Printf("Failed to notify user %d about removed %s %d: %v", authorID, contentType, contentID, err)
+	}
+}
+
+// checkModeratorPermission resolves hubName and checks whether the user may act
+// in it with perm, returning the hub ID (0 if the hub does not exist).
+// An empty perm only requires being a moderator.
+func (h *ModerationHandlerV2) checkModeratorPermission(c *gin.Context, hubName string, userID int, perm string) (int, bool, error) {
 	// Get hub by name
 	hub, err := h.hubRepo.GetByName(c.Request.Context(), hubName)
 	if err != nil {
@@ -908,19 +1572,49 @@ func (h *ModerationHandlerV2) checkModeratorPermission(c *gin.Context, hubName s
 		return 0, false, nil
 	}
 
-	// Check if user is an admin (admins have mod powers on all hubs)
+	allowed, err := h.hasModPermission(c, hub.ID, userID, perm)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return hub.ID, allowed, nil
+}
+
+// isProtectedTarget reports whether targetID is the hub's owner or one of its
+// moderators while actorID is neither the owner nor a site admin.
+func (h *ModerationHandlerV2) isProtectedTarget(c *gin.Context, hubID, actorID, targetID int) (bool, error) {
+	if c.GetString("role") == "admin" {
+		return false, nil
+	}
+
+	hub, err := h.hubRepo.GetByID(c.Request.Context(), hubID)
+	if err != nil || hub == nil {
+		return false, err
+	}
+	if hub.CreatedBy != nil {
+		if *hub.CreatedBy == actorID {
+			return false, nil
+		}
+		if *hub.CreatedBy == targetID {
+			return true, nil
+		}
+	}
+
+	return h.hubModRepo.IsModerator(c.Request.Context(), hubID, targetID)
+}
+
+// hasModPermission checks whether the user moderates the hub with perm.
+// Admins have every permission on all hubs; an empty perm only requires being a moderator.
+func (h *ModerationHandlerV2) hasModPermission(c *gin.Context, hubID, userID int, perm string) (bool, error) {
 	roleVal, exists := c.Get("role")
 	if exists {
 		if role, ok := roleVal.(string); ok && role == "admin" {
-			return hub.ID, true, nil
+			return true, nil
 		}
 	}
 
-	// Check if user is a moderator
-	isMod, err := h.hubModRepo.IsModerator(c.Request.Context(), hub.ID, userID)
-	if err != nil {
-		return 0, false, err
+	if perm == "" {
+		return h.hubModRepo.IsModerator(c.Request.Context(), hubID, userID)
 	}
-
-	return hub.ID, isMod, nil
+	return h.hubModRepo.HasPermission(c.Request.Context(), hubID, userID, perm)
 }
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPreferences_DefaultAllOnAndPartialUpdates(t *testing.T) {
+	_, db, userID, cleanup := setupNotificationsHandlerTest(t)
+	defer cleanup()
+
+	handler := NewSettingsHandler(models.NewUserSettingsRepository(db.Pool))
+	router := gin.New()
+	router.Use(authMiddleware(userID))
+	router.GET("/notifications/preferences", handler.GetNotificationPreferences)
+	router.PUT("/notifications/preferences", handler.UpdateNotificationPreferences)
+
+	request := func(method string, body interface{}) (*httptest.ResponseRecorder, map[string]bool) {
+		var payload []byte
+		if body != nil {
+			var err error
+			payload, err = json.Marshal(body)
+			require.NoError(t, err)
+		}
+		req := httptest.NewRequest(method, "/notifications/preferences", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		var response struct {
+			Preferences map[string]bool `json:"preferences"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &response)
+		return w, response.Preferences
+	}
+
+	w, prefs := request(http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]bool{"reply": true, "mention": true, "mod_action": true, "vote": true}, prefs)
+
+	w, prefs = request(http.MethodPut, gin.H{"vote": false, "mod_action": false})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, map[string]bool{"reply": true, "mention": true, "mod_action": false, "vote": false}, prefs)
+
+	// Categories left out keep their value
+	w, prefs = request(http.MethodPut, gin.H{"mod_action": true})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.False(t, prefs["vote"])
+	assert.True(t, prefs["mod_action"])
+
+	w, _ = request(http.MethodPut, gin.H{"likes": false})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+	w, _ = request(http.MethodPut, gin.H{"vote": "off"})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+}
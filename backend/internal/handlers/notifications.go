@@ -19,7 +19,9 @@ func NewNotificationsHandler(notifRepo *models.NotificationRepository) *Notifica
 }
 
 // GetNotifications returns notifications for the authenticated user
-// GET /api/v1/notifications?limit=20&offset=0&unread_only=false
+// GET /api/v1/notifications?limit=20&offset=0&unread_only=false&grouped=false
+// With grouped=true, notifications about the same target collapse into one entry
+// with a count; unread_only doesn't apply to grouped results.
 func (h *NotificationsHandler) GetNotifications(c *gin.Context) {
 	userID := c.GetInt("user_id")
 
@@ -27,12 +29,28 @@ func (h *NotificationsHandler) GetNotifications(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	unreadOnly, _ := strconv.ParseBool(c.DefaultQuery("unread_only", "false"))
+	grouped, _ := strconv.ParseBool(c.DefaultQuery("grouped", "false"))
 
 	// Validate limit
 	if limit < 1 || limit > 100 {
 		limit = 20
 	}
 
+	if grouped {
+		groups, err := h.notifRepo.GetGrouped(c.Request.Context(), userID, limit, offset)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"notifications": groups,
+			"grouped":       true,
+			"limit":         limit,
+			"offset":        offset,
+		})
+		return
+	}
+
 	notifications, err := h.notifRepo.GetByUserID(c.Request.Context(), userID, limit, offset, unreadOnly)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
@@ -78,11 +96,25 @@ func (h *NotificationsHandler) MarkAsRead(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
 }
 
-// MarkAllAsRead marks all notifications as read for the user
-// POST /api/v1/notifications/read-all
+// MarkAllAsRead marks all notifications as read for the user, or only those of
+// one type or category (e.g. reply, mention) when ?type= is given
+// POST /api/v1/notifications/read-all?type=reply
 func (h *NotificationsHandler) MarkAllAsRead(c *gin.Context) {
 	userID := c.GetInt("user_id")
 
+	if notifType := c.Query("type"); notifType != "" {
+		if models.NotificationTypesFor(notifType) == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown notification type: " + notifType})
+			return
+		}
+		if err := h.notifRepo.MarkAllReadByType(c.Request.Context(), userID, notifType); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark notifications as read"})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"message": "Notifications marked as read"})
+		return
+	}
+
 	if err := h.notifRepo.MarkAllAsRead(c.Request.Context(), userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to mark all notifications as read"})
 		return
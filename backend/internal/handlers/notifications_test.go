@@ -199,6 +199,55 @@ func TestMarkAllAsRead(t *testing.T) {
 	assert.Equal(t, 0, count)
 }
 
+func TestMarkAllAsRead_ByType(t *testing.T) {
+	handler, db, userID, cleanup := setupNotificationsHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	notifRepo := models.NewNotificationRepository(db.Pool)
+	replyID := createTestNotification(t, db, userID, "comment_reply")
+	createTestNotification(t, db, userID, "post_comment")
+	mentionID := createTestNotification(t, db, userID, "mention")
+	milestoneID := createTestNotification(t, db, userID, "post_milestone")
+
+	router := gin.New()
+	router.POST("/read-all", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.MarkAllAsRead(c)
+	})
+	markRead := func(query string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/read-all"+query, nil))
+		return w.Code
+	}
+	unread := func() int {
+		count, err := notifRepo.GetUnreadCount(ctx, userID)
+		require.NoError(t, err)
+		return count
+	}
+	isRead := func(id int) bool {
+		notif, err := notifRepo.GetByID(ctx, id, userID)
+		require.NoError(t, err)
+		return notif.Read
+	}
+	require.Equal(t, 4, unread())
+
+	// A category covers all of its types
+	require.Equal(t, http.StatusOK, markRead("?type=reply"))
+	assert.Equal(t, 2, unread())
+	assert.True(t, isRead(replyID))
+	assert.False(t, isRead(mentionID))
+	assert.False(t, isRead(milestoneID))
+
+	// An exact notification type only covers itself
+	require.Equal(t, http.StatusOK, markRead("?type=post_milestone"))
+	assert.Equal(t, 1, unread())
+	assert.False(t, isRead(mentionID))
+
+	assert.Equal(t, http.StatusBadRequest, markRead("?type=likes"))
+	assert.Equal(t, 1, unread())
+}
+
 func TestDeleteNotification(t *testing.T) {
 	handler, db, userID, cleanup := setupNotificationsHandlerTest(t)
 	defer cleanup()
@@ -258,3 +307,68 @@ func TestGetNotificationsUnreadOnly(t *testing.T) {
 	notifications := response["notifications"].([]interface{})
 	assert.Len(t, notifications, 1, "Should only return unread notifications")
 }
+
+func TestGetNotifications_GroupedCollapsesSameTarget(t *testing.T) {
+	handler, db, userID, cleanup := setupNotificationsHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	notifRepo := models.NewNotificationRepository(db.Pool)
+	var voteIDs []int
+	for i := 0; i < 3; i++ {
+		notif := &models.Notification{
+			UserID:           userID,
+			NotificationType: "post_velocity",
+			ContentType:      testStrPtr("post"),
+			ContentID:        testIntPtr(42),
+			Message:          "Your post is trending",
+		}
+		require.NoError(t, notifRepo.Create(ctx, notif))
+		voteIDs = append(voteIDs, notif.ID)
+	}
+	require.NoError(t, notifRepo.MarkAsRead(ctx, voteIDs[0], userID))
+	// Same type on another post, and a different type on the same post, stay separate
+	otherPost := createTestNotification(t, db, userID, "post_velocity")
+	reply := &models.Notification{UserID: userID, NotificationType: "comment_reply", ContentType: testStrPtr("post"), ContentID: testIntPtr(42), Message: "Someone replied"}
+	require.NoError(t, notifRepo.Create(ctx, reply))
+
+	router := gin.New()
+	router.GET("/notifications", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		handler.GetNotifications(c)
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/notifications?grouped=true", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Notifications []models.GroupedNotification `json:"notifications"`
+		Grouped       bool                         `json:"grouped"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Grouped)
+	require.Len(t, response.Notifications, 3)
+
+	byID := map[int]models.GroupedNotification{}
+	for _, g := range response.Notifications {
+		byID[g.ID] = g
+	}
+	votes, ok := byID[voteIDs[2]]
+	require.True(t, ok, "the group is represented by its newest notification")
+	assert.Equal(t, 3, votes.Count)
+	assert.Equal(t, []int{voteIDs[2], voteIDs[1], voteIDs[0]}, votes.IDs)
+	assert.False(t, votes.Read, "a group with unread notifications is unread")
+	assert.False(t, votes.LatestAt.IsZero())
+	assert.Equal(t, 1, byID[otherPost].Count)
+	assert.Equal(t, 1, byID[reply.ID].Count)
+
+	// The flat list is unchanged
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/notifications", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var flat struct {
+		Notifications []models.Notification `json:"notifications"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &flat))
+	assert.Len(t, flat.Notifications, 5)
+}
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/omninudge/backend/internal/models"
@@ -17,6 +21,9 @@ type PostsHandler struct {
 	modRepo      *models.HubModeratorRepository
 	feedRepo     *models.FeedRepository
 	notifService *services.NotificationService
+	hubBanRepo   *models.HubBanRepository
+	automodRepo  *models.AutomodRepository
+	modLogRepo   *models.ModLogRepository
 }
 
 // NewPostsHandler creates a new posts handler
@@ -35,6 +42,17 @@ func (h *PostsHandler) SetNotificationService(notifService *services.Notificatio
 	h.notifService = notifService
 }
 
+// SetHubBanRepository enables blocking banned users from posting to hubs
+func (h *PostsHandler) SetHubBanRepository(hubBanRepo *models.HubBanRepository) {
+	h.hubBanRepo = hubBanRepo
+}
+
+// SetAutomod enables automod rules on new hub posts
+func (h *PostsHandler) SetAutomod(automodRepo *models.AutomodRepository, modLogRepo *models.ModLogRepository) {
+	h.automodRepo = automodRepo
+	h.modLogRepo = modLogRepo
+}
+
 // GetSubredditPosts handles GET /api/v1/subreddits/:name/posts
 // Returns local platform posts that have been crossposted to a subreddit
 func (h *PostsHandler) GetSubredditPosts(c *gin.Context) {
@@ -127,7 +145,7 @@ func (h *PostsHandler) CreatePost(c *gin.Context) {
 
 	var req CreatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -155,13 +173,24 @@ func (h *PostsHandler) CreatePost(c *gin.Context) {
 		}
 		hubID = req.HubID
 
-		// Validate content_options
-		if hub.ContentOptions == "links_only" && req.PostType == "text" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "This hub only accepts link posts"})
+		if rejectIfHubBanned(c, h.hubBanRepo, hub.ID, userID.(int)) {
 			return
 		}
-		if hub.ContentOptions == "text_only" && req.PostType == "link" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "This hub only accepts text posts"})
+
+		uid := userID.(int)
+		accessible, err := h.hubRepo.IsAccessibleTo(c.Request.Context(), hub.ID, &uid)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check hub membership", "details": err.Error()})
+			return
+		}
+		if !accessible {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This hub is private"})
+			return
+		}
+
+		// Validate content_options
+		if violation := hubContentViolation(hub.ContentOptions, req.PostType, req.MediaURL); violation != "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": violation})
 			return
 		}
 	}
@@ -190,6 +219,20 @@ func (h *PostsHandler) CreatePost(c *gin.Context) {
 	post.Score++
 	post.Upvotes++
 
+	content := post.Title
+	if post.Body != nil {
+		content += "\n" + *post.Body
+	}
+	removed := hubID != nil && applyAutomod(c.Request.Context(), h.automodRepo, h.modLogRepo, *hubID, models.AutomodAppliesToPosts, "post", post.ID, content, h.postRepo.MarkAsRemoved)
+	post.IsRemoved = removed
+
+	// Removed posts don't notify anyone they mention
+	if h.notifService != nil && !removed {
+		if err := h.notifService.NotifyMentions(c.Request.Context(), "post", post.ID, userID.(int), content); err != nil {
+			log.Printf("Failed to notify mentions in post %d: %v", post.ID, err)
+		}
+	}
+
 	c.JSON(http.StatusCreated, post)
 }
 
@@ -208,6 +251,10 @@ func (h *PostsHandler) GetPost(c *gin.Context) {
 		userID = &uidInt
 	}
 
+	if !requirePostAccess(c, h.postRepo, postID) {
+		return
+	}
+
 	post, err := h.postRepo.GetByIDWithUser(c.Request.Context(), postID, userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get post", "details": err.Error()})
@@ -239,6 +286,84 @@ func (h *PostsHandler) GetPost(c *gin.Context) {
 	c.JSON(http.StatusOK, post)
 }
 
+// requirePostAccess writes a 404 (or 500) response and returns false when the
+// current user can't access postID, e.g. because it's in a private hub they
+// don't belong to. Anonymous requests only reach posts outside private hubs.
+func requirePostAccess(c *gin.Context, postRepo *models.PlatformPostRepository, postID int) bool {
+	var viewerID *int
+	if uid, ok := c.Get("user_id"); ok {
+		if id, ok := uid.(int); ok {
+			viewerID = &id
+		}
+	}
+
+	accessible, err := postRepo.IsAccessibleTo(c.Request.Context(), postID, viewerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get post", "details": err.Error()})
+		return false
+	}
+	if !accessible {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return false
+	}
+	return true
+}
+
+// maxBatchLookupIDs caps how many posts or comments one batch lookup resolves
+const maxBatchLookupIDs = 100
+
+// parseIDList parses a comma-separated list of positive IDs, dropping duplicates
+func parseIDList(raw string) ([]int, error) {
+	var ids []int
+	seen := map[int]bool{}
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.Atoi(part)
+		if err != nil || id < 1 {
+			return nil, fmt.Errorf("invalid id %q", part)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, errors.New("ids is required")
+	}
+	if len(ids) > maxBatchLookupIDs {
+		return nil, fmt.Errorf("at most %d ids can be requested at once", maxBatchLookupIDs)
+	}
+	return ids, nil
+}
+
+// GetPostsByIDs handles GET /api/v1/posts?ids=1,2,3
+// Resolves several posts in one query. Posts the viewer can't see are omitted.
+func (h *PostsHandler) GetPostsByIDs(c *gin.Context) {
+	ids, err := parseIDList(c.Query("ids"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
+
+	var userID *int
+	if uid, exists := c.Get("user_id"); exists {
+		uidInt := uid.(int)
+		userID = &uidInt
+	}
+
+	posts, err := h.postRepo.GetVisibleByIDs(c.Request.Context(), ids, userID, includeNSFW)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get posts", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"posts": posts})
+}
+
 // GetFeed handles GET /api/v1/posts/feed
 func (h *PostsHandler) GetFeed(c *gin.Context) {
 	// Parse query parameters
@@ -258,6 +383,14 @@ func (h *PostsHandler) GetFeed(c *gin.Context) {
 		return
 	}
 
+	// Tag filters only apply to platform posts; both accept repeated params or comma lists
+	includeTags := parseTagParams(c.QueryArray("tags"))
+	excludeTags := parseTagParams(c.QueryArray("exclude_tags"))
+	if len(includeTags) > 0 || len(excludeTags) > 0 {
+		h.getTagFilteredFeed(c, sortBy, includeTags, excludeTags, limit, offset, hubName, sourceFilter)
+		return
+	}
+
 	if hubName != "" {
 		if sourceFilter == "reddit" {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Cannot filter by hub when requesting Reddit-only feed"})
@@ -309,6 +442,264 @@ func (h *PostsHandler) GetUserPosts(c *gin.Context) {
 	c.JSON(http.StatusNotImplemented, gin.H{"error": "Not implemented yet"})
 }
 
+// GetPostCrossposts handles GET /api/v1/posts/:id/crossposts
+// Lists live crossposts of a platform post; removed crossposts and ones the
+// viewer can't see are not included
+func (h *PostsHandler) GetPostCrossposts(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
+
+	if !requirePostAccess(c, h.postRepo, postID) {
+		return
+	}
+
+	origin, err := h.postRepo.GetByID(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post", "details": err.Error()})
+		return
+	}
+	if origin == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	crossposts, err := h.postRepo.GetCrossposts(c.Request.Context(), postID, optionalViewerID(c), includeNSFW, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch crossposts", "details": err.Error()})
+		return
+	}
+	if crossposts == nil {
+		crossposts = []*models.PlatformPost{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"crossposts":      crossposts,
+		"crosspost_count": origin.CrosspostCount,
+		"limit":           limit,
+		"offset":          offset,
+	})
+}
+
+// GetPostEdits handles GET /api/v1/posts/:id/edits
+// Returns the prior versions of a post, most recent edit first. Only the author,
+// global moderators/admins and the hub's moderators may see them.
+func (h *PostsHandler) GetPostEdits(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	roleStr := c.GetString("role")
+
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	post, err := h.postRepo.GetByID(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post", "details": err.Error()})
+		return
+	}
+	if post == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	visible, err := h.postRepo.IsVisibleTo(c.Request.Context(), postID, &userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch post", "details": err.Error()})
+		return
+	}
+	if !visible {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	isHubMod := false
+	if h.modRepo != nil && post.HubID != nil {
+		if ok, err := h.modRepo.IsModerator(c.Request.Context(), *post.HubID, userID); err == nil {
+			isHubMod = ok
+		}
+	}
+	if post.AuthorID != userID && roleStr != "moderator" && roleStr != "admin" && !isHubMod {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the author and moderators can view a post's edit history"})
+		return
+	}
+
+	edits, err := h.postRepo.GetEditHistory(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch edit history", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"post_id": postID,
+		"edits":   edits,
+	})
+}
+
+// RestorePost handles POST /api/v1/admin/posts/:id/restore (admin only)
+// Undoes a soft delete so the post shows up in feeds again.
+func (h *PostsHandler) RestorePost(c *gin.Context) {
+	postID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid post ID"})
+		return
+	}
+
+	post, err := h.postRepo.GetByIDIncludingDeleted(c.Request.Context(), postID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get post", "details": err.Error()})
+		return
+	}
+	if post == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if !post.IsDeleted {
+		c.JSON(http.StatusConflict, gin.H{"error": "Post is not deleted"})
+		return
+	}
+
+	if err := h.postRepo.Restore(c.Request.Context(), postID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to restore post", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Post restored successfully", "post_id": postID})
+}
+
+// getTagFilteredFeed serves GetFeed when tags or exclude_tags are given
+func (h *PostsHandler) getTagFilteredFeed(c *gin.Context, sortBy string, includeTags, excludeTags []string, limit, offset int, hubName, sourceFilter string) {
+	if sourceFilter == "reddit" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tag filters cannot be used with the Reddit-only feed"})
+		return
+	}
+	if hubName != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Tag filters cannot be combined with a hub filter"})
+		return
+	}
+	if len(includeTags) > maxTagsPerQuery || len(excludeTags) > maxTagsPerQuery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many tags (max 10)"})
+		return
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	posts, err := h.postRepo.GetFeedFiltered(c.Request.Context(), sortBy, includeTags, excludeTags, limit, offset, optionalViewerID(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get feed", "details": err.Error()})
+		return
+	}
+	if posts == nil {
+		posts = []*models.PlatformPost{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":        posts,
+		"limit":        limit,
+		"offset":       offset,
+		"sort":         sortBy,
+		"source":       "platform",
+		"tags":         includeTags,
+		"exclude_tags": excludeTags,
+	})
+}
+
+// maxTagsPerQuery bounds how many tags a by-tags lookup may request
+const maxTagsPerQuery = 10
+
+// GetPostsByTags handles GET /api/v1/posts/by-tags?tags=a,b&match=any|all&sort=new|top
+func (h *PostsHandler) GetPostsByTags(c *gin.Context) {
+	tags := parseTagList(c.Query("tags"))
+	if len(tags) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "At least one tag is required"})
+		return
+	}
+	if len(tags) > maxTagsPerQuery {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Too many tags (max 10)"})
+		return
+	}
+
+	match := c.DefaultQuery("match", "any")
+	if match != "any" && match != "all" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid match mode. Must be 'any' or 'all'"})
+		return
+	}
+
+	sortBy := c.DefaultQuery("sort", "new")
+	if sortBy != "new" && sortBy != "top" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort. Must be 'new' or 'top'"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
+	var userID *int
+	if uid, exists := c.Get("user_id"); exists {
+		uidInt := uid.(int)
+		userID = &uidInt
+	}
+
+	posts, err := h.postRepo.GetByTags(c.Request.Context(), tags, match == "all", sortBy, userID, includeNSFW, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts", "details": err.Error()})
+		return
+	}
+	if posts == nil {
+		posts = []*models.PlatformPost{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"posts":  posts,
+		"tags":   tags,
+		"match":  match,
+		"sort":   sortBy,
+		"limit":  limit,
+		"offset": offset,
+	})
+}
+
+// parseTagList splits a comma-separated tag list, dropping blanks and duplicates
+func parseTagList(raw string) []string {
+	seen := make(map[string]bool)
+	tags := []string{}
+	for _, tag := range strings.Split(raw, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// parseTagParams merges a repeated query param whose values may themselves be comma-separated
+func parseTagParams(values []string) []string {
+	return parseTagList(strings.Join(values, ","))
+}
+
 // UpdatePost handles PUT /api/v1/posts/:id
 func (h *PostsHandler) UpdatePost(c *gin.Context) {
 	// Get user ID from context
@@ -338,10 +729,10 @@ func (h *PostsHandler) UpdatePost(c *gin.Context) {
 		return
 	}
 
-	// Verify user owns this post or is a global moderator/admin or hub moderator
+	// Verify user owns this post or is a global moderator/admin or hub moderator with the posts scope
 	isHubMod := false
 	if h.modRepo != nil && existingPost.HubID != nil {
-		if ok, err := h.modRepo.IsModerator(c.Request.Context(), *existingPost.HubID, userID.(int)); err == nil {
+		if ok, err := h.modRepo.HasPermission(c.Request.Context(), *existingPost.HubID, userID.(int), models.ModPermPosts); err == nil {
 			isHubMod = ok
 		}
 	}
@@ -353,7 +744,7 @@ func (h *PostsHandler) UpdatePost(c *gin.Context) {
 
 	var req UpdatePostRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -402,10 +793,10 @@ func (h *PostsHandler) DeletePost(c *gin.Context) {
 		return
 	}
 
-	// Verify user owns this post or is global mod/admin or hub mod
+	// Verify user owns this post or is global mod/admin or hub mod with the posts scope
 	isHubMod := false
 	if h.modRepo != nil && existingPost.HubID != nil {
-		if ok, err := h.modRepo.IsModerator(c.Request.Context(), *existingPost.HubID, userID.(int)); err == nil {
+		if ok, err := h.modRepo.HasPermission(c.Request.Context(), *existingPost.HubID, userID.(int), models.ModPermPosts); err == nil {
 			isHubMod = ok
 		}
 	}
@@ -442,11 +833,20 @@ func (h *PostsHandler) VotePost(c *gin.Context) {
 		IsUpvote *bool `json:"is_upvote"` // true=upvote, false=downvote, null=remove
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
-	if err := h.postRepo.Vote(c.Request.Context(), postID, userID.(int), req.IsUpvote); err != nil {
+	if !requirePostAccess(c, h.postRepo, postID) {
+		return
+	}
+
+	result, err := h.postRepo.VoteWithResult(c.Request.Context(), postID, userID.(int), req.IsUpvote)
+	if errors.Is(err, models.ErrVoteTargetNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to vote on post", "details": err.Error()})
 		return
 	}
@@ -457,12 +857,21 @@ func (h *PostsHandler) VotePost(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get updated post", "details": err.Error()})
 		return
 	}
+	if post == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		return
+	}
+
+	// Report the counts from the vote transaction rather than the re-read,
+	// which may already include other users' concurrent votes
+	post.Score, post.Upvotes, post.Downvotes = result.Score, result.Upvotes, result.Downvotes
+	post.UserVote = &result.UserVote
 
 	// Trigger notification check if this was an upvote and service is available
 	if h.notifService != nil && req.IsUpvote != nil && *req.IsUpvote {
 		// Run in background to not block response
 		go func() {
-			_ = h.notifService.CheckAndNotifyVote(c.Request.Context(), "post", postID, post.AuthorID, post.Upvotes)
+			_ = h.notifService.CheckAndNotifyVote(c.Request.Context(), "post", postID, post.AuthorID, result.Upvotes)
 		}()
 	}
 
@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrivateHubPosts_LimitedToMembersAndAdmins(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	modRepo := models.NewHubModeratorRepository(db.Pool)
+	memberRepo := models.NewHubMemberRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	newUser := func(name string) *models.User {
+		user := &models.User{Username: fmt.Sprintf("%s_%d", name, suffix), PasswordHash: "hash"}
+		require.NoError(t, userRepo.Create(ctx, user))
+		return user
+	}
+	owner := newUser("privposts_owner")
+	member := newUser("privposts_member")
+	stranger := newUser("privposts_stranger")
+	admin := newUser("privposts_admin")
+	require.NoError(t, userRepo.UpdateRole(ctx, admin.ID, "admin"))
+
+	hub := &models.Hub{Name: fmt.Sprintf("privposts_%d", suffix), Type: "private", ContentOptions: "any", CreatedBy: &owner.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+	require.NoError(t, memberRepo.AddMember(ctx, hub.ID, member.ID, owner.ID))
+
+	post := &models.PlatformPost{AuthorID: member.ID, HubID: &hub.ID, Title: "Members only"}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	postsHandler := NewPostsHandler(postRepo, hubRepo, userRepo, modRepo, nil)
+	commentsHandler := NewCommentsHandler(commentRepo, postRepo, modRepo)
+	gin.SetMode(gin.TestMode)
+
+	request := func(userID int, method, path string, payload interface{}) int {
+		router := gin.New()
+		if userID != 0 {
+			router.Use(authMiddleware(userID))
+		}
+		router.GET("/posts/:id", postsHandler.GetPost)
+		router.POST("/posts", postsHandler.CreatePost)
+		router.GET("/posts/:id/comments", commentsHandler.GetComments)
+		router.POST("/posts/:id/comments", commentsHandler.CreateComment)
+
+		var body []byte
+		if payload != nil {
+			body, err = json.Marshal(payload)
+			require.NoError(t, err)
+		}
+		req := httptest.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	postPath := fmt.Sprintf("/posts/%d", post.ID)
+	commentsPath := postPath + "/comments"
+	newPost := map[string]interface{}{"hub_id": hub.ID, "title": "Hello", "post_type": "text"}
+	newComment := map[string]interface{}{"body": "Hi"}
+
+	for _, userID := range []int{member.ID, owner.ID, admin.ID} {
+		assert.Equal(t, http.StatusOK, request(userID, http.MethodGet, postPath, nil))
+		assert.Equal(t, http.StatusOK, request(userID, http.MethodGet, commentsPath, nil))
+		assert.Equal(t, http.StatusCreated, request(userID, http.MethodPost, commentsPath, newComment))
+		assert.Equal(t, http.StatusCreated, request(userID, http.MethodPost, "/posts", newPost))
+	}
+
+	// Outsiders can't tell the post exists, or post into the hub
+	for _, userID := range []int{stranger.ID, 0} {
+		assert.Equal(t, http.StatusNotFound, request(userID, http.MethodGet, postPath, nil))
+		assert.Equal(t, http.StatusNotFound, request(userID, http.MethodGet, commentsPath, nil))
+	}
+	assert.Equal(t, http.StatusNotFound, request(stranger.ID, http.MethodPost, commentsPath, newComment))
+	assert.Equal(t, http.StatusForbidden, request(stranger.ID, http.MethodPost, "/posts", newPost))
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type byTagsTestEnv struct {
+	db       *database.DB
+	postRepo *models.PlatformPostRepository
+	router   *gin.Engine
+	authorID int
+	hubID    int
+	tagA     string
+	tagB     string
+}
+
+func setupByTagsTest(t *testing.T) (*byTagsTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	user := &models.User{Username: fmt.Sprintf("tagger_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hub := &models.Hub{Name: fmt.Sprintf("taghub_%d", suffix), CreatedBy: &user.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	handler := NewPostsHandler(postRepo, hubRepo, userRepo, nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts/by-tags", handler.GetPostsByTags)
+	router.GET("/posts/feed", handler.GetFeed)
+
+	env := &byTagsTestEnv{
+		db:       db,
+		postRepo: postRepo,
+		router:   router,
+		authorID: user.ID,
+		hubID:    hub.ID,
+		tagA:     fmt.Sprintf("alpha%d", suffix),
+		tagB:     fmt.Sprintf("beta%d", suffix),
+	}
+	return env, func() { db.Close() }
+}
+
+func (env *byTagsTestEnv) createPost(t *testing.T, title string, tags ...string) *models.PlatformPost {
+	t.Helper()
+	post := &models.PlatformPost{AuthorID: env.authorID, HubID: &env.hubID, Title: title, Tags: tags}
+	require.NoError(t, env.postRepo.Create(context.Background(), post))
+	return post
+}
+
+func (env *byTagsTestEnv) fetchIDs(t *testing.T, query string) []int {
+	t.Helper()
+	return env.fetchPathIDs(t, "/posts/by-tags?"+query)
+}
+
+func (env *byTagsTestEnv) fetchPathIDs(t *testing.T, target string) []int {
+	t.Helper()
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response struct {
+		Posts []models.PlatformPost `json:"posts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	ids := make([]int, 0, len(response.Posts))
+	for _, p := range response.Posts {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestGetPostsByTags_AnyVersusAll(t *testing.T) {
+	env, cleanup := setupByTagsTest(t)
+	defer cleanup()
+
+	onlyA := env.createPost(t, "only a", env.tagA)
+	onlyB := env.createPost(t, "only b", env.tagB)
+	both := env.createPost(t, "both", env.tagA, env.tagB)
+
+	anyIDs := env.fetchIDs(t, fmt.Sprintf("tags=%s,%s", env.tagA, env.tagB))
+	assert.ElementsMatch(t, []int{onlyA.ID, onlyB.ID, both.ID}, anyIDs)
+
+	allIDs := env.fetchIDs(t, fmt.Sprintf("tags=%s,%s&match=all", env.tagA, env.tagB))
+	assert.Equal(t, []int{both.ID}, allIDs)
+}
+
+func TestGetPostsByTags_PostWithBothTagsNotDuplicated(t *testing.T) {
+	env, cleanup := setupByTagsTest(t)
+	defer cleanup()
+
+	both := env.createPost(t, "both", env.tagA, env.tagB)
+
+	// Repeating a tag in the query must not repeat the post either
+	ids := env.fetchIDs(t, fmt.Sprintf("tags=%s,%s,%s", env.tagA, env.tagB, env.tagA))
+	assert.Equal(t, []int{both.ID}, ids)
+}
+
+func TestGetPostsByTags_StablePaginationOnTies(t *testing.T) {
+	env, cleanup := setupByTagsTest(t)
+	defer cleanup()
+
+	var created []int
+	for i := 0; i < 5; i++ {
+		created = append(created, env.createPost(t, fmt.Sprintf("tie %d", i), env.tagA).ID)
+	}
+
+	// Force identical timestamps so only the id tiebreaker orders the page
+	_, err := env.db.Pool.Exec(context.Background(),
+		`UPDATE platform_posts SET created_at = '2024-01-01T00:00:00Z' WHERE id = ANY($1)`, created)
+	require.NoError(t, err)
+
+	var paged []int
+	for offset := 0; offset < 5; offset += 2 {
+		paged = append(paged, env.fetchIDs(t, fmt.Sprintf("tags=%s&limit=2&offset=%d", env.tagA, offset))...)
+	}
+
+	expected := []int{created[4], created[3], created[2], created[1], created[0]}
+	assert.Equal(t, expected, paged)
+}
+
+func TestGetPostsByTags_HidesPostsViewerCannotSee(t *testing.T) {
+	env, cleanup := setupByTagsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	visible := env.createPost(t, "visible", env.tagA)
+
+	removed := env.createPost(t, "removed", env.tagA)
+	require.NoError(t, env.postRepo.MarkAsRemoved(ctx, removed.ID, env.authorID))
+
+	hubRepo := models.NewHubRepository(env.db.Pool)
+	suffix := time.Now().UnixNano()
+	privateHub := &models.Hub{Name: fmt.Sprintf("tagprivate_%d", suffix), Type: "private", CreatedBy: &env.authorID}
+	require.NoError(t, hubRepo.Create(ctx, privateHub))
+	quarantinedHub := &models.Hub{Name: fmt.Sprintf("tagquarantined_%d", suffix), CreatedBy: &env.authorID}
+	require.NoError(t, hubRepo.Create(ctx, quarantinedHub))
+	_, err := env.db.Pool.Exec(ctx, `UPDATE hubs SET is_quarantined = TRUE WHERE id = $1`, quarantinedHub.ID)
+	require.NoError(t, err)
+
+	for _, hubID := range []int{privateHub.ID, quarantinedHub.ID} {
+		id := hubID
+		post := &models.PlatformPost{AuthorID: env.authorID, HubID: &id, Title: "hidden", Tags: []string{env.tagA}}
+		require.NoError(t, env.postRepo.Create(ctx, post))
+	}
+
+	// Anonymous viewers only see the public, live post
+	assert.Equal(t, []int{visible.ID}, env.fetchIDs(t, "tags="+env.tagA))
+}
+
+func TestGetPostsByTags_RejectsInvalidParams(t *testing.T) {
+	handler := NewPostsHandler(nil, nil, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts/by-tags", handler.GetPostsByTags)
+
+	for _, query := range []string{"", "tags=,%20,", "tags=a&match=some", "tags=a&sort=hot"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/by-tags?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestParseTagList_TrimsAndDedups(t *testing.T) {
+	assert.Equal(t, []string{"go", "rust"}, parseTagList(" go, rust ,go,,"))
+	assert.Empty(t, parseTagList(""))
+}
+
+func TestGetFeed_TagFilters(t *testing.T) {
+	env, cleanup := setupByTagsTest(t)
+	defer cleanup()
+
+	onlyA := env.createPost(t, "only a", env.tagA)
+	onlyB := env.createPost(t, "only b", env.tagB)
+	both := env.createPost(t, "both", env.tagA, env.tagB)
+	untagged := env.createPost(t, "untagged")
+
+	t.Run("include only", func(t *testing.T) {
+		ids := env.fetchPathIDs(t, fmt.Sprintf("/posts/feed?tags=%s&limit=100", env.tagA))
+		assert.ElementsMatch(t, []int{onlyA.ID, both.ID}, ids)
+	})
+
+	t.Run("exclude only", func(t *testing.T) {
+		ids := env.fetchPathIDs(t, fmt.Sprintf("/posts/feed?exclude_tags=%s&limit=100", env.tagA))
+		assert.Contains(t, ids, onlyB.ID)
+		assert.Contains(t, ids, untagged.ID, "untagged posts should survive tag exclusion")
+		assert.NotContains(t, ids, onlyA.ID)
+		assert.NotContains(t, ids, both.ID)
+	})
+
+	t.Run("exclude repeated param", func(t *testing.T) {
+		ids := env.fetchPathIDs(t, fmt.Sprintf("/posts/feed?exclude_tags=%s&exclude_tags=%s&limit=100", env.tagA, env.tagB))
+		assert.Contains(t, ids, untagged.ID)
+		assert.NotContains(t, ids, onlyA.ID)
+		assert.NotContains(t, ids, onlyB.ID)
+		assert.NotContains(t, ids, both.ID)
+	})
+
+	t.Run("combined", func(t *testing.T) {
+		ids := env.fetchPathIDs(t, fmt.Sprintf("/posts/feed?tags=%s,%s&exclude_tags=%s&limit=100", env.tagA, env.tagB, env.tagB))
+		assert.Equal(t, []int{onlyA.ID}, ids)
+	})
+}
+
+func TestGetFeed_TagFiltersRejectRedditSource(t *testing.T) {
+	handler := NewPostsHandler(nil, nil, nil, nil, nil)
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/posts/feed", handler.GetFeed)
+
+	for _, query := range []string{"exclude_tags=spoiler&source=reddit", "tags=go&hub=golang"} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts/feed?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestParseTagParams_MergesRepeatedValues(t *testing.T) {
+	assert.Equal(t, []string{"spoiler", "nsfw", "meta"}, parseTagParams([]string{"spoiler", " nsfw,meta", "spoiler"}))
+	assert.Empty(t, parseTagParams(nil))
+}
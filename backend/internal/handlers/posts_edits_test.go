@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPostEdits_LimitedToAuthorAndModerators(t *testing.T) {
+	env, cleanup := setupHubModeratorsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	owner := env.createUser(t, "edits_owner")
+	author := env.createUser(t, "edits_author")
+	mod := env.createUser(t, "edits_mod")
+	member := env.createUser(t, "edits_member")
+	stranger := env.createUser(t, "edits_stranger")
+
+	hub := env.createHub(t, "editshub", "public", owner.ID)
+	require.NoError(t, env.modRepo.AddModerator(ctx, hub.ID, mod.ID))
+	post := &models.PlatformPost{AuthorID: author.ID, HubID: &hub.ID, Title: "Original"}
+	require.NoError(t, env.postRepo.Create(ctx, post))
+
+	privateHub := env.createHub(t, "editsprivate", "private", owner.ID)
+	require.NoError(t, env.memberRepo.AddMember(ctx, privateHub.ID, author.ID, owner.ID))
+	privatePost := &models.PlatformPost{AuthorID: author.ID, HubID: &privateHub.ID, Title: "Members only"}
+	require.NoError(t, env.postRepo.Create(ctx, privatePost))
+
+	handler := NewPostsHandler(env.postRepo, env.hubRepo, env.userRepo, env.modRepo, nil)
+	getEdits := func(userID, postID int) int {
+		router := gin.New()
+		router.GET("/posts/:id/edits", authMiddleware(userID), handler.GetPostEdits)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/posts/%d/edits", postID), nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, getEdits(author.ID, post.ID))
+	assert.Equal(t, http.StatusOK, getEdits(mod.ID, post.ID))
+	assert.Equal(t, http.StatusForbidden, getEdits(member.ID, post.ID))
+	assert.Equal(t, http.StatusForbidden, getEdits(stranger.ID, post.ID))
+
+	// Posts the viewer can't see at all look missing
+	assert.Equal(t, http.StatusOK, getEdits(author.ID, privatePost.ID))
+	assert.Equal(t, http.StatusNotFound, getEdits(stranger.ID, privatePost.ID))
+
+	require.NoError(t, env.postRepo.MarkAsRemoved(ctx, post.ID, mod.ID))
+	assert.Equal(t, http.StatusNotFound, getEdits(author.ID, post.ID))
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestorePost_RoundTrip(t *testing.T) {
+	env, cleanup := setupByTagsTest(t)
+	defer cleanup()
+
+	handler := NewPostsHandler(env.postRepo, nil, nil, nil, nil)
+	router := gin.New()
+	router.POST("/admin/posts/:id/restore", handler.RestorePost)
+
+	restore := func(id string) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/posts/"+id+"/restore", nil))
+		return w.Code
+	}
+
+	post := env.createPost(t, "restore me", env.tagA)
+	assert.Equal(t, http.StatusConflict, restore(fmt.Sprint(post.ID)), "live posts cannot be restored")
+
+	require.NoError(t, env.postRepo.SoftDelete(context.Background(), post.ID))
+	assert.Empty(t, env.fetchIDs(t, "tags="+env.tagA), "deleted post should be hidden")
+
+	assert.Equal(t, http.StatusOK, restore(fmt.Sprint(post.ID)))
+	assert.Equal(t, []int{post.ID}, env.fetchIDs(t, "tags="+env.tagA), "restored post should be visible again")
+
+	assert.Equal(t, http.StatusNotFound, restore("2147483647"))
+	assert.Equal(t, http.StatusBadRequest, restore("abc"))
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+)
+
+// PushSubscriptionsHandler registers browsers for web push notifications
+type PushSubscriptionsHandler struct {
+	pushRepo *models.PushSubscriptionRepository
+}
+
+// NewPushSubscriptionsHandler creates a new push subscriptions handler
+func NewPushSubscriptionsHandler(pushRepo *models.PushSubscriptionRepository) *PushSubscriptionsHandler {
+	return &PushSubscriptionsHandler{pushRepo: pushRepo}
+}
+
+// PushSubscribeRequest mirrors the browser's PushSubscription.toJSON() output
+type PushSubscribeRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys"`
+}
+
+// PushUnsubscribeRequest identifies the subscription to remove
+type PushUnsubscribeRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
+
+// Subscribe stores a push subscription for the authenticated user
+// POST /api/v1/notifications/push/subscribe
+func (h *PushSubscriptionsHandler) Subscribe(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var req PushSubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+	if !services.IsAllowedPushEndpoint(req.Endpoint) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "endpoint must be an https URL on a known push service"})
+		return
+	}
+
+	sub := &models.PushSubscription{
+		UserID:   userID,
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+	}
+	if err := h.pushRepo.Subscribe(c.Request.Context(), sub); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save push subscription"})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscription": sub})
+}
+
+// Unsubscribe removes one of the authenticated user's push subscriptions
+// POST /api/v1/notifications/push/unsubscribe
+func (h *PushSubscriptionsHandler) Unsubscribe(c *gin.Context) {
+	userID := c.GetInt("user_id")
+
+	var req PushUnsubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		return
+	}
+
+	removed, err := h.pushRepo.Unsubscribe(c.Request.Context(), userID, req.Endpoint)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove push subscription"})
+		return
+	}
+	if !removed {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Push subscription not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Push subscription removed"})
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushSubscriptions_SubscribeAndUnsubscribe(t *testing.T) {
+	_, db, userID, cleanup := setupNotificationsHandlerTest(t)
+	defer cleanup()
+
+	pushRepo := models.NewPushSubscriptionRepository(db.Pool)
+	handler := NewPushSubscriptionsHandler(pushRepo)
+	router := gin.New()
+	router.Use(authMiddleware(userID))
+	router.POST("/notifications/push/subscribe", handler.Subscribe)
+	router.POST("/notifications/push/unsubscribe", handler.Unsubscribe)
+
+	send := func(path string, payload gin.H) *httptest.ResponseRecorder {
+		body, err := json.Marshal(payload)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	endpoint := "https://fcm.googleapis.com/fcm/send/" + uniqueNotifUsername("endpoint")
+	subscribe := gin.H{"endpoint": endpoint, "keys": gin.H{"p256dh": "client-key", "auth": "client-secret"}}
+	w := send("/notifications/push/subscribe", subscribe)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Re-subscribing the same browser refreshes its keys rather than duplicating it
+	subscribe["keys"] = gin.H{"p256dh": "rotated-key", "auth": "rotated-secret"}
+	w = send("/notifications/push/subscribe", subscribe)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	subs, err := pushRepo.GetByUserID(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, "rotated-key", subs[0].P256dh)
+	assert.Equal(t, "rotated-secret", subs[0].Auth)
+
+	// Only https endpoints on known push services, so the server can't be
+	// pointed at internal hosts
+	for _, bad := range []string{
+		"http://fcm.googleapis.com/fcm/send/x",
+		"https://push.example.com/x",
+		"https://169.254.169.254/latest/meta-data/",
+		"https://localhost:8080/x",
+	} {
+		w = send("/notifications/push/subscribe", gin.H{"endpoint": bad, "keys": gin.H{"p256dh": "k", "auth": "a"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code, bad)
+	}
+	w = send("/notifications/push/subscribe", gin.H{"endpoint": endpoint})
+	assert.Equal(t, http.StatusBadRequest, w.Code, w.Body.String())
+
+	w = send("/notifications/push/unsubscribe", gin.H{"endpoint": endpoint})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	w = send("/notifications/push/unsubscribe", gin.H{"endpoint": endpoint})
+	assert.Equal(t, http.StatusNotFound, w.Code, w.Body.String())
+}
@@ -7,10 +7,12 @@ import (
 	"fmt"
 	"html"
 	"log"
+	"net"
 	"net/http"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -24,6 +26,7 @@ const redditCacheTTL = 15 * time.Minute
 type RedditHandler struct {
 	redditClient *services.RedditClient
 	redditRepo   *models.RedditPostRepository
+	knownBots    *services.KnownBots
 }
 
 // NewRedditHandler creates a new Reddit handler
@@ -39,6 +42,36 @@ func NewRedditHandlerForTest(redditClient *services.RedditClient) *RedditHandler
 	return &RedditHandler{redditClient: redditClient}
 }
 
+// SetKnownBots enables bot flagging on Reddit comment threads (called after initialization)
+func (h *RedditHandler) SetKnownBots(knownBots *services.KnownBots) {
+	h.knownBots = knownBots
+}
+
+// respondRedditError reports a failed Reddit call. Calls that ran out of time
+// (the request deadline or the client timeout) get a retryable 504, and
+// private or banned subreddits get a 403 or 404 with a reason the frontend can
+// show; anything else responds 500 with body.
+func respondRedditError(c *gin.Context, err error, body gin.H) {
+	switch {
+	case isRedditTimeout(err):
+		c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Reddit took too long to respond", "retryable": true})
+	case errors.Is(err, services.ErrRedditForbiddenPrivate):
+		c.JSON(http.StatusForbidden, gin.H{"error": "This subreddit is private", "reason": "private"})
+	case errors.Is(err, services.ErrRedditBanned):
+		c.JSON(http.StatusNotFound, gin.H{"error": "This subreddit has been banned", "reason": "banned"})
+	default:
+		c.JSON(http.StatusInternalServerError, body)
+	}
+}
+
+func isRedditTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
 // GetSubredditPosts handles GET /api/v1/reddit/r/:subreddit
 func (h *RedditHandler) GetSubredditPosts(c *gin.Context) {
 	subreddit := c.Param("subreddit")
@@ -51,7 +84,8 @@ func (h *RedditHandler) GetSubredditPosts(c *gin.Context) {
 	sort := c.DefaultQuery("sort", "hot") // hot, new, top, rising, controversial
 	timeFilter := c.DefaultQuery("t", "") // hour, day, week, month, year, all (for top/controversial)
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
-	after := c.DefaultQuery("after", "") // Pagination cursor
+	after := c.DefaultQuery("after", "")   // Pagination cursor (older items)
+	before := c.DefaultQuery("before", "") // Pagination cursor (newer items)
 
 	// Validate limit
 	if limit < 1 || limit > 100 {
@@ -59,13 +93,17 @@ func (h *RedditHandler) GetSubredditPosts(c *gin.Context) {
 	}
 
 	// Fetch from Reddit
-	listing, err := h.redditClient.GetSubredditPosts(c.Request.Context(), subreddit, sort, timeFilter, limit, after)
+	listing, err := h.redditClient.GetSubredditPosts(c.Request.Context(), subreddit, sort, timeFilter, limit, after, before)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subreddit posts", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch subreddit posts", "details": err.Error()})
 		return
 	}
-	cacheKey := fmt.Sprintf("sr:%s:%s:%s:%d:%s", strings.ToLower(subreddit), sort, timeFilter, limit, after)
-	h.cacheListing(c.Request.Context(), listing, cacheKey)
+	if listing.Stale {
+		c.Header("X-Cache", "stale")
+	} else {
+		cacheKey := fmt.Sprintf("sr:%s:%s:%s:%d:%s:%s", strings.ToLower(subreddit), sort, timeFilter, limit, after, before)
+		h.cacheListing(c.Request.Context(), listing, cacheKey)
+	}
 
 	// Extract posts from listing
 	posts := make([]services.RedditPost, 0, len(listing.Data.Children))
@@ -94,7 +132,7 @@ func (h *RedditHandler) GetSubredditAbout(c *gin.Context) {
 
 	about, err := h.redditClient.GetSubredditAbout(c.Request.Context(), subreddit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subreddit details", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch subreddit details", "details": err.Error()})
 		return
 	}
 
@@ -104,6 +142,27 @@ func (h *RedditHandler) GetSubredditAbout(c *gin.Context) {
 	})
 }
 
+// GetSubredditRules handles GET /api/v1/reddit/r/:subreddit/rules
+// so hub moderators can import a subreddit's rules.
+func (h *RedditHandler) GetSubredditRules(c *gin.Context) {
+	subreddit := c.Param("subreddit")
+	if subreddit == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subreddit name is required"})
+		return
+	}
+
+	rules, err := h.redditClient.GetSubredditRules(c.Request.Context(), subreddit)
+	if err != nil {
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch subreddit rules", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subreddit": strings.ToLower(subreddit),
+		"rules":     rules,
+	})
+}
+
 // GetSubredditModerators handles GET /api/v1/reddit/r/:subreddit/moderators
 func (h *RedditHandler) GetSubredditModerators(c *gin.Context) {
 	subreddit := c.Param("subreddit")
@@ -122,7 +181,7 @@ func (h *RedditHandler) GetSubredditModerators(c *gin.Context) {
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
+		respondRedditError(c, err, gin.H{
 			"error":   "Failed to fetch subreddit moderators",
 			"details": err.Error(),
 		})
@@ -135,6 +194,117 @@ func (h *RedditHandler) GetSubredditModerators(c *gin.Context) {
 	})
 }
 
+// GetSubredditLinkFlairs handles GET /api/v1/reddit/r/:subreddit/flairs
+func (h *RedditHandler) GetSubredditLinkFlairs(c *gin.Context) {
+	subreddit := c.Param("subreddit")
+	if subreddit == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subreddit name is required"})
+		return
+	}
+
+	flairs, err := h.redditClient.GetSubredditLinkFlairs(c.Request.Context(), subreddit)
+	if err != nil {
+		if errors.Is(err, services.ErrRedditFlairsUnavailable) {
+			c.JSON(http.StatusOK, gin.H{
+				"subreddit": strings.ToLower(subreddit),
+				"flairs":    []services.RedditFlair{},
+				"warning":   "Reddit blocked the flair list for this subreddit without OAuth access.",
+			})
+			return
+		}
+		respondRedditError(c, err, gin.H{
+			"error":   "Failed to fetch subreddit flairs",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subreddit": strings.ToLower(subreddit),
+		"flairs":    flairs,
+	})
+}
+
+// GetSubredditOverview handles GET /api/v1/reddit/r/:subreddit/overview
+// Resolves about, rules and moderators concurrently. A failed section is
+// returned as null and listed in section_errors instead of failing the request.
+func (h *RedditHandler) GetSubredditOverview(c *gin.Context) {
+	subreddit := c.Param("subreddit")
+	if subreddit == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subreddit name is required"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	var (
+		wg         sync.WaitGroup
+		about      *services.RedditSubredditAbout
+		rules      []services.RedditSubredditRule
+		moderators []services.RedditSubredditModerator
+		aboutErr   error
+		rulesErr   error
+		modsErr    error
+	)
+
+	wg.Add(3)
+	go func() {
+		defer wg.Done()
+		about, aboutErr = h.redditClient.GetSubredditAbout(ctx, subreddit)
+	}()
+	go func() {
+		defer wg.Done()
+		rules, rulesErr = h.redditClient.GetSubredditRules(ctx, subreddit)
+	}()
+	go func() {
+		defer wg.Done()
+		moderators, modsErr = h.redditClient.GetSubredditModerators(ctx, subreddit)
+	}()
+	wg.Wait()
+
+	sectionErrors := gin.H{}
+	response := gin.H{
+		"subreddit":      strings.ToLower(subreddit),
+		"about":          about,
+		"rules":          rules,
+		"moderators":     moderators,
+		"section_errors": sectionErrors,
+	}
+
+	if errors.Is(aboutErr, services.ErrRedditForbiddenPrivate) || errors.Is(aboutErr, services.ErrRedditBanned) {
+		// The other sections are meaningless for a subreddit nobody can view
+		respondRedditError(c, aboutErr, nil)
+		return
+	}
+	if aboutErr != nil {
+		sectionErrors["about"] = "Failed to fetch subreddit details"
+		response["about"] = nil
+	}
+	if rulesErr != nil {
+		sectionErrors["rules"] = "Failed to fetch subreddit rules"
+		response["rules"] = nil
+	}
+	if modsErr != nil {
+		if errors.Is(modsErr, services.ErrRedditModeratorsUnavailable) {
+			sectionErrors["moderators"] = "Reddit blocked the moderators list for this subreddit without OAuth access."
+		} else {
+			sectionErrors["moderators"] = "Failed to fetch subreddit moderators"
+		}
+		response["moderators"] = nil
+	}
+
+	if len(sectionErrors) == 3 {
+		if isRedditTimeout(aboutErr) && isRedditTimeout(rulesErr) && isRedditTimeout(modsErr) {
+			respondRedditError(c, aboutErr, nil)
+			return
+		}
+		response["error"] = "Failed to fetch subreddit overview"
+		c.JSON(http.StatusBadGateway, response)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 // GetFrontPage handles GET /api/v1/reddit/frontpage
 func (h *RedditHandler) GetFrontPage(c *gin.Context) {
 	// Parse query parameters
@@ -142,20 +312,30 @@ func (h *RedditHandler) GetFrontPage(c *gin.Context) {
 	timeFilter := c.DefaultQuery("t", "")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
 	after := c.DefaultQuery("after", "")
+	before := c.DefaultQuery("before", "")
 
 	// Validate limit
 	if limit < 1 || limit > 100 {
 		limit = 25
 	}
 
-	// Fetch from Reddit
-	listing, err := h.redditClient.GetFrontPage(c.Request.Context(), sort, timeFilter, limit, after)
+	// Fetch from Reddit; users who linked Reddit get their own front page
+	var listing *services.RedditListing
+	var err error
+	personalized := false
+	if userID, ok := c.Get("user_id"); ok {
+		listing, personalized, err = h.redditClient.GetUserFrontPage(c.Request.Context(), userID.(int), sort, timeFilter, limit, after, before)
+	} else {
+		listing, err = h.redditClient.GetFrontPage(c.Request.Context(), sort, timeFilter, limit, after, before)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch front page", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch front page", "details": err.Error()})
 		return
 	}
-	cacheKey := fmt.Sprintf("front:%s:%s:%d:%s", sort, timeFilter, limit, after)
-	h.cacheListing(c.Request.Context(), listing, cacheKey)
+	if !personalized {
+		cacheKey := fmt.Sprintf("front:%s:%s:%d:%s:%s", sort, timeFilter, limit, after, before)
+		h.cacheListing(c.Request.Context(), listing, cacheKey)
+	}
 
 	// Extract posts from listing
 	posts := make([]services.RedditPost, 0, len(listing.Data.Children))
@@ -164,12 +344,13 @@ func (h *RedditHandler) GetFrontPage(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"sort":   sort,
-		"time":   timeFilter,
-		"limit":  limit,
-		"after":  listing.Data.After,
-		"before": listing.Data.Before,
-		"posts":  posts,
+		"sort":         sort,
+		"time":         timeFilter,
+		"limit":        limit,
+		"after":        listing.Data.After,
+		"before":       listing.Data.Before,
+		"personalized": personalized,
+		"posts":        posts,
 	})
 }
 
@@ -195,14 +376,83 @@ func (h *RedditHandler) GetPostComments(c *gin.Context) {
 	// Fetch from Reddit
 	result, err := h.redditClient.GetPostComments(c.Request.Context(), subreddit, postID, sort, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch comments", "details": err.Error()})
 		return
 	}
 
+	// Flag known bot comments; they start collapsed unless expand_bots=true
+	if h.knownBots != nil {
+		expandBots, _ := strconv.ParseBool(c.DefaultQuery("expand_bots", "false"))
+		h.knownBots.FlagBotComments(c.Request.Context(), result, expandBots)
+	}
+
 	// Return raw Reddit response (includes post + comments)
 	c.JSON(http.StatusOK, result)
 }
 
+// GetDuplicatePosts handles GET /api/v1/reddit/r/:subreddit/duplicates/:postId
+// and returns other discussions of the same link, highest score first.
+func (h *RedditHandler) GetDuplicatePosts(c *gin.Context) {
+	subreddit := c.Param("subreddit")
+	postID := c.Param("postId")
+	if subreddit == "" || postID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Subreddit and post ID are required"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	duplicates, err := h.redditClient.GetDuplicatePosts(c.Request.Context(), subreddit, postID, limit)
+	if err != nil {
+		if errors.Is(err, services.ErrRedditNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+			return
+		}
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch duplicate posts", "details": err.Error()})
+		return
+	}
+
+	posts := make([]services.RedditPost, 0, len(duplicates))
+	for _, post := range duplicates {
+		posts = append(posts, normalizeRedditPost(post))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subreddit": subreddit,
+		"post_id":   postID,
+		"limit":     limit,
+		"posts":     posts,
+	})
+}
+
+// ResolveRedditLink handles GET /api/v1/reddit/resolve?url=
+// and turns a pasted redd.it or reddit.com permalink into the post it points to.
+func (h *RedditHandler) ResolveRedditLink(c *gin.Context) {
+	link := c.Query("url")
+	if link == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+
+	post, err := h.redditClient.ResolveShortlink(c.Request.Context(), link)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRedditInvalidLink):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "URL is not a Reddit post link", "details": err.Error()})
+		case errors.Is(err, services.ErrRedditNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+		default:
+			respondRedditError(c, err, gin.H{"error": "Failed to resolve Reddit link", "details": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"post": normalizeRedditPost(*post)})
+}
+
 // SearchPosts handles GET /api/v1/reddit/search
 func (h *RedditHandler) SearchPosts(c *gin.Context) {
 	query := c.Query("q")
@@ -217,6 +467,7 @@ func (h *RedditHandler) SearchPosts(c *gin.Context) {
 	timeFilter := c.DefaultQuery("t", "")       // hour, day, week, month, year, all (for top)
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
 	after := c.DefaultQuery("after", "")
+	before := c.DefaultQuery("before", "")
 	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
 
 	// Validate limit
@@ -225,9 +476,9 @@ func (h *RedditHandler) SearchPosts(c *gin.Context) {
 	}
 
 	// Fetch from Reddit
-	listing, err := h.redditClient.SearchPosts(c.Request.Context(), query, subreddit, sort, timeFilter, limit, after, includeNSFW)
+	listing, err := h.redditClient.SearchPosts(c.Request.Context(), query, subreddit, sort, timeFilter, limit, after, before, includeNSFW)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search posts", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to search posts", "details": err.Error()})
 		return
 	}
 
@@ -266,7 +517,7 @@ func (h *RedditHandler) SearchRedditUsers(c *gin.Context) {
 
 	listing, err := h.redditClient.SearchUsers(c.Request.Context(), query, limit, after, includeNSFW)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to search users", "details": err.Error()})
 		return
 	}
 
@@ -320,7 +571,7 @@ func (h *RedditHandler) AutocompleteSubreddits(c *gin.Context) {
 
 	suggestions, err := h.redditClient.AutocompleteSubreddits(c.Request.Context(), query, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subreddit suggestions", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch subreddit suggestions", "details": err.Error()})
 		return
 	}
 
@@ -346,7 +597,7 @@ func (h *RedditHandler) SearchSubreddits(c *gin.Context) {
 
 	results, nextAfter, err := h.redditClient.SearchSubreddits(c.Request.Context(), query, limit, after)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search subreddits", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to search subreddits", "details": err.Error()})
 		return
 	}
 
@@ -390,10 +641,15 @@ func (h *RedditHandler) GetRedditUserListing(c *gin.Context) {
 	sort := c.DefaultQuery("sort", "new")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
 	after := c.DefaultQuery("after", "")
+	before := c.DefaultQuery("before", "")
 
-	listing, err := h.redditClient.GetUserListing(c.Request.Context(), username, section, sort, limit, after)
+	listing, err := h.redditClient.GetUserListing(c.Request.Context(), username, section, sort, limit, after, before)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user activity", "details": err.Error()})
+		if errors.Is(err, services.ErrRedditConflictingCursors) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only one of after or before can be set"})
+			return
+		}
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch user activity", "details": err.Error()})
 		return
 	}
 
@@ -407,6 +663,50 @@ func (h *RedditHandler) GetRedditUserListing(c *gin.Context) {
 	})
 }
 
+// GetRedditMultireddit handles GET /api/v1/reddit/user/:username/m/:multi
+func (h *RedditHandler) GetRedditMultireddit(c *gin.Context) {
+	username := c.Param("username")
+	multi := c.Param("multi")
+	if username == "" || multi == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Username and multireddit name are required"})
+		return
+	}
+
+	sort := c.DefaultQuery("sort", "hot")
+	timeFilter := c.DefaultQuery("t", "")
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "25"))
+	after := c.DefaultQuery("after", "")
+	if limit < 1 || limit > 100 {
+		limit = 25
+	}
+
+	listing, err := h.redditClient.GetMultireddit(c.Request.Context(), username, multi, sort, timeFilter, limit, after)
+	if err != nil {
+		if errors.Is(err, services.ErrRedditNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Multireddit not found"})
+			return
+		}
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch multireddit", "details": err.Error()})
+		return
+	}
+
+	posts := make([]services.RedditPost, 0, len(listing.Data.Children))
+	for _, child := range listing.Data.Children {
+		posts = append(posts, normalizeRedditPost(child.Data))
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"username": username,
+		"multi":    multi,
+		"sort":     sort,
+		"time":     timeFilter,
+		"limit":    limit,
+		"after":    listing.Data.After,
+		"before":   listing.Data.Before,
+		"posts":    posts,
+	})
+}
+
 // GetRedditUserAbout handles GET /api/v1/reddit/user/:username/about
 func (h *RedditHandler) GetRedditUserAbout(c *gin.Context) {
 	username := c.Param("username")
@@ -417,7 +717,7 @@ func (h *RedditHandler) GetRedditUserAbout(c *gin.Context) {
 
 	about, err := h.redditClient.GetUserAbout(c.Request.Context(), username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch user", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch user", "details": err.Error()})
 		return
 	}
 
@@ -434,7 +734,7 @@ func (h *RedditHandler) GetRedditUserTrophies(c *gin.Context) {
 
 	trophies, err := h.redditClient.GetUserTrophies(c.Request.Context(), username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch trophies", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch trophies", "details": err.Error()})
 		return
 	}
 
@@ -451,7 +751,7 @@ func (h *RedditHandler) GetRedditUserModerated(c *gin.Context) {
 
 	subs, err := h.redditClient.GetUserModeratedSubreddits(c.Request.Context(), username)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderated subreddits", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch moderated subreddits", "details": err.Error()})
 		return
 	}
 
@@ -472,6 +772,7 @@ func (h *RedditHandler) GetSubredditMedia(c *gin.Context) {
 	timeFilter := c.DefaultQuery("t", "")
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
 	after := c.DefaultQuery("after", "")
+	before := c.DefaultQuery("before", "")
 
 	// Validate limit (fetch more to filter for media)
 	if limit < 1 || limit > 100 {
@@ -479,13 +780,17 @@ func (h *RedditHandler) GetSubredditMedia(c *gin.Context) {
 	}
 
 	// Fetch from Reddit - get more posts to ensure we have enough media
-	listing, err := h.redditClient.GetSubredditPosts(c.Request.Context(), subreddit, sort, timeFilter, 100, after)
+	listing, err := h.redditClient.GetSubredditPosts(c.Request.Context(), subreddit, sort, timeFilter, 100, after, before)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch subreddit posts", "details": err.Error()})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch subreddit posts", "details": err.Error()})
 		return
 	}
-	cacheKey := fmt.Sprintf("media:%s:%s:%s:%s", strings.ToLower(subreddit), sort, timeFilter, after)
-	h.cacheListing(c.Request.Context(), listing, cacheKey)
+	if listing.Stale {
+		c.Header("X-Cache", "stale")
+	} else {
+		cacheKey := fmt.Sprintf("media:%s:%s:%s:%s:%s", strings.ToLower(subreddit), sort, timeFilter, after, before)
+		h.cacheListing(c.Request.Context(), listing, cacheKey)
+	}
 
 	// Filter for media posts only
 	mediaPosts := make([]gin.H, 0)
@@ -540,6 +845,7 @@ func (h *RedditHandler) GetSubredditMedia(c *gin.Context) {
 		"total":       len(mediaPosts),
 		"media_posts": mediaPosts,
 		"after":       listing.Data.After,
+		"before":      listing.Data.Before,
 	})
 }
 
@@ -696,7 +1002,7 @@ func (h *RedditHandler) GetSubredditWikiPage(c *gin.Context) {
 		pagePath = "index"
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	wikiPage, err := h.redditClient.GetSubredditWikiPage(ctx, subreddit, pagePath, revision)
 	if err != nil {
 		if errors.Is(err, services.ErrRedditNotFound) {
@@ -704,7 +1010,7 @@ func (h *RedditHandler) GetSubredditWikiPage(c *gin.Context) {
 			return
 		}
 		log.Printf("Error fetching wiki page for r/%s/wiki/%s: %v", subreddit, pagePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wiki page"})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch wiki page"})
 		return
 	}
 
@@ -738,7 +1044,7 @@ func (h *RedditHandler) CompareSubredditWikiRevisions(c *gin.Context) {
 		return
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	fromData, err := h.redditClient.GetSubredditWikiPage(ctx, subreddit, pagePath, fromRevision)
 	if err != nil {
 		if errors.Is(err, services.ErrRedditNotFound) {
@@ -746,7 +1052,7 @@ func (h *RedditHandler) CompareSubredditWikiRevisions(c *gin.Context) {
 			return
 		}
 		log.Printf("Error fetching from revision for r/%s/wiki/%s: %v", subreddit, pagePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch older revision"})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch older revision"})
 		return
 	}
 
@@ -757,7 +1063,7 @@ func (h *RedditHandler) CompareSubredditWikiRevisions(c *gin.Context) {
 			return
 		}
 		log.Printf("Error fetching to revision for r/%s/wiki/%s: %v", subreddit, pagePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch newer revision"})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch newer revision"})
 		return
 	}
 
@@ -779,7 +1085,7 @@ func (h *RedditHandler) GetWikiPage(c *gin.Context) {
 		pagePath = "index"
 	}
 
-	ctx := context.Background()
+	ctx := c.Request.Context()
 	wikiPage, err := h.redditClient.GetWikiPage(ctx, pagePath)
 	if err != nil {
 		if errors.Is(err, services.ErrRedditNotFound) {
@@ -787,7 +1093,7 @@ func (h *RedditHandler) GetWikiPage(c *gin.Context) {
 			return
 		}
 		log.Printf("Error fetching wiki page wiki/%s: %v", pagePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wiki page"})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch wiki page"})
 		return
 	}
 
@@ -815,7 +1121,7 @@ func (h *RedditHandler) GetSubredditWikiRevisions(c *gin.Context) {
 			return
 		}
 		log.Printf("Error fetching wiki revisions for r/%s/wiki/%s: %v", subreddit, pagePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wiki revisions"})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch wiki revisions"})
 		return
 	}
 
@@ -850,7 +1156,7 @@ func (h *RedditHandler) GetSubredditWikiDiscussions(c *gin.Context) {
 			return
 		}
 		log.Printf("Error fetching wiki discussions for r/%s/wiki/%s: %v", subreddit, pagePath, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch wiki discussions"})
+		respondRedditError(c, err, gin.H{"error": "Failed to fetch wiki discussions"})
 		return
 	}
 
@@ -111,6 +111,7 @@ func (h *RedditCommentsHandler) GetRedditPostComments(c *gin.Context) {
 		"post_id":   postID,
 		"count":     len(comments),
 		"comments":  comments,
+		"tree":      models.BuildTree(comments),
 	})
 }
 
@@ -134,7 +135,7 @@ func (h *RedditCommentsHandler) CreateRedditPostComment(c *gin.Context) {
 
 	var req CreateRedditCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -232,7 +233,7 @@ func (h *RedditCommentsHandler) UpdateRedditPostComment(c *gin.Context) {
 
 	var req UpdateRedditCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -333,7 +334,7 @@ func (h *RedditCommentsHandler) UpdateRedditPostCommentPreferences(c *gin.Contex
 
 	var req UpdateRedditCommentPreferencesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body", "details": err.Error()})
+		respondBindingError(c, "Invalid request body", err)
 		return
 	}
 
@@ -373,7 +374,7 @@ func (h *RedditCommentsHandler) VoteRedditPostComment(c *gin.Context) {
 	// Parse vote request
 	var req VoteRedditCommentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body. Vote must be -1 (downvote), 0 (remove), or 1 (upvote)", "details": err.Error()})
+		respondBindingError(c, "Invalid request body. Vote must be -1 (downvote), 0 (remove), or 1 (upvote)", err)
 		return
 	}
 
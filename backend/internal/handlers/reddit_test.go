@@ -5,22 +5,37 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/api/middleware"
 	"github.com/omninudge/backend/internal/services"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+// newTestRedditClient builds a Reddit client with a valid single User-Agent.
+func newTestRedditClient(t *testing.T, cache services.Cache, cacheTTL time.Duration, clientID, clientSecret string, opts ...services.RedditClientOption) *services.RedditClient {
+	t.Helper()
+	client, err := services.NewRedditClient("test-agent", cache, cacheTTL, clientID, clientSecret, opts...)
+	require.NoError(t, err)
+	return client
+}
+
 // mockRedditCache is a simple in-memory cache for testing
 type mockRedditCache struct {
+	mu    sync.Mutex
 	store map[string]string
 }
 
 func (m *mockRedditCache) Get(ctx context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.store == nil {
 		return "", false, nil
 	}
@@ -29,6 +44,8 @@ func (m *mockRedditCache) Get(ctx context.Context, key string) (string, bool, er
 }
 
 func (m *mockRedditCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	if m.store == nil {
 		m.store = make(map[string]string)
 	}
@@ -36,6 +53,13 @@ func (m *mockRedditCache) Set(ctx context.Context, key string, value string, ttl
 	return nil
 }
 
+func (m *mockRedditCache) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.store, key)
+	return nil
+}
+
 // hostRewriteTransport rewrites outgoing requests to a test server
 type hostRewriteTransport struct {
 	target *httptest.Server
@@ -120,7 +144,7 @@ func setupRedditHandlerTest(t *testing.T) (*RedditHandler, *httptest.Server, *in
 	}))
 
 	cache := &mockRedditCache{store: make(map[string]string)}
-	client := services.NewRedditClient("test-agent", cache, time.Minute, "", "")
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
 	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
 
 	handler := NewRedditHandlerForTest(client)
@@ -306,7 +330,7 @@ func TestAutocompleteSubreddits(t *testing.T) {
 	}))
 	defer ts.Close()
 
-	client := services.NewRedditClient("test-agent", nil, time.Minute, "", "")
+	client := newTestRedditClient(t, nil, time.Minute, "", "")
 	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
 	handler := NewRedditHandlerForTest(client)
 
@@ -329,7 +353,7 @@ func TestAutocompleteSubreddits(t *testing.T) {
 }
 
 func TestAutocompleteSubredditsRequiresQuery(t *testing.T) {
-	client := services.NewRedditClient("test-agent", nil, time.Minute, "", "")
+	client := newTestRedditClient(t, nil, time.Minute, "", "")
 	handler := NewRedditHandlerForTest(client)
 
 	router := gin.Default()
@@ -504,3 +528,478 @@ func TestGetSubredditMediaPaginationSupport(t *testing.T) {
 	// Response should include pagination cursor
 	assert.Equal(t, "t3_after", response["after"])
 }
+
+func TestGetSubredditOverview_ModeratorsUnavailable(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		switch {
+		case strings.Contains(r.URL.Path, "/about/moderators"):
+			w.WriteHeader(http.StatusForbidden)
+		case strings.HasSuffix(r.URL.Path, "/about/rules.json"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"rules": [{"short_name": "Be civil", "kind": "all", "priority": 0}]}`))
+		case strings.HasSuffix(r.URL.Path, "/about.json"):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"data": {"display_name": "golang", "title": "The Go Programming Language", "subscribers": 250000}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	cache := &mockRedditCache{store: make(map[string]string)}
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/r/:subreddit/overview", handler.GetSubredditOverview)
+
+	req := httptest.NewRequest(http.MethodGet, "/r/golang/overview", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+
+	about := response["about"].(map[string]interface{})
+	assert.Equal(t, "golang", about["display_name"])
+
+	rules := response["rules"].([]interface{})
+	require.Len(t, rules, 1)
+	assert.Equal(t, "Be civil", rules[0].(map[string]interface{})["short_name"])
+
+	assert.Nil(t, response["moderators"])
+	sectionErrors := response["section_errors"].(map[string]interface{})
+	assert.Contains(t, sectionErrors, "moderators")
+	assert.NotContains(t, sectionErrors, "about")
+	assert.NotContains(t, sectionErrors, "rules")
+
+	// About and rules are cached independently; only the moderators lookup is retried
+	firstCalls := atomic.LoadInt32(&calls)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/r/golang/overview", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, firstCalls+2, atomic.LoadInt32(&calls))
+}
+
+// botThreadFixture is a trimmed Reddit comments response: [post listing, comment listing]
+const botThreadFixture = `[
+	{"kind": "Listing", "data": {"children": [{"kind": "t3", "data": {"id": "p1", "author": "op_user", "title": "Post"}}]}},
+	{"kind": "Listing", "data": {"children": [
+		{"kind": "t1", "data": {"id": "c1", "author": "AutoModerator", "body": "Please read the rules.", "collapsed": false, "replies": ""}},
+		{"kind": "t1", "data": {"id": "c2", "author": "regular_user", "body": "Great post!", "collapsed": false, "replies": {
+			"kind": "Listing", "data": {"children": [
+				{"kind": "t1", "data": {"id": "c3", "author": "RemindMeBot", "body": "I will be messaging you", "collapsed": false, "replies": ""}}
+			]}
+		}}},
+		{"kind": "more", "data": {"count": 3, "children": ["c4", "c5", "c6"]}}
+	]}}
+]`
+
+func setupBotThreadTest(t *testing.T) (*gin.Engine, func()) {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(botThreadFixture))
+	}))
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+	handler.SetKnownBots(services.NewKnownBots([]string{"AutoModerator", "RemindMeBot"}, nil, time.Minute))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/r/:subreddit/comments/:postId", handler.GetPostComments)
+	return router, ts.Close
+}
+
+func fetchBotThreadComments(t *testing.T, router *gin.Engine, path string) map[string]map[string]interface{} {
+	t.Helper()
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var thread []interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &thread))
+	require.Len(t, thread, 2)
+
+	comments := map[string]map[string]interface{}{}
+	var collect func(listing interface{})
+	collect = func(listing interface{}) {
+		l, ok := listing.(map[string]interface{})
+		if !ok {
+			return
+		}
+		children, _ := l["data"].(map[string]interface{})["children"].([]interface{})
+		for _, child := range children {
+			node := child.(map[string]interface{})
+			if node["kind"] != "t1" {
+				continue
+			}
+			data := node["data"].(map[string]interface{})
+			comments[data["id"].(string)] = data
+			collect(data["replies"])
+		}
+	}
+	collect(thread[1])
+	return comments
+}
+
+func TestGetPostComments_BotCommentsFlaggedAndCollapsed(t *testing.T) {
+	router, cleanup := setupBotThreadTest(t)
+	defer cleanup()
+
+	comments := fetchBotThreadComments(t, router, "/r/golang/comments/p1")
+	require.Len(t, comments, 3)
+
+	assert.Equal(t, true, comments["c1"]["is_bot"])
+	assert.Equal(t, true, comments["c1"]["collapsed"])
+
+	assert.Equal(t, false, comments["c2"]["is_bot"])
+	assert.Equal(t, false, comments["c2"]["collapsed"])
+
+	// Nested replies are flagged too
+	assert.Equal(t, true, comments["c3"]["is_bot"])
+	assert.Equal(t, true, comments["c3"]["collapsed"])
+}
+
+func TestGetPostComments_ExpandBots(t *testing.T) {
+	router, cleanup := setupBotThreadTest(t)
+	defer cleanup()
+
+	comments := fetchBotThreadComments(t, router, "/r/golang/comments/p1?expand_bots=true")
+
+	assert.Equal(t, true, comments["c1"]["is_bot"])
+	assert.Equal(t, false, comments["c1"]["collapsed"])
+}
+
+func TestRedditListingsThreadPaginationCursors(t *testing.T) {
+	var mu sync.Mutex
+	var lastQuery url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		lastQuery = r.URL.Query()
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/user/") {
+			_, _ = w.Write([]byte(`{"kind":"Listing","data":{"after":"t1_older","before":"t1_newer","children":[]}}`))
+			return
+		}
+		resp := services.RedditListing{Kind: "Listing"}
+		resp.Data.After = "t3_older"
+		resp.Data.Before = "t3_newer"
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/r/:subreddit", handler.GetSubredditPosts)
+	router.GET("/r/:subreddit/media", handler.GetSubredditMedia)
+	router.GET("/frontpage", handler.GetFrontPage)
+	router.GET("/search", handler.SearchPosts)
+	router.GET("/user/:username/:section", handler.GetRedditUserListing)
+
+	tests := []struct {
+		name   string
+		path   string
+		after  string
+		before string
+	}{
+		{"subreddit", "/r/golang", "t3_older", "t3_newer"},
+		{"subreddit media", "/r/golang/media", "t3_older", "t3_newer"},
+		{"front page", "/frontpage", "t3_older", "t3_newer"},
+		{"search", "/search?q=gophers", "t3_older", "t3_newer"},
+		{"user listing", "/user/spez/submitted", "t1_older", "t1_newer"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := tt.path
+			if strings.Contains(path, "?") {
+				path += "&before=t3_cursor"
+			} else {
+				path += "?before=t3_cursor"
+			}
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+
+			mu.Lock()
+			assert.Equal(t, "t3_cursor", lastQuery.Get("before"))
+			assert.Empty(t, lastQuery.Get("after"))
+			mu.Unlock()
+
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.after, response["after"])
+			assert.Equal(t, tt.before, response["before"])
+		})
+	}
+}
+
+func TestRedditHandlers_SlowUpstreamTimesOutWith504(t *testing.T) {
+	upstreamCancelled := make(chan struct{}, 1)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+			upstreamCancelled <- struct{}{}
+		case <-time.After(5 * time.Second):
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"kind":"Listing","data":{"children":[]}}`))
+		}
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	const timeout = 200 * time.Millisecond
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestTimeout(timeout))
+	router.GET("/r/:subreddit", handler.GetSubredditPosts)
+	router.GET("/r/:subreddit/wiki/:pagePath", handler.GetSubredditWikiPage)
+
+	for _, path := range []string{"/r/golang", "/r/golang/wiki/index"} {
+		start := time.Now()
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		elapsed := time.Since(start)
+
+		require.Equal(t, http.StatusGatewayTimeout, w.Code, path)
+		assert.Less(t, elapsed, timeout+time.Second, path)
+		var body map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, true, body["retryable"], path)
+
+		// The in-flight Reddit request is aborted rather than left running
+		select {
+		case <-upstreamCancelled:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("upstream request for %s was not cancelled", path)
+		}
+	}
+}
+
+func TestGetRedditMultireddit(t *testing.T) {
+	var gotQuery url.Values
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user/spez/m/tech/top.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		gotQuery = r.URL.Query()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"Listing","data":{"after":"t3_next","children":[{"kind":"t3","data":{"id":"m1","title":"multi post","subreddit":"golang"}}]}}`))
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/user/:username/m/:multi", handler.GetRedditMultireddit)
+	router.GET("/user/:username/:section", handler.GetRedditUserListing)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/spez/m/tech?sort=top&t=week&limit=10", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "week", gotQuery.Get("t"))
+	assert.Equal(t, "10", gotQuery.Get("limit"))
+
+	var response struct {
+		After string                `json:"after"`
+		Posts []services.RedditPost `json:"posts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "t3_next", response.After)
+	require.Len(t, response.Posts, 1)
+	assert.Equal(t, "m1", response.Posts[0].ID)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/user/spez/m/missing", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Multireddit not found")
+}
+
+func TestGetSubredditPosts_ServesStaleListingWithHeader(t *testing.T) {
+	var failing atomic.Bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"Listing","data":{"children":[{"kind":"t3","data":{"id":"s1","title":"cached post","subreddit":"golang"}}]}}`))
+	}))
+	defer ts.Close()
+
+	// A 1ns TTL makes every cached listing stale immediately
+	client := newTestRedditClient(t, &mockRedditCache{}, time.Nanosecond, "", "",
+		services.WithRedditRetryPolicy(services.RedditRetryPolicy{}),
+		services.WithRedditStaleFallback(time.Hour))
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/r/:subreddit", handler.GetSubredditPosts)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/r/golang", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Cache"))
+
+	failing.Store(true)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/r/golang", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "stale", w.Header().Get("X-Cache"))
+	assert.Contains(t, w.Body.String(), "cached post")
+
+	// Other subreddits have nothing cached to fall back on
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/r/rust", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestRedditHandlers_PrivateAndBannedSubreddits(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/r/secret/"):
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"reason": "private", "message": "Forbidden", "error": 403}`))
+		case strings.HasPrefix(r.URL.Path, "/r/gone/"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"reason": "banned", "message": "Not Found", "error": 404}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`<html>blocked</html>`))
+		}
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "",
+		services.WithRedditRetryPolicy(services.RedditRetryPolicy{}))
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/r/:subreddit", handler.GetSubredditPosts)
+	router.GET("/r/:subreddit/about", handler.GetSubredditAbout)
+	router.GET("/r/:subreddit/overview", handler.GetSubredditOverview)
+
+	cases := []struct {
+		subreddit string
+		status    int
+		reason    interface{}
+	}{
+		{"secret", http.StatusForbidden, "private"},
+		{"gone", http.StatusNotFound, "banned"},
+		{"other", http.StatusInternalServerError, nil},
+	}
+	for _, tc := range cases {
+		for _, path := range []string{"/r/" + tc.subreddit, "/r/" + tc.subreddit + "/about"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+			require.Equal(t, tc.status, w.Code, path)
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tc.reason, body["reason"], path)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/r/secret/overview", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestResolveRedditLink(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("id") != "t3_abc123" {
+			_, _ = w.Write([]byte(`{"kind":"Listing","data":{"children":[]}}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"kind":"Listing","data":{"children":[{"kind":"t3","data":{"id":"abc123","subreddit":"golang","title":"Resolved &amp; found"}}]}}`))
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/resolve", handler.ResolveRedditLink)
+
+	resolve := func(link string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/resolve?url="+url.QueryEscape(link), nil))
+		return w
+	}
+
+	w := resolve("https://redd.it/abc123")
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Post services.RedditPost `json:"post"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "abc123", body.Post.ID)
+	assert.Equal(t, "Resolved & found", body.Post.Title)
+
+	assert.Equal(t, http.StatusNotFound, resolve("https://www.reddit.com/r/golang/comments/zzz999/gone/").Code)
+	assert.Equal(t, http.StatusBadRequest, resolve("https://example.com/comments/abc123").Code)
+	assert.Equal(t, http.StatusBadRequest, resolve("").Code)
+}
+
+func TestGetSubredditRules(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/r/golang/about/rules.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"rules": [{"short_name": "Be civil", "description": "No insults", "violation_reason": "Incivility", "created_utc": 1600000000.0}]}`))
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, services.NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	handler := NewRedditHandlerForTest(client)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/r/:subreddit/rules", handler.GetSubredditRules)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/r/golang/rules", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Subreddit string                         `json:"subreddit"`
+		Rules     []services.RedditSubredditRule `json:"rules"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "golang", response.Subreddit)
+	require.Len(t, response.Rules, 1)
+	assert.Equal(t, "Be civil", response.Rules[0].ShortName)
+	assert.Equal(t, "Incivility", response.Rules[0].ViolationReason)
+}
@@ -24,12 +24,13 @@ type SavedItemsHandler struct {
 }
 
 type redditPostFetcher interface {
-	GetPostInfo(ctx context.Context, subreddit string, redditPostID string) (*services.RedditPost, error)
+	GetPostsInfo(ctx context.Context, redditPostIDs []string) (map[string]*services.RedditPost, error)
 }
 
 type removedRedditPost struct {
 	Subreddit    string `json:"subreddit"`
 	RedditPostID string `json:"reddit_post_id"`
+	Reason       string `json:"reason,omitempty"`
 }
 
 type saveRedditPostRequest struct {
@@ -124,19 +125,32 @@ func (h *SavedItemsHandler) pruneRemovedRedditPosts(c *gin.Context, userID int,
 	var filtered []*models.SavedRedditPost
 	var removed []removedRedditPost
 
-	for _, post := range posts {
-		isRemoved := isLocallyRemovedRedditPost(post)
-
-		if !isRemoved && h.redditClient != nil {
-			apiPost, err := h.redditClient.GetPostInfo(ctx, post.Subreddit, post.RedditPostID)
+	// Look every remaining post up in one batched call rather than one request each
+	var apiPosts map[string]*services.RedditPost
+	if h.redditClient != nil {
+		ids := make([]string, 0, len(posts))
+		for _, post := range posts {
+			if !isLocallyRemovedRedditPost(post) {
+				ids = append(ids, post.RedditPostID)
+			}
+		}
+		if len(ids) > 0 {
+			var err error
+			apiPosts, err = h.redditClient.GetPostsInfo(ctx, ids)
 			if err != nil {
-				c.Error(fmt.Errorf("failed to fetch reddit post info for %s/%s: %w", post.Subreddit, post.RedditPostID, err))
-			} else if services.IsRedditPostRemoved(apiPost) || apiPost == nil {
-				isRemoved = true
+				c.Error(fmt.Errorf("failed to fetch reddit post info for %d saved posts: %w", len(ids), err))
 			}
 		}
+	}
+
+	for _, post := range posts {
+		reason := localRedditRemovalReason(post)
 
-		if isRemoved {
+		if reason == "" && apiPosts != nil {
+			reason = services.RedditRemovalReason(apiPosts[post.RedditPostID])
+		}
+
+		if reason != "" {
 			if err := h.savedRepo.RemoveRedditPost(ctx, userID, post.Subreddit, post.RedditPostID); err != nil {
 				c.Error(fmt.Errorf("failed to remove stale reddit post %s/%s: %w", post.Subreddit, post.RedditPostID, err))
 				filtered = append(filtered, post)
@@ -145,6 +159,7 @@ func (h *SavedItemsHandler) pruneRemovedRedditPosts(c *gin.Context, userID int,
 			removed = append(removed, removedRedditPost{
 				Subreddit:    post.Subreddit,
 				RedditPostID: post.RedditPostID,
+				Reason:       reason,
 			})
 			continue
 		}
@@ -155,17 +170,21 @@ func (h *SavedItemsHandler) pruneRemovedRedditPosts(c *gin.Context, userID int,
 }
 
 func isLocallyRemovedRedditPost(post *models.SavedRedditPost) bool {
-	title := normalizeSavedText(post.Title)
-	if title == "[removed]" || title == "[deleted]" || strings.Contains(title, "removed by moderator") {
-		return true
-	}
+	return localRedditRemovalReason(post) != ""
+}
 
+// localRedditRemovalReason labels saved posts whose stored title or author
+// already show the removal, using the same labels as services.RedditRemovalReason.
+func localRedditRemovalReason(post *models.SavedRedditPost) string {
+	title := normalizeSavedText(post.Title)
 	author := normalizeSavedText(post.Author)
-	if author == "[deleted]" {
-		return true
+	if title == "[deleted]" || author == "[deleted]" {
+		return services.RedditRemovalDeletedByAuthor
 	}
-
-	return false
+	if title == "[removed]" || strings.Contains(title, "removed by moderator") {
+		return services.RedditRemovalModerator
+	}
+	return ""
 }
 
 func normalizeSavedText(value string) string {
@@ -22,14 +22,14 @@ type fakeRedditClient struct {
 	posts map[string]*services.RedditPost
 }
 
-func (f *fakeRedditClient) GetPostInfo(ctx context.Context, subreddit string, redditPostID string) (*services.RedditPost, error) {
-	if f.posts == nil {
-		return nil, nil
+func (f *fakeRedditClient) GetPostsInfo(ctx context.Context, redditPostIDs []string) (map[string]*services.RedditPost, error) {
+	posts := make(map[string]*services.RedditPost, len(redditPostIDs))
+	for _, id := range redditPostIDs {
+		if post, ok := f.posts[id]; ok {
+			posts[id] = post
+		}
 	}
-	if post, ok := f.posts[redditPostID]; ok {
-		return post, nil
-	}
-	return nil, nil
+	return posts, nil
 }
 
 // setupSavedItemsTest creates a test setup with database and handler
@@ -245,6 +245,7 @@ func TestGetSavedItems_RemovesModeratorDeletedRedditPosts(t *testing.T) {
 	autoRemoved, ok := response["auto_removed_reddit_posts"].([]interface{})
 	require.True(t, ok)
 	assert.Len(t, autoRemoved, 1)
+	assert.Equal(t, services.RedditRemovalModerator, autoRemoved[0].(map[string]interface{})["reason"])
 
 	remaining, err := savedRepo.GetSavedRedditPosts(ctx, userID)
 	require.NoError(t, err)
@@ -1,27 +1,129 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/omninudge/backend/internal/models"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/errgroup"
 )
 
+// unifiedSearchConcurrency bounds how many subsearches SearchAll runs at once
+const unifiedSearchConcurrency = 2
+
+// searchSection runs one subsearch for SearchAll as viewerID (nil when
+// anonymous) and returns its results and their count
+type searchSection func(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit int) (interface{}, int, error)
+
 // SearchHandler handles full-text search requests
 type SearchHandler struct {
-	pool *pgxpool.Pool
+	pool        *pgxpool.Pool
+	postRepo    *models.PlatformPostRepository
+	commentRepo *models.PostCommentRepository
+
+	// Subsearches run by SearchAll, keyed by response field
+	sections map[string]searchSection
 }
 
 // NewSearchHandler creates a new search handler
 func NewSearchHandler(pool *pgxpool.Pool) *SearchHandler {
-	return &SearchHandler{pool: pool}
+	h := &SearchHandler{
+		pool:        pool,
+		postRepo:    models.NewPlatformPostRepository(pool),
+		commentRepo: models.NewPostCommentRepository(pool),
+	}
+	h.sections = map[string]searchSection{
+		"posts": func(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit int) (interface{}, int, error) {
+			posts, err := h.postRepo.Search(ctx, query, "relevance", models.PostSearchFilters{IncludeNSFW: includeNSFW, ViewerID: viewerID}, limit, 0)
+			return posts, len(posts), err
+		},
+		"comments": func(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit int) (interface{}, int, error) {
+			comments, err := h.commentRepo.Search(ctx, query, viewerID, includeNSFW, limit, 0)
+			return comments, len(comments), err
+		},
+		"users": func(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit int) (interface{}, int, error) {
+			users, err := h.searchUsers(ctx, query, "relevance", includeNSFW, limit, 0)
+			return users, len(users), err
+		},
+		"hubs": func(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit int) (interface{}, int, error) {
+			hubs, err := h.searchHubs(ctx, query, "relevance", includeNSFW, limit, 0)
+			return hubs, len(hubs), err
+		},
+	}
+	return h
+}
+
+// SearchAll searches posts, comments, users and hubs in one request
+// GET /api/v1/search/all?q=query&limit=5
+// Each section returns up to limit results. A failing section comes back empty
+// with a note under "errors" instead of failing the whole response.
+func (h *SearchHandler) SearchAll(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Search query is required"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "5"))
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
+
+	if limit < 1 || limit > 25 {
+		limit = 5
+	}
+
+	type sectionResult struct {
+		results interface{}
+		count   int
+		err     error
+	}
+
+	ctx := c.Request.Context()
+	viewerID := optionalViewerID(c)
+	results := make(map[string]*sectionResult, len(h.sections))
+	var g errgroup.Group
+	g.SetLimit(unifiedSearchConcurrency)
+	for name, search := range h.sections {
+		result := &sectionResult{}
+		results[name] = result
+		g.Go(func() error {
+			// Errors stay with their section so the other searches still complete
+			result.results, result.count, result.err = search(ctx, query, viewerID, includeNSFW, limit)
+			return nil
+		})
+	}
+	g.Wait()
+
+	response := gin.H{"query": query, "limit": limit}
+	counts := gin.H{}
+	errs := gin.H{}
+	for name, result := range results {
+		if result.err != nil {
+			log.Printf("Unified search: %s search failed: %v", name, result.err)
+			errs[name] = "Search failed"
+			result.count = 0
+		}
+		if result.count == 0 {
+			result.results = []interface{}{}
+		}
+		response[name] = result.results
+		counts[name] = result.count
+	}
+	response["counts"] = counts
+	if len(errs) > 0 {
+		response["errors"] = errs
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // SearchPosts searches posts using full-text search
-// GET /api/v1/search/posts?q=query&limit=20&offset=0
+// GET /api/v1/search/posts?q=query&sort=relevance|new|old|top&hub=&author=&after=&before=&limit=20&offset=0
 func (h *SearchHandler) SearchPosts(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
@@ -32,38 +134,29 @@ func (h *SearchHandler) SearchPosts(c *gin.Context) {
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
 	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
-	sort := strings.ToLower(c.DefaultQuery("sort", "relevance")) // relevance | new | old
+	sort := strings.ToLower(c.DefaultQuery("sort", "relevance")) // unknown values fall back to relevance
 
-	if limit < 1 || limit > 100 {
-		limit = 20
+	filters := models.PostSearchFilters{
+		HubName:        c.Query("hub"),
+		AuthorUsername: c.Query("author"),
+		IncludeNSFW:    includeNSFW,
+		ViewerID:       optionalViewerID(c),
 	}
-
-	orderClause := `
-		ORDER BY rank DESC, created_at DESC
-	`
-	if sort == "new" {
-		orderClause = `
-		ORDER BY created_at DESC, rank DESC
-		`
-	} else if sort == "old" {
-		orderClause = `
-		ORDER BY created_at ASC, rank DESC
-		`
+	var err error
+	if filters.After, err = parseOptionalTime(c.Query("after")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "after must be an RFC3339 timestamp"})
+		return
+	}
+	if filters.Before, err = parseOptionalTime(c.Query("before")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "before must be an RFC3339 timestamp"})
+		return
 	}
 
-	sql := `
-		SELECT id, author_id, hub_id, title, body, tags, score, upvotes, downvotes,
-		       num_comments, view_count, created_at,
-		       ts_rank(search_vector, plainto_tsquery('english', $1)) as rank
-		FROM platform_posts
-		WHERE search_vector @@ plainto_tsquery('english', $1)
-		AND is_deleted = FALSE
-		AND (nsfw = FALSE OR $4 = TRUE)
-	` + orderClause + `
-		LIMIT $2 OFFSET $3
-	`
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
 
-	rows, err := h.pool.Query(c.Request.Context(), sql, query, limit, offset, includeNSFW)
+	posts, err := h.postRepo.Search(c.Request.Context(), query, sort, filters, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Search failed",
@@ -71,23 +164,6 @@ func (h *SearchHandler) SearchPosts(c *gin.Context) {
 		})
 		return
 	}
-	defer rows.Close()
-
-	var posts []*models.PlatformPost
-	for rows.Next() {
-		post := &models.PlatformPost{}
-		var rank float64
-		err := rows.Scan(
-			&post.ID, &post.AuthorID, &post.HubID, &post.Title, &post.Body, &post.Tags,
-			&post.Score, &post.Upvotes, &post.Downvotes, &post.NumComments, &post.ViewCount,
-			&post.CreatedAt, &rank,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse results"})
-			return
-		}
-		posts = append(posts, post)
-	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"posts":  posts,
@@ -97,8 +173,31 @@ func (h *SearchHandler) SearchPosts(c *gin.Context) {
 	})
 }
 
+// optionalViewerID returns the signed-in user, or nil when anonymous
+func optionalViewerID(c *gin.Context) *int {
+	if uid, ok := c.Get("user_id"); ok {
+		if id, ok := uid.(int); ok {
+			return &id
+		}
+	}
+	return nil
+}
+
+// parseOptionalTime parses an RFC3339 query value; empty means no bound
+func parseOptionalTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	parsed = parsed.UTC()
+	return &parsed, nil
+}
+
 // SearchComments searches comments using full-text search
-// GET /api/v1/search/comments?q=query&limit=20&offset=0
+// GET /api/v1/search/comments?q=query&limit=20&offset=0&include_nsfw=false
 func (h *SearchHandler) SearchComments(c *gin.Context) {
 	query := c.Query("q")
 	if query == "" {
@@ -108,44 +207,17 @@ func (h *SearchHandler) SearchComments(c *gin.Context) {
 
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	includeNSFW, _ := strconv.ParseBool(c.DefaultQuery("include_nsfw", "false"))
 
 	if limit < 1 || limit > 100 {
 		limit = 20
 	}
 
-	sql := `
-		SELECT id, post_id, user_id, parent_comment_id, body, depth, score,
-		       upvotes, downvotes, created_at,
-		       ts_rank(search_vector, plainto_tsquery('english', $1)) as rank
-		FROM post_comments
-		WHERE search_vector @@ plainto_tsquery('english', $1)
-		AND is_deleted = FALSE
-		ORDER BY rank DESC, created_at DESC
-		LIMIT $2 OFFSET $3
-	`
-
-	rows, err := h.pool.Query(c.Request.Context(), sql, query, limit, offset)
+	comments, err := h.commentRepo.Search(c.Request.Context(), query, optionalViewerID(c), includeNSFW, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed", "details": err.Error()})
 		return
 	}
-	defer rows.Close()
-
-	var comments []*models.PostComment
-	for rows.Next() {
-		comment := &models.PostComment{}
-		var rank float64
-		err := rows.Scan(
-			&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID,
-			&comment.Body, &comment.Depth, &comment.Score, &comment.Upvotes, &comment.Downvotes,
-			&comment.CreatedAt, &rank,
-		)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse results"})
-			return
-		}
-		comments = append(comments, comment)
-	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"comments": comments,
@@ -173,33 +245,34 @@ func (h *SearchHandler) SearchUsers(c *gin.Context) {
 		limit = 20
 	}
 
-	orderClause := `
-		ORDER BY rank DESC, created_at DESC
-	`
-	if sort == "new" {
-		orderClause = `
-		ORDER BY created_at DESC, rank DESC
-		`
-	} else if sort == "old" {
-		orderClause = `
-		ORDER BY created_at ASC, rank DESC
-		`
+	users, err := h.searchUsers(c.Request.Context(), query, sort, includeNSFW, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed", "details": err.Error()})
+		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"users":  users,
+		"limit":  limit,
+		"offset": offset,
+		"query":  query,
+	})
+}
+
+func (h *SearchHandler) searchUsers(ctx context.Context, query, sort string, includeNSFW bool, limit, offset int) ([]*models.User, error) {
 	sql := `
 		SELECT id, username, bio, avatar_url, karma, created_at,
 		       ts_rank(search_vector, plainto_tsquery('english', $1)) as rank
 		FROM users
 		WHERE search_vector @@ plainto_tsquery('english', $1)
 		AND (nsfw = FALSE OR $4 = TRUE)
-	` + orderClause + `
+	` + searchOrderClause(sort) + `
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := h.pool.Query(c.Request.Context(), sql, query, limit, offset, includeNSFW)
+	rows, err := h.pool.Query(ctx, sql, query, limit, offset, includeNSFW)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed", "details": err.Error()})
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -212,18 +285,12 @@ func (h *SearchHandler) SearchUsers(c *gin.Context) {
 			&user.CreatedAt, &rank,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse results"})
-			return
+			return nil, err
 		}
 		users = append(users, user)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"users":  users,
-		"limit":  limit,
-		"offset": offset,
-		"query":  query,
-	})
+	return users, rows.Err()
 }
 
 // SearchHubs searches hubs using full-text search
@@ -244,33 +311,34 @@ func (h *SearchHandler) SearchHubs(c *gin.Context) {
 		limit = 20
 	}
 
-	orderClause := `
-		ORDER BY rank DESC, created_at DESC
-	`
-	if sort == "new" {
-		orderClause = `
-		ORDER BY created_at DESC, rank DESC
-		`
-	} else if sort == "old" {
-		orderClause = `
-		ORDER BY created_at ASC, rank DESC
-		`
+	hubs, err := h.searchHubs(c.Request.Context(), query, sort, includeNSFW, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed", "details": err.Error()})
+		return
 	}
 
+	c.JSON(http.StatusOK, gin.H{
+		"hubs":   hubs,
+		"limit":  limit,
+		"offset": offset,
+		"query":  query,
+	})
+}
+
+func (h *SearchHandler) searchHubs(ctx context.Context, query, sort string, includeNSFW bool, limit, offset int) ([]*models.Hub, error) {
 	sql := `
 		SELECT id, name, description, title, type, content_options, is_quarantined, subscriber_count, created_by, created_at,
 		       ts_rank(search_vector, plainto_tsquery('english', $1)) as rank
 		FROM hubs
 		WHERE search_vector @@ plainto_tsquery('english', $1)
 		AND (nsfw = FALSE OR $4 = TRUE)
-	` + orderClause + `
+	` + searchOrderClause(sort) + `
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := h.pool.Query(c.Request.Context(), sql, query, limit, offset, includeNSFW)
+	rows, err := h.pool.Query(ctx, sql, query, limit, offset, includeNSFW)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Search failed", "details": err.Error()})
-		return
+		return nil, err
 	}
 	defer rows.Close()
 
@@ -279,20 +347,32 @@ func (h *SearchHandler) SearchHubs(c *gin.Context) {
 		hub := &models.Hub{}
 		var rank float64
 		err := rows.Scan(
-			&hub.ID, &hub.Name, &hub.Description, &hub.CreatedBy,
-			&hub.CreatedAt, &rank,
+			&hub.ID, &hub.Name, &hub.Description, &hub.Title, &hub.Type, &hub.ContentOptions,
+			&hub.IsQuarantined, &hub.SubscriberCount, &hub.CreatedBy, &hub.CreatedAt, &rank,
 		)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse results"})
-			return
+			return nil, err
 		}
 		hubs = append(hubs, hub)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"hubs":   hubs,
-		"limit":  limit,
-		"offset": offset,
-		"query":  query,
-	})
+	return hubs, rows.Err()
+}
+
+// searchOrderClause orders user and hub results: relevance (default), new or old
+func searchOrderClause(sort string) string {
+	switch sort {
+	case "new":
+		return `
+		ORDER BY created_at DESC, rank DESC
+		`
+	case "old":
+		return `
+		ORDER BY created_at ASC, rank DESC
+		`
+	default:
+		return `
+		ORDER BY rank DESC, created_at DESC
+		`
+	}
 }
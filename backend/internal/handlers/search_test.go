@@ -238,6 +238,241 @@ func TestSearchHubs(t *testing.T) {
 	assert.GreaterOrEqual(t, len(hubs), 1, "Should find hub with AI in description")
 }
 
+func TestSearchPosts_Sort(t *testing.T) {
+	handler, db, cleanup := setupSearchHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := models.NewUserRepository(db.Pool)
+	user := &models.User{
+		Username:     uniqueSearchName("author"),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	// A token no other test uses, so only these posts match
+	term := fmt.Sprintf("rankterm%d%d", searchTestSuffix, atomic.AddInt64(&searchTestCounter, 1))
+
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	createPost := func(title, body string, age time.Duration, score int) int {
+		post := &models.PlatformPost{AuthorID: user.ID, Title: title, Body: &body}
+		require.NoError(t, postRepo.Create(ctx, post))
+		require.NoError(t, postRepo.UpdateCreatedAt(ctx, post.ID, time.Now().Add(-age)))
+		_, err := db.Pool.Exec(ctx, `UPDATE platform_posts SET score = $1 WHERE id = $2`, score, post.ID)
+		require.NoError(t, err)
+		return post.ID
+	}
+
+	// The year-old post matches in both title and body, so it has the higher
+	// raw rank; the fresh one only matches in the body
+	oldID := createPost("All about "+term, "Everything on "+term+" and more "+term, 365*24*time.Hour, 100)
+	midID := createPost("Monthly roundup", "Some notes on "+term, 30*24*time.Hour, 50)
+	newID := createPost("Fresh thoughts", "A quick take on "+term, time.Minute, 5)
+
+	router := gin.Default()
+	router.GET("/search/posts", handler.SearchPosts)
+
+	search := func(sort string) []int {
+		url := "/search/posts?q=" + term
+		if sort != "" {
+			url += "&sort=" + sort
+		}
+		req := httptest.NewRequest("GET", url, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+
+		var response struct {
+			Posts []models.PlatformPost `json:"posts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := make([]int, 0, len(response.Posts))
+		for _, p := range response.Posts {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	relevance := search("relevance")
+	require.Len(t, relevance, 3)
+	assert.Equal(t, newID, relevance[0], "recency weighting should lift the fresh post above the old one")
+	assert.Equal(t, relevance, search(""), "relevance is the default sort")
+
+	assert.Equal(t, []int{newID, midID, oldID}, search("new"))
+	assert.Equal(t, []int{oldID, midID, newID}, search("old"))
+	assert.Equal(t, []int{oldID, midID, newID}, search("top"))
+
+	assert.Equal(t, relevance, search("random"), "unknown sorts fall back to relevance")
+}
+
+func TestSearchPosts_Filters(t *testing.T) {
+	handler, db, cleanup := setupSearchHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+
+	userRepo := models.NewUserRepository(db.Pool)
+	alice := &models.User{Username: uniqueSearchName("alice"), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	bob := &models.User{Username: uniqueSearchName("bob"), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, bob))
+
+	hubRepo := models.NewHubRepository(db.Pool)
+	golangHub := &models.Hub{Name: uniqueSearchName("golang"), CreatedBy: &alice.ID}
+	require.NoError(t, hubRepo.Create(ctx, golangHub))
+	rustHub := &models.Hub{Name: uniqueSearchName("rust"), CreatedBy: &alice.ID}
+	require.NoError(t, hubRepo.Create(ctx, rustHub))
+
+	term := fmt.Sprintf("filterterm%d%d", searchTestSuffix, atomic.AddInt64(&searchTestCounter, 1))
+
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	createPost := func(author *models.User, hub *models.Hub, createdAt time.Time) int {
+		body := "Discussing " + term
+		post := &models.PlatformPost{AuthorID: author.ID, HubID: &hub.ID, Title: "Post", Body: &body}
+		require.NoError(t, postRepo.Create(ctx, post))
+		require.NoError(t, postRepo.UpdateCreatedAt(ctx, post.ID, createdAt))
+		return post.ID
+	}
+
+	jan := time.Date(2025, 1, 15, 12, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 15, 12, 0, 0, 0, time.UTC)
+	aliceGolangJan := createPost(alice, golangHub, jan)
+	bobGolangMar := createPost(bob, golangHub, mar)
+	aliceRustMar := createPost(alice, rustHub, mar)
+
+	router := gin.Default()
+	router.GET("/search/posts", handler.SearchPosts)
+
+	search := func(params string) (int, []int) {
+		req := httptest.NewRequest("GET", "/search/posts?sort=new&q="+term+params, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+
+		var response struct {
+			Posts []models.PlatformPost `json:"posts"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := []int{}
+		for _, p := range response.Posts {
+			ids = append(ids, p.ID)
+		}
+		return w.Code, ids
+	}
+
+	_, ids := search("")
+	assert.ElementsMatch(t, []int{aliceGolangJan, bobGolangMar, aliceRustMar}, ids)
+
+	_, ids = search("&hub=" + golangHub.Name)
+	assert.ElementsMatch(t, []int{aliceGolangJan, bobGolangMar}, ids, "only the golang hub's matches")
+
+	_, ids = search("&hub=" + golangHub.Name + "&author=" + alice.Username)
+	assert.Equal(t, []int{aliceGolangJan}, ids)
+
+	_, ids = search("&after=2025-02-01T00:00:00Z")
+	assert.ElementsMatch(t, []int{bobGolangMar, aliceRustMar}, ids)
+
+	_, ids = search("&before=2025-02-01T00:00:00Z")
+	assert.Equal(t, []int{aliceGolangJan}, ids)
+
+	code, ids := search("&hub=" + uniqueSearchName("missing"))
+	assert.Equal(t, http.StatusOK, code, "an unknown hub is not an error")
+	assert.Empty(t, ids)
+
+	code, ids = search("&author=" + uniqueSearchName("nobody"))
+	assert.Equal(t, http.StatusOK, code, "an unknown author is not an error")
+	assert.Empty(t, ids)
+
+	code, _ = search("&after=2025-02-01")
+	assert.Equal(t, http.StatusBadRequest, code)
+	code, _ = search("&before=yesterday")
+	assert.Equal(t, http.StatusBadRequest, code)
+}
+
+func TestSearchAll(t *testing.T) {
+	handler, db, cleanup := setupSearchHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	term := fmt.Sprintf("unifiedterm%d%d", searchTestSuffix, atomic.AddInt64(&searchTestCounter, 1))
+
+	// One user, hub, post and comment, each matching the term
+	userRepo := models.NewUserRepository(db.Pool)
+	bio := "I write about " + term
+	user := &models.User{Username: uniqueSearchName("author"), PasswordHash: "test_hash", Bio: &bio}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hubRepo := models.NewHubRepository(db.Pool)
+	description := "Everything " + term
+	hub := &models.Hub{Name: uniqueSearchName("hub"), Description: &description, CreatedBy: &user.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	body := "A post on " + term
+	post := &models.PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "Post", Body: &body}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+	comment := &models.PostComment{PostID: post.ID, UserID: user.ID, Body: "A comment on " + term}
+	require.NoError(t, commentRepo.Create(ctx, comment))
+
+	router := gin.Default()
+	router.GET("/search/all", handler.SearchAll)
+
+	type unifiedResponse struct {
+		Posts    []models.PlatformPost `json:"posts"`
+		Comments []models.PostComment  `json:"comments"`
+		Users    []models.User         `json:"users"`
+		Hubs     []models.Hub          `json:"hubs"`
+		Counts   map[string]int        `json:"counts"`
+		Errors   map[string]string     `json:"errors"`
+	}
+	search := func() unifiedResponse {
+		req := httptest.NewRequest("GET", "/search/all?q="+term, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+
+		var response unifiedResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	t.Run("all sections populate", func(t *testing.T) {
+		response := search()
+		assert.Empty(t, response.Errors)
+
+		require.Len(t, response.Posts, 1)
+		assert.Equal(t, post.ID, response.Posts[0].ID)
+		require.Len(t, response.Comments, 1)
+		assert.Equal(t, comment.ID, response.Comments[0].ID)
+		require.Len(t, response.Users, 1)
+		assert.Equal(t, user.ID, response.Users[0].ID)
+		require.Len(t, response.Hubs, 1)
+		assert.Equal(t, hub.ID, response.Hubs[0].ID)
+
+		assert.Equal(t, map[string]int{"posts": 1, "comments": 1, "users": 1, "hubs": 1}, response.Counts)
+	})
+
+	t.Run("failing section leaves the others", func(t *testing.T) {
+		handler.sections["comments"] = func(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit int) (interface{}, int, error) {
+			return nil, 0, fmt.Errorf("forced comment search failure")
+		}
+
+		response := search()
+		assert.Equal(t, map[string]string{"comments": "Search failed"}, response.Errors)
+		assert.NotNil(t, response.Comments, "a failed section is an empty list, not null")
+		assert.Empty(t, response.Comments)
+		assert.Len(t, response.Posts, 1)
+		assert.Len(t, response.Users, 1)
+		assert.Len(t, response.Hubs, 1)
+		assert.Equal(t, map[string]int{"posts": 1, "comments": 0, "users": 1, "hubs": 1}, response.Counts)
+	})
+}
+
 func TestSearchMissingQuery(t *testing.T) {
 	handler, _, cleanup := setupSearchHandlerTest(t)
 	defer cleanup()
@@ -308,3 +543,68 @@ func TestSearchPagination(t *testing.T) {
 	assert.Equal(t, 2, limit)
 	assert.Equal(t, 0, offset)
 }
+
+func TestSearch_HidesPostsViewerCantSee(t *testing.T) {
+	handler, db, cleanup := setupSearchHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+
+	member := &models.User{Username: uniqueSearchName("member"), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, member))
+	stranger := &models.User{Username: uniqueSearchName("stranger"), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, stranger))
+
+	privateHub := &models.Hub{Name: uniqueSearchName("private_hub"), Type: "private", CreatedBy: &member.ID}
+	require.NoError(t, hubRepo.Create(ctx, privateHub))
+	publicHub := &models.Hub{Name: uniqueSearchName("public_hub"), CreatedBy: &member.ID}
+	require.NoError(t, hubRepo.Create(ctx, publicHub))
+
+	term := fmt.Sprintf("quokka%d", searchTestSuffix)
+	privatePost := &models.PlatformPost{AuthorID: member.ID, HubID: &privateHub.ID, Title: "Private " + term}
+	require.NoError(t, postRepo.Create(ctx, privatePost))
+	removedPost := &models.PlatformPost{AuthorID: member.ID, HubID: &publicHub.ID, Title: "Removed " + term}
+	require.NoError(t, postRepo.Create(ctx, removedPost))
+	require.NoError(t, postRepo.MarkAsRemoved(ctx, removedPost.ID, member.ID))
+	require.NoError(t, commentRepo.Create(ctx, &models.PostComment{PostID: privatePost.ID, UserID: member.ID, Body: "Secret " + term}))
+
+	search := func(userID int, path string) map[string]interface{} {
+		router := gin.New()
+		if userID != 0 {
+			router.Use(authMiddleware(userID))
+		}
+		router.GET("/search/posts", handler.SearchPosts)
+		router.GET("/search/comments", handler.SearchComments)
+		router.GET("/search/all", handler.SearchAll)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code, "body=%s", w.Body.String())
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+	count := func(response map[string]interface{}, key string) int {
+		results, _ := response[key].([]interface{})
+		return len(results)
+	}
+
+	// The member finds the private post and its comment, never the removed post
+	assert.Equal(t, 1, count(search(member.ID, "/search/posts?q="+term), "posts"))
+	assert.Equal(t, 1, count(search(member.ID, "/search/posts?q="+term+"&hub="+privateHub.Name), "posts"))
+	assert.Equal(t, 1, count(search(member.ID, "/search/comments?q="+term), "comments"))
+
+	for _, userID := range []int{stranger.ID, 0} {
+		assert.Equal(t, 0, count(search(userID, "/search/posts?q="+term), "posts"))
+		assert.Equal(t, 0, count(search(userID, "/search/posts?q="+term+"&hub="+privateHub.Name), "posts"))
+		assert.Equal(t, 0, count(search(userID, "/search/comments?q="+term), "comments"))
+
+		all := search(userID, "/search/all?q="+term)
+		assert.Equal(t, 0, count(all, "posts"))
+		assert.Equal(t, 0, count(all, "comments"))
+	}
+}
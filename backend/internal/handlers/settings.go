@@ -50,6 +50,15 @@ type updateSettingsRequest struct {
 	NotifyCommentMilestone *bool `json:"notify_comment_milestone"`
 	NotifyCommentVelocity  *bool `json:"notify_comment_velocity"`
 	DailyDigest            *bool `json:"daily_digest"`
+	NotifyReportUpdates    *bool `json:"notify_report_updates"`
+	NotifyContentRemoved   *bool `json:"notify_content_removed"`
+
+	// immediate, hourly or daily
+	NotificationCadence *string `json:"notification_cadence"`
+
+	// Quiet hours for digest emails, as UTC hours 0-23; -1 clears them
+	QuietHoursStart *int `json:"quiet_hours_start"`
+	QuietHoursEnd   *int `json:"quiet_hours_end"`
 }
 
 // UpdateSettings updates the current user's settings.
@@ -121,6 +130,41 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 	if req.DailyDigest != nil {
 		settings.DailyDigest = *req.DailyDigest
 	}
+	if req.NotifyReportUpdates != nil {
+		settings.NotifyReportUpdates = *req.NotifyReportUpdates
+	}
+	if req.NotifyContentRemoved != nil {
+		settings.NotifyContentRemoved = *req.NotifyContentRemoved
+	}
+	if req.NotificationCadence != nil {
+		cadence := strings.ToLower(strings.TrimSpace(*req.NotificationCadence))
+		if !models.ValidNotificationCadence(cadence) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Notification cadence must be 'immediate', 'hourly', or 'daily'"})
+			return
+		}
+		settings.NotificationCadence = cadence
+	}
+	if req.QuietHoursStart != nil || req.QuietHoursEnd != nil {
+		start, end := settings.QuietHoursStart, settings.QuietHoursEnd
+		if req.QuietHoursStart != nil {
+			start = req.QuietHoursStart
+		}
+		if req.QuietHoursEnd != nil {
+			end = req.QuietHoursEnd
+		}
+		if (start != nil && *start == -1) || (end != nil && *end == -1) {
+			start, end = nil, nil
+		}
+		if (start == nil) != (end == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quiet_hours_start and quiet_hours_end must be set together"})
+			return
+		}
+		if start != nil && (*start < 0 || *start > 23 || *end < 0 || *end > 23) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Quiet hours must be between 0 and 23"})
+			return
+		}
+		settings.QuietHoursStart, settings.QuietHoursEnd = start, end
+	}
 
 	updated, err := h.settingsRepo.Update(c.Request.Context(), settings)
 	if err != nil {
@@ -131,6 +175,56 @@ func (h *SettingsHandler) UpdateSettings(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// GetNotificationPreferences returns which notification categories the user receives.
+// GET /api/v1/notifications/preferences
+func (h *SettingsHandler) GetNotificationPreferences(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	prefs, err := h.settingsRepo.GetNotificationPreferences(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
+// UpdateNotificationPreferences turns notification categories on or off. Categories
+// left out of the body keep their current value.
+// PUT /api/v1/notifications/preferences
+func (h *SettingsHandler) UpdateNotificationPreferences(c *gin.Context) {
+	userID, ok := h.getUserID(c)
+	if !ok {
+		return
+	}
+
+	var changes models.NotificationPreferences
+	if err := c.ShouldBindJSON(&changes); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Preferences must map categories to true or false"})
+		return
+	}
+	for category := range changes {
+		if !models.ValidNotificationCategory(category) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":      "Unknown notification category: " + category,
+				"categories": models.NotificationCategories,
+			})
+			return
+		}
+	}
+
+	prefs, err := h.settingsRepo.UpdateNotificationPreferences(c.Request.Context(), userID, changes)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification preferences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": prefs})
+}
+
 func (h *SettingsHandler) getUserID(c *gin.Context) (int, bool) {
 	userIDValue, exists := c.Get("user_id")
 	if !exists {
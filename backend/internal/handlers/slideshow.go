@@ -1,10 +1,14 @@
 package handlers
 
 import (
+	"context"
+	"log"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
 	"github.com/omninudge/backend/internal/websocket"
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5"
@@ -17,6 +21,7 @@ type SlideshowHandler struct {
 	slideshowRepo *models.SlideshowRepository
 	conversationRepo *models.ConversationRepository
 	hub          *websocket.Hub
+	autoAdvance  *services.SlideshowService
 }
 
 // NewSlideshowHandler creates a new slideshow handler
@@ -26,12 +31,43 @@ func NewSlideshowHandler(
 	conversationRepo *models.ConversationRepository,
 	hub *websocket.Hub,
 ) *SlideshowHandler {
-	return &SlideshowHandler{
+	h := &SlideshowHandler{
 		pool:         pool,
 		slideshowRepo: slideshowRepo,
 		conversationRepo: conversationRepo,
 		hub:          hub,
+		autoAdvance:  services.NewSlideshowService(slideshowRepo, hub),
 	}
+	hub.OnSlideshowViewerLeft(h.viewerDisappeared)
+	return h
+}
+
+// viewerDisappeared tells the controller about a viewer whose heartbeat timed
+// out or whose last connection closed
+func (h *SlideshowHandler) viewerDisappeared(slideshowID, userID int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := h.slideshowRepo.GetByID(ctx, slideshowID)
+	if err != nil {
+		log.Printf("Failed to load slideshow %d to report viewer %d leaving: %v", slideshowID, userID, err)
+		return
+	}
+	if session != nil {
+		h.broadcastViewerLeft(session, userID)
+	}
+}
+
+// broadcastViewerLeft sends slideshow_viewer_left to the controller, unless
+// the controller is the one who left
+func (h *SlideshowHandler) broadcastViewerLeft(session *models.SlideshowSession, userID int) {
+	if session.ControllerUserID == userID {
+		return
+	}
+	h.hub.BroadcastToUsers([]int{session.ControllerUserID}, "slideshow_viewer_left", gin.H{
+		"slideshow_id": session.ID,
+		"user_id":      userID,
+	})
 }
 
 // StartSlideshow handles POST /api/v1/conversations/:id/slideshow
@@ -157,6 +193,10 @@ func (h *SlideshowHandler) StartSlideshow(c *gin.Context) {
 		otherUserID = conversation.User2ID
 	}
 
+	if session.AutoAdvance {
+		h.autoAdvance.StartAutoAdvance(session.ID, []int{userID, otherUserID}, autoAdvanceInterval(session.AutoAdvanceInterval))
+	}
+
 	h.hub.BroadcastToUsers([]int{userID, otherUserID}, "slideshow_started", gin.H{
 		"conversation_id":        conversationID,
 		"slideshow_id":           session.ID,
@@ -365,6 +405,13 @@ func (h *SlideshowHandler) UpdateAutoAdvance(c *gin.Context) {
 		return
 	}
 
+	// The server drives auto-advance so both participants stay on the same slide
+	if req.AutoAdvance {
+		h.autoAdvance.StartAutoAdvance(sessionID, []int{conversation.User1ID, conversation.User2ID}, autoAdvanceInterval(req.AutoAdvanceInterval))
+	} else {
+		h.autoAdvance.StopAutoAdvance(sessionID)
+	}
+
 	// Broadcast auto_advance_updated event
 	h.hub.BroadcastToUsers([]int{conversation.User1ID, conversation.User2ID}, "slideshow_auto_advance_updated", gin.H{
 		"slideshow_id":          sessionID,
@@ -418,6 +465,9 @@ func (h *SlideshowHandler) StopSlideshow(c *gin.Context) {
 		return
 	}
 
+	h.autoAdvance.StopAutoAdvance(sessionID)
+	h.hub.ClearSlideshowViewers(sessionID)
+
 	// Broadcast slideshow_stopped event
 	h.hub.BroadcastToUsers([]int{conversation.User1ID, conversation.User2ID}, "slideshow_stopped", gin.H{
 		"slideshow_id": sessionID,
@@ -465,3 +515,95 @@ func (h *SlideshowHandler) GetSlideshow(c *gin.Context) {
 
 	c.JSON(http.StatusOK, session)
 }
+
+// JoinSlideshow handles POST /api/v1/slideshows/:id/join
+// Clients call it when they open the slideshow and then periodically as a
+// heartbeat; the controller is told when a new viewer arrives.
+func (h *SlideshowHandler) JoinSlideshow(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	session, conversation, ok := h.participantSession(c, userID)
+	if !ok {
+		return
+	}
+
+	// Auto-advance pauses while nobody is watching; resume it for this viewer
+	if session.AutoAdvance {
+		h.autoAdvance.EnsureAutoAdvance(session.ID, []int{conversation.User1ID, conversation.User2ID}, autoAdvanceInterval(session.AutoAdvanceInterval))
+	}
+
+	if h.hub.JoinSlideshow(session.ID, userID) && session.ControllerUserID != userID {
+		h.hub.BroadcastToUsers([]int{session.ControllerUserID}, "slideshow_viewer_joined", gin.H{
+			"slideshow_id": session.ID,
+			"user_id":      userID,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"viewers": h.hub.SlideshowViewers(session.ID)})
+}
+
+// LeaveSlideshow handles POST /api/v1/slideshows/:id/leave
+func (h *SlideshowHandler) LeaveSlideshow(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	session, _, ok := h.participantSession(c, userID)
+	if !ok {
+		return
+	}
+
+	if h.hub.LeaveSlideshow(session.ID, userID) {
+		h.broadcastViewerLeft(session, userID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"viewers": h.hub.SlideshowViewers(session.ID)})
+}
+
+// GetViewers handles GET /api/v1/slideshows/:id/viewers
+func (h *SlideshowHandler) GetViewers(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	session, _, ok := h.participantSession(c, userID)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"viewers": h.hub.SlideshowViewers(session.ID)})
+}
+
+// participantSession loads the slideshow named by :id and checks the user is
+// part of its conversation, writing the error response if not
+func (h *SlideshowHandler) participantSession(c *gin.Context, userID int) (*models.SlideshowSession, *models.Conversation, bool) {
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid slideshow ID"})
+		return nil, nil, false
+	}
+
+	session, err := h.slideshowRepo.GetByID(c.Request.Context(), sessionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Slideshow not found"})
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch slideshow"})
+		return nil, nil, false
+	}
+	if session == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Slideshow not found"})
+		return nil, nil, false
+	}
+
+	conversation, err := h.conversationRepo.GetByID(c.Request.Context(), session.ConversationID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversation"})
+		return nil, nil, false
+	}
+	if conversation.User1ID != userID && conversation.User2ID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not part of this conversation"})
+		return nil, nil, false
+	}
+
+	return session, conversation, true
+}
+
+// autoAdvanceInterval converts a stored auto-advance interval in seconds
+func autoAdvanceInterval(seconds int) time.Duration {
+	return time.Duration(seconds) * time.Second
+}
@@ -416,6 +416,89 @@ func TestStopSlideshow(t *testing.T) {
 	assert.Nil(t, deletedSession)
 }
 
+func TestSlideshowViewers_JoinAndLeave(t *testing.T) {
+	handler, db, controllerID, viewerID, convID, cleanup := setupSlideshowHandlerTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	slideshowRepo := models.NewSlideshowRepository(db.Pool)
+	session := &models.SlideshowSession{
+		ConversationID:      convID,
+		SlideshowType:       "reddit",
+		Subreddit:           strPtr("pics"),
+		TotalItems:          10,
+		ControllerUserID:    controllerID,
+		AutoAdvanceInterval: 5,
+	}
+	require.NoError(t, slideshowRepo.CreateSession(ctx, session))
+
+	// Connect the controller so it receives viewer events
+	go handler.hub.Run()
+	controller := &websocket.Client{Hub: handler.hub, Send: make(chan *websocket.Message, 16), UserID: controllerID}
+	handler.hub.Register(controller)
+
+	do := func(userID int, method, action string) []websocket.SlideshowViewer {
+		router := gin.Default()
+		withUser := func(c *gin.Context) { c.Set("user_id", userID) }
+		router.POST("/slideshows/:id/join", withUser, handler.JoinSlideshow)
+		router.POST("/slideshows/:id/leave", withUser, handler.LeaveSlideshow)
+		router.GET("/slideshows/:id/viewers", withUser, handler.GetViewers)
+
+		req := httptest.NewRequest(method, fmt.Sprintf("/slideshows/%d/%s", session.ID, action), nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, "Response body: %s", w.Body.String())
+
+		var response struct {
+			Viewers []websocket.SlideshowViewer `json:"viewers"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Viewers
+	}
+	viewerIDs := func(viewers []websocket.SlideshowViewer) []int {
+		ids := make([]int, 0, len(viewers))
+		for _, v := range viewers {
+			ids = append(ids, v.UserID)
+		}
+		return ids
+	}
+	expectEvent := func(msgType string, userID int) {
+		select {
+		case msg := <-controller.Send:
+			assert.Equal(t, msgType, msg.Type)
+			payload := msg.Payload.(gin.H)
+			assert.Equal(t, session.ID, payload["slideshow_id"])
+			assert.Equal(t, userID, payload["user_id"])
+		case <-time.After(time.Second):
+			t.Fatalf("controller did not receive %s", msgType)
+		}
+	}
+
+	// Both participants join; only the other viewer's arrival is announced
+	do(viewerID, "POST", "join")
+	expectEvent("slideshow_viewer_joined", viewerID)
+	do(controllerID, "POST", "join")
+
+	// A repeated join is a heartbeat, not a new arrival
+	do(viewerID, "POST", "join")
+
+	viewers := do(controllerID, "GET", "viewers")
+	assert.ElementsMatch(t, []int{controllerID, viewerID}, viewerIDs(viewers))
+	for _, v := range viewers {
+		assert.WithinDuration(t, time.Now(), v.LastSeen, 5*time.Second)
+	}
+
+	do(viewerID, "POST", "leave")
+	expectEvent("slideshow_viewer_left", viewerID)
+	assert.Equal(t, []int{controllerID}, viewerIDs(do(viewerID, "GET", "viewers")))
+
+	select {
+	case msg := <-controller.Send:
+		t.Fatalf("unexpected event %s", msg.Type)
+	default:
+	}
+}
+
 func strPtr(s string) *string {
 	return &s
 }
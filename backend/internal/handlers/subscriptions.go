@@ -46,7 +46,19 @@ func (h *SubscriptionsHandler) SubscribeToHub(c *gin.Context) {
 		return
 	}
 
-	err = h.hubSubRepo.Subscribe(c.Request.Context(), userID.(int), hub.ID)
+	// Subscribing to a private hub would put its posts in the user's feed
+	uid := userID.(int)
+	accessible, err := h.hubRepo.IsAccessibleTo(c.Request.Context(), hub.ID, &uid)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check hub membership", "details": err.Error()})
+		return
+	}
+	if !accessible {
+		c.JSON(http.StatusForbidden, gin.H{"error": "This hub is private"})
+		return
+	}
+
+	err = h.hubSubRepo.Subscribe(c.Request.Context(), uid, hub.ID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to subscribe", "details": err.Error()})
 		return
@@ -93,6 +93,38 @@ func TestSubscribeToHub_HubNotFound(t *testing.T) {
 	assert.Equal(t, http.StatusNotFound, w.Code)
 }
 
+func TestSubscribeToHub_PrivateHubRequiresAccess(t *testing.T) {
+	handler, hubSubRepo, _, hubRepo, cleanup := setupSubscriptionsTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	hubName := fmt.Sprintf("privatesub_%d", time.Now().UnixNano())
+	hub := &models.Hub{
+		Name:      hubName,
+		Type:      "private",
+		CreatedBy: intPtr(1),
+	}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	subscribe := func(userID int) int {
+		router := gin.New()
+		router.POST("/hubs/:name/subscribe", mockAuthMiddleware(userID), handler.SubscribeToHub)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/hubs/"+hubName+"/subscribe", nil))
+		return w.Code
+	}
+
+	gin.SetMode(gin.TestMode)
+	outsiderID := 1 << 30
+	assert.Equal(t, http.StatusForbidden, subscribe(outsiderID))
+	subscribed, err := hubSubRepo.IsSubscribed(ctx, outsiderID, hub.ID)
+	require.NoError(t, err)
+	assert.False(t, subscribed)
+
+	// The creator can still subscribe
+	assert.Equal(t, http.StatusOK, subscribe(1))
+}
+
 func TestUnsubscribeFromHub_Success(t *testing.T) {
 	handler, hubSubRepo, _, hubRepo, cleanup := setupSubscriptionsTest(t)
 	defer cleanup()
@@ -221,6 +253,66 @@ func TestGetUserHubSubscriptions_Success(t *testing.T) {
 	assert.Len(t, subscriptions, 2)
 }
 
+func TestHubSubscriberCount_TracksSubscriptionsAndRecounts(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	handler := NewSubscriptionsHandler(models.NewHubSubscriptionRepository(db.Pool), models.NewSubredditSubscriptionRepository(db.Pool), hubRepo)
+
+	suffix := time.Now().UnixNano()
+	alice := &models.User{Username: fmt.Sprintf("subcount_alice_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, alice))
+	bob := &models.User{Username: fmt.Sprintf("subcount_bob_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, bob))
+	hub := &models.Hub{Name: fmt.Sprintf("subcount_%d", suffix), CreatedBy: &alice.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	gin.SetMode(gin.TestMode)
+	send := func(userID int, method, action string) int {
+		router := gin.New()
+		router.POST("/hubs/:name/subscribe", authMiddleware(userID), handler.SubscribeToHub)
+		router.DELETE("/hubs/:name/unsubscribe", authMiddleware(userID), handler.UnsubscribeFromHub)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(method, fmt.Sprintf("/hubs/%s/%s", hub.Name, action), nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			SubscriberCount int `json:"subscriber_count"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.SubscriberCount
+	}
+	subscribe := func(userID int) int { return send(userID, http.MethodPost, "subscribe") }
+	unsubscribe := func(userID int) int { return send(userID, http.MethodDelete, "unsubscribe") }
+
+	assert.Equal(t, 1, subscribe(alice.ID))
+	assert.Equal(t, 1, subscribe(alice.ID), "subscribing twice counts once")
+	assert.Equal(t, 2, subscribe(bob.ID))
+	assert.Equal(t, 1, unsubscribe(alice.ID))
+	assert.Equal(t, 1, unsubscribe(alice.ID), "unsubscribing twice counts once")
+
+	// Drift is repaired by a recount
+	_, err = db.Pool.Exec(ctx, `UPDATE hubs SET subscriber_count = 42 WHERE id = $1`, hub.ID)
+	require.NoError(t, err)
+	count, err := hubRepo.RecountSubscribers(ctx, hub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	fetched, err := hubRepo.GetByName(ctx, hub.Name)
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetched.SubscriberCount)
+
+	// The count never goes negative
+	_, err = db.Pool.Exec(ctx, `UPDATE hubs SET subscriber_count = 0 WHERE id = $1`, hub.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, unsubscribe(bob.ID))
+}
+
 func TestSubscribeToSubreddit_Success(t *testing.T) {
 	handler, _, _, _, cleanup := setupSubscriptionsTest(t)
 	defer cleanup()
@@ -1,8 +1,12 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -18,6 +22,7 @@ type ThemesHandler struct {
 	installedRepo     *models.UserInstalledThemeRepository
 	settingsRepo      *models.UserSettingsRepository
 	sanitizer         *services.CSSSanitizer
+	cssLimits         services.ThemeCSSLimits
 }
 
 // NewThemesHandler creates a new themes handler.
@@ -34,9 +39,15 @@ func NewThemesHandler(
 		installedRepo:     installedRepo,
 		settingsRepo:      settingsRepo,
 		sanitizer:         sanitizer,
+		cssLimits:         services.DefaultThemeCSSLimits(),
 	}
 }
 
+// SetCSSLimits overrides the default theme size and complexity limits.
+func (h *ThemesHandler) SetCSSLimits(limits services.ThemeCSSLimits) {
+	h.cssLimits = limits
+}
+
 // ============================================================================
 // Validation Helpers
 // ============================================================================
@@ -68,10 +79,10 @@ var validPageNames = map[string]bool{
 func (h *ThemesHandler) validateThemeName(name string) error {
 	name = strings.TrimSpace(name)
 	if name == "" {
-		return gin.Error{Err: nil, Type: gin.ErrorTypeBind, Meta: "Theme name cannot be empty"}
+		return errors.New("Theme name cannot be empty")
 	}
 	if len(name) > 100 {
-		return gin.Error{Err: nil, Type: gin.ErrorTypeBind, Meta: "Theme name must be 100 characters or less"}
+		return errors.New("Theme name must be 100 characters or less")
 	}
 	return nil
 }
@@ -83,22 +94,76 @@ func (h *ThemesHandler) validateCSSVariables(vars map[string]interface{}) error
 	}
 	// Basic validation - check for reasonable size
 	if len(vars) > 200 {
-		return gin.Error{Err: nil, Type: gin.ErrorTypeBind, Meta: "Too many CSS variables (max 200)"}
+		return errors.New("Too many CSS variables (max 200)")
 	}
 	// Validate each key/value pair
 	for key, value := range vars {
 		// Keys should be valid CSS variable names (lowercase, hyphens)
 		if !isValidCSSVariableName(key) {
-			return gin.Error{Err: nil, Type: gin.ErrorTypeBind, Meta: "Invalid CSS variable name: " + key}
+			return errors.New("Invalid CSS variable name: " + key)
 		}
 		// Values should be strings
 		if _, ok := value.(string); !ok {
-			return gin.Error{Err: nil, Type: gin.ErrorTypeBind, Meta: "CSS variable values must be strings"}
+			return errors.New("CSS variable values must be strings")
 		}
 	}
 	return nil
 }
 
+// enforceCSSLimits checks a theme against the size and complexity limits for its
+// visibility, measuring the CSS after comments and extra whitespace are stripped.
+// It writes a 400 response and returns false when a limit is exceeded.
+func (h *ThemesHandler) enforceCSSLimits(c *gin.Context, customCSS *string, vars map[string]interface{}, public bool) bool {
+	if limitErr := h.checkCSSLimits(customCSS, vars, public); limitErr != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Theme exceeds size limits", "details": limitErr.Error(), "limit": limitErr})
+		return false
+	}
+	return true
+}
+
+// checkCSSLimits returns the limit a theme exceeds, if any, measured the way
+// enforceCSSLimits does.
+func (h *ThemesHandler) checkCSSLimits(customCSS *string, vars map[string]interface{}, public bool) *services.CSSLimitError {
+	css := ""
+	if customCSS != nil {
+		css = h.sanitizer.NormalizeWhitespace(h.sanitizer.StripComments(*customCSS))
+	}
+	return h.cssLimits.For(public).Check(css, len(vars))
+}
+
+// validateThemeCSS runs the checks saving a theme applies to its variables and
+// custom CSS, returning the first failure.
+func (h *ThemesHandler) validateThemeCSS(customCSS string, vars map[string]interface{}) error {
+	if err := h.validateCSSVariables(vars); err != nil {
+		return err
+	}
+	if err := h.sanitizer.ValidateVariables(vars); err != nil {
+		return err
+	}
+	return h.sanitizer.Sanitize(customCSS)
+}
+
+// needsAdvancedMode reports whether a theme is gated behind advanced mode:
+// full_css themes and any theme carrying custom CSS
+func needsAdvancedMode(themeType string, customCSS *string) bool {
+	return themeType == "full_css" || (customCSS != nil && strings.TrimSpace(*customCSS) != "")
+}
+
+// requireAdvancedMode checks that the user has advanced mode enabled, which
+// gates custom CSS. It writes a 403 response and returns false otherwise.
+func (h *ThemesHandler) requireAdvancedMode(c *gin.Context, userID int) bool {
+	settings, err := h.settingsRepo.GetByUserID(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch settings"})
+		return false
+	}
+	if settings == nil || !settings.AdvancedModeEnabled {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Enable advanced mode to create or edit themes with custom CSS"})
+		return false
+	}
+	return true
+}
+
 // isValidCSSVariableName checks if a CSS variable name is valid
 func isValidCSSVariableName(name string) bool {
 	if len(name) == 0 || len(name) > 100 {
@@ -133,14 +198,18 @@ type createThemeRequest struct {
 
 // CreateTheme handles POST /api/v1/themes
 func (h *ThemesHandler) CreateTheme(c *gin.Context) {
-	userID := c.GetInt("user_id")
-
 	var req createThemeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request", "details": err.Error()})
+		respondBindingError(c, "Invalid request", err)
 		return
 	}
 
+	h.createTheme(c, c.GetInt("user_id"), &req)
+}
+
+// createTheme validates and sanitizes req and saves it as a new theme owned by
+// userID, writing the response. It is shared by CreateTheme and ImportTheme.
+func (h *ThemesHandler) createTheme(c *gin.Context, userID int, req *createThemeRequest) {
 	// Validate theme name
 	if err := h.validateThemeName(req.ThemeName); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -178,6 +247,10 @@ func (h *ThemesHandler) CreateTheme(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := h.sanitizer.ValidateVariables(req.CSSVariables); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "CSS validation failed", "details": err.Error()})
+		return
+	}
 
 	// Sanitize custom CSS if provided
 	if req.CustomCSS != nil && *req.CustomCSS != "" {
@@ -188,6 +261,14 @@ func (h *ThemesHandler) CreateTheme(c *gin.Context) {
 		}
 	}
 
+	if !h.enforceCSSLimits(c, req.CustomCSS, req.CSSVariables, req.IsPublic) {
+		return
+	}
+
+	if needsAdvancedMode(req.ThemeType, req.CustomCSS) && !h.requireAdvancedMode(c, userID) {
+		return
+	}
+
 	// Create theme
 	theme := &models.UserTheme{
 		UserID:           userID,
@@ -218,6 +299,86 @@ func (h *ThemesHandler) CreateTheme(c *gin.Context) {
 	c.JSON(http.StatusCreated, created)
 }
 
+type previewThemeRequest struct {
+	CSSVariables map[string]interface{} `json:"css_variables"`
+	CustomCSS    string                 `json:"custom_css"`
+	IsPublic     bool                   `json:"is_public"`
+}
+
+// PreviewTheme handles POST /api/v1/themes/preview
+// Returns the CSS that would actually be applied, without saving a theme.
+// Dangerous rules are dropped and listed rather than failing the request,
+// so the editor can show exactly what will render and what was removed.
+// valid reports whether saving the same theme would succeed: the preview runs
+// the save-time validation and limits on the submitted CSS first.
+func (h *ThemesHandler) PreviewTheme(c *gin.Context) {
+	var req previewThemeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondBindingError(c, "Invalid request", err)
+		return
+	}
+
+	var validationError *string
+	if err := h.validateThemeCSS(req.CustomCSS, req.CSSVariables); err != nil {
+		message := err.Error()
+		validationError = &message
+	}
+	limitErr := h.checkCSSLimits(&req.CustomCSS, req.CSSVariables, req.IsPublic)
+
+	sanitizedCSS, rejectedRules := h.sanitizer.SanitizeRules(req.CustomCSS)
+
+	_, variablesCSS, variableErrors := h.sanitizeVariablesLeniently(req.CSSVariables)
+
+	c.JSON(http.StatusOK, gin.H{
+		"sanitized_css":    sanitizedCSS,
+		"variables_css":    variablesCSS,
+		"rejected_rules":   rejectedRules,
+		"variable_errors":  variableErrors,
+		"validation_error": validationError,
+		"limit_error":      limitErr,
+		"valid":            validationError == nil && limitErr == nil && len(rejectedRules) == 0 && len(variableErrors) == 0,
+	})
+}
+
+// sanitizeVariablesLeniently validates variables one at a time so every bad
+// entry is reported. It returns the safe variables, the same variables as a
+// :root block, and an error message per rejected name.
+func (h *ThemesHandler) sanitizeVariablesLeniently(vars map[string]interface{}) (map[string]string, string, gin.H) {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	safe := map[string]string{}
+	variableErrors := gin.H{}
+	var declarations []string
+	if len(names) > 200 {
+		variableErrors["css_variables"] = "Too many CSS variables (max 200)"
+	} else {
+		for _, name := range names {
+			single := map[string]interface{}{name: vars[name]}
+			err := h.validateCSSVariables(single)
+			if err == nil {
+				err = h.sanitizer.ValidateVariables(single)
+			}
+			if err != nil {
+				variableErrors[name] = err.Error()
+				continue
+			}
+			value := h.sanitizer.NormalizeWhitespace(vars[name].(string))
+			safe[name] = value
+			declarations = append(declarations, name+": "+value+";")
+		}
+	}
+
+	variablesCSS := ""
+	if len(declarations) > 0 {
+		variablesCSS = ":root { " + strings.Join(declarations, " ") + " }"
+	}
+	return safe, variablesCSS, variableErrors
+}
+
 // GetTheme handles GET /api/v1/themes/:id
 func (h *ThemesHandler) GetTheme(c *gin.Context) {
 	themeID, err := strconv.Atoi(c.Param("id"))
@@ -272,9 +433,14 @@ type updateThemeRequest struct {
 	Category         *string                `json:"category"`
 	Tags             []string               `json:"tags"`
 	ThumbnailURL     *string                `json:"thumbnail_url"`
+	Changelog        *string                `json:"changelog"` // Recorded when the update bumps the version
 }
 
+// maxChangelogLength caps the changelog note stored with a theme version.
+const maxChangelogLength = 2000
+
 // UpdateTheme handles PUT /api/v1/themes/:id
+// Changing css_variables or custom_css bumps the patch version.
 func (h *ThemesHandler) UpdateTheme(c *gin.Context) {
 	userID := c.GetInt("user_id")
 	themeID, err := strconv.Atoi(c.Param("id"))
@@ -299,13 +465,22 @@ func (h *ThemesHandler) UpdateTheme(c *gin.Context) {
 		c.JSON(http.StatusForbidden, gin.H{"error": "You can only update your own themes"})
 		return
 	}
-
 	var req updateThemeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		respondBindingError(c, "Invalid request", err)
+		return
+	}
+	if req.Changelog != nil && len(*req.Changelog) > maxChangelogLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Changelog must be 2000 characters or less"})
 		return
 	}
 
+	previousVariables := theme.CSSVariables
+	previousCSS := ""
+	if theme.CustomCSS != nil {
+		previousCSS = *theme.CustomCSS
+	}
+
 	// Update fields
 	if req.ThemeName != nil {
 		if len(*req.ThemeName) > 100 {
@@ -318,6 +493,14 @@ func (h *ThemesHandler) UpdateTheme(c *gin.Context) {
 		theme.ThemeDescription = req.ThemeDescription
 	}
 	if req.CSSVariables != nil {
+		if err := h.validateCSSVariables(req.CSSVariables); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := h.sanitizer.ValidateVariables(req.CSSVariables); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "CSS validation failed", "details": err.Error()})
+			return
+		}
 		theme.CSSVariables = req.CSSVariables
 	}
 	if req.CustomCSS != nil {
@@ -344,7 +527,34 @@ func (h *ThemesHandler) UpdateTheme(c *gin.Context) {
 		theme.ThumbnailURL = req.ThumbnailURL
 	}
 
-	if err := h.themeRepo.Update(c.Request.Context(), theme); err != nil {
+	if needsAdvancedMode(theme.ThemeType, theme.CustomCSS) && !h.requireAdvancedMode(c, userID) {
+		return
+	}
+
+	// Re-check limits whenever the CSS grows or the theme becomes public
+	if req.CSSVariables != nil || req.CustomCSS != nil || req.IsPublic != nil {
+		if !h.enforceCSSLimits(c, theme.CustomCSS, theme.CSSVariables, theme.IsPublic || theme.IsMarketplace) {
+			return
+		}
+	}
+
+	currentCSS := ""
+	if theme.CustomCSS != nil {
+		currentCSS = *theme.CustomCSS
+	}
+	styleChanged := (req.CSSVariables != nil && !reflect.DeepEqual(previousVariables, theme.CSSVariables)) ||
+		(req.CustomCSS != nil && previousCSS != currentCSS)
+	if styleChanged {
+		var changelog *string
+		if req.Changelog != nil && strings.TrimSpace(*req.Changelog) != "" {
+			trimmed := strings.TrimSpace(*req.Changelog)
+			changelog = &trimmed
+		}
+		if _, err := h.themeRepo.UpdateAndBumpVersion(c.Request.Context(), theme, changelog); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update theme"})
+			return
+		}
+	} else if err := h.themeRepo.Update(c.Request.Context(), theme); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update theme"})
 		return
 	}
@@ -359,6 +569,37 @@ func (h *ThemesHandler) UpdateTheme(c *gin.Context) {
 	c.JSON(http.StatusOK, updated)
 }
 
+// GetThemeVersions handles GET /api/v1/themes/:id/versions
+func (h *ThemesHandler) GetThemeVersions(c *gin.Context) {
+	themeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid theme ID"})
+		return
+	}
+
+	theme, err := h.themeRepo.GetByID(c.Request.Context(), themeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme", "details": err.Error()})
+		return
+	}
+	if theme == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Theme not found"})
+		return
+	}
+
+	versions, err := h.themeRepo.GetVersions(c.Request.Context(), themeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme versions", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"theme_id":        themeID,
+		"current_version": theme.Version,
+		"versions":        versions,
+	})
+}
+
 // DeleteTheme handles DELETE /api/v1/themes/:id
 func (h *ThemesHandler) DeleteTheme(c *gin.Context) {
 	userID := c.GetInt("user_id")
@@ -408,9 +649,44 @@ func (h *ThemesHandler) GetPredefinedThemes(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
 		"themes": themes,
 		"count":  len(themes),
+		"pairs":  pairPredefinedThemes(themes),
 	})
 }
 
+// themePair is a light theme and its dark counterpart.
+type themePair struct {
+	LightThemeID int `json:"light_theme_id"`
+	DarkThemeID  int `json:"dark_theme_id"`
+}
+
+// pairPredefinedThemes groups themes that are paired with each other, deciding
+// which side is light from their background colors. Pairs whose partner isn't
+// in themes are skipped.
+func pairPredefinedThemes(themes []*models.UserTheme) []themePair {
+	byID := make(map[int]*models.UserTheme, len(themes))
+	for _, theme := range themes {
+		byID[theme.ID] = theme
+	}
+
+	pairs := []themePair{}
+	for _, theme := range themes {
+		if theme.PairedThemeID == nil {
+			continue
+		}
+		partner, ok := byID[*theme.PairedThemeID]
+		// Each pair is seen from both sides; keep it once
+		if !ok || partner.PairedThemeID == nil || *partner.PairedThemeID != theme.ID || partner.ID < theme.ID {
+			continue
+		}
+		light, dark := theme, partner
+		if light.ColorScheme() == models.ColorSchemeDark && dark.ColorScheme() == models.ColorSchemeLight {
+			light, dark = dark, light
+		}
+		pairs = append(pairs, themePair{LightThemeID: light.ID, DarkThemeID: dark.ID})
+	}
+	return pairs
+}
+
 // ============================================================================
 // Public Theme Browser (Phase 2c - Community Sharing)
 // ============================================================================
@@ -429,7 +705,13 @@ func (h *ThemesHandler) BrowseThemes(c *gin.Context) {
 		categoryPtr = &category
 	}
 
-	themes, err := h.themeRepo.GetPublicThemes(c.Request.Context(), limit, offset, categoryPtr)
+	sortBy := c.DefaultQuery("sort", models.ThemeSortPopular)
+	if sortBy != models.ThemeSortPopular && sortBy != models.ThemeSortTopRated {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid sort. Must be: popular or top_rated"})
+		return
+	}
+
+	themes, err := h.themeRepo.GetPublicThemes(c.Request.Context(), limit, offset, categoryPtr, sortBy)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch public themes", "details": err.Error()})
 		return
@@ -439,6 +721,7 @@ func (h *ThemesHandler) BrowseThemes(c *gin.Context) {
 		"themes": themes,
 		"limit":  limit,
 		"offset": offset,
+		"sort":   sortBy,
 	})
 }
 
@@ -456,7 +739,7 @@ func (h *ThemesHandler) InstallTheme(c *gin.Context) {
 
 	var req installThemeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		respondBindingError(c, "Invalid request", err)
 		return
 	}
 
@@ -512,7 +795,7 @@ func (h *ThemesHandler) SetActiveTheme(c *gin.Context) {
 
 	var req setActiveThemeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		respondBindingError(c, "Invalid request", err)
 		return
 	}
 
@@ -548,6 +831,7 @@ func (h *ThemesHandler) SetActiveTheme(c *gin.Context) {
 }
 
 // GetInstalledThemes handles GET /api/v1/themes/installed
+// Each entry carries installed_version, current_version and update_available.
 func (h *ThemesHandler) GetInstalledThemes(c *gin.Context) {
 	userID := c.GetInt("user_id")
 
@@ -578,7 +862,7 @@ func (h *ThemesHandler) SetPageOverride(c *gin.Context) {
 
 	var req setPageOverrideRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		respondBindingError(c, "Invalid request", err)
 		return
 	}
 
@@ -664,6 +948,258 @@ func (h *ThemesHandler) DeletePageOverride(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Page override deleted successfully"})
 }
 
+// ============================================================================
+// Effective Theme Resolution
+// ============================================================================
+
+// Where the effective theme for a page came from
+const (
+	themeSourceOverride = "override"
+	themeSourceActive   = "active"
+	themeSourceDefault  = "default"
+)
+
+// EffectiveTheme is the theme that applies to one page for one user, with the
+// CSS variables of every layer beneath it merged in.
+type EffectiveTheme struct {
+	Page                string            `json:"page"`
+	Source              string            `json:"source"`
+	Theme               *models.UserTheme `json:"theme"`
+	AdvancedModeEnabled bool              `json:"advanced_mode_enabled"`
+	CSSVariables        map[string]string `json:"css_variables"`
+	VariablesCSS        string            `json:"variables_css"`
+	CustomCSS           string            `json:"custom_css"`
+}
+
+// ResolveTheme handles GET /api/v1/themes/resolve?page=feed
+// Returns the single theme that applies to a page, so the client doesn't have
+// to combine overrides, the active theme and advanced mode itself.
+func (h *ThemesHandler) ResolveTheme(c *gin.Context) {
+	page := c.Query("page")
+	if !validPageNames[page] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	resolved, err := h.ResolveEffectiveTheme(c.Request.Context(), c.GetInt("user_id"), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve theme"})
+		return
+	}
+
+	c.JSON(http.StatusOK, resolved)
+}
+
+// GetEffectiveTheme handles GET /api/v1/themes/effective/:pageName
+// Same resolution as ResolveTheme, with the page taken from the path.
+func (h *ThemesHandler) GetEffectiveTheme(c *gin.Context) {
+	page := c.Param("pageName")
+	if !validPageNames[page] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page"})
+		return
+	}
+
+	effective, err := h.ResolveEffectiveTheme(c.Request.Context(), c.GetInt("user_id"), page)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve theme"})
+		return
+	}
+
+	c.JSON(http.StatusOK, effective)
+}
+
+// ResolveEffectiveTheme resolves the theme for pageName. Precedence is the page
+// override, then the active global theme, then the default predefined theme.
+// A referenced theme that was deleted, or that the user no longer owns or has
+// installed, is skipped. Variables are layered in the same order, so an
+// override that only sets a few of them inherits the rest from the active and
+// default themes. Custom CSS is only applied with advanced mode on; without
+// it the CSS is left out of Theme as well. Theme is nil only when nothing is
+// chosen and no predefined theme exists.
+func (h *ThemesHandler) ResolveEffectiveTheme(ctx context.Context, userID int, pageName string) (*EffectiveTheme, error) {
+	settings, err := h.settingsRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	override, active, err := h.pageThemeLayers(ctx, userID, pageName, settings)
+	if err != nil {
+		return nil, err
+	}
+	fallback, err := h.themeRepo.GetDefaultPredefinedTheme(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := &EffectiveTheme{
+		Page:                pageName,
+		Source:              themeSourceDefault,
+		Theme:               fallback,
+		AdvancedModeEnabled: settings != nil && settings.AdvancedModeEnabled,
+	}
+	if override != nil {
+		resolved.Source, resolved.Theme = themeSourceOverride, override
+	} else if active != nil {
+		resolved.Source, resolved.Theme = themeSourceActive, active
+	}
+
+	merged := map[string]interface{}{}
+	for _, layer := range []*models.UserTheme{fallback, active, override} {
+		if layer == nil {
+			continue
+		}
+		for name, value := range layer.CSSVariables {
+			merged[name] = value
+		}
+	}
+	resolved.CSSVariables, resolved.VariablesCSS, _ = h.sanitizeVariablesLeniently(merged)
+
+	if resolved.Theme != nil && resolved.Theme.CustomCSS != nil {
+		if resolved.AdvancedModeEnabled {
+			resolved.CustomCSS, _ = h.sanitizer.SanitizeRules(*resolved.Theme.CustomCSS)
+		} else {
+			// Copy so the CSS is withheld from the response, not the cached theme
+			theme := *resolved.Theme
+			theme.CustomCSS = nil
+			resolved.Theme = &theme
+		}
+	}
+
+	return resolved, nil
+}
+
+// pageThemeLayers returns the user's override for page and their active global
+// theme. Either is nil when unset or no longer usable.
+func (h *ThemesHandler) pageThemeLayers(ctx context.Context, userID int, page string, settings *models.UserSettings) (override, active *models.UserTheme, err error) {
+	pageOverride, err := h.themeOverrideRepo.GetOverride(ctx, userID, page)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pageOverride != nil {
+		if override, err = h.usableTheme(ctx, userID, pageOverride.ThemeID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if settings != nil && settings.ActiveThemeID != nil {
+		if active, err = h.usableTheme(ctx, userID, *settings.ActiveThemeID); err != nil {
+			return nil, nil, err
+		}
+	}
+	return override, active, nil
+}
+
+// usableTheme returns the theme if it still exists and the user may apply it:
+// predefined themes, their own themes, and themes they have installed.
+// It returns nil for anything else.
+func (h *ThemesHandler) usableTheme(ctx context.Context, userID, themeID int) (*models.UserTheme, error) {
+	theme, err := h.themeRepo.GetByID(ctx, themeID)
+	if err != nil || theme == nil {
+		return nil, err
+	}
+	if theme.ThemeType == "predefined" || theme.UserID == userID {
+		return theme, nil
+	}
+	installed, err := h.installedRepo.HasInstalled(ctx, userID, themeID)
+	if err != nil || !installed {
+		return nil, err
+	}
+	return theme, nil
+}
+
+// ============================================================================
+// Export / Import
+// ============================================================================
+
+// themeBundleSchemaVersion is the current version of the portable theme format.
+// Bump it when the bundle layout changes and teach ImportTheme the old one.
+const themeBundleSchemaVersion = 1
+
+// themeBundle is a portable copy of a theme. It carries only the theme's look and
+// descriptive metadata; ids, ownership, marketplace and rating fields stay behind.
+type themeBundle struct {
+	SchemaVersion    int                    `json:"schema_version" binding:"required"`
+	ThemeName        string                 `json:"theme_name" binding:"required"`
+	ThemeDescription *string                `json:"theme_description,omitempty"`
+	ThemeType        string                 `json:"theme_type" binding:"required"`
+	ScopeType        string                 `json:"scope_type" binding:"required"`
+	TargetPage       *string                `json:"target_page,omitempty"`
+	CSSVariables     map[string]interface{} `json:"css_variables,omitempty"`
+	CustomCSS        *string                `json:"custom_css,omitempty"`
+	Category         *string                `json:"category,omitempty"`
+	Tags             []string               `json:"tags,omitempty"`
+}
+
+// ExportTheme handles GET /api/v1/themes/:id/export
+// Any theme the user can apply or that is public can be exported.
+func (h *ThemesHandler) ExportTheme(c *gin.Context) {
+	userID := c.GetInt("user_id")
+	themeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid theme ID"})
+		return
+	}
+
+	theme, err := h.themeRepo.GetByID(c.Request.Context(), themeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme", "details": err.Error()})
+		return
+	}
+	if theme != nil && !theme.IsPublic {
+		theme, err = h.usableTheme(c.Request.Context(), userID, themeID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme", "details": err.Error()})
+			return
+		}
+	}
+	if theme == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Theme not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, themeBundle{
+		SchemaVersion:    themeBundleSchemaVersion,
+		ThemeName:        theme.ThemeName,
+		ThemeDescription: theme.ThemeDescription,
+		ThemeType:        theme.ThemeType,
+		ScopeType:        theme.ScopeType,
+		TargetPage:       theme.TargetPage,
+		CSSVariables:     theme.CSSVariables,
+		CustomCSS:        theme.CustomCSS,
+		Category:         theme.Category,
+		Tags:             theme.Tags,
+	})
+}
+
+// ImportTheme handles POST /api/v1/themes/import
+// The bundle goes through the same validation and CSS sanitization as
+// CreateTheme and becomes a new private theme owned by the caller.
+func (h *ThemesHandler) ImportTheme(c *gin.Context) {
+	var bundle themeBundle
+	if err := c.ShouldBindJSON(&bundle); err != nil {
+		respondBindingError(c, "Invalid theme bundle", err)
+		return
+	}
+
+	if bundle.SchemaVersion != themeBundleSchemaVersion {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":                    "Unsupported schema_version",
+			"supported_schema_version": themeBundleSchemaVersion,
+		})
+		return
+	}
+
+	h.createTheme(c, c.GetInt("user_id"), &createThemeRequest{
+		ThemeName:        bundle.ThemeName,
+		ThemeDescription: bundle.ThemeDescription,
+		ThemeType:        bundle.ThemeType,
+		ScopeType:        bundle.ScopeType,
+		TargetPage:       bundle.TargetPage,
+		CSSVariables:     bundle.CSSVariables,
+		CustomCSS:        bundle.CustomCSS,
+		Category:         bundle.Category,
+		Tags:             bundle.Tags,
+	})
+}
+
 // ============================================================================
 // Advanced Mode Toggle
 // ============================================================================
@@ -678,7 +1214,7 @@ func (h *ThemesHandler) SetAdvancedMode(c *gin.Context) {
 
 	var req setAdvancedModeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request"})
+		respondBindingError(c, "Invalid request", err)
 		return
 	}
 
@@ -725,7 +1261,7 @@ func (h *ThemesHandler) RateTheme(c *gin.Context) {
 
 	var req rateThemeRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request. Rating must be 1-5"})
+		respondBindingError(c, "Invalid request. Rating must be 1-5", err)
 		return
 	}
 
@@ -734,10 +1270,77 @@ func (h *ThemesHandler) RateTheme(c *gin.Context) {
 		reviewPtr = &req.Review
 	}
 
-	if err := h.installedRepo.RateTheme(c.Request.Context(), userID, req.ThemeID, req.Rating, reviewPtr); err != nil {
+	theme, err := h.themeRepo.GetByID(c.Request.Context(), req.ThemeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme"})
+		return
+	}
+	if theme == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Theme not found"})
+		return
+	}
+
+	created, err := h.installedRepo.RateTheme(c.Request.Context(), userID, req.ThemeID, req.Rating, reviewPtr)
+	if errors.Is(err, models.ErrThemeNotInstalled) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Install the theme before rating it"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to rate theme"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Theme rated successfully"})
+	if created {
+		c.JSON(http.StatusCreated, gin.H{"message": "Theme rated successfully", "created": true})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Theme rating updated", "created": false})
+}
+
+// GetThemeReviews handles GET /api/v1/themes/:id/reviews
+func (h *ThemesHandler) GetThemeReviews(c *gin.Context) {
+	themeID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid theme ID"})
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	ctx := c.Request.Context()
+	theme, err := h.themeRepo.GetByID(ctx, themeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme", "details": err.Error()})
+		return
+	}
+	if theme == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Theme not found"})
+		return
+	}
+
+	avg, count, err := h.installedRepo.GetRatingSummary(ctx, themeID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch theme rating", "details": err.Error()})
+		return
+	}
+	reviews, err := h.installedRepo.GetReviews(ctx, themeID, limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reviews", "details": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reviews":        reviews,
+		"average_rating": avg,
+		"rating_count":   count,
+		"limit":          limit,
+		"offset":         offset,
+	})
 }
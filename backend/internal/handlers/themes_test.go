@@ -0,0 +1,1034 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performThemePreview(t *testing.T, body interface{}) (int, map[string]interface{}) {
+	t.Helper()
+
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/themes/preview", authMiddleware(1), handler.PreviewTheme)
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/themes/preview", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestPreviewTheme_ReportsRejectedRulesAndVariables(t *testing.T) {
+	code, response := performThemePreview(t, map[string]interface{}{
+		"custom_css": `@import "x.css"; .ok { color: red; } .bad { background: url(javascript:alert(1)); }`,
+		"css_variables": map[string]interface{}{
+			"--primary":  "#ff0000",
+			"--tracking": "url(https://evil.example/pixel.png)",
+		},
+	})
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ".ok { color: red; }", response["sanitized_css"])
+	assert.Equal(t, ":root { --primary: #ff0000; }", response["variables_css"])
+	assert.Equal(t, false, response["valid"])
+
+	rejected := response["rejected_rules"].([]interface{})
+	assert.Len(t, rejected, 2)
+
+	variableErrors := response["variable_errors"].(map[string]interface{})
+	assert.Contains(t, variableErrors, "--tracking")
+	assert.NotContains(t, variableErrors, "--primary")
+}
+
+func TestPreviewTheme_SafeThemeIsValid(t *testing.T) {
+	code, response := performThemePreview(t, map[string]interface{}{
+		"custom_css":    ".header {  color:  blue; }",
+		"css_variables": map[string]interface{}{"--accent": "rgb(0, 0, 255)"},
+	})
+
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, ".header { color: blue; }", response["sanitized_css"])
+	assert.Empty(t, response["rejected_rules"])
+	assert.Equal(t, true, response["valid"])
+}
+
+// frontendDefaultThemeVariables reads DEFAULT_THEME_VARIABLES from the
+// frontend, which is what the theme editor sends when a theme is saved.
+func frontendDefaultThemeVariables(t *testing.T) map[string]interface{} {
+	t.Helper()
+	source, err := os.ReadFile("../../../frontend/src/data/themeVariables.ts")
+	require.NoError(t, err)
+
+	block := regexp.MustCompile(`(?s)DEFAULT_THEME_VARIABLES[^{]*\{(.*?)\n\};`).FindSubmatch(source)
+	require.NotNil(t, block, "DEFAULT_THEME_VARIABLES not found")
+
+	vars := map[string]interface{}{}
+	entry := regexp.MustCompile(`'(--[\w-]+)':\s*(?:'([^']*)'|"([^"]*)")`)
+	for _, m := range entry.FindAllSubmatch(block[1], -1) {
+		vars[string(m[1])] = string(m[2]) + string(m[3])
+	}
+	require.NotEmpty(t, vars)
+	return vars
+}
+
+func TestThemeVariables_FrontendDefaultsRoundTrip(t *testing.T) {
+	defaults := frontendDefaultThemeVariables(t)
+	require.Contains(t, defaults, "--font-family-base")
+
+	code, response := performThemePreview(t, map[string]interface{}{"css_variables": defaults})
+	require.Equal(t, http.StatusOK, code)
+	assert.Empty(t, response["variable_errors"])
+	assert.Equal(t, true, response["valid"])
+
+	// Resolving a theme keeps every variable, fonts included
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	safe, _, variableErrors := handler.sanitizeVariablesLeniently(defaults)
+	assert.Empty(t, variableErrors)
+	require.Len(t, safe, len(defaults))
+	for name, value := range defaults {
+		assert.Equal(t, value, safe[name], name)
+	}
+}
+
+func testThemeLimits() services.ThemeCSSLimits {
+	return services.ThemeCSSLimits{
+		Private: services.CSSLimits{MaxCSSBytes: 1000, MaxRules: 3, MaxVariables: 5},
+		Public:  services.CSSLimits{MaxCSSBytes: 40, MaxRules: 2, MaxVariables: 1},
+	}
+}
+
+func TestCreateTheme_RejectsOverLimitCSS(t *testing.T) {
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	handler.SetCSSLimits(testThemeLimits())
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/themes", authMiddleware(1), handler.CreateTheme)
+
+	cases := map[string]map[string]interface{}{
+		"too many rules": {
+			"custom_css": "a { color: red; } b { color: red; } c { color: red; } d { color: red; }",
+		},
+		"too large for public": {
+			// 41 bytes once whitespace is normalized; the comment is not counted
+			"custom_css": "/* note */ .main-header-title-tx {  color: #123456; }  ",
+			"is_public":  true,
+		},
+		"too many variables for public": {
+			"css_variables": map[string]interface{}{"--a": "red", "--b": "blue"},
+			"is_public":     true,
+		},
+	}
+
+	for name, fields := range cases {
+		body := map[string]interface{}{"theme_name": "Limits", "theme_type": "full_css", "scope_type": "global"}
+		for k, v := range fields {
+			body[k] = v
+		}
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/themes", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		assert.Contains(t, w.Body.String(), "Theme exceeds size limits", name)
+	}
+}
+
+func TestCreateTheme_ValidatesVariableValues(t *testing.T) {
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/themes", authMiddleware(1), handler.CreateTheme)
+
+	cases := map[string]map[string]interface{}{
+		"javascript url": {"--color-primary": "url(javascript:alert(1))"},
+		"unknown value":  {"--color-primary": "expression-ish"},
+		"unknown key":    {"color": "red"},
+	}
+
+	for name, vars := range cases {
+		payload, err := json.Marshal(map[string]interface{}{
+			"theme_name":    "Variables",
+			"theme_type":    "variable_customization",
+			"scope_type":    "global",
+			"css_variables": vars,
+		})
+		require.NoError(t, err)
+
+		req := httptest.NewRequest(http.MethodPost, "/themes", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, name)
+		assert.Contains(t, w.Body.String(), "CSS validation failed", name)
+	}
+}
+
+func TestPreviewTheme_ReportsLimitAtBoundary(t *testing.T) {
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	handler.SetCSSLimits(testThemeLimits())
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/themes/preview", authMiddleware(1), handler.PreviewTheme)
+
+	preview := func(body map[string]interface{}) map[string]interface{} {
+		payload, err := json.Marshal(body)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/themes/preview", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	// Exactly at the public rule limit
+	atLimit := preview(map[string]interface{}{"custom_css": "a { } b { }", "is_public": true})
+	assert.Nil(t, atLimit["limit_error"])
+	assert.Equal(t, true, atLimit["valid"])
+
+	// One rule over
+	overLimit := preview(map[string]interface{}{"custom_css": "a { } b { } c { }", "is_public": true})
+	limitErr := overLimit["limit_error"].(map[string]interface{})
+	assert.Equal(t, "custom_css_rules", limitErr["limit"])
+	assert.Equal(t, float64(2), limitErr["max"])
+	assert.Equal(t, false, overLimit["valid"])
+
+	// The same CSS is fine for a private theme
+	private := preview(map[string]interface{}{"custom_css": "a { } b { } c { }"})
+	assert.Nil(t, private["limit_error"])
+
+	// Limits are measured on the submitted CSS, as on save, not on what
+	// survives sanitizing
+	withRejected := preview(map[string]interface{}{
+		"custom_css": "a { } b { } c { background: url(javascript:alert(1)); }",
+		"is_public":  true,
+	})
+	assert.Equal(t, "a { }\nb { }", withRejected["sanitized_css"])
+	require.NotNil(t, withRejected["limit_error"])
+	assert.NotNil(t, withRejected["validation_error"])
+	assert.Equal(t, false, withRejected["valid"])
+}
+
+type themeResolveTestEnv struct {
+	themeRepo     *models.UserThemeRepository
+	overrideRepo  *models.UserThemeOverrideRepository
+	installedRepo *models.UserInstalledThemeRepository
+	settingsRepo  *models.UserSettingsRepository
+	userRepo      *models.UserRepository
+	handler       *ThemesHandler
+	user          *models.User
+	other         *models.User
+}
+
+func setupThemeResolveTest(t *testing.T) (*themeResolveTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	env := &themeResolveTestEnv{
+		themeRepo:     models.NewUserThemeRepository(db.Pool),
+		overrideRepo:  models.NewUserThemeOverrideRepository(db.Pool),
+		installedRepo: models.NewUserInstalledThemeRepository(db.Pool),
+		settingsRepo:  models.NewUserSettingsRepository(db.Pool),
+	}
+	env.handler = NewThemesHandler(env.themeRepo, env.overrideRepo, env.installedRepo, env.settingsRepo, services.NewCSSSanitizer())
+
+	env.userRepo = models.NewUserRepository(db.Pool)
+	suffix := time.Now().UnixNano()
+	env.user = &models.User{Username: fmt.Sprintf("resolver_%d", suffix), PasswordHash: "hash"}
+	env.other = &models.User{Username: fmt.Sprintf("resolver_other_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, env.userRepo.Create(ctx, env.user))
+	require.NoError(t, env.userRepo.Create(ctx, env.other))
+
+	gin.SetMode(gin.TestMode)
+	return env, func() { db.Close() }
+}
+
+func (env *themeResolveTestEnv) createTheme(t *testing.T, owner *models.User, name string) *models.UserTheme {
+	t.Helper()
+	css := ".header { color: red; } .bad { background: url(javascript:alert(1)); }"
+	theme, err := env.themeRepo.Create(context.Background(), &models.UserTheme{
+		UserID:       owner.ID,
+		ThemeName:    name,
+		ThemeType:    "full_css",
+		ScopeType:    "global",
+		CSSVariables: map[string]interface{}{"--primary": "#123456"},
+		CustomCSS:    &css,
+		Version:      "1.0.0",
+	})
+	require.NoError(t, err)
+	return theme
+}
+
+func (env *themeResolveTestEnv) setSettings(t *testing.T, activeThemeID *int, advanced bool) {
+	t.Helper()
+	ctx := context.Background()
+	settings, err := env.settingsRepo.GetByUserID(ctx, env.user.ID)
+	require.NoError(t, err)
+	if settings == nil {
+		settings, err = env.settingsRepo.CreateDefault(ctx, env.user.ID)
+		require.NoError(t, err)
+	}
+	settings.ActiveThemeID = activeThemeID
+	settings.AdvancedModeEnabled = advanced
+	_, err = env.settingsRepo.Update(ctx, settings)
+	require.NoError(t, err)
+}
+
+func (env *themeResolveTestEnv) resolve(t *testing.T, page string) map[string]interface{} {
+	t.Helper()
+	router := gin.New()
+	router.GET("/themes/resolve", authMiddleware(env.user.ID), env.handler.ResolveTheme)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/resolve?page="+page, nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return response
+}
+
+func resolvedThemeID(response map[string]interface{}) int {
+	theme, ok := response["theme"].(map[string]interface{})
+	if !ok {
+		return 0
+	}
+	return int(theme["id"].(float64))
+}
+
+func TestResolveTheme_Precedence(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	active := env.createTheme(t, env.user, "active")
+	feedTheme := env.createTheme(t, env.user, "feed")
+
+	// No active theme and no override: the default applies
+	response := env.resolve(t, "feed")
+	assert.Equal(t, "default", response["source"])
+
+	// Active theme applies to every page without an override
+	env.setSettings(t, &active.ID, false)
+	response = env.resolve(t, "feed")
+	assert.Equal(t, "active", response["source"])
+	assert.Equal(t, active.ID, resolvedThemeID(response))
+	assert.Equal(t, "#123456", response["css_variables"].(map[string]interface{})["--primary"])
+	assert.Equal(t, "", response["custom_css"], "custom CSS needs advanced mode")
+	assert.Nil(t, response["theme"].(map[string]interface{})["custom_css"], "theme CSS is withheld too")
+
+	// A page override wins on its page only
+	_, err := env.overrideRepo.SetOverride(context.Background(), env.user.ID, "feed", feedTheme.ID)
+	require.NoError(t, err)
+	env.setSettings(t, &active.ID, true)
+	response = env.resolve(t, "feed")
+	assert.Equal(t, "override", response["source"])
+	assert.Equal(t, feedTheme.ID, resolvedThemeID(response))
+	assert.Equal(t, ".header { color: red; }", response["custom_css"])
+
+	response = env.resolve(t, "profile")
+	assert.Equal(t, "active", response["source"])
+	assert.Equal(t, active.ID, resolvedThemeID(response))
+}
+
+func TestResolveTheme_FallsBackFromUnavailableThemes(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	active := env.createTheme(t, env.user, "active")
+	borrowed := env.createTheme(t, env.other, "borrowed")
+	_, err := env.installedRepo.Install(ctx, env.user.ID, borrowed.ID, 0)
+	require.NoError(t, err)
+
+	_, err = env.overrideRepo.SetOverride(ctx, env.user.ID, "messages", borrowed.ID)
+	require.NoError(t, err)
+	env.setSettings(t, &active.ID, false)
+
+	response := env.resolve(t, "messages")
+	assert.Equal(t, "override", response["source"])
+	assert.Equal(t, borrowed.ID, resolvedThemeID(response))
+
+	// Uninstalling another user's theme drops the override back to the active theme
+	require.NoError(t, env.installedRepo.Uninstall(ctx, env.user.ID, borrowed.ID))
+	response = env.resolve(t, "messages")
+	assert.Equal(t, "active", response["source"])
+	assert.Equal(t, active.ID, resolvedThemeID(response))
+
+	// Deleting the active theme leaves only the default
+	require.NoError(t, env.themeRepo.Delete(ctx, active.ID, env.user.ID))
+	response = env.resolve(t, "messages")
+	assert.Equal(t, "default", response["source"])
+	assert.NotEqual(t, active.ID, resolvedThemeID(response))
+}
+
+func TestResolveTheme_RejectsUnknownPage(t *testing.T) {
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/themes/resolve", authMiddleware(1), handler.ResolveTheme)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/resolve?page=admin", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+// ensureDefaultTheme makes sure a predefined theme exists and returns the one
+// the resolver falls back to, which may predate this test run.
+func (env *themeResolveTestEnv) ensureDefaultTheme(t *testing.T) *models.UserTheme {
+	t.Helper()
+	ctx := context.Background()
+	_, err := env.themeRepo.Create(ctx, &models.UserTheme{
+		UserID:       env.other.ID,
+		ThemeName:    "Default Light",
+		ThemeType:    "predefined",
+		ScopeType:    "global",
+		CSSVariables: map[string]interface{}{"--color-background": "#ffffff", "--accent": "blue"},
+		Version:      "1.0.0",
+	})
+	require.NoError(t, err)
+	fallback, err := env.themeRepo.GetDefaultPredefinedTheme(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, fallback)
+	return fallback
+}
+
+func TestResolveEffectiveTheme_PageOverride(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	fallback := env.ensureDefaultTheme(t)
+	active := env.createTheme(t, env.user, "active")
+	feed, err := env.themeRepo.Create(ctx, &models.UserTheme{
+		UserID:       env.user.ID,
+		ThemeName:    "feed",
+		ThemeType:    "variable_customization",
+		ScopeType:    "per_page",
+		CSSVariables: map[string]interface{}{"--primary": "#abcdef"},
+		Version:      "1.0.0",
+	})
+	require.NoError(t, err)
+	env.setSettings(t, &active.ID, false)
+	_, err = env.overrideRepo.SetOverride(ctx, env.user.ID, "feed", feed.ID)
+	require.NoError(t, err)
+
+	resolved, err := env.handler.ResolveEffectiveTheme(ctx, env.user.ID, "feed")
+	require.NoError(t, err)
+	assert.Equal(t, "override", resolved.Source)
+	require.NotNil(t, resolved.Theme)
+	assert.Equal(t, feed.ID, resolved.Theme.ID)
+
+	// The override wins on conflicts and inherits everything else
+	expected := map[string]string{}
+	for name, value := range fallback.CSSVariables {
+		expected[name] = value.(string)
+	}
+	expected["--primary"] = "#abcdef"
+	assert.Equal(t, expected, resolved.CSSVariables)
+}
+
+func TestResolveEffectiveTheme_ActiveWithoutOverride(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	env.ensureDefaultTheme(t)
+	active := env.createTheme(t, env.user, "active")
+	env.setSettings(t, &active.ID, false)
+
+	resolved, err := env.handler.ResolveEffectiveTheme(ctx, env.user.ID, "profile")
+	require.NoError(t, err)
+	assert.Equal(t, "active", resolved.Source)
+	require.NotNil(t, resolved.Theme)
+	assert.Equal(t, active.ID, resolved.Theme.ID)
+	assert.Equal(t, "#123456", resolved.CSSVariables["--primary"])
+}
+
+func TestResolveEffectiveTheme_FallsBackToPredefinedDefault(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	fallback := env.ensureDefaultTheme(t)
+
+	response := env.resolve(t, "settings")
+	assert.Equal(t, "settings", response["page"])
+	assert.Equal(t, "default", response["source"])
+	assert.Equal(t, fallback.ID, resolvedThemeID(response))
+	assert.Len(t, response["css_variables"], len(fallback.CSSVariables))
+}
+
+func TestGetEffectiveTheme_MatchesResolve(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	active := env.createTheme(t, env.user, "active")
+	feed := env.createTheme(t, env.user, "feed")
+	env.setSettings(t, &active.ID, false)
+	_, err := env.overrideRepo.SetOverride(ctx, env.user.ID, "feed", feed.ID)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/themes/effective/:pageName", authMiddleware(env.user.ID), env.handler.GetEffectiveTheme)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/effective/feed", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "override", response["source"])
+	assert.Equal(t, feed.ID, resolvedThemeID(response))
+	assert.Equal(t, env.resolve(t, "feed"), response)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/effective/profile", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "active", response["source"])
+	assert.Equal(t, active.ID, resolvedThemeID(response))
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/effective/admin", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func performThemeImport(t *testing.T, handler *ThemesHandler, userID int, bundle interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.POST("/themes/import", authMiddleware(userID), handler.ImportTheme)
+
+	payload, err := json.Marshal(bundle)
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/themes/import", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestThemeExportImport_RoundTrip(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	css := ".header { color: red; }"
+	category := "dark"
+	source, err := env.themeRepo.Create(context.Background(), &models.UserTheme{
+		UserID:       env.other.ID,
+		ThemeName:    "Portable",
+		ThemeType:    "full_css",
+		ScopeType:    "global",
+		CSSVariables: map[string]interface{}{"--primary": "#123456", "--spacing-sm": "0.5rem"},
+		CustomCSS:    &css,
+		IsPublic:     true,
+		Category:     &category,
+		Tags:         []string{"minimal"},
+		Version:      "2.3.0",
+	})
+	require.NoError(t, err)
+	env.setSettings(t, nil, true)
+
+	router := gin.New()
+	router.GET("/themes/:id/export", authMiddleware(env.user.ID), env.handler.ExportTheme)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/themes/%d/export", source.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var bundle map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bundle))
+	assert.EqualValues(t, 1, bundle["schema_version"])
+	for _, field := range []string{"id", "user_id", "is_public", "install_count", "created_at"} {
+		assert.NotContains(t, bundle, field)
+	}
+
+	w = performThemeImport(t, env.handler, env.user.ID, bundle)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var imported models.UserTheme
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &imported))
+	assert.NotEqual(t, source.ID, imported.ID)
+	assert.Equal(t, env.user.ID, imported.UserID)
+	assert.False(t, imported.IsPublic)
+	assert.Equal(t, source.ThemeName, imported.ThemeName)
+	assert.Equal(t, source.ThemeType, imported.ThemeType)
+	assert.Equal(t, source.CSSVariables, imported.CSSVariables)
+	require.NotNil(t, imported.CustomCSS)
+	assert.Equal(t, css, *imported.CustomCSS)
+	assert.Equal(t, []string{"minimal"}, imported.Tags)
+
+	// Private themes the caller cannot use are not exportable
+	private, err := env.themeRepo.Create(context.Background(), &models.UserTheme{
+		UserID: env.other.ID, ThemeName: "Secret", ThemeType: "full_css", ScopeType: "global", Version: "1.0.0",
+	})
+	require.NoError(t, err)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/themes/%d/export", private.ID), nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestImportTheme_RejectsUnsafeOrUnsupportedBundles(t *testing.T) {
+	handler := NewThemesHandler(nil, nil, nil, nil, services.NewCSSSanitizer())
+	gin.SetMode(gin.TestMode)
+
+	bundle := func() map[string]interface{} {
+		return map[string]interface{}{
+			"schema_version": 1,
+			"theme_name":     "Imported",
+			"theme_type":     "full_css",
+			"scope_type":     "global",
+		}
+	}
+
+	unsafeCSS := bundle()
+	unsafeCSS["custom_css"] = ".a { background: url(javascript:alert(1)); }"
+	w := performThemeImport(t, handler, 1, unsafeCSS)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "CSS validation failed")
+
+	unsafeVariable := bundle()
+	unsafeVariable["css_variables"] = map[string]interface{}{"--bg": "expression(alert(1))"}
+	w = performThemeImport(t, handler, 1, unsafeVariable)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "CSS validation failed")
+
+	future := bundle()
+	future["schema_version"] = 2
+	w = performThemeImport(t, handler, 1, future)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Unsupported schema_version")
+}
+
+func (env *themeResolveTestEnv) sendTheme(t *testing.T, method, path string, body map[string]interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.POST("/themes", authMiddleware(env.user.ID), env.handler.CreateTheme)
+	router.PUT("/themes/:id", authMiddleware(env.user.ID), env.handler.UpdateTheme)
+
+	payload, err := json.Marshal(body)
+	require.NoError(t, err)
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func fullCSSThemeBody() map[string]interface{} {
+	return map[string]interface{}{
+		"theme_name": "Full CSS",
+		"theme_type": "full_css",
+		"scope_type": "global",
+		"custom_css": ".header { color: red; }",
+	}
+}
+
+func TestCreateTheme_FullCSSAllowedInAdvancedMode(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	env.setSettings(t, nil, true)
+
+	w := env.sendTheme(t, http.MethodPost, "/themes", fullCSSThemeBody())
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestCreateTheme_FullCSSRequiresAdvancedMode(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	// No settings row yet counts as advanced mode off
+	w := env.sendTheme(t, http.MethodPost, "/themes", fullCSSThemeBody())
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	env.setSettings(t, nil, false)
+	w = env.sendTheme(t, http.MethodPost, "/themes", fullCSSThemeBody())
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	// Existing full_css themes can't be edited once advanced mode is off
+	existing := env.createTheme(t, env.user, "legacy")
+	w = env.sendTheme(t, http.MethodPut, fmt.Sprintf("/themes/%d", existing.ID), map[string]interface{}{"custom_css": ".a { color: blue; }"})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+}
+
+func TestCreateTheme_VariableCustomizationAlwaysAllowed(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	env.setSettings(t, nil, false)
+
+	w := env.sendTheme(t, http.MethodPost, "/themes", map[string]interface{}{
+		"theme_name":    "Variables only",
+		"theme_type":    "variable_customization",
+		"scope_type":    "global",
+		"css_variables": map[string]interface{}{"--primary": "#123456"},
+	})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+}
+
+func TestCreateTheme_CustomCSSRequiresAdvancedMode(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	env.setSettings(t, nil, false)
+
+	w := env.sendTheme(t, http.MethodPost, "/themes", map[string]interface{}{
+		"theme_name":    "Variables plus CSS",
+		"theme_type":    "variable_customization",
+		"scope_type":    "global",
+		"css_variables": map[string]interface{}{"--primary": "#123456"},
+		"custom_css":    ".a { color: blue; }",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	// Adding custom CSS to an existing variables theme is gated the same way
+	w = env.sendTheme(t, http.MethodPost, "/themes", map[string]interface{}{
+		"theme_name":    "Variables only",
+		"theme_type":    "variable_customization",
+		"scope_type":    "global",
+		"css_variables": map[string]interface{}{"--primary": "#123456"},
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created models.UserTheme
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	w = env.sendTheme(t, http.MethodPut, fmt.Sprintf("/themes/%d", created.ID), map[string]interface{}{"custom_css": ".a { color: blue; }"})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+
+	w = env.sendTheme(t, http.MethodPut, fmt.Sprintf("/themes/%d", created.ID), map[string]interface{}{"theme_name": "Renamed"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+// rateAsNewUsers installs theme for one new user per rating and records it.
+func (env *themeResolveTestEnv) rateAsNewUsers(t *testing.T, theme *models.UserTheme, ratings ...int) {
+	t.Helper()
+	ctx := context.Background()
+	for i, rating := range ratings {
+		rater := &models.User{Username: fmt.Sprintf("rater_%d_%d_%d", theme.ID, i, time.Now().UnixNano()), PasswordHash: "hash"}
+		require.NoError(t, env.userRepo.Create(ctx, rater))
+		_, err := env.installedRepo.Install(ctx, rater.ID, theme.ID, 0)
+		require.NoError(t, err)
+		review := fmt.Sprintf("review %d", i)
+		_, err = env.installedRepo.RateTheme(ctx, rater.ID, theme.ID, rating, &review)
+		require.NoError(t, err)
+	}
+}
+
+func TestThemeRatings_SummaryAndReviews(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	theme := env.createTheme(t, env.other, "rated")
+	env.rateAsNewUsers(t, theme, 5, 4, 2)
+
+	// An install without a rating doesn't count
+	_, err := env.installedRepo.Install(ctx, env.user.ID, theme.ID, 0)
+	require.NoError(t, err)
+
+	avg, count, err := env.installedRepo.GetRatingSummary(ctx, theme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+	assert.InDelta(t, 11.0/3.0, avg, 0.0001)
+
+	router := gin.New()
+	router.GET("/themes/:id", authMiddleware(env.user.ID), env.handler.GetTheme)
+	router.GET("/themes/:id/reviews", authMiddleware(env.user.ID), env.handler.GetThemeReviews)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/themes/%d", theme.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var fetched models.UserTheme
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &fetched))
+	assert.Equal(t, 3, fetched.RatingCount)
+	assert.InDelta(t, 11.0/3.0, fetched.AverageRating, 0.0001)
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, fmt.Sprintf("/themes/%d/reviews?limit=2", theme.ID), nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		Reviews     []models.ThemeReview `json:"reviews"`
+		RatingCount int                  `json:"rating_count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 3, response.RatingCount)
+	require.Len(t, response.Reviews, 2)
+	for _, review := range response.Reviews {
+		assert.NotEmpty(t, review.Username)
+		require.NotNil(t, review.Review)
+	}
+}
+
+func TestBrowseThemes_TopRated(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	category := fmt.Sprintf("toprated_%d", time.Now().UnixNano())
+	create := func(name string) *models.UserTheme {
+		theme, err := env.themeRepo.Create(ctx, &models.UserTheme{
+			UserID:       env.other.ID,
+			ThemeName:    name,
+			ThemeType:    "variable_customization",
+			ScopeType:    "global",
+			CSSVariables: map[string]interface{}{"--primary": "#123456"},
+			IsPublic:     true,
+			Category:     &category,
+			Version:      "1.0.0",
+		})
+		require.NoError(t, err)
+		return theme
+	}
+	popular := create("popular but mediocre")
+	best := create("best")
+	good := create("good")
+	env.rateAsNewUsers(t, popular, 2, 3, 2, 3)
+	env.rateAsNewUsers(t, best, 5, 5)
+	env.rateAsNewUsers(t, good, 4)
+
+	router := gin.New()
+	router.GET("/themes/browse", authMiddleware(env.user.ID), env.handler.BrowseThemes)
+	browse := func(query string) []int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/browse?category="+category+query, nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Themes []models.UserTheme `json:"themes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		ids := make([]int, 0, len(response.Themes))
+		for _, theme := range response.Themes {
+			ids = append(ids, theme.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []int{popular.ID, best.ID, good.ID}, browse(""))
+	assert.Equal(t, []int{best.ID, good.ID, popular.ID}, browse("&sort=top_rated"))
+
+	// Sorting uses the same live ratings as GetTheme, so an uninstalled
+	// rating stops counting
+	_, err := env.installedRepo.Install(ctx, env.user.ID, good.ID, 0)
+	require.NoError(t, err)
+	_, err = env.installedRepo.RateTheme(ctx, env.user.ID, good.ID, 1, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []int{best.ID, popular.ID, good.ID}, browse("&sort=top_rated"))
+	require.NoError(t, env.installedRepo.Uninstall(ctx, env.user.ID, good.ID))
+	assert.Equal(t, []int{best.ID, good.ID, popular.ID}, browse("&sort=top_rated"))
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/browse?sort=newest", nil))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRateTheme_RerateUpdatesExistingRating(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	theme := env.createTheme(t, env.other, "rerated")
+	env.rateAsNewUsers(t, theme, 3)
+
+	router := gin.New()
+	router.POST("/themes/rate", authMiddleware(env.user.ID), env.handler.RateTheme)
+	rate := func(rating int, review string) *httptest.ResponseRecorder {
+		payload, err := json.Marshal(map[string]interface{}{"theme_id": theme.ID, "rating": rating, "review": review})
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPost, "/themes/rate", bytes.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Rating requires an install and must not create one
+	w := rate(3, "fine")
+	require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+	installed, err := env.installedRepo.GetInstalledTheme(ctx, env.user.ID, theme.ID)
+	require.NoError(t, err)
+	assert.Nil(t, installed)
+	stored, err := env.themeRepo.GetByID(ctx, theme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stored.InstallCount)
+
+	_, err = env.installedRepo.Install(ctx, env.user.ID, theme.ID, 0)
+	require.NoError(t, err)
+	w = rate(3, "fine")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	avg, count, err := env.installedRepo.GetRatingSummary(ctx, theme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.InDelta(t, 3.0, avg, 0.0001)
+
+	w = rate(5, "grew on me")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Contains(t, w.Body.String(), `"created":false`)
+
+	installed, err = env.installedRepo.GetInstalledTheme(ctx, env.user.ID, theme.ID)
+	require.NoError(t, err)
+	require.NotNil(t, installed)
+	require.NotNil(t, installed.UserRating)
+	assert.Equal(t, 5, *installed.UserRating)
+	require.NotNil(t, installed.Review)
+	assert.Equal(t, "grew on me", *installed.Review)
+
+	reviews, err := env.installedRepo.GetReviews(ctx, theme.ID, 10, 0)
+	require.NoError(t, err)
+	rows := 0
+	for _, review := range reviews {
+		if review.UserID == env.user.ID {
+			rows++
+		}
+	}
+	assert.Equal(t, 1, rows, "re-rating must not add a second row")
+
+	avg, count, err = env.installedRepo.GetRatingSummary(ctx, theme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.InDelta(t, 4.0, avg, 0.0001)
+
+	stored, err = env.themeRepo.GetByID(ctx, theme.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 4.0, stored.AverageRating, 0.0001, "the theme's average follows the update")
+}
+
+func TestUpdateTheme_BumpsVersionWhenStyleChanges(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	theme, err := env.themeRepo.Create(ctx, &models.UserTheme{
+		UserID:       env.user.ID,
+		ThemeName:    "versioned",
+		ThemeType:    "variable_customization",
+		ScopeType:    "global",
+		CSSVariables: map[string]interface{}{"--primary": "#123456"},
+		Version:      "1.0.0",
+	})
+	require.NoError(t, err)
+	path := fmt.Sprintf("/themes/%d", theme.ID)
+
+	// A description-only edit keeps the version
+	w := env.sendTheme(t, http.MethodPut, path, map[string]interface{}{"theme_description": "calmer"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	// So does resending identical variables
+	w = env.sendTheme(t, http.MethodPut, path, map[string]interface{}{"css_variables": map[string]interface{}{"--primary": "#123456"}})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	stored, err := env.themeRepo.GetByID(ctx, theme.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", stored.Version)
+
+	w = env.sendTheme(t, http.MethodPut, path, map[string]interface{}{
+		"css_variables": map[string]interface{}{"--primary": "#654321"},
+		"changelog":     "Darker primary color",
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated models.UserTheme
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.Equal(t, "1.0.1", updated.Version)
+
+	router := gin.New()
+	router.GET("/themes/:id/versions", authMiddleware(env.user.ID), env.handler.GetThemeVersions)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path+"/versions", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var response struct {
+		CurrentVersion string                `json:"current_version"`
+		Versions       []models.ThemeVersion `json:"versions"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "1.0.1", response.CurrentVersion)
+	require.Len(t, response.Versions, 1)
+	assert.Equal(t, "1.0.1", response.Versions[0].Version)
+	require.NotNil(t, response.Versions[0].Changelog)
+	assert.Equal(t, "Darker primary color", *response.Versions[0].Changelog)
+}
+
+func TestGetInstalledThemes_ReportsAvailableUpdates(t *testing.T) {
+	env, cleanup := setupThemeResolveTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	theme := env.createTheme(t, env.other, "evolving")
+	_, err := env.installedRepo.Install(ctx, env.user.ID, theme.ID, 0)
+	require.NoError(t, err)
+
+	router := gin.New()
+	router.GET("/themes/installed", authMiddleware(env.user.ID), env.handler.GetInstalledThemes)
+	installed := func() models.UserInstalledTheme {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/themes/installed", nil))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Themes []models.UserInstalledTheme `json:"themes"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Themes, 1)
+		return response.Themes[0]
+	}
+
+	item := installed()
+	require.NotNil(t, item.InstalledVersion)
+	assert.Equal(t, "1.0.0", *item.InstalledVersion)
+	assert.Equal(t, "1.0.0", item.CurrentVersion)
+	assert.False(t, item.UpdateAvailable)
+
+	_, err = env.themeRepo.BumpVersion(ctx, theme.ID, nil)
+	require.NoError(t, err)
+
+	item = installed()
+	assert.Equal(t, "1.0.0", *item.InstalledVersion)
+	assert.Equal(t, "1.0.1", item.CurrentVersion)
+	assert.True(t, item.UpdateAvailable)
+
+	// Reinstalling picks up the new version
+	_, err = env.installedRepo.Install(ctx, env.user.ID, theme.ID, 0)
+	require.NoError(t, err)
+	item = installed()
+	assert.Equal(t, "1.0.1", *item.InstalledVersion)
+	assert.False(t, item.UpdateAvailable)
+}
+
+func TestPairPredefinedThemes_GroupsLightAndDark(t *testing.T) {
+	intPtr := func(i int) *int { return &i }
+	themes := []*models.UserTheme{
+		{ID: 1, PairedThemeID: intPtr(2), CSSVariables: map[string]interface{}{"color-background": "#0f172a"}},
+		{ID: 2, PairedThemeID: intPtr(1), CSSVariables: map[string]interface{}{"color-background": "#ffffff"}},
+		{ID: 3},
+		// Partner isn't predefined, so there is nothing to group
+		{ID: 4, PairedThemeID: intPtr(99)},
+	}
+
+	assert.Equal(t, []themePair{{LightThemeID: 2, DarkThemeID: 1}}, pairPredefinedThemes(themes))
+}
@@ -126,7 +126,7 @@ func (h *UsersHandler) GetUserPosts(c *gin.Context) {
 		limit = 20
 	}
 
-	posts, err := h.postRepo.GetByAuthor(c.Request.Context(), user.ID, limit, offset)
+	posts, err := h.postRepo.GetByAuthor(c.Request.Context(), user.ID, optionalViewerID(c), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch posts", "details": err.Error()})
 		return
@@ -159,7 +159,7 @@ func (h *UsersHandler) GetUserComments(c *gin.Context) {
 		limit = 20
 	}
 
-	comments, err := h.commentRepo.GetByUserID(c.Request.Context(), user.ID, limit, offset)
+	comments, err := h.commentRepo.GetByUserID(c.Request.Context(), user.ID, optionalViewerID(c), limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch comments", "details": err.Error()})
 		return
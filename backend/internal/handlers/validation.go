@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+func init() {
+	// Report validation failures using JSON field names instead of Go struct field names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// respondBindingError writes a 400 response for a failed ShouldBindJSON call.
+// The top-level "error" message is kept for existing clients, while "fields"
+// maps each offending JSON field to a human-readable message.
+func respondBindingError(c *gin.Context, message string, err error) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":  message,
+		"fields": bindingErrorFields(err),
+	})
+}
+
+// bindingErrorFields translates gin/validator binding errors into a per-field error map
+func bindingErrorFields(err error) map[string]string {
+	fields := map[string]string{}
+
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		for _, fe := range validationErrs {
+			fields[fe.Field()] = validationMessage(fe)
+		}
+	case errors.As(err, &typeErr):
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		fields[field] = fmt.Sprintf("%s must be of type %s", field, jsonTypeName(typeErr.Type))
+	case errors.As(err, &syntaxErr):
+		fields["body"] = "Request body is not valid JSON"
+	case errors.Is(err, io.EOF):
+		fields["body"] = "Request body is required"
+	default:
+		fields["body"] = "Request body could not be parsed"
+	}
+
+	return fields
+}
+
+// validationMessage returns a friendly message for a single validator failure
+func validationMessage(fe validator.FieldError) string {
+	field := fe.Field()
+	isString := fe.Kind() == reflect.String
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", field)
+	case "min":
+		if isString {
+			return fmt.Sprintf("%s must be at least %s characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at least %s", field, fe.Param())
+	case "max":
+		if isString {
+			return fmt.Sprintf("%s must be at most %s characters", field, fe.Param())
+		}
+		return fmt.Sprintf("%s must be at most %s", field, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "email":
+		return fmt.Sprintf("%s must be a valid email address", field)
+	default:
+		return fmt.Sprintf("%s is invalid", field)
+	}
+}
+
+// jsonTypeName maps a Go type to the JSON type name clients expect
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Ptr:
+		return jsonTypeName(t.Elem())
+	default:
+		return t.Kind().String()
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func performBindingRequest(t *testing.T, handler gin.HandlerFunc, body string) (int, map[string]interface{}) {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/test", authMiddleware(1), handler)
+
+	req := httptest.NewRequest(http.MethodPost, "/test", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	return w.Code, response
+}
+
+func TestCreatePost_MissingTitle_ReturnsFieldErrors(t *testing.T) {
+	handler := NewPostsHandler(nil, nil, nil, nil, nil)
+
+	code, response := performBindingRequest(t, handler.CreatePost, `{"body": "no title", "hub_id": 1}`)
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, response["error"], "Invalid")
+
+	fields, ok := response["fields"].(map[string]interface{})
+	require.True(t, ok, "expected a fields map in the response")
+	assert.Equal(t, "title is required", fields["title"])
+	assert.NotContains(t, fields, "Title")
+	assert.NotContains(t, response, "details")
+}
+
+func TestCreatePost_TitleTooLong_ReturnsFieldErrors(t *testing.T) {
+	handler := NewPostsHandler(nil, nil, nil, nil, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":  string(bytes.Repeat([]byte("a"), 301)),
+		"hub_id": 1,
+	})
+	code, response := performBindingRequest(t, handler.CreatePost, string(body))
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	fields := response["fields"].(map[string]interface{})
+	assert.Equal(t, "title must be at most 300 characters", fields["title"])
+}
+
+func TestCreateHub_WrongType_ReturnsFieldErrors(t *testing.T) {
+	handler := NewHubsHandler(nil, nil, nil, nil)
+
+	code, response := performBindingRequest(t, handler.Create, `{"name": 42}`)
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Contains(t, response["error"], "Invalid")
+	fields := response["fields"].(map[string]interface{})
+	assert.Equal(t, "name must be of type string", fields["name"])
+}
+
+func TestCreateConversation_MalformedJSON_ReturnsBodyError(t *testing.T) {
+	handler := NewConversationsHandler(nil, nil, nil)
+
+	code, response := performBindingRequest(t, handler.CreateConversation, `{"other_user_id":`)
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	fields := response["fields"].(map[string]interface{})
+	assert.Contains(t, fields, "body")
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type voteTestEnv struct {
+	userRepo  *models.UserRepository
+	router    *gin.Engine
+	voterID   int
+	postID    int
+	commentID int
+	suffix    int64
+}
+
+type voteResponse struct {
+	Score     int  `json:"score"`
+	Upvotes   int  `json:"upvotes"`
+	Downvotes int  `json:"downvotes"`
+	UserVote  *int `json:"user_vote"`
+}
+
+func setupVoteTest(t *testing.T) (*voteTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	commentRepo := models.NewPostCommentRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	voter := &models.User{Username: fmt.Sprintf("voter_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, voter))
+
+	hub := &models.Hub{Name: fmt.Sprintf("votehub_%d", suffix), CreatedBy: &voter.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	post := &models.PlatformPost{AuthorID: voter.ID, HubID: &hub.ID, Title: "Vote on me"}
+	require.NoError(t, postRepo.Create(ctx, post))
+	comment := &models.PostComment{PostID: post.ID, UserID: voter.ID, Body: "and me"}
+	require.NoError(t, commentRepo.Create(ctx, comment))
+
+	postsHandler := NewPostsHandler(postRepo, hubRepo, userRepo, nil, nil)
+	commentsHandler := NewCommentsHandler(commentRepo, postRepo, nil)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// Tests pick the voter per request through the X-Test-User header
+	router.Use(func(c *gin.Context) {
+		var id int
+		fmt.Sscan(c.GetHeader("X-Test-User"), &id)
+		c.Set("user_id", id)
+		c.Next()
+	})
+	router.POST("/posts/:id/vote", postsHandler.VotePost)
+	router.POST("/comments/:id/vote", commentsHandler.VoteComment)
+
+	env := &voteTestEnv{userRepo: userRepo, router: router, voterID: voter.ID, postID: post.ID, commentID: comment.ID, suffix: suffix}
+	return env, func() { db.Close() }
+}
+
+func (env *voteTestEnv) vote(t *testing.T, path string, userID int, isUpvote *bool) (int, voteResponse) {
+	body, err := json.Marshal(map[string]*bool{"is_upvote": isUpvote})
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Test-User", fmt.Sprint(userID))
+	w := httptest.NewRecorder()
+	env.router.ServeHTTP(w, req)
+
+	var response voteResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	}
+	return w.Code, response
+}
+
+func TestVote_TransitionsReturnAuthoritativeState(t *testing.T) {
+	env, cleanup := setupVoteTest(t)
+	defer cleanup()
+
+	up, down := true, false
+	steps := []struct {
+		name      string
+		isUpvote  *bool
+		score     int
+		upvotes   int
+		downvotes int
+		userVote  int
+	}{
+		{"new upvote", &up, 1, 1, 0, 1},
+		{"repeat upvote", &up, 1, 1, 0, 1},
+		{"toggle to down", &down, -1, 0, 1, -1},
+		{"remove", nil, 0, 0, 0, 0},
+		{"remove again", nil, 0, 0, 0, 0},
+	}
+
+	for _, path := range []string{
+		fmt.Sprintf("/posts/%d/vote", env.postID),
+		fmt.Sprintf("/comments/%d/vote", env.commentID),
+	} {
+		for _, step := range steps {
+			code, res := env.vote(t, path, env.voterID, step.isUpvote)
+			require.Equal(t, http.StatusOK, code, "%s %s", path, step.name)
+			assert.Equal(t, step.score, res.Score, "%s %s score", path, step.name)
+			assert.Equal(t, step.upvotes, res.Upvotes, "%s %s upvotes", path, step.name)
+			assert.Equal(t, step.downvotes, res.Downvotes, "%s %s downvotes", path, step.name)
+			require.NotNil(t, res.UserVote, "%s %s", path, step.name)
+			assert.Equal(t, step.userVote, *res.UserVote, "%s %s user_vote", path, step.name)
+		}
+	}
+}
+
+func TestVote_ConcurrentVotesStayConsistent(t *testing.T) {
+	env, cleanup := setupVoteTest(t)
+	defer cleanup()
+
+	const voters = 8
+	ids := make([]int, voters)
+	for i := range ids {
+		user := &models.User{Username: fmt.Sprintf("crowd_%d_%d", i, env.suffix), PasswordHash: "hash"}
+		require.NoError(t, env.userRepo.Create(context.Background(), user))
+		ids[i] = user.ID
+	}
+
+	up := true
+	path := fmt.Sprintf("/posts/%d/vote", env.postID)
+	var wg sync.WaitGroup
+	codes := make([]int, voters*2)
+	for i, id := range ids {
+		// Each voter double-submits, as a client retrying an optimistic vote would
+		for j := 0; j < 2; j++ {
+			wg.Add(1)
+			go func(slot, userID int) {
+				defer wg.Done()
+				codes[slot], _ = env.vote(t, path, userID, &up)
+			}(i*2+j, id)
+		}
+	}
+	wg.Wait()
+
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	// One more no-op vote reads back the settled state
+	_, res := env.vote(t, path, ids[0], &up)
+	assert.Equal(t, voters, res.Upvotes)
+	assert.Equal(t, voters, res.Score)
+}
+
+func TestVote_MissingTargetReturnsNotFound(t *testing.T) {
+	env, cleanup := setupVoteTest(t)
+	defer cleanup()
+
+	up := true
+	code, _ := env.vote(t, "/posts/999999999/vote", env.voterID, &up)
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = env.vote(t, "/comments/999999999/vote", env.voterID, &up)
+	assert.Equal(t, http.StatusNotFound, code)
+}
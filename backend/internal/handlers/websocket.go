@@ -3,63 +3,42 @@ package handlers
 import (
 	"log"
 	"net/http"
-	"net/url"
-	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	ws "github.com/gorilla/websocket"
 	"github.com/omninudge/backend/internal/websocket"
 )
 
-var allowedDevPorts = map[string]struct{}{
-	"":     {},
-	"80":   {},
-	"3000": {},
-	"5173": {},
-	"5174": {},
-	"5175": {},
-	"5176": {},
-	"8080": {},
-}
-
-var upgrader = ws.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			return true
-		}
-
-		parsed, err := url.Parse(origin)
-		if err != nil {
-			return false
-		}
-
-		host := strings.ToLower(parsed.Hostname())
-		if host == "localhost" || host == "127.0.0.1" || host == "::1" {
-			if _, ok := allowedDevPorts[parsed.Port()]; ok {
-				return true
-			}
-		}
-
-		return false
-	},
-}
-
 // WebSocketHandler handles WebSocket connections
 type WebSocketHandler struct {
-	hub *websocket.Hub
+	hub          *websocket.Hub
+	upgrader     ws.Upgrader
+	helloTimeout time.Duration
 }
 
-// NewWebSocketHandler creates a new WebSocket handler
-func NewWebSocketHandler(hub *websocket.Hub) *WebSocketHandler {
+// NewWebSocketHandler creates a new WebSocket handler. Browsers may only connect
+// from the same origin or one of allowedOrigins.
+func NewWebSocketHandler(hub *websocket.Hub, allowedOrigins []string) *WebSocketHandler {
 	return &WebSocketHandler{
 		hub: hub,
+		upgrader: ws.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     websocket.NewOriginChecker(allowedOrigins),
+		},
+		helloTimeout: websocket.DefaultHelloTimeout,
 	}
 }
 
-// HandleWebSocket handles WebSocket upgrade requests
+// SetHelloTimeout changes how long new connections have to send their hello frame
+func (h *WebSocketHandler) SetHelloTimeout(timeout time.Duration) {
+	h.helloTimeout = timeout
+}
+
+// HandleWebSocket handles WebSocket upgrade requests. The client must send
+// {"type":"hello"} as its first frame; it is then registered with the hub and
+// answered with a hello_ack.
 func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	// Get user ID from context (set by AuthRequired middleware)
 	userID, exists := c.Get("user_id")
@@ -69,12 +48,17 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 	}
 
 	// Upgrade HTTP connection to WebSocket
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		log.Printf("Failed to upgrade WebSocket: %v", err)
 		return
 	}
 
+	if err := websocket.AwaitHello(conn, h.helloTimeout); err != nil {
+		log.Printf("Closing WebSocket for user_id=%v without hello: %v", userID, err)
+		return
+	}
+
 	// Create new client
 	client := &websocket.Client{
 		UserID: userID.(int),
@@ -83,6 +67,10 @@ func (h *WebSocketHandler) HandleWebSocket(c *gin.Context) {
 		Hub:    h.hub,
 	}
 
+	// Queue the hello acknowledgement; it is only written once the client is
+	// registered, so the client knows it will receive broadcasts from then on
+	client.Send <- &websocket.Message{RecipientID: client.UserID, Type: "hello_ack"}
+
 	// Register client with hub
 	h.hub.Register(client)
 
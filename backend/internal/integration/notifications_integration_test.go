@@ -55,6 +55,7 @@ func setupNotificationIntegrationTest(t *testing.T) (*gin.Engine, *database.Data
 		settingsRepo,
 		postRepo,
 		commentRepo,
+		userRepo,
 		hub,
 	)
 
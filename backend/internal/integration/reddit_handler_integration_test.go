@@ -32,6 +32,11 @@ func (m *mapCache) Set(_ context.Context, key string, value string, ttl time.Dur
 	return nil
 }
 
+func (m *mapCache) Delete(_ context.Context, key string) error {
+	delete(m.store, key)
+	return nil
+}
+
 // stubTransport returns a canned Reddit listing and tracks hits
 type stubTransport struct {
 	hits *int32
@@ -60,7 +65,8 @@ func (t *stubTransport) RoundTrip(_ *http.Request) (*http.Response, error) {
 func TestRedditFrontpageMockCaching(t *testing.T) {
 	var hits int32
 	cache := &mapCache{store: make(map[string]string)}
-	client := services.NewRedditClient("ua", cache, 5*time.Minute, "", "")
+	client, err := services.NewRedditClient("ua", cache, 5*time.Minute, "", "")
+	require.NoError(t, err)
 	client.HTTPClientForTest().Transport = &stubTransport{hits: &hits}
 
 	gin.SetMode(gin.TestMode)
@@ -111,10 +111,9 @@ func newTestDeps(t *testing.T) *TestDeps {
 	authHandler := handlers.NewAuthHandler(authService, userRepo)
 	postsHandler := handlers.NewPostsHandler(postRepo, hubRepo, userRepo, modRepo, feedRepo)
 	commentsHandler := handlers.NewCommentsHandler(commentRepo, postRepo, modRepo)
-	redditHandler := handlers.NewRedditHandler(
-		services.NewRedditClient(cfg.Reddit.UserAgent, services.NoopCache{}, 0, cfg.Reddit.ClientID, cfg.Reddit.ClientSecret),
-		redditPostRepo,
-	)
+	redditClient, err := services.NewRedditClient(cfg.Reddit.UserAgent, services.NoopCache{}, 0, cfg.Reddit.ClientID, cfg.Reddit.ClientSecret)
+	require.NoError(t, err)
+	redditHandler := handlers.NewRedditHandler(redditClient, redditPostRepo)
 	conversationsHandler := handlers.NewConversationsHandler(conversationRepo, messageRepo, userRepo)
 	messagesHandler := handlers.NewMessagesHandler(db.Pool, messageRepo, conversationRepo, hub)
 	usersHandler := handlers.NewUsersHandler(userRepo, postRepo, commentRepo, nil, modRepo)
@@ -124,7 +123,7 @@ func newTestDeps(t *testing.T) *TestDeps {
 	hubsHandler := handlers.NewHubsHandler(hubRepo, postRepo, modRepo, hubSubRepo)
 	moderationHandler := handlers.NewModerationHandler(reportRepo, modRepo)
 	adminHandler := handlers.NewAdminHandler(userRepo, modRepo, db.Pool)
-	wsHandler := handlers.NewWebSocketHandler(hub)
+	wsHandler := handlers.NewWebSocketHandler(hub, cfg.CORS.AllowedOrigins)
 
 	router := gin.New()
 	router.Use(gin.Recovery())
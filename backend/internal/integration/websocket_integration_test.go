@@ -42,9 +42,14 @@ func TestWebSocketTypingBroadcast(t *testing.T) {
 		wsURL := "ws" + ts.URL[len("http"):] + "/api/v1/ws"
 		h := http.Header{}
 		h.Set("Authorization", "Bearer "+token)
-		h.Set("Origin", "http://localhost:8080")
+		h.Set("Origin", "http://localhost:5173")
 		conn, _, err := websocket.DefaultDialer.Dial(wsURL, h)
 		require.NoError(t, err)
+		require.NoError(t, conn.WriteJSON(map[string]string{"type": "hello"}))
+		var ack map[string]interface{}
+		conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		require.NoError(t, conn.ReadJSON(&ack))
+		require.Equal(t, "hello_ack", ack["type"])
 		return conn
 	}
 
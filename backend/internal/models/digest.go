@@ -0,0 +1,208 @@
+package models
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/omninudge/backend/internal/utils"
+)
+
+// DigestRecipient is a user eligible for a digest email, with the preferences
+// needed to build and schedule it.
+type DigestRecipient struct {
+	UserID           int
+	Username         string
+	Email            string
+	LastSeen         time.Time
+	LastDigestSentAt *time.Time
+	QuietHoursStart  *int
+	QuietHoursEnd    *int
+
+	NotifyCommentReplies   bool
+	NotifyPostMilestone    bool
+	NotifyPostVelocity     bool
+	NotifyCommentMilestone bool
+	NotifyCommentVelocity  bool
+}
+
+// DigestPost is a post summarized in a digest email.
+type DigestPost struct {
+	ID          int
+	Title       string
+	HubName     string
+	Score       int
+	NumComments int
+}
+
+// DigestRepository handles the queries behind digest emails.
+type DigestRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDigestRepository creates a new digest repository
+func NewDigestRepository(pool *pgxpool.Pool) *DigestRepository {
+	return &DigestRepository{pool: pool}
+}
+
+// ListRecipients returns users who opted into digests, have an email address,
+// haven't been seen since inactiveSince, and haven't had a digest since sentBefore.
+// Results are ordered by user ID and start after afterID, so callers can page
+// past users they skipped without marking.
+func (r *DigestRepository) ListRecipients(ctx context.Context, inactiveSince, sentBefore time.Time, afterID, limit int) ([]*DigestRecipient, error) {
+	query := `
+		SELECT u.id, u.username, u.email, u.email_encrypted, u.last_seen,
+		       s.last_digest_sent_at, s.quiet_hours_start, s.quiet_hours_end,
+		       s.notify_comment_replies, s.notify_post_milestone, s.notify_post_velocity,
+		       s.notify_comment_milestone, s.notify_comment_velocity
+		FROM users u
+		JOIN user_settings s ON s.user_id = u.id
+		WHERE s.daily_digest = TRUE
+		  AND u.email IS NOT NULL AND u.email <> ''
+		  AND u.last_seen < $1
+		  AND (s.last_digest_sent_at IS NULL OR s.last_digest_sent_at < $2)
+		  AND u.id > $3
+		ORDER BY u.id
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, inactiveSince, sentBefore, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []*DigestRecipient
+	for rows.Next() {
+		rec := &DigestRecipient{}
+		var email string
+		var encrypted bool
+		if err := rows.Scan(
+			&rec.UserID, &rec.Username, &email, &encrypted, &rec.LastSeen,
+			&rec.LastDigestSentAt, &rec.QuietHoursStart, &rec.QuietHoursEnd,
+			&rec.NotifyCommentReplies, &rec.NotifyPostMilestone, &rec.NotifyPostVelocity,
+			&rec.NotifyCommentMilestone, &rec.NotifyCommentVelocity,
+		); err != nil {
+			return nil, err
+		}
+		if encrypted {
+			if email, err = utils.DecryptEmail(email); err != nil {
+				return nil, err
+			}
+		}
+		rec.Email = email
+		recipients = append(recipients, rec)
+	}
+
+	return recipients, rows.Err()
+}
+
+// GetTopSubscribedPosts returns the highest scoring posts created since the given
+// time in hubs the user subscribes to.
+func (r *DigestRepository) GetTopSubscribedPosts(ctx context.Context, userID int, since time.Time, limit int) ([]*DigestPost, error) {
+	query := `
+		SELECT p.id, p.title, h.name, p.score, p.num_comments
+		FROM platform_posts p
+		JOIN hub_subscriptions hs ON hs.hub_id = p.hub_id AND hs.user_id = $1
+		JOIN hubs h ON h.id = p.hub_id
+		WHERE p.created_at >= $2
+		  AND p.is_deleted = FALSE AND p.is_removed = FALSE
+		ORDER BY p.score DESC, p.created_at DESC, p.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, since, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*DigestPost
+	for rows.Next() {
+		post := &DigestPost{}
+		if err := rows.Scan(&post.ID, &post.Title, &post.HubName, &post.Score, &post.NumComments); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// GetUnreadNotifications returns the user's newest unread notifications of the given types.
+func (r *DigestRepository) GetUnreadNotifications(ctx context.Context, userID int, types []string, limit int) ([]*Notification, error) {
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	query := `
+		SELECT id, user_id, notification_type, content_type, content_id,
+		       actor_id, milestone_count, votes_per_hour, message, read, created_at
+		FROM notifications
+		WHERE user_id = $1 AND read = FALSE AND notification_type = ANY($2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, types, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []*Notification
+	for rows.Next() {
+		n := &Notification{}
+		if err := rows.Scan(
+			&n.ID, &n.UserID, &n.NotificationType, &n.ContentType, &n.ContentID,
+			&n.ActorID, &n.MilestoneCount, &n.VotesPerHour, &n.Message, &n.Read, &n.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// EnsureUnsubscribeToken returns the user's digest unsubscribe token, creating one if needed.
+func (r *DigestRepository) EnsureUnsubscribeToken(ctx context.Context, userID int) (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	var token string
+	err := r.pool.QueryRow(ctx, `
+		UPDATE user_settings
+		SET digest_unsubscribe_token = COALESCE(digest_unsubscribe_token, $2)
+		WHERE user_id = $1
+		RETURNING digest_unsubscribe_token
+	`, userID, hex.EncodeToString(b)).Scan(&token)
+	return token, err
+}
+
+// MarkSent records that a digest was sent to the user.
+func (r *DigestRepository) MarkSent(ctx context.Context, userID int, sentAt time.Time) error {
+	_, err := r.pool.Exec(ctx, `UPDATE user_settings SET last_digest_sent_at = $2 WHERE user_id = $1`, userID, sentAt)
+	return err
+}
+
+// Unsubscribe turns off digests for the user owning token.
+// Returns false if the token is unknown.
+func (r *DigestRepository) Unsubscribe(ctx context.Context, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE user_settings
+		SET daily_digest = FALSE, updated_at = CURRENT_TIMESTAMP
+		WHERE digest_unsubscribe_token = $1
+	`, token)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
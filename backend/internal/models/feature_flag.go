@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// FeatureFlag is a named runtime toggle. When enabled, the flag applies to
+// RolloutPercentage of users plus everyone listed in UserIDs.
+type FeatureFlag struct {
+	Name              string    `json:"name"`
+	Description       *string   `json:"description,omitempty"`
+	Enabled           bool      `json:"enabled"`
+	RolloutPercentage int       `json:"rollout_percentage"`
+	UserIDs           []int32   `json:"user_ids"`
+	UpdatedBy         *int      `json:"updated_by,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+type FeatureFlagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFeatureFlagRepository(db *pgxpool.Pool) *FeatureFlagRepository {
+	return &FeatureFlagRepository{db: db}
+}
+
+const featureFlagColumns = `name, description, enabled, rollout_percentage, user_ids, updated_by, created_at, updated_at`
+
+func scanFeatureFlag(row pgx.Row) (*FeatureFlag, error) {
+	var flag FeatureFlag
+	err := row.Scan(
+		&flag.Name, &flag.Description, &flag.Enabled, &flag.RolloutPercentage,
+		&flag.UserIDs, &flag.UpdatedBy, &flag.CreatedAt, &flag.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if flag.UserIDs == nil {
+		flag.UserIDs = []int32{}
+	}
+	return &flag, nil
+}
+
+// List returns all feature flags ordered by name
+func (r *FeatureFlagRepository) List(ctx context.Context) ([]*FeatureFlag, error) {
+	rows, err := r.db.Query(ctx, `SELECT `+featureFlagColumns+` FROM feature_flags ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list feature flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []*FeatureFlag{}
+	for rows.Next() {
+		flag, err := scanFeatureFlag(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan feature flag: %w", err)
+		}
+		flags = append(flags, flag)
+	}
+	return flags, rows.Err()
+}
+
+// GetByName returns a single flag, or nil if it does not exist
+func (r *FeatureFlagRepository) GetByName(ctx context.Context, name string) (*FeatureFlag, error) {
+	flag, err := scanFeatureFlag(r.db.QueryRow(ctx, `SELECT `+featureFlagColumns+` FROM feature_flags WHERE name = $1`, name))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feature flag: %w", err)
+	}
+	return flag, nil
+}
+
+// Upsert creates or replaces a flag's settings
+func (r *FeatureFlagRepository) Upsert(ctx context.Context, flag *FeatureFlag) (*FeatureFlag, error) {
+	userIDs := flag.UserIDs
+	if userIDs == nil {
+		userIDs = []int32{}
+	}
+
+	query := `
+		INSERT INTO feature_flags (name, description, enabled, rollout_percentage, user_ids, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (name) DO UPDATE SET
+			description = EXCLUDED.description,
+			enabled = EXCLUDED.enabled,
+			rollout_percentage = EXCLUDED.rollout_percentage,
+			user_ids = EXCLUDED.user_ids,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = NOW()
+		RETURNING ` + featureFlagColumns
+
+	saved, err := scanFeatureFlag(r.db.QueryRow(ctx, query,
+		flag.Name, flag.Description, flag.Enabled, flag.RolloutPercentage, userIDs, flag.UpdatedBy,
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to save feature flag: %w", err)
+	}
+	return saved, nil
+}
+
+// Delete removes a flag
+func (r *FeatureFlagRepository) Delete(ctx context.Context, name string) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM feature_flags WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("feature flag %s not found", name)
+	}
+	return nil
+}
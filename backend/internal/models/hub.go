@@ -21,8 +21,17 @@ type Hub struct {
 	CreatedBy       *int       `json:"created_by,omitempty"`
 	CreatedAt       time.Time  `json:"created_at"`
 	NSFW            bool       `json:"nsfw"`
+	SidebarMD       *string    `json:"sidebar_md,omitempty"`       // Markdown shown in the hub sidebar
+	BannerURL       *string    `json:"banner_url,omitempty"`
+	MaxPinnedPosts  int        `json:"max_pinned_posts,omitempty"` // How many posts moderators can pin at once
 }
 
+// Bounds for Hub.MaxPinnedPosts, matching the hubs table constraint
+const (
+	MinHubPinnedPosts = 1
+	MaxHubPinnedPosts = 10
+)
+
 // HubRepository manages hubs
 type HubRepository struct {
 	pool *pgxpool.Pool
@@ -56,11 +65,13 @@ func (r *HubRepository) Create(ctx context.Context, h *Hub) error {
 func (r *HubRepository) GetByName(ctx context.Context, name string) (*Hub, error) {
 	h := &Hub{}
 	query := `
-		SELECT id, name, description, title, type, content_options, is_quarantined, subscriber_count, created_by, created_at, nsfw
+		SELECT id, name, description, title, type, content_options, is_quarantined, subscriber_count, created_by, created_at, nsfw,
+		       sidebar_md, banner_url, max_pinned_posts
 		FROM hubs
 		WHERE name = $1
 	`
-	err := r.pool.QueryRow(ctx, query, name).Scan(&h.ID, &h.Name, &h.Description, &h.Title, &h.Type, &h.ContentOptions, &h.IsQuarantined, &h.SubscriberCount, &h.CreatedBy, &h.CreatedAt, &h.NSFW)
+	err := r.pool.QueryRow(ctx, query, name).Scan(&h.ID, &h.Name, &h.Description, &h.Title, &h.Type, &h.ContentOptions, &h.IsQuarantined, &h.SubscriberCount, &h.CreatedBy, &h.CreatedAt, &h.NSFW,
+		&h.SidebarMD, &h.BannerURL, &h.MaxPinnedPosts)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -74,11 +85,13 @@ func (r *HubRepository) GetByName(ctx context.Context, name string) (*Hub, error
 func (r *HubRepository) GetByID(ctx context.Context, id int) (*Hub, error) {
 	h := &Hub{}
 	query := `
-		SELECT id, name, description, title, type, content_options, is_quarantined, subscriber_count, created_by, created_at, nsfw
+		SELECT id, name, description, title, type, content_options, is_quarantined, subscriber_count, created_by, created_at, nsfw,
+		       sidebar_md, banner_url, max_pinned_posts
 		FROM hubs
 		WHERE id = $1
 	`
-	err := r.pool.QueryRow(ctx, query, id).Scan(&h.ID, &h.Name, &h.Description, &h.Title, &h.Type, &h.ContentOptions, &h.IsQuarantined, &h.SubscriberCount, &h.CreatedBy, &h.CreatedAt, &h.NSFW)
+	err := r.pool.QueryRow(ctx, query, id).Scan(&h.ID, &h.Name, &h.Description, &h.Title, &h.Type, &h.ContentOptions, &h.IsQuarantined, &h.SubscriberCount, &h.CreatedBy, &h.CreatedAt, &h.NSFW,
+		&h.SidebarMD, &h.BannerURL, &h.MaxPinnedPosts)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -88,6 +101,27 @@ func (r *HubRepository) GetByID(ctx context.Context, id int) (*Hub, error) {
 	return h, nil
 }
 
+// IsAccessibleTo reports whether viewerID may read and post in the hub.
+// Hubs that don't exist report false.
+func (r *HubRepository) IsAccessibleTo(ctx context.Context, hubID int, viewerID *int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM hubs h WHERE h.id = $1 AND ` + hubAccessibleToViewerClause(2) + `)`
+
+	var accessible bool
+	err := r.pool.QueryRow(ctx, query, hubID, viewerID).Scan(&accessible)
+	return accessible, err
+}
+
+// Update saves a hub's editable settings. The name, type and owner can't be changed.
+func (r *HubRepository) Update(ctx context.Context, h *Hub) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE hubs
+		SET title = $2, description = $3, content_options = $4, sidebar_md = $5, banner_url = $6,
+		    max_pinned_posts = $7
+		WHERE id = $1
+	`, h.ID, h.Title, h.Description, h.ContentOptions, h.SidebarMD, h.BannerURL, h.MaxPinnedPosts)
+	return err
+}
+
 // List returns paginated hubs
 func (r *HubRepository) List(ctx context.Context, limit, offset int) ([]*Hub, error) {
 	query := `
@@ -166,6 +200,59 @@ func (r *HubRepository) SearchHubs(ctx context.Context, query string, limit int)
 	return hubs, rows.Err()
 }
 
+// RelatedHub is a hub suggested alongside another, with the number of
+// subscribers they have in common
+type RelatedHub struct {
+	Hub
+	SharedSubscribers int `json:"shared_subscribers"`
+}
+
+// GetRelatedHubs returns the public, unquarantined hubs most co-subscribed with
+// hubID, ordered by how many subscribers they share
+func (r *HubRepository) GetRelatedHubs(ctx context.Context, hubID, limit int, includeNSFW bool) ([]*RelatedHub, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT h.id, h.name, h.description, h.title, h.type, h.content_options, h.is_quarantined, h.subscriber_count, h.created_by, h.created_at, h.nsfw,
+		       COUNT(*) AS shared_subscribers
+		FROM hub_subscriptions mine
+		JOIN hub_subscriptions other ON other.user_id = mine.user_id AND other.hub_id <> mine.hub_id
+		JOIN hubs h ON h.id = other.hub_id
+		WHERE mine.hub_id = $1
+		AND h.type <> 'private'
+		AND h.is_quarantined = FALSE
+		AND (h.nsfw = FALSE OR $3 = TRUE)
+		GROUP BY h.id
+		ORDER BY shared_subscribers DESC, h.subscriber_count DESC, h.id ASC
+		LIMIT $2
+	`, hubID, limit, includeNSFW)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hubs []*RelatedHub
+	for rows.Next() {
+		h := &RelatedHub{}
+		if err := rows.Scan(&h.ID, &h.Name, &h.Description, &h.Title, &h.Type, &h.ContentOptions, &h.IsQuarantined, &h.SubscriberCount, &h.CreatedBy, &h.CreatedAt, &h.NSFW, &h.SharedSubscribers); err != nil {
+			return nil, err
+		}
+		hubs = append(hubs, h)
+	}
+	return hubs, rows.Err()
+}
+
+// RecountSubscribers recomputes a hub's subscriber_count from hub_subscriptions,
+// repairing any drift, and returns the corrected count
+func (r *HubRepository) RecountSubscribers(ctx context.Context, hubID int) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx, `
+		UPDATE hubs
+		SET subscriber_count = (SELECT COUNT(*) FROM hub_subscriptions WHERE hub_id = $1)
+		WHERE id = $1
+		RETURNING subscriber_count
+	`, hubID).Scan(&count)
+	return count, err
+}
+
 // GetTrendingHubs returns trending hubs
 // TODO: Implement growth rate algorithm based on subscriber growth over time
 // For now, just returns popular hubs
@@ -0,0 +1,243 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Automod rule actions and targets
+const (
+	AutomodActionRemove = "remove"
+
+	AutomodAppliesToPosts    = "posts"
+	AutomodAppliesToComments = "comments"
+	AutomodAppliesToAll      = "all"
+)
+
+var (
+	// ErrInvalidAutomodPattern is returned when a rule's pattern is empty or is not a valid regex
+	ErrInvalidAutomodPattern = errors.New("invalid automod pattern")
+	// ErrInvalidAutomodRule is returned when a rule's action or applies_to is not recognised
+	ErrInvalidAutomodRule = errors.New("invalid automod rule")
+)
+
+type AutomodRule struct {
+	ID        int       `json:"id"`
+	HubID     int       `json:"hub_id"`
+	Pattern   string    `json:"pattern"`
+	IsRegex   bool      `json:"is_regex"`
+	Action    string    `json:"action"`
+	AppliesTo string    `json:"applies_to"`
+	CreatedBy int       `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the rule's pattern, action and applies_to, filling in defaults
+// for an empty action or applies_to
+func (rule *AutomodRule) Validate() error {
+	if strings.TrimSpace(rule.Pattern) == "" {
+		return fmt.Errorf("%w: pattern is required", ErrInvalidAutomodPattern)
+	}
+	if rule.IsRegex {
+		if _, err := compileAutomodRegexp(rule.Pattern); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidAutomodPattern, err)
+		}
+	}
+
+	if rule.Action == "" {
+		rule.Action = AutomodActionRemove
+	}
+	if rule.Action != AutomodActionRemove {
+		return fmt.Errorf("%w: unknown action %q", ErrInvalidAutomodRule, rule.Action)
+	}
+
+	if rule.AppliesTo == "" {
+		rule.AppliesTo = AutomodAppliesToAll
+	}
+	switch rule.AppliesTo {
+	case AutomodAppliesToPosts, AutomodAppliesToComments, AutomodAppliesToAll:
+	default:
+		return fmt.Errorf("%w: unknown applies_to %q", ErrInvalidAutomodRule, rule.AppliesTo)
+	}
+
+	return nil
+}
+
+// automodRegexps caches compiled regex patterns by source, since rules are
+// evaluated against every new post and comment in their hub
+var automodRegexps sync.Map
+
+func compileAutomodRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := automodRegexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	automodRegexps.Store(pattern, re)
+	return re, nil
+}
+
+// Matches reports whether content trips the rule. Literal patterns match
+// case-insensitively anywhere in the content; regex patterns are used as written.
+func (rule *AutomodRule) Matches(content string) bool {
+	if rule.IsRegex {
+		re, err := compileAutomodRegexp(rule.Pattern)
+		if err != nil {
+			return false
+		}
+		return re.MatchString(content)
+	}
+	return strings.Contains(strings.ToLower(content), strings.ToLower(rule.Pattern))
+}
+
+// AutomodRules is a hub's rule list, evaluated in order
+type AutomodRules []*AutomodRule
+
+// Evaluate returns the first rule content matches, in the order given
+func (rules AutomodRules) Evaluate(content string) (matched bool, ruleID int) {
+	for _, rule := range rules {
+		if rule.Matches(content) {
+			return true, rule.ID
+		}
+	}
+	return false, 0
+}
+
+type AutomodRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewAutomodRepository(db *pgxpool.Pool) *AutomodRepository {
+	return &AutomodRepository{db: db}
+}
+
+// Create validates and stores a new rule
+func (r *AutomodRepository) Create(ctx context.Context, rule *AutomodRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO hub_automod_rules (hub_id, pattern, is_regex, action, applies_to, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, rule.HubID, rule.Pattern, rule.IsRegex, rule.Action, rule.AppliesTo, rule.CreatedBy).
+		Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create automod rule: %w", err)
+	}
+
+	return nil
+}
+
+// Update validates and saves changes to an existing rule's pattern, action and applies_to
+func (r *AutomodRepository) Update(ctx context.Context, rule *AutomodRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE hub_automod_rules
+		SET pattern = $2, is_regex = $3, action = $4, applies_to = $5, updated_at = NOW()
+		WHERE id = $1
+		RETURNING hub_id, created_by, created_at, updated_at
+	`
+
+	err := r.db.QueryRow(ctx, query, rule.ID, rule.Pattern, rule.IsRegex, rule.Action, rule.AppliesTo).
+		Scan(&rule.HubID, &rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("automod rule %d not found", rule.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update automod rule: %w", err)
+	}
+
+	return nil
+}
+
+// Delete deletes a rule
+func (r *AutomodRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.Exec(ctx, `DELETE FROM hub_automod_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete automod rule: %w", err)
+	}
+
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("automod rule %d not found", id)
+	}
+
+	return nil
+}
+
+// GetByID gets a rule, returning nil if it does not exist
+func (r *AutomodRepository) GetByID(ctx context.Context, id int) (*AutomodRule, error) {
+	query := `
+		SELECT id, hub_id, pattern, is_regex, action, applies_to, created_by, created_at, updated_at
+		FROM hub_automod_rules
+		WHERE id = $1
+	`
+
+	var rule AutomodRule
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&rule.ID, &rule.HubID, &rule.Pattern, &rule.IsRegex, &rule.Action, &rule.AppliesTo,
+		&rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automod rule: %w", err)
+	}
+
+	return &rule, nil
+}
+
+// GetByHub lists a hub's rules in creation order. A non-empty appliesTo
+// ("posts" or "comments") limits the list to rules covering that content.
+func (r *AutomodRepository) GetByHub(ctx context.Context, hubID int, appliesTo string) ([]*AutomodRule, error) {
+	query := `
+		SELECT id, hub_id, pattern, is_regex, action, applies_to, created_by, created_at, updated_at
+		FROM hub_automod_rules
+		WHERE hub_id = $1
+		AND ($2 = '' OR applies_to IN ($2, 'all'))
+		ORDER BY id ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, hubID, appliesTo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get automod rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []*AutomodRule{}
+	for rows.Next() {
+		var rule AutomodRule
+		err := rows.Scan(
+			&rule.ID, &rule.HubID, &rule.Pattern, &rule.IsRegex, &rule.Action, &rule.AppliesTo,
+			&rule.CreatedBy, &rule.CreatedAt, &rule.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan automod rule: %w", err)
+		}
+		rules = append(rules, &rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating automod rules: %w", err)
+	}
+
+	return rules, nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAutomodRulesEvaluate_LiteralMatchesCaseInsensitively(t *testing.T) {
+	rules := AutomodRules{{ID: 7, Pattern: "Free Crypto"}}
+
+	matched, ruleID := rules.Evaluate("get your FREE crypto here")
+	assert.True(t, matched)
+	assert.Equal(t, 7, ruleID)
+
+	matched, ruleID = rules.Evaluate("crypto is not free")
+	assert.False(t, matched)
+	assert.Equal(t, 0, ruleID)
+}
+
+func TestAutomodRulesEvaluate_LiteralIsNotTreatedAsRegex(t *testing.T) {
+	rules := AutomodRules{{ID: 1, Pattern: "a.b"}}
+
+	matched, _ := rules.Evaluate("axb")
+	assert.False(t, matched)
+
+	matched, _ = rules.Evaluate("see a.b")
+	assert.True(t, matched)
+}
+
+func TestAutomodRulesEvaluate_Regex(t *testing.T) {
+	rules := AutomodRules{
+		{ID: 1, Pattern: "harmless"},
+		{ID: 2, Pattern: `(?i)buy\s+now\s*!+`, IsRegex: true},
+	}
+
+	matched, ruleID := rules.Evaluate("Limited offer, BUY   NOW!!!")
+	assert.True(t, matched)
+	assert.Equal(t, 2, ruleID)
+
+	matched, _ = rules.Evaluate("buy it later")
+	assert.False(t, matched)
+}
+
+func TestAutomodRulesEvaluate_FirstMatchingRuleWins(t *testing.T) {
+	rules := AutomodRules{
+		{ID: 3, Pattern: "spam"},
+		{ID: 4, Pattern: `sp[a]m`, IsRegex: true},
+	}
+
+	matched, ruleID := rules.Evaluate("this is spam")
+	assert.True(t, matched)
+	assert.Equal(t, 3, ruleID)
+}
+
+func TestAutomodRepositoryCreate_RejectsInvalidRegex(t *testing.T) {
+	repo := NewAutomodRepository(nil)
+
+	err := repo.Create(context.Background(), &AutomodRule{HubID: 1, Pattern: "(unclosed", IsRegex: true, CreatedBy: 1})
+	assert.ErrorIs(t, err, ErrInvalidAutomodPattern)
+
+	err = repo.Create(context.Background(), &AutomodRule{HubID: 1, Pattern: "   ", CreatedBy: 1})
+	assert.ErrorIs(t, err, ErrInvalidAutomodPattern)
+}
+
+func TestAutomodRuleValidate_DefaultsAndRejectsUnknownValues(t *testing.T) {
+	rule := &AutomodRule{Pattern: "(unclosed"}
+	require.NoError(t, rule.Validate(), "an invalid regex is fine as a literal")
+	assert.Equal(t, AutomodActionRemove, rule.Action)
+	assert.Equal(t, AutomodAppliesToAll, rule.AppliesTo)
+
+	rule = &AutomodRule{Pattern: "x", AppliesTo: "messages"}
+	assert.ErrorIs(t, rule.Validate(), ErrInvalidAutomodRule)
+
+	rule = &AutomodRule{Pattern: "x", Action: "ban"}
+	assert.ErrorIs(t, rule.Validate(), ErrInvalidAutomodRule)
+}
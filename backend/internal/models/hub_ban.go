@@ -173,3 +173,38 @@ func (r *HubBanRepository) CleanExpiredBans(ctx context.Context) (int64, error)
 
 	return result.RowsAffected(), nil
 }
+
+// ExpireDueBans deletes temporary bans whose expires_at has passed and returns
+// them so callers can record each expiry
+func (r *HubBanRepository) ExpireDueBans(ctx context.Context) ([]*HubBan, error) {
+	query := `
+		DELETE FROM hub_bans
+		WHERE ban_type = 'temporary' AND expires_at < NOW()
+		RETURNING id, hub_id, user_id, banned_by, reason, note, ban_type, expires_at, created_at
+	`
+
+	rows, err := r.db.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to expire bans: %w", err)
+	}
+	defer rows.Close()
+
+	var expired []*HubBan
+	for rows.Next() {
+		var ban HubBan
+		err := rows.Scan(
+			&ban.ID, &ban.HubID, &ban.UserID, &ban.BannedBy, &ban.Reason, &ban.Note,
+			&ban.BanType, &ban.ExpiresAt, &ban.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan expired ban: %w", err)
+		}
+		expired = append(expired, &ban)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expired bans: %w", err)
+	}
+
+	return expired, nil
+}
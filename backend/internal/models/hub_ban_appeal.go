@@ -0,0 +1,185 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Ban appeal statuses
+const (
+	BanAppealPending  = "pending"
+	BanAppealApproved = "approved"
+	BanAppealDenied   = "denied"
+)
+
+var (
+	// ErrBanAppealPending is returned when the ban already has an open appeal
+	ErrBanAppealPending = errors.New("ban already has a pending appeal")
+	// ErrBanAppealResolved is returned when resolving an appeal that is no longer pending
+	ErrBanAppealResolved = errors.New("ban appeal has already been resolved")
+)
+
+type BanAppeal struct {
+	ID             int        `json:"id"`
+	BanID          *int       `json:"ban_id,omitempty"` // Cleared once the ban is lifted
+	HubID          int        `json:"hub_id"`
+	UserID         int        `json:"user_id"`
+	Message        string     `json:"message"`
+	Status         string     `json:"status"`
+	ResolvedBy     *int       `json:"resolved_by,omitempty"`
+	ResolutionNote *string    `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+
+	// Populated fields
+	Username string `json:"username,omitempty"`
+}
+
+type BanAppealRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBanAppealRepository(db *pgxpool.Pool) *BanAppealRepository {
+	return &BanAppealRepository{db: db}
+}
+
+// Create files an appeal against ban on behalf of the banned user
+func (r *BanAppealRepository) Create(ctx context.Context, ban *HubBan, message string) (*BanAppeal, error) {
+	query := `
+		INSERT INTO hub_ban_appeals (ban_id, hub_id, user_id, message)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, ban_id, hub_id, user_id, message, status, resolved_by, resolution_note, created_at, resolved_at
+	`
+
+	var appeal BanAppeal
+	err := r.db.QueryRow(ctx, query, ban.ID, ban.HubID, ban.UserID, message).Scan(
+		&appeal.ID, &appeal.BanID, &appeal.HubID, &appeal.UserID, &appeal.Message, &appeal.Status,
+		&appeal.ResolvedBy, &appeal.ResolutionNote, &appeal.CreatedAt, &appeal.ResolvedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.SQLState() == "23505" {
+			return nil, ErrBanAppealPending
+		}
+		return nil, fmt.Errorf("failed to create ban appeal: %w", err)
+	}
+
+	return &appeal, nil
+}
+
+// GetByID gets an appeal, returning nil if it does not exist
+func (r *BanAppealRepository) GetByID(ctx context.Context, id int) (*BanAppeal, error) {
+	query := `
+		SELECT a.id, a.ban_id, a.hub_id, a.user_id, a.message, a.status, a.resolved_by, a.resolution_note,
+			   a.created_at, a.resolved_at, u.username
+		FROM hub_ban_appeals a
+		JOIN users u ON a.user_id = u.id
+		WHERE a.id = $1
+	`
+
+	var appeal BanAppeal
+	err := r.db.QueryRow(ctx, query, id).Scan(
+		&appeal.ID, &appeal.BanID, &appeal.HubID, &appeal.UserID, &appeal.Message, &appeal.Status,
+		&appeal.ResolvedBy, &appeal.ResolutionNote, &appeal.CreatedAt, &appeal.ResolvedAt, &appeal.Username,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ban appeal: %w", err)
+	}
+
+	return &appeal, nil
+}
+
+// ListByHub lists a hub's appeals, oldest first so moderators work through them in order.
+// An empty status returns appeals in every status.
+func (r *BanAppealRepository) ListByHub(ctx context.Context, hubID int, status string) ([]*BanAppeal, error) {
+	query := `
+		SELECT a.id, a.ban_id, a.hub_id, a.user_id, a.message, a.status, a.resolved_by, a.resolution_note,
+			   a.created_at, a.resolved_at, u.username
+		FROM hub_ban_appeals a
+		JOIN users u ON a.user_id = u.id
+		WHERE a.hub_id = $1
+		AND ($2 = '' OR a.status = $2)
+		ORDER BY a.created_at ASC, a.id ASC
+	`
+
+	rows, err := r.db.Query(ctx, query, hubID, status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ban appeals: %w", err)
+	}
+	defer rows.Close()
+
+	appeals := []*BanAppeal{}
+	for rows.Next() {
+		var appeal BanAppeal
+		err := rows.Scan(
+			&appeal.ID, &appeal.BanID, &appeal.HubID, &appeal.UserID, &appeal.Message, &appeal.Status,
+			&appeal.ResolvedBy, &appeal.ResolutionNote, &appeal.CreatedAt, &appeal.ResolvedAt, &appeal.Username,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan ban appeal: %w", err)
+		}
+		appeals = append(appeals, &appeal)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating ban appeals: %w", err)
+	}
+
+	return appeals, nil
+}
+
+// Resolve moves a pending appeal to approved or denied. Approving an appeal
+// also lifts the ban in the same transaction, so an approved appeal never
+// leaves the user banned. A ban that already expired or was lifted is fine.
+func (r *BanAppealRepository) Resolve(ctx context.Context, id, resolvedBy int, status string, note *string) (*BanAppeal, error) {
+	if status != BanAppealApproved && status != BanAppealDenied {
+		return nil, fmt.Errorf("invalid ban appeal status: %s", status)
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ban appeal: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE hub_ban_appeals
+		SET status = $3, resolved_by = $2, resolution_note = $4, resolved_at = NOW()
+		WHERE id = $1 AND status = 'pending'
+		RETURNING id, ban_id, hub_id, user_id, message, status, resolved_by, resolution_note, created_at, resolved_at
+	`
+
+	var appeal BanAppeal
+	err = tx.QueryRow(ctx, query, id, resolvedBy, status, note).Scan(
+		&appeal.ID, &appeal.BanID, &appeal.HubID, &appeal.UserID, &appeal.Message, &appeal.Status,
+		&appeal.ResolvedBy, &appeal.ResolutionNote, &appeal.CreatedAt, &appeal.ResolvedAt,
+	)
+	if err == pgx.ErrNoRows {
+		return nil, ErrBanAppealResolved
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ban appeal: %w", err)
+	}
+
+	if status == BanAppealApproved {
+		if _, err := tx.Exec(ctx, `DELETE FROM hub_bans WHERE hub_id = $1 AND user_id = $2`, appeal.HubID, appeal.UserID); err != nil {
+			return nil, fmt.Errorf("failed to unban user: %w", err)
+		}
+		appeal.BanID = nil
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to resolve ban appeal: %w", err)
+	}
+
+	return &appeal, nil
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrHubMemberUserNotFound is returned when inviting a user that doesn't exist
+var ErrHubMemberUserNotFound = errors.New("user not found")
+
+// HubMemberRepository manages the invite list of private hubs
+type HubMemberRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewHubMemberRepository creates a new repo
+func NewHubMemberRepository(pool *pgxpool.Pool) *HubMemberRepository {
+	return &HubMemberRepository{pool: pool}
+}
+
+// AddMember invites a user to a hub. addedBy is the moderator who invited them.
+// Adding an existing member is a no-op.
+func (r *HubMemberRepository) AddMember(ctx context.Context, hubID, userID, addedBy int) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO hub_members (hub_id, user_id, added_by)
+		VALUES ($1, $2, $3) ON CONFLICT DO NOTHING
+	`, hubID, userID, addedBy)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.SQLState() == "23503" && pgErr.ConstraintName == "hub_members_user_id_fkey" {
+		return ErrHubMemberUserNotFound
+	}
+	return err
+}
+
+// IsMember checks whether a user is on a hub's member list
+func (r *HubMemberRepository) IsMember(ctx context.Context, hubID, userID int) (bool, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS(SELECT 1 FROM hub_members WHERE hub_id = $1 AND user_id = $2)
+	`, hubID, userID).Scan(&exists)
+	return exists, err
+}
+
+// RemoveMember removes a user from a hub's member list, reporting whether they were on it
+func (r *HubMemberRepository) RemoveMember(ctx context.Context, hubID, userID int) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM hub_members WHERE hub_id = $1 AND user_id = $2`, hubID, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
@@ -2,22 +2,40 @@ package models
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-// HubModerator links users to moderated hubs
-type HubModerator struct {
-	ID     int `json:"id"`
-	HubID  int `json:"hub_id"`
-	UserID int `json:"user_id"`
-}
+// Hub moderator permission scopes. ModPermFull implies every other scope.
+const (
+	ModPermFull   = "full"
+	ModPermPosts  = "posts"  // Remove/approve/lock/pin content and work the mod queue
+	ModPermConfig = "config" // Removal reasons and automod rules
+	ModPermAccess = "access" // Bans and ban appeals
+)
+
+// ErrInvalidModPermission is returned when setting an unknown permission scope
+var ErrInvalidModPermission = errors.New("invalid moderator permission")
+
+var (
+	// ErrNotModerator is returned when removing a user who doesn't moderate the hub
+	ErrNotModerator = errors.New("user is not a moderator of this hub")
+	// ErrLastModerator is returned when removing a hub's only moderator
+	ErrLastModerator = errors.New("cannot remove the last moderator of a hub")
+)
 
-// HubModeratorUser holds limited user info for moderators
-type HubModeratorUser struct {
-	UserID    int
-	Username  string
-	AvatarURL *string
+// HubModerator links users to moderated hubs, with basic profile info for listings
+type HubModerator struct {
+	ID          int       `json:"id"`
+	HubID       int       `json:"hub_id"`
+	UserID      int       `json:"user_id"`
+	Permissions []string  `json:"permissions"`
+	Username    string    `json:"username"`
+	AvatarURL   *string   `json:"avatar_url,omitempty"`
+	AddedAt     time.Time `json:"added_at"`
 }
 
 // ModeratedHubSummary holds hub info for moderator listings
@@ -58,10 +76,49 @@ func (r *HubModeratorRepository) IsModerator(ctx context.Context, hubID, userID
 	return exists, err
 }
 
-// GetModeratorsForHub returns the moderators for a given hub with basic profile info
-func (r *HubModeratorRepository) GetModeratorsForHub(ctx context.Context, hubID int) ([]HubModeratorUser, error) {
+// HasPermission checks if user moderates hub with the given permission scope.
+// Moderators with ModPermFull have every permission.
+func (r *HubModeratorRepository) HasPermission(ctx context.Context, hubID, userID int, perm string) (bool, error) {
+	var allowed bool
+	err := r.pool.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM hub_moderators
+			WHERE hub_id = $1 AND user_id = $2
+			AND ('full' = ANY(permissions) OR $3 = ANY(permissions))
+		)
+	`, hubID, userID, perm).Scan(&allowed)
+	return allowed, err
+}
+
+// SetPermissions replaces a moderator's permission scopes. Returns false if
+// the user does not moderate the hub.
+func (r *HubModeratorRepository) SetPermissions(ctx context.Context, hubID, userID int, perms []string) (bool, error) {
+	if len(perms) == 0 {
+		return false, fmt.Errorf("%w: at least one permission is required", ErrInvalidModPermission)
+	}
+	for _, perm := range perms {
+		switch perm {
+		case ModPermFull, ModPermPosts, ModPermConfig, ModPermAccess:
+		default:
+			return false, fmt.Errorf("%w: %q", ErrInvalidModPermission, perm)
+		}
+	}
+
+	result, err := r.pool.Exec(ctx, `
+		UPDATE hub_moderators
+		SET permissions = $3
+		WHERE hub_id = $1 AND user_id = $2
+	`, hubID, userID, perms)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// GetModerators returns the moderators for a given hub with basic profile info
+func (r *HubModeratorRepository) GetModerators(ctx context.Context, hubID int) ([]HubModerator, error) {
 	rows, err := r.pool.Query(ctx, `
-		SELECT u.id, u.username, u.avatar_url
+		SELECT hm.id, hm.hub_id, hm.user_id, hm.permissions, u.username, u.avatar_url, hm.added_at
 		FROM hub_moderators hm
 		JOIN users u ON hm.user_id = u.id
 		WHERE hm.hub_id = $1
@@ -72,10 +129,10 @@ func (r *HubModeratorRepository) GetModeratorsForHub(ctx context.Context, hubID
 	}
 	defer rows.Close()
 
-	var moderators []HubModeratorUser
+	var moderators []HubModerator
 	for rows.Next() {
-		var mod HubModeratorUser
-		if err := rows.Scan(&mod.UserID, &mod.Username, &mod.AvatarURL); err != nil {
+		var mod HubModerator
+		if err := rows.Scan(&mod.ID, &mod.HubID, &mod.UserID, &mod.Permissions, &mod.Username, &mod.AvatarURL, &mod.AddedAt); err != nil {
 			return nil, err
 		}
 		moderators = append(moderators, mod)
@@ -108,11 +165,43 @@ func (r *HubModeratorRepository) GetHubsForModerator(ctx context.Context, userID
 	return hubs, rows.Err()
 }
 
-// RemoveModerator removes a user as moderator from a hub
+// RemoveModerator removes a user as moderator from a hub. A hub always keeps at
+// least one moderator, so removing the last one fails with ErrLastModerator.
 func (r *HubModeratorRepository) RemoveModerator(ctx context.Context, hubID, userID int) error {
-	_, err := r.pool.Exec(ctx, `
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Serialize removals per hub so two concurrent demotions can't both pass the check
+	if _, err := tx.Exec(ctx, `SELECT id FROM hubs WHERE id = $1 FOR UPDATE`, hubID); err != nil {
+		return err
+	}
+
+	var isMod bool
+	var others int
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(BOOL_OR(user_id = $2), FALSE), COUNT(*) FILTER (WHERE user_id <> $2)
+		FROM hub_moderators
+		WHERE hub_id = $1
+	`, hubID, userID).Scan(&isMod, &others)
+	if err != nil {
+		return err
+	}
+	if !isMod {
+		return ErrNotModerator
+	}
+	if others == 0 {
+		return ErrLastModerator
+	}
+
+	if _, err := tx.Exec(ctx, `
 		DELETE FROM hub_moderators
 		WHERE hub_id = $1 AND user_id = $2
-	`, hubID, userID)
-	return err
+	`, hubID, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
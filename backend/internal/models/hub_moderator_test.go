@@ -0,0 +1,18 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubModeratorSetPermissions_RejectsUnknownScope(t *testing.T) {
+	repo := NewHubModeratorRepository(nil)
+
+	_, err := repo.SetPermissions(context.Background(), 1, 1, []string{ModPermPosts, "everything"})
+	assert.ErrorIs(t, err, ErrInvalidModPermission)
+
+	_, err = repo.SetPermissions(context.Background(), 1, 1, nil)
+	assert.ErrorIs(t, err, ErrInvalidModPermission)
+}
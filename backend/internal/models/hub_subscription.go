@@ -36,7 +36,7 @@ func (r *HubSubscriptionRepository) Subscribe(ctx context.Context, userID, hubID
 	defer tx.Rollback(ctx)
 
 	// Insert subscription (ignore if already exists)
-	_, err = tx.Exec(ctx, `
+	cmdTag, err := tx.Exec(ctx, `
 		INSERT INTO hub_subscriptions (user_id, hub_id)
 		VALUES ($1, $2)
 		ON CONFLICT (user_id, hub_id) DO NOTHING
@@ -45,19 +45,16 @@ func (r *HubSubscriptionRepository) Subscribe(ctx context.Context, userID, hubID
 		return err
 	}
 
-	// Increment subscriber count
-	_, err = tx.Exec(ctx, `
-		UPDATE hubs
-		SET subscriber_count = subscriber_count + 1
-		WHERE id = $1
-		AND NOT EXISTS (
-			SELECT 1 FROM hub_subscriptions
-			WHERE user_id = $2 AND hub_id = $1
-			AND subscribed_at < NOW() - INTERVAL '1 second'
-		)
-	`, hubID, userID)
-	if err != nil {
-		return err
+	// Only increment if the subscription is new
+	if cmdTag.RowsAffected() > 0 {
+		_, err = tx.Exec(ctx, `
+			UPDATE hubs
+			SET subscriber_count = subscriber_count + 1
+			WHERE id = $1
+		`, hubID)
+		if err != nil {
+			return err
+		}
 	}
 
 	return tx.Commit(ctx)
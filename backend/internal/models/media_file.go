@@ -23,8 +23,20 @@ type MediaFile struct {
 	Duration         *int      `json:"duration,omitempty"`
 	UsedInMessageID  *int      `json:"used_in_message_id,omitempty"`
 	UploadedAt       time.Time `json:"uploaded_at"`
+
+	// Transcoding of uploaded videos; images and other files are always ready
+	ProcessingStatus string  `json:"processing_status"`
+	PlayableURL      *string `json:"playable_url,omitempty"`
+	HLSURL           *string `json:"hls_url,omitempty"`
 }
 
+// Media processing statuses
+const (
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
+
 // MediaFileRepository handles database operations for media files
 type MediaFileRepository struct {
 	pool *pgxpool.Pool
@@ -40,12 +52,17 @@ func (r *MediaFileRepository) Create(ctx context.Context, media *MediaFile) erro
 	query := `
 		INSERT INTO media_files (
 			user_id, filename, original_filename, file_type, file_size,
-			storage_url, thumbnail_url, storage_path, width, height, duration, used_in_message_id
+			storage_url, thumbnail_url, storage_path, width, height, duration, used_in_message_id,
+			processing_status
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 		RETURNING id, uploaded_at
 	`
 
+	if media.ProcessingStatus == "" {
+		media.ProcessingStatus = MediaStatusReady
+	}
+
 	return r.pool.QueryRow(ctx, query,
 		media.UserID,
 		media.Filename,
@@ -59,5 +76,89 @@ func (r *MediaFileRepository) Create(ctx context.Context, media *MediaFile) erro
 		media.Height,
 		media.Duration,
 		media.UsedInMessageID,
+		media.ProcessingStatus,
 	).Scan(&media.ID, &media.UploadedAt)
 }
+
+// ListProcessing returns media files still waiting on a transcode, oldest first
+func (r *MediaFileRepository) ListProcessing(ctx context.Context, limit int) ([]*MediaFile, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, filename, file_type, storage_url, storage_path, processing_status
+		FROM media_files
+		WHERE processing_status = 'processing'
+		ORDER BY uploaded_at ASC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var files []*MediaFile
+	for rows.Next() {
+		media := &MediaFile{}
+		var storagePath *string
+		if err := rows.Scan(&media.ID, &media.UserID, &media.Filename, &media.FileType, &media.StorageURL, &storagePath, &media.ProcessingStatus); err != nil {
+			return nil, err
+		}
+		if storagePath != nil {
+			media.StoragePath = *storagePath
+		}
+		files = append(files, media)
+	}
+	return files, rows.Err()
+}
+
+// MarkProcessed records a finished transcode and makes the media playable
+func (r *MediaFileRepository) MarkProcessed(ctx context.Context, id int, playableURL string, hlsURL *string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE media_files
+		SET processing_status = 'ready', playable_url = $2, hls_url = $3, processing_error = NULL
+		WHERE id = $1
+	`, id, playableURL, hlsURL)
+	return err
+}
+
+// MarkProcessingFailed records a failed transcode. The original upload stays
+// available through storage_url.
+func (r *MediaFileRepository) MarkProcessingFailed(ctx context.Context, id int, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE media_files SET processing_status = 'failed', processing_error = $2 WHERE id = $1
+	`, id, reason)
+	return err
+}
+
+// MediaStatus is the processing state of an uploaded file, looked up by URL
+type MediaStatus struct {
+	Status      string
+	PlayableURL *string
+}
+
+// GetStatusByURLs returns the processing state of uploaded media keyed by storage URL.
+// URLs that don't belong to an upload are absent from the result.
+func (r *MediaFileRepository) GetStatusByURLs(ctx context.Context, urls []string) (map[string]MediaStatus, error) {
+	statuses := make(map[string]MediaStatus)
+	if len(urls) == 0 {
+		return statuses, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT storage_url, processing_status, playable_url
+		FROM media_files
+		WHERE storage_url = ANY($1)
+	`, urls)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url string
+		var status MediaStatus
+		if err := rows.Scan(&url, &status.Status, &status.PlayableURL); err != nil {
+			return nil, err
+		}
+		statuses[url] = status
+	}
+	return statuses, rows.Err()
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -25,10 +26,23 @@ type Message struct {
 	MediaURL                 *string    `json:"media_url,omitempty"`
 	MediaType                *string    `json:"media_type,omitempty"`
 	MediaSize                *int       `json:"media_size,omitempty"`
+	MediaStatus              *string    `json:"media_status,omitempty"`         // processing, ready or failed for uploaded media
 	EncryptionVersion        string     `json:"encryption_version"`             // For future encryption updates, e.g., "v1"
 	MediaEncryptionKey       *string    `json:"media_encryption_key,omitempty"` // RSA-encrypted AES key (Base64) for recipient
 	MediaEncryptionIV        *string    `json:"media_encryption_iv,omitempty"`  // AES-GCM initialization vector (Base64)
 	SenderMediaEncryptionKey *string    `json:"sender_media_encryption_key,omitempty"`
+	IsEdited                 bool       `json:"is_edited"`
+	EditedAt                 *time.Time `json:"edited_at,omitempty"`
+
+	// Reactions is filled in by handlers that list messages; repository reads leave it empty
+	Reactions []MessageReaction `json:"reactions,omitempty"`
+}
+
+// MessageReaction aggregates one emoji's reactions on a message
+type MessageReaction struct {
+	Emoji   string `json:"emoji"`
+	Count   int    `json:"count"`
+	UserIDs []int  `json:"user_ids"`
 }
 
 // MessageRepository handles database operations for messages
@@ -90,13 +104,16 @@ func (r *MessageRepository) GetByID(ctx context.Context, id int) (*Message, erro
 		       m.message_type, m.sent_at, m.delivered_at, m.read_at,
 		       m.deleted_for_sender, m.deleted_for_recipient,
 		       m.media_file_id,
-		       COALESCE(mf.storage_url, m.media_url) as media_url,
+		       COALESCE(mf.playable_url, mf.storage_url, m.media_url) as media_url,
 		       COALESCE(m.media_type, mf.file_type) as media_type,
 		       COALESCE(m.media_size, mf.file_size) as media_size,
+		       mf.processing_status,
 		       m.encryption_version,
 		       m.media_encryption_key,
 		       m.media_encryption_iv,
-		       m.sender_media_encryption_key
+		       m.sender_media_encryption_key,
+		       m.is_edited,
+		       m.edited_at
 		FROM messages m
 		LEFT JOIN media_files mf ON m.media_file_id = mf.id
 		WHERE m.id = $1
@@ -119,10 +136,13 @@ func (r *MessageRepository) GetByID(ctx context.Context, id int) (*Message, erro
 		&message.MediaURL,
 		&message.MediaType,
 		&message.MediaSize,
+		&message.MediaStatus,
 		&message.EncryptionVersion,
 		&message.MediaEncryptionKey,
 		&message.MediaEncryptionIV,
 		&message.SenderMediaEncryptionKey,
+		&message.IsEdited,
+		&message.EditedAt,
 	)
 
 	if err != nil {
@@ -141,13 +161,16 @@ func (r *MessageRepository) GetByConversationID(ctx context.Context, conversatio
 		       m.message_type, m.sent_at, m.delivered_at, m.read_at,
 		       m.deleted_for_sender, m.deleted_for_recipient,
 		       m.media_file_id,
-		       COALESCE(mf.storage_url, m.media_url) as media_url,
+		       COALESCE(mf.playable_url, mf.storage_url, m.media_url) as media_url,
 		       COALESCE(m.media_type, mf.file_type) as media_type,
 		       COALESCE(m.media_size, mf.file_size) as media_size,
+		       mf.processing_status,
 		       m.encryption_version,
 		       m.media_encryption_key,
 		       m.media_encryption_iv,
-		       m.sender_media_encryption_key
+		       m.sender_media_encryption_key,
+		       m.is_edited,
+		       m.edited_at
 		FROM messages m
 		LEFT JOIN media_files mf ON m.media_file_id = mf.id
 		WHERE m.conversation_id = $1
@@ -185,10 +208,13 @@ func (r *MessageRepository) GetByConversationID(ctx context.Context, conversatio
 			&message.MediaURL,
 			&message.MediaType,
 			&message.MediaSize,
+			&message.MediaStatus,
 			&message.EncryptionVersion,
 			&message.MediaEncryptionKey,
 			&message.MediaEncryptionIV,
 			&message.SenderMediaEncryptionKey,
+			&message.IsEdited,
+			&message.EditedAt,
 		)
 		if err != nil {
 			return nil, err
@@ -199,6 +225,192 @@ func (r *MessageRepository) GetByConversationID(ctx context.Context, conversatio
 	return messages, rows.Err()
 }
 
+// MessageSearchFilters narrows SearchInConversation by message metadata.
+// Content is end-to-end encrypted, so only type and time can be searched.
+type MessageSearchFilters struct {
+	MessageType string     // Optional; "" matches every type
+	From        *time.Time // Optional inclusive lower bound on sent_at
+	To          *time.Time // Optional inclusive upper bound on sent_at
+	Limit       int
+	Offset      int
+}
+
+// SearchInConversation returns the messages in a conversation visible to userID
+// that match filters, newest first
+func (r *MessageRepository) SearchInConversation(ctx context.Context, conversationID int, userID int, filters MessageSearchFilters) ([]*Message, error) {
+	query := `
+		SELECT m.id, m.conversation_id, m.sender_id, m.recipient_id, m.encrypted_content,
+		       m.sender_encrypted_content,
+		       m.message_type, m.sent_at, m.delivered_at, m.read_at,
+		       m.deleted_for_sender, m.deleted_for_recipient,
+		       m.media_file_id,
+		       COALESCE(mf.playable_url, mf.storage_url, m.media_url) as media_url,
+		       COALESCE(m.media_type, mf.file_type) as media_type,
+		       COALESCE(m.media_size, mf.file_size) as media_size,
+		       mf.processing_status,
+		       m.encryption_version,
+		       m.media_encryption_key,
+		       m.media_encryption_iv,
+		       m.sender_media_encryption_key,
+		       m.is_edited,
+		       m.edited_at
+		FROM messages m
+		LEFT JOIN media_files mf ON m.media_file_id = mf.id
+		WHERE m.conversation_id = $1
+		  AND (
+		    (m.sender_id = $2 AND m.deleted_for_sender = false) OR
+		    (m.recipient_id = $2 AND m.deleted_for_recipient = false)
+		  )
+		  AND ($3 = '' OR m.message_type = $3)
+		  AND ($4::timestamp IS NULL OR m.sent_at >= $4)
+		  AND ($5::timestamp IS NULL OR m.sent_at <= $5)
+		ORDER BY m.sent_at DESC, m.id DESC
+		LIMIT $6 OFFSET $7
+	`
+
+	rows, err := r.pool.Query(ctx, query, conversationID, userID, filters.MessageType, filters.From, filters.To, filters.Limit, filters.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := make([]*Message, 0)
+	for rows.Next() {
+		message := &Message{}
+		err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.RecipientID,
+			&message.EncryptedContent,
+			&message.SenderEncryptedContent,
+			&message.MessageType,
+			&message.SentAt,
+			&message.DeliveredAt,
+			&message.ReadAt,
+			&message.DeletedForSender,
+			&message.DeletedForRecipient,
+			&message.MediaFileID,
+			&message.MediaURL,
+			&message.MediaType,
+			&message.MediaSize,
+			&message.MediaStatus,
+			&message.EncryptionVersion,
+			&message.MediaEncryptionKey,
+			&message.MediaEncryptionIV,
+			&message.SenderMediaEncryptionKey,
+			&message.IsEdited,
+			&message.EditedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+// StreamByConversationID walks every message in a conversation that is visible to userID,
+// oldest first, invoking fn for each row without buffering the whole history in memory.
+// Messages deleted for this user (including delete-for-both) are excluded.
+func (r *MessageRepository) StreamByConversationID(ctx context.Context, conversationID int, userID int, fn func(*Message) error) error {
+	query := `
+		SELECT m.id, m.conversation_id, m.sender_id, m.recipient_id, m.encrypted_content,
+		       m.sender_encrypted_content,
+		       m.message_type, m.sent_at, m.delivered_at, m.read_at,
+		       m.deleted_for_sender, m.deleted_for_recipient,
+		       m.media_file_id,
+		       COALESCE(mf.playable_url, mf.storage_url, m.media_url) as media_url,
+		       COALESCE(m.media_type, mf.file_type) as media_type,
+		       COALESCE(m.media_size, mf.file_size) as media_size,
+		       mf.processing_status,
+		       m.encryption_version,
+		       m.media_encryption_key,
+		       m.media_encryption_iv,
+		       m.sender_media_encryption_key,
+		       m.is_edited,
+		       m.edited_at
+		FROM messages m
+		LEFT JOIN media_files mf ON m.media_file_id = mf.id
+		WHERE m.conversation_id = $1
+		  AND (
+		    (m.sender_id = $2 AND m.deleted_for_sender = false) OR
+		    (m.recipient_id = $2 AND m.deleted_for_recipient = false)
+		  )
+		ORDER BY m.sent_at ASC, m.id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		message := &Message{}
+		err := rows.Scan(
+			&message.ID,
+			&message.ConversationID,
+			&message.SenderID,
+			&message.RecipientID,
+			&message.EncryptedContent,
+			&message.SenderEncryptedContent,
+			&message.MessageType,
+			&message.SentAt,
+			&message.DeliveredAt,
+			&message.ReadAt,
+			&message.DeletedForSender,
+			&message.DeletedForRecipient,
+			&message.MediaFileID,
+			&message.MediaURL,
+			&message.MediaType,
+			&message.MediaSize,
+			&message.MediaStatus,
+			&message.EncryptionVersion,
+			&message.MediaEncryptionKey,
+			&message.MediaEncryptionIV,
+			&message.SenderMediaEncryptionKey,
+			&message.IsEdited,
+			&message.EditedAt,
+		)
+		if err != nil {
+			return err
+		}
+		if err := fn(message); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// ErrMessageNotEditable is returned by UpdateContent when the message does not
+// belong to the sender or has already been read by the recipient.
+var ErrMessageNotEditable = errors.New("message cannot be edited")
+
+// UpdateContent replaces the content of an unread message sent by senderID and
+// marks it as edited. senderEncryptedContent is left unchanged when nil.
+func (r *MessageRepository) UpdateContent(ctx context.Context, messageID int, senderID int, encryptedContent string, senderEncryptedContent *string) error {
+	query := `
+		UPDATE messages
+		SET encrypted_content = $3,
+		    sender_encrypted_content = COALESCE($4, sender_encrypted_content),
+		    is_edited = true,
+		    edited_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND sender_id = $2 AND read_at IS NULL
+	`
+
+	result, err := r.pool.Exec(ctx, query, messageID, senderID, encryptedContent, senderEncryptedContent)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return ErrMessageNotEditable
+	}
+	return nil
+}
+
 // MarkAsDelivered updates the delivered_at timestamp for a message
 func (r *MessageRepository) MarkAsDelivered(ctx context.Context, messageID int) error {
 	query := `
@@ -264,24 +476,90 @@ func (r *MessageRepository) MarkAllAsRead(ctx context.Context, conversationID in
 	return err
 }
 
-// SoftDeleteForUser marks a message as deleted for a specific user
-func (r *MessageRepository) SoftDeleteForUser(ctx context.Context, messageID int, userID int) error {
-	// Determine if user is sender or recipient
-	var isSender bool
-	err := r.pool.QueryRow(ctx, "SELECT sender_id = $1 FROM messages WHERE id = $2", userID, messageID).Scan(&isSender)
+// AffectedConversation summarises what MarkAllReadForUser marked read in one conversation
+type AffectedConversation struct {
+	ConversationID int
+	SenderID       int
+	MessageIDs     []int // Messages still visible to the reader, i.e. not deleted on their side
+	MarkedCount    int   // Every message marked read, including ones the reader had deleted
+}
+
+// MarkAllReadForUser marks every unread message addressed to the user as read,
+// across all conversations they take part in, and groups the result by
+// conversation in the order the rows came back.
+func (r *MessageRepository) MarkAllReadForUser(ctx context.Context, userID int) ([]AffectedConversation, error) {
+	// Joining conversations keeps the update to conversations the user is
+	// actually in, even if a message row's recipient_id were inconsistent
+	query := `
+		UPDATE messages m
+		SET read_at = CURRENT_TIMESTAMP
+		FROM conversations c
+		WHERE m.conversation_id = c.id
+		  AND (c.user1_id = $1 OR c.user2_id = $1)
+		  AND m.recipient_id = $1
+		  AND m.read_at IS NULL
+		RETURNING m.id, m.conversation_id, m.sender_id, m.deleted_for_recipient
+	`
+	rows, err := r.pool.Query(ctx, query, userID)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	defer rows.Close()
+
+	index := make(map[int]int)
+	var affected []AffectedConversation
+	for rows.Next() {
+		var messageID, conversationID, senderID int
+		var deletedForRecipient bool
+		if err := rows.Scan(&messageID, &conversationID, &senderID, &deletedForRecipient); err != nil {
+			return nil, err
+		}
 
-	var query string
-	if isSender {
-		query = `UPDATE messages SET deleted_for_sender = true WHERE id = $1`
-	} else {
-		query = `UPDATE messages SET deleted_for_recipient = true WHERE id = $1`
+		i, ok := index[conversationID]
+		if !ok {
+			i = len(affected)
+			index[conversationID] = i
+			affected = append(affected, AffectedConversation{ConversationID: conversationID, SenderID: senderID})
+		}
+		affected[i].MarkedCount++
+		if !deletedForRecipient {
+			affected[i].MessageIDs = append(affected[i].MessageIDs, messageID)
+		}
 	}
+	return affected, rows.Err()
+}
 
-	_, err = r.pool.Exec(ctx, query, messageID)
-	return err
+// HideForUser hides a message for one participant only; the other participant
+// still sees it. Once both sender and recipient have hidden the message it is
+// hard-deleted in the same transaction. hardDeleted reports whether that happened.
+// Returns pgx.ErrNoRows if the message does not exist or userID is not a participant.
+func (r *MessageRepository) HideForUser(ctx context.Context, messageID int, userID int) (hardDeleted bool, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var deletedForSender, deletedForRecipient bool
+	err = tx.QueryRow(ctx, `
+		UPDATE messages
+		SET deleted_for_sender = deleted_for_sender OR sender_id = $2,
+		    deleted_for_recipient = deleted_for_recipient OR recipient_id = $2
+		WHERE id = $1 AND (sender_id = $2 OR recipient_id = $2)
+		RETURNING deleted_for_sender, deleted_for_recipient
+	`, messageID, userID).Scan(&deletedForSender, &deletedForRecipient)
+	if err != nil {
+		return false, err
+	}
+
+	if deletedForSender && deletedForRecipient {
+		if _, err := tx.Exec(ctx, `DELETE FROM messages WHERE id = $1`, messageID); err != nil {
+			return false, err
+		}
+		hardDeleted = true
+	}
+
+	return hardDeleted, tx.Commit(ctx)
 }
 
 // SoftDeleteForBoth marks a message as deleted for both sender and recipient
@@ -323,6 +601,42 @@ func (r *MessageRepository) GetUnreadCount(ctx context.Context, conversationID i
 	return count, err
 }
 
+// UnreadCountsByConversation returns the number of unread messages addressed to
+// userID in each of convIDs using a single query. Conversations without unread
+// messages are absent from the map.
+func (r *MessageRepository) UnreadCountsByConversation(ctx context.Context, userID int, convIDs []int) (map[int]int, error) {
+	counts := make(map[int]int, len(convIDs))
+	if len(convIDs) == 0 {
+		return counts, nil
+	}
+
+	query := `
+		SELECT conversation_id, COUNT(*)
+		FROM messages
+		WHERE conversation_id = ANY($2)
+		  AND recipient_id = $1
+		  AND read_at IS NULL
+		  AND deleted_for_recipient = false
+		GROUP BY conversation_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, convIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var conversationID, count int
+		if err := rows.Scan(&conversationID, &count); err != nil {
+			return nil, err
+		}
+		counts[conversationID] = count
+	}
+
+	return counts, rows.Err()
+}
+
 // GetLatestMessage gets the most recent message in a conversation
 func (r *MessageRepository) GetLatestMessage(ctx context.Context, conversationID int) (*Message, error) {
 	message := &Message{}
@@ -333,13 +647,16 @@ func (r *MessageRepository) GetLatestMessage(ctx context.Context, conversationID
 		       m.message_type, m.sent_at, m.delivered_at, m.read_at,
 		       m.deleted_for_sender, m.deleted_for_recipient,
 		       m.media_file_id,
-		       COALESCE(mf.storage_url, m.media_url) as media_url,
+		       COALESCE(mf.playable_url, mf.storage_url, m.media_url) as media_url,
 		       COALESCE(m.media_type, mf.file_type) as media_type,
 		       COALESCE(m.media_size, mf.file_size) as media_size,
+		       mf.processing_status,
 		       m.encryption_version,
 		       m.media_encryption_key,
 		       m.media_encryption_iv,
-		       m.sender_media_encryption_key
+		       m.sender_media_encryption_key,
+		       m.is_edited,
+		       m.edited_at
 		FROM messages m
 		LEFT JOIN media_files mf ON m.media_file_id = mf.id
 		WHERE m.conversation_id = $1
@@ -364,10 +681,13 @@ func (r *MessageRepository) GetLatestMessage(ctx context.Context, conversationID
 		&message.MediaURL,
 		&message.MediaType,
 		&message.MediaSize,
+		&message.MediaStatus,
 		&message.EncryptionVersion,
 		&message.MediaEncryptionKey,
 		&message.MediaEncryptionIV,
 		&message.SenderMediaEncryptionKey,
+		&message.IsEdited,
+		&message.EditedAt,
 	)
 
 	if err != nil {
@@ -377,6 +697,68 @@ func (r *MessageRepository) GetLatestMessage(ctx context.Context, conversationID
 	return message, nil
 }
 
+// AddReaction records userID reacting to a message with emoji.
+// Reacting twice with the same emoji is a no-op; added reports whether a row was inserted.
+func (r *MessageRepository) AddReaction(ctx context.Context, messageID int, userID int, emoji string) (bool, error) {
+	query := `
+		INSERT INTO message_reactions (message_id, user_id, emoji)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	result, err := r.pool.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// RemoveReaction deletes userID's emoji reaction from a message; removed reports whether one existed
+func (r *MessageRepository) RemoveReaction(ctx context.Context, messageID int, userID int, emoji string) (bool, error) {
+	query := `
+		DELETE FROM message_reactions
+		WHERE message_id = $1 AND user_id = $2 AND emoji = $3
+	`
+	result, err := r.pool.Exec(ctx, query, messageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// GetReactions returns aggregated reactions keyed by message ID, each list
+// ordered by when the emoji was first used. Messages without reactions are absent.
+func (r *MessageRepository) GetReactions(ctx context.Context, messageIDs []int) (map[int][]MessageReaction, error) {
+	reactions := make(map[int][]MessageReaction, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+
+	query := `
+		SELECT message_id, emoji, COUNT(*), array_agg(user_id ORDER BY created_at, user_id)
+		FROM message_reactions
+		WHERE message_id = ANY($1)
+		GROUP BY message_id, emoji
+		ORDER BY message_id, MIN(created_at), emoji
+	`
+
+	rows, err := r.pool.Query(ctx, query, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID int
+		var reaction MessageReaction
+		if err := rows.Scan(&messageID, &reaction.Emoji, &reaction.Count, &reaction.UserIDs); err != nil {
+			return nil, err
+		}
+		reactions[messageID] = append(reactions[messageID], reaction)
+	}
+
+	return reactions, rows.Err()
+}
+
 // IsParticipant checks if a user is a participant in the message
 func (m *Message) IsParticipant(userID int) bool {
 	return m.SenderID == userID || m.RecipientID == userID
@@ -76,6 +76,13 @@ func (r *ModLogRepository) Log(ctx context.Context, hubID, moderatorID int, acti
 
 // GetByHub retrieves mod logs for a specific hub with pagination
 func (r *ModLogRepository) GetByHub(ctx context.Context, hubID int, limit, offset int) ([]*ModLog, error) {
+	return r.GetByHubFiltered(ctx, hubID, "", nil, limit, offset)
+}
+
+// GetByHubFiltered retrieves a hub's mod logs, optionally narrowed to one
+// action type and/or one moderator. An empty actionType or nil moderatorID
+// leaves that filter off.
+func (r *ModLogRepository) GetByHubFiltered(ctx context.Context, hubID int, actionType string, moderatorID *int, limit, offset int) ([]*ModLog, error) {
 	query := `
 		SELECT ml.id, ml.hub_id, ml.moderator_id, ml.action, ml.target_type, ml.target_id,
 			   ml.details, ml.created_at, u.username as moderator_name, h.name as hub_name
@@ -83,11 +90,13 @@ func (r *ModLogRepository) GetByHub(ctx context.Context, hubID int, limit, offse
 		JOIN users u ON ml.moderator_id = u.id
 		JOIN hubs h ON ml.hub_id = h.id
 		WHERE ml.hub_id = $1
+		AND ($2 = '' OR ml.action = $2)
+		AND ($3::INTEGER IS NULL OR ml.moderator_id = $3)
 		ORDER BY ml.created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT $4 OFFSET $5
 	`
 
-	rows, err := r.db.Query(ctx, query, hubID, limit, offset)
+	rows, err := r.db.Query(ctx, query, hubID, actionType, moderatorID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get mod logs: %w", err)
 	}
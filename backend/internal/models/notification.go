@@ -136,6 +136,133 @@ func (r *NotificationRepository) GetByUserID(
 	return notifications, rows.Err()
 }
 
+// NotificationGroupWindow is how close together notifications about the same
+// target must be to collapse into one group. Windows are fixed buckets of this
+// length, not sliding, so a burst across a boundary yields two groups.
+const NotificationGroupWindow = 24 * time.Hour
+
+// GroupedNotification is the newest notification of a group plus how many
+// notifications about the same target it stands for
+type GroupedNotification struct {
+	Notification
+	Count    int       `json:"count"`
+	LatestAt time.Time `json:"latest_at"`
+	// IDs lists every notification in the group, newest first
+	IDs []int `json:"ids"`
+}
+
+// GetGrouped retrieves a user's notifications with those of the same type about
+// the same content within one NotificationGroupWindow collapsed into a single
+// entry. Notifications without a content target are never grouped. A group is
+// read only once all of its notifications are.
+func (r *NotificationRepository) GetGrouped(ctx context.Context, userID int, limit int, offset int) ([]*GroupedNotification, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH groups AS (
+			SELECT
+				MAX(id) AS latest_id,
+				COUNT(*) AS count,
+				MAX(created_at) AS latest_at,
+				BOOL_AND(read) AS all_read,
+				ARRAY_AGG(id ORDER BY id DESC) AS ids
+			FROM notifications
+			WHERE user_id = $1
+			GROUP BY
+				notification_type, content_type, content_id,
+				CASE WHEN content_id IS NULL THEN id END,
+				FLOOR(EXTRACT(EPOCH FROM created_at) / $4::numeric)
+		)
+		SELECT
+			n.id, n.user_id, n.notification_type, n.content_type, n.content_id,
+			n.actor_id, n.milestone_count, n.votes_per_hour, n.message, n.created_at,
+			g.all_read, g.count, g.latest_at, g.ids,
+			u.id, u.username, u.avatar_url
+		FROM groups g
+		JOIN notifications n ON n.id = g.latest_id
+		LEFT JOIN users u ON n.actor_id = u.id
+		ORDER BY g.latest_at DESC, n.id DESC
+		LIMIT $2 OFFSET $3
+	`, userID, limit, offset, int64(NotificationGroupWindow/time.Second))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []*GroupedNotification
+	for rows.Next() {
+		g := &GroupedNotification{}
+		var actorID *int
+		var actorUsername *string
+		var actorAvatar *string
+
+		err := rows.Scan(
+			&g.ID, &g.UserID, &g.NotificationType, &g.ContentType, &g.ContentID,
+			&g.ActorID, &g.MilestoneCount, &g.VotesPerHour, &g.Message, &g.CreatedAt,
+			&g.Read, &g.Count, &g.LatestAt, &g.IDs,
+			&actorID, &actorUsername, &actorAvatar,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if actorID != nil {
+			g.Actor = &User{ID: *actorID, AvatarURL: actorAvatar}
+			if actorUsername != nil {
+				g.Actor.Username = *actorUsername
+			}
+		}
+
+		groups = append(groups, g)
+	}
+
+	return groups, rows.Err()
+}
+
+// FoldIntoUnread merges another occurrence into the user's newest unread
+// notification of the given type created since the cutoff. milestone_count
+// tracks how many occurrences the notification covers and message is rebuilt
+// from that count. Returns the updated notification, or nil if there was none
+// to fold into.
+func (r *NotificationRepository) FoldIntoUnread(
+	ctx context.Context,
+	userID int,
+	notificationType string,
+	since time.Time,
+	message func(count int) string,
+) (*Notification, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	n := &Notification{}
+	err = tx.QueryRow(ctx, `
+		SELECT id, user_id, notification_type, COALESCE(milestone_count, 1), created_at
+		FROM notifications
+		WHERE user_id = $1 AND notification_type = $2 AND read = false AND created_at >= $3
+		ORDER BY created_at DESC
+		LIMIT 1
+		FOR UPDATE
+	`, userID, notificationType, since).Scan(&n.ID, &n.UserID, &n.NotificationType, &n.MilestoneCount, &n.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	count := *n.MilestoneCount + 1
+	n.MilestoneCount = &count
+	n.Message = message(count)
+	if _, err := tx.Exec(ctx, `
+		UPDATE notifications SET milestone_count = $2, message = $3 WHERE id = $1
+	`, n.ID, count, n.Message); err != nil {
+		return nil, err
+	}
+
+	return n, tx.Commit(ctx)
+}
+
 // GetUnreadCount returns the count of unread notifications for a user
 func (r *NotificationRepository) GetUnreadCount(ctx context.Context, userID int) (int, error) {
 	var count int
@@ -170,6 +297,27 @@ func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID int)
 	return err
 }
 
+// NotificationTypesFor resolves a notification type or category name (see
+// NotificationCategories) to the notification types it covers. Returns nil if
+// it is neither.
+func NotificationTypesFor(typeOrCategory string) []string {
+	if types, ok := notificationCategoryTypes[typeOrCategory]; ok {
+		return types
+	}
+	if NotificationCategoryFor(typeOrCategory) != "" {
+		return []string{typeOrCategory}
+	}
+	return nil
+}
+
+// MarkAllReadByType marks a user's notifications of one type as read. notifType
+// may also be a category such as "reply", which covers every type in it.
+func (r *NotificationRepository) MarkAllReadByType(ctx context.Context, userID int, notifType string) error {
+	query := `UPDATE notifications SET read = true WHERE user_id = $1 AND notification_type = ANY($2) AND read = false`
+	_, err := r.pool.Exec(ctx, query, userID, NotificationTypesFor(notifType))
+	return err
+}
+
 // Delete deletes a notification
 func (r *NotificationRepository) Delete(ctx context.Context, notificationID, userID int) error {
 	query := `DELETE FROM notifications WHERE id = $1 AND user_id = $2`
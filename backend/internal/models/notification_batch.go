@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -18,6 +19,7 @@ type NotificationBatch struct {
 	MilestoneCount   *int       `json:"milestone_count,omitempty"`
 	ScheduledFor     time.Time  `json:"scheduled_for"`
 	Status           string     `json:"status"`
+	Cadence          *string    `json:"cadence,omitempty"` // Set for batches held for an hourly/daily digest
 	CreatedAt        time.Time  `json:"created_at"`
 	ProcessedAt      *time.Time `json:"processed_at,omitempty"`
 }
@@ -37,8 +39,8 @@ func (r *NotificationBatchRepository) Create(ctx context.Context, batch *Notific
 	query := `
 		INSERT INTO notification_batches (
 			user_id, content_type, content_id, notification_type,
-			votes_per_hour, milestone_count, scheduled_for, status
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			votes_per_hour, milestone_count, scheduled_for, status, cadence
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at
 	`
 
@@ -52,10 +54,12 @@ func (r *NotificationBatchRepository) Create(ctx context.Context, batch *Notific
 		batch.MilestoneCount,
 		batch.ScheduledFor,
 		batch.Status,
+		batch.Cadence,
 	).Scan(&batch.ID, &batch.CreatedAt)
 }
 
-// GetPendingBatches retrieves all pending batches scheduled before the given time
+// GetPendingBatches retrieves all pending batches scheduled before the given time.
+// Batches held for a digest are left to GetDueDigestBatches.
 func (r *NotificationBatchRepository) GetPendingBatches(
 	ctx context.Context,
 	beforeTime time.Time,
@@ -64,9 +68,10 @@ func (r *NotificationBatchRepository) GetPendingBatches(
 		SELECT
 			id, user_id, content_type, content_id, notification_type,
 			votes_per_hour, milestone_count, scheduled_for, status,
-			created_at, processed_at
+			created_at, processed_at, cadence
 		FROM notification_batches
 		WHERE status = 'pending'
+		AND cadence IS NULL
 		AND scheduled_for <= $1
 		ORDER BY scheduled_for ASC
 		LIMIT 1000
@@ -76,6 +81,36 @@ func (r *NotificationBatchRepository) GetPendingBatches(
 	if err != nil {
 		return nil, err
 	}
+	return scanNotificationBatches(rows)
+}
+
+// GetDueDigestBatches retrieves the pending batches held for a cadence's digest
+// whose window closed before the given time, grouped by user
+func (r *NotificationBatchRepository) GetDueDigestBatches(
+	ctx context.Context,
+	cadence string,
+	beforeTime time.Time,
+) ([]*NotificationBatch, error) {
+	query := `
+		SELECT
+			id, user_id, content_type, content_id, notification_type,
+			votes_per_hour, milestone_count, scheduled_for, status,
+			created_at, processed_at, cadence
+		FROM notification_batches
+		WHERE status = 'pending'
+		AND cadence = $1
+		AND scheduled_for <= $2
+		ORDER BY user_id ASC, scheduled_for ASC, id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, cadence, beforeTime)
+	if err != nil {
+		return nil, err
+	}
+	return scanNotificationBatches(rows)
+}
+
+func scanNotificationBatches(rows pgx.Rows) ([]*NotificationBatch, error) {
 	defer rows.Close()
 
 	var batches []*NotificationBatch
@@ -84,7 +119,7 @@ func (r *NotificationBatchRepository) GetPendingBatches(
 		err := rows.Scan(
 			&b.ID, &b.UserID, &b.ContentType, &b.ContentID, &b.NotificationType,
 			&b.VotesPerHour, &b.MilestoneCount, &b.ScheduledFor, &b.Status,
-			&b.CreatedAt, &b.ProcessedAt,
+			&b.CreatedAt, &b.ProcessedAt, &b.Cadence,
 		)
 		if err != nil {
 			return nil, err
@@ -106,6 +141,17 @@ func (r *NotificationBatchRepository) MarkAsProcessed(ctx context.Context, batch
 	return err
 }
 
+// MarkAllAsProcessed marks several batches as processed at once
+func (r *NotificationBatchRepository) MarkAllAsProcessed(ctx context.Context, batchIDs []int) error {
+	query := `
+		UPDATE notification_batches
+		SET status = 'processed', processed_at = NOW()
+		WHERE id = ANY($1)
+	`
+	_, err := r.pool.Exec(ctx, query, batchIDs)
+	return err
+}
+
 // CancelBatch cancels pending batches for specific content
 // This is used when velocity increases and we want to send immediate notification instead
 func (r *NotificationBatchRepository) CancelBatch(
@@ -3,10 +3,13 @@ package models
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -31,6 +34,7 @@ type PlatformPost struct {
 	MediaURL     *string `json:"media_url,omitempty"`
 	MediaType    *string `json:"media_type,omitempty"`
 	ThumbnailURL *string `json:"thumbnail_url,omitempty"`
+	MediaStatus  *string `json:"media_status,omitempty"` // processing, ready or failed when media_url is an upload
 
 	// Engagement metrics
 	Score       int     `json:"score"`
@@ -45,14 +49,18 @@ type PlatformPost struct {
 
 	// Status
 	IsDeleted bool       `json:"is_deleted"`
+	IsRemoved bool       `json:"is_removed"` // Removed by a moderator or automod
 	IsEdited  bool       `json:"is_edited"`
 	EditedAt  *time.Time `json:"edited_at,omitempty"`
+	IsPinned  bool       `json:"is_pinned"`
+	PinOrder  *int       `json:"pin_order,omitempty"` // Position among the hub's pinned posts (1 = first)
 
 	// Crosspost information (if this post is a crosspost)
 	CrosspostOriginType      *string `json:"crosspost_origin_type,omitempty"`      // "reddit" or "platform"
 	CrosspostOriginSubreddit *string `json:"crosspost_origin_subreddit,omitempty"` // For Reddit crossposts (source subreddit)
 	CrosspostOriginPostID    *string `json:"crosspost_origin_post_id,omitempty"`   // Reddit post ID or platform post ID
 	CrosspostOriginalTitle   *string `json:"crosspost_original_title,omitempty"`   // Original title before editing
+	CrosspostCount           int     `json:"crosspost_count"`                      // Live (not deleted or removed) crossposts of this post
 
 	// Subreddit association (for posts that belong to a subreddit context)
 	TargetSubreddit *string `json:"target_subreddit,omitempty"` // Subreddit this post is posted to
@@ -86,7 +94,7 @@ const platformPostSelectColumns = `
 	score, upvotes, downvotes, num_comments, view_count,
 	is_deleted, is_edited, edited_at,
 	crosspost_origin_type, crosspost_origin_subreddit, crosspost_origin_post_id, crosspost_original_title,
-	target_subreddit, crossposted_at, created_at, hot_score
+	target_subreddit, crossposted_at, created_at, hot_score, is_pinned, pin_order, crosspost_count, is_removed
 `
 
 const platformPostSelectColumnsPrefixed = `
@@ -94,7 +102,7 @@ const platformPostSelectColumnsPrefixed = `
 	p.score, p.upvotes, p.downvotes, p.num_comments, p.view_count,
 	p.is_deleted, p.is_edited, p.edited_at,
 	p.crosspost_origin_type, p.crosspost_origin_subreddit, p.crosspost_origin_post_id, p.crosspost_original_title,
-	p.target_subreddit, p.crossposted_at, p.created_at, p.hot_score
+	p.target_subreddit, p.crossposted_at, p.created_at, p.hot_score, p.is_pinned, p.pin_order, p.crosspost_count, p.is_removed
 `
 
 // PlatformPostRepository handles database operations for platform posts
@@ -119,7 +127,13 @@ func (r *PlatformPostRepository) Create(ctx context.Context, post *PlatformPost)
 		RETURNING id, score, upvotes, downvotes, num_comments, view_count, is_deleted, is_edited, edited_at, crossposted_at, created_at
 	`
 
-	return r.pool.QueryRow(ctx, query,
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, query,
 		post.AuthorID,
 		post.HubID,
 		post.Title,
@@ -147,6 +161,15 @@ func (r *PlatformPostRepository) Create(ctx context.Context, post *PlatformPost)
 		&post.CrosspostedAt,
 		&post.CreatedAt,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := adjustCrosspostOriginCount(ctx, tx, post.CrosspostOriginType, post.CrosspostOriginPostID, 1); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 // GetByID retrieves a post by its ID
@@ -171,6 +194,28 @@ func (r *PlatformPostRepository) GetByID(ctx context.Context, id int) (*Platform
 	return post, nil
 }
 
+// GetByIDIncludingDeleted retrieves a post by its ID even if it has been soft-deleted
+func (r *PlatformPostRepository) GetByIDIncludingDeleted(ctx context.Context, id int) (*PlatformPost, error) {
+	post := &PlatformPost{}
+
+	query := `
+		SELECT ` + platformPostSelectColumns + `
+		FROM platform_posts
+		WHERE id = $1
+	`
+
+	err := scanPlatformPost(r.pool.QueryRow(ctx, query, id), post)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return post, nil
+}
+
 // GetByIDWithUser retrieves a single post by ID with user vote information
 func (r *PlatformPostRepository) GetByIDWithUser(ctx context.Context, id int, userID *int) (*PlatformPost, error) {
 	post := &PlatformPost{}
@@ -204,27 +249,117 @@ func (r *PlatformPostRepository) GetByIDWithUser(ctx context.Context, id int, us
 	return post, nil
 }
 
-// GetFeed retrieves a feed of posts ordered by creation time or score
-func (r *PlatformPostRepository) GetFeed(ctx context.Context, sortBy string, limit, offset int) ([]*PlatformPost, error) {
+// GetByIDs retrieves several posts in one query, in the order of ids.
+// Missing posts and posts userID can't see (deleted, removed, or in a private
+// hub they can't access) are omitted. When userID is provided each post
+// carries that user's vote. NSFW posts are included; callers that honour the
+// viewer's NSFW preference use GetVisibleByIDs.
+func (r *PlatformPostRepository) GetByIDs(ctx context.Context, ids []int, userID *int) ([]*PlatformPost, error) {
+	return r.GetVisibleByIDs(ctx, ids, userID, true)
+}
+
+// GetFeed retrieves a feed of posts ordered by creation time or score.
+// Removed posts and private hubs viewerID can't access are left out.
+func (r *PlatformPostRepository) GetFeed(ctx context.Context, sortBy string, limit, offset int, viewerID *int) ([]*PlatformPost, error) {
 	var orderClause string
 	switch sortBy {
 	case "hot", "score":
-		orderClause = "ORDER BY score DESC, created_at DESC"
+		orderClause = "ORDER BY p.score DESC, p.created_at DESC"
 	case "new":
-		orderClause = "ORDER BY created_at DESC"
+		orderClause = "ORDER BY p.created_at DESC"
 	default:
-		orderClause = "ORDER BY created_at DESC"
+		orderClause = "ORDER BY p.created_at DESC"
 	}
 
 	query := `
-		SELECT ` + platformPostSelectColumns + `
-		FROM platform_posts
-		WHERE is_deleted = FALSE
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.is_deleted = FALSE AND p.is_removed = FALSE
+		AND ` + hubAccessibleToViewerClause(3) + `
 		` + orderClause + `
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.pool.Query(ctx, query, limit, offset)
+	rows, err := r.pool.Query(ctx, query, limit, offset, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*PlatformPost
+	for rows.Next() {
+		post := &PlatformPost{}
+		if err := scanPlatformPost(rows, post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// PostSearchFilters narrows a post search. Zero values don't filter; an
+// unknown hub or author name matches nothing. Results are always limited to
+// posts ViewerID may see (nil for anonymous searches).
+type PostSearchFilters struct {
+	HubName        string
+	AuthorUsername string
+	After          *time.Time
+	Before         *time.Time
+	IncludeNSFW    bool
+	ViewerID       *int
+}
+
+// Search finds posts matching a full-text query. Sorts:
+//   - "relevance" (default): ts_rank divided by the log of the post's age in
+//     days, so a fresh post with a decent match beats a years-old one
+//   - "new" / "old": by creation time
+//   - "top": by score
+func (r *PlatformPostRepository) Search(ctx context.Context, query, sortBy string, filters PostSearchFilters, limit, offset int) ([]*PlatformPost, error) {
+	var orderClause string
+	switch sortBy {
+	case "new":
+		orderClause = "ORDER BY p.created_at DESC, p.id DESC"
+	case "old":
+		orderClause = "ORDER BY p.created_at ASC, p.id ASC"
+	case "top":
+		orderClause = "ORDER BY p.score DESC, p.created_at DESC"
+	default:
+		orderClause = `ORDER BY ts_rank(p.search_vector, plainto_tsquery('english', $1))
+			/ LN(2 + GREATEST(EXTRACT(EPOCH FROM NOW() - p.created_at), 0) / 86400) DESC,
+			p.created_at DESC`
+	}
+
+	args := []interface{}{query, limit, offset, filters.IncludeNSFW, filters.ViewerID}
+	paramIndex := 6
+	whereClause := ""
+	if filters.HubName != "" {
+		whereClause += fmt.Sprintf(" AND p.hub_id = (SELECT id FROM hubs WHERE name = $%d)", paramIndex)
+		args = append(args, filters.HubName)
+		paramIndex++
+	}
+	if filters.AuthorUsername != "" {
+		whereClause += fmt.Sprintf(" AND p.author_id = (SELECT id FROM users WHERE username = $%d)", paramIndex)
+		args = append(args, filters.AuthorUsername)
+		paramIndex++
+	}
+	timeClause, timeArgs := buildTimeRangeClause(filters.After, filters.Before, paramIndex)
+	whereClause += timeClause
+	args = append(args, timeArgs...)
+
+	sql := `
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.search_vector @@ plainto_tsquery('english', $1)
+		AND ` + postVisibleToViewerClause(5, 4) + `
+		` + whereClause + `
+		` + orderClause + `
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, sql, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -242,17 +377,20 @@ func (r *PlatformPostRepository) GetFeed(ctx context.Context, sortBy string, lim
 	return posts, rows.Err()
 }
 
-// GetByAuthor retrieves posts by a specific author
-func (r *PlatformPostRepository) GetByAuthor(ctx context.Context, authorID int, limit, offset int) ([]*PlatformPost, error) {
+// GetByAuthor retrieves posts by a specific author, leaving out posts in
+// private hubs viewerID can't access (nil for anonymous viewers)
+func (r *PlatformPostRepository) GetByAuthor(ctx context.Context, authorID int, viewerID *int, limit, offset int) ([]*PlatformPost, error) {
 	query := `
-		SELECT ` + platformPostSelectColumns + `
-		FROM platform_posts
-		WHERE author_id = $1 AND is_deleted = FALSE
-		ORDER BY created_at DESC
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.author_id = $1 AND p.is_deleted = FALSE
+		AND ` + hubAccessibleToViewerClause(4) + `
+		ORDER BY p.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, authorID, limit, offset)
+	rows, err := r.pool.Query(ctx, query, authorID, limit, offset, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -284,19 +422,20 @@ func (r *PlatformPostRepository) GetByHubWithUser(
 	userID *int,
 	startTime, endTime *time.Time,
 ) ([]*PlatformPost, error) {
+	// Pinned posts always lead the hub feed, in their configured order
 	var orderClause string
 	switch sortBy {
 	case "hot":
-		orderClause = "ORDER BY p.hot_score DESC, p.created_at DESC"
+		orderClause = "ORDER BY " + pinnedFirstOrder + "p.hot_score DESC, p.created_at DESC"
 	case "new":
-		orderClause = "ORDER BY p.created_at DESC"
+		orderClause = "ORDER BY " + pinnedFirstOrder + "p.created_at DESC"
 	case "top":
-		orderClause = "ORDER BY p.score DESC, p.created_at DESC"
+		orderClause = "ORDER BY " + pinnedFirstOrder + "p.score DESC, p.created_at DESC"
 	case "rising":
 		// Rising sort: score divided by age in hours
-		orderClause = `ORDER BY (p.score::float / GREATEST(EXTRACT(EPOCH FROM (NOW() - p.created_at)) / 3600, 1)) DESC`
+		orderClause = `ORDER BY ` + pinnedFirstOrder + `(p.score::float / GREATEST(EXTRACT(EPOCH FROM (NOW() - p.created_at)) / 3600, 1)) DESC`
 	default:
-		orderClause = "ORDER BY p.hot_score DESC, p.created_at DESC"
+		orderClause = "ORDER BY " + pinnedFirstOrder + "p.hot_score DESC, p.created_at DESC"
 	}
 
 	timeClause, timeArgs := buildTimeRangeClause(startTime, endTime, 5)
@@ -411,17 +550,36 @@ func (r *PlatformPostRepository) GetBySubredditWithUser(
 	return posts, rows.Err()
 }
 
-// GetByTags retrieves posts that contain any of the specified tags
-func (r *PlatformPostRepository) GetByTags(ctx context.Context, tags []string, limit, offset int) ([]*PlatformPost, error) {
+// GetByTags retrieves posts tagged with any (or, with matchAll, every) of the
+// specified tags. Each post is a single row, so a post carrying several of the
+// requested tags is returned once; id breaks ties so pagination is stable.
+// Only posts viewerID may see are returned, and quarantined hubs are left out.
+func (r *PlatformPostRepository) GetByTags(ctx context.Context, tags []string, matchAll bool, sortBy string, viewerID *int, includeNSFW bool, limit, offset int) ([]*PlatformPost, error) {
+	tagOperator := "&&"
+	if matchAll {
+		tagOperator = "@>"
+	}
+
+	var orderBy string
+	switch sortBy {
+	case "top":
+		orderBy = "p.score DESC, p.created_at DESC, p.id DESC"
+	default:
+		orderBy = "p.created_at DESC, p.id DESC"
+	}
+
 	query := `
-		SELECT ` + platformPostSelectColumns + `
-		FROM platform_posts
-		WHERE tags && $1 AND is_deleted = FALSE
-		ORDER BY created_at DESC
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.tags ` + tagOperator + ` $1
+		AND COALESCE(h.is_quarantined, FALSE) = FALSE
+		AND ` + postVisibleToViewerClause(4, 5) + `
+		ORDER BY ` + orderBy + `
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, tags, limit, offset)
+	rows, err := r.pool.Query(ctx, query, tags, limit, offset, viewerID, includeNSFW)
 	if err != nil {
 		return nil, err
 	}
@@ -439,8 +597,99 @@ func (r *PlatformPostRepository) GetByTags(ctx context.Context, tags []string, l
 	return posts, rows.Err()
 }
 
-// Update updates a post's content
+// GetFeedExcludingTags retrieves the platform feed without posts carrying any of excludeTags
+func (r *PlatformPostRepository) GetFeedExcludingTags(ctx context.Context, sortBy string, excludeTags []string, limit, offset int, viewerID *int) ([]*PlatformPost, error) {
+	return r.GetFeedFiltered(ctx, sortBy, nil, excludeTags, limit, offset, viewerID)
+}
+
+// GetFeedFiltered retrieves the platform feed restricted to posts tagged with any
+// of includeTags (when non-empty) and without any of excludeTags. Untagged posts
+// are never excluded. Sorting and visibility follow GetFeed, with id breaking ties.
+func (r *PlatformPostRepository) GetFeedFiltered(ctx context.Context, sortBy string, includeTags, excludeTags []string, limit, offset int, viewerID *int) ([]*PlatformPost, error) {
+	var orderClause string
+	switch sortBy {
+	case "hot", "score":
+		orderClause = "ORDER BY p.score DESC, p.created_at DESC, p.id DESC"
+	default:
+		orderClause = "ORDER BY p.created_at DESC, p.id DESC"
+	}
+
+	args := []interface{}{viewerID}
+	whereClause := "WHERE p.is_deleted = FALSE AND p.is_removed = FALSE AND " + hubAccessibleToViewerClause(1)
+	if len(includeTags) > 0 {
+		args = append(args, includeTags)
+		whereClause += fmt.Sprintf(" AND p.tags && $%d", len(args))
+	}
+	if len(excludeTags) > 0 {
+		args = append(args, excludeTags)
+		whereClause += fmt.Sprintf(" AND NOT (COALESCE(p.tags, '{}') && $%d)", len(args))
+	}
+	args = append(args, limit, offset)
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		%s
+		%s
+		LIMIT $%d OFFSET $%d
+	`, platformPostSelectColumnsPrefixed, whereClause, orderClause, len(args)-1, len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*PlatformPost
+	for rows.Next() {
+		post := &PlatformPost{}
+		if err := scanPlatformPost(rows, post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// PostEdit is a prior version of a platform post's title and body
+type PostEdit struct {
+	Title string  `json:"title"`
+	Body  *string `json:"body,omitempty"`
+	// EditedAt is when this version was replaced by an edit
+	EditedAt time.Time `json:"edited_at"`
+}
+
+// postEditExecer is satisfied by both the pool and a transaction
+type postEditExecer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
+// Update updates a post's content. When the title or body changes the previous
+// version is recorded in post_edit_history in the same transaction.
 func (r *PlatformPostRepository) Update(ctx context.Context, post *PlatformPost) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var prevTitle string
+	var prevBody *string
+	if err := tx.QueryRow(ctx,
+		`SELECT title, body FROM platform_posts WHERE id = $1 AND is_deleted = FALSE FOR UPDATE`,
+		post.ID,
+	).Scan(&prevTitle, &prevBody); err != nil {
+		return err
+	}
+
+	if prevTitle != post.Title || !equalOptionalString(prevBody, post.Body) {
+		if err := recordPostEdit(ctx, tx, post.ID, prevTitle, prevBody); err != nil {
+			return err
+		}
+	}
+
 	query := `
 		UPDATE platform_posts
 		SET title = $1, body = $2, tags = $3, media_url = $4, media_type = $5,
@@ -449,7 +698,7 @@ func (r *PlatformPostRepository) Update(ctx context.Context, post *PlatformPost)
 		RETURNING edited_at
 	`
 
-	return r.pool.QueryRow(ctx, query,
+	if err := tx.QueryRow(ctx, query,
 		post.Title,
 		post.Body,
 		post.Tags,
@@ -457,14 +706,296 @@ func (r *PlatformPostRepository) Update(ctx context.Context, post *PlatformPost)
 		post.MediaType,
 		post.ThumbnailURL,
 		post.ID,
-	).Scan(&post.EditedAt)
+	).Scan(&post.EditedAt); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordEdit stores a prior version of a post's title and body
+func (r *PlatformPostRepository) RecordEdit(ctx context.Context, postID int, prevTitle string, prevBody *string) error {
+	return recordPostEdit(ctx, r.pool, postID, prevTitle, prevBody)
+}
+
+func recordPostEdit(ctx context.Context, db postEditExecer, postID int, prevTitle string, prevBody *string) error {
+	_, err := db.Exec(ctx,
+		`INSERT INTO post_edit_history (post_id, title, body) VALUES ($1, $2, $3)`,
+		postID, prevTitle, prevBody,
+	)
+	return err
+}
+
+// GetEditHistory returns the prior versions of a post, most recent edit first
+func (r *PlatformPostRepository) GetEditHistory(ctx context.Context, postID int) ([]PostEdit, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT title, body, edited_at
+		FROM post_edit_history
+		WHERE post_id = $1
+		ORDER BY edited_at DESC, id DESC
+	`, postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edits := []PostEdit{}
+	for rows.Next() {
+		var edit PostEdit
+		if err := rows.Scan(&edit.Title, &edit.Body, &edit.EditedAt); err != nil {
+			return nil, err
+		}
+		edits = append(edits, edit)
+	}
+
+	return edits, rows.Err()
+}
+
+func equalOptionalString(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// SoftDelete marks a post as deleted
+// Deleting a live crosspost also drops it from its origin's crosspost count
+func (r *PlatformPostRepository) SoftDelete(ctx context.Context, postID int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := lockCrosspostState(ctx, tx, postID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE platform_posts SET is_deleted = TRUE WHERE id = $1`, postID); err != nil {
+		return err
+	}
+
+	if state != nil && !state.isDeleted && !state.isRemoved {
+		if err := adjustCrosspostOriginCount(ctx, tx, state.originType, state.originPostID, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// crosspostState is the removal state and origin of a post, read under a row lock
+type crosspostState struct {
+	isDeleted    bool
+	isRemoved    bool
+	originType   *string
+	originPostID *string
+}
+
+// lockCrosspostState locks a post row and returns its crosspost state, or nil if it does not exist
+func lockCrosspostState(ctx context.Context, tx pgx.Tx, postID int) (*crosspostState, error) {
+	state := &crosspostState{}
+	err := tx.QueryRow(ctx, `
+		SELECT is_deleted, is_removed, crosspost_origin_type, crosspost_origin_post_id
+		FROM platform_posts
+		WHERE id = $1
+		FOR UPDATE
+	`, postID).Scan(&state.isDeleted, &state.isRemoved, &state.originType, &state.originPostID)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// adjustCrosspostOriginCount shifts the crosspost_count of a platform crosspost's origin.
+// Reddit crossposts and origins that no longer exist are left alone; soft-deleted
+// origins keep their count in step so it is correct if they are ever restored.
+func adjustCrosspostOriginCount(ctx context.Context, tx pgx.Tx, originType, originPostID *string, delta int) error {
+	if originType == nil || *originType != "platform" || originPostID == nil {
+		return nil
+	}
+	originID, err := strconv.Atoi(*originPostID)
+	if err != nil {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE platform_posts
+		SET crosspost_count = GREATEST(crosspost_count + $2, 0)
+		WHERE id = $1
+	`, originID, delta)
+	return err
+}
+
+// Restore undoes SoftDelete. Restoring a live crosspost increments its origin's
+// crosspost count again; posts that were also removed by a moderator stay uncounted.
+func (r *PlatformPostRepository) Restore(ctx context.Context, postID int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := lockCrosspostState(ctx, tx, postID)
+	if err != nil {
+		return err
+	}
+	if state == nil || !state.isDeleted {
+		return tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE platform_posts SET is_deleted = FALSE WHERE id = $1`, postID); err != nil {
+		return err
+	}
+
+	if !state.isRemoved {
+		if err := adjustCrosspostOriginCount(ctx, tx, state.originType, state.originPostID, 1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetCrossposts returns live crossposts of a platform post, newest first.
+// Removed and deleted crossposts are excluded, matching crosspost_count, as
+// are crossposts viewerID may not see (private hubs, NSFW unless includeNSFW).
+func (r *PlatformPostRepository) GetCrossposts(ctx context.Context, originPostID int, viewerID *int, includeNSFW bool, limit, offset int) ([]*PlatformPost, error) {
+	query := `
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.crosspost_origin_type = 'platform'
+		  AND p.crosspost_origin_post_id = $1
+		  AND ` + postVisibleToViewerClause(4, 5) + `
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, strconv.Itoa(originPostID), limit, offset, viewerID, includeNSFW)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*PlatformPost
+	for rows.Next() {
+		post := &PlatformPost{}
+		if err := scanPlatformPost(rows, post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// postVisibleToViewerClause limits p (a platform post, with its hub joined as h)
+// to posts viewerArg may see: not deleted or removed, not NSFW unless nsfwArg is
+// true, and in a hub the viewer can access (see hubAccessibleToViewerClause).
+// viewerArg may be NULL for anonymous viewers.
+func postVisibleToViewerClause(viewerArg, nsfwArg int) string {
+	return fmt.Sprintf(`
+		p.is_deleted = FALSE AND p.is_removed = FALSE
+		AND ($%[2]d OR (p.nsfw = FALSE AND COALESCE(h.nsfw, FALSE) = FALSE))
+		AND %[3]s`, viewerArg, nsfwArg, hubAccessibleToViewerClause(viewerArg))
 }
 
-// SoftDelete marks a post as deleted
-func (r *PlatformPostRepository) SoftDelete(ctx context.Context, postID int) error {
-	query := `UPDATE platform_posts SET is_deleted = TRUE WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, postID)
-	return err
+// hubAccessibleToViewerClause limits h (a hub, possibly NULL from a left join)
+// to hubs viewerArg may read and post in: anything but private hubs, and
+// private hubs the viewer created, moderates or is a member of. Site admins
+// can access every hub.
+func hubAccessibleToViewerClause(viewerArg int) string {
+	return fmt.Sprintf(`(
+			h.id IS NULL OR h.type <> 'private'
+			OR h.created_by = $%[1]d::int
+			OR EXISTS (SELECT 1 FROM hub_moderators hm WHERE hm.hub_id = h.id AND hm.user_id = $%[1]d::int)
+			OR EXISTS (SELECT 1 FROM hub_members hmb WHERE hmb.hub_id = h.id AND hmb.user_id = $%[1]d::int)
+			OR EXISTS (SELECT 1 FROM users au WHERE au.id = $%[1]d::int AND au.role = 'admin')
+		)`, viewerArg)
+}
+
+// IsAccessibleTo reports whether viewerID may read and interact with the post:
+// it exists, isn't deleted, and isn't in a private hub the viewer can't access.
+// Unlike IsVisibleTo, removed posts still count, since their pages show the
+// removal rather than disappearing.
+func (r *PlatformPostRepository) IsAccessibleTo(ctx context.Context, postID int, viewerID *int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM platform_posts p
+			LEFT JOIN hubs h ON h.id = p.hub_id
+			WHERE p.id = $1 AND p.is_deleted = FALSE AND ` + hubAccessibleToViewerClause(2) + `
+		)
+	`
+
+	var accessible bool
+	err := r.pool.QueryRow(ctx, query, postID, viewerID).Scan(&accessible)
+	return accessible, err
+}
+
+// IsVisibleTo reports whether viewerID may open the post: it exists, isn't
+// deleted or removed, and isn't in a private hub the viewer can't access.
+// NSFW is a listing preference rather than an access rule, so it isn't checked.
+func (r *PlatformPostRepository) IsVisibleTo(ctx context.Context, postID int, viewerID *int) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1
+			FROM platform_posts p
+			LEFT JOIN hubs h ON h.id = p.hub_id
+			WHERE p.id = $1 AND ` + postVisibleToViewerClause(2, 3) + `
+		)
+	`
+
+	var visible bool
+	err := r.pool.QueryRow(ctx, query, postID, viewerID, true).Scan(&visible)
+	return visible, err
+}
+
+// GetVisibleByIDs returns the posts among ids that userID may see, in the
+// order requested, with the viewer's vote. IDs that don't exist or aren't
+// visible are left out.
+func (r *PlatformPostRepository) GetVisibleByIDs(ctx context.Context, ids []int, userID *int, includeNSFW bool) ([]*PlatformPost, error) {
+	if len(ids) == 0 {
+		return []*PlatformPost{}, nil
+	}
+
+	query := `
+		SELECT ` + platformPostSelectColumnsPrefixed + `,
+		CASE
+			WHEN pv.is_upvote IS NULL THEN NULL
+			WHEN pv.is_upvote = TRUE THEN 1
+			ELSE -1
+		END as user_vote,
+		u.username, COALESCE(h.name, '')
+		FROM platform_posts p
+		JOIN users u ON u.id = p.author_id
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		LEFT JOIN post_votes pv ON pv.post_id = p.id AND pv.user_id = $2
+		WHERE p.id = ANY($1) AND ` + postVisibleToViewerClause(2, 3) + `
+		ORDER BY array_position($1::int[], p.id)
+	`
+
+	rows, err := r.pool.Query(ctx, query, ids, userID, includeNSFW)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*PlatformPost{}
+	for rows.Next() {
+		post := &PlatformPost{}
+		if err := scanPlatformPostWithVote(rows, post, &post.AuthorUsername, &post.HubName); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
 }
 
 // IncrementViewCount increments the view count for a post
@@ -480,6 +1011,23 @@ func (r *PlatformPostRepository) UpdateCreatedAt(ctx context.Context, postID int
 	return err
 }
 
+// RecomputeHotScores refreshes hot_score for posts created in the last sinceHours hours
+// using the calculate_hot_score SQL function. Only rows whose score changed are
+// rewritten; the number of updated rows is returned.
+func (r *PlatformPostRepository) RecomputeHotScores(ctx context.Context, sinceHours int) (int64, error) {
+	query := `
+		UPDATE platform_posts
+		SET hot_score = calculate_hot_score(score, 0, created_at)
+		WHERE created_at >= NOW() - make_interval(hours => $1)
+		  AND hot_score IS DISTINCT FROM calculate_hot_score(score, 0, created_at)
+	`
+	tag, err := r.pool.Exec(ctx, query, sinceHours)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
 func scanPlatformPost(row pgx.Row, post *PlatformPost, extraDest ...interface{}) error {
 	dests := []interface{}{
 		&post.ID,
@@ -507,6 +1055,10 @@ func scanPlatformPost(row pgx.Row, post *PlatformPost, extraDest ...interface{})
 		&post.CrosspostedAt,
 		&post.CreatedAt,
 		&post.HotScore,
+		&post.IsPinned,
+		&post.PinOrder,
+		&post.CrosspostCount,
+		&post.IsRemoved,
 	}
 	dests = append(dests, extraDest...)
 	return row.Scan(dests...)
@@ -539,39 +1091,55 @@ func scanPlatformPostWithVote(row pgx.Row, post *PlatformPost, extraDest ...inte
 		&post.CrosspostedAt,
 		&post.CreatedAt,
 		&post.HotScore,
+		&post.IsPinned,
+		&post.PinOrder,
+		&post.CrosspostCount,
+		&post.IsRemoved,
 		&post.UserVote,
 	}
 	dests = append(dests, extraDest...)
 	return row.Scan(dests...)
 }
 
-// Vote records a user's vote and updates aggregate counts, preventing duplicates.
+// VoteWithResult records a user's vote and updates aggregate counts, preventing duplicates.
 // isUpvote: true (upvote), false (downvote), nil (remove vote)
-func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID int, isUpvote *bool) error {
+// It returns the resulting counts and vote state as read in the same transaction.
+func (r *PlatformPostRepository) VoteWithResult(ctx context.Context, postID int, userID int, isUpvote *bool) (*VoteResult, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	// Lock the post first so concurrent votes on it serialize; otherwise two
+	// requests from the same user could both see no existing vote.
+	var lockedID int
+	err = tx.QueryRow(ctx, "SELECT id FROM platform_posts WHERE id = $1 FOR UPDATE", postID).Scan(&lockedID)
+	if err == pgx.ErrNoRows {
+		return nil, ErrVoteTargetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	var existingIsUpvote bool
 	err = tx.QueryRow(ctx, "SELECT is_upvote FROM post_votes WHERE post_id = $1 AND user_id = $2", postID, userID).Scan(&existingIsUpvote)
 	if err != nil && err != pgx.ErrNoRows {
-		return err
+		return nil, err
 	}
 
 	switch {
 	case err == pgx.ErrNoRows:
 		// New vote
 		if isUpvote == nil {
-			return tx.Commit(ctx) // nothing to remove
+			break
 		}
 
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO post_votes (post_id, user_id, is_upvote)
 			VALUES ($1, $2, $3)
 		`, postID, userID, *isUpvote); err != nil {
-			return err
+			return nil, err
 		}
 
 		if *isUpvote {
@@ -580,7 +1148,7 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 				SET upvotes = upvotes + 1, score = score + 1
 				WHERE id = $1
 			`, postID); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			if _, err := tx.Exec(ctx, `
@@ -588,13 +1156,13 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 				SET downvotes = downvotes + 1, score = score - 1
 				WHERE id = $1
 			`, postID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	case isUpvote == nil:
 		// Remove existing vote
 		if _, err := tx.Exec(ctx, `DELETE FROM post_votes WHERE post_id = $1 AND user_id = $2`, postID, userID); err != nil {
-			return err
+			return nil, err
 		}
 		if existingIsUpvote {
 			if _, err := tx.Exec(ctx, `
@@ -603,7 +1171,7 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 				    score = score - 1
 				WHERE id = $1
 			`, postID); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			if _, err := tx.Exec(ctx, `
@@ -612,12 +1180,12 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 				    score = score + 1
 				WHERE id = $1
 			`, postID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	case existingIsUpvote == *isUpvote:
 		// Duplicate same-direction vote: no-op
-		return tx.Commit(ctx)
+		break
 	default:
 		// Toggle vote direction
 		if _, err := tx.Exec(ctx, `
@@ -625,7 +1193,7 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 			SET is_upvote = $3, created_at = CURRENT_TIMESTAMP
 			WHERE post_id = $1 AND user_id = $2
 		`, postID, userID, *isUpvote); err != nil {
-			return err
+			return nil, err
 		}
 
 		if *isUpvote {
@@ -637,7 +1205,7 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 				    score = score + 2
 				WHERE id = $1
 			`, postID); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			// Up -> Down
@@ -648,17 +1216,33 @@ func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID in
 				    score = score - 2
 				WHERE id = $1
 			`, postID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 
-	return tx.Commit(ctx)
+	result := &VoteResult{UserVote: voteValue(isUpvote)}
+	if err := tx.QueryRow(ctx, "SELECT score, upvotes, downvotes FROM platform_posts WHERE id = $1", postID).
+		Scan(&result.Score, &result.Upvotes, &result.Downvotes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Vote records a vote without returning the resulting state.
+func (r *PlatformPostRepository) Vote(ctx context.Context, postID int, userID int, isUpvote *bool) error {
+	_, err := r.VoteWithResult(ctx, postID, userID, isUpvote)
+	return err
 }
 
 // GetPopularFeed returns filtered, personalized feed (h/popular)
 // Excludes quarantined hubs
 // Optionally filters by subscribed hub IDs if provided
+// Removed posts and private hubs viewerID can't access are left out
 // Sorts by hot_score DESC (or other sort option)
 func (r *PlatformPostRepository) GetPopularFeed(
 	ctx context.Context,
@@ -666,6 +1250,7 @@ func (r *PlatformPostRepository) GetPopularFeed(
 	sort string,
 	limit, offset int,
 	startTime, endTime *time.Time,
+	viewerID *int,
 ) ([]*PlatformPost, error) {
 	var orderClause string
 	switch sort {
@@ -681,11 +1266,13 @@ func (r *PlatformPostRepository) GetPopularFeed(
 		orderClause = "ORDER BY p.hot_score DESC, p.created_at DESC"
 	}
 
-	// Base WHERE clause excludes deleted posts, quarantined hubs, and crossposted posts
-	whereClause := `WHERE p.is_deleted = FALSE AND h.is_quarantined = FALSE AND p.target_subreddit IS NULL`
+	// Base WHERE clause excludes deleted and removed posts, quarantined hubs,
+	// private hubs the viewer can't access, and crossposted posts
+	whereClause := `WHERE p.is_deleted = FALSE AND p.is_removed = FALSE AND h.is_quarantined = FALSE AND p.target_subreddit IS NULL
+			AND ` + hubAccessibleToViewerClause(1)
 
-	args := []interface{}{}
-	paramIndex := 1
+	args := []interface{}{viewerID}
+	paramIndex := 2
 
 	if len(subscribedHubIDs) > 0 {
 		whereClause += fmt.Sprintf(" AND p.hub_id = ANY($%d)", paramIndex)
@@ -749,38 +1336,42 @@ func (r *PlatformPostRepository) GetPopularFeed(
 // GetAllFeed returns global firehose (h/all)
 // Includes quarantined hubs (unless user opts out)
 // No subscription filtering
+// Removed posts and private hubs viewerID can't access are left out
 // Sorts by hot_score DESC
 func (r *PlatformPostRepository) GetAllFeed(
 	ctx context.Context,
 	sort string,
 	limit, offset int,
 	startTime, endTime *time.Time,
+	viewerID *int,
 ) ([]*PlatformPost, error) {
 	var orderClause string
 	switch sort {
 	case "hot":
-		orderClause = "ORDER BY hot_score DESC, created_at DESC"
+		orderClause = "ORDER BY p.hot_score DESC, p.created_at DESC"
 	case "new":
-		orderClause = "ORDER BY created_at DESC"
+		orderClause = "ORDER BY p.created_at DESC"
 	case "top":
-		orderClause = "ORDER BY score DESC, created_at DESC"
+		orderClause = "ORDER BY p.score DESC, p.created_at DESC"
 	case "rising":
-		orderClause = `ORDER BY (score::float / GREATEST(EXTRACT(EPOCH FROM (NOW() - created_at)) / 3600, 1)) DESC`
+		orderClause = `ORDER BY (p.score::float / GREATEST(EXTRACT(EPOCH FROM (NOW() - p.created_at)) / 3600, 1)) DESC`
 	default:
-		orderClause = "ORDER BY hot_score DESC, created_at DESC"
+		orderClause = "ORDER BY p.hot_score DESC, p.created_at DESC"
 	}
 
-	timeClause, timeArgs := buildTimeRangeClause(startTime, endTime, 3)
+	timeClause, timeArgs := buildTimeRangeClause(startTime, endTime, 4)
 
 	query := `
-		SELECT ` + platformPostSelectColumns + `
-		FROM platform_posts
-		WHERE is_deleted = FALSE AND target_subreddit IS NULL` + timeClause + `
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.is_deleted = FALSE AND p.is_removed = FALSE AND p.target_subreddit IS NULL
+		AND ` + hubAccessibleToViewerClause(3) + timeClause + `
 		` + orderClause + `
 		LIMIT $1 OFFSET $2
 	`
 
-	args := []interface{}{limit, offset}
+	args := []interface{}{limit, offset, viewerID}
 	args = append(args, timeArgs...)
 
 	rows, err := r.pool.Query(ctx, query, args...)
@@ -801,26 +1392,262 @@ func (r *PlatformPostRepository) GetAllFeed(
 	return posts, rows.Err()
 }
 
+// ErrUnsupportedKeysetSort is returned when keyset pagination is requested for a sort without a stable cursor
+var ErrUnsupportedKeysetSort = errors.New("keyset pagination is only supported for the hot sort")
+
+// GetAllFeedAfter returns the next page of the global firehose (h/all) after the
+// post identified by (afterHotScore, afterID), using a keyset predicate instead of
+// OFFSET so deep pages stay fast and rows don't shift as new posts arrive.
+// Pass afterID <= 0 to fetch the first page. Only the "hot" sort is supported.
+// Visibility matches GetAllFeed.
+func (r *PlatformPostRepository) GetAllFeedAfter(
+	ctx context.Context,
+	sort string,
+	afterHotScore float64,
+	afterID int,
+	limit int,
+	viewerID *int,
+) ([]*PlatformPost, error) {
+	if sort != "" && sort != "hot" {
+		return nil, ErrUnsupportedKeysetSort
+	}
+
+	args := []interface{}{limit, viewerID}
+	cursorClause := ""
+	if afterID > 0 {
+		cursorClause = " AND (p.hot_score, p.id) < ($3, $4)"
+		args = append(args, afterHotScore, afterID)
+	}
+
+	query := `
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.is_deleted = FALSE AND p.is_removed = FALSE AND p.target_subreddit IS NULL
+		AND ` + hubAccessibleToViewerClause(2) + cursorClause + `
+		ORDER BY p.hot_score DESC, p.id DESC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*PlatformPost
+	for rows.Next() {
+		post := &PlatformPost{}
+		if err := scanPlatformPost(rows, post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// DefaultRisingWindowHours is how far back GetRisingFeed counts votes when no window is given
+const DefaultRisingWindowHours = 6
+
+// GetRisingFeed returns the global feed ordered by net votes cast in the last
+// windowHours hours, so posts gaining votes now outrank posts whose votes all
+// landed long ago. Ties fall back to the age-normalised score used by the
+// "rising" sort elsewhere. Visibility matches GetAllFeed.
+func (r *PlatformPostRepository) GetRisingFeed(ctx context.Context, windowHours int, limit, offset int, viewerID *int) ([]*PlatformPost, error) {
+	if windowHours <= 0 {
+		windowHours = DefaultRisingWindowHours
+	}
+
+	query := `
+		SELECT ` + platformPostSelectColumnsPrefixed + `
+		FROM platform_posts p
+		LEFT JOIN (
+			SELECT post_id, SUM(CASE WHEN is_upvote THEN 1 ELSE -1 END) AS recent_score
+			FROM post_votes
+			WHERE created_at >= NOW() - make_interval(hours => $1)
+			GROUP BY post_id
+		) rv ON rv.post_id = p.id
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE p.is_deleted = FALSE AND p.is_removed = FALSE AND p.target_subreddit IS NULL
+		AND ` + hubAccessibleToViewerClause(4) + `
+		ORDER BY COALESCE(rv.recent_score, 0) DESC,
+			(p.score::float / GREATEST(EXTRACT(EPOCH FROM (NOW() - p.created_at)) / 3600, 1)) DESC,
+			p.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, windowHours, limit, offset, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*PlatformPost
+	for rows.Next() {
+		post := &PlatformPost{}
+		if err := scanPlatformPost(rows, post); err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
 // MarkAsRemoved marks a post as removed by a moderator
+// Removing a live crosspost decrements its origin's crosspost count in the same transaction
 func (r *PlatformPostRepository) MarkAsRemoved(ctx context.Context, postID int, moderatorID int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := lockCrosspostState(ctx, tx, postID)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE platform_posts
 		SET is_removed = TRUE, removed_by = $2, removed_at = NOW()
 		WHERE id = $1
 	`
-	_, err := r.pool.Exec(ctx, query, postID, moderatorID)
-	return err
+	if _, err := tx.Exec(ctx, query, postID, moderatorID); err != nil {
+		return err
+	}
+
+	if state != nil && !state.isDeleted && !state.isRemoved {
+		if err := adjustCrosspostOriginCount(ctx, tx, state.originType, state.originPostID, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MarkAuthorContentRemoved removes every not-yet-removed post and comment authorID
+// has in a hub and records each one in removed_content, all in one transaction.
+// Crosspost origin counts are adjusted the same way MarkAsRemoved does.
+func (r *PlatformPostRepository) MarkAuthorContentRemoved(ctx context.Context, hubID, authorID, modID int) (postCount, commentCount int, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE platform_posts
+		SET is_removed = TRUE, removed_by = $3, removed_at = NOW()
+		WHERE hub_id = $1 AND author_id = $2 AND is_removed = FALSE
+		RETURNING id, is_deleted, crosspost_origin_type, crosspost_origin_post_id
+	`, hubID, authorID, modID)
+	if err != nil {
+		return 0, 0, err
+	}
+	var postIDs []int
+	var liveCrossposts []crosspostState
+	for rows.Next() {
+		var id int
+		var state crosspostState
+		if err := rows.Scan(&id, &state.isDeleted, &state.originType, &state.originPostID); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		postIDs = append(postIDs, id)
+		if !state.isDeleted {
+			liveCrossposts = append(liveCrossposts, state)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	for _, state := range liveCrossposts {
+		if err := adjustCrosspostOriginCount(ctx, tx, state.originType, state.originPostID, -1); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE post_comments c
+		SET is_removed = TRUE, removed_by = $3, removed_at = NOW()
+		FROM platform_posts p
+		WHERE c.post_id = p.id AND p.hub_id = $1 AND c.user_id = $2 AND c.is_removed = FALSE
+		RETURNING c.id
+	`, hubID, authorID, modID)
+	if err != nil {
+		return 0, 0, err
+	}
+	var commentIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, 0, err
+		}
+		commentIDs = append(commentIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, 0, err
+	}
+
+	// Same upsert as RemovedContentRepository.RemoveContent, one row per item
+	_, err = tx.Exec(ctx, `
+		INSERT INTO removed_content (content_type, content_id, hub_id, removed_by, custom_reason, mod_note)
+		SELECT 'post', id, $1, $2, '', '' FROM unnest($3::int[]) AS id
+		UNION ALL
+		SELECT 'comment', id, $1, $2, '', '' FROM unnest($4::int[]) AS id
+		ON CONFLICT (content_type, content_id) DO UPDATE
+			SET removed_by = EXCLUDED.removed_by,
+				removal_reason_id = NULL,
+				custom_reason = EXCLUDED.custom_reason,
+				mod_note = EXCLUDED.mod_note,
+				removed_at = NOW()
+	`, hubID, modID, postIDs, commentIDs)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, err
+	}
+	return len(postIDs), len(commentIDs), nil
 }
 
 // MarkAsApproved marks a post as approved (unremoves it)
+// Approving a removed crosspost restores its origin's crosspost count
 func (r *PlatformPostRepository) MarkAsApproved(ctx context.Context, postID int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	state, err := lockCrosspostState(ctx, tx, postID)
+	if err != nil {
+		return err
+	}
+
 	query := `
 		UPDATE platform_posts
 		SET is_removed = FALSE, removed_by = NULL, removed_at = NULL
 		WHERE id = $1
 	`
-	_, err := r.pool.Exec(ctx, query, postID)
-	return err
+	if _, err := tx.Exec(ctx, query, postID); err != nil {
+		return err
+	}
+
+	if state != nil && !state.isDeleted && state.isRemoved {
+		if err := adjustCrosspostOriginCount(ctx, tx, state.originType, state.originPostID, 1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 // LockPost locks a post to prevent new comments
@@ -837,16 +1664,153 @@ func (r *PlatformPostRepository) UnlockPost(ctx context.Context, postID int) err
 	return err
 }
 
-// PinPost pins a post to the top of the hub
+// ErrPinLimitReached is returned when a hub already has its maximum number of pinned posts
+var ErrPinLimitReached = errors.New("hub already has the maximum number of pinned posts")
+
+// pinnedFirstOrder sorts a hub's pinned posts ahead of the regular feed, in pin order
+const pinnedFirstOrder = "p.is_pinned DESC, p.pin_order ASC NULLS LAST, "
+
+// PinPost pins a post to the top of its hub, appending it after any existing pinned posts.
+// Returns ErrPinLimitReached if the hub is already at its max_pinned_posts cap.
 func (r *PlatformPostRepository) PinPost(ctx context.Context, postID int) error {
-	query := `UPDATE platform_posts SET is_pinned = TRUE WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, postID)
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var hubID *int
+	var alreadyPinned bool
+	if err := tx.QueryRow(ctx, `SELECT hub_id, is_pinned FROM platform_posts WHERE id = $1 FOR UPDATE`, postID).Scan(&hubID, &alreadyPinned); err != nil {
+		return err
+	}
+	if alreadyPinned {
+		return tx.Commit(ctx)
+	}
+
+	if hubID != nil {
+		// Lock the hub row so concurrent pins can't both slip under the cap
+		var maxPinned, pinnedCount int
+		if err := tx.QueryRow(ctx, `SELECT max_pinned_posts FROM hubs WHERE id = $1 FOR UPDATE`, *hubID).Scan(&maxPinned); err != nil {
+			return err
+		}
+		if err := tx.QueryRow(ctx, `
+			SELECT COUNT(*) FROM platform_posts
+			WHERE hub_id = $1 AND is_pinned = TRUE AND is_deleted = FALSE
+		`, *hubID).Scan(&pinnedCount); err != nil {
+			return err
+		}
+		if pinnedCount >= maxPinned {
+			return ErrPinLimitReached
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE platform_posts
+		SET is_pinned = TRUE,
+		    pinned_at = NOW(),
+		    pin_order = (
+		        SELECT COALESCE(MAX(pin_order), 0) + 1
+		        FROM platform_posts
+		        WHERE hub_id IS NOT DISTINCT FROM $2 AND is_pinned = TRUE AND is_deleted = FALSE
+		    )
+		WHERE id = $1
+	`, postID, hubID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
-// UnpinPost unpins a post
+// UnpinPost unpins a post and closes the gap it leaves in the hub's pin order
 func (r *PlatformPostRepository) UnpinPost(ctx context.Context, postID int) error {
-	query := `UPDATE platform_posts SET is_pinned = FALSE WHERE id = $1`
-	_, err := r.pool.Exec(ctx, query, postID)
-	return err
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var hubID *int
+	var pinOrder *int
+	if err := tx.QueryRow(ctx, `SELECT hub_id, pin_order FROM platform_posts WHERE id = $1 FOR UPDATE`, postID).Scan(&hubID, &pinOrder); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE platform_posts
+		SET is_pinned = FALSE, pin_order = NULL, pinned_at = NULL
+		WHERE id = $1
+	`, postID); err != nil {
+		return err
+	}
+
+	if pinOrder != nil {
+		if _, err := tx.Exec(ctx, `
+			UPDATE platform_posts
+			SET pin_order = pin_order - 1
+			WHERE hub_id IS NOT DISTINCT FROM $1 AND is_pinned = TRUE AND pin_order > $2
+		`, hubID, *pinOrder); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetPinOrder moves a pinned post to the given 1-based position among its hub's pinned posts,
+// shifting the others to keep the order contiguous. Positions past the end are clamped.
+func (r *PlatformPostRepository) SetPinOrder(ctx context.Context, postID int, position int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var hubID *int
+	var isPinned bool
+	if err := tx.QueryRow(ctx, `SELECT hub_id, is_pinned FROM platform_posts WHERE id = $1 FOR UPDATE`, postID).Scan(&hubID, &isPinned); err != nil {
+		return err
+	}
+	if !isPinned {
+		return fmt.Errorf("post %d is not pinned", postID)
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT id FROM platform_posts
+		WHERE hub_id IS NOT DISTINCT FROM $1 AND is_pinned = TRUE AND is_deleted = FALSE AND id <> $2
+		ORDER BY pin_order ASC NULLS LAST, pinned_at ASC
+		FOR UPDATE
+	`, hubID, postID)
+	if err != nil {
+		return err
+	}
+	var ordered []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return err
+		}
+		ordered = append(ordered, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if position < 1 {
+		position = 1
+	}
+	if position > len(ordered)+1 {
+		position = len(ordered) + 1
+	}
+	ordered = append(ordered[:position-1], append([]int{postID}, ordered[position-1:]...)...)
+
+	for i, id := range ordered {
+		if _, err := tx.Exec(ctx, `UPDATE platform_posts SET pin_order = $2 WHERE id = $1`, id, i+1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
@@ -0,0 +1,506 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/omninudge/backend/internal/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupPlatformPostTest(t *testing.T) (*database.DB, *User, *Hub, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	suffix := time.Now().UnixNano()
+	user := &User{Username: fmt.Sprintf("postrepo_%d", suffix), PasswordHash: "test_hash"}
+	require.NoError(t, NewUserRepository(db.Pool).Create(ctx, user))
+
+	hub := &Hub{Name: fmt.Sprintf("postrepohub_%d", suffix), CreatedBy: &user.ID}
+	require.NoError(t, NewHubRepository(db.Pool).Create(ctx, hub))
+
+	return db, user, hub, func() { db.Close() }
+}
+
+func TestGetAllFeedAfter_RejectsNonHotSort(t *testing.T) {
+	repo := NewPlatformPostRepository(nil)
+
+	_, err := repo.GetAllFeedAfter(context.Background(), "new", 0, 0, 10, nil)
+	assert.ErrorIs(t, err, ErrUnsupportedKeysetSort)
+}
+
+func TestGetAllFeedAfter_PagesWithoutDuplicatesAndStableCursor(t *testing.T) {
+	db, user, hub, cleanup := setupPlatformPostTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	// Date the fixtures in the future so they sort above anything else in the
+	// shared test database, and pair them up so hot scores tie and id breaks the tie.
+	base := time.Now().UTC().Add(10 * 365 * 24 * time.Hour)
+	createPost := func(title string, score int, createdAt time.Time) int {
+		post := &PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: title}
+		require.NoError(t, postRepo.Create(ctx, post))
+		_, err := db.Pool.Exec(ctx, `UPDATE platform_posts SET score = $2 WHERE id = $1`, post.ID, score)
+		require.NoError(t, err)
+		require.NoError(t, postRepo.UpdateCreatedAt(ctx, post.ID, createdAt))
+		return post.ID
+	}
+
+	ours := make(map[int]bool, 50)
+	for i := 0; i < 50; i++ {
+		id := createPost(fmt.Sprintf("Keyset post %d", i), 10, base.Add(time.Duration(i/2)*time.Minute))
+		ours[id] = true
+	}
+
+	var expected []int
+	rows, err := db.Pool.Query(ctx, `SELECT id FROM platform_posts WHERE hub_id = $1 ORDER BY hot_score DESC, id DESC`, hub.ID)
+	require.NoError(t, err)
+	for rows.Next() {
+		var id int
+		require.NoError(t, rows.Scan(&id))
+		expected = append(expected, id)
+	}
+	rows.Close()
+	require.NoError(t, rows.Err())
+	require.Len(t, expected, 50)
+
+	var seen []int
+	seenSet := make(map[int]bool)
+	afterHotScore, afterID := 0.0, 0
+	insertedID := 0
+
+	for page := 0; page < 100 && len(seen) < len(expected); page++ {
+		posts, err := postRepo.GetAllFeedAfter(ctx, "hot", afterHotScore, afterID, 10, nil)
+		require.NoError(t, err)
+		if len(posts) == 0 {
+			break
+		}
+
+		for _, post := range posts {
+			require.False(t, seenSet[post.ID], "post %d returned twice", post.ID)
+			seenSet[post.ID] = true
+			if ours[post.ID] {
+				seen = append(seen, post.ID)
+			}
+		}
+		last := posts[len(posts)-1]
+		afterHotScore, afterID = last.HotScore, last.ID
+
+		if page == 0 {
+			// A new post landing at the top of the feed mid-scroll must not shift later pages
+			insertedID = createPost("Inserted mid-scroll", 10, base.Add(24*time.Hour))
+		}
+	}
+
+	assert.Equal(t, expected, seen, "keyset pages should walk every post exactly once in feed order")
+	assert.False(t, seenSet[insertedID], "post inserted above the cursor should not appear on later pages")
+}
+
+func TestGetByIDs_PreservesOrderAndOmitsDeleted(t *testing.T) {
+	db, user, hub, cleanup := setupPlatformPostTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	ids := make([]int, 4)
+	for i := range ids {
+		post := &PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: fmt.Sprintf("Bulk post %d", i)}
+		require.NoError(t, postRepo.Create(ctx, post))
+		ids[i] = post.ID
+	}
+	require.NoError(t, postRepo.SoftDelete(ctx, ids[1]))
+
+	voter := &User{Username: fmt.Sprintf("bulkvoter_%d", time.Now().UnixNano()), PasswordHash: "test_hash"}
+	require.NoError(t, NewUserRepository(db.Pool).Create(ctx, voter))
+	upvote := true
+	require.NoError(t, postRepo.Vote(ctx, ids[0], voter.ID, &upvote))
+
+	requested := []int{ids[3], ids[1], ids[0], 2147483647, ids[2]}
+
+	posts, err := postRepo.GetByIDs(ctx, requested, nil)
+	require.NoError(t, err)
+	got := make([]int, 0, len(posts))
+	for _, post := range posts {
+		got = append(got, post.ID)
+		assert.Nil(t, post.UserVote)
+	}
+	assert.Equal(t, []int{ids[3], ids[0], ids[2]}, got, "deleted and missing ids should be omitted, order preserved")
+
+	posts, err = postRepo.GetByIDs(ctx, requested, &voter.ID)
+	require.NoError(t, err)
+	require.Len(t, posts, 3)
+	assert.Equal(t, ids[0], posts[1].ID)
+	require.NotNil(t, posts[1].UserVote)
+	assert.Equal(t, 1, *posts[1].UserVote)
+	assert.Nil(t, posts[0].UserVote)
+
+	empty, err := postRepo.GetByIDs(ctx, nil, nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
+
+func TestGetRisingFeed_PrefersRecentVotes(t *testing.T) {
+	db, user, hub, cleanup := setupPlatformPostTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+	userRepo := NewUserRepository(db.Pool)
+
+	stale := &PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "Voted days ago"}
+	require.NoError(t, postRepo.Create(ctx, stale))
+	fresh := &PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "Voted just now"}
+	require.NoError(t, postRepo.Create(ctx, fresh))
+
+	upvote := true
+	for i := 0; i < 3; i++ {
+		voter := &User{Username: fmt.Sprintf("risingvoter_%d_%d", i, time.Now().UnixNano()), PasswordHash: "test_hash"}
+		require.NoError(t, userRepo.Create(ctx, voter))
+		require.NoError(t, postRepo.Vote(ctx, stale.ID, voter.ID, &upvote))
+		require.NoError(t, postRepo.Vote(ctx, fresh.ID, voter.ID, &upvote))
+	}
+
+	// Same total score, but every vote on the stale post landed two days ago.
+	// The fresh post is also older, so the plain score-per-hour ratio alone would favour the stale one.
+	_, err := db.Pool.Exec(ctx, `UPDATE post_votes SET created_at = NOW() - INTERVAL '48 hours' WHERE post_id = $1`, stale.ID)
+	require.NoError(t, err)
+	require.NoError(t, postRepo.UpdateCreatedAt(ctx, fresh.ID, time.Now().Add(-5*time.Hour)))
+
+	var staleScore, freshScore int
+	require.NoError(t, db.Pool.QueryRow(ctx, `SELECT score FROM platform_posts WHERE id = $1`, stale.ID).Scan(&staleScore))
+	require.NoError(t, db.Pool.QueryRow(ctx, `SELECT score FROM platform_posts WHERE id = $1`, fresh.ID).Scan(&freshScore))
+	require.Equal(t, staleScore, freshScore)
+
+	posts, err := postRepo.GetRisingFeed(ctx, 6, 1000, 0, nil)
+	require.NoError(t, err)
+
+	stalePos, freshPos := -1, -1
+	for i, post := range posts {
+		switch post.ID {
+		case stale.ID:
+			stalePos = i
+		case fresh.ID:
+			freshPos = i
+		}
+	}
+	require.NotEqual(t, -1, freshPos, "post with recent votes should be in the rising feed")
+	if stalePos != -1 {
+		assert.Less(t, freshPos, stalePos, "recent votes should outrank older votes on an equal score")
+	}
+}
+
+func TestRestore_RoundTripsSoftDelete(t *testing.T) {
+	db, user, hub, cleanup := setupPlatformPostTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	post := &PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "Deleted by accident"}
+	require.NoError(t, postRepo.Create(ctx, post))
+	require.NoError(t, postRepo.SoftDelete(ctx, post.ID))
+
+	hidden, _ := postRepo.GetByID(ctx, post.ID)
+	assert.Nil(t, hidden, "GetByID should hide a deleted post")
+
+	deleted, err := postRepo.GetByIDIncludingDeleted(ctx, post.ID)
+	require.NoError(t, err)
+	require.NotNil(t, deleted)
+	assert.True(t, deleted.IsDeleted)
+
+	require.NoError(t, postRepo.Restore(ctx, post.ID))
+
+	restored, err := postRepo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+	require.NotNil(t, restored)
+	assert.False(t, restored.IsDeleted)
+	assert.Equal(t, "Deleted by accident", restored.Title)
+
+	missing, err := postRepo.GetByIDIncludingDeleted(ctx, 2147483647)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestUpdate_RecordsEditHistory(t *testing.T) {
+	db, user, hub, cleanup := setupPlatformPostTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	originalBody := "first body"
+	post := &PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: "First title", Body: &originalBody}
+	require.NoError(t, postRepo.Create(ctx, post))
+
+	secondBody := "second body"
+	post.Title = "Second title"
+	post.Body = &secondBody
+	require.NoError(t, postRepo.Update(ctx, post))
+
+	post.Title = "Third title"
+	require.NoError(t, postRepo.Update(ctx, post))
+
+	edits, err := postRepo.GetEditHistory(ctx, post.ID)
+	require.NoError(t, err)
+	require.Len(t, edits, 2)
+
+	assert.Equal(t, "Second title", edits[0].Title, "most recent edit should come first")
+	require.NotNil(t, edits[0].Body)
+	assert.Equal(t, "second body", *edits[0].Body)
+
+	assert.Equal(t, "First title", edits[1].Title)
+	require.NotNil(t, edits[1].Body)
+	assert.Equal(t, "first body", *edits[1].Body)
+	assert.False(t, edits[0].EditedAt.Before(edits[1].EditedAt))
+
+	current, err := postRepo.GetByID(ctx, post.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Third title", current.Title)
+	assert.True(t, current.IsEdited)
+}
+
+// feedVisibilityFixture is a public post, a removed post and a post in a
+// private hub, with one user who is a member of that hub and one who isn't.
+type feedVisibilityFixture struct {
+	author      *User
+	member      *User
+	outsider    *User
+	publicPost  int
+	removedPost int
+	privatePost int
+}
+
+// setupFeedVisibilityTest creates the fixture posts scored and dated to sort
+// ahead of anything else in the shared test database.
+func setupFeedVisibilityTest(t *testing.T) (*database.DB, *feedVisibilityFixture, func()) {
+	db, owner, hub, cleanup := setupPlatformPostTest(t)
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+	userRepo := NewUserRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	private := &Hub{Name: fmt.Sprintf("privatefeedhub_%d", suffix), Type: "private", CreatedBy: &owner.ID}
+	require.NoError(t, NewHubRepository(db.Pool).Create(ctx, private))
+	adult := &Hub{Name: fmt.Sprintf("nsfwxposthub_%d", suffix), Type: "public", CreatedBy: &owner.ID, NSFW: true}
+	require.NoError(t, NewHubRepository(db.Pool).Create(ctx, adult))
+
+	fx := &feedVisibilityFixture{
+		author:   owner,
+		member:   &User{Username: fmt.Sprintf("feedmember_%d", suffix), PasswordHash: "test_hash"},
+		outsider: &User{Username: fmt.Sprintf("feedoutsider_%d", suffix), PasswordHash: "test_hash"},
+	}
+	require.NoError(t, userRepo.Create(ctx, fx.member))
+	require.NoError(t, userRepo.Create(ctx, fx.outsider))
+	require.NoError(t, NewHubMemberRepository(db.Pool).AddMember(ctx, private.ID, fx.member.ID, owner.ID))
+
+	createPost := func(title string, hubID int) int {
+		post := &PlatformPost{AuthorID: owner.ID, HubID: &hubID, Title: title}
+		require.NoError(t, postRepo.Create(ctx, post))
+		_, err := db.Pool.Exec(ctx, `
+			UPDATE platform_posts
+			SET score = 1000000, hot_score = 1000000000, created_at = NOW() + INTERVAL '20 years'
+			WHERE id = $1
+		`, post.ID)
+		require.NoError(t, err)
+		return post.ID
+	}
+	fx.publicPost = createPost("Public feed post", hub.ID)
+	fx.removedPost = createPost("Removed feed post", hub.ID)
+	fx.privatePost = createPost("Private feed post", private.ID)
+	require.NoError(t, postRepo.MarkAsRemoved(ctx, fx.removedPost, owner.ID))
+
+	return db, fx, cleanup
+}
+
+// assertFeedVisibility checks that posts, as fetched for viewer, include the
+// public post, leave out the removed one, and include the private post only
+// for the hub member.
+func (fx *feedVisibilityFixture) assertFeedVisibility(t *testing.T, posts []*PlatformPost, viewer *User) {
+	t.Helper()
+	ids := make(map[int]bool, len(posts))
+	for _, post := range posts {
+		ids[post.ID] = true
+	}
+	assert.True(t, ids[fx.publicPost], "public post should be listed")
+	assert.False(t, ids[fx.removedPost], "removed post should not be listed")
+	assert.Equal(t, viewer == fx.member, ids[fx.privatePost], "private hub post should be listed only for members")
+}
+
+func TestGetAllFeed_HidesPrivateHubsAndRemovedPosts(t *testing.T) {
+	db, fx, cleanup := setupFeedVisibilityTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	for _, viewer := range []*User{fx.outsider, fx.member} {
+		posts, err := postRepo.GetAllFeed(ctx, "new", 100, 0, nil, nil, &viewer.ID)
+		require.NoError(t, err)
+		fx.assertFeedVisibility(t, posts, viewer)
+
+		posts, err = postRepo.GetAllFeedAfter(ctx, "hot", 0, 0, 100, &viewer.ID)
+		require.NoError(t, err)
+		fx.assertFeedVisibility(t, posts, viewer)
+	}
+
+	posts, err := postRepo.GetAllFeed(ctx, "new", 100, 0, nil, nil, nil)
+	require.NoError(t, err)
+	fx.assertFeedVisibility(t, posts, nil)
+}
+
+func TestGetPopularFeed_HidesPrivateHubsAndRemovedPosts(t *testing.T) {
+	db, fx, cleanup := setupFeedVisibilityTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	for _, viewer := range []*User{fx.outsider, fx.member} {
+		posts, err := postRepo.GetPopularFeed(ctx, []int{}, "new", 100, 0, nil, nil, &viewer.ID)
+		require.NoError(t, err)
+		fx.assertFeedVisibility(t, posts, viewer)
+	}
+
+	posts, err := postRepo.GetPopularFeed(ctx, []int{}, "new", 100, 0, nil, nil, nil)
+	require.NoError(t, err)
+	fx.assertFeedVisibility(t, posts, nil)
+}
+
+func TestGetByAuthor_HidesPrivateHubActivityFromNonMembers(t *testing.T) {
+	db, fx, cleanup := setupFeedVisibilityTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+	commentRepo := NewPostCommentRepository(db.Pool)
+
+	publicComment := &PostComment{PostID: fx.publicPost, UserID: fx.author.ID, Body: "Public comment"}
+	require.NoError(t, commentRepo.Create(ctx, publicComment))
+	privateComment := &PostComment{PostID: fx.privatePost, UserID: fx.author.ID, Body: "Private comment"}
+	require.NoError(t, commentRepo.Create(ctx, privateComment))
+
+	for _, viewer := range []*User{nil, fx.outsider, fx.member} {
+		var viewerID *int
+		if viewer != nil {
+			viewerID = &viewer.ID
+		}
+
+		posts, err := postRepo.GetByAuthor(ctx, fx.author.ID, viewerID, 100, 0)
+		require.NoError(t, err)
+		postIDs := make(map[int]bool, len(posts))
+		for _, post := range posts {
+			postIDs[post.ID] = true
+		}
+		assert.True(t, postIDs[fx.publicPost], "public post should be listed")
+		assert.Equal(t, viewer == fx.member, postIDs[fx.privatePost], "private hub post should be listed only for members")
+
+		comments, err := commentRepo.GetByUserID(ctx, fx.author.ID, viewerID, 100, 0)
+		require.NoError(t, err)
+		commentIDs := make(map[int]bool, len(comments))
+		for _, comment := range comments {
+			commentIDs[comment.ID] = true
+		}
+		assert.True(t, commentIDs[publicComment.ID], "public comment should be listed")
+		assert.Equal(t, viewer == fx.member, commentIDs[privateComment.ID], "private hub comment should be listed only for members")
+	}
+}
+
+func TestGetRisingFeed_HidesPrivateHubsAndRemovedPosts(t *testing.T) {
+	db, fx, cleanup := setupFeedVisibilityTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	for _, viewer := range []*User{fx.outsider, fx.member} {
+		posts, err := postRepo.GetRisingFeed(ctx, 6, 1000, 0, &viewer.ID)
+		require.NoError(t, err)
+		fx.assertFeedVisibility(t, posts, viewer)
+	}
+}
+
+func TestGetFeed_HidesPrivateHubsAndRemovedPosts(t *testing.T) {
+	db, fx, cleanup := setupFeedVisibilityTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	for _, viewer := range []*User{fx.outsider, fx.member} {
+		posts, err := postRepo.GetFeed(ctx, "new", 100, 0, &viewer.ID)
+		require.NoError(t, err)
+		fx.assertFeedVisibility(t, posts, viewer)
+	}
+}
+
+func TestGetFeedFiltered_HidesPrivateHubsAndRemovedPosts(t *testing.T) {
+	db, fx, cleanup := setupFeedVisibilityTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+
+	for _, viewer := range []*User{fx.outsider, fx.member} {
+		posts, err := postRepo.GetFeedExcludingTags(ctx, "new", []string{"spoiler"}, 100, 0, &viewer.ID)
+		require.NoError(t, err)
+		fx.assertFeedVisibility(t, posts, viewer)
+	}
+}
+
+func TestGetCrossposts_RespectsViewerVisibility(t *testing.T) {
+	db, owner, hub, cleanup := setupPlatformPostTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	postRepo := NewPlatformPostRepository(db.Pool)
+	userRepo := NewUserRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	private := &Hub{Name: fmt.Sprintf("privatexposthub_%d", suffix), Type: "private", CreatedBy: &owner.ID}
+	require.NoError(t, NewHubRepository(db.Pool).Create(ctx, private))
+	adult := &Hub{Name: fmt.Sprintf("nsfwxposthub_%d", suffix), Type: "public", CreatedBy: &owner.ID, NSFW: true}
+	require.NoError(t, NewHubRepository(db.Pool).Create(ctx, adult))
+	member := &User{Username: fmt.Sprintf("xpostmember_%d", suffix), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, member))
+	outsider := &User{Username: fmt.Sprintf("xpostoutsider_%d", suffix), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, outsider))
+	require.NoError(t, NewHubMemberRepository(db.Pool).AddMember(ctx, private.ID, member.ID, owner.ID))
+
+	origin := &PlatformPost{AuthorID: owner.ID, HubID: &hub.ID, Title: "Origin"}
+	require.NoError(t, postRepo.Create(ctx, origin))
+	originType, originID := "platform", fmt.Sprint(origin.ID)
+	crosspost := func(hubID int) int {
+		post := &PlatformPost{AuthorID: owner.ID, HubID: &hubID, Title: "Crosspost",
+			CrosspostOriginType: &originType, CrosspostOriginPostID: &originID}
+		require.NoError(t, postRepo.Create(ctx, post))
+		return post.ID
+	}
+	public := crosspost(hub.ID)
+	nsfw := crosspost(adult.ID)
+	hidden := crosspost(private.ID)
+
+	listed := func(viewerID *int, includeNSFW bool) []int {
+		posts, err := postRepo.GetCrossposts(ctx, origin.ID, viewerID, includeNSFW, 10, 0)
+		require.NoError(t, err)
+		ids := []int{}
+		for _, post := range posts {
+			ids = append(ids, post.ID)
+		}
+		return ids
+	}
+
+	assert.ElementsMatch(t, []int{public}, listed(nil, false))
+	assert.ElementsMatch(t, []int{public, nsfw}, listed(&outsider.ID, true))
+	assert.ElementsMatch(t, []int{public, hidden}, listed(&member.ID, false))
+	assert.ElementsMatch(t, []int{public, nsfw, hidden}, listed(&member.ID, true))
+}
@@ -341,18 +341,22 @@ func (r *PostCommentRepository) GetReplies(ctx context.Context, parentCommentID
 	return comments, rows.Err()
 }
 
-// GetByUserID retrieves comments by a specific user
-func (r *PostCommentRepository) GetByUserID(ctx context.Context, userID int, limit, offset int) ([]*PostComment, error) {
+// GetByUserID retrieves comments by a specific user, leaving out comments on
+// posts in private hubs viewerID can't access (nil for anonymous viewers)
+func (r *PostCommentRepository) GetByUserID(ctx context.Context, userID int, viewerID *int, limit, offset int) ([]*PostComment, error) {
 	query := `
-		SELECT id, post_id, user_id, parent_comment_id, body, score, upvotes, downvotes,
-		       is_deleted, is_edited, edited_at, depth, created_at
-		FROM post_comments
-		WHERE user_id = $1 AND is_deleted = FALSE
-		ORDER BY created_at DESC
+		SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.body, c.score, c.upvotes, c.downvotes,
+		       c.is_deleted, c.is_edited, c.edited_at, c.depth, c.created_at
+		FROM post_comments c
+		JOIN platform_posts p ON p.id = c.post_id
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE c.user_id = $1 AND c.is_deleted = FALSE
+		AND ` + hubAccessibleToViewerClause(4) + `
+		ORDER BY c.created_at DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID, limit, offset)
+	rows, err := r.pool.Query(ctx, query, userID, limit, offset, viewerID)
 	if err != nil {
 		return nil, err
 	}
@@ -385,6 +389,47 @@ func (r *PostCommentRepository) GetByUserID(ctx context.Context, userID int, lim
 	return comments, rows.Err()
 }
 
+// Search finds live comments matching a full-text query, best match first.
+// Only comments on posts viewerID may see (nil for anonymous) are returned.
+func (r *PostCommentRepository) Search(ctx context.Context, query string, viewerID *int, includeNSFW bool, limit, offset int) ([]*PostComment, error) {
+	sql := `
+		SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.body, c.depth, c.score,
+		       c.upvotes, c.downvotes, c.created_at,
+		       ts_rank(c.search_vector, plainto_tsquery('english', $1)) as rank
+		FROM post_comments c
+		JOIN platform_posts p ON p.id = c.post_id
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE c.search_vector @@ plainto_tsquery('english', $1)
+		AND c.is_deleted = FALSE AND c.is_removed = FALSE
+		AND ` + postVisibleToViewerClause(4, 5) + `
+		ORDER BY rank DESC, c.created_at DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, sql, query, limit, offset, viewerID, includeNSFW)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []*PostComment
+	for rows.Next() {
+		comment := &PostComment{}
+		var rank float64
+		err := rows.Scan(
+			&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID,
+			&comment.Body, &comment.Depth, &comment.Score, &comment.Upvotes, &comment.Downvotes,
+			&comment.CreatedAt, &rank,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
 // Update updates a comment's content
 func (r *PostCommentRepository) Update(ctx context.Context, comment *PostComment) error {
 	query := `
@@ -458,33 +503,45 @@ func (r *PostCommentRepository) SoftDelete(ctx context.Context, commentID int) e
 	return tx.Commit(ctx)
 }
 
-// Vote records a user's vote and updates aggregate counts, preventing duplicates.
+// VoteWithResult records a user's vote and updates aggregate counts, preventing duplicates.
 // isUpvote: true (upvote), false (downvote), nil (remove vote)
-func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID int, isUpvote *bool) error {
+// It returns the resulting counts and vote state as read in the same transaction.
+func (r *PostCommentRepository) VoteWithResult(ctx context.Context, commentID int, userID int, isUpvote *bool) (*VoteResult, error) {
 	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
+	// Lock the comment first so concurrent votes on it serialize; otherwise two
+	// requests from the same user could both see no existing vote.
+	var lockedID int
+	err = tx.QueryRow(ctx, "SELECT id FROM post_comments WHERE id = $1 FOR UPDATE", commentID).Scan(&lockedID)
+	if err == pgx.ErrNoRows {
+		return nil, ErrVoteTargetNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
 	var existingIsUpvote bool
 	err = tx.QueryRow(ctx, "SELECT is_upvote FROM comment_votes WHERE comment_id = $1 AND user_id = $2", commentID, userID).Scan(&existingIsUpvote)
 	if err != nil && err != pgx.ErrNoRows {
-		return err
+		return nil, err
 	}
 
 	switch {
 	case err == pgx.ErrNoRows:
 		// New vote
 		if isUpvote == nil {
-			return tx.Commit(ctx)
+			break
 		}
 
 		if _, err := tx.Exec(ctx, `
 			INSERT INTO comment_votes (comment_id, user_id, is_upvote)
 			VALUES ($1, $2, $3)
 		`, commentID, userID, *isUpvote); err != nil {
-			return err
+			return nil, err
 		}
 
 		if *isUpvote {
@@ -493,7 +550,7 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 				SET upvotes = upvotes + 1, score = score + 1
 				WHERE id = $1
 			`, commentID); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			if _, err := tx.Exec(ctx, `
@@ -501,13 +558,13 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 				SET downvotes = downvotes + 1, score = score - 1
 				WHERE id = $1
 			`, commentID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	case isUpvote == nil:
 		// Remove existing vote
 		if _, err := tx.Exec(ctx, `DELETE FROM comment_votes WHERE comment_id = $1 AND user_id = $2`, commentID, userID); err != nil {
-			return err
+			return nil, err
 		}
 		if existingIsUpvote {
 			if _, err := tx.Exec(ctx, `
@@ -516,7 +573,7 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 				    score = score - 1
 				WHERE id = $1
 			`, commentID); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			if _, err := tx.Exec(ctx, `
@@ -525,12 +582,12 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 				    score = score + 1
 				WHERE id = $1
 			`, commentID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	case existingIsUpvote == *isUpvote:
 		// Duplicate same-direction vote: no-op
-		return tx.Commit(ctx)
+		break
 	default:
 		// Toggle vote direction
 		if _, err := tx.Exec(ctx, `
@@ -538,7 +595,7 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 			SET is_upvote = $3, created_at = CURRENT_TIMESTAMP
 			WHERE comment_id = $1 AND user_id = $2
 		`, commentID, userID, *isUpvote); err != nil {
-			return err
+			return nil, err
 		}
 
 		if *isUpvote {
@@ -549,7 +606,7 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 				    score = score + 2
 				WHERE id = $1
 			`, commentID); err != nil {
-				return err
+				return nil, err
 			}
 		} else {
 			if _, err := tx.Exec(ctx, `
@@ -559,12 +616,27 @@ func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID
 				    score = score - 2
 				WHERE id = $1
 			`, commentID); err != nil {
-				return err
+				return nil, err
 			}
 		}
 	}
 
-	return tx.Commit(ctx)
+	result := &VoteResult{UserVote: voteValue(isUpvote)}
+	if err := tx.QueryRow(ctx, "SELECT score, upvotes, downvotes FROM post_comments WHERE id = $1", commentID).
+		Scan(&result.Score, &result.Upvotes, &result.Downvotes); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Vote records a vote without returning the resulting state.
+func (r *PostCommentRepository) Vote(ctx context.Context, commentID int, userID int, isUpvote *bool) error {
+	_, err := r.VoteWithResult(ctx, commentID, userID, isUpvote)
+	return err
 }
 
 // GetReplyCount returns the number of replies to a comment
@@ -0,0 +1,293 @@
+package models
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CommentCursor identifies a position within the siblings of one parent: the
+// top-level comments of a post when ParentID is nil, or the replies to ParentID.
+// A cursor with ID 0 points at the start of the sibling list.
+type CommentCursor struct {
+	ParentID  *int      `json:"p,omitempty"`
+	Sort      string    `json:"s"`
+	Score     int       `json:"sc,omitempty"`
+	CreatedAt time.Time `json:"t,omitempty"`
+	ID        int       `json:"i,omitempty"`
+}
+
+// ErrInvalidCommentCursor is returned when a cursor string cannot be decoded.
+var ErrInvalidCommentCursor = errors.New("invalid comment cursor")
+
+// NormalizeCommentSort maps a requested sort onto one supported by keyset pagination.
+func NormalizeCommentSort(sortBy string) string {
+	switch sortBy {
+	case "new", "old":
+		return sortBy
+	default:
+		return "top"
+	}
+}
+
+// CursorAfter returns a cursor positioned just after comment within its siblings.
+func CursorAfter(comment *PostComment, sortBy string) *CommentCursor {
+	return &CommentCursor{
+		ParentID:  comment.ParentCommentID,
+		Sort:      NormalizeCommentSort(sortBy),
+		Score:     comment.Score,
+		CreatedAt: comment.CreatedAt,
+		ID:        comment.ID,
+	}
+}
+
+// Encode serializes the cursor into an opaque URL-safe string.
+func (c *CommentCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCommentCursor parses a cursor produced by Encode.
+func DecodeCommentCursor(s string) (*CommentCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCommentCursor
+	}
+	var cursor CommentCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCommentCursor
+	}
+	if cursor.Sort != NormalizeCommentSort(cursor.Sort) || cursor.ID < 0 {
+		return nil, ErrInvalidCommentCursor
+	}
+	return &cursor, nil
+}
+
+// commentKeysetOrder returns the ORDER BY clause for a sort, always ending in the
+// id tiebreaker so keyset pages never skip or repeat comments.
+func commentKeysetOrder(alias, sortBy string) string {
+	switch sortBy {
+	case "new":
+		return fmt.Sprintf("%[1]s.created_at DESC, %[1]s.id DESC", alias)
+	case "old":
+		return fmt.Sprintf("%[1]s.created_at ASC, %[1]s.id ASC", alias)
+	default:
+		return fmt.Sprintf("%[1]s.score DESC, %[1]s.created_at DESC, %[1]s.id DESC", alias)
+	}
+}
+
+// commentThreadSelect returns the shared column list and joins, including the
+// viewer's vote when userID is set as the given positional argument.
+func commentThreadSelect(userID *int, userArg int) string {
+	if userID == nil {
+		return `
+			SELECT pc.id, pc.post_id, pc.user_id, u.username,
+			       pc.parent_comment_id, pc.body, pc.score, pc.upvotes, pc.downvotes,
+			       pc.is_deleted, pc.is_edited, pc.edited_at, pc.depth, pc.created_at,
+			       pc.inbox_replies_disabled,
+			       0 AS user_vote
+			FROM post_comments pc
+			JOIN users u ON u.id = pc.user_id`
+	}
+	return fmt.Sprintf(`
+			SELECT pc.id, pc.post_id, pc.user_id, u.username,
+			       pc.parent_comment_id, pc.body, pc.score, pc.upvotes, pc.downvotes,
+			       pc.is_deleted, pc.is_edited, pc.edited_at, pc.depth, pc.created_at,
+			       pc.inbox_replies_disabled,
+			       CASE
+			           WHEN cv.comment_id IS NULL THEN 0
+			           WHEN cv.is_upvote THEN 1
+			           ELSE -1
+			       END AS user_vote
+			FROM post_comments pc
+			JOIN users u ON u.id = pc.user_id
+			LEFT JOIN comment_votes cv ON cv.comment_id = pc.id AND cv.user_id = $%d`, userArg)
+}
+
+func scanThreadComments(rows pgx.Rows, userID *int) ([]*PostComment, error) {
+	defer rows.Close()
+
+	var comments []*PostComment
+	for rows.Next() {
+		comment := &PostComment{}
+		var userVote int
+		err := rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&comment.UserID,
+			&comment.Username,
+			&comment.ParentCommentID,
+			&comment.Body,
+			&comment.Score,
+			&comment.Upvotes,
+			&comment.Downvotes,
+			&comment.IsDeleted,
+			&comment.IsEdited,
+			&comment.EditedAt,
+			&comment.Depth,
+			&comment.CreatedAt,
+			&comment.InboxRepliesDisabled,
+			&userVote,
+		)
+		if err != nil {
+			return nil, err
+		}
+		if userID != nil {
+			v := userVote
+			comment.UserVote = &v
+		}
+		comment.SanitizeDeletedPlaceholder()
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+// GetSiblingPage returns up to limit comments sharing the cursor's parent on a
+// post, starting just after the cursor position. Pages are keyset-paginated on
+// (score, created_at, id) or (created_at, id) depending on the cursor's sort.
+func (r *PostCommentRepository) GetSiblingPage(ctx context.Context, postID int, cursor *CommentCursor, limit int, userID *int) ([]*PostComment, error) {
+	sortBy := NormalizeCommentSort(cursor.Sort)
+	args := []interface{}{postID, DeletedCommentPlaceholder, limit}
+	conditions := []string{"pc.post_id = $1", "(pc.is_deleted = FALSE OR pc.body = $2)"}
+
+	if cursor.ParentID == nil {
+		conditions = append(conditions, "pc.parent_comment_id IS NULL")
+	} else {
+		args = append(args, *cursor.ParentID)
+		conditions = append(conditions, fmt.Sprintf("pc.parent_comment_id = $%d", len(args)))
+	}
+
+	if cursor.ID > 0 {
+		switch sortBy {
+		case "new":
+			args = append(args, cursor.CreatedAt, cursor.ID)
+			conditions = append(conditions, fmt.Sprintf("(pc.created_at, pc.id) < ($%d, $%d)", len(args)-1, len(args)))
+		case "old":
+			args = append(args, cursor.CreatedAt, cursor.ID)
+			conditions = append(conditions, fmt.Sprintf("(pc.created_at, pc.id) > ($%d, $%d)", len(args)-1, len(args)))
+		default:
+			args = append(args, cursor.Score, cursor.CreatedAt, cursor.ID)
+			conditions = append(conditions, fmt.Sprintf("(pc.score, pc.created_at, pc.id) < ($%d, $%d, $%d)", len(args)-2, len(args)-1, len(args)))
+		}
+	}
+
+	if userID != nil {
+		args = append(args, *userID)
+	}
+	query := commentThreadSelect(userID, len(args)) + `
+			WHERE ` + strings.Join(conditions, " AND ") + `
+			ORDER BY ` + commentKeysetOrder("pc", sortBy) + `
+			LIMIT $3
+		`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return scanThreadComments(rows, userID)
+}
+
+// GetFirstRepliesBatch returns the first perParent replies of each parent in
+// parentIDs, in sort order, with a single query rather than one per parent.
+func (r *PostCommentRepository) GetFirstRepliesBatch(ctx context.Context, parentIDs []int, sortBy string, perParent int, userID *int) (map[int][]*PostComment, error) {
+	result := make(map[int][]*PostComment, len(parentIDs))
+	if len(parentIDs) == 0 || perParent <= 0 {
+		return result, nil
+	}
+
+	sortBy = NormalizeCommentSort(sortBy)
+	args := []interface{}{parentIDs, DeletedCommentPlaceholder, perParent}
+	if userID != nil {
+		args = append(args, *userID)
+	}
+	query := `
+		SELECT replies.*
+		FROM unnest($1::int[]) AS parent(id)
+		CROSS JOIN LATERAL (` + commentThreadSelect(userID, len(args)) + `
+			WHERE pc.parent_comment_id = parent.id AND (pc.is_deleted = FALSE OR pc.body = $2)
+			ORDER BY ` + commentKeysetOrder("pc", sortBy) + `
+			LIMIT $3
+		) replies
+		ORDER BY replies.parent_comment_id, ` + commentKeysetOrder("replies", sortBy) + `
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	replies, err := scanThreadComments(rows, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		result[*reply.ParentCommentID] = append(result[*reply.ParentCommentID], reply)
+	}
+	return result, nil
+}
+
+// CountVisibleReplies returns the number of visible direct replies for each parent.
+// Parents without replies are omitted from the map.
+func (r *PostCommentRepository) CountVisibleReplies(ctx context.Context, parentIDs []int) (map[int]int, error) {
+	counts := make(map[int]int, len(parentIDs))
+	if len(parentIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT parent_comment_id, COUNT(*)
+		FROM post_comments
+		WHERE parent_comment_id = ANY($1) AND (is_deleted = FALSE OR body = $2)
+		GROUP BY parent_comment_id
+	`, parentIDs, DeletedCommentPlaceholder)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var parentID, count int
+		if err := rows.Scan(&parentID, &count); err != nil {
+			return nil, err
+		}
+		counts[parentID] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetVisibleByIDs returns the comments among ids that userID may see, in the
+// order requested, with the viewer's vote. Removed comments and comments on
+// posts the viewer can't see are left out; deleted comments come back as
+// placeholders like everywhere else in a thread.
+func (r *PostCommentRepository) GetVisibleByIDs(ctx context.Context, ids []int, userID *int, includeNSFW bool) ([]*PostComment, error) {
+	if len(ids) == 0 {
+		return []*PostComment{}, nil
+	}
+
+	query := commentThreadSelect(userID, 3) + `
+		JOIN platform_posts p ON p.id = pc.post_id
+		LEFT JOIN hubs h ON h.id = p.hub_id
+		WHERE pc.id = ANY($1) AND (pc.is_deleted = FALSE OR pc.body = $2) AND pc.is_removed = FALSE
+		  AND ` + postVisibleToViewerClause(3, 4) + `
+		ORDER BY array_position($1::int[], pc.id)
+	`
+
+	rows, err := r.pool.Query(ctx, query, ids, DeletedCommentPlaceholder, userID, includeNSFW)
+	if err != nil {
+		return nil, err
+	}
+	comments, err := scanThreadComments(rows, userID)
+	if err != nil {
+		return nil, err
+	}
+	if comments == nil {
+		comments = []*PostComment{}
+	}
+	return comments, nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PushSubscription is a browser's web push endpoint and the keys used to encrypt payloads for it
+type PushSubscription struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"user_id"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"p256dh"`
+	Auth      string    `json:"auth"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PushSubscriptionRepository stores web push subscriptions
+type PushSubscriptionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPushSubscriptionRepository creates a new repo
+func NewPushSubscriptionRepository(pool *pgxpool.Pool) *PushSubscriptionRepository {
+	return &PushSubscriptionRepository{pool: pool}
+}
+
+// Subscribe saves a subscription for a user. Endpoints are unique per browser, so
+// re-subscribing an endpoint refreshes its keys and moves it to the current user.
+func (r *PushSubscriptionRepository) Subscribe(ctx context.Context, sub *PushSubscription) error {
+	return r.pool.QueryRow(ctx, `
+		INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (endpoint) DO UPDATE
+		SET user_id = EXCLUDED.user_id, p256dh = EXCLUDED.p256dh, auth = EXCLUDED.auth
+		RETURNING id, created_at
+	`, sub.UserID, sub.Endpoint, sub.P256dh, sub.Auth).Scan(&sub.ID, &sub.CreatedAt)
+}
+
+// GetByUserID returns every subscription registered by a user
+func (r *PushSubscriptionRepository) GetByUserID(ctx context.Context, userID int) ([]*PushSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, user_id, endpoint, p256dh, auth, created_at
+		FROM push_subscriptions
+		WHERE user_id = $1
+		ORDER BY id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []*PushSubscription{}
+	for rows.Next() {
+		sub := &PushSubscription{}
+		if err := rows.Scan(&sub.ID, &sub.UserID, &sub.Endpoint, &sub.P256dh, &sub.Auth, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// Unsubscribe removes a user's subscription for an endpoint, reporting whether one existed
+func (r *PushSubscriptionRepository) Unsubscribe(ctx context.Context, userID int, endpoint string) (bool, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`, userID, endpoint)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// DeleteByEndpoint removes a subscription the push service reported as expired
+func (r *PushSubscriptionRepository) DeleteByEndpoint(ctx context.Context, endpoint string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM push_subscriptions WHERE endpoint = $1`, endpoint)
+	return err
+}
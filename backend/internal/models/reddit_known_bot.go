@@ -0,0 +1,83 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// RedditKnownBot is an admin-added Reddit bot account
+type RedditKnownBot struct {
+	Username  string    `json:"username"`
+	AddedBy   *int      `json:"added_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type RedditKnownBotRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRedditKnownBotRepository(db *pgxpool.Pool) *RedditKnownBotRepository {
+	return &RedditKnownBotRepository{db: db}
+}
+
+// List returns all admin-added bots ordered by username
+func (r *RedditKnownBotRepository) List(ctx context.Context) ([]*RedditKnownBot, error) {
+	rows, err := r.db.Query(ctx, `SELECT username, added_by, created_at FROM reddit_known_bots ORDER BY username`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list known bots: %w", err)
+	}
+	defer rows.Close()
+
+	bots := []*RedditKnownBot{}
+	for rows.Next() {
+		var bot RedditKnownBot
+		if err := rows.Scan(&bot.Username, &bot.AddedBy, &bot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan known bot: %w", err)
+		}
+		bots = append(bots, &bot)
+	}
+	return bots, rows.Err()
+}
+
+// ListUsernames returns the usernames of all admin-added bots
+func (r *RedditKnownBotRepository) ListUsernames(ctx context.Context) ([]string, error) {
+	bots, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make([]string, 0, len(bots))
+	for _, bot := range bots {
+		usernames = append(usernames, bot.Username)
+	}
+	return usernames, nil
+}
+
+// Add records a bot username; adding an existing bot is a no-op
+func (r *RedditKnownBotRepository) Add(ctx context.Context, username string, addedBy int) (*RedditKnownBot, error) {
+	query := `
+		INSERT INTO reddit_known_bots (username, added_by)
+		VALUES ($1, $2)
+		ON CONFLICT (username) DO UPDATE SET username = EXCLUDED.username
+		RETURNING username, added_by, created_at
+	`
+
+	var bot RedditKnownBot
+	err := r.db.QueryRow(ctx, query, strings.ToLower(username), addedBy).Scan(&bot.Username, &bot.AddedBy, &bot.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add known bot: %w", err)
+	}
+	return &bot, nil
+}
+
+// Remove deletes a bot username, reporting whether it existed
+func (r *RedditKnownBotRepository) Remove(ctx context.Context, username string) (bool, error) {
+	result, err := r.db.Exec(ctx, `DELETE FROM reddit_known_bots WHERE username = $1`, strings.ToLower(username))
+	if err != nil {
+		return false, fmt.Errorf("failed to remove known bot: %w", err)
+	}
+	return result.RowsAffected() > 0, nil
+}
@@ -26,6 +26,9 @@ type RedditPostComment struct {
 	CreatedAt             time.Time  `json:"created_at"`
 	UpdatedAt             *time.Time `json:"updated_at,omitempty"`
 	DeletedAt             *time.Time `json:"deleted_at,omitempty"`
+
+	// Replies is only populated on trees returned by BuildTree
+	Replies []*RedditPostComment `json:"replies,omitempty"`
 }
 
 // RedditPostCommentRepository manages local comments on Reddit posts
@@ -49,6 +52,37 @@ func (c *RedditPostComment) SanitizeDeletedPlaceholder() {
 	}
 }
 
+// BuildTree nests a flat list of comments into reply trees keyed by ParentCommentID.
+// Siblings keep the order of the input slice, so callers should rank the flat list first.
+// Comments whose parent is missing from the list (e.g. hard-deleted) are attached at the root.
+// The input comments are not modified; the returned nodes are copies.
+func BuildTree(comments []*RedditPostComment) []*RedditPostComment {
+	nodes := make(map[int]*RedditPostComment, len(comments))
+	ordered := make([]*RedditPostComment, 0, len(comments))
+	for _, comment := range comments {
+		if comment == nil {
+			continue
+		}
+		node := *comment
+		node.Replies = nil
+		nodes[node.ID] = &node
+		ordered = append(ordered, &node)
+	}
+
+	roots := make([]*RedditPostComment, 0, len(ordered))
+	for _, node := range ordered {
+		if node.ParentCommentID != nil && *node.ParentCommentID != node.ID {
+			if parent, ok := nodes[*node.ParentCommentID]; ok {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots
+}
+
 // Create creates a new comment on a Reddit post and auto-upvotes it
 func (r *RedditPostCommentRepository) Create(ctx context.Context, comment *RedditPostComment) error {
 	// Start transaction
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTree_NestsRepliesAndAttachesOrphans(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+
+	comments := []*RedditPostComment{
+		{ID: 1, Content: "root"},
+		{ID: 2, ParentCommentID: intPtr(1), Content: "child"},
+		{ID: 3, ParentCommentID: intPtr(2), Content: "grandchild"},
+		{ID: 4, ParentCommentID: intPtr(1), Content: "second child"},
+		{ID: 5, ParentCommentID: intPtr(99), Content: "orphan"},
+		{ID: 6, Content: "second root"},
+	}
+
+	roots := BuildTree(comments)
+
+	require.Len(t, roots, 3)
+	require.Equal(t, 1, roots[0].ID)
+	require.Equal(t, 5, roots[1].ID, "comment with a deleted parent should be attached at the root")
+	require.Equal(t, 6, roots[2].ID)
+
+	require.Len(t, roots[0].Replies, 2)
+	require.Equal(t, 2, roots[0].Replies[0].ID)
+	require.Equal(t, 4, roots[0].Replies[1].ID)
+
+	require.Len(t, roots[0].Replies[0].Replies, 1)
+	require.Equal(t, 3, roots[0].Replies[0].Replies[0].ID)
+	require.Empty(t, roots[0].Replies[0].Replies[0].Replies)
+
+	for _, comment := range comments {
+		require.Nil(t, comment.Replies, "input comments should not be mutated")
+	}
+}
@@ -93,9 +93,9 @@ func (r *RemovedContentRepository) IsContentRemoved(ctx context.Context, content
 func (r *RemovedContentRepository) GetByContent(ctx context.Context, contentType string, contentID int) (*RemovedContent, error) {
 	query := `
 		SELECT rc.id, rc.content_type, rc.content_id, rc.hub_id, rc.removed_by,
-			   rc.removal_reason_id, rc.custom_reason, rc.mod_note, rc.removed_at,
+			   rc.removal_reason_id, COALESCE(rc.custom_reason, ''), COALESCE(rc.mod_note, ''), rc.removed_at,
 			   u.username as removed_by_name,
-			   rr.title as reason_title, rr.message as reason_message
+			   COALESCE(rr.title, '') as reason_title, COALESCE(rr.message, '') as reason_message
 		FROM removed_content rc
 		JOIN users u ON rc.removed_by = u.id
 		LEFT JOIN removal_reasons rr ON rc.removal_reason_id = rr.id
@@ -118,28 +118,30 @@ func (r *RemovedContentRepository) GetByContent(ctx context.Context, contentType
 	return &removed, nil
 }
 
-// GetByHub lists all removed content for a hub
-func (r *RemovedContentRepository) GetByHub(ctx context.Context, hubID int, limit, offset int) ([]*RemovedContent, error) {
+// GetByHub lists removed content for a hub, most recent first. contentType
+// ("post" or "comment") narrows the listing; empty returns both.
+func (r *RemovedContentRepository) GetByHub(ctx context.Context, hubID int, contentType string, limit, offset int) ([]*RemovedContent, error) {
 	query := `
 		SELECT rc.id, rc.content_type, rc.content_id, rc.hub_id, rc.removed_by,
-			   rc.removal_reason_id, rc.custom_reason, rc.mod_note, rc.removed_at,
+			   rc.removal_reason_id, COALESCE(rc.custom_reason, ''), COALESCE(rc.mod_note, ''), rc.removed_at,
 			   u.username as removed_by_name,
-			   rr.title as reason_title, rr.message as reason_message
+			   COALESCE(rr.title, '') as reason_title, COALESCE(rr.message, '') as reason_message
 		FROM removed_content rc
 		JOIN users u ON rc.removed_by = u.id
 		LEFT JOIN removal_reasons rr ON rc.removal_reason_id = rr.id
 		WHERE rc.hub_id = $1
-		ORDER BY rc.removed_at DESC
-		LIMIT $2 OFFSET $3
+		AND ($2 = '' OR rc.content_type = $2)
+		ORDER BY rc.removed_at DESC, rc.id DESC
+		LIMIT $3 OFFSET $4
 	`
 
-	rows, err := r.db.Query(ctx, query, hubID, limit, offset)
+	rows, err := r.db.Query(ctx, query, hubID, contentType, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get removed content: %w", err)
 	}
 	defer rows.Close()
 
-	var removals []*RemovedContent
+	removals := []*RemovedContent{}
 	for rows.Next() {
 		var removed RemovedContent
 		err := rows.Scan(
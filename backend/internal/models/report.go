@@ -2,8 +2,10 @@ package models
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -45,6 +47,31 @@ func (r *ReportRepository) UpdateStatus(ctx context.Context, id int, status stri
 	return err
 }
 
+// Resolve updates report status and returns the updated report along with its
+// previous status, so callers can tell whether this call changed the outcome.
+// Returns (nil, "", nil) if the report doesn't exist.
+func (r *ReportRepository) Resolve(ctx context.Context, id int, status string) (*Report, string, error) {
+	query := `
+		UPDATE reports r
+		SET status = $2
+		FROM (SELECT id, status FROM reports WHERE id = $1 FOR UPDATE) prev
+		WHERE r.id = prev.id
+		RETURNING r.id, r.reporter_id, r.target_type, r.target_id, r.reason, r.status, r.created_at, prev.status
+	`
+	rep := &Report{}
+	var previous string
+	err := r.pool.QueryRow(ctx, query, id, status).Scan(
+		&rep.ID, &rep.ReporterID, &rep.TargetType, &rep.TargetID, &rep.Reason, &rep.Status, &rep.CreatedAt, &previous,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return rep, previous, nil
+}
+
 // ListByStatus lists reports by status
 func (r *ReportRepository) ListByStatus(ctx context.Context, status string, limit, offset int) ([]*Report, error) {
 	query := `
@@ -70,3 +97,70 @@ func (r *ReportRepository) ListByStatus(ctx context.Context, status string, limi
 	}
 	return reports, rows.Err()
 }
+
+// ModQueueItem is a reported post or comment awaiting moderator review
+type ModQueueItem struct {
+	ContentType    string    `json:"content_type"` // post, comment
+	ContentID      int       `json:"content_id"`
+	PostID         int       `json:"post_id"` // The post itself, or the post a comment belongs to
+	Title          *string   `json:"title,omitempty"`
+	Body           *string   `json:"body,omitempty"`
+	AuthorID       int       `json:"author_id"`
+	AuthorUsername string    `json:"author_username"`
+	ReportCount    int       `json:"report_count"`
+	LastReportedAt time.Time `json:"last_reported_at"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// GetModQueue lists a hub's posts and comments that have open reports and
+// have not been removed, most reported first. contentType is "posts",
+// "comments" or "all".
+func (r *ReportRepository) GetModQueue(ctx context.Context, hubID int, contentType string, limit, offset int) ([]*ModQueueItem, error) {
+	query := `
+		WITH open_reports AS (
+			SELECT target_type, target_id, COUNT(*) AS report_count, MAX(created_at) AS last_reported_at
+			FROM reports
+			WHERE status = 'open' AND target_type IN ('post', 'comment')
+			GROUP BY target_type, target_id
+		)
+		SELECT 'post', p.id, p.id, p.title, p.body, p.author_id, u.username,
+			   o.report_count, o.last_reported_at, p.created_at
+		FROM open_reports o
+		JOIN platform_posts p ON o.target_type = 'post' AND p.id = o.target_id
+		JOIN users u ON p.author_id = u.id
+		WHERE p.hub_id = $1 AND p.is_removed = FALSE AND p.is_deleted = FALSE
+		AND $2 IN ('all', 'posts')
+
+		UNION ALL
+
+		SELECT 'comment', c.id, c.post_id, NULL, c.body, c.user_id, u.username,
+			   o.report_count, o.last_reported_at, c.created_at
+		FROM open_reports o
+		JOIN post_comments c ON o.target_type = 'comment' AND c.id = o.target_id
+		JOIN platform_posts p ON c.post_id = p.id
+		JOIN users u ON c.user_id = u.id
+		WHERE p.hub_id = $1 AND c.is_removed = FALSE AND c.is_deleted = FALSE
+		AND $2 IN ('all', 'comments')
+
+		ORDER BY report_count DESC, last_reported_at DESC
+		LIMIT $3 OFFSET $4
+	`
+	rows, err := r.pool.Query(ctx, query, hubID, contentType, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*ModQueueItem{}
+	for rows.Next() {
+		item := &ModQueueItem{}
+		if err := rows.Scan(
+			&item.ContentType, &item.ContentID, &item.PostID, &item.Title, &item.Body, &item.AuthorID, &item.AuthorUsername,
+			&item.ReportCount, &item.LastReportedAt, &item.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
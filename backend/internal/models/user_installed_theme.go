@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -24,8 +25,14 @@ type UserInstalledTheme struct {
 	UserRating         *int       `json:"user_rating,omitempty"` // 1-5 stars
 	Review             *string    `json:"review,omitempty"`
 	ReviewedAt         *time.Time `json:"reviewed_at,omitempty"`
+
+	// CurrentVersion is the theme's latest version; only set by GetUserInstalledThemes
+	CurrentVersion string `json:"current_version,omitempty"`
 }
 
+// ErrThemeNotInstalled is returned when rating a theme the user hasn't installed.
+var ErrThemeNotInstalled = errors.New("theme is not installed")
+
 // UserInstalledThemeRepository handles CRUD operations for user_installed_themes.
 type UserInstalledThemeRepository struct {
 	pool *pgxpool.Pool
@@ -36,13 +43,14 @@ func NewUserInstalledThemeRepository(pool *pgxpool.Pool) *UserInstalledThemeRepo
 	return &UserInstalledThemeRepository{pool: pool}
 }
 
-// Install records a theme installation (purchase/download).
+// Install records a theme installation (purchase/download) and captures the
+// theme's current version. Reinstalling picks up the latest version.
 func (r *UserInstalledThemeRepository) Install(ctx context.Context, userID, themeID, pricePaid int) (*UserInstalledTheme, error) {
 	query := `
-		INSERT INTO user_installed_themes (user_id, theme_id, price_paid)
-		VALUES ($1, $2, $3)
+		INSERT INTO user_installed_themes (user_id, theme_id, price_paid, installed_version)
+		VALUES ($1, $2, $3, (SELECT version FROM user_themes WHERE id = $2))
 		ON CONFLICT (user_id, theme_id) DO UPDATE
-		SET installed_at = NOW()
+		SET installed_at = NOW(), installed_version = EXCLUDED.installed_version, update_available = FALSE
 		RETURNING id, user_id, theme_id, purchased_at, price_paid, is_active, installed_at,
 		          last_used_at, installed_version, update_available, auto_update_enabled,
 		          user_rating, review, reviewed_at
@@ -107,15 +115,20 @@ func (r *UserInstalledThemeRepository) GetInstalledTheme(ctx context.Context, us
 	return installed, nil
 }
 
-// GetUserInstalledThemes fetches all themes installed by a user.
+// GetUserInstalledThemes fetches all themes installed by a user along with each
+// theme's current version. UpdateAvailable is true when the version captured at
+// install time is older than the current one; installs that predate version
+// tracking have no installed version and never report an update.
 func (r *UserInstalledThemeRepository) GetUserInstalledThemes(ctx context.Context, userID int) ([]*UserInstalledTheme, error) {
 	query := `
-		SELECT id, user_id, theme_id, purchased_at, price_paid, is_active, installed_at,
-		       last_used_at, installed_version, update_available, auto_update_enabled,
-		       user_rating, review, reviewed_at
-		FROM user_installed_themes
-		WHERE user_id = $1
-		ORDER BY installed_at DESC
+		SELECT uit.id, uit.user_id, uit.theme_id, uit.purchased_at, uit.price_paid, uit.is_active, uit.installed_at,
+		       uit.last_used_at, uit.installed_version,
+		       COALESCE(uit.installed_version <> ut.version, FALSE), uit.auto_update_enabled,
+		       uit.user_rating, uit.review, uit.reviewed_at, ut.version
+		FROM user_installed_themes uit
+		JOIN user_themes ut ON ut.id = uit.theme_id
+		WHERE uit.user_id = $1
+		ORDER BY uit.installed_at DESC
 	`
 
 	rows, err := r.pool.Query(ctx, query, userID)
@@ -142,6 +155,7 @@ func (r *UserInstalledThemeRepository) GetUserInstalledThemes(ctx context.Contex
 			&item.UserRating,
 			&item.Review,
 			&item.ReviewedAt,
+			&item.CurrentVersion,
 		)
 		if err != nil {
 			return nil, err
@@ -180,16 +194,80 @@ func (r *UserInstalledThemeRepository) SetActive(ctx context.Context, userID, th
 	return tx.Commit(ctx)
 }
 
-// RateTheme allows a user to rate an installed theme.
-func (r *UserInstalledThemeRepository) RateTheme(ctx context.Context, userID, themeID, rating int, review *string) error {
+// RateTheme records a user's rating and review of a theme, replacing any earlier
+// rating so each user counts once toward the average. Only installed themes can
+// be rated; otherwise ErrThemeNotInstalled is returned and nothing is written,
+// so rating never counts as an install. created reports whether the user had
+// not rated the theme before.
+func (r *UserInstalledThemeRepository) RateTheme(ctx context.Context, userID, themeID, rating int, review *string) (created bool, err error) {
 	query := `
+		WITH previous AS (
+			SELECT user_rating FROM user_installed_themes WHERE user_id = $1 AND theme_id = $2
+		)
 		UPDATE user_installed_themes
-		SET user_rating = $1, review = $2, reviewed_at = NOW()
-		WHERE user_id = $3 AND theme_id = $4
+		SET user_rating = $3, review = $4, reviewed_at = NOW()
+		WHERE user_id = $1 AND theme_id = $2
+		RETURNING (SELECT user_rating FROM previous) IS NULL
 	`
 
-	_, err := r.pool.Exec(ctx, query, rating, review, userID, themeID)
-	return err
+	err = r.pool.QueryRow(ctx, query, userID, themeID, rating, review).Scan(&created)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, ErrThemeNotInstalled
+	}
+	return created, err
+}
+
+// ThemeReview is one user's rating of a theme, with their review text if any.
+type ThemeReview struct {
+	UserID     int       `json:"user_id"`
+	Username   string    `json:"username"`
+	Rating     int       `json:"rating"`
+	Review     *string   `json:"review,omitempty"`
+	ReviewedAt time.Time `json:"reviewed_at"`
+}
+
+// GetRatingSummary computes a theme's average rating and number of ratings
+// from the current installs, so ratings from uninstalled copies drop out.
+func (r *UserInstalledThemeRepository) GetRatingSummary(ctx context.Context, themeID int) (float64, int, error) {
+	query := `
+		SELECT COALESCE(AVG(user_rating), 0)::float8, COUNT(user_rating)
+		FROM user_installed_themes
+		WHERE theme_id = $1 AND user_rating IS NOT NULL
+	`
+
+	var avg float64
+	var count int
+	err := r.pool.QueryRow(ctx, query, themeID).Scan(&avg, &count)
+	return avg, count, err
+}
+
+// GetReviews lists a theme's ratings, newest first.
+func (r *UserInstalledThemeRepository) GetReviews(ctx context.Context, themeID, limit, offset int) ([]*ThemeReview, error) {
+	query := `
+		SELECT uit.user_id, u.username, uit.user_rating, uit.review, COALESCE(uit.reviewed_at, uit.installed_at)
+		FROM user_installed_themes uit
+		JOIN users u ON u.id = uit.user_id
+		WHERE uit.theme_id = $1 AND uit.user_rating IS NOT NULL
+		ORDER BY uit.reviewed_at DESC NULLS LAST, uit.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, themeID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reviews := []*ThemeReview{}
+	for rows.Next() {
+		review := &ThemeReview{}
+		if err := rows.Scan(&review.UserID, &review.Username, &review.Rating, &review.Review, &review.ReviewedAt); err != nil {
+			return nil, err
+		}
+		reviews = append(reviews, review)
+	}
+
+	return reviews, rows.Err()
 }
 
 // Uninstall removes a theme installation.
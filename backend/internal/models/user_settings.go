@@ -10,6 +10,23 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// Notification cadences. Hourly and daily collect batchable notifications into
+// a single digest per window.
+const (
+	NotificationCadenceImmediate = "immediate"
+	NotificationCadenceHourly    = "hourly"
+	NotificationCadenceDaily     = "daily"
+)
+
+// ValidNotificationCadence reports whether cadence is a known notification cadence
+func ValidNotificationCadence(cadence string) bool {
+	switch cadence {
+	case NotificationCadenceImmediate, NotificationCadenceHourly, NotificationCadenceDaily:
+		return true
+	}
+	return false
+}
+
 // UserSettings represents per-user preferences for the platform.
 type UserSettings struct {
 	UserID               int       `json:"user_id"`
@@ -26,6 +43,15 @@ type UserSettings struct {
 	NotifyCommentMilestone bool `json:"notify_comment_milestone"`
 	NotifyCommentVelocity  bool `json:"notify_comment_velocity"`
 	DailyDigest            bool `json:"daily_digest"`
+	NotifyReportUpdates    bool `json:"notify_report_updates"`
+	NotifyContentRemoved   bool `json:"notify_content_removed"`
+
+	// How often batchable notifications are delivered (immediate, hourly, daily)
+	NotificationCadence string `json:"notification_cadence"`
+
+	// Quiet hours (UTC, 0-23) during which digest emails are held back
+	QuietHoursStart *int `json:"quiet_hours_start"`
+	QuietHoursEnd   *int `json:"quiet_hours_end"`
 
 	// Media gallery preferences
 	MediaGalleryFilter string `json:"media_gallery_filter"` // 'all', 'mine', 'theirs'
@@ -54,7 +80,9 @@ func (r *UserSettingsRepository) GetByUserID(ctx context.Context, userID int) (*
 		       auto_append_invitation, theme,
 		       notify_comment_replies, notify_post_milestone, notify_post_velocity,
 		       notify_comment_milestone, notify_comment_velocity, daily_digest,
-		       media_gallery_filter, active_theme_id, advanced_mode_enabled, updated_at
+		       media_gallery_filter, active_theme_id, advanced_mode_enabled,
+		       quiet_hours_start, quiet_hours_end, notify_report_updates, notify_content_removed,
+		       notification_cadence, updated_at
 		FROM user_settings
 		WHERE user_id = $1
 	`
@@ -76,6 +104,11 @@ func (r *UserSettingsRepository) GetByUserID(ctx context.Context, userID int) (*
 		&settings.MediaGalleryFilter,
 		&settings.ActiveThemeID,
 		&settings.AdvancedModeEnabled,
+		&settings.QuietHoursStart,
+		&settings.QuietHoursEnd,
+		&settings.NotifyReportUpdates,
+		&settings.NotifyContentRemoved,
+		&settings.NotificationCadence,
 		&settings.UpdatedAt,
 	)
 	if err != nil {
@@ -98,7 +131,9 @@ func (r *UserSettingsRepository) CreateDefault(ctx context.Context, userID int)
 		          auto_append_invitation, theme,
 		          notify_comment_replies, notify_post_milestone, notify_post_velocity,
 		          notify_comment_milestone, notify_comment_velocity, daily_digest,
-		          media_gallery_filter, active_theme_id, advanced_mode_enabled, updated_at
+		          media_gallery_filter, active_theme_id, advanced_mode_enabled,
+		          quiet_hours_start, quiet_hours_end, notify_report_updates, notify_content_removed,
+		          notification_cadence, updated_at
 	`
 
 	settings := &UserSettings{}
@@ -118,6 +153,11 @@ func (r *UserSettingsRepository) CreateDefault(ctx context.Context, userID int)
 		&settings.MediaGalleryFilter,
 		&settings.ActiveThemeID,
 		&settings.AdvancedModeEnabled,
+		&settings.QuietHoursStart,
+		&settings.QuietHoursEnd,
+		&settings.NotifyReportUpdates,
+		&settings.NotifyContentRemoved,
+		&settings.NotificationCadence,
 		&settings.UpdatedAt,
 	)
 
@@ -150,13 +190,20 @@ func (r *UserSettingsRepository) Update(ctx context.Context, settings *UserSetti
 		    media_gallery_filter = $13,
 		    active_theme_id = $14,
 		    advanced_mode_enabled = $15,
+		    quiet_hours_start = $16,
+		    quiet_hours_end = $17,
+		    notify_report_updates = $18,
+		    notify_content_removed = $19,
+		    notification_cadence = $20,
 		    updated_at = CURRENT_TIMESTAMP
 		WHERE user_id = $1
 		RETURNING user_id, notification_sound, show_read_receipts, show_typing_indicators,
 		          auto_append_invitation, theme,
 		          notify_comment_replies, notify_post_milestone, notify_post_velocity,
 		          notify_comment_milestone, notify_comment_velocity, daily_digest,
-		          media_gallery_filter, active_theme_id, advanced_mode_enabled, updated_at
+		          media_gallery_filter, active_theme_id, advanced_mode_enabled,
+		          quiet_hours_start, quiet_hours_end, notify_report_updates, notify_content_removed,
+		          notification_cadence, updated_at
 	`
 
 	updated := &UserSettings{}
@@ -176,6 +223,11 @@ func (r *UserSettingsRepository) Update(ctx context.Context, settings *UserSetti
 		settings.MediaGalleryFilter,
 		settings.ActiveThemeID,
 		settings.AdvancedModeEnabled,
+		settings.QuietHoursStart,
+		settings.QuietHoursEnd,
+		settings.NotifyReportUpdates,
+		settings.NotifyContentRemoved,
+		settings.NotificationCadence,
 	).Scan(
 		&updated.UserID,
 		&updated.NotificationSound,
@@ -192,6 +244,11 @@ func (r *UserSettingsRepository) Update(ctx context.Context, settings *UserSetti
 		&updated.MediaGalleryFilter,
 		&updated.ActiveThemeID,
 		&updated.AdvancedModeEnabled,
+		&updated.QuietHoursStart,
+		&updated.QuietHoursEnd,
+		&updated.NotifyReportUpdates,
+		&updated.NotifyContentRemoved,
+		&updated.NotificationCadence,
 		&updated.UpdatedAt,
 	)
 	if err != nil {
@@ -200,3 +257,96 @@ func (r *UserSettingsRepository) Update(ctx context.Context, settings *UserSetti
 
 	return updated, nil
 }
+
+// Notification categories users can mute individually
+const (
+	NotificationCategoryReply     = "reply"
+	NotificationCategoryMention   = "mention"
+	NotificationCategoryModAction = "mod_action"
+	NotificationCategoryVote      = "vote"
+)
+
+// NotificationCategories lists every category in notification_preferences
+var NotificationCategories = []string{
+	NotificationCategoryReply,
+	NotificationCategoryMention,
+	NotificationCategoryModAction,
+	NotificationCategoryVote,
+}
+
+// ValidNotificationCategory reports whether category is a known notification category
+func ValidNotificationCategory(category string) bool {
+	for _, c := range NotificationCategories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// notificationCategoryTypes lists the notification types in each category. Every
+// notification type the platform sends belongs to exactly one category.
+var notificationCategoryTypes = map[string][]string{
+	NotificationCategoryReply:     {"comment_reply", "post_comment"},
+	NotificationCategoryMention:   {"mention"},
+	NotificationCategoryModAction: {"report_action_taken", "report_no_action", "content_removed"},
+	NotificationCategoryVote:      {"post_milestone", "post_velocity", "comment_milestone", "comment_velocity", "notification_digest"},
+}
+
+// NotificationCategoryFor maps a notification type to the category that mutes it.
+// Types outside every category return "" and can't be muted this way.
+func NotificationCategoryFor(notificationType string) string {
+	for category, types := range notificationCategoryTypes {
+		for _, t := range types {
+			if t == notificationType {
+				return category
+			}
+		}
+	}
+	return ""
+}
+
+// NotificationPreferences maps a notification category to whether it is enabled
+type NotificationPreferences map[string]bool
+
+// Allows reports whether a notification of the given type should be delivered.
+// Categories the user never set are enabled.
+func (p NotificationPreferences) Allows(notificationType string) bool {
+	enabled, ok := p[NotificationCategoryFor(notificationType)]
+	return !ok || enabled
+}
+
+// GetNotificationPreferences returns a user's per-category preferences with every
+// category filled in; users without a settings row get the all-on default.
+func (r *UserSettingsRepository) GetNotificationPreferences(ctx context.Context, userID int) (NotificationPreferences, error) {
+	stored := NotificationPreferences{}
+	err := r.pool.QueryRow(ctx, `
+		SELECT notification_preferences FROM user_settings WHERE user_id = $1
+	`, userID).Scan(&stored)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, err
+	}
+
+	prefs := NotificationPreferences{}
+	for _, category := range NotificationCategories {
+		enabled, ok := stored[category]
+		prefs[category] = !ok || enabled
+	}
+	return prefs, nil
+}
+
+// UpdateNotificationPreferences merges changes into a user's stored preferences,
+// creating their settings row if needed, and returns the full result.
+func (r *UserSettingsRepository) UpdateNotificationPreferences(ctx context.Context, userID int, changes NotificationPreferences) (NotificationPreferences, error) {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO user_settings (user_id, notification_preferences)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE
+		SET notification_preferences = user_settings.notification_preferences || EXCLUDED.notification_preferences,
+		    updated_at = CURRENT_TIMESTAMP
+	`, userID, changes)
+	if err != nil {
+		return nil, err
+	}
+	return r.GetNotificationPreferences(ctx, userID)
+}
@@ -3,9 +3,14 @@ package models
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -30,6 +35,7 @@ type UserTheme struct {
 	RatingCount      int                    `json:"rating_count"`
 	AverageRating    float64                `json:"average_rating"`
 	Version          string                 `json:"version"`
+	PairedThemeID    *int                   `json:"paired_theme_id,omitempty"` // Light/dark counterpart
 	CreatedAt        time.Time              `json:"created_at"`
 	UpdatedAt        time.Time              `json:"updated_at"`
 }
@@ -94,13 +100,20 @@ func (r *UserThemeRepository) Create(ctx context.Context, theme *UserTheme) (*Us
 	return theme, err
 }
 
+// themeRatingColumns computes rating_count and average_rating from the current
+// installs, the same way as UserInstalledThemeRepository.GetRatingSummary, so
+// listings, sorting and single-theme reads agree. The stored counters aren't
+// updated when a rated install is removed.
+const themeRatingColumns = `(SELECT COUNT(uit.user_rating) FROM user_installed_themes uit WHERE uit.theme_id = user_themes.id) AS rating_count,
+		       (SELECT COALESCE(AVG(uit.user_rating), 0)::float8 FROM user_installed_themes uit WHERE uit.theme_id = user_themes.id) AS average_rating`
+
 // GetByID fetches a theme by its ID. Returns (nil, nil) if not found.
 func (r *UserThemeRepository) GetByID(ctx context.Context, id int) (*UserTheme, error) {
 	query := `
 		SELECT id, user_id, theme_name, theme_description, theme_type, scope_type, target_page,
 		       css_variables, custom_css, is_public, is_marketplace, price_coins,
-		       category, tags, thumbnail_url, install_count, rating_count, average_rating,
-		       version, created_at, updated_at
+		       category, tags, thumbnail_url, install_count, ` + themeRatingColumns + `,
+		       version, paired_theme_id, created_at, updated_at
 		FROM user_themes
 		WHERE id = $1
 	`
@@ -128,6 +141,7 @@ func (r *UserThemeRepository) GetByID(ctx context.Context, id int) (*UserTheme,
 		&theme.RatingCount,
 		&theme.AverageRating,
 		&theme.Version,
+		&theme.PairedThemeID,
 		&theme.CreatedAt,
 		&theme.UpdatedAt,
 	)
@@ -154,8 +168,8 @@ func (r *UserThemeRepository) GetByUserID(ctx context.Context, userID int, limit
 	query := `
 		SELECT id, user_id, theme_name, theme_description, theme_type, scope_type, target_page,
 		       css_variables, custom_css, is_public, is_marketplace, price_coins,
-		       category, tags, thumbnail_url, install_count, rating_count, average_rating,
-		       version, created_at, updated_at
+		       category, tags, thumbnail_url, install_count, ` + themeRatingColumns + `,
+		       version, paired_theme_id, created_at, updated_at
 		FROM user_themes
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -193,6 +207,7 @@ func (r *UserThemeRepository) GetByUserID(ctx context.Context, userID int, limit
 			&theme.RatingCount,
 			&theme.AverageRating,
 			&theme.Version,
+			&theme.PairedThemeID,
 			&theme.CreatedAt,
 			&theme.UpdatedAt,
 		)
@@ -213,13 +228,20 @@ func (r *UserThemeRepository) GetByUserID(ctx context.Context, userID int, limit
 	return themes, rows.Err()
 }
 
-// GetPublicThemes fetches all public themes (for browsing).
-func (r *UserThemeRepository) GetPublicThemes(ctx context.Context, limit, offset int, category *string) ([]*UserTheme, error) {
+// Sort orders for GetPublicThemes
+const (
+	ThemeSortPopular  = "popular"
+	ThemeSortTopRated = "top_rated"
+)
+
+// GetPublicThemes fetches all public themes (for browsing), most installed
+// first or, with ThemeSortTopRated, highest rated first.
+func (r *UserThemeRepository) GetPublicThemes(ctx context.Context, limit, offset int, category *string, sortBy string) ([]*UserTheme, error) {
 	query := `
 		SELECT id, user_id, theme_name, theme_description, theme_type, scope_type, target_page,
 		       css_variables, custom_css, is_public, is_marketplace, price_coins,
-		       category, tags, thumbnail_url, install_count, rating_count, average_rating,
-		       version, created_at, updated_at
+		       category, tags, thumbnail_url, install_count, ` + themeRatingColumns + `,
+		       version, paired_theme_id, created_at, updated_at
 		FROM user_themes
 		WHERE is_public = true
 	`
@@ -233,7 +255,11 @@ func (r *UserThemeRepository) GetPublicThemes(ctx context.Context, limit, offset
 		args = append(args, *category)
 	}
 
-	query += ` ORDER BY install_count DESC, average_rating DESC, created_at DESC`
+	if sortBy == ThemeSortTopRated {
+		query += ` ORDER BY average_rating DESC, rating_count DESC, created_at DESC`
+	} else {
+		query += ` ORDER BY install_count DESC, average_rating DESC, created_at DESC`
+	}
 
 	argCount++
 	query += ` LIMIT $` + string(rune('0'+argCount))
@@ -274,6 +300,7 @@ func (r *UserThemeRepository) GetPublicThemes(ctx context.Context, limit, offset
 			&theme.RatingCount,
 			&theme.AverageRating,
 			&theme.Version,
+			&theme.PairedThemeID,
 			&theme.CreatedAt,
 			&theme.UpdatedAt,
 		)
@@ -294,8 +321,38 @@ func (r *UserThemeRepository) GetPublicThemes(ctx context.Context, limit, offset
 	return themes, rows.Err()
 }
 
+// themeExecer is satisfied by both the pool and a transaction
+type themeExecer interface {
+	Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error)
+}
+
 // Update modifies an existing theme.
 func (r *UserThemeRepository) Update(ctx context.Context, theme *UserTheme) error {
+	return updateTheme(ctx, r.pool, theme)
+}
+
+// UpdateAndBumpVersion saves theme and bumps its version (see BumpVersion) in
+// one transaction, so a style change is never saved without its version entry.
+// It returns the new version.
+func (r *UserThemeRepository) UpdateAndBumpVersion(ctx context.Context, theme *UserTheme, changelog *string) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := updateTheme(ctx, tx, theme); err != nil {
+		return "", err
+	}
+	next, err := bumpThemeVersion(ctx, tx, theme.ID, changelog)
+	if err != nil {
+		return "", err
+	}
+
+	return next, tx.Commit(ctx)
+}
+
+func updateTheme(ctx context.Context, db themeExecer, theme *UserTheme) error {
 	query := `
 		UPDATE user_themes
 		SET theme_name = $1, theme_description = $2, theme_type = $3, scope_type = $4,
@@ -315,7 +372,7 @@ func (r *UserThemeRepository) Update(ctx context.Context, theme *UserTheme) erro
 		}
 	}
 
-	_, err = r.pool.Exec(ctx, query,
+	_, err = db.Exec(ctx, query,
 		theme.ThemeName,
 		theme.ThemeDescription,
 		theme.ThemeType,
@@ -337,6 +394,104 @@ func (r *UserThemeRepository) Update(ctx context.Context, theme *UserTheme) erro
 	return err
 }
 
+// ThemeVersion is one entry in a theme's version history.
+type ThemeVersion struct {
+	ID        int       `json:"id"`
+	ThemeID   int       `json:"theme_id"`
+	Version   string    `json:"version"`
+	Changelog *string   `json:"changelog,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// NextPatchVersion increments the patch component of a MAJOR.MINOR.PATCH
+// version. Anything else is treated as 1.0.0.
+func NextPatchVersion(version string) string {
+	parts := strings.Split(version, ".")
+	if len(parts) == 3 {
+		major, errMajor := strconv.Atoi(parts[0])
+		minor, errMinor := strconv.Atoi(parts[1])
+		patch, errPatch := strconv.Atoi(parts[2])
+		if errMajor == nil && errMinor == nil && errPatch == nil && major >= 0 && minor >= 0 && patch >= 0 {
+			return fmt.Sprintf("%d.%d.%d", major, minor, patch+1)
+		}
+	}
+	return "1.0.1"
+}
+
+// BumpVersion increments a theme's patch version, records it in the version
+// history with an optional changelog, and flags existing installs as having
+// an update available. It returns the new version.
+func (r *UserThemeRepository) BumpVersion(ctx context.Context, themeID int, changelog *string) (string, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback(ctx)
+
+	next, err := bumpThemeVersion(ctx, tx, themeID, changelog)
+	if err != nil {
+		return "", err
+	}
+
+	return next, tx.Commit(ctx)
+}
+
+func bumpThemeVersion(ctx context.Context, tx pgx.Tx, themeID int, changelog *string) (string, error) {
+	var current string
+	if err := tx.QueryRow(ctx, `SELECT version FROM user_themes WHERE id = $1 FOR UPDATE`, themeID).Scan(&current); err != nil {
+		return "", err
+	}
+	next := NextPatchVersion(current)
+
+	if _, err := tx.Exec(ctx, `UPDATE user_themes SET version = $1, updated_at = NOW() WHERE id = $2`, next, themeID); err != nil {
+		return "", err
+	}
+
+	_, err := tx.Exec(ctx, `
+		INSERT INTO theme_versions (theme_id, version, changelog)
+		VALUES ($1, $2, $3)
+	`, themeID, next, changelog)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_installed_themes
+		SET update_available = TRUE
+		WHERE theme_id = $1 AND installed_version IS NOT NULL AND installed_version <> $2
+	`, themeID, next)
+	if err != nil {
+		return "", err
+	}
+
+	return next, nil
+}
+
+// GetVersions lists a theme's version history, newest first.
+func (r *UserThemeRepository) GetVersions(ctx context.Context, themeID int) ([]*ThemeVersion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, theme_id, version, changelog, created_at
+		FROM theme_versions
+		WHERE theme_id = $1
+		ORDER BY created_at DESC, id DESC
+	`, themeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := []*ThemeVersion{}
+	for rows.Next() {
+		version := &ThemeVersion{}
+		if err := rows.Scan(&version.ID, &version.ThemeID, &version.Version, &version.Changelog, &version.CreatedAt); err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
 // Delete removes a theme (only if user is the owner).
 func (r *UserThemeRepository) Delete(ctx context.Context, themeID, userID int) error {
 	query := `DELETE FROM user_themes WHERE id = $1 AND user_id = $2`
@@ -344,14 +499,33 @@ func (r *UserThemeRepository) Delete(ctx context.Context, themeID, userID int) e
 	return err
 }
 
+// GetDefaultPredefinedTheme fetches the theme used when a user has nothing
+// active: the first predefined theme that was seeded. Returns nil if none exist.
+func (r *UserThemeRepository) GetDefaultPredefinedTheme(ctx context.Context) (*UserTheme, error) {
+	var id int
+	err := r.pool.QueryRow(ctx, `
+		SELECT id FROM user_themes
+		WHERE theme_type = 'predefined'
+		ORDER BY id ASC
+		LIMIT 1
+	`).Scan(&id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return r.GetByID(ctx, id)
+}
+
 // GetPredefinedThemes fetches all predefined (system) themes.
 // Predefined themes are created by user_id = 0 or have theme_type = 'predefined'.
 func (r *UserThemeRepository) GetPredefinedThemes(ctx context.Context) ([]*UserTheme, error) {
 	query := `
 		SELECT id, user_id, theme_name, theme_description, theme_type, scope_type, target_page,
 		       css_variables, custom_css, is_public, is_marketplace, price_coins,
-		       category, tags, thumbnail_url, install_count, rating_count, average_rating,
-		       version, created_at, updated_at
+		       category, tags, thumbnail_url, install_count, ` + themeRatingColumns + `,
+		       version, paired_theme_id, created_at, updated_at
 		FROM user_themes
 		WHERE theme_type = 'predefined'
 		ORDER BY theme_name ASC
@@ -388,6 +562,7 @@ func (r *UserThemeRepository) GetPredefinedThemes(ctx context.Context) ([]*UserT
 			&theme.RatingCount,
 			&theme.AverageRating,
 			&theme.Version,
+			&theme.PairedThemeID,
 			&theme.CreatedAt,
 			&theme.UpdatedAt,
 		)
@@ -407,3 +582,81 @@ func (r *UserThemeRepository) GetPredefinedThemes(ctx context.Context) ([]*UserT
 
 	return themes, rows.Err()
 }
+
+// PairThemes links two themes as each other's light/dark counterpart,
+// replacing any earlier pairing either of them had.
+func (r *UserThemeRepository) PairThemes(ctx context.Context, themeID, pairedThemeID int) error {
+	if themeID == pairedThemeID {
+		return errors.New("a theme cannot be paired with itself")
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Unlink the previous partners so no theme points at a pair it's no longer in
+	_, err = tx.Exec(ctx, `
+		UPDATE user_themes SET paired_theme_id = NULL
+		WHERE paired_theme_id IN ($1, $2) OR id IN ($1, $2)
+	`, themeID, pairedThemeID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_themes
+		SET paired_theme_id = CASE WHEN id = $1 THEN $2 ELSE $1 END
+		WHERE id IN ($1, $2)
+	`, themeID, pairedThemeID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPairedTheme fetches the light/dark counterpart of a theme.
+// Returns (nil, nil) if the theme doesn't exist or isn't paired.
+func (r *UserThemeRepository) GetPairedTheme(ctx context.Context, themeID int) (*UserTheme, error) {
+	theme, err := r.GetByID(ctx, themeID)
+	if err != nil || theme == nil || theme.PairedThemeID == nil {
+		return nil, err
+	}
+	return r.GetByID(ctx, *theme.PairedThemeID)
+}
+
+// Color schemes reported by ColorScheme
+const (
+	ColorSchemeLight = "light"
+	ColorSchemeDark  = "dark"
+)
+
+// ColorScheme reports whether a theme is light or dark from the luminance of its
+// background color variable. Themes without a readable hex background are light.
+func (t *UserTheme) ColorScheme() string {
+	for _, name := range []string{"color-background", "--color-background"} {
+		value, ok := t.CSSVariables[name].(string)
+		if !ok {
+			continue
+		}
+		hex := strings.TrimPrefix(strings.TrimSpace(value), "#")
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) != 6 {
+			continue
+		}
+		rgb, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			continue
+		}
+		red, green, blue := float64(rgb>>16&0xff), float64(rgb>>8&0xff), float64(rgb&0xff)
+		if (0.299*red+0.587*green+0.114*blue)/255 < 0.5 {
+			return ColorSchemeDark
+		}
+		return ColorSchemeLight
+	}
+	return ColorSchemeLight
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextPatchVersion(t *testing.T) {
+	cases := map[string]string{
+		"1.0.0":  "1.0.1",
+		"1.0.9":  "1.0.10",
+		"2.3.41": "2.3.42",
+		"":       "1.0.1",
+		"1.0":    "1.0.1",
+		"v1.0.0": "1.0.1",
+		"1.0.-1": "1.0.1",
+	}
+	for version, expected := range cases {
+		assert.Equal(t, expected, NextPatchVersion(version), version)
+	}
+}
+
+func TestUserThemeColorScheme(t *testing.T) {
+	cases := map[string]struct {
+		variables map[string]interface{}
+		expected  string
+	}{
+		"white background":    {map[string]interface{}{"color-background": "#ffffff"}, ColorSchemeLight},
+		"near-black":          {map[string]interface{}{"color-background": "#111827"}, ColorSchemeDark},
+		"short hex":           {map[string]interface{}{"--color-background": "#000"}, ColorSchemeDark},
+		"no background":       {map[string]interface{}{"color-primary": "#000000"}, ColorSchemeLight},
+		"unreadable color":    {map[string]interface{}{"color-background": "black"}, ColorSchemeLight},
+		"no variables at all": {nil, ColorSchemeLight},
+	}
+	for name, tc := range cases {
+		theme := &UserTheme{CSSVariables: tc.variables}
+		assert.Equal(t, tc.expected, theme.ColorScheme(), name)
+	}
+}
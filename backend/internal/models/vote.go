@@ -0,0 +1,28 @@
+package models
+
+import "errors"
+
+// ErrVoteTargetNotFound is returned when voting on a post or comment that doesn't exist.
+var ErrVoteTargetNotFound = errors.New("vote target not found")
+
+// VoteResult is the authoritative state of a post or comment right after a vote,
+// read inside the voting transaction so clients can reconcile optimistic updates.
+type VoteResult struct {
+	Score     int `json:"score"`
+	Upvotes   int `json:"upvotes"`
+	Downvotes int `json:"downvotes"`
+	// UserVote is the voter's resulting vote: -1 (down), 0 (none), or 1 (up)
+	UserVote int `json:"user_vote"`
+}
+
+// voteValue converts a requested vote into its -1/0/1 form.
+func voteValue(isUpvote *bool) int {
+	switch {
+	case isUpvote == nil:
+		return 0
+	case *isUpvote:
+		return 1
+	default:
+		return -1
+	}
+}
@@ -14,6 +14,7 @@ import (
 type Cache interface {
 	Get(ctx context.Context, key string) (string, bool, error)
 	Set(ctx context.Context, key string, value string, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
 }
 
 // NoopCache is a no-op cache implementation
@@ -23,8 +24,9 @@ func (NoopCache) Get(ctx context.Context, key string) (string, bool, error) { re
 func (NoopCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
 	return nil
 }
+func (NoopCache) Delete(ctx context.Context, key string) error { return nil }
 
-// RedisCache is a lightweight Redis client using RESP for simple GET/SETEX
+// RedisCache is a lightweight Redis client using RESP for simple GET/SETEX/DEL
 type RedisCache struct {
 	addr     string
 	password string
@@ -97,6 +99,21 @@ func (r *RedisCache) Set(ctx context.Context, key string, value string, ttl time
 	return err
 }
 
+// Delete removes a key; deleting a missing key is not an error
+func (r *RedisCache) Delete(ctx context.Context, key string) error {
+	conn, err := r.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := writeCommand(conn, "DEL", key); err != nil {
+		return err
+	}
+	_, _, err = readReply(conn)
+	return err
+}
+
 func writeCommand(conn net.Conn, args ...string) error {
 	var b strings.Builder
 	b.WriteString(fmt.Sprintf("*%d\r\n", len(args)))
@@ -107,7 +124,7 @@ func writeCommand(conn net.Conn, args ...string) error {
 	return err
 }
 
-// readReply handles simple string and bulk string
+// readReply handles simple string, integer and bulk string
 func readReply(conn net.Conn) (string, bool, error) {
 	reader := bufio.NewReader(conn)
 	line, err := reader.ReadString('\n')
@@ -118,7 +135,7 @@ func readReply(conn net.Conn) (string, bool, error) {
 		return "", false, fmt.Errorf("empty redis reply")
 	}
 	switch line[0] {
-	case '+': // simple string
+	case '+', ':': // simple string or integer
 		return strings.TrimSuffix(line[1:], "\r\n"), true, nil
 	case '$': // bulk string
 		sizeStr := strings.TrimSpace(line[1:])
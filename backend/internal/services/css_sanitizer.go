@@ -2,8 +2,11 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
+	"sort"
 	"strings"
+	"unicode"
 )
 
 // CSSSanitizer provides CSS validation and sanitization to prevent XSS attacks.
@@ -17,6 +20,14 @@ type CSSSanitizer struct {
 	bindingPattern    *regexp.Regexp
 	vbscriptPattern   *regexp.Regexp
 	htmlTagPattern    *regexp.Regexp
+
+	// Allowlist patterns for ValidateVariables
+	varNamePattern      *regexp.Regexp
+	hexColorPattern     *regexp.Regexp
+	rgbColorPattern     *regexp.Regexp
+	dimensionPattern    *regexp.Regexp
+	quotedStringPattern *regexp.Regexp
+	fontFamilyPattern   *regexp.Regexp
 }
 
 // NewCSSSanitizer creates a new CSS sanitizer with compiled patterns.
@@ -43,6 +54,15 @@ func NewCSSSanitizer() *CSSSanitizer {
 
 		// Block HTML tags (prevent breaking out of style context)
 		htmlTagPattern: regexp.MustCompile(`<[^>]*>`),
+
+		varNamePattern:   regexp.MustCompile(`^--[a-zA-Z0-9-_]+$`),
+		hexColorPattern:  regexp.MustCompile(`^#([0-9a-f]{3,4}|[0-9a-f]{6}|[0-9a-f]{8})$`),
+		rgbColorPattern:  regexp.MustCompile(`^rgba?\(\s*[0-9.%\s,/]+\)$`),
+		dimensionPattern: regexp.MustCompile(`^[-+]?(\d+|\d*\.\d+)(px|rem|em|%|ms|s)?$`),
+		// A quoted string can't escape its quotes or the declaration it sits in
+		quotedStringPattern: regexp.MustCompile(`^("[^"\\<>{};\r\n]*"|'[^'\\<>{};\r\n]*')$`),
+		// An unquoted family name is one or more identifiers, e.g. Segoe UI or -apple-system
+		fontFamilyPattern: regexp.MustCompile(`^-?[a-z_][a-z0-9_-]*( -?[a-z_][a-z0-9_-]*)*$`),
 	}
 }
 
@@ -56,6 +76,26 @@ func (s *CSSSanitizer) Sanitize(css string) error {
 	// Trim whitespace
 	css = strings.TrimSpace(css)
 
+	if err := s.checkDangerousPatterns(css); err != nil {
+		return err
+	}
+
+	// Check for balanced braces (prevent CSS injection)
+	if !s.hasBalancedBraces(css) {
+		return errors.New("CSS has unbalanced braces - possible injection attempt")
+	}
+
+	// Check CSS size limit (prevent DoS via large CSS)
+	const maxCSSSize = 100 * 1024 // 100KB
+	if len(css) > maxCSSSize {
+		return errors.New("CSS exceeds maximum size of 100KB")
+	}
+
+	return nil
+}
+
+// checkDangerousPatterns returns an error describing the first forbidden construct in css.
+func (s *CSSSanitizer) checkDangerousPatterns(css string) error {
 	// Check for HTML tags (attempt to break out of <style> context)
 	if s.htmlTagPattern.MatchString(css) {
 		return errors.New("CSS contains HTML tags")
@@ -95,17 +135,6 @@ func (s *CSSSanitizer) Sanitize(css string) error {
 		return errors.New("CSS contains forbidden -moz-binding property")
 	}
 
-	// Check for balanced braces (prevent CSS injection)
-	if !s.hasBalancedBraces(css) {
-		return errors.New("CSS has unbalanced braces - possible injection attempt")
-	}
-
-	// Check CSS size limit (prevent DoS via large CSS)
-	const maxCSSSize = 100 * 1024 // 100KB
-	if len(css) > maxCSSSize {
-		return errors.New("CSS exceeds maximum size of 100KB")
-	}
-
 	return nil
 }
 
@@ -160,6 +189,178 @@ func (s *CSSSanitizer) SanitizeVariables(variables map[string]interface{}) error
 	return nil
 }
 
+// ValidateVariables is the strict check applied when themes are saved. Names
+// must be custom properties (--name) and every value must be made of allowlisted
+// tokens: hex colors, rgb()/rgba(), named colors, quoted strings, and numbers
+// with an optional px, rem, em, % or ms/s unit. Space- or comma-separated lists
+// of those tokens (e.g. box shadows) are allowed; url(), expression() and
+// @import never are. Font family variables (--...font-family...) instead take a
+// font stack: a comma-separated list of quoted or unquoted family names.
+func (s *CSSSanitizer) ValidateVariables(variables map[string]interface{}) error {
+	keys := make([]string, 0, len(variables))
+	for key := range variables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if !s.varNamePattern.MatchString(key) {
+			return errors.New("invalid CSS variable name: " + key)
+		}
+
+		var value string
+		switch v := variables[key].(type) {
+		case string:
+			value = strings.TrimSpace(v)
+		case float64, int:
+			continue
+		default:
+			return errors.New("invalid CSS variable value type for: " + key)
+		}
+
+		if err := s.checkDangerousPatterns(value); err != nil {
+			return fmt.Errorf("CSS variable %s: %w", key, err)
+		}
+
+		if strings.Contains(key, "font-family") {
+			if err := s.validateFontStack(value); err != nil {
+				return fmt.Errorf("CSS variable %s: %w", key, err)
+			}
+			continue
+		}
+
+		tokens := splitValueTokens(value)
+		if len(tokens) == 0 {
+			return errors.New("CSS variable value is empty: " + key)
+		}
+		for _, token := range tokens {
+			if !s.isAllowedValueToken(token) {
+				return fmt.Errorf("CSS variable %s has a disallowed value: %q", key, token)
+			}
+		}
+	}
+
+	return nil
+}
+
+// isAllowedValueToken reports whether a single value token is on the allowlist.
+func (s *CSSSanitizer) isAllowedValueToken(token string) bool {
+	lower := strings.ToLower(token)
+	return s.hexColorPattern.MatchString(lower) ||
+		s.rgbColorPattern.MatchString(lower) ||
+		s.dimensionPattern.MatchString(lower) ||
+		s.quotedStringPattern.MatchString(token) ||
+		allowedCSSKeywords[lower]
+}
+
+// validateFontStack checks a comma-separated list of font families such as
+// -apple-system, 'Segoe UI', Roboto, sans-serif.
+func (s *CSSSanitizer) validateFontStack(value string) error {
+	if value == "" {
+		return errors.New("font stack is empty")
+	}
+	for _, family := range splitFontStack(value) {
+		family = strings.Join(strings.Fields(family), " ")
+		if !s.quotedStringPattern.MatchString(family) && !s.fontFamilyPattern.MatchString(strings.ToLower(family)) {
+			return fmt.Errorf("disallowed font family: %q", family)
+		}
+	}
+	return nil
+}
+
+// splitFontStack splits a font stack on the commas that are not inside quotes.
+func splitFontStack(value string) []string {
+	var parts []string
+	var quote rune
+	start := 0
+	for i, ch := range value {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == ',':
+			parts = append(parts, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, value[start:])
+}
+
+// splitValueTokens splits a variable value on whitespace and commas that are
+// not inside parentheses or quotes, so "0 1px rgb(0 0 0 / 0.1)" yields three
+// tokens and "'Segoe UI'" stays one.
+func splitValueTokens(value string) []string {
+	var tokens []string
+	var quote rune
+	depth := 0
+	start := 0
+	for i, ch := range value {
+		switch {
+		case quote != 0:
+			if ch == quote {
+				quote = 0
+			}
+		case ch == '"' || ch == '\'':
+			quote = ch
+		case ch == '(':
+			depth++
+		case ch == ')':
+			depth--
+		case depth == 0 && (ch == ',' || unicode.IsSpace(ch)):
+			if start < i {
+				tokens = append(tokens, value[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(value) {
+		tokens = append(tokens, value[start:])
+	}
+	return tokens
+}
+
+// allowedCSSKeywords are the named colors and keywords accepted in variable values.
+var allowedCSSKeywords = map[string]bool{
+	// Keywords
+	"transparent": true, "currentcolor": true, "inherit": true, "initial": true, "unset": true, "none": true,
+	"ease": true, "ease-in": true, "ease-out": true, "ease-in-out": true, "linear": true,
+
+	// Named colors
+	"aliceblue": true, "antiquewhite": true, "aqua": true, "aquamarine": true, "azure": true,
+	"beige": true, "bisque": true, "black": true, "blanchedalmond": true, "blue": true,
+	"blueviolet": true, "brown": true, "burlywood": true, "cadetblue": true, "chartreuse": true,
+	"chocolate": true, "coral": true, "cornflowerblue": true, "cornsilk": true, "crimson": true,
+	"cyan": true, "darkblue": true, "darkcyan": true, "darkgoldenrod": true, "darkgray": true,
+	"darkgreen": true, "darkgrey": true, "darkkhaki": true, "darkmagenta": true, "darkolivegreen": true,
+	"darkorange": true, "darkorchid": true, "darkred": true, "darksalmon": true, "darkseagreen": true,
+	"darkslateblue": true, "darkslategray": true, "darkslategrey": true, "darkturquoise": true, "darkviolet": true,
+	"deeppink": true, "deepskyblue": true, "dimgray": true, "dimgrey": true, "dodgerblue": true,
+	"firebrick": true, "floralwhite": true, "forestgreen": true, "fuchsia": true, "gainsboro": true,
+	"ghostwhite": true, "gold": true, "goldenrod": true, "gray": true, "green": true,
+	"greenyellow": true, "grey": true, "honeydew": true, "hotpink": true, "indianred": true,
+	"indigo": true, "ivory": true, "khaki": true, "lavender": true, "lavenderblush": true,
+	"lawngreen": true, "lemonchiffon": true, "lightblue": true, "lightcoral": true, "lightcyan": true,
+	"lightgoldenrodyellow": true, "lightgray": true, "lightgreen": true, "lightgrey": true, "lightpink": true,
+	"lightsalmon": true, "lightseagreen": true, "lightskyblue": true, "lightslategray": true, "lightslategrey": true,
+	"lightsteelblue": true, "lightyellow": true, "lime": true, "limegreen": true, "linen": true,
+	"magenta": true, "maroon": true, "mediumaquamarine": true, "mediumblue": true, "mediumorchid": true,
+	"mediumpurple": true, "mediumseagreen": true, "mediumslateblue": true, "mediumspringgreen": true, "mediumturquoise": true,
+	"mediumvioletred": true, "midnightblue": true, "mintcream": true, "mistyrose": true, "moccasin": true,
+	"navajowhite": true, "navy": true, "oldlace": true, "olive": true, "olivedrab": true,
+	"orange": true, "orangered": true, "orchid": true, "palegoldenrod": true, "palegreen": true,
+	"paleturquoise": true, "palevioletred": true, "papayawhip": true, "peachpuff": true, "peru": true,
+	"pink": true, "plum": true, "powderblue": true, "purple": true, "rebeccapurple": true,
+	"red": true, "rosybrown": true, "royalblue": true, "saddlebrown": true, "salmon": true,
+	"sandybrown": true, "seagreen": true, "seashell": true, "sienna": true, "silver": true,
+	"skyblue": true, "slateblue": true, "slategray": true, "slategrey": true, "snow": true,
+	"springgreen": true, "steelblue": true, "tan": true, "teal": true, "thistle": true,
+	"tomato": true, "turquoise": true, "violet": true, "wheat": true, "white": true,
+	"whitesmoke": true, "yellow": true, "yellowgreen": true,
+}
+
 // StripComments removes CSS comments (/* ... */) from input.
 // This helps prevent comment-based obfuscation of malicious code.
 func (s *CSSSanitizer) StripComments(css string) string {
@@ -199,6 +400,77 @@ func (s *CSSSanitizer) ValidateAndNormalize(css string) (string, error) {
 	return css, nil
 }
 
+// RejectedCSSRule is a top-level CSS rule dropped by SanitizeRules and why.
+type RejectedCSSRule struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// SanitizeRules is the lenient counterpart of ValidateAndNormalize used for previews.
+// Instead of failing on the first problem it drops each dangerous top-level rule
+// (including whole @media blocks) and returns the remaining normalized CSS, one
+// rule per line, along with the rules it rejected.
+func (s *CSSSanitizer) SanitizeRules(css string) (string, []RejectedCSSRule) {
+	rejected := []RejectedCSSRule{}
+	css = s.NormalizeWhitespace(s.StripComments(css))
+	if css == "" {
+		return "", rejected
+	}
+
+	var kept []string
+	for _, rule := range splitTopLevelRules(css) {
+		if err := s.checkDangerousPatterns(rule); err != nil {
+			rejected = append(rejected, RejectedCSSRule{Rule: rule, Reason: err.Error()})
+			continue
+		}
+		if !s.hasBalancedBraces(rule) {
+			rejected = append(rejected, RejectedCSSRule{Rule: rule, Reason: "CSS has unbalanced braces - possible injection attempt"})
+			continue
+		}
+		kept = append(kept, rule)
+	}
+
+	return strings.Join(kept, "\n"), rejected
+}
+
+// splitTopLevelRules splits normalized CSS into top-level statements: blocks such
+// as "a { color: red; }" or "@media ... { ... }" and block-less at-rules ending
+// in ";". Any unterminated trailing text is returned as its own statement.
+func splitTopLevelRules(css string) []string {
+	var rules []string
+	depth := 0
+	start := 0
+	for i, ch := range css {
+		switch ch {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth <= 0 {
+				rules = append(rules, strings.TrimSpace(css[start:i+1]))
+				start = i + 1
+				depth = 0
+			}
+		case ';':
+			if depth == 0 {
+				rules = append(rules, strings.TrimSpace(css[start:i+1]))
+				start = i + 1
+			}
+		}
+	}
+	if rest := strings.TrimSpace(css[start:]); rest != "" {
+		rules = append(rules, rest)
+	}
+
+	filtered := rules[:0]
+	for _, rule := range rules {
+		if rule != "" && rule != ";" {
+			filtered = append(filtered, rule)
+		}
+	}
+	return filtered
+}
+
 // IsValidSelector checks if a CSS selector is safe.
 // This is a basic check - full CSS parsing would be more robust.
 func (s *CSSSanitizer) IsValidSelector(selector string) bool {
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeRules_StripsAndReportsDangerousRules(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	css := `
+		@import "https://evil.example/steal.css";
+		.card { color: red; }
+		.legacy { width: expression(alert(1)); }
+		.link { background: url(javascript:alert(1)); }
+		/* comment */ .title   {  font-weight: bold;  }
+	`
+
+	sanitized, rejected := sanitizer.SanitizeRules(css)
+
+	assert.Equal(t, ".card { color: red; }\n.title { font-weight: bold; }", sanitized)
+	require.Len(t, rejected, 3)
+	assert.Contains(t, rejected[0].Rule, "@import")
+	assert.Contains(t, rejected[0].Reason, "@import")
+	assert.Contains(t, rejected[1].Rule, ".legacy")
+	assert.Contains(t, rejected[1].Reason, "expression()")
+	assert.Contains(t, rejected[2].Rule, ".link")
+	assert.Contains(t, rejected[2].Reason, "url()")
+}
+
+func TestSanitizeRules_SafeCSSPassesThrough(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	sanitized, rejected := sanitizer.SanitizeRules(`@media (max-width: 600px) { .a { color: blue; } .b { margin: 0; } }`)
+
+	assert.Empty(t, rejected)
+	assert.Equal(t, `@media (max-width: 600px) { .a { color: blue; } .b { margin: 0; } }`, sanitized)
+	assert.NoError(t, sanitizer.Sanitize(sanitized))
+}
+
+func TestSanitizeRules_DropsWholeAtRuleContainingDangerousRule(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	sanitized, rejected := sanitizer.SanitizeRules(`.ok { color: red; } @media print { .x { behavior: url(x.htc); } }`)
+
+	assert.Equal(t, ".ok { color: red; }", sanitized)
+	require.Len(t, rejected, 1)
+	assert.Contains(t, rejected[0].Rule, "@media print")
+}
+
+func TestSanitizeRules_ReportsUnbalancedBraces(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	sanitized, rejected := sanitizer.SanitizeRules(`.a { color: red; } } .b { color: blue;`)
+
+	assert.Equal(t, ".a { color: red; }", sanitized)
+	require.Len(t, rejected, 2)
+	for _, rule := range rejected {
+		assert.Contains(t, rule.Reason, "unbalanced braces")
+	}
+}
+
+func TestValidateVariables_AcceptsAllowlistedValues(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	err := sanitizer.ValidateVariables(map[string]interface{}{
+		"--color-primary":   "#3b82f6",
+		"--color-short":     "#fff",
+		"--color-overlay":   "rgba(0, 0, 0, 0.5)",
+		"--color-text":      "RebeccaPurple",
+		"--color-bg":        "transparent",
+		"--font-size-base":  "1rem",
+		"--spacing-sm":      "0.5em",
+		"--border-width":    "2px",
+		"--shadow-md":       "0 4px 6px -1px rgb(0 0 0 / 0.1)",
+		"--transition-base": "200ms ease",
+		"--z-index":         float64(10),
+	})
+	assert.NoError(t, err)
+}
+
+func TestValidateVariables_AcceptsFontStacksAndQuotedStrings(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	err := sanitizer.ValidateVariables(map[string]interface{}{
+		"--font-family-base": "-apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif",
+		"--font-family-mono": `"Fira Code", Menlo Regular, monospace`,
+		"--label-separator":  "' / '",
+	})
+	assert.NoError(t, err)
+}
+
+func TestValidateVariables_RejectsMalformedFontStacks(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	for _, value := range []string{
+		"",
+		"Arial,, serif",
+		"'Segoe UI",
+		"Arial; } body { display: none",
+		"'Evil'; color: red",
+		"Arial, 12px",
+		"Arial, rgb(0 0 0)",
+	} {
+		err := sanitizer.ValidateVariables(map[string]interface{}{"--font-family-base": value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestValidateVariables_RejectsDangerousValues(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	cases := map[string]string{
+		"javascript url": "url(javascript:alert(1))",
+		"expression":     "expression(alert(1))",
+		"import":         "@import 'https://evil.example/x.css'",
+		"bare protocol":  "javascript:alert(1)",
+	}
+	for name, value := range cases {
+		err := sanitizer.ValidateVariables(map[string]interface{}{"--color-primary": value})
+		assert.Error(t, err, name)
+	}
+}
+
+func TestValidateVariables_RejectsValuesOutsideAllowlist(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	for _, value := range []string{"notacolor", "#12345", "calc(100% - 2px)", "12pt", "", "red; } body { display: none", `"\201C"`, "'a} b'"} {
+		err := sanitizer.ValidateVariables(map[string]interface{}{"--color-primary": value})
+		assert.Error(t, err, value)
+	}
+}
+
+func TestValidateVariables_RejectsUnknownKey(t *testing.T) {
+	sanitizer := NewCSSSanitizer()
+
+	err := sanitizer.ValidateVariables(map[string]interface{}{"background-image": "red"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "background-image")
+}
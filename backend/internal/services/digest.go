@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+)
+
+// EmailMessage is a plain-text email to a single recipient.
+type EmailMessage struct {
+	To      string
+	Subject string
+	Body    string
+	// Headers are extra headers such as List-Unsubscribe
+	Headers map[string]string
+}
+
+// Mailer sends email. Implementations can wrap SMTP or a provider API;
+// NoopMailer is used when none is configured.
+type Mailer interface {
+	Send(ctx context.Context, msg EmailMessage) error
+}
+
+// NoopMailer discards every message.
+type NoopMailer struct{}
+
+// Send implements Mailer.
+func (NoopMailer) Send(ctx context.Context, msg EmailMessage) error { return nil }
+
+// DigestConfig controls who receives digest emails and how often.
+type DigestConfig struct {
+	// InactiveFor is how long a user must have been away to get a digest
+	InactiveFor time.Duration
+	// Cadence is the minimum time between two digests to the same user
+	Cadence time.Duration
+	// MaxPosts and MaxNotifications cap each section of the email
+	MaxPosts         int
+	MaxNotifications int
+	// BatchSize caps how many recipients are loaded per query
+	BatchSize int
+	// UnsubscribeURL is the base URL of the unsubscribe endpoint; the token is appended as ?token=
+	UnsubscribeURL string
+}
+
+// DefaultDigestConfig returns the settings used when none are configured.
+func DefaultDigestConfig() DigestConfig {
+	return DigestConfig{
+		InactiveFor:      7 * 24 * time.Hour,
+		Cadence:          7 * 24 * time.Hour,
+		MaxPosts:         5,
+		MaxNotifications: 10,
+		BatchSize:        500,
+		UnsubscribeURL:   "http://localhost:8080/api/v1/digest/unsubscribe",
+	}
+}
+
+// DigestService composes and sends digest emails to inactive users.
+type DigestService struct {
+	repo   *models.DigestRepository
+	mailer Mailer
+	config DigestConfig
+	now    func() time.Time
+}
+
+// NewDigestService creates a digest service. A nil mailer falls back to NoopMailer.
+func NewDigestService(repo *models.DigestRepository, mailer Mailer, config DigestConfig) *DigestService {
+	if mailer == nil {
+		mailer = NoopMailer{}
+	}
+	return &DigestService{repo: repo, mailer: mailer, config: config, now: time.Now}
+}
+
+// SendDigests emails every eligible user whose quiet hours aren't in effect and
+// who has something to read. Users with nothing to report are skipped without
+// being marked, so they're reconsidered on the next run. Recipients are paged
+// by user ID, so skipped users never crowd later ones out of a batch.
+// Returns the number sent.
+func (s *DigestService) SendDigests(ctx context.Context) (int, error) {
+	now := s.now()
+	batchSize := s.config.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultDigestConfig().BatchSize
+	}
+
+	sent := 0
+	afterID := 0
+	for {
+		recipients, err := s.repo.ListRecipients(ctx, now.Add(-s.config.InactiveFor), now.Add(-s.config.Cadence), afterID, batchSize)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, rec := range recipients {
+			if s.sendDigest(ctx, rec, now) {
+				sent++
+			}
+		}
+
+		if len(recipients) < batchSize {
+			return sent, nil
+		}
+		afterID = recipients[len(recipients)-1].UserID
+	}
+}
+
+// sendDigest emails one recipient and records it. Returns false if the user
+// was skipped or anything failed.
+func (s *DigestService) sendDigest(ctx context.Context, rec *models.DigestRecipient, now time.Time) bool {
+	if InQuietHours(now, rec.QuietHoursStart, rec.QuietHoursEnd) {
+		return false
+	}
+
+	msg, ok, err := s.buildDigest(ctx, rec)
+	if err != nil {
+		log.Printf("Failed to build digest for user %d: %v", rec.UserID, err)
+		return false
+	}
+	if !ok {
+		return false
+	}
+
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		log.Printf("Failed to send digest to user %d: %v", rec.UserID, err)
+		return false
+	}
+	if err := s.repo.MarkSent(ctx, rec.UserID, now); err != nil {
+		log.Printf("Failed to record digest for user %d: %v", rec.UserID, err)
+		return false
+	}
+	return true
+}
+
+// buildDigest composes the email for one recipient. ok is false when there is
+// nothing worth sending.
+func (s *DigestService) buildDigest(ctx context.Context, rec *models.DigestRecipient) (EmailMessage, bool, error) {
+	// Only cover what's new since the user last visited or last got a digest
+	since := rec.LastSeen
+	if rec.LastDigestSentAt != nil && rec.LastDigestSentAt.After(since) {
+		since = *rec.LastDigestSentAt
+	}
+	posts, err := s.repo.GetTopSubscribedPosts(ctx, rec.UserID, since, s.config.MaxPosts)
+	if err != nil {
+		return EmailMessage{}, false, err
+	}
+	notifications, err := s.repo.GetUnreadNotifications(ctx, rec.UserID, digestNotificationTypes(rec), s.config.MaxNotifications)
+	if err != nil {
+		return EmailMessage{}, false, err
+	}
+	if len(posts) == 0 && len(notifications) == 0 {
+		return EmailMessage{}, false, nil
+	}
+
+	token, err := s.repo.EnsureUnsubscribeToken(ctx, rec.UserID)
+	if err != nil {
+		return EmailMessage{}, false, err
+	}
+	unsubscribeURL := s.config.UnsubscribeURL + "?token=" + url.QueryEscape(token)
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "Hi %s,\n\nHere's what you missed since your last visit.\n", rec.Username)
+	if len(posts) > 0 {
+		body.WriteString("\nTop posts in your hubs:\n")
+		for _, post := range posts {
+			fmt.Fprintf(&body, "- [h/%s] %s (%d points, %d comments)\n", post.HubName, post.Title, post.Score, post.NumComments)
+		}
+	}
+	if len(notifications) > 0 {
+		body.WriteString("\nUnread notifications:\n")
+		for _, n := range notifications {
+			fmt.Fprintf(&body, "- %s\n", n.Message)
+		}
+	}
+	fmt.Fprintf(&body, "\nTo stop receiving these emails, unsubscribe here: %s\n", unsubscribeURL)
+
+	return EmailMessage{
+		To:      rec.Email,
+		Subject: "Your OmniNudge digest",
+		Body:    body.String(),
+		Headers: map[string]string{
+			"List-Unsubscribe": "<" + unsubscribeURL + ">",
+			// RFC 8058 one-click: mail clients POST to the link to unsubscribe
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, true, nil
+}
+
+// digestNotificationTypes returns the notification types the user still wants to hear about.
+func digestNotificationTypes(rec *models.DigestRecipient) []string {
+	var types []string
+	if rec.NotifyCommentReplies {
+		types = append(types, "comment_reply")
+	}
+	if rec.NotifyPostMilestone {
+		types = append(types, "post_milestone")
+	}
+	if rec.NotifyPostVelocity {
+		types = append(types, "post_velocity")
+	}
+	if rec.NotifyCommentMilestone {
+		types = append(types, "comment_milestone")
+	}
+	if rec.NotifyCommentVelocity {
+		types = append(types, "comment_velocity")
+	}
+	return types
+}
+
+// InQuietHours reports whether t falls in the UTC quiet window [start, end).
+// Windows may wrap past midnight (e.g. 22 to 7); equal bounds or unset hours
+// mean no quiet hours.
+func InQuietHours(t time.Time, start, end *int) bool {
+	if start == nil || end == nil || *start == *end {
+		return false
+	}
+	hour := t.UTC().Hour()
+	if *start < *end {
+		return hour >= *start && hour < *end
+	}
+	return hour >= *start || hour < *end
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubMailer struct {
+	mu   sync.Mutex
+	sent []EmailMessage
+}
+
+func (m *stubMailer) Send(ctx context.Context, msg EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// sentTo returns the messages addressed to the given recipients, ignoring
+// anyone else left eligible in a shared test database.
+func (m *stubMailer) sentTo(emails ...string) map[string]EmailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	wanted := map[string]bool{}
+	for _, e := range emails {
+		wanted[e] = true
+	}
+	result := map[string]EmailMessage{}
+	for _, msg := range m.sent {
+		if wanted[msg.To] {
+			result[msg.To] = msg
+		}
+	}
+	return result
+}
+
+type digestTestEnv struct {
+	db       *database.Database
+	service  *DigestService
+	mailer   *stubMailer
+	settings *models.UserSettingsRepository
+	hubID    int
+	authorID int
+	suffix   int64
+}
+
+func setupDigestTest(t *testing.T) (*digestTestEnv, func()) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	hubRepo := models.NewHubRepository(db.Pool)
+
+	suffix := time.Now().UnixNano()
+	author := &models.User{Username: fmt.Sprintf("digestauthor_%d", suffix), PasswordHash: "hash"}
+	require.NoError(t, userRepo.Create(ctx, author))
+	hub := &models.Hub{Name: fmt.Sprintf("digesthub_%d", suffix), CreatedBy: &author.ID}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	mailer := &stubMailer{}
+	config := DefaultDigestConfig()
+	config.BatchSize = 10000
+	env := &digestTestEnv{
+		db:       db,
+		service:  NewDigestService(models.NewDigestRepository(db.Pool), mailer, config),
+		mailer:   mailer,
+		settings: models.NewUserSettingsRepository(db.Pool),
+		hubID:    hub.ID,
+		authorID: author.ID,
+		suffix:   suffix,
+	}
+	return env, func() { db.Close() }
+}
+
+// createReader creates a hub subscriber with an email address who was last seen
+// lastSeenAgo ago and has digests turned on or off.
+func (env *digestTestEnv) createReader(t *testing.T, name string, optedIn bool, lastSeenAgo time.Duration) string {
+	t.Helper()
+	ctx := context.Background()
+
+	user := &models.User{Username: fmt.Sprintf("%s_%d", name, env.suffix), PasswordHash: "hash"}
+	require.NoError(t, models.NewUserRepository(env.db.Pool).Create(ctx, user))
+
+	email := fmt.Sprintf("%s_%d@example.com", name, env.suffix)
+	_, err := env.db.Pool.Exec(ctx,
+		`UPDATE users SET email = $2, email_encrypted = FALSE, last_seen = $3 WHERE id = $1`,
+		user.ID, email, time.Now().Add(-lastSeenAgo))
+	require.NoError(t, err)
+
+	settings, err := env.settings.CreateDefault(ctx, user.ID)
+	require.NoError(t, err)
+	settings.DailyDigest = optedIn
+	_, err = env.settings.Update(ctx, settings)
+	require.NoError(t, err)
+
+	require.NoError(t, models.NewHubSubscriptionRepository(env.db.Pool).Subscribe(ctx, user.ID, env.hubID))
+	return email
+}
+
+func (env *digestTestEnv) publishPost(t *testing.T, title string) {
+	t.Helper()
+	post := &models.PlatformPost{AuthorID: env.authorID, HubID: &env.hubID, Title: title}
+	require.NoError(t, models.NewPlatformPostRepository(env.db.Pool).Create(context.Background(), post))
+}
+
+func TestSendDigests_OnlyOptedInInactiveUsers(t *testing.T) {
+	env, cleanup := setupDigestTest(t)
+	defer cleanup()
+
+	week := 7 * 24 * time.Hour
+	inactive := env.createReader(t, "digest_inactive", true, 10*24*time.Hour)
+	optedOut := env.createReader(t, "digest_optedout", false, 10*24*time.Hour)
+	recent := env.createReader(t, "digest_recent", true, time.Hour)
+	borderline := env.createReader(t, "digest_borderline", true, week-time.Hour)
+	env.publishPost(t, "Something you missed")
+
+	_, err := env.service.SendDigests(context.Background())
+	require.NoError(t, err)
+
+	sent := env.mailer.sentTo(inactive, optedOut, recent, borderline)
+	require.Len(t, sent, 1)
+	msg, ok := sent[inactive]
+	require.True(t, ok)
+	assert.Contains(t, msg.Body, "Something you missed")
+	assert.Contains(t, msg.Body, "unsubscribe")
+	assert.Contains(t, msg.Headers["List-Unsubscribe"], "?token=")
+	assert.Equal(t, "List-Unsubscribe=One-Click", msg.Headers["List-Unsubscribe-Post"])
+}
+
+func TestSendDigests_RespectsCadenceAndUnsubscribe(t *testing.T) {
+	env, cleanup := setupDigestTest(t)
+	defer cleanup()
+
+	email := env.createReader(t, "digest_cadence", true, 10*24*time.Hour)
+	env.publishPost(t, "First news")
+
+	_, err := env.service.SendDigests(context.Background())
+	require.NoError(t, err)
+	msg, ok := env.mailer.sentTo(email)[email]
+	require.True(t, ok)
+
+	// A second run within the cadence must not email the user again
+	env.mailer.sent = nil
+	env.publishPost(t, "Second news")
+	_, err = env.service.SendDigests(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, env.mailer.sentTo(email))
+
+	// The link in the email turns digests off
+	token := msg.Body[strings.Index(msg.Body, "?token=")+len("?token="):]
+	unsubscribed, err := models.NewDigestRepository(env.db.Pool).Unsubscribe(context.Background(), strings.TrimSpace(token))
+	require.NoError(t, err)
+	assert.True(t, unsubscribed)
+}
+
+func TestSendDigests_SkipsUsersInQuietHours(t *testing.T) {
+	env, cleanup := setupDigestTest(t)
+	defer cleanup()
+
+	email := env.createReader(t, "digest_quiet", true, 10*24*time.Hour)
+	env.publishPost(t, "Late night news")
+
+	// Quiet hours covering the current UTC hour
+	_, err := env.db.Pool.Exec(context.Background(), `
+		UPDATE user_settings SET quiet_hours_start = $2, quiet_hours_end = $3
+		WHERE user_id = (SELECT id FROM users WHERE email = $1)`,
+		email, time.Now().UTC().Hour(), (time.Now().UTC().Hour()+1)%24)
+	require.NoError(t, err)
+
+	_, err = env.service.SendDigests(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, env.mailer.sentTo(email))
+}
+
+func TestSendDigests_PagesPastSkippedUsers(t *testing.T) {
+	env, cleanup := setupDigestTest(t)
+	defer cleanup()
+
+	// A batch of one: the quiet user comes first and is skipped without being
+	// marked, yet the reader created after it must still get a digest
+	env.service.config.BatchSize = 1
+	quiet := env.createReader(t, "digest_paged_quiet", true, 10*24*time.Hour)
+	reader := env.createReader(t, "digest_paged_reader", true, 10*24*time.Hour)
+	env.publishPost(t, "Paged news")
+
+	_, err := env.db.Pool.Exec(context.Background(), `
+		UPDATE user_settings SET quiet_hours_start = $2, quiet_hours_end = $3
+		WHERE user_id = (SELECT id FROM users WHERE email = $1)`,
+		quiet, time.Now().UTC().Hour(), (time.Now().UTC().Hour()+1)%24)
+	require.NoError(t, err)
+
+	_, err = env.service.SendDigests(context.Background())
+	require.NoError(t, err)
+	sent := env.mailer.sentTo(quiet, reader)
+	assert.Len(t, sent, 1)
+	assert.Contains(t, sent, reader)
+}
+
+func TestInQuietHours(t *testing.T) {
+	hours := func(start, end int) (*int, *int) { return &start, &end }
+	at := func(hour int) time.Time { return time.Date(2024, 1, 1, hour, 30, 0, 0, time.UTC) }
+
+	start, end := hours(22, 7)
+	assert.True(t, InQuietHours(at(23), start, end))
+	assert.True(t, InQuietHours(at(6), start, end))
+	assert.False(t, InQuietHours(at(7), start, end))
+	assert.False(t, InQuietHours(at(21), start, end))
+
+	start, end = hours(9, 17)
+	assert.True(t, InQuietHours(at(9), start, end))
+	assert.False(t, InQuietHours(at(17), start, end))
+
+	assert.False(t, InQuietHours(at(3), nil, nil))
+	start, end = hours(5, 5)
+	assert.False(t, InQuietHours(at(5), start, end))
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"context"
+	"hash/fnv"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+)
+
+// DefaultFeatureFlagTTL is how long flag settings are cached before reloading
+const DefaultFeatureFlagTTL = 30 * time.Second
+
+// FeatureFlagStore loads all feature flags (implemented by models.FeatureFlagRepository)
+type FeatureFlagStore interface {
+	List(ctx context.Context) ([]*models.FeatureFlag, error)
+}
+
+// FeatureFlags answers whether a named flag is on for a user.
+// Flags are loaded in bulk and cached in memory for a short TTL so that
+// route guards do not hit the database on every request.
+type FeatureFlags struct {
+	store FeatureFlagStore
+	ttl   time.Duration
+
+	mu       sync.RWMutex
+	flags    map[string]*models.FeatureFlag
+	loadedAt time.Time
+	now      func() time.Time
+}
+
+// NewFeatureFlags creates a cached feature flag accessor
+func NewFeatureFlags(store FeatureFlagStore, ttl time.Duration) *FeatureFlags {
+	if ttl <= 0 {
+		ttl = DefaultFeatureFlagTTL
+	}
+	return &FeatureFlags{
+		store: store,
+		ttl:   ttl,
+		now:   time.Now,
+	}
+}
+
+// IsEnabled reports whether the flag is on for the given user.
+// Use userID 0 for anonymous requests; they only see fully rolled out flags.
+// Unknown flags are treated as off.
+func (f *FeatureFlags) IsEnabled(ctx context.Context, name string, userID int) bool {
+	flag := f.get(ctx, name)
+	if flag == nil || !flag.Enabled {
+		return false
+	}
+
+	for _, id := range flag.UserIDs {
+		if userID != 0 && int(id) == userID {
+			return true
+		}
+	}
+
+	if flag.RolloutPercentage >= 100 {
+		return true
+	}
+	if flag.RolloutPercentage <= 0 || userID == 0 {
+		return false
+	}
+	return RolloutBucket(name, userID) < flag.RolloutPercentage
+}
+
+// Invalidate drops the cached flags so the next lookup reloads them
+func (f *FeatureFlags) Invalidate() {
+	f.mu.Lock()
+	f.loadedAt = time.Time{}
+	f.mu.Unlock()
+}
+
+// RolloutBucket deterministically maps a user to a bucket in [0, 100) for a
+// flag. Hashing the flag name with the user ID keeps a user's bucket stable
+// for one flag while spreading rollouts of different flags across users.
+func RolloutBucket(name string, userID int) int {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{':'})
+	h.Write([]byte(strconv.Itoa(userID)))
+	return int(h.Sum32() % 100)
+}
+
+func (f *FeatureFlags) get(ctx context.Context, name string) *models.FeatureFlag {
+	f.mu.RLock()
+	snapshot := f.flags
+	fresh := snapshot != nil && f.now().Sub(f.loadedAt) < f.ttl
+	f.mu.RUnlock()
+	if fresh {
+		return snapshot[name]
+	}
+
+	// Query without holding the lock so other lookups keep using the snapshot
+	flags, err := f.store.List(ctx)
+	if err != nil {
+		log.Printf("Failed to load feature flags: %v", err)
+		if snapshot != nil {
+			// Keep serving the last known settings and retry after another TTL
+			f.mu.Lock()
+			f.loadedAt = f.now()
+			f.mu.Unlock()
+		}
+		// With nothing loaded yet, nothing is cached so the next lookup retries
+		return snapshot[name]
+	}
+
+	loaded := make(map[string]*models.FeatureFlag, len(flags))
+	for _, fl := range flags {
+		loaded[fl.Name] = fl
+	}
+
+	f.mu.Lock()
+	f.flags = loaded
+	f.loadedAt = f.now()
+	f.mu.Unlock()
+	return loaded[name]
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubFlagStore struct {
+	flags []*models.FeatureFlag
+	err   error
+	calls int
+}
+
+func (s *stubFlagStore) List(ctx context.Context) ([]*models.FeatureFlag, error) {
+	s.calls++
+	return s.flags, s.err
+}
+
+func TestFeatureFlags_OffAndUnknownFlagsAreDisabled(t *testing.T) {
+	store := &stubFlagStore{flags: []*models.FeatureFlag{
+		{Name: "polls", Enabled: false, RolloutPercentage: 100},
+		{Name: "themes_marketplace", Enabled: true, RolloutPercentage: 100},
+	}}
+	flags := NewFeatureFlags(store, time.Minute)
+	ctx := context.Background()
+
+	assert.False(t, flags.IsEnabled(ctx, "polls", 1))
+	assert.False(t, flags.IsEnabled(ctx, "does_not_exist", 1))
+	assert.True(t, flags.IsEnabled(ctx, "themes_marketplace", 1))
+	assert.True(t, flags.IsEnabled(ctx, "themes_marketplace", 0), "fully rolled out flags apply to anonymous users")
+}
+
+func TestFeatureFlags_PercentageRolloutIsDeterministic(t *testing.T) {
+	store := &stubFlagStore{flags: []*models.FeatureFlag{
+		{Name: "slideshow", Enabled: true, RolloutPercentage: 30},
+	}}
+	flags := NewFeatureFlags(store, time.Minute)
+	ctx := context.Background()
+
+	included, excluded := 0, 0
+	for userID := 1; userID <= 1000; userID++ {
+		expected := RolloutBucket("slideshow", userID) < 30
+		got := flags.IsEnabled(ctx, "slideshow", userID)
+		require.Equal(t, expected, got, "user %d", userID)
+		// Repeated lookups must give the same answer
+		require.Equal(t, got, flags.IsEnabled(ctx, "slideshow", userID))
+		if got {
+			included++
+		} else {
+			excluded++
+		}
+	}
+
+	// Roughly 30% of users land in the rollout
+	assert.InDelta(t, 300, included, 60)
+	assert.InDelta(t, 700, excluded, 60)
+	assert.False(t, flags.IsEnabled(ctx, "slideshow", 0), "partial rollouts exclude anonymous users")
+}
+
+func TestRolloutBucket_StableAndInRange(t *testing.T) {
+	for userID := 1; userID <= 200; userID++ {
+		bucket := RolloutBucket("polls", userID)
+		assert.GreaterOrEqual(t, bucket, 0)
+		assert.Less(t, bucket, 100)
+		assert.Equal(t, bucket, RolloutBucket("polls", userID))
+	}
+}
+
+func TestFeatureFlags_TargetedUsersAlwaysIncluded(t *testing.T) {
+	store := &stubFlagStore{flags: []*models.FeatureFlag{
+		{Name: "polls", Enabled: true, RolloutPercentage: 0, UserIDs: []int32{42}},
+	}}
+	flags := NewFeatureFlags(store, time.Minute)
+	ctx := context.Background()
+
+	assert.True(t, flags.IsEnabled(ctx, "polls", 42))
+	assert.False(t, flags.IsEnabled(ctx, "polls", 43))
+}
+
+func TestFeatureFlags_CachesUntilTTLOrInvalidate(t *testing.T) {
+	store := &stubFlagStore{flags: []*models.FeatureFlag{
+		{Name: "polls", Enabled: true, RolloutPercentage: 100},
+	}}
+	flags := NewFeatureFlags(store, time.Minute)
+	now := time.Now()
+	flags.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	assert.True(t, flags.IsEnabled(ctx, "polls", 1))
+	store.flags[0] = &models.FeatureFlag{Name: "polls", Enabled: false}
+	assert.True(t, flags.IsEnabled(ctx, "polls", 1), "cached value is served within the TTL")
+	assert.Equal(t, 1, store.calls)
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, flags.IsEnabled(ctx, "polls", 1))
+	assert.Equal(t, 2, store.calls)
+
+	store.flags[0] = &models.FeatureFlag{Name: "polls", Enabled: true, RolloutPercentage: 100}
+	flags.Invalidate()
+	assert.True(t, flags.IsEnabled(ctx, "polls", 1))
+	assert.Equal(t, 3, store.calls)
+}
+
+func TestFeatureFlags_KeepsLastKnownFlagsOnStoreError(t *testing.T) {
+	store := &stubFlagStore{flags: []*models.FeatureFlag{
+		{Name: "polls", Enabled: true, RolloutPercentage: 100},
+	}}
+	flags := NewFeatureFlags(store, time.Minute)
+	ctx := context.Background()
+
+	require.True(t, flags.IsEnabled(ctx, "polls", 1))
+
+	store.err = errors.New("database unavailable")
+	flags.Invalidate()
+	assert.True(t, flags.IsEnabled(ctx, "polls", 1))
+}
+
+func TestFeatureFlags_RetriesInitialLoadAfterStoreError(t *testing.T) {
+	store := &stubFlagStore{err: errors.New("database unavailable")}
+	flags := NewFeatureFlags(store, time.Minute)
+	ctx := context.Background()
+
+	assert.False(t, flags.IsEnabled(ctx, "polls", 1))
+
+	store.err = nil
+	store.flags = []*models.FeatureFlag{{Name: "polls", Enabled: true, RolloutPercentage: 100}}
+	assert.True(t, flags.IsEnabled(ctx, "polls", 1), "a failed first load must not be cached")
+	assert.Equal(t, 2, store.calls)
+}
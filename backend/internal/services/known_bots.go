@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// KnownBotStore loads admin-added bot usernames (implemented by models.RedditKnownBotRepository)
+type KnownBotStore interface {
+	ListUsernames(ctx context.Context) ([]string, error)
+}
+
+// KnownBots recognizes Reddit bot accounts from a built-in list plus
+// admin-added usernames, which are cached for a short TTL.
+type KnownBots struct {
+	builtIn map[string]bool
+	store   KnownBotStore
+	ttl     time.Duration
+
+	mu       sync.RWMutex
+	extra    map[string]bool
+	loadedAt time.Time
+}
+
+// NewKnownBots creates a bot matcher. store may be nil to use only the built-in list.
+func NewKnownBots(builtIn []string, store KnownBotStore, ttl time.Duration) *KnownBots {
+	if ttl <= 0 {
+		ttl = time.Minute
+	}
+	k := &KnownBots{
+		builtIn: make(map[string]bool, len(builtIn)),
+		store:   store,
+		ttl:     ttl,
+	}
+	for _, name := range builtIn {
+		k.builtIn[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return k
+}
+
+// IsBot reports whether username belongs to a known bot (case-insensitive)
+func (k *KnownBots) IsBot(ctx context.Context, username string) bool {
+	name := strings.ToLower(strings.TrimSpace(username))
+	if name == "" {
+		return false
+	}
+	if k.builtIn[name] {
+		return true
+	}
+	return k.loadExtra(ctx)[name]
+}
+
+// Invalidate drops cached admin-added bots so the next lookup reloads them
+func (k *KnownBots) Invalidate() {
+	k.mu.Lock()
+	k.loadedAt = time.Time{}
+	k.mu.Unlock()
+}
+
+func (k *KnownBots) loadExtra(ctx context.Context) map[string]bool {
+	if k.store == nil {
+		return nil
+	}
+
+	k.mu.RLock()
+	if k.extra != nil && time.Since(k.loadedAt) < k.ttl {
+		extra := k.extra
+		k.mu.RUnlock()
+		return extra
+	}
+	k.mu.RUnlock()
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.extra != nil && time.Since(k.loadedAt) < k.ttl {
+		return k.extra
+	}
+
+	usernames, err := k.store.ListUsernames(ctx)
+	if err != nil {
+		log.Printf("Failed to load known Reddit bots: %v", err)
+		if k.extra == nil {
+			k.extra = map[string]bool{}
+		}
+		k.loadedAt = time.Now()
+		return k.extra
+	}
+
+	k.extra = make(map[string]bool, len(usernames))
+	for _, name := range usernames {
+		k.extra[strings.ToLower(name)] = true
+	}
+	k.loadedAt = time.Now()
+	return k.extra
+}
+
+// FlagBotComments walks a raw Reddit comments response ([post listing,
+// comment listing]) and marks every comment with is_bot. Bot comments are
+// also marked collapsed unless expand is true. Returns the number of bot
+// comments found.
+func (k *KnownBots) FlagBotComments(ctx context.Context, thread interface{}, expand bool) int {
+	count := 0
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch v := node.(type) {
+		case []interface{}:
+			for _, item := range v {
+				walk(item)
+			}
+		case map[string]interface{}:
+			data, _ := v["data"].(map[string]interface{})
+			if data == nil {
+				return
+			}
+			if v["kind"] == "t1" {
+				author, _ := data["author"].(string)
+				isBot := k.IsBot(ctx, author)
+				data["is_bot"] = isBot
+				if isBot {
+					count++
+					if !expand {
+						data["collapsed"] = true
+					}
+				}
+				walk(data["replies"])
+				return
+			}
+			walk(data["children"])
+		}
+	}
+	walk(thread)
+	return count
+}
@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubKnownBotStore struct {
+	usernames []string
+	calls     int
+}
+
+func (s *stubKnownBotStore) ListUsernames(ctx context.Context) ([]string, error) {
+	s.calls++
+	return s.usernames, nil
+}
+
+func TestKnownBots_BuiltInAndAdminAdded(t *testing.T) {
+	store := &stubKnownBotStore{usernames: []string{"customhelperbot"}}
+	bots := NewKnownBots([]string{"AutoModerator"}, store, time.Minute)
+	ctx := context.Background()
+
+	assert.True(t, bots.IsBot(ctx, "automoderator"), "matching is case-insensitive")
+	assert.True(t, bots.IsBot(ctx, "CustomHelperBot"))
+	assert.False(t, bots.IsBot(ctx, "regular_user"))
+	assert.False(t, bots.IsBot(ctx, ""))
+
+	// Admin additions are cached until invalidated
+	store.usernames = append(store.usernames, "newbot")
+	assert.False(t, bots.IsBot(ctx, "newbot"))
+	bots.Invalidate()
+	assert.True(t, bots.IsBot(ctx, "newbot"))
+	assert.Equal(t, 2, store.calls)
+}
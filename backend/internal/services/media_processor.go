@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+)
+
+// MediaProcessingStore records the outcome of media processing.
+// MediaFileRepository implements it.
+type MediaProcessingStore interface {
+	ListProcessing(ctx context.Context, limit int) ([]*models.MediaFile, error)
+	MarkProcessed(ctx context.Context, id int, playableURL string, hlsURL *string) error
+	MarkProcessingFailed(ctx context.Context, id int, reason string) error
+}
+
+// MediaProcessor transcodes uploaded videos in the background and stores the
+// renditions through the storage service.
+type MediaProcessor struct {
+	store      MediaProcessingStore
+	transcoder Transcoder
+	storage    StorageService
+	timeout    time.Duration
+	slots      chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewMediaProcessor creates a media processor running at most workers
+// transcodes at once. A nil transcoder disables processing.
+func NewMediaProcessor(store MediaProcessingStore, transcoder Transcoder, storage StorageService, workers int, timeout time.Duration) *MediaProcessor {
+	if transcoder == nil {
+		transcoder = NoopTranscoder{}
+	}
+	if workers < 1 {
+		workers = 1
+	}
+	return &MediaProcessor{
+		store:      store,
+		transcoder: transcoder,
+		storage:    storage,
+		timeout:    timeout,
+		slots:      make(chan struct{}, workers),
+	}
+}
+
+// Enabled reports whether uploads are transcoded at all.
+func (p *MediaProcessor) Enabled() bool {
+	if p == nil {
+		return false
+	}
+	_, noop := p.transcoder.(NoopTranscoder)
+	return !noop
+}
+
+// NeedsProcessing reports whether an upload should go through the transcoder.
+func (p *MediaProcessor) NeedsProcessing(contentType, filename string) bool {
+	return p.Enabled() && IsVideoType(contentType, filename)
+}
+
+// Enqueue transcodes media in the background. The media row must already be
+// stored with processing status.
+func (p *MediaProcessor) Enqueue(media *models.MediaFile) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		p.slots <- struct{}{}
+		defer func() { <-p.slots }()
+
+		ctx := context.Background()
+		if p.timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, p.timeout)
+			defer cancel()
+		}
+		if err := p.Process(ctx, media); err != nil {
+			log.Printf("Failed to process media %d: %v", media.ID, err)
+		}
+	}()
+}
+
+// ResumePending re-enqueues media left processing by a previous run.
+func (p *MediaProcessor) ResumePending(ctx context.Context) error {
+	if !p.Enabled() {
+		return nil
+	}
+	pending, err := p.store.ListProcessing(ctx, 500)
+	if err != nil {
+		return err
+	}
+	for _, media := range pending {
+		p.Enqueue(media)
+	}
+	return nil
+}
+
+// Wait blocks until all enqueued media has been processed.
+func (p *MediaProcessor) Wait() {
+	p.wg.Wait()
+}
+
+// Process transcodes one media file and records the result. A failed
+// transcode marks the media failed; the original upload stays usable.
+func (p *MediaProcessor) Process(ctx context.Context, media *models.MediaFile) error {
+	workDir, err := os.MkdirTemp("", fmt.Sprintf("transcode_%d_", media.ID))
+	if err != nil {
+		return p.fail(ctx, media.ID, err)
+	}
+	defer os.RemoveAll(workDir)
+
+	result, err := p.transcoder.Transcode(ctx, media.StoragePath, workDir)
+	if err != nil {
+		return p.fail(ctx, media.ID, err)
+	}
+	if result == nil {
+		return p.fail(ctx, media.ID, fmt.Errorf("transcoder produced no output"))
+	}
+
+	prefix := path.Join("transcoded", fmt.Sprintf("%d", media.ID))
+	mp4, err := p.saveFile(ctx, result.MP4Path, path.Join(prefix, "video.mp4"))
+	if err != nil {
+		return p.fail(ctx, media.ID, err)
+	}
+
+	var hlsURL *string
+	if result.HLSDir != "" {
+		entries, err := os.ReadDir(result.HLSDir)
+		if err != nil {
+			return p.fail(ctx, media.ID, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			saved, err := p.saveFile(ctx, filepath.Join(result.HLSDir, entry.Name()), path.Join(prefix, "hls", entry.Name()))
+			if err != nil {
+				return p.fail(ctx, media.ID, err)
+			}
+			if entry.Name() == HLSPlaylistName {
+				url := relativeMediaURL(saved)
+				hlsURL = &url
+			}
+		}
+	}
+
+	return p.store.MarkProcessed(ctx, media.ID, relativeMediaURL(mp4), hlsURL)
+}
+
+// relativeMediaURL returns the site-relative /uploads/... URL of a saved file,
+// the same form the upload handler records, so stored URLs don't pin a host.
+func relativeMediaURL(f *UploadedFile) string {
+	return "/uploads/" + filepath.ToSlash(f.StoragePath)
+}
+
+func (p *MediaProcessor) saveFile(ctx context.Context, localPath, relPath string) (*UploadedFile, error) {
+	f, err := os.Open(localPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return p.storage.Save(ctx, relPath, f)
+}
+
+// fail marks the media failed and returns the cause
+func (p *MediaProcessor) fail(ctx context.Context, mediaID int, cause error) error {
+	// Record the failure even if the transcode ran out of time
+	if err := p.store.MarkProcessingFailed(context.WithoutCancel(ctx), mediaID, cause.Error()); err != nil {
+		log.Printf("Failed to mark media %d as failed: %v", mediaID, err)
+	}
+	return cause
+}
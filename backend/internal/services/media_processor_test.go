@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubMediaStore records processing outcomes in memory
+type stubMediaStore struct {
+	mu       sync.Mutex
+	pending  []*models.MediaFile
+	playable map[int]string
+	hls      map[int]*string
+	failed   map[int]string
+}
+
+func newStubMediaStore(pending ...*models.MediaFile) *stubMediaStore {
+	return &stubMediaStore{
+		pending:  pending,
+		playable: map[int]string{},
+		hls:      map[int]*string{},
+		failed:   map[int]string{},
+	}
+}
+
+func (s *stubMediaStore) ListProcessing(ctx context.Context, limit int) ([]*models.MediaFile, error) {
+	return s.pending, nil
+}
+
+func (s *stubMediaStore) MarkProcessed(ctx context.Context, id int, playableURL string, hlsURL *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.playable[id] = playableURL
+	s.hls[id] = hlsURL
+	return nil
+}
+
+func (s *stubMediaStore) MarkProcessingFailed(ctx context.Context, id int, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[id] = reason
+	return nil
+}
+
+// stubTranscoder writes placeholder renditions instead of running ffmpeg
+type stubTranscoder struct {
+	hls bool
+	err error
+}
+
+func (t stubTranscoder) Transcode(ctx context.Context, inputPath, outputDir string) (*TranscodeResult, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	result := &TranscodeResult{MP4Path: filepath.Join(outputDir, "video.mp4")}
+	if err := os.WriteFile(result.MP4Path, []byte("mp4"), 0o644); err != nil {
+		return nil, err
+	}
+	if t.hls {
+		result.HLSDir = filepath.Join(outputDir, "hls")
+		if err := os.MkdirAll(result.HLSDir, 0o755); err != nil {
+			return nil, err
+		}
+		for _, name := range []string{HLSPlaylistName, "segment_000.ts"} {
+			if err := os.WriteFile(filepath.Join(result.HLSDir, name), []byte(name), 0o644); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+func TestMediaProcessor_MarksReadyWithRenditions(t *testing.T) {
+	baseDir := t.TempDir()
+	store := newStubMediaStore()
+	storage := NewLocalStorageService(baseDir, "http://media.test")
+	processor := NewMediaProcessor(store, stubTranscoder{hls: true}, storage, 1, 0)
+
+	require.True(t, processor.NeedsProcessing("video/quicktime", "clip.mov"))
+	processor.Enqueue(&models.MediaFile{ID: 7, StoragePath: "media/clip.mov"})
+	processor.Wait()
+
+	require.Empty(t, store.failed)
+	assert.Equal(t, "/uploads/media/transcoded/7/video.mp4", store.playable[7])
+	require.NotNil(t, store.hls[7])
+	assert.Equal(t, "/uploads/media/transcoded/7/hls/index.m3u8", *store.hls[7])
+
+	for _, rel := range []string{"video.mp4", "hls/index.m3u8", "hls/segment_000.ts"} {
+		assert.FileExists(t, filepath.Join(baseDir, "media", "transcoded", "7", rel))
+	}
+}
+
+func TestMediaProcessor_MarksFailedWhenTranscodeFails(t *testing.T) {
+	store := newStubMediaStore(&models.MediaFile{ID: 3, StoragePath: "media/broken.mp4"})
+	storage := NewLocalStorageService(t.TempDir(), "")
+	processor := NewMediaProcessor(store, stubTranscoder{err: errors.New("corrupt input")}, storage, 2, 0)
+
+	// Pending rows from a previous run are picked up again
+	require.NoError(t, processor.ResumePending(context.Background()))
+	processor.Wait()
+
+	assert.Empty(t, store.playable)
+	assert.Contains(t, store.failed[3], "corrupt input")
+}
+
+func TestMediaProcessor_DisabledWithoutTranscoder(t *testing.T) {
+	var nilProcessor *MediaProcessor
+	assert.False(t, nilProcessor.NeedsProcessing("video/mp4", "clip.mp4"))
+
+	processor := NewMediaProcessor(newStubMediaStore(), NoopTranscoder{}, nil, 1, 0)
+	assert.False(t, processor.Enabled())
+	assert.False(t, processor.NeedsProcessing("video/mp4", "clip.mp4"))
+
+	enabled := NewMediaProcessor(newStubMediaStore(), stubTranscoder{}, nil, 1, 0)
+	assert.True(t, enabled.NeedsProcessing("application/octet-stream", "clip.MKV"))
+	assert.False(t, enabled.NeedsProcessing("image/png", "photo.png"))
+}
+
+func TestLocalStorageSave_RejectsEscapingPaths(t *testing.T) {
+	storage := NewLocalStorageService(t.TempDir(), "")
+	for _, rel := range []string{"../outside.mp4", "/etc/passwd", ""} {
+		_, err := storage.Save(context.Background(), rel, strings.NewReader("x"))
+		assert.Error(t, err, rel)
+	}
+}
+
+func TestFFmpegTranscoder_ProducesMP4AndHLS(t *testing.T) {
+	transcoder := NewFFmpegTranscoder("ffmpeg", true)
+	if !transcoder.Available() {
+		t.Skip("ffmpeg not installed")
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.avi")
+	gen := exec.Command("ffmpeg", "-hide_banner", "-loglevel", "error",
+		"-f", "lavfi", "-i", "testsrc=duration=1:size=160x120:rate=10",
+		"-f", "lavfi", "-i", "sine=duration=1",
+		"-c:v", "mpeg4", "-c:a", "mp2", input)
+	out, err := gen.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	result, err := transcoder.Transcode(context.Background(), input, dir)
+	if err != nil && strings.Contains(err.Error(), "Unknown encoder") {
+		t.Skip("ffmpeg built without libx264")
+	}
+	require.NoError(t, err)
+	assert.FileExists(t, result.MP4Path)
+	assert.FileExists(t, filepath.Join(result.HLSDir, HLSPlaylistName))
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/omninudge/backend/internal/models"
+)
+
+// maxMentionsPerContent caps how many users a single post or comment can notify
+const maxMentionsPerContent = 10
+
+var (
+	fencedCodePattern = regexp.MustCompile("(?s)```.*?(```|$)")
+	inlineCodePattern = regexp.MustCompile("`[^`\n]*`")
+	// A mention starts the text or follows a character that can't be part of a
+	// word, email address or URL path, so "bob@example.com" isn't a mention
+	mentionPattern = regexp.MustCompile(`(?:^|[^\w@/.])@([A-Za-z0-9_-]+)`)
+)
+
+// ParseMentions returns the usernames mentioned as @username in text, in order
+// of first appearance and without duplicates (compared case-insensitively).
+// Mentions inside inline code or fenced code blocks are ignored.
+func ParseMentions(text string) []string {
+	text = fencedCodePattern.ReplaceAllString(text, " ")
+	text = inlineCodePattern.ReplaceAllString(text, " ")
+
+	var usernames []string
+	seen := map[string]bool{}
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		username := strings.TrimRight(match[1], "-_")
+		key := strings.ToLower(username)
+		if username == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
+
+// NotifyMentions notifies every user mentioned in a new post or comment. The
+// author, unknown usernames, users who have blocked the author and users who
+// can't open the post (e.g. outsiders to a private hub) are skipped; each user
+// is notified at most once per piece of content, so users already notified
+// about it (e.g. of a reply to their comment) get no mention on top.
+func (s *NotificationService) NotifyMentions(
+	ctx context.Context,
+	contentType string,
+	contentID int,
+	authorID int,
+	text string,
+) error {
+	usernames := ParseMentions(text)
+	if len(usernames) > maxMentionsPerContent {
+		usernames = usernames[:maxMentionsPerContent]
+	}
+
+	if len(usernames) == 0 {
+		return nil
+	}
+
+	postID := contentID
+	if contentType == "comment" {
+		comment, err := s.commentRepo.GetByID(ctx, contentID)
+		if err != nil {
+			return err
+		}
+		postID = comment.PostID
+	}
+
+	notified := map[int]bool{authorID: true}
+	for _, username := range usernames {
+		user, err := s.userRepo.GetByUsername(ctx, username)
+		if errors.Is(err, pgx.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			log.Printf("Failed to look up mentioned user %q: %v", username, err)
+			continue
+		}
+		if user == nil || notified[user.ID] {
+			continue
+		}
+		notified[user.ID] = true
+
+		var skip bool
+		if err := s.pool.QueryRow(ctx, `
+			SELECT EXISTS(SELECT 1 FROM blocked_users WHERE blocker_id = $1 AND blocked_id = $2)
+				OR EXISTS(SELECT 1 FROM notifications WHERE user_id = $1 AND content_type = $3 AND content_id = $4)
+		`, user.ID, authorID, contentType, contentID).Scan(&skip); err != nil {
+			return err
+		}
+		if skip {
+			continue
+		}
+
+		accessible, err := s.postRepo.IsAccessibleTo(ctx, postID, &user.ID)
+		if err != nil {
+			log.Printf("Failed to check post %d access for mentioned user %d: %v", postID, user.ID, err)
+			continue
+		}
+		if !accessible {
+			continue
+		}
+
+		ct := contentType
+		id := contentID
+		actorID := authorID
+		notification := &models.Notification{
+			UserID:           user.ID,
+			NotificationType: "mention",
+			ContentType:      &ct,
+			ContentID:        &id,
+			ActorID:          &actorID,
+			Message:          "Someone mentioned you in a " + contentType,
+		}
+		if err := s.sendNotification(ctx, notification); err != nil {
+			log.Printf("Failed to notify user %d of mention: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
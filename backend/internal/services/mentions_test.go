@@ -0,0 +1,33 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"none", "no mentions here", nil},
+		{"start of text", "@alice hi", []string{"alice"}},
+		{"trailing punctuation", "thanks @alice, @bob! and @carol.", []string{"alice", "bob", "carol"}},
+		{"wrapped", "(@alice) \"@bob\" @carol's idea", []string{"alice", "bob", "carol"}},
+		{"underscores and dashes", "cc @some_user-1 and @dash-", []string{"some_user-1", "dash"}},
+		{"deduped case-insensitively", "@alice @Alice @ALICE @bob", []string{"alice", "bob"}},
+		{"email addresses ignored", "write to bob@example.com", nil},
+		{"url paths ignored", "see https://example.com/@alice", nil},
+		{"bare at sign", "meet @ noon", nil},
+		{"inline code ignored", "run `@alice` then ping @bob", []string{"bob"}},
+		{"fenced code ignored", "```\n@alice\n@bob\n```\nthanks @carol", []string{"carol"}},
+		{"unterminated fence ignored", "@alice\n```\n@bob", []string{"alice"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseMentions(tt.text))
+		})
+	}
+}
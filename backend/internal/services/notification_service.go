@@ -2,8 +2,12 @@ package services
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/omninudge/backend/internal/models"
@@ -28,10 +32,23 @@ type NotificationService struct {
 	settingsRepo     *models.UserSettingsRepository
 	postRepo         *models.PlatformPostRepository
 	commentRepo      *models.PostCommentRepository
+	userRepo         *models.UserRepository
 	hub              *websocket.Hub
 	velocityDetector VelocityDetector
+	pushSender       PushSender
+	pushRepo         *models.PushSubscriptionRepository
+	pushSlots        chan struct{}
+	pushWG           sync.WaitGroup
+	now              func() time.Time
 }
 
+const (
+	// maxInFlightPushes caps concurrent web push deliveries; further pushes are dropped
+	maxInFlightPushes = 64
+	// pushDeliveryTimeout bounds delivery to all of one user's subscriptions
+	pushDeliveryTimeout = 30 * time.Second
+)
+
 // NewNotificationService creates a new notification service
 func NewNotificationService(
 	pool *pgxpool.Pool,
@@ -41,6 +58,7 @@ func NewNotificationService(
 	settingsRepo *models.UserSettingsRepository,
 	postRepo *models.PlatformPostRepository,
 	commentRepo *models.PostCommentRepository,
+	userRepo *models.UserRepository,
 	hub *websocket.Hub,
 ) *NotificationService {
 	ns := &NotificationService{
@@ -51,13 +69,25 @@ func NewNotificationService(
 		settingsRepo: settingsRepo,
 		postRepo:     postRepo,
 		commentRepo:  commentRepo,
+		userRepo:     userRepo,
 		hub:          hub,
+		now:          time.Now,
 	}
 	// Use rule-based detector by default, can be swapped for ML later
 	ns.velocityDetector = NewRuleBasedVelocityDetector(pool, baselineRepo)
 	return ns
 }
 
+// SetPushSender enables web push delivery to users who aren't connected over websocket
+func (s *NotificationService) SetPushSender(sender PushSender, repo *models.PushSubscriptionRepository) {
+	if sender == nil {
+		sender = NoopPushSender{}
+	}
+	s.pushSender = sender
+	s.pushRepo = repo
+	s.pushSlots = make(chan struct{}, maxInFlightPushes)
+}
+
 // CheckAndNotifyVote processes a vote and determines if notification should be sent
 // This is called after every upvote on posts/comments
 func (s *NotificationService) CheckAndNotifyVote(
@@ -85,7 +115,7 @@ func (s *NotificationService) CheckAndNotifyVote(
 	// Check velocity notifications
 	if (contentType == "post" && settings.NotifyPostVelocity) ||
 		(contentType == "comment" && settings.NotifyCommentVelocity) {
-		if err := s.checkVelocityNotification(ctx, contentType, contentID, authorID); err != nil {
+		if err := s.checkVelocityNotification(ctx, contentType, contentID, authorID, settings.NotificationCadence); err != nil {
 			log.Printf("Velocity check failed: %v", err)
 		}
 	}
@@ -136,12 +166,14 @@ func (s *NotificationService) checkMilestoneNotification(
 	return nil
 }
 
-// checkVelocityNotification checks if content is getting unusual upvote velocity
+// checkVelocityNotification checks if content is getting unusual upvote velocity.
+// Users on an hourly or daily cadence get it in their next digest instead.
 func (s *NotificationService) checkVelocityNotification(
 	ctx context.Context,
 	contentType string,
 	contentID int,
 	authorID int,
+	cadence string,
 ) error {
 	// Calculate current velocity (votes in last 3 hours)
 	votesPerHour, err := s.calculateVelocity(ctx, contentType, contentID, 3)
@@ -159,6 +191,23 @@ func (s *NotificationService) checkVelocityNotification(
 		return nil
 	}
 
+	notifType := fmt.Sprintf("%s_velocity", contentType)
+	vphInt := int(votesPerHour)
+
+	if cadence == models.NotificationCadenceHourly || cadence == models.NotificationCadenceDaily {
+		// Even viral content waits for the digest; the user asked for fewer interruptions
+		return s.batchRepo.Create(ctx, &models.NotificationBatch{
+			UserID:           authorID,
+			ContentType:      contentType,
+			ContentID:        contentID,
+			NotificationType: notifType,
+			VotesPerHour:     &vphInt,
+			ScheduledFor:     digestWindowEnd(cadence, s.now()),
+			Status:           "pending",
+			Cadence:          &cadence,
+		})
+	}
+
 	// Check if exponential growth (determines batching)
 	isExponential, err := s.velocityDetector.IsExponentialGrowth(ctx, contentType, contentID, votesPerHour)
 	if err != nil {
@@ -166,9 +215,7 @@ func (s *NotificationService) checkVelocityNotification(
 		isExponential = false
 	}
 
-	notifType := fmt.Sprintf("%s_velocity", contentType)
-	message := s.buildVelocityMessage(contentType, int(votesPerHour))
-	vphInt := int(votesPerHour)
+	message := s.buildVelocityMessage(contentType, vphInt)
 
 	if isExponential {
 		// Send immediately for viral content
@@ -233,6 +280,88 @@ func (s *NotificationService) NotifyCommentReply(
 	return s.sendNotification(ctx, notification)
 }
 
+// reportFoldWindow is how long an unread report outcome notification keeps
+// absorbing further outcomes of the same kind, so a moderator clearing many of
+// one user's reports at once produces a single notification
+const reportFoldWindow = time.Hour
+
+// ReportOutcomeNotificationType maps a resolved report status to the
+// notification type sent to its reporter. ok is false for statuses that
+// aren't a resolution.
+func ReportOutcomeNotificationType(status string) (string, bool) {
+	switch status {
+	case "reviewed":
+		return "report_action_taken", true
+	case "dismissed":
+		return "report_no_action", true
+	default:
+		return "", false
+	}
+}
+
+// NotifyReportResolved tells a reporter how their report was resolved. The
+// notification carries only the outcome: no moderator, target or reason.
+func (s *NotificationService) NotifyReportResolved(ctx context.Context, reporterID int, status string) error {
+	notificationType, ok := ReportOutcomeNotificationType(status)
+	if !ok {
+		return nil
+	}
+
+	settings, err := s.getOrCreateSettings(ctx, reporterID)
+	if err != nil {
+		log.Printf("Failed to get settings for user %d: %v", reporterID, err)
+		return nil
+	}
+	if !settings.NotifyReportUpdates {
+		return nil // User has opted out of report updates
+	}
+
+	message := func(count int) string {
+		return s.buildReportOutcomeMessage(notificationType, count)
+	}
+	folded, err := s.notifRepo.FoldIntoUnread(ctx, reporterID, notificationType, time.Now().Add(-reportFoldWindow), message)
+	if err != nil {
+		return err
+	}
+	if folded != nil {
+		return nil
+	}
+
+	return s.sendNotification(ctx, &models.Notification{
+		UserID:           reporterID,
+		NotificationType: notificationType,
+		Message:          message(1),
+	})
+}
+
+// NotifyContentRemoved tells an author that a moderator removed their post or
+// comment, quoting the removal reason. Like report outcomes, the moderator who
+// acted is not exposed.
+func (s *NotificationService) NotifyContentRemoved(
+	ctx context.Context,
+	authorID int,
+	contentType string,
+	contentID int,
+	reason *models.RemovalReason,
+) error {
+	settings, err := s.getOrCreateSettings(ctx, authorID)
+	if err != nil {
+		log.Printf("Failed to get settings for user %d: %v", authorID, err)
+		return nil
+	}
+	if !settings.NotifyContentRemoved {
+		return nil // User has opted out of removal notices
+	}
+
+	return s.sendNotification(ctx, &models.Notification{
+		UserID:           authorID,
+		NotificationType: "content_removed",
+		ContentType:      &contentType,
+		ContentID:        &contentID,
+		Message:          s.buildContentRemovedMessage(contentType, reason),
+	})
+}
+
 // ProcessBatchedNotifications processes all pending notification batches
 // Called by the worker every 15 minutes
 func (s *NotificationService) ProcessBatchedNotifications(ctx context.Context) error {
@@ -269,32 +398,172 @@ func (s *NotificationService) ProcessBatchedNotifications(ctx context.Context) e
 	return nil
 }
 
+// digestWindowEnd returns when the digest window containing now closes: the top
+// of the next hour for hourly digests, or the next midnight UTC for daily ones
+func digestWindowEnd(cadence string, now time.Time) time.Time {
+	now = now.UTC()
+	if cadence == models.NotificationCadenceDaily {
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	}
+	return now.Truncate(time.Hour).Add(time.Hour)
+}
+
+// FlushDigests sends each user a single digest notification covering every
+// batch queued under cadence whose window has closed, and returns how many
+// digests were sent. Called by the worker alongside ProcessBatchedNotifications.
+func (s *NotificationService) FlushDigests(ctx context.Context, cadence string) (int, error) {
+	batches, err := s.batchRepo.GetDueDigestBatches(ctx, cadence, s.now().UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for start := 0; start < len(batches); {
+		end := start
+		for end < len(batches) && batches[end].UserID == batches[start].UserID {
+			end++
+		}
+		if err := s.sendDigest(ctx, cadence, batches[start:end]); err != nil {
+			log.Printf("Failed to send %s digest to user %d: %v", cadence, batches[start].UserID, err)
+		} else {
+			sent++
+		}
+		start = end
+	}
+
+	return sent, nil
+}
+
+// sendDigest collapses one user's due batches into a single notification
+func (s *NotificationService) sendDigest(ctx context.Context, cadence string, batches []*models.NotificationBatch) error {
+	type content struct {
+		contentType string
+		contentID   int
+	}
+	seen := make(map[content]bool)
+	posts, comments := 0, 0
+	ids := make([]int, len(batches))
+	for i, batch := range batches {
+		ids[i] = batch.ID
+		key := content{batch.ContentType, batch.ContentID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		if batch.ContentType == "post" {
+			posts++
+		} else {
+			comments++
+		}
+	}
+
+	notification := &models.Notification{
+		UserID:           batches[0].UserID,
+		NotificationType: "notification_digest",
+		Message:          s.buildDigestMessage(cadence, posts, comments),
+	}
+	if err := s.sendNotification(ctx, notification); err != nil {
+		return err
+	}
+
+	return s.batchRepo.MarkAllAsProcessed(ctx, ids)
+}
+
 // sendNotification creates and delivers a notification
 func (s *NotificationService) sendNotification(ctx context.Context, notification *models.Notification) error {
+	// Drop notifications whose category the user has muted
+	prefs, err := s.settingsRepo.GetNotificationPreferences(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+	if !prefs.Allows(notification.NotificationType) {
+		return nil
+	}
+
 	// Save to database (persistent storage)
 	if err := s.notifRepo.Create(ctx, notification); err != nil {
 		return err
 	}
 
-	// Send via WebSocket if user is online
+	payload := gin.H{
+		"id":                notification.ID,
+		"notification_type": notification.NotificationType,
+		"message":           notification.Message,
+		"content_type":      notification.ContentType,
+		"content_id":        notification.ContentID,
+		"created_at":        notification.CreatedAt,
+	}
+
+	// Send via WebSocket if user is online, otherwise fall back to web push
 	if s.hub != nil && s.hub.IsUserOnline(notification.UserID) {
 		s.hub.Broadcast(&websocket.Message{
 			RecipientID: notification.UserID,
 			Type:        "notification",
-			Payload: gin.H{
-				"id":                notification.ID,
-				"notification_type": notification.NotificationType,
-				"message":           notification.Message,
-				"content_type":      notification.ContentType,
-				"content_id":        notification.ContentID,
-				"created_at":        notification.CreatedAt,
-			},
+			Payload:     payload,
 		})
+	} else {
+		s.queuePush(notification.UserID, payload)
 	}
 
 	return nil
 }
 
+// queuePush delivers a web push in the background, so a slow push service
+// never holds up the request that created the notification. Delivery gets
+// its own context because the request's is cancelled once it returns.
+func (s *NotificationService) queuePush(userID int, payload gin.H) {
+	if s.pushSender == nil || s.pushRepo == nil {
+		return
+	}
+
+	select {
+	case s.pushSlots <- struct{}{}:
+	default:
+		log.Printf("Dropping push for user %d: %d deliveries already in flight", userID, maxInFlightPushes)
+		return
+	}
+
+	s.pushWG.Add(1)
+	go func() {
+		defer s.pushWG.Done()
+		defer func() { <-s.pushSlots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), pushDeliveryTimeout)
+		defer cancel()
+		s.sendPush(ctx, userID, payload)
+	}()
+}
+
+// sendPush delivers a notification to every push subscription of an offline user.
+// Failures are logged rather than returned since the notification is already stored.
+func (s *NotificationService) sendPush(ctx context.Context, userID int, payload gin.H) {
+	subs, err := s.pushRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to load push subscriptions for user %d: %v", userID, err)
+		return
+	}
+	if len(subs) == 0 {
+		return
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		log.Printf("Failed to encode push payload for user %d: %v", userID, err)
+		return
+	}
+
+	for _, sub := range subs {
+		err := s.pushSender.Send(ctx, sub, body)
+		if errors.Is(err, ErrPushSubscriptionExpired) || errors.Is(err, ErrPushEndpointNotAllowed) {
+			if err := s.pushRepo.DeleteByEndpoint(ctx, sub.Endpoint); err != nil {
+				log.Printf("Failed to delete expired push subscription %d: %v", sub.ID, err)
+			}
+		} else if err != nil {
+			log.Printf("Push to subscription %d failed: %v", sub.ID, err)
+		}
+	}
+}
+
 // calculateVelocity calculates votes per hour for content over the last N hours
 func (s *NotificationService) calculateVelocity(
 	ctx context.Context,
@@ -347,3 +616,36 @@ func (s *NotificationService) buildVelocityMessage(contentType string, votesPerH
 	}
 	return fmt.Sprintf("Your comment is trending! Getting %d upvotes/hour", votesPerHour)
 }
+
+// buildDigestMessage creates the message summarizing a digest window
+func (s *NotificationService) buildDigestMessage(cadence string, posts, comments int) string {
+	var parts []string
+	if posts == 1 {
+		parts = append(parts, "1 post")
+	} else if posts > 1 {
+		parts = append(parts, fmt.Sprintf("%d posts", posts))
+	}
+	if comments == 1 {
+		parts = append(parts, "1 comment")
+	} else if comments > 1 {
+		parts = append(parts, fmt.Sprintf("%d comments", comments))
+	}
+	return fmt.Sprintf("Your %s digest: %s of yours trended", cadence, strings.Join(parts, " and "))
+}
+
+// buildReportOutcomeMessage creates the message telling a reporter how their reports were resolved
+func (s *NotificationService) buildReportOutcomeMessage(notificationType string, count int) string {
+	outcome := "no action was needed"
+	if notificationType == "report_action_taken" {
+		outcome = "action was taken"
+	}
+	if count == 1 {
+		return fmt.Sprintf("Thanks for your report. A moderator reviewed it and %s.", outcome)
+	}
+	return fmt.Sprintf("Thanks for your reports. Moderators reviewed %d of them and %s.", count, outcome)
+}
+
+// buildContentRemovedMessage creates the message telling an author why their content was removed
+func (s *NotificationService) buildContentRemovedMessage(contentType string, reason *models.RemovalReason) string {
+	return fmt.Sprintf("Your %s was removed by the moderators. Reason: %s\n\n%s", contentType, reason.Title, reason.Message)
+}
@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -48,6 +50,7 @@ func setupNotificationTest(t *testing.T) (*NotificationService, *database.Databa
 		settingsRepo,
 		postRepo,
 		commentRepo,
+		models.NewUserRepository(db.Pool),
 		hub,
 	)
 
@@ -309,3 +312,231 @@ func TestSelfReplyNoNotification(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, notifs, 0, "Should not create notification for self-reply")
 }
+
+// alwaysTrendingDetector flags every vote as unusual, non-exponential velocity
+type alwaysTrendingDetector struct{}
+
+func (alwaysTrendingDetector) ShouldNotify(ctx context.Context, userID int, contentType string, votesPerHour float64) (bool, error) {
+	return true, nil
+}
+
+func (alwaysTrendingDetector) IsExponentialGrowth(ctx context.Context, contentType string, contentID int, currentVPH float64) (bool, error) {
+	return false, nil
+}
+
+func TestHourlyDigestCollapsesVelocityNotifications(t *testing.T) {
+	service, db, cleanup := setupNotificationTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	service.velocityDetector = alwaysTrendingDetector{}
+	now := time.Date(2030, 1, 1, 10, 20, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	authorID := createTestUser(t, db, uniqueNotificationName("author"))
+	creatorID := createTestUser(t, db, uniqueNotificationName("creator"))
+	hubID := createTestHub(t, db, uniqueNotificationName("test_hub"), creatorID)
+	firstPost := createTestPost(t, db, authorID, hubID)
+	secondPost := createTestPost(t, db, authorID, hubID)
+	comment := createTestComment(t, db, firstPost, authorID, nil)
+
+	settingsRepo := models.NewUserSettingsRepository(db.Pool)
+	settings, err := settingsRepo.CreateDefault(ctx, authorID)
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationCadenceImmediate, settings.NotificationCadence)
+	settings.NotifyPostVelocity = true
+	settings.NotifyCommentVelocity = true
+	settings.NotificationCadence = models.NotificationCadenceHourly
+	_, err = settingsRepo.Update(ctx, settings)
+	require.NoError(t, err)
+
+	// Several trending events within the 10:00-11:00 window
+	for i := 0; i < 3; i++ {
+		require.NoError(t, service.CheckAndNotifyVote(ctx, "post", firstPost, authorID, 1))
+	}
+	require.NoError(t, service.CheckAndNotifyVote(ctx, "post", secondPost, authorID, 1))
+	require.NoError(t, service.CheckAndNotifyVote(ctx, "comment", comment, authorID, 1))
+
+	notifRepo := models.NewNotificationRepository(db.Pool)
+	notifications := func() []*models.Notification {
+		notifs, err := notifRepo.GetByUserID(ctx, authorID, 10, 0, false)
+		require.NoError(t, err)
+		return notifs
+	}
+	assert.Empty(t, notifications(), "nothing is delivered until the window closes")
+
+	// Regular batch processing leaves digest batches alone
+	pending, err := models.NewNotificationBatchRepository(db.Pool).GetPendingBatches(ctx, now.Add(24*time.Hour))
+	require.NoError(t, err)
+	for _, batch := range pending {
+		assert.NotEqual(t, authorID, batch.UserID)
+	}
+
+	now = time.Date(2030, 1, 1, 10, 59, 0, 0, time.UTC)
+	_, err = service.FlushDigests(ctx, models.NotificationCadenceHourly)
+	require.NoError(t, err)
+	assert.Empty(t, notifications())
+
+	now = time.Date(2030, 1, 1, 11, 0, 0, 0, time.UTC)
+	sent, err := service.FlushDigests(ctx, models.NotificationCadenceHourly)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, sent, 1)
+
+	notifs := notifications()
+	require.Len(t, notifs, 1, "the window collapses into a single digest")
+	assert.Equal(t, "notification_digest", notifs[0].NotificationType)
+	assert.Equal(t, "Your hourly digest: 2 posts and 1 comment of yours trended", notifs[0].Message)
+
+	// Flushed batches aren't sent again
+	_, err = service.FlushDigests(ctx, models.NotificationCadenceHourly)
+	require.NoError(t, err)
+	assert.Len(t, notifications(), 1)
+}
+
+func TestDigestWindowEnd(t *testing.T) {
+	now := time.Date(2030, 3, 31, 23, 45, 10, 0, time.UTC)
+	assert.Equal(t, time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), digestWindowEnd(models.NotificationCadenceHourly, now))
+	assert.Equal(t, time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), digestWindowEnd(models.NotificationCadenceDaily, now))
+
+	now = time.Date(2030, 3, 31, 8, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2030, 3, 31, 9, 0, 0, 0, time.UTC), digestWindowEnd(models.NotificationCadenceHourly, now))
+	assert.Equal(t, time.Date(2030, 4, 1, 0, 0, 0, 0, time.UTC), digestWindowEnd(models.NotificationCadenceDaily, now))
+}
+
+// recordingPushSender records every push and reports expiredEndpoint as gone
+type recordingPushSender struct {
+	mu              sync.Mutex
+	sent            map[string][]byte
+	expiredEndpoint string
+}
+
+func (r *recordingPushSender) Send(ctx context.Context, sub *models.PushSubscription, payload []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sent[sub.Endpoint] = payload
+	if sub.Endpoint == r.expiredEndpoint {
+		return ErrPushSubscriptionExpired
+	}
+	return nil
+}
+
+func TestPushSentOnlyWhenUserOffline(t *testing.T) {
+	service, db, cleanup := setupNotificationTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	pushRepo := models.NewPushSubscriptionRepository(db.Pool)
+	sender := &recordingPushSender{sent: map[string][]byte{}}
+	service.SetPushSender(sender, pushRepo)
+
+	offlineID := createTestUser(t, db, uniqueNotificationName("push_offline"))
+	onlineID := createTestUser(t, db, uniqueNotificationName("push_online"))
+
+	endpoint := func(name string) string {
+		return "https://push.example.com/" + uniqueNotificationName(name)
+	}
+	offlineEndpoint := endpoint("offline")
+	expiredEndpoint := endpoint("expired")
+	onlineEndpoint := endpoint("online")
+	sender.expiredEndpoint = expiredEndpoint
+	for userID, endpoints := range map[int][]string{offlineID: {offlineEndpoint, expiredEndpoint}, onlineID: {onlineEndpoint}} {
+		for _, e := range endpoints {
+			require.NoError(t, pushRepo.Subscribe(ctx, &models.PushSubscription{UserID: userID, Endpoint: e, P256dh: "key", Auth: "secret"}))
+		}
+	}
+
+	go service.hub.Run()
+	service.hub.Register(&websocket.Client{Hub: service.hub, UserID: onlineID, Send: make(chan *websocket.Message, 16)})
+	require.Eventually(t, func() bool { return service.hub.IsUserOnline(onlineID) }, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, service.NotifyReportResolved(ctx, offlineID, "reviewed"))
+	require.NoError(t, service.NotifyReportResolved(ctx, onlineID, "reviewed"))
+	service.pushWG.Wait()
+
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+	assert.Len(t, sender.sent, 2)
+	assert.Contains(t, sender.sent, offlineEndpoint)
+	assert.Contains(t, sender.sent, expiredEndpoint)
+	assert.NotContains(t, sender.sent, onlineEndpoint, "online users get the websocket message instead")
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(sender.sent[offlineEndpoint], &payload))
+	assert.Equal(t, "report_action_taken", payload["notification_type"])
+
+	// Subscriptions the push service reports as gone are dropped
+	subs, err := pushRepo.GetByUserID(ctx, offlineID)
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Equal(t, offlineEndpoint, subs[0].Endpoint)
+}
+
+// blockingPushSender hangs until released, like an unresponsive push service
+type blockingPushSender struct {
+	release chan struct{}
+	ctxErr  chan error
+}
+
+func (b *blockingPushSender) Send(ctx context.Context, sub *models.PushSubscription, payload []byte) error {
+	<-b.release
+	b.ctxErr <- ctx.Err()
+	return nil
+}
+
+func TestPushDoesNotBlockNotification(t *testing.T) {
+	service, db, cleanup := setupNotificationTest(t)
+	defer cleanup()
+
+	pushRepo := models.NewPushSubscriptionRepository(db.Pool)
+	sender := &blockingPushSender{release: make(chan struct{}), ctxErr: make(chan error, 1)}
+	service.SetPushSender(sender, pushRepo)
+
+	userID := createTestUser(t, db, uniqueNotificationName("push_slow"))
+	require.NoError(t, pushRepo.Subscribe(context.Background(), &models.PushSubscription{
+		UserID: userID, Endpoint: "https://push.example.com/" + uniqueNotificationName("slow"), P256dh: "key", Auth: "secret",
+	}))
+
+	// The notification is stored and returned while the push is still hanging,
+	// and cancelling the caller's context doesn't cancel the delivery
+	ctx, cancel := context.WithCancel(context.Background())
+	require.NoError(t, service.NotifyReportResolved(ctx, userID, "reviewed"))
+	cancel()
+
+	close(sender.release)
+	service.pushWG.Wait()
+	assert.NoError(t, <-sender.ctxErr)
+}
+
+func TestMutedCategorySuppressesOnlyThatCategory(t *testing.T) {
+	service, db, cleanup := setupNotificationTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	authorID := createTestUser(t, db, uniqueNotificationName("muted_author"))
+	replierID := createTestUser(t, db, uniqueNotificationName("muted_replier"))
+	creatorID := createTestUser(t, db, uniqueNotificationName("creator"))
+	hubID := createTestHub(t, db, uniqueNotificationName("test_hub"), creatorID)
+	postID := createTestPost(t, db, authorID, hubID)
+	parentID := createTestComment(t, db, postID, authorID, nil)
+
+	settingsRepo := models.NewUserSettingsRepository(db.Pool)
+	settings, err := settingsRepo.CreateDefault(ctx, authorID)
+	require.NoError(t, err)
+	settings.NotifyPostMilestone = true
+	settings.NotifyCommentReplies = true
+	_, err = settingsRepo.Update(ctx, settings)
+	require.NoError(t, err)
+
+	prefs, err := settingsRepo.UpdateNotificationPreferences(ctx, authorID, models.NotificationPreferences{models.NotificationCategoryVote: false})
+	require.NoError(t, err)
+	assert.Equal(t, models.NotificationPreferences{"reply": true, "mention": true, "mod_action": true, "vote": false}, prefs)
+
+	require.NoError(t, service.CheckAndNotifyVote(ctx, "post", postID, authorID, 10))
+	replyID := createTestComment(t, db, postID, replierID, &parentID)
+	require.NoError(t, service.NotifyCommentReply(ctx, replyID, authorID, replierID))
+
+	notifs, err := models.NewNotificationRepository(db.Pool).GetByUserID(ctx, authorID, 10, 0, false)
+	require.NoError(t, err)
+	require.Len(t, notifs, 1, "the milestone is muted but the reply still arrives")
+	assert.Equal(t, "comment_reply", notifs[0].NotificationType)
+}
@@ -0,0 +1,269 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/omninudge/backend/internal/models"
+)
+
+// ErrPushSubscriptionExpired is returned by a PushSender when the push service
+// reports the subscription is gone (HTTP 404/410); the subscription is then deleted.
+var ErrPushSubscriptionExpired = errors.New("push subscription expired")
+
+// ErrPushEndpointNotAllowed is returned for endpoints outside the known browser push services
+var ErrPushEndpointNotAllowed = errors.New("push endpoint is not a known push service")
+
+// pushServiceHosts are the browser push services a subscription may point at;
+// a leading dot matches any subdomain. Anything else could make the server
+// POST to internal or cloud metadata addresses on a user's behalf.
+var pushServiceHosts = []string{
+	"fcm.googleapis.com",                // Chrome, Chromium Edge, Opera, Brave
+	"android.googleapis.com",            // Legacy GCM endpoints
+	"updates.push.services.mozilla.com", // Firefox
+	".push.apple.com",                   // Safari
+	".notify.windows.com",               // Legacy Edge
+}
+
+// IsAllowedPushEndpoint reports whether endpoint is an https URL on a known
+// push service, on the default port and without credentials
+func IsAllowedPushEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme != "https" || u.User != nil {
+		return false
+	}
+	if port := u.Port(); port != "" && port != "443" {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, allowed := range pushServiceHosts {
+		if host == allowed || (strings.HasPrefix(allowed, ".") && strings.HasSuffix(host, allowed)) {
+			return true
+		}
+	}
+	return false
+}
+
+// PushSender delivers an encrypted web push message to one subscription.
+// VAPIDPushSender is the real implementation; NoopPushSender is used when no
+// VAPID key is configured.
+type PushSender interface {
+	Send(ctx context.Context, sub *models.PushSubscription, payload []byte) error
+}
+
+// NoopPushSender discards every message.
+type NoopPushSender struct{}
+
+// Send implements PushSender.
+func (NoopPushSender) Send(ctx context.Context, sub *models.PushSubscription, payload []byte) error {
+	return nil
+}
+
+const (
+	// pushRecordSize is the aes128gcm record size; the whole message is one record
+	pushRecordSize = 4096
+	// maxPushPayload leaves room in the record for the padding delimiter and GCM tag
+	maxPushPayload = pushRecordSize - 17
+	// pushTTL is how long the push service holds a message for an unreachable browser
+	pushTTL = 24 * time.Hour
+	// vapidTokenLifetime is the lifetime of the VAPID JWT; push services reject anything over 24h
+	vapidTokenLifetime = 12 * time.Hour
+)
+
+// VAPIDPushSender delivers web push messages encrypted per RFC 8291 and
+// authenticated with VAPID (RFC 8292)
+type VAPIDPushSender struct {
+	privateKey *ecdsa.PrivateKey
+	// publicKey is the base64url uncompressed public key browsers subscribe with
+	publicKey string
+	subject   string
+	client    *http.Client
+	now       func() time.Time
+	// allowEndpoint guards every send; tests swap it to reach a local server
+	allowEndpoint func(endpoint string) bool
+}
+
+// NewVAPIDPushSender creates a sender from a base64url-encoded raw P-256
+// private key, as printed by `web-push generate-vapid-keys`. subject is a
+// mailto: or https: contact URL push services can use to reach the operator.
+func NewVAPIDPushSender(privateKey, subject string) (*VAPIDPushSender, error) {
+	raw, err := decodePushKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	key, err := ecdsa.ParseRawPrivateKey(elliptic.P256(), raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	public, err := key.PublicKey.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid VAPID private key: %w", err)
+	}
+	if !strings.HasPrefix(subject, "mailto:") && !strings.HasPrefix(subject, "https:") {
+		return nil, fmt.Errorf("VAPID subject must be a mailto: or https: URL")
+	}
+
+	return &VAPIDPushSender{
+		privateKey: key,
+		publicKey:  base64.RawURLEncoding.EncodeToString(public),
+		subject:    subject,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			// A redirect could point anywhere, so the push service's answer is final
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+		now:           time.Now,
+		allowEndpoint: IsAllowedPushEndpoint,
+	}, nil
+}
+
+// PublicKey returns the base64url application server key browsers pass to
+// pushManager.subscribe
+func (s *VAPIDPushSender) PublicKey() string {
+	return s.publicKey
+}
+
+// Send implements PushSender. Endpoints are checked against the push service
+// allowlist again here, since subscriptions may predate it.
+func (s *VAPIDPushSender) Send(ctx context.Context, sub *models.PushSubscription, payload []byte) error {
+	if !s.allowEndpoint(sub.Endpoint) {
+		return ErrPushEndpointNotAllowed
+	}
+	endpoint, err := url.Parse(sub.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return fmt.Errorf("invalid push endpoint %q", sub.Endpoint)
+	}
+
+	body, err := encryptPushPayload(sub, payload)
+	if err != nil {
+		return err
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"aud": endpoint.Scheme + "://" + endpoint.Host,
+		"exp": s.now().Add(vapidTokenLifetime).Unix(),
+		"sub": s.subject,
+	}).SignedString(s.privateKey)
+	if err != nil {
+		return fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "vapid t="+token+", k="+s.publicKey)
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(pushTTL.Seconds())))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return ErrPushSubscriptionExpired
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("push service returned %s", resp.Status)
+	}
+	return nil
+}
+
+// encryptPushPayload encrypts payload for sub as a single aes128gcm record (RFC 8291)
+func encryptPushPayload(sub *models.PushSubscription, payload []byte) ([]byte, error) {
+	if len(payload) > maxPushPayload {
+		return nil, fmt.Errorf("push payload is %d bytes; the limit is %d", len(payload), maxPushPayload)
+	}
+
+	uaPublicRaw, err := decodePushKey(sub.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	uaPublic, err := ecdh.P256().NewPublicKey(uaPublicRaw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodePushKey(sub.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	asPrivate, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	asPublic := asPrivate.PublicKey().Bytes()
+	sharedSecret, err := asPrivate.ECDH(uaPublic)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	keyInfo := "WebPush: info\x00" + string(uaPublicRaw) + string(asPublic)
+	ikm, err := hkdf.Key(sha256.New, sharedSecret, authSecret, keyInfo, 32)
+	if err != nil {
+		return nil, err
+	}
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key ID length and the sender's public key
+	header := make([]byte, 0, 16+4+1+len(asPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, pushRecordSize)
+	header = append(header, byte(len(asPublic)))
+	header = append(header, asPublic...)
+
+	// 0x02 marks the last (and only) record
+	plaintext := append(append([]byte{}, payload...), 0x02)
+	return gcm.Seal(header, nonce, plaintext, nil), nil
+}
+
+// decodePushKey decodes base64url keys, with or without padding
+func decodePushKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
@@ -7,11 +7,14 @@ import (
 	"fmt"
 	"html"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
@@ -19,7 +22,9 @@ import (
 
 // RedditClient handles interactions with Reddit's public JSON API
 type RedditClient struct {
-	userAgent    string
+	// userAgents is rotated per request; it usually holds a single entry
+	userAgents   []string
+	uaIndex      uint32
 	httpClient   *http.Client
 	cache        Cache
 	cacheTTL     time.Duration
@@ -27,6 +32,168 @@ type RedditClient struct {
 	clientSecret string
 	tokenMu      sync.Mutex
 	appToken     *redditAppToken
+	retry        RedditRetryPolicy
+	rateMu       sync.Mutex
+	rateLimit    RedditRateLimit
+	// staleWindow keeps listings in the cache this long past their TTL so they
+	// can be served when Reddit fails. Zero disables the fallback.
+	staleWindow time.Duration
+	// userTokens holds OAuth tokens from users who linked Reddit, keyed by user ID
+	userTokenMu sync.Mutex
+	userTokens  map[int]redditAppToken
+	// filterNSFW drops over_18 posts from listings unless a call overrides it
+	filterNSFW bool
+	// negativeTTL is how long a post Reddit no longer returns is remembered as
+	// missing before it is looked up again. Zero disables negative caching.
+	negativeTTL time.Duration
+}
+
+// RedditRateLimit is the rate-limit budget Reddit reported on its most recent
+// response. Remaining is fractional because Reddit reports it that way.
+type RedditRateLimit struct {
+	Remaining    float64   `json:"remaining"`
+	Used         int       `json:"used"`
+	ResetSeconds int       `json:"reset_seconds"`
+	Timestamp    time.Time `json:"timestamp"`
+}
+
+// RedditRetryPolicy controls how requests that Reddit rate limits (429) or
+// fails (5xx) are retried. Delays double from BaseDelay on each attempt; a
+// Retry-After header overrides the computed delay. A response asking for a
+// longer wait than MaxDelay is returned as is.
+type RedditRetryPolicy struct {
+	MaxRetries int
+	BaseDelay  time.Duration
+	MaxDelay   time.Duration
+}
+
+// DefaultRedditRetryPolicy retries three times, waiting 0.5s, 1s and 2s.
+func DefaultRedditRetryPolicy() RedditRetryPolicy {
+	return RedditRetryPolicy{MaxRetries: 3, BaseDelay: 500 * time.Millisecond, MaxDelay: 10 * time.Second}
+}
+
+// RedditHTTPOptions tunes the HTTP client used to reach Reddit. Timeout bounds
+// a whole request, DialTimeout only the TCP connect, and MaxIdleConnsPerHost
+// how many keep-alive connections to each Reddit host are pooled.
+type RedditHTTPOptions struct {
+	Timeout             time.Duration
+	DialTimeout         time.Duration
+	MaxIdleConnsPerHost int
+}
+
+// DefaultRedditHTTPOptions matches Go's default transport with a 10s timeout.
+func DefaultRedditHTTPOptions() RedditHTTPOptions {
+	return RedditHTTPOptions{
+		Timeout:             10 * time.Second,
+		DialTimeout:         30 * time.Second,
+		MaxIdleConnsPerHost: http.DefaultMaxIdleConnsPerHost,
+	}
+}
+
+// WithRedditHTTPOptions replaces the HTTP client built from
+// DefaultRedditHTTPOptions. Zero fields keep their defaults.
+func WithRedditHTTPOptions(opts RedditHTTPOptions) RedditClientOption {
+	defaults := DefaultRedditHTTPOptions()
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaults.Timeout
+	}
+	if opts.DialTimeout <= 0 {
+		opts.DialTimeout = defaults.DialTimeout
+	}
+	if opts.MaxIdleConnsPerHost <= 0 {
+		opts.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+	return func(r *RedditClient) {
+		r.httpClient = newRedditHTTPClient(opts)
+	}
+}
+
+func newRedditHTTPClient(opts RedditHTTPOptions) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = (&net.Dialer{
+		Timeout:   opts.DialTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.MaxIdleConnsPerHost = opts.MaxIdleConnsPerHost
+	return &http.Client{
+		Timeout:   opts.Timeout,
+		Transport: transport,
+	}
+}
+
+// RedditClientOption customizes a RedditClient at construction time.
+type RedditClientOption func(*RedditClient)
+
+// WithRedditRetryPolicy replaces the default retry policy. Tests pass a zero
+// policy to disable retries.
+func WithRedditRetryPolicy(policy RedditRetryPolicy) RedditClientOption {
+	return func(r *RedditClient) {
+		r.retry = policy
+	}
+}
+
+// WithRedditStaleFallback lets GetSubredditPosts serve a listing up to window
+// past its cache TTL when the fresh fetch fails.
+func WithRedditStaleFallback(window time.Duration) RedditClientOption {
+	return func(r *RedditClient) {
+		if window > 0 {
+			r.staleWindow = window
+		}
+	}
+}
+
+// WithRedditNSFWFilter makes post listings drop over_18 posts by default.
+// WithNSFWFilter overrides the setting for a single call.
+func WithRedditNSFWFilter(enabled bool) RedditClientOption {
+	return func(r *RedditClient) {
+		r.filterNSFW = enabled
+	}
+}
+
+// defaultRedditNegativeTTL keeps removed-post lookups from repeating on every
+// saved-items load while still noticing restored posts within minutes.
+const defaultRedditNegativeTTL = 2 * time.Minute
+
+// WithRedditNegativeCacheTTL sets how long missing posts are remembered.
+// A zero or negative ttl disables negative caching.
+func WithRedditNegativeCacheTTL(ttl time.Duration) RedditClientOption {
+	return func(r *RedditClient) {
+		if ttl < 0 {
+			ttl = 0
+		}
+		r.negativeTTL = ttl
+	}
+}
+
+type nsfwFilterKey struct{}
+
+// WithNSFWFilter overrides the client's NSFW filter for Reddit calls made with
+// the returned context.
+func WithNSFWFilter(ctx context.Context, filter bool) context.Context {
+	return context.WithValue(ctx, nsfwFilterKey{}, filter)
+}
+
+func (r *RedditClient) shouldFilterNSFW(ctx context.Context) bool {
+	if filter, ok := ctx.Value(nsfwFilterKey{}).(bool); ok {
+		return filter
+	}
+	return r.filterNSFW
+}
+
+// filterListingNSFW returns listing without over_18 posts when filtering is on.
+// The filtered listing is a copy so cached listings keep every post.
+func (r *RedditClient) filterListingNSFW(ctx context.Context, listing *RedditListing) *RedditListing {
+	if listing == nil || !r.shouldFilterNSFW(ctx) {
+		return listing
+	}
+	filtered := *listing
+	filtered.Data.Children = filtered.Data.Children[:0:0]
+	for _, child := range listing.Data.Children {
+		if !child.Data.Over18 {
+			filtered.Data.Children = append(filtered.Data.Children, child)
+		}
+	}
+	return &filtered
 }
 
 type redditAppToken struct {
@@ -37,9 +204,45 @@ type redditAppToken struct {
 // ErrRedditModeratorsUnavailable indicates Reddit refused to return the moderators list.
 var ErrRedditModeratorsUnavailable = errors.New("reddit moderators list unavailable without authentication")
 
+// ErrRedditFlairsUnavailable indicates Reddit refused to return the link flair list.
+var ErrRedditFlairsUnavailable = errors.New("reddit link flairs unavailable without authentication")
+
 // ErrRedditNotFound indicates the requested Reddit resource was not found.
 var ErrRedditNotFound = errors.New("reddit resource not found")
 
+// ErrRedditForbiddenPrivate indicates the subreddit is private and needs an invite to view.
+var ErrRedditForbiddenPrivate = errors.New("reddit subreddit is private")
+
+// ErrRedditBanned indicates Reddit has banned the subreddit.
+var ErrRedditBanned = errors.New("reddit subreddit is banned")
+
+// subredditAccessError maps Reddit's refusal body ({"reason": "private"} and
+// friends) to a sentinel. It returns nil for any other response.
+func subredditAccessError(statusCode int, body []byte) error {
+	if statusCode != http.StatusForbidden && statusCode != http.StatusNotFound {
+		return nil
+	}
+	var payload struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil
+	}
+	switch payload.Reason {
+	case "private":
+		return ErrRedditForbiddenPrivate
+	case "banned":
+		return ErrRedditBanned
+	}
+	return nil
+}
+
+// ErrRedditInvalidLink indicates a URL that is not a link to a Reddit post.
+var ErrRedditInvalidLink = errors.New("not a reddit post link")
+
+// ErrRedditConflictingCursors indicates both after and before were given for one page.
+var ErrRedditConflictingCursors = errors.New("after and before cannot both be set")
+
 type redditHTTPError struct {
 	statusCode int
 	body       string
@@ -52,26 +255,196 @@ func (e *redditHTTPError) Error() string {
 	return fmt.Sprintf("reddit responded with status %d: %s", e.statusCode, strings.TrimSpace(e.body))
 }
 
-// NewRedditClient creates a new Reddit client
-func NewRedditClient(userAgent string, cache Cache, cacheTTL time.Duration, clientID, clientSecret string) *RedditClient {
+// ErrInvalidRedditUserAgent indicates a User-Agent Reddit would throttle or reject.
+var ErrInvalidRedditUserAgent = errors.New("invalid reddit user agent")
+
+// genericUserAgentMarkers appear in browser and HTTP library default User-Agents,
+// which Reddit throttles aggressively.
+var genericUserAgentMarkers = []string{
+	"mozilla/", "applewebkit", "chrome/", "safari/", "firefox/", "gecko/",
+	"go-http-client", "python-requests", "python-urllib", "curl/", "wget/",
+	"okhttp", "axios", "node-fetch",
+}
+
+// ValidateRedditUserAgent checks that ua is a descriptive, app-specific User-Agent
+// rather than empty or a browser/library default.
+// Reddit's recommended format is "<platform>:<app id>:<version> (by /u/<username>)".
+func ValidateRedditUserAgent(ua string) error {
+	trimmed := strings.TrimSpace(ua)
+	if trimmed == "" {
+		return fmt.Errorf("%w: user agent is empty", ErrInvalidRedditUserAgent)
+	}
+	lower := strings.ToLower(trimmed)
+	for _, marker := range genericUserAgentMarkers {
+		if strings.Contains(lower, marker) {
+			return fmt.Errorf("%w: %q looks like a browser or library default", ErrInvalidRedditUserAgent, trimmed)
+		}
+	}
+	return nil
+}
+
+// NewRedditClientWithUserAgents creates a Reddit client that rotates through
+// userAgents, one per request, to spread load across compliant identifiers.
+// Every entry must pass ValidateRedditUserAgent and, for pools of more than one,
+// name a contact as in "(by /u/username)" so each still identifies the app owner.
+func NewRedditClientWithUserAgents(userAgents []string, cache Cache, cacheTTL time.Duration, clientID, clientSecret string, opts ...RedditClientOption) (*RedditClient, error) {
+	if len(userAgents) == 0 {
+		return nil, fmt.Errorf("%w: no user agents configured", ErrInvalidRedditUserAgent)
+	}
+	agents := make([]string, 0, len(userAgents))
+	for _, ua := range userAgents {
+		if err := ValidateRedditUserAgent(ua); err != nil {
+			return nil, err
+		}
+		ua = strings.TrimSpace(ua)
+		if len(userAgents) > 1 && !strings.Contains(strings.ToLower(ua), "(by ") {
+			return nil, fmt.Errorf("%w: rotated user agent %q must include a contact such as (by /u/username)", ErrInvalidRedditUserAgent, ua)
+		}
+		agents = append(agents, ua)
+	}
+
+	return newRedditClient(agents, cache, cacheTTL, clientID, clientSecret, opts...), nil
+}
+
+// NewRedditClient creates a Reddit client with a single User-Agent, which must
+// pass ValidateRedditUserAgent.
+func NewRedditClient(userAgent string, cache Cache, cacheTTL time.Duration, clientID, clientSecret string, opts ...RedditClientOption) (*RedditClient, error) {
+	return NewRedditClientWithUserAgents([]string{userAgent}, cache, cacheTTL, clientID, clientSecret, opts...)
+}
+
+func newRedditClient(userAgents []string, cache Cache, cacheTTL time.Duration, clientID, clientSecret string, opts ...RedditClientOption) *RedditClient {
 	if cache == nil {
 		cache = NoopCache{}
 	}
 	if cacheTTL <= 0 {
 		cacheTTL = 5 * time.Minute
 	}
-	return &RedditClient{
-		userAgent: userAgent,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+	client := &RedditClient{
+		userAgents:   userAgents,
+		httpClient:   newRedditHTTPClient(DefaultRedditHTTPOptions()),
 		cache:        cache,
 		cacheTTL:     cacheTTL,
 		clientID:     clientID,
 		clientSecret: clientSecret,
+		retry:        DefaultRedditRetryPolicy(),
+		negativeTTL:  defaultRedditNegativeTTL,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client
+}
+
+// doWithRetry sends req, retrying 429 and 5xx responses with exponential
+// backoff. Transport errors are returned straight away. The last response is
+// returned unchanged once retries run out.
+func (r *RedditClient) doWithRetry(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := r.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		r.recordRateLimit(resp.Header)
+		if !isRetryableRedditStatus(resp.StatusCode) || attempt >= r.retry.MaxRetries {
+			return resp, nil
+		}
+
+		delay := r.retry.BaseDelay << attempt
+		if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			delay = retryAfter
+		}
+		if r.retry.MaxDelay > 0 && delay > r.retry.MaxDelay {
+			return resp, nil
+		}
+
+		// A body-bearing request can only be resent if it can be rewound
+		next := req.Clone(req.Context())
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, nil
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return resp, nil
+			}
+			next.Body = body
+		}
+
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-req.Context().Done():
+				timer.Stop()
+				return nil, req.Context().Err()
+			case <-timer.C:
+			}
+		}
+		req = next
 	}
 }
 
+// LastRateLimit returns the rate-limit headers from the most recent Reddit
+// response. The zero value means no response has reported them yet.
+func (r *RedditClient) LastRateLimit() RedditRateLimit {
+	r.rateMu.Lock()
+	defer r.rateMu.Unlock()
+	return r.rateLimit
+}
+
+// recordRateLimit stores Reddit's x-ratelimit-* headers. Responses without
+// them (e.g. some error pages) leave the previous values in place.
+func (r *RedditClient) recordRateLimit(header http.Header) {
+	remaining := header.Get("X-Ratelimit-Remaining")
+	used := header.Get("X-Ratelimit-Used")
+	reset := header.Get("X-Ratelimit-Reset")
+	if remaining == "" && used == "" && reset == "" {
+		return
+	}
+
+	limit := RedditRateLimit{Timestamp: time.Now()}
+	limit.Remaining, _ = strconv.ParseFloat(strings.TrimSpace(remaining), 64)
+	limit.Used, _ = strconv.Atoi(strings.TrimSpace(used))
+	limit.ResetSeconds, _ = strconv.Atoi(strings.TrimSpace(reset))
+
+	r.rateMu.Lock()
+	r.rateLimit = limit
+	r.rateMu.Unlock()
+}
+
+func isRetryableRedditStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if wait := at.Sub(now); wait > 0 {
+			return wait, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// nextUserAgent returns the User-Agent for the next request, cycling through the pool.
+func (r *RedditClient) nextUserAgent() string {
+	if len(r.userAgents) == 1 {
+		return r.userAgents[0]
+	}
+	i := atomic.AddUint32(&r.uaIndex, 1) - 1
+	return r.userAgents[int(i%uint32(len(r.userAgents)))]
+}
+
 // HTTPClientForTest exposes the underlying HTTP client for test overrides.
 func (r *RedditClient) HTTPClientForTest() *http.Client {
 	return r.httpClient
@@ -89,35 +462,101 @@ func normalizeRemovedIndicator(value string) string {
 
 // RedditPost represents a post from Reddit's API
 type RedditPost struct {
-	ID                       string         `json:"id"`
-	Subreddit                string         `json:"subreddit"`
-	Title                    string         `json:"title"`
-	Author                   string         `json:"author"`
-	RemovedByCategory        string         `json:"removed_by_category"`
-	RemovedBy                *string        `json:"removed_by"`
-	BannedBy                 *string        `json:"banned_by"`
-	Selftext                 string         `json:"selftext"`     // Post body text
-	URL                      string         `json:"url"`          // Link or media URL
-	Permalink                string         `json:"permalink"`    // Reddit URL
-	Thumbnail                string         `json:"thumbnail"`    // Thumbnail URL
-	Score                    int            `json:"score"`        // Upvotes - downvotes
-	NumComments              int            `json:"num_comments"` // Comment count
-	CreatedUTC               float64        `json:"created_utc"`  // Unix timestamp
-	Over18                   bool           `json:"over_18"`      // NSFW flag
-	PostHint                 string         `json:"post_hint"`    // Type hint: image, video, link, etc.
-	IsVideo                  bool           `json:"is_video"`     // Is it a video
-	IsSelf                   bool           `json:"is_self"`      // Is it a text post
-	LinkFlairText            string         `json:"link_flair_text"`
-	LinkFlairBackgroundColor string         `json:"link_flair_background_color"`
-	LinkFlairTextColor       string         `json:"link_flair_text_color"`
-	Distinguished            *string        `json:"distinguished"` // Mod/admin flag
-	Stickied                 bool           `json:"stickied"`      // Pinned post
-	Domain                   string         `json:"domain"`        // Source domain
-	MediaEmbed               MediaEmbed     `json:"media_embed"`   // Embedded media
-	SecureMediaEmbed         MediaEmbed     `json:"secure_media_embed"`
-	Media                    *RedditMedia   `json:"media"`        // Media container
-	SecureMedia              *RedditMedia   `json:"secure_media"` // Secure media container
-	Preview                  *RedditPreview `json:"preview"`      // Preview images for link posts
+	ID                       string                         `json:"id"`
+	Subreddit                string                         `json:"subreddit"`
+	Title                    string                         `json:"title"`
+	Author                   string                         `json:"author"`
+	RemovedByCategory        string                         `json:"removed_by_category"`
+	RemovedBy                *string                        `json:"removed_by"`
+	BannedBy                 *string                        `json:"banned_by"`
+	Quarantine               bool                           `json:"quarantine"`
+	AuthorIsBlocked          bool                           `json:"author_is_blocked"`
+	Selftext                 string                         `json:"selftext"`     // Post body text
+	URL                      string                         `json:"url"`          // Link or media URL
+	Permalink                string                         `json:"permalink"`    // Reddit URL
+	Thumbnail                string                         `json:"thumbnail"`    // Thumbnail URL
+	Score                    int                            `json:"score"`        // Upvotes - downvotes
+	NumComments              int                            `json:"num_comments"` // Comment count
+	CreatedUTC               float64                        `json:"created_utc"`  // Unix timestamp
+	Over18                   bool                           `json:"over_18"`      // NSFW flag
+	PostHint                 string                         `json:"post_hint"`    // Type hint: image, video, link, etc.
+	IsVideo                  bool                           `json:"is_video"`     // Is it a video
+	IsSelf                   bool                           `json:"is_self"`      // Is it a text post
+	LinkFlairText            string                         `json:"link_flair_text"`
+	LinkFlairBackgroundColor string                         `json:"link_flair_background_color"`
+	LinkFlairTextColor       string                         `json:"link_flair_text_color"`
+	Distinguished            *string                        `json:"distinguished"` // Mod/admin flag
+	Stickied                 bool                           `json:"stickied"`      // Pinned post
+	Domain                   string                         `json:"domain"`        // Source domain
+	MediaEmbed               MediaEmbed                     `json:"media_embed"`   // Embedded media
+	SecureMediaEmbed         MediaEmbed                     `json:"secure_media_embed"`
+	Media                    *RedditMedia                   `json:"media"`                    // Media container
+	SecureMedia              *RedditMedia                   `json:"secure_media"`             // Secure media container
+	Preview                  *RedditPreview                 `json:"preview"`                  // Preview images for link posts
+	SuggestedSort            string                         `json:"suggested_sort,omitempty"` // Comment sort chosen by the subreddit or author
+	GalleryData              *RedditGalleryData             `json:"gallery_data,omitempty"`   // Ordered gallery items
+	MediaMetadata            map[string]RedditMediaMetadata `json:"media_metadata,omitempty"` // Gallery assets keyed by media ID
+}
+
+// GalleryImages resolves a gallery post's items, in gallery order, into image
+// sources. Items whose media failed to process or is missing are skipped.
+func (p RedditPost) GalleryImages() []RedditImageSource {
+	if p.GalleryData == nil || len(p.MediaMetadata) == 0 {
+		return nil
+	}
+	images := make([]RedditImageSource, 0, len(p.GalleryData.Items))
+	for _, item := range p.GalleryData.Items {
+		meta, ok := p.MediaMetadata[item.MediaID]
+		if !ok || (meta.Status != "" && meta.Status != "valid") {
+			continue
+		}
+		// Animated items carry gif/mp4 renditions instead of a still URL
+		src := meta.Source.URL
+		if src == "" {
+			src = meta.Source.GIF
+		}
+		if src == "" {
+			continue
+		}
+		images = append(images, RedditImageSource{
+			URL:    html.UnescapeString(src),
+			Width:  meta.Source.Width,
+			Height: meta.Source.Height,
+		})
+	}
+	return images
+}
+
+// RedditGalleryData lists the items of a gallery post in display order
+type RedditGalleryData struct {
+	Items []RedditGalleryItem `json:"items"`
+}
+
+// RedditGalleryItem references one media_metadata entry of a gallery
+type RedditGalleryItem struct {
+	MediaID     string `json:"media_id"`
+	ID          int64  `json:"id"`
+	Caption     string `json:"caption,omitempty"`
+	OutboundURL string `json:"outbound_url,omitempty"`
+}
+
+// RedditMediaMetadata describes an uploaded gallery asset. Reddit uses
+// single-letter keys: e is the kind (Image, AnimatedImage), m the MIME type.
+type RedditMediaMetadata struct {
+	Status   string                      `json:"status"`
+	Kind     string                      `json:"e"`
+	MIMEType string                      `json:"m"`
+	Source   RedditMediaMetadataSource   `json:"s"`
+	Previews []RedditMediaMetadataSource `json:"p"`
+}
+
+// RedditMediaMetadataSource is one rendition of a gallery asset
+type RedditMediaMetadataSource struct {
+	URL    string `json:"u,omitempty"`
+	GIF    string `json:"gif,omitempty"`
+	MP4    string `json:"mp4,omitempty"`
+	Width  int    `json:"x"`
+	Height int    `json:"y"`
 }
 
 // MediaEmbed represents embedded media from Reddit
@@ -182,6 +621,9 @@ type RedditListing struct {
 			Data RedditPost `json:"data"`
 		} `json:"children"`
 	} `json:"data"`
+	// Stale is set when the listing came from an expired cache entry because
+	// Reddit could not be reached.
+	Stale bool `json:"-"`
 }
 
 // redditGenericListing models generic Reddit listing responses that may include posts or comments
@@ -270,6 +712,17 @@ type RedditSubredditAbout struct {
 	CreatedUTC          float64 `json:"created_utc"`
 }
 
+// RedditSubredditRule represents a single community rule for a subreddit
+type RedditSubredditRule struct {
+	ShortName       string  `json:"short_name"`
+	Description     string  `json:"description"`
+	DescriptionHTML string  `json:"description_html"`
+	ViolationReason string  `json:"violation_reason"`
+	Kind            string  `json:"kind"` // "link", "comment" or "all"
+	Priority        int     `json:"priority"`
+	CreatedUTC      float64 `json:"created_utc"`
+}
+
 // RedditSubredditModerator represents a single moderator entry for a subreddit
 type RedditSubredditModerator struct {
 	ID              string   `json:"id"`
@@ -278,6 +731,14 @@ type RedditSubredditModerator struct {
 	ModPermissions  []string `json:"mod_permissions"`
 }
 
+// RedditFlair is a post (link) flair template a subreddit offers to submitters
+type RedditFlair struct {
+	ID              string `json:"id"`
+	Text            string `json:"text"`
+	BackgroundColor string `json:"background_color"`
+	TextColor       string `json:"text_color"`
+}
+
 // RedditWikiAuthor captures author details on wiki revision entries.
 type RedditWikiAuthor struct {
 	Kind string               `json:"kind"`
@@ -352,13 +813,32 @@ type subredditSearchListing struct {
 	} `json:"data"`
 }
 
-// GetSubredditPosts fetches posts from a subreddit
-func (r *RedditClient) GetSubredditPosts(ctx context.Context, subreddit string, sort string, timeFilter string, limit int, after string) (*RedditListing, error) {
-	cacheKey := fmt.Sprintf("sr:%s:%s:%s:%d:%s", subreddit, sort, timeFilter, limit, after)
-	if listing, ok, err := r.getCachedListing(ctx, cacheKey); err == nil && ok {
-		return listing, nil
+// GetSubredditPosts fetches posts from a subreddit. When the stale fallback is
+// enabled and Reddit fails, an expired cached listing is returned with Stale set.
+func (r *RedditClient) GetSubredditPosts(ctx context.Context, subreddit string, sort string, timeFilter string, limit int, after, before string) (*RedditListing, error) {
+	cacheKey := fmt.Sprintf("sr:%s:%s:%s:%d:%s:%s", subreddit, sort, timeFilter, limit, after, before)
+	if listing, ok, err := r.getCachedListing(ctx, cacheKey, false); err == nil && ok {
+		return r.filterListingNSFW(ctx, listing), nil
 	}
 
+	listing, err := r.fetchSubredditPosts(ctx, subreddit, sort, timeFilter, limit, after, before)
+	if err != nil {
+		// Private and banned are Reddit's actual answer, not an outage
+		if r.staleWindow > 0 && !errors.Is(err, context.Canceled) &&
+			!errors.Is(err, ErrRedditForbiddenPrivate) && !errors.Is(err, ErrRedditBanned) {
+			// The request context may be what failed, so look up the stale copy without it
+			if stale, ok, cacheErr := r.getCachedListing(context.WithoutCancel(ctx), cacheKey, true); cacheErr == nil && ok {
+				return r.filterListingNSFW(ctx, stale), nil
+			}
+		}
+		return nil, err
+	}
+
+	_ = r.setCachedListing(ctx, cacheKey, *listing)
+	return r.filterListingNSFW(ctx, listing), nil
+}
+
+func (r *RedditClient) fetchSubredditPosts(ctx context.Context, subreddit string, sort string, timeFilter string, limit int, after, before string) (*RedditListing, error) {
 	// Build URL
 	url := fmt.Sprintf("https://www.reddit.com/r/%s/%s.json", subreddit, sort)
 
@@ -369,7 +849,7 @@ func (r *RedditClient) GetSubredditPosts(ctx context.Context, subreddit string,
 	}
 
 	// Set headers
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	// Add query parameters
 	q := req.URL.Query()
@@ -379,13 +859,16 @@ func (r *RedditClient) GetSubredditPosts(ctx context.Context, subreddit string,
 	if after != "" {
 		q.Add("after", after)
 	}
+	if before != "" {
+		q.Add("before", before) // Newer items, for "check for new" polling
+	}
 	if timeFilter != "" && (sort == "top" || sort == "controversial") {
 		q.Add("t", timeFilter) // hour, day, week, month, year, all
 	}
 	req.URL.RawQuery = q.Encode()
 
 	// Make request
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch subreddit: %w", err)
 	}
@@ -393,6 +876,9 @@ func (r *RedditClient) GetSubredditPosts(ctx context.Context, subreddit string,
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		if accessErr := subredditAccessError(resp.StatusCode, body); accessErr != nil {
+			return nil, accessErr
+		}
 		return nil, fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -402,15 +888,16 @@ func (r *RedditClient) GetSubredditPosts(ctx context.Context, subreddit string,
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	_ = r.setCachedListing(ctx, cacheKey, listing)
 	return &listing, nil
 }
 
-// GetFrontPage fetches posts from Reddit's front page
-func (r *RedditClient) GetFrontPage(ctx context.Context, sort string, timeFilter string, limit int, after string) (*RedditListing, error) {
-	cacheKey := fmt.Sprintf("fp:%s:%s:%d:%s", sort, timeFilter, limit, after)
-	if listing, ok, err := r.getCachedListing(ctx, cacheKey); err == nil && ok {
-		return listing, nil
+// GetFrontPage fetches posts from Reddit's front page. sort is used as the path
+// segment, so Reddit's default "best" ordering maps to /best.json alongside
+// hot, new, top, rising and controversial.
+func (r *RedditClient) GetFrontPage(ctx context.Context, sort string, timeFilter string, limit int, after, before string) (*RedditListing, error) {
+	cacheKey := fmt.Sprintf("fp:%s:%s:%d:%s:%s", sort, timeFilter, limit, after, before)
+	if listing, ok, err := r.getCachedListing(ctx, cacheKey, false); err == nil && ok {
+		return r.filterListingNSFW(ctx, listing), nil
 	}
 
 	// Build URL
@@ -423,7 +910,7 @@ func (r *RedditClient) GetFrontPage(ctx context.Context, sort string, timeFilter
 	}
 
 	// Set headers
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	// Add query parameters
 	q := req.URL.Query()
@@ -433,13 +920,16 @@ func (r *RedditClient) GetFrontPage(ctx context.Context, sort string, timeFilter
 	if after != "" {
 		q.Add("after", after)
 	}
+	if before != "" {
+		q.Add("before", before)
+	}
 	if timeFilter != "" && (sort == "top" || sort == "controversial") {
 		q.Add("t", timeFilter)
 	}
 	req.URL.RawQuery = q.Encode()
 
 	// Make request
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch front page: %w", err)
 	}
@@ -456,6 +946,128 @@ func (r *RedditClient) GetFrontPage(ctx context.Context, sort string, timeFilter
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	_ = r.setCachedListing(ctx, cacheKey, listing)
+	return r.filterListingNSFW(ctx, &listing), nil
+}
+
+// GetUserFrontPage fetches userID's personalized front page from
+// oauth.reddit.com using the token stored by SetUserToken. Without a user token
+// it reads the generic front page with the app-only token, or from the public
+// JSON API when no client credentials are configured. personalized reports
+// whether the user's own token was used.
+func (r *RedditClient) GetUserFrontPage(ctx context.Context, userID int, sort string, timeFilter string, limit int, after, before string) (listing *RedditListing, personalized bool, err error) {
+	token, personalized, err := r.oauthToken(ctx, userID)
+	if err != nil {
+		listing, err = r.GetFrontPage(ctx, sort, timeFilter, limit, after, before)
+		return listing, false, err
+	}
+
+	// Personalized pages are cached per user so they never leak between accounts
+	cacheKey := fmt.Sprintf("fp:app:%s:%s:%d:%s:%s", sort, timeFilter, limit, after, before)
+	if personalized {
+		cacheKey = fmt.Sprintf("fp:user:%d:%s:%s:%d:%s:%s", userID, sort, timeFilter, limit, after, before)
+	}
+	if listing, ok, err := r.getCachedListing(ctx, cacheKey, false); err == nil && ok {
+		return r.filterListingNSFW(ctx, listing), personalized, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://oauth.reddit.com/%s", url.PathEscape(sort)), nil)
+	if err != nil {
+		return nil, personalized, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.nextUserAgent())
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	q := req.URL.Query()
+	if limit > 0 {
+		q.Add("limit", fmt.Sprintf("%d", limit))
+	}
+	if after != "" {
+		q.Add("after", after)
+	}
+	if before != "" {
+		q.Add("before", before)
+	}
+	if timeFilter != "" && (sort == "top" || sort == "controversial") {
+		q.Add("t", timeFilter)
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := r.doWithRetry(req)
+	if err != nil {
+		return nil, personalized, fmt.Errorf("failed to fetch user front page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, personalized, fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var page RedditListing
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, personalized, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	_ = r.setCachedListing(ctx, cacheKey, page)
+	return r.filterListingNSFW(ctx, &page), personalized, nil
+}
+
+// GetMultireddit fetches posts from a user's custom multireddit
+func (r *RedditClient) GetMultireddit(ctx context.Context, username, multiName, sort, timeFilter string, limit int, after string) (*RedditListing, error) {
+	username = strings.TrimSpace(username)
+	multiName = strings.TrimSpace(multiName)
+	if username == "" || multiName == "" {
+		return nil, fmt.Errorf("username and multireddit name are required")
+	}
+	if sort == "" {
+		sort = "hot"
+	}
+
+	cacheKey := fmt.Sprintf("multi:%s:%s:%s:%s:%d:%s", strings.ToLower(username), strings.ToLower(multiName), sort, timeFilter, limit, after)
+	if listing, ok, err := r.getCachedListing(ctx, cacheKey, false); err == nil && ok {
+		return listing, nil
+	}
+
+	requestURL := fmt.Sprintf("https://www.reddit.com/user/%s/m/%s/%s.json",
+		url.PathEscape(username), url.PathEscape(multiName), url.PathEscape(sort))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.nextUserAgent())
+
+	q := req.URL.Query()
+	if limit > 0 {
+		q.Add("limit", fmt.Sprintf("%d", limit))
+	}
+	if after != "" {
+		q.Add("after", after)
+	}
+	if timeFilter != "" && (sort == "top" || sort == "controversial") {
+		q.Add("t", timeFilter) // hour, day, week, month, year, all
+	}
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := r.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch multireddit: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrRedditNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var listing RedditListing
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
 	_ = r.setCachedListing(ctx, cacheKey, listing)
 	return &listing, nil
 }
@@ -467,20 +1079,25 @@ func (r *RedditClient) GetPostInfo(ctx context.Context, subreddit string, reddit
 	}
 	_ = subreddit
 
+	if r.isKnownMissingPost(ctx, redditPostID) {
+		return nil, nil
+	}
+
 	url := fmt.Sprintf("https://www.reddit.com/api/info.json?id=t3_%s", redditPostID)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create info request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch post info: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
+		r.rememberMissingPost(ctx, redditPostID)
 		return nil, nil
 	}
 	if resp.StatusCode != http.StatusOK {
@@ -493,6 +1110,7 @@ func (r *RedditClient) GetPostInfo(ctx context.Context, subreddit string, reddit
 		return nil, fmt.Errorf("failed to decode post info: %w", err)
 	}
 	if len(listing.Data.Children) == 0 {
+		r.rememberMissingPost(ctx, redditPostID)
 		return nil, nil
 	}
 
@@ -503,6 +1121,187 @@ func (r *RedditClient) GetPostInfo(ctx context.Context, subreddit string, reddit
 	return &post, nil
 }
 
+// redditInfoBatchSize is the most IDs /api/info.json accepts per request
+const redditInfoBatchSize = 100
+
+// GetPostsInfo fetches metadata for many Reddit posts, batching IDs into
+// /api/info.json requests of up to 100. The map is keyed by post ID; posts
+// Reddit no longer returns are absent. Any failed batch fails the whole call
+// so callers never mistake a network error for a missing post.
+func (r *RedditClient) GetPostsInfo(ctx context.Context, redditPostIDs []string) (map[string]*RedditPost, error) {
+	seen := make(map[string]bool, len(redditPostIDs))
+	ids := make([]string, 0, len(redditPostIDs))
+	for _, id := range redditPostIDs {
+		id = strings.TrimPrefix(strings.TrimSpace(id), "t3_")
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if r.isKnownMissingPost(ctx, id) {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	posts := make(map[string]*RedditPost, len(ids))
+	for start := 0; start < len(ids); start += redditInfoBatchSize {
+		end := start + redditInfoBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		if err := r.fetchPostsInfoBatch(ctx, ids[start:end], posts); err != nil {
+			return nil, err
+		}
+	}
+	for _, id := range ids {
+		if posts[id] == nil {
+			r.rememberMissingPost(ctx, id)
+		}
+	}
+	return posts, nil
+}
+
+func negativePostInfoKey(redditPostID string) string {
+	return "info:neg:" + redditPostID
+}
+
+// isKnownMissingPost reports whether a recent lookup found no such post.
+func (r *RedditClient) isKnownMissingPost(ctx context.Context, redditPostID string) bool {
+	if r.negativeTTL <= 0 {
+		return false
+	}
+	_, ok, err := r.cache.Get(ctx, negativePostInfoKey(redditPostID))
+	return err == nil && ok
+}
+
+// rememberMissingPost stores a tombstone so the post is not looked up again
+// until negativeTTL passes.
+func (r *RedditClient) rememberMissingPost(ctx context.Context, redditPostID string) {
+	if r.negativeTTL <= 0 {
+		return
+	}
+	_ = r.cache.Set(ctx, negativePostInfoKey(redditPostID), "1", r.negativeTTL)
+}
+
+func (r *RedditClient) fetchPostsInfoBatch(ctx context.Context, ids []string, into map[string]*RedditPost) error {
+	fullnames := make([]string, len(ids))
+	for i, id := range ids {
+		fullnames[i] = "t3_" + id
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://www.reddit.com/api/info.json", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create info request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.nextUserAgent())
+	q := req.URL.Query()
+	q.Add("id", strings.Join(fullnames, ","))
+	q.Add("limit", strconv.Itoa(len(ids)))
+	req.URL.RawQuery = q.Encode()
+
+	resp, err := r.doWithRetry(req)
+	if err != nil {
+		return fmt.Errorf("failed to fetch post info: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var listing redditGenericListing
+	if err := json.NewDecoder(resp.Body).Decode(&listing); err != nil {
+		return fmt.Errorf("failed to decode post info: %w", err)
+	}
+	for _, child := range listing.Data.Children {
+		var post RedditPost
+		if err := json.Unmarshal(child.Data, &post); err != nil {
+			return fmt.Errorf("failed to parse post info: %w", err)
+		}
+		into[post.ID] = &post
+	}
+	return nil
+}
+
+// ResolveShortlink returns the post behind a redd.it shortlink or a
+// reddit.com comments permalink. Only the post ID is taken from the link and
+// the link itself is never fetched; non-Reddit hosts are rejected with
+// ErrRedditInvalidLink.
+func (r *RedditClient) ResolveShortlink(ctx context.Context, shortURL string) (*RedditPost, error) {
+	postID, err := parseRedditPostLink(shortURL)
+	if err != nil {
+		return nil, err
+	}
+	post, err := r.GetPostInfo(ctx, "", postID)
+	if err != nil {
+		return nil, err
+	}
+	if post == nil {
+		return nil, ErrRedditNotFound
+	}
+	return post, nil
+}
+
+// parseRedditPostLink extracts the base36 post ID from redd.it/{id},
+// reddit.com/comments/{id} or reddit.com/r/{sub}/comments/{id}/... links.
+func parseRedditPostLink(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", fmt.Errorf("%w: url is required", ErrRedditInvalidLink)
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "https://" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrRedditInvalidLink, err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("%w: unsupported scheme %q", ErrRedditInvalidLink, parsed.Scheme)
+	}
+	if parsed.User != nil || parsed.Port() != "" {
+		return "", fmt.Errorf("%w: unexpected credentials or port", ErrRedditInvalidLink)
+	}
+
+	host := strings.ToLower(parsed.Hostname())
+	segments := strings.FieldsFunc(parsed.Path, func(c rune) bool { return c == '/' })
+	var postID string
+	switch {
+	case host == "redd.it":
+		if len(segments) == 1 {
+			postID = segments[0]
+		}
+	case host == "reddit.com" || strings.HasSuffix(host, ".reddit.com"):
+		for i := 0; i+1 < len(segments); i++ {
+			if segments[i] == "comments" {
+				postID = segments[i+1]
+				break
+			}
+		}
+	default:
+		return "", fmt.Errorf("%w: host %q is not a reddit domain", ErrRedditInvalidLink, host)
+	}
+
+	postID = strings.TrimPrefix(strings.ToLower(postID), "t3_")
+	if !isRedditBase36ID(postID) {
+		return "", fmt.Errorf("%w: no post id in path", ErrRedditInvalidLink)
+	}
+	return postID, nil
+}
+
+func isRedditBase36ID(id string) bool {
+	if id == "" || len(id) > 13 {
+		return false
+	}
+	for _, c := range id {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
 // GetPostComments fetches comments for a specific Reddit post
 func (r *RedditClient) GetPostComments(ctx context.Context, subreddit string, postID string, sort string, limit int) (interface{}, error) {
 	cacheKey := fmt.Sprintf("cm:%s:%s:%s:%d", subreddit, postID, sort, limit)
@@ -523,7 +1322,7 @@ func (r *RedditClient) GetPostComments(ctx context.Context, subreddit string, po
 	}
 
 	// Set headers
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	// Add query parameters
 	q := req.URL.Query()
@@ -536,7 +1335,7 @@ func (r *RedditClient) GetPostComments(ctx context.Context, subreddit string, po
 	req.URL.RawQuery = q.Encode()
 
 	// Make request
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch comments: %w", err)
 	}
@@ -559,10 +1358,218 @@ func (r *RedditClient) GetPostComments(ctx context.Context, subreddit string, po
 	return result, nil
 }
 
+// GetDuplicatePosts fetches other submissions of the same link as postID,
+// highest score first. Reddit returns [original, duplicates] like the comments endpoint.
+func (r *RedditClient) GetDuplicatePosts(ctx context.Context, subreddit string, postID string, limit int) ([]RedditPost, error) {
+	cacheKey := fmt.Sprintf("dup:%s:%s:%d", strings.ToLower(subreddit), postID, limit)
+	if cached, ok, err := r.cache.Get(ctx, cacheKey); err == nil && ok {
+		var posts []RedditPost
+		if err := json.Unmarshal([]byte(cached), &posts); err == nil {
+			return posts, nil
+		}
+	}
+
+	requestURL := fmt.Sprintf("https://www.reddit.com/r/%s/duplicates/%s.json", url.PathEscape(subreddit), url.PathEscape(postID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.nextUserAgent())
+
+	if limit > 0 {
+		q := req.URL.Query()
+		q.Add("limit", fmt.Sprintf("%d", limit))
+		req.URL.RawQuery = q.Encode()
+	}
+
+	resp, err := r.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch duplicates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrRedditNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var listings []RedditListing
+	if err := json.NewDecoder(resp.Body).Decode(&listings); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(listings) < 2 {
+		return nil, fmt.Errorf("unexpected duplicates response with %d listings", len(listings))
+	}
+
+	posts := make([]RedditPost, 0, len(listings[1].Data.Children))
+	for _, child := range listings[1].Data.Children {
+		posts = append(posts, child.Data)
+	}
+	sort.SliceStable(posts, func(i, j int) bool {
+		return posts[i].Score > posts[j].Score
+	})
+
+	if data, err := json.Marshal(posts); err == nil {
+		_ = r.cache.Set(ctx, cacheKey, string(data), r.cacheTTL)
+	}
+	return posts, nil
+}
+
+// RedditCommentsResult is a decoded comments page: the post and its comment tree
+type RedditCommentsResult struct {
+	Post          RedditPost       `json:"post"`
+	SuggestedSort string           `json:"suggested_sort"`
+	NumComments   int              `json:"num_comments"`
+	Comments      []*RedditComment `json:"comments"`
+}
+
+// RedditComment is one node of a comment tree. Reddit truncates long threads
+// with "more" stubs; those nodes have Kind "more" and list the omitted comment
+// IDs in MoreChildren instead of carrying a body.
+type RedditComment struct {
+	Kind          string           `json:"kind"`
+	ID            string           `json:"id"`
+	ParentID      string           `json:"parent_id"`
+	Author        string           `json:"author,omitempty"`
+	Body          string           `json:"body,omitempty"`
+	Score         int              `json:"score"`
+	CreatedUTC    float64          `json:"created_utc,omitempty"`
+	Depth         int              `json:"depth"`
+	Distinguished *string          `json:"distinguished,omitempty"`
+	Stickied      bool             `json:"stickied,omitempty"`
+	Replies       []*RedditComment `json:"replies,omitempty"`
+	MoreChildren  []string         `json:"more_children,omitempty"`
+	MoreCount     int              `json:"more_count,omitempty"`
+}
+
+// GetPostCommentsTyped is GetPostComments decoded into a RedditCommentsResult.
+// It shares GetPostComments' cache entry.
+func (r *RedditClient) GetPostCommentsTyped(ctx context.Context, subreddit string, postID string, sort string, limit int) (*RedditCommentsResult, error) {
+	raw, err := r.GetPostComments(ctx, subreddit, postID, sort, limit)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode comments: %w", err)
+	}
+	return decodeRedditComments(data)
+}
+
+// redditThing is the kind/data envelope Reddit wraps every object in
+type redditThing struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+type redditThingListing struct {
+	Data struct {
+		Children []redditThing `json:"children"`
+	} `json:"data"`
+}
+
+// decodeRedditComments parses the [post listing, comment listing] pair
+// returned by /r/{sub}/comments/{id}.json.
+func decodeRedditComments(data []byte) (*RedditCommentsResult, error) {
+	var listings []redditThingListing
+	if err := json.Unmarshal(data, &listings); err != nil {
+		return nil, fmt.Errorf("failed to decode comments: %w", err)
+	}
+	if len(listings) < 2 || len(listings[0].Data.Children) == 0 {
+		return nil, fmt.Errorf("unexpected comments response with %d listings", len(listings))
+	}
+
+	var result RedditCommentsResult
+	if err := json.Unmarshal(listings[0].Data.Children[0].Data, &result.Post); err != nil {
+		return nil, fmt.Errorf("failed to decode comments post: %w", err)
+	}
+	result.SuggestedSort = result.Post.SuggestedSort
+	result.NumComments = result.Post.NumComments
+
+	comments, err := decodeRedditCommentThings(listings[1].Data.Children)
+	if err != nil {
+		return nil, err
+	}
+	result.Comments = comments
+	return &result, nil
+}
+
+func decodeRedditCommentThings(things []redditThing) ([]*RedditComment, error) {
+	comments := make([]*RedditComment, 0, len(things))
+	for _, thing := range things {
+		switch thing.Kind {
+		case "t1":
+			var raw struct {
+				ID            string          `json:"id"`
+				ParentID      string          `json:"parent_id"`
+				Author        string          `json:"author"`
+				Body          string          `json:"body"`
+				Score         int             `json:"score"`
+				CreatedUTC    float64         `json:"created_utc"`
+				Depth         int             `json:"depth"`
+				Distinguished *string         `json:"distinguished"`
+				Stickied      bool            `json:"stickied"`
+				Replies       json.RawMessage `json:"replies"`
+			}
+			if err := json.Unmarshal(thing.Data, &raw); err != nil {
+				return nil, fmt.Errorf("failed to decode comment: %w", err)
+			}
+			comment := &RedditComment{
+				Kind:          thing.Kind,
+				ID:            raw.ID,
+				ParentID:      raw.ParentID,
+				Author:        raw.Author,
+				Body:          html.UnescapeString(raw.Body),
+				Score:         raw.Score,
+				CreatedUTC:    raw.CreatedUTC,
+				Depth:         raw.Depth,
+				Distinguished: raw.Distinguished,
+				Stickied:      raw.Stickied,
+			}
+			// Reddit sends "replies": "" for leaf comments and a listing otherwise
+			if len(raw.Replies) > 0 && raw.Replies[0] == '{' {
+				var replies redditThingListing
+				if err := json.Unmarshal(raw.Replies, &replies); err != nil {
+					return nil, fmt.Errorf("failed to decode comment replies: %w", err)
+				}
+				children, err := decodeRedditCommentThings(replies.Data.Children)
+				if err != nil {
+					return nil, err
+				}
+				comment.Replies = children
+			}
+			comments = append(comments, comment)
+		case "more":
+			var raw struct {
+				ID       string   `json:"id"`
+				ParentID string   `json:"parent_id"`
+				Depth    int      `json:"depth"`
+				Count    int      `json:"count"`
+				Children []string `json:"children"`
+			}
+			if err := json.Unmarshal(thing.Data, &raw); err != nil {
+				return nil, fmt.Errorf("failed to decode more comments stub: %w", err)
+			}
+			comments = append(comments, &RedditComment{
+				Kind:         thing.Kind,
+				ID:           raw.ID,
+				ParentID:     raw.ParentID,
+				Depth:        raw.Depth,
+				MoreChildren: raw.Children,
+				MoreCount:    raw.Count,
+			})
+		}
+	}
+	return comments, nil
+}
+
 // SearchPosts searches for posts across Reddit
-func (r *RedditClient) SearchPosts(ctx context.Context, query string, subreddit string, sort string, timeFilter string, limit int, after string, includeNSFW bool) (*RedditListing, error) {
-	cacheKey := fmt.Sprintf("search:%s:%s:%s:%s:%d:%s:%t", query, subreddit, sort, timeFilter, limit, after, includeNSFW)
-	if listing, ok, err := r.getCachedListing(ctx, cacheKey); err == nil && ok {
+func (r *RedditClient) SearchPosts(ctx context.Context, query string, subreddit string, sort string, timeFilter string, limit int, after, before string, includeNSFW bool) (*RedditListing, error) {
+	cacheKey := fmt.Sprintf("search:%s:%s:%s:%s:%d:%s:%s:%t", query, subreddit, sort, timeFilter, limit, after, before, includeNSFW)
+	if listing, ok, err := r.getCachedListing(ctx, cacheKey, false); err == nil && ok {
 		return listing, nil
 	}
 
@@ -580,7 +1587,7 @@ func (r *RedditClient) SearchPosts(ctx context.Context, query string, subreddit
 	}
 
 	// Set headers
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	// Add query parameters
 	q := req.URL.Query()
@@ -601,10 +1608,13 @@ func (r *RedditClient) SearchPosts(ctx context.Context, query string, subreddit
 	if after != "" {
 		q.Add("after", after)
 	}
+	if before != "" {
+		q.Add("before", before)
+	}
 	req.URL.RawQuery = q.Encode()
 
 	// Make request
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search: %w", err)
 	}
@@ -632,7 +1642,7 @@ func (r *RedditClient) SearchUsers(ctx context.Context, query string, limit int,
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	q := req.URL.Query()
 	q.Add("q", query)
@@ -645,7 +1655,7 @@ func (r *RedditClient) SearchUsers(ctx context.Context, query string, limit int,
 	q.Add("include_over_18", strconv.FormatBool(includeNSFW))
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
@@ -664,33 +1674,68 @@ func (r *RedditClient) SearchUsers(ctx context.Context, query string, limit int,
 	return &listing, nil
 }
 
+// Labels returned by RedditRemovalReason.
+const (
+	RedditRemovalModerator       = "moderator"
+	RedditRemovalSpam            = "spam"
+	RedditRemovalDeletedByAuthor = "deleted by author"
+	RedditRemovalCopyright       = "copyright"
+	RedditRemovalAdmin           = "reddit admin"
+	RedditRemovalQuarantined     = "quarantined"
+	RedditRemovalAuthorBlocked   = "author blocked"
+	RedditRemovalUnavailable     = "unavailable"
+)
+
 // IsRedditPostRemoved returns true if the Reddit post has been removed or deleted.
 func IsRedditPostRemoved(post *RedditPost) bool {
+	return RedditRemovalReason(post) != ""
+}
+
+// RedditRemovalReason returns a human readable label explaining why a post is
+// no longer visible, or "" when it has not been removed. A nil post (one Reddit
+// no longer returns at all) is reported as RedditRemovalUnavailable.
+func RedditRemovalReason(post *RedditPost) string {
 	if post == nil {
-		return true
+		return RedditRemovalUnavailable
+	}
+
+	switch category := normalizeRemovedIndicator(post.RemovedByCategory); category {
+	case "":
+	case "deleted", "author":
+		return RedditRemovalDeletedByAuthor
+	case "spam":
+		return RedditRemovalSpam
+	case "copyright_takedown":
+		return RedditRemovalCopyright
+	case "anti_evil_ops", "community_ops", "legal_operations", "content_takedown", "reddit":
+		return RedditRemovalAdmin
+	default:
+		// "moderator", "automod_filtered" and any category Reddit adds later
+		return RedditRemovalModerator
+	}
+
+	if post.Quarantine {
+		return RedditRemovalQuarantined
 	}
-	if post.RemovedByCategory != "" {
-		return true
+	if post.AuthorIsBlocked {
+		return RedditRemovalAuthorBlocked
 	}
 	if post.RemovedBy != nil && normalizeRemovedIndicator(*post.RemovedBy) != "" {
-		return true
+		return RedditRemovalModerator
 	}
 	if post.BannedBy != nil && normalizeRemovedIndicator(*post.BannedBy) != "" {
-		return true
+		return RedditRemovalModerator
 	}
 
 	title := normalizeRemovedIndicator(post.Title)
-	if title == "[removed]" || title == "[deleted]" || strings.Contains(title, "removed by moderator") {
-		return true
-	}
 	body := normalizeRemovedIndicator(post.Selftext)
-	if body == "[removed]" || body == "[deleted]" {
-		return true
+	if title == "[deleted]" || body == "[deleted]" || normalizeRemovedIndicator(post.Author) == "[deleted]" {
+		return RedditRemovalDeletedByAuthor
 	}
-	if normalizeRemovedIndicator(post.Author) == "[deleted]" {
-		return true
+	if title == "[removed]" || body == "[removed]" || strings.Contains(title, "removed by moderator") {
+		return RedditRemovalModerator
 	}
-	return false
+	return ""
 }
 
 // AutocompleteSubreddits fetches subreddit suggestions for a given query
@@ -708,7 +1753,7 @@ func (r *RedditClient) AutocompleteSubreddits(ctx context.Context, query string,
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	q := req.URL.Query()
 	q.Set("query", query)
@@ -716,7 +1761,7 @@ func (r *RedditClient) AutocompleteSubreddits(ctx context.Context, query string,
 	q.Set("include_profiles", "false")
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch subreddit suggestions: %w", err)
 	}
@@ -767,7 +1812,7 @@ func (r *RedditClient) SearchSubreddits(ctx context.Context, query string, limit
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
 	q := req.URL.Query()
 	q.Set("q", query)
@@ -777,7 +1822,7 @@ func (r *RedditClient) SearchSubreddits(ctx context.Context, query string, limit
 	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to search subreddits: %w", err)
 	}
@@ -815,7 +1860,7 @@ func (r *RedditClient) SearchSubreddits(ctx context.Context, query string, limit
 }
 
 // GetUserListing fetches a Reddit user's overview/submitted/comments listing
-func (r *RedditClient) GetUserListing(ctx context.Context, username, section, sort string, limit int, after string) (*RedditUserListing, error) {
+func (r *RedditClient) GetUserListing(ctx context.Context, username, section, sort string, limit int, after, before string) (*RedditUserListing, error) {
 	username = strings.TrimSpace(username)
 	if username == "" {
 		return nil, fmt.Errorf("username is required")
@@ -829,12 +1874,16 @@ func (r *RedditClient) GetUserListing(ctx context.Context, username, section, so
 	if limit < 1 || limit > 100 {
 		limit = 25
 	}
+	// after pages forward (older items), before pages backward (newer items)
+	if after != "" && before != "" {
+		return nil, ErrRedditConflictingCursors
+	}
 
-	cacheKey := fmt.Sprintf("user:%s:%s:%s:%d:%s", strings.ToLower(username), section, sort, limit, after)
+	cacheKey := fmt.Sprintf("user:%s:%s:%s:%d:%s:%s", strings.ToLower(username), section, sort, limit, after, before)
 	if cached, ok, err := r.cache.Get(ctx, cacheKey); err == nil && ok {
 		var listing RedditUserListing
 		if err := json.Unmarshal([]byte(cached), &listing); err == nil {
-			return &listing, nil
+			return r.filterUserListingNSFW(ctx, &listing), nil
 		}
 	}
 
@@ -843,7 +1892,7 @@ func (r *RedditClient) GetUserListing(ctx context.Context, username, section, so
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 	q := req.URL.Query()
 	if sort != "" {
 		q.Add("sort", sort)
@@ -854,9 +1903,12 @@ func (r *RedditClient) GetUserListing(ctx context.Context, username, section, so
 	if after != "" {
 		q.Add("after", after)
 	}
+	if before != "" {
+		q.Add("before", before)
+	}
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user listing: %w", err)
 	}
@@ -898,7 +1950,24 @@ func (r *RedditClient) GetUserListing(ctx context.Context, username, section, so
 		_ = r.cache.Set(ctx, cacheKey, string(data), r.cacheTTL)
 	}
 
-	return &listing, nil
+	return r.filterUserListingNSFW(ctx, &listing), nil
+}
+
+// filterUserListingNSFW drops over_18 posts from a user listing when filtering
+// is on. Comments are kept.
+func (r *RedditClient) filterUserListingNSFW(ctx context.Context, listing *RedditUserListing) *RedditUserListing {
+	if listing == nil || !r.shouldFilterNSFW(ctx) {
+		return listing
+	}
+	filtered := *listing
+	filtered.Items = make([]RedditUserItem, 0, len(listing.Items))
+	for _, item := range listing.Items {
+		if item.Post != nil && item.Post.Over18 {
+			continue
+		}
+		filtered.Items = append(filtered.Items, item)
+	}
+	return &filtered
 }
 
 // GetUserAbout fetches profile metadata for a Reddit user
@@ -920,9 +1989,9 @@ func (r *RedditClient) GetUserAbout(ctx context.Context, username string) (*Redd
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch user: %w", err)
 	}
@@ -982,9 +2051,9 @@ func (r *RedditClient) GetUserTrophies(ctx context.Context, username string) ([]
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch trophies: %w", err)
 	}
@@ -1051,9 +2120,9 @@ func (r *RedditClient) GetUserModeratedSubreddits(ctx context.Context, username
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch moderated subreddits: %w", err)
 	}
@@ -1111,9 +2180,9 @@ func (r *RedditClient) GetSubredditAbout(ctx context.Context, subreddit string)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subreddit about request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch subreddit about: %w", err)
 	}
@@ -1121,6 +2190,9 @@ func (r *RedditClient) GetSubredditAbout(ctx context.Context, subreddit string)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
+		if accessErr := subredditAccessError(resp.StatusCode, body); accessErr != nil {
+			return nil, accessErr
+		}
 		return nil, fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
@@ -1138,6 +2210,59 @@ func (r *RedditClient) GetSubredditAbout(ctx context.Context, subreddit string)
 	return &raw.Data, nil
 }
 
+// GetSubredditRules fetches the community rules for a subreddit
+func (r *RedditClient) GetSubredditRules(ctx context.Context, subreddit string) ([]RedditSubredditRule, error) {
+	subreddit = strings.TrimSpace(subreddit)
+	if subreddit == "" {
+		return nil, fmt.Errorf("subreddit is required")
+	}
+
+	cacheKey := fmt.Sprintf("sr:rules:%s", strings.ToLower(subreddit))
+	if cached, ok, err := r.cache.Get(ctx, cacheKey); err == nil && ok {
+		var rules []RedditSubredditRule
+		if err := json.Unmarshal([]byte(cached), &rules); err == nil {
+			return rules, nil
+		}
+	}
+
+	url := fmt.Sprintf("https://www.reddit.com/r/%s/about/rules.json", subreddit)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subreddit rules request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.nextUserAgent())
+
+	resp, err := r.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subreddit rules: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if accessErr := subredditAccessError(resp.StatusCode, body); accessErr != nil {
+			return nil, accessErr
+		}
+		return nil, fmt.Errorf("reddit API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var raw struct {
+		Rules []RedditSubredditRule `json:"rules"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("failed to decode subreddit rules: %w", err)
+	}
+	if raw.Rules == nil {
+		raw.Rules = []RedditSubredditRule{}
+	}
+
+	if data, err := json.Marshal(raw.Rules); err == nil {
+		_ = r.cache.Set(ctx, cacheKey, string(data), r.cacheTTL)
+	}
+
+	return raw.Rules, nil
+}
+
 // GetSubredditModerators fetches the moderators for a subreddit
 func (r *RedditClient) GetSubredditModerators(ctx context.Context, subreddit string) ([]RedditSubredditModerator, error) {
 	subreddit = strings.TrimSpace(subreddit)
@@ -1197,12 +2322,12 @@ func (r *RedditClient) fetchSubredditModeratorsAPI(ctx context.Context, subreddi
 	if err != nil {
 		return nil, fmt.Errorf("failed to create subreddit moderators request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 	if token != "" {
 		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch subreddit moderators: %w", err)
 	}
@@ -1239,15 +2364,89 @@ func (r *RedditClient) fetchSubredditModeratorsAPI(ctx context.Context, subreddi
 	return mods, nil
 }
 
+// GetSubredditLinkFlairs fetches the post flair templates available in a subreddit.
+// It uses the app-only token when client credentials are configured and the
+// public JSON endpoint otherwise, returning ErrRedditFlairsUnavailable when
+// Reddit requires authentication that we cannot provide.
+func (r *RedditClient) GetSubredditLinkFlairs(ctx context.Context, subreddit string) ([]RedditFlair, error) {
+	subreddit = strings.TrimSpace(subreddit)
+	if subreddit == "" {
+		return nil, fmt.Errorf("subreddit is required")
+	}
+
+	cacheKey := fmt.Sprintf("sr:flairs:%s", strings.ToLower(subreddit))
+	if cached, ok, err := r.cache.Get(ctx, cacheKey); err == nil && ok {
+		var flairs []RedditFlair
+		if err := json.Unmarshal([]byte(cached), &flairs); err == nil {
+			return flairs, nil
+		}
+	}
+
+	token := ""
+	if r.clientID != "" && r.clientSecret != "" {
+		var err error
+		token, err = r.getAppAccessToken(ctx)
+		if err != nil {
+			token = ""
+		}
+	}
+
+	var url string
+	if token != "" {
+		url = fmt.Sprintf("https://oauth.reddit.com/r/%s/api/link_flair_v2", subreddit)
+	} else {
+		url = fmt.Sprintf("https://www.reddit.com/r/%s/api/link_flair_v2.json", subreddit)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subreddit flairs request: %w", err)
+	}
+	req.Header.Set("User-Agent", r.nextUserAgent())
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := r.doWithRetry(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subreddit flairs: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		if accessErr := subredditAccessError(resp.StatusCode, body); accessErr != nil {
+			return nil, accessErr
+		}
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return nil, ErrRedditFlairsUnavailable
+		}
+		return nil, &redditHTTPError{statusCode: resp.StatusCode, body: string(body)}
+	}
+
+	var flairs []RedditFlair
+	if err := json.NewDecoder(resp.Body).Decode(&flairs); err != nil {
+		return nil, fmt.Errorf("failed to decode subreddit flairs: %w", err)
+	}
+	if flairs == nil {
+		flairs = []RedditFlair{}
+	}
+
+	if data, err := json.Marshal(flairs); err == nil {
+		_ = r.cache.Set(ctx, cacheKey, string(data), r.cacheTTL)
+	}
+
+	return flairs, nil
+}
+
 func (r *RedditClient) fetchSubredditModeratorsFromHTML(ctx context.Context, subreddit string) ([]RedditSubredditModerator, error) {
 	url := fmt.Sprintf("https://www.reddit.com/r/%s/about/moderators", subreddit)
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create moderators fallback request: %w", err)
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch moderators fallback: %w", err)
 	}
@@ -1316,6 +2515,50 @@ func (r *RedditClient) fetchSubredditModeratorsFromHTML(ctx context.Context, sub
 	return mods, nil
 }
 
+// SetUserToken stores the Reddit OAuth access token for userID, replacing any
+// previous one. An empty token forgets the user's token. Refreshing is left to
+// the caller.
+func (r *RedditClient) SetUserToken(userID int, token string, expiry time.Time) {
+	r.userTokenMu.Lock()
+	defer r.userTokenMu.Unlock()
+
+	if token == "" {
+		delete(r.userTokens, userID)
+		return
+	}
+	if r.userTokens == nil {
+		r.userTokens = make(map[int]redditAppToken)
+	}
+	r.userTokens[userID] = redditAppToken{value: token, expiry: expiry}
+}
+
+// userAccessToken returns userID's stored token unless it is about to expire.
+// A zero expiry means Reddit did not report one and the token is used as is.
+func (r *RedditClient) userAccessToken(userID int) (string, bool) {
+	r.userTokenMu.Lock()
+	defer r.userTokenMu.Unlock()
+
+	token, ok := r.userTokens[userID]
+	if !ok {
+		return "", false
+	}
+	if !token.expiry.IsZero() && time.Until(token.expiry) <= 30*time.Second {
+		return "", false
+	}
+	return token.value, true
+}
+
+// oauthToken picks the bearer token for an oauth.reddit.com request on behalf
+// of userID: the user's own token when one is stored, otherwise the app-only
+// token. personal reports whether the user's token was chosen.
+func (r *RedditClient) oauthToken(ctx context.Context, userID int) (token string, personal bool, err error) {
+	if token, ok := r.userAccessToken(userID); ok {
+		return token, true, nil
+	}
+	token, err = r.getAppAccessToken(ctx)
+	return token, false, err
+}
+
 func (r *RedditClient) getAppAccessToken(ctx context.Context) (string, error) {
 	if r.clientID == "" || r.clientSecret == "" {
 		return "", errors.New("reddit client credentials are not configured")
@@ -1335,9 +2578,9 @@ func (r *RedditClient) getAppAccessToken(ctx context.Context) (string, error) {
 	}
 	req.SetBasicAuth(r.clientID, r.clientSecret)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return "", fmt.Errorf("failed to request reddit token: %w", err)
 	}
@@ -1375,27 +2618,55 @@ func (r *RedditClient) getAppAccessToken(ctx context.Context) (string, error) {
 	return r.appToken.value, nil
 }
 
-func (r *RedditClient) getCachedListing(ctx context.Context, key string) (*RedditListing, bool, error) {
+// cachedListingEnvelope wraps a cached listing with the time it stops being
+// fresh. The cache entry itself lives past that when the stale fallback is on.
+type cachedListingEnvelope struct {
+	SoftExpiresAt time.Time     `json:"soft_expires_at"`
+	Listing       RedditListing `json:"listing"`
+}
+
+// getCachedListing returns a fresh cached listing. With allowStale, an entry
+// past its soft expiry is returned too, marked Stale.
+func (r *RedditClient) getCachedListing(ctx context.Context, key string, allowStale bool) (*RedditListing, bool, error) {
 	cached, ok, err := r.cache.Get(ctx, key)
 	if err != nil || !ok {
 		return nil, false, err
 	}
-	var listing RedditListing
-	if err := json.Unmarshal([]byte(cached), &listing); err != nil {
+	var envelope cachedListingEnvelope
+	if err := json.Unmarshal([]byte(cached), &envelope); err != nil {
 		return nil, false, err
 	}
+	if envelope.SoftExpiresAt.IsZero() {
+		// Entries written before the envelope existed hold the bare listing
+		var listing RedditListing
+		if err := json.Unmarshal([]byte(cached), &listing); err != nil {
+			return nil, false, err
+		}
+		return &listing, true, nil
+	}
+	listing := envelope.Listing
+	if time.Now().After(envelope.SoftExpiresAt) {
+		if !allowStale {
+			return nil, false, nil
+		}
+		listing.Stale = true
+	}
 	return &listing, true, nil
 }
 
 func (r *RedditClient) setCachedListing(ctx context.Context, key string, listing RedditListing) error {
-	data, err := json.Marshal(listing)
+	data, err := json.Marshal(cachedListingEnvelope{
+		SoftExpiresAt: time.Now().Add(r.cacheTTL),
+		Listing:       listing,
+	})
 	if err != nil {
 		return err
 	}
-	return r.cache.Set(ctx, key, string(data), r.cacheTTL)
+	return r.cache.Set(ctx, key, string(data), r.cacheTTL+r.staleWindow)
 }
 
-// GetSubredditWikiPage fetches a wiki page from a subreddit
+// GetSubredditWikiPage fetches a wiki page from a subreddit.
+// content_html is sanitized (see SanitizeWikiHTML) before it is returned.
 func (r *RedditClient) GetSubredditWikiPage(ctx context.Context, subreddit string, pagePath string, revision string) (map[string]interface{}, error) {
 	requestURL := fmt.Sprintf("https://www.reddit.com/r/%s/wiki/%s.json", subreddit, pagePath)
 	if revision != "" {
@@ -1407,9 +2678,9 @@ func (r *RedditClient) GetSubredditWikiPage(ctx context.Context, subreddit strin
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1431,6 +2702,7 @@ func (r *RedditClient) GetSubredditWikiPage(ctx context.Context, subreddit strin
 		return nil, err
 	}
 
+	sanitizeWikiPageHTML(result.Data)
 	return result.Data, nil
 }
 
@@ -1441,9 +2713,9 @@ func (r *RedditClient) GetWikiPage(ctx context.Context, pagePath string) (map[st
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1465,6 +2737,7 @@ func (r *RedditClient) GetWikiPage(ctx context.Context, pagePath string) (map[st
 		return nil, err
 	}
 
+	sanitizeWikiPageHTML(result.Data)
 	return result.Data, nil
 }
 
@@ -1493,9 +2766,9 @@ func (r *RedditClient) GetSubredditWikiRevisions(ctx context.Context, subreddit,
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1551,9 +2824,9 @@ func (r *RedditClient) GetSubredditWikiDiscussions(ctx context.Context, subreddi
 	if err != nil {
 		return nil, err
 	}
-	req.Header.Set("User-Agent", r.userAgent)
+	req.Header.Set("User-Agent", r.nextUserAgent())
 
-	resp, err := r.httpClient.Do(req)
+	resp, err := r.doWithRetry(req)
 	if err != nil {
 		return nil, err
 	}
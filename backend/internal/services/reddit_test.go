@@ -1,11 +1,17 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -26,6 +32,11 @@ func (m *mapCache) Set(ctx context.Context, key string, value string, ttl time.D
 	return nil
 }
 
+func (m *mapCache) Delete(ctx context.Context, key string) error {
+	delete(m.store, key)
+	return nil
+}
+
 // hostRewriteTransport rewrites outgoing requests to a test server host
 type hostRewriteTransport struct {
 	target *httptest.Server
@@ -63,13 +74,13 @@ func TestRedditClientCachesFrontPage(t *testing.T) {
 	defer ts.Close()
 
 	cache := &mapCache{store: make(map[string]string)}
-	client := NewRedditClient("test-agent", cache, time.Minute, "", "")
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
 	client.httpClient.Transport = &hostRewriteTransport{target: ts}
 
 	ctx := context.Background()
 
 	// First call should hit the server
-	listing, err := client.GetFrontPage(ctx, "hot", "", 10, "")
+	listing, err := client.GetFrontPage(ctx, "hot", "", 10, "", "")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -81,7 +92,7 @@ func TestRedditClientCachesFrontPage(t *testing.T) {
 	}
 
 	// Second call should be served from cache (no additional server hit)
-	listing2, err := client.GetFrontPage(ctx, "hot", "", 10, "")
+	listing2, err := client.GetFrontPage(ctx, "hot", "", 10, "", "")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -92,3 +103,1138 @@ func TestRedditClientCachesFrontPage(t *testing.T) {
 		t.Fatalf("expected server still called once, got %d", handlerCalls)
 	}
 }
+
+func TestRedditClientRotatesUserAgents(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.Header.Get("User-Agent"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(RedditListing{Kind: "Listing"})
+	}))
+	defer ts.Close()
+
+	pool := []string{
+		"web:omninudge:v1.0 (by /u/omninudge_ops)",
+		"web:omninudge-feed:v1.0 (by /u/omninudge_ops)",
+		"web:omninudge-comments:v1.0 (by /u/omninudge_ops)",
+	}
+	client, err := NewRedditClientWithUserAgents(pool, NoopCache{}, time.Minute, "", "")
+	if err != nil {
+		t.Fatalf("expected valid pool, got %v", err)
+	}
+	client.httpClient.Transport = &hostRewriteTransport{target: ts}
+
+	for i := 0; i < 6; i++ {
+		if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err != nil {
+			t.Fatalf("request %d failed: %v", i, err)
+		}
+	}
+
+	for i, ua := range seen {
+		if ua != pool[i%len(pool)] {
+			t.Fatalf("request %d: expected user agent %q, got %q", i, pool[i%len(pool)], ua)
+		}
+	}
+	if len(seen) != 6 {
+		t.Fatalf("expected 6 requests, got %d", len(seen))
+	}
+}
+
+func TestNewRedditClientWithUserAgents_RejectsInvalidConfig(t *testing.T) {
+	cases := map[string][]string{
+		"no agents":       nil,
+		"empty":           {"  "},
+		"browser":         {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0 Safari/537.36"},
+		"go default":      {"Go-http-client/1.1"},
+		"library default": {"python-requests/2.31"},
+		"pool without contact": {
+			"web:omninudge:v1.0 (by /u/omninudge_ops)",
+			"web:omninudge:v1.0",
+		},
+	}
+	for name, agents := range cases {
+		if _, err := NewRedditClientWithUserAgents(agents, nil, time.Minute, "", ""); !errors.Is(err, ErrInvalidRedditUserAgent) {
+			t.Errorf("%s: expected ErrInvalidRedditUserAgent, got %v", name, err)
+		}
+	}
+
+	// A single descriptive agent keeps the existing single-UA behavior
+	if _, err := NewRedditClientWithUserAgents([]string{"OmniNudge:v1.0"}, nil, time.Minute, "", ""); err != nil {
+		t.Fatalf("expected single app user agent to be accepted, got %v", err)
+	}
+}
+
+// newTestRedditClient builds a client with a valid single User-Agent.
+func newTestRedditClient(t *testing.T, cache Cache, cacheTTL time.Duration, clientID, clientSecret string, opts ...RedditClientOption) *RedditClient {
+	t.Helper()
+	client, err := NewRedditClient("test-agent", cache, cacheTTL, clientID, clientSecret, opts...)
+	if err != nil {
+		t.Fatalf("NewRedditClient: %v", err)
+	}
+	return client
+}
+
+func TestNewRedditClient_ValidatesUserAgent(t *testing.T) {
+	for _, ua := range []string{"", "Go-http-client/1.1", "Mozilla/5.0 (X11; Linux x86_64) Gecko/20100101 Firefox/120.0"} {
+		if _, err := NewRedditClient(ua, nil, time.Minute, "", ""); !errors.Is(err, ErrInvalidRedditUserAgent) {
+			t.Errorf("%q: expected ErrInvalidRedditUserAgent, got %v", ua, err)
+		}
+	}
+
+	client, err := NewRedditClient("  OmniNudge:v1.0  ", nil, time.Minute, "", "")
+	if err != nil {
+		t.Fatalf("expected app user agent to be accepted, got %v", err)
+	}
+	if ua := client.nextUserAgent(); ua != "OmniNudge:v1.0" {
+		t.Fatalf("expected trimmed user agent, got %q", ua)
+	}
+}
+
+func TestRedditClientUserListingPagesBackwardWithBefore(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"kind":"Listing","data":{"after":"t1_older","before":"t1_newer","children":[]}}`))
+	}))
+	defer ts.Close()
+
+	cache := &mapCache{store: make(map[string]string)}
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
+	client.httpClient.Transport = &hostRewriteTransport{target: ts}
+	ctx := context.Background()
+
+	listing, err := client.GetUserListing(ctx, "spez", "overview", "new", 25, "", "t1_cursor")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if listing.Before != "t1_newer" || listing.After != "t1_older" {
+		t.Fatalf("unexpected cursors: after=%q before=%q", listing.After, listing.Before)
+	}
+
+	// The same cursor used forward is a different page, not a cache hit
+	if _, err := client.GetUserListing(ctx, "spez", "overview", "new", 25, "t1_cursor", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(queries) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(queries))
+	}
+	if !strings.Contains(queries[0], "before=t1_cursor") || strings.Contains(queries[0], "after=") {
+		t.Fatalf("backward page sent wrong cursor: %s", queries[0])
+	}
+	if !strings.Contains(queries[1], "after=t1_cursor") || strings.Contains(queries[1], "before=") {
+		t.Fatalf("forward page sent wrong cursor: %s", queries[1])
+	}
+}
+
+func TestRedditClientUserListingRejectsBothCursors(t *testing.T) {
+	calls := int32(0)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	client.httpClient.Transport = &hostRewriteTransport{target: ts}
+
+	_, err := client.GetUserListing(context.Background(), "spez", "overview", "new", 25, "t1_a", "t1_b")
+	if !errors.Is(err, ErrRedditConflictingCursors) {
+		t.Fatalf("expected ErrRedditConflictingCursors, got %v", err)
+	}
+	if atomic.LoadInt32(&calls) != 0 {
+		t.Fatalf("expected no request to Reddit, got %d", calls)
+	}
+}
+
+// scriptedTransport replies with the queued status codes in order, then 200
+type scriptedTransport struct {
+	mu         sync.Mutex
+	statuses   []int
+	retryAfter string
+	calls      int
+}
+
+func (s *scriptedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	status := http.StatusOK
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	body, _ := json.Marshal(RedditListing{Kind: "Listing"})
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}
+	if status != http.StatusOK && s.retryAfter != "" {
+		resp.Header.Set("Retry-After", s.retryAfter)
+	}
+	return resp, nil
+}
+
+func TestRedditClientRetriesRateLimitedRequests(t *testing.T) {
+	transport := &scriptedTransport{statuses: []int{http.StatusTooManyRequests}}
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{MaxRetries: 2}))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err != nil {
+		t.Fatalf("expected retry to succeed, got %v", err)
+	}
+	if transport.calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", transport.calls)
+	}
+}
+
+func TestRedditClientStopsRetryingAfterMaxRetries(t *testing.T) {
+	transport := &scriptedTransport{statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}}
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{MaxRetries: 1}))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err == nil {
+		t.Fatalf("expected error after exhausting retries")
+	}
+	if transport.calls != 2 {
+		t.Fatalf("expected 2 attempts, got %d", transport.calls)
+	}
+
+	// A zero policy disables retries entirely
+	transport = &scriptedTransport{statuses: []int{http.StatusTooManyRequests}}
+	client = newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{}))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err == nil {
+		t.Fatalf("expected 429 to be returned without retrying")
+	}
+	if transport.calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", transport.calls)
+	}
+}
+
+func TestRedditClientGivesUpWhenRetryAfterExceedsMaxDelay(t *testing.T) {
+	transport := &scriptedTransport{statuses: []int{http.StatusTooManyRequests}, retryAfter: "120"}
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{MaxRetries: 3, MaxDelay: time.Second}))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err == nil {
+		t.Fatalf("expected 429 to be returned when Retry-After is too long")
+	}
+	if transport.calls != 1 {
+		t.Fatalf("expected 1 attempt, got %d", transport.calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"3", 3 * time.Second, true},
+		{now.Add(5 * time.Second).Format(http.TimeFormat), 5 * time.Second, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"", 0, false},
+		{"soon", 0, false},
+	}
+	for _, tc := range cases {
+		got, ok := parseRetryAfter(tc.value, now)
+		if got != tc.want || ok != tc.ok {
+			t.Fatalf("parseRetryAfter(%q) = %v, %v; want %v, %v", tc.value, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+// headerTransport replies 200 with fixed headers
+type headerTransport struct {
+	header http.Header
+}
+
+func (h headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, _ := json.Marshal(RedditListing{Kind: "Listing"})
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     h.header.Clone(),
+		Body:       io.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestRedditClientRecordsRateLimitHeaders(t *testing.T) {
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	if got := client.LastRateLimit(); !got.Timestamp.IsZero() {
+		t.Fatalf("expected zero rate limit before any request, got %+v", got)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/json")
+	header.Set("x-ratelimit-remaining", "587.0")
+	header.Set("x-ratelimit-used", "13")
+	header.Set("x-ratelimit-reset", "242")
+	client.SetHTTPClient(&http.Client{Transport: headerTransport{header: header}})
+
+	before := time.Now()
+	if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	got := client.LastRateLimit()
+	if got.Remaining != 587 || got.Used != 13 || got.ResetSeconds != 242 {
+		t.Fatalf("unexpected rate limit %+v", got)
+	}
+	if got.Timestamp.Before(before) {
+		t.Fatalf("expected timestamp after request start, got %v", got.Timestamp)
+	}
+}
+
+func seedExpiredListing(t *testing.T, cache *mapCache, key, postID string) {
+	t.Helper()
+	var listing RedditListing
+	listing.Kind = "Listing"
+	listing.Data.Children = []struct {
+		Kind string     `json:"kind"`
+		Data RedditPost `json:"data"`
+	}{
+		{Kind: "t3", Data: RedditPost{ID: postID, Title: "cached", Subreddit: "golang"}},
+	}
+	data, err := json.Marshal(cachedListingEnvelope{SoftExpiresAt: time.Now().Add(-time.Minute), Listing: listing})
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+	cache.store[key] = string(data)
+}
+
+func TestRedditClientServesStaleListingWhenRedditFails(t *testing.T) {
+	cache := &mapCache{store: make(map[string]string)}
+	seedExpiredListing(t, cache, "sr:golang:hot::25::", "old1")
+
+	transport := &scriptedTransport{statuses: []int{http.StatusServiceUnavailable}}
+	client := newTestRedditClient(t, cache, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{}), WithRedditStaleFallback(time.Hour))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	listing, err := client.GetSubredditPosts(context.Background(), "golang", "hot", "", 25, "", "")
+	if err != nil {
+		t.Fatalf("expected stale listing, got %v", err)
+	}
+	if !listing.Stale {
+		t.Fatalf("expected listing to be marked stale")
+	}
+	if len(listing.Data.Children) != 1 || listing.Data.Children[0].Data.ID != "old1" {
+		t.Fatalf("unexpected stale listing %+v", listing.Data.Children)
+	}
+	if transport.calls != 1 {
+		t.Fatalf("expected a fresh fetch before falling back, got %d calls", transport.calls)
+	}
+
+	// Once Reddit recovers the fresh listing replaces the stale one
+	listing, err = client.GetSubredditPosts(context.Background(), "golang", "hot", "", 25, "", "")
+	if err != nil {
+		t.Fatalf("expected fresh listing, got %v", err)
+	}
+	if listing.Stale {
+		t.Fatalf("expected fresh listing not to be marked stale")
+	}
+}
+
+func TestRedditClientStaleFallbackMisses(t *testing.T) {
+	// Nothing cached: the upstream error is returned
+	transport := &scriptedTransport{statuses: []int{http.StatusServiceUnavailable}}
+	client := newTestRedditClient(t, &mapCache{store: make(map[string]string)}, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{}), WithRedditStaleFallback(time.Hour))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	if _, err := client.GetSubredditPosts(context.Background(), "golang", "hot", "", 25, "", ""); err == nil {
+		t.Fatalf("expected error on hard miss")
+	}
+
+	// Expired entry but fallback disabled: the upstream error is returned
+	cache := &mapCache{store: make(map[string]string)}
+	seedExpiredListing(t, cache, "sr:golang:hot::25::", "old1")
+	transport = &scriptedTransport{statuses: []int{http.StatusServiceUnavailable}}
+	client = newTestRedditClient(t, cache, time.Minute, "", "", WithRedditRetryPolicy(RedditRetryPolicy{}))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	if _, err := client.GetSubredditPosts(context.Background(), "golang", "hot", "", 25, "", ""); err == nil {
+		t.Fatalf("expected error when stale fallback is disabled")
+	}
+}
+
+// galleryListingJSON is trimmed from a real r/pics gallery post
+const galleryListingJSON = `{"kind":"Listing","data":{"after":null,"before":null,"children":[{"kind":"t3","data":{
+	"id":"1abcxyz","subreddit":"pics","title":"Trip photos","author":"traveller",
+	"url":"https://www.reddit.com/gallery/1abcxyz","is_gallery":true,"domain":"reddit.com",
+	"gallery_data":{"items":[
+		{"media_id":"zz9third","id":301},
+		{"media_id":"aa1first","id":302,"caption":"Sunrise"},
+		{"media_id":"mm5broken","id":303},
+		{"media_id":"gg7anim","id":304}
+	]},
+	"media_metadata":{
+		"aa1first":{"status":"valid","e":"Image","m":"image/jpg",
+			"p":[{"y":108,"x":108,"u":"https://preview.redd.it/aa1first.jpg?width=108&amp;crop=smart&amp;s=p1"}],
+			"s":{"y":1440,"x":1080,"u":"https://preview.redd.it/aa1first.jpg?width=1080&amp;format=pjpg&amp;s=s1"}},
+		"zz9third":{"status":"valid","e":"Image","m":"image/png",
+			"s":{"y":800,"x":1200,"u":"https://preview.redd.it/zz9third.png?width=1200&amp;s=s3"}},
+		"mm5broken":{"status":"failed"},
+		"gg7anim":{"status":"valid","e":"AnimatedImage","m":"image/gif",
+			"s":{"y":240,"x":320,"gif":"https://i.redd.it/gg7anim.gif","mp4":"https://preview.redd.it/gg7anim.gif?format=mp4&amp;s=s4"}}
+	}}}]}}`
+
+func TestRedditPostGalleryImagesFollowGalleryOrder(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(galleryListingJSON))
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	ctx := context.Background()
+
+	listing, err := client.GetSubredditPosts(ctx, "pics", "hot", "", 25, "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(listing.Data.Children) != 1 {
+		t.Fatalf("expected 1 child, got %d", len(listing.Data.Children))
+	}
+	info, err := client.GetPostInfo(ctx, "pics", "1abcxyz")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	want := []RedditImageSource{
+		{URL: "https://preview.redd.it/zz9third.png?width=1200&s=s3", Width: 1200, Height: 800},
+		{URL: "https://preview.redd.it/aa1first.jpg?width=1080&format=pjpg&s=s1", Width: 1080, Height: 1440},
+		{URL: "https://i.redd.it/gg7anim.gif", Width: 320, Height: 240},
+	}
+	for name, post := range map[string]RedditPost{"listing": listing.Data.Children[0].Data, "info": *info} {
+		if post.GalleryData == nil || len(post.GalleryData.Items) != 4 {
+			t.Fatalf("%s: expected 4 gallery items, got %+v", name, post.GalleryData)
+		}
+		if post.GalleryData.Items[1].Caption != "Sunrise" {
+			t.Fatalf("%s: expected caption to decode, got %q", name, post.GalleryData.Items[1].Caption)
+		}
+		got := post.GalleryImages()
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %d images, got %+v", name, len(want), got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: image %d = %+v, want %+v", name, i, got[i], want[i])
+			}
+		}
+	}
+
+	if images := (RedditPost{ID: "plain"}).GalleryImages(); images != nil {
+		t.Fatalf("expected no images for a non-gallery post, got %+v", images)
+	}
+}
+
+// bodyTransport replies with a fixed JSON body (200 unless status is set) and
+// records request paths
+type bodyTransport struct {
+	status int
+	body   string
+	paths  []string
+}
+
+func (b *bodyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.paths = append(b.paths, req.URL.Path)
+	status := b.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return &http.Response{
+		StatusCode: status,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(strings.NewReader(b.body)),
+		Request:    req,
+	}, nil
+}
+
+func TestRedditClientGetDuplicatePostsSortsByScore(t *testing.T) {
+	transport := &bodyTransport{body: `[
+		{"kind":"Listing","data":{"children":[{"kind":"t3","data":{"id":"orig","subreddit":"golang","title":"Go 1.23 released","score":900}}]}},
+		{"kind":"Listing","data":{"children":[
+			{"kind":"t3","data":{"id":"d1","subreddit":"programming","title":"Go 1.23","score":40}},
+			{"kind":"t3","data":{"id":"d2","subreddit":"technology","title":"Go 1.23 is out","score":1200}},
+			{"kind":"t3","data":{"id":"d3","subreddit":"golang_jobs","title":"Go release","score":3}}
+		]}}
+	]`}
+	cache := &mapCache{store: make(map[string]string)}
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	posts, err := client.GetDuplicatePosts(context.Background(), "golang", "orig", 25)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(transport.paths) != 1 || transport.paths[0] != "/r/golang/duplicates/orig.json" {
+		t.Fatalf("unexpected request paths %v", transport.paths)
+	}
+
+	var ids []string
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+	if strings.Join(ids, ",") != "d2,d1,d3" {
+		t.Fatalf("expected duplicates sorted by score, got %v", ids)
+	}
+	if _, ok := cache.store["dup:golang:orig:25"]; !ok {
+		t.Fatalf("expected duplicates to be cached under dup: key, got %v", cache.store)
+	}
+
+	// Served from cache the second time
+	if _, err := client.GetDuplicatePosts(context.Background(), "golang", "orig", 25); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(transport.paths) != 1 {
+		t.Fatalf("expected cached duplicates, got %d requests", len(transport.paths))
+	}
+}
+
+func TestRedditClientOAuthTokenSelection(t *testing.T) {
+	var tokenRequests int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/access_token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		atomic.AddInt32(&tokenRequests, 1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"app-token","token_type":"bearer","expires_in":3600}`))
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "id", "secret")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	ctx := context.Background()
+
+	client.SetUserToken(1, "user-token", time.Now().Add(time.Hour))
+	client.SetUserToken(2, "expiring-token", time.Now().Add(10*time.Second))
+	client.SetUserToken(3, "no-expiry-token", time.Time{})
+
+	cases := []struct {
+		userID       int
+		wantToken    string
+		wantPersonal bool
+	}{
+		{1, "user-token", true},
+		{2, "app-token", false}, // about to expire, so the app token is used
+		{3, "no-expiry-token", true},
+		{4, "app-token", false}, // never linked Reddit
+	}
+	for _, tc := range cases {
+		token, personal, err := client.oauthToken(ctx, tc.userID)
+		if err != nil {
+			t.Fatalf("user %d: expected no error, got %v", tc.userID, err)
+		}
+		if token != tc.wantToken || personal != tc.wantPersonal {
+			t.Fatalf("user %d: got (%q, %t), want (%q, %t)", tc.userID, token, personal, tc.wantToken, tc.wantPersonal)
+		}
+	}
+	if atomic.LoadInt32(&tokenRequests) != 1 {
+		t.Fatalf("expected the app token to be fetched once, got %d", tokenRequests)
+	}
+
+	// Clearing a user's token falls back to the app token
+	client.SetUserToken(1, "", time.Time{})
+	if token, personal, _ := client.oauthToken(ctx, 1); token != "app-token" || personal {
+		t.Fatalf("expected app token after clearing, got (%q, %t)", token, personal)
+	}
+
+	// Without client credentials there is nothing to fall back to
+	anonymous := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	if _, _, err := anonymous.oauthToken(ctx, 4); err == nil {
+		t.Fatalf("expected error without user token or client credentials")
+	}
+}
+
+func TestRedditClientGetUserFrontPage(t *testing.T) {
+	var mu sync.Mutex
+	var seen []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen = append(seen, r.URL.Path+" "+r.Header.Get("Authorization"))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(RedditListing{Kind: "Listing"})
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, &mapCache{store: make(map[string]string)}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+	client.SetUserToken(1, "user-token", time.Now().Add(time.Hour))
+	ctx := context.Background()
+
+	if _, personalized, err := client.GetUserFrontPage(ctx, 1, "hot", "", 25, "", ""); err != nil || !personalized {
+		t.Fatalf("expected personalized front page, got personalized=%t err=%v", personalized, err)
+	}
+	if _, personalized, err := client.GetUserFrontPage(ctx, 2, "hot", "", 25, "", ""); err != nil || personalized {
+		t.Fatalf("expected public front page, got personalized=%t err=%v", personalized, err)
+	}
+
+	want := []string{"/hot Bearer user-token", "/hot.json "}
+	if strings.Join(seen, "|") != strings.Join(want, "|") {
+		t.Fatalf("unexpected requests %q, want %q", seen, want)
+	}
+}
+
+func TestRedditClientClassifiesSubredditAccessErrors(t *testing.T) {
+	cases := []struct {
+		name   string
+		status int
+		body   string
+		want   error
+	}{
+		{"private", http.StatusForbidden, `{"reason": "private", "message": "Forbidden", "error": 403}`, ErrRedditForbiddenPrivate},
+		{"banned", http.StatusNotFound, `{"reason": "banned", "message": "Not Found", "error": 404}`, ErrRedditBanned},
+		{"banned 403", http.StatusForbidden, `{"reason": "banned", "message": "Forbidden", "error": 403}`, ErrRedditBanned},
+		{"quarantined", http.StatusForbidden, `{"reason": "quarantined", "message": "Forbidden", "error": 403}`, nil},
+		{"html", http.StatusForbidden, `<html>blocked</html>`, nil},
+	}
+	for _, tc := range cases {
+		client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+			WithRedditRetryPolicy(RedditRetryPolicy{}), WithRedditStaleFallback(time.Hour))
+		client.SetHTTPClient(&http.Client{Transport: &bodyTransport{status: tc.status, body: tc.body}})
+
+		_, postsErr := client.GetSubredditPosts(context.Background(), "secret", "hot", "", 25, "", "")
+		_, aboutErr := client.GetSubredditAbout(context.Background(), "secret")
+		for label, err := range map[string]error{"posts": postsErr, "about": aboutErr} {
+			if err == nil {
+				t.Fatalf("%s/%s: expected error", tc.name, label)
+			}
+			if tc.want != nil && !errors.Is(err, tc.want) {
+				t.Fatalf("%s/%s: expected %v, got %v", tc.name, label, tc.want, err)
+			}
+			if tc.want == nil && (errors.Is(err, ErrRedditForbiddenPrivate) || errors.Is(err, ErrRedditBanned)) {
+				t.Fatalf("%s/%s: expected a generic error, got %v", tc.name, label, err)
+			}
+		}
+	}
+}
+
+func TestRedditClientFrontPageSortPaths(t *testing.T) {
+	transport := &bodyTransport{body: `{"kind":"Listing","data":{"children":[]}}`}
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	for _, sort := range []string{"best", "top"} {
+		if _, err := client.GetFrontPage(context.Background(), sort, "week", 10, "", ""); err != nil {
+			t.Fatalf("%s: expected no error, got %v", sort, err)
+		}
+	}
+	if strings.Join(transport.paths, ",") != "/best.json,/top.json" {
+		t.Fatalf("unexpected front page paths %v", transport.paths)
+	}
+}
+
+func TestRedditClientResolveShortlink(t *testing.T) {
+	transport := &bodyTransport{body: `{"kind":"Listing","data":{"children":[{"kind":"t3","data":{"id":"abc123","subreddit":"golang","title":"Resolved"}}]}}`}
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	for _, link := range []string{
+		"https://redd.it/abc123",
+		"redd.it/abc123",
+		"https://www.reddit.com/r/golang/comments/abc123/resolved_title/",
+		"https://old.reddit.com/comments/abc123",
+	} {
+		post, err := client.ResolveShortlink(context.Background(), link)
+		if err != nil {
+			t.Fatalf("%s: expected no error, got %v", link, err)
+		}
+		if post.ID != "abc123" || post.Title != "Resolved" {
+			t.Fatalf("%s: unexpected post %+v", link, post)
+		}
+	}
+	requests := len(transport.paths)
+
+	for _, link := range []string{
+		"https://example.com/comments/abc123",
+		"https://reddit.com.evil.test/comments/abc123",
+		"https://notreddit.com/comments/abc123",
+		"http://169.254.169.254/latest/meta-data",
+		"ftp://redd.it/abc123",
+		"https://redd.it/",
+		"https://www.reddit.com/r/golang/",
+		"https://redd.it/abc123:8080",
+		"",
+	} {
+		if _, err := client.ResolveShortlink(context.Background(), link); !errors.Is(err, ErrRedditInvalidLink) {
+			t.Fatalf("%q: expected ErrRedditInvalidLink, got %v", link, err)
+		}
+	}
+	if len(transport.paths) != requests {
+		t.Fatalf("rejected links must not reach Reddit, got %d extra requests", len(transport.paths)-requests)
+	}
+}
+
+// commentsPayloadJSON is trimmed from a captured /r/golang/comments/{id}.json response
+const commentsPayloadJSON = `[
+	{"kind":"Listing","data":{"children":[{"kind":"t3","data":{
+		"id":"1c0m3nt","subreddit":"golang","title":"What are you working on?","author":"AutoModerator",
+		"num_comments":57,"score":42,"suggested_sort":"new","stickied":true}}]}},
+	{"kind":"Listing","data":{"children":[
+		{"kind":"t1","data":{"id":"c1","parent_id":"t3_1c0m3nt","author":"gopher","body":"A CLI in Go &amp; SQLite",
+			"score":12,"created_utc":1712345678.0,"depth":0,
+			"replies":{"kind":"Listing","data":{"children":[
+				{"kind":"t1","data":{"id":"c1a","parent_id":"t1_c1","author":"reviewer","body":"Nice!","score":3,
+					"created_utc":1712345700.0,"depth":1,"replies":""}},
+				{"kind":"more","data":{"id":"c1m","parent_id":"t1_c1","depth":1,"count":4,"children":["c1b","c1c","c1d","c1e"]}}
+			]}}}},
+		{"kind":"t1","data":{"id":"c2","parent_id":"t3_1c0m3nt","author":"[deleted]","body":"[removed]",
+			"score":1,"created_utc":1712345800.0,"depth":0,"replies":""}},
+		{"kind":"more","data":{"id":"c3m","parent_id":"t3_1c0m3nt","depth":0,"count":30,"children":["c3","c4"]}}
+	]}}
+]`
+
+func TestRedditClientGetPostCommentsTypedDecodesTree(t *testing.T) {
+	transport := &bodyTransport{body: commentsPayloadJSON}
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	result, err := client.GetPostCommentsTyped(context.Background(), "golang", "1c0m3nt", "", 50)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if result.Post.ID != "1c0m3nt" || result.SuggestedSort != "new" || result.NumComments != 57 {
+		t.Fatalf("unexpected post fields %+v", result)
+	}
+	if len(result.Comments) != 3 {
+		t.Fatalf("expected 3 top-level nodes, got %d", len(result.Comments))
+	}
+
+	first := result.Comments[0]
+	if first.Author != "gopher" || first.Body != "A CLI in Go & SQLite" || first.Score != 12 || first.CreatedUTC != 1712345678 {
+		t.Fatalf("unexpected first comment %+v", first)
+	}
+	if len(first.Replies) != 2 {
+		t.Fatalf("expected 2 replies, got %d", len(first.Replies))
+	}
+	if reply := first.Replies[0]; reply.ID != "c1a" || reply.Depth != 1 || len(reply.Replies) != 0 {
+		t.Fatalf("unexpected reply %+v", reply)
+	}
+	if more := first.Replies[1]; more.Kind != "more" || more.MoreCount != 4 || strings.Join(more.MoreChildren, ",") != "c1b,c1c,c1d,c1e" {
+		t.Fatalf("unexpected nested more stub %+v", more)
+	}
+
+	if leaf := result.Comments[1]; leaf.ID != "c2" || leaf.Replies != nil {
+		t.Fatalf("unexpected leaf comment %+v", leaf)
+	}
+	if more := result.Comments[2]; more.Kind != "more" || strings.Join(more.MoreChildren, ",") != "c3,c4" {
+		t.Fatalf("unexpected top-level more stub %+v", more)
+	}
+}
+
+func TestRedditClientNSFWFilter(t *testing.T) {
+	mixed := `{"kind":"Listing","data":{"children":[
+		{"kind":"t3","data":{"id":"safe1","over_18":false}},
+		{"kind":"t3","data":{"id":"nsfw1","over_18":true}},
+		{"kind":"t3","data":{"id":"safe2"}}
+	]}}`
+	ids := func(listing *RedditListing) string {
+		var out []string
+		for _, child := range listing.Data.Children {
+			out = append(out, child.Data.ID)
+		}
+		return strings.Join(out, ",")
+	}
+	ctx := context.Background()
+
+	cache := &mapCache{store: make(map[string]string)}
+	filtering := newTestRedditClient(t, cache, time.Minute, "", "", WithRedditNSFWFilter(true))
+	filtering.SetHTTPClient(&http.Client{Transport: &bodyTransport{body: mixed}})
+
+	listing, err := filtering.GetSubredditPosts(ctx, "mixed", "hot", "", 25, "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := ids(listing); got != "safe1,safe2" {
+		t.Fatalf("expected NSFW post filtered, got %s", got)
+	}
+	// The cache keeps the full listing, so an override sees every post
+	listing, err = filtering.GetSubredditPosts(WithNSFWFilter(ctx, false), "mixed", "hot", "", 25, "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if got := ids(listing); got != "safe1,nsfw1,safe2" {
+		t.Fatalf("expected override to include NSFW post, got %s", got)
+	}
+	if listing, _ = filtering.GetFrontPage(ctx, "hot", "", 25, "", ""); ids(listing) != "safe1,safe2" {
+		t.Fatalf("expected front page filtered, got %s", ids(listing))
+	}
+
+	open := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	open.SetHTTPClient(&http.Client{Transport: &bodyTransport{body: mixed}})
+	if listing, _ = open.GetFrontPage(ctx, "hot", "", 25, "", ""); ids(listing) != "safe1,nsfw1,safe2" {
+		t.Fatalf("expected listing untouched with filter off, got %s", ids(listing))
+	}
+	if listing, _ = open.GetFrontPage(WithNSFWFilter(ctx, true), "hot", "", 25, "", ""); ids(listing) != "safe1,safe2" {
+		t.Fatalf("expected override to filter, got %s", ids(listing))
+	}
+
+	// User listings drop NSFW posts but keep comments
+	userListing := `{"kind":"Listing","data":{"children":[
+		{"kind":"t3","data":{"id":"p1","over_18":true}},
+		{"kind":"t1","data":{"id":"c1","body":"comment"}},
+		{"kind":"t3","data":{"id":"p2"}}
+	]}}`
+	filtering.SetHTTPClient(&http.Client{Transport: &bodyTransport{body: userListing}})
+	user, err := filtering.GetUserListing(ctx, "someone", "overview", "new", 25, "", "")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(user.Items) != 2 || user.Items[0].Comment == nil || user.Items[1].Post.ID != "p2" {
+		t.Fatalf("unexpected filtered user listing %+v", user.Items)
+	}
+}
+
+func TestRedditClientGetPostsInfoBatchesIDs(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/info.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fullnames := strings.Split(r.URL.Query().Get("id"), ",")
+		mu.Lock()
+		batches = append(batches, fullnames)
+		mu.Unlock()
+
+		// Every third post no longer exists and is left out of the response
+		var listing RedditListing
+		for i, name := range fullnames {
+			if i%3 == 2 {
+				continue
+			}
+			listing.Data.Children = append(listing.Data.Children, struct {
+				Kind string     `json:"kind"`
+				Data RedditPost `json:"data"`
+			}{Kind: "t3", Data: RedditPost{ID: strings.TrimPrefix(name, "t3_")}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(listing)
+	}))
+	defer ts.Close()
+
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: &hostRewriteTransport{target: ts}})
+
+	ids := make([]string, 0, 151)
+	for i := 0; i < 150; i++ {
+		ids = append(ids, strconv.FormatInt(int64(1000+i), 36))
+	}
+	ids = append(ids, ids[0]) // duplicates are only requested once
+
+	posts, err := client.GetPostsInfo(context.Background(), ids)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(batches) != 2 || len(batches[0]) != 100 || len(batches[1]) != 50 {
+		t.Fatalf("expected batches of 100 and 50, got %d batches", len(batches))
+	}
+	if batches[0][0] != "t3_"+ids[0] || batches[1][0] != "t3_"+ids[100] {
+		t.Fatalf("unexpected batch contents %v / %v", batches[0][0], batches[1][0])
+	}
+	if len(posts) != 101 {
+		t.Fatalf("expected 101 surviving posts, got %d", len(posts))
+	}
+	if posts[ids[0]] == nil || posts[ids[2]] != nil {
+		t.Fatalf("expected map keyed by post id with missing posts absent")
+	}
+
+	if empty, err := client.GetPostsInfo(context.Background(), nil); err != nil || len(empty) != 0 || len(batches) != 2 {
+		t.Fatalf("expected no request for empty input, got %v, %v", empty, err)
+	}
+}
+
+// expiringCache is an in-memory cache that honours TTLs
+type expiringCache struct {
+	mu      sync.Mutex
+	entries map[string]expiringEntry
+}
+
+type expiringEntry struct {
+	value   string
+	expires time.Time
+}
+
+func (c *expiringCache) Get(ctx context.Context, key string) (string, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return "", false, nil
+	}
+	return entry.value, true, nil
+}
+
+func (c *expiringCache) Set(ctx context.Context, key string, value string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]expiringEntry)
+	}
+	c.entries[key] = expiringEntry{value: value, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+func (c *expiringCache) Delete(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+	return nil
+}
+
+func TestRedditClientCachesMissingPostInfo(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+	empty := `{"kind":"Listing","data":{"children":[]}}`
+	ctx := context.Background()
+
+	transport := &bodyTransport{body: empty}
+	cache := &expiringCache{}
+	client := newTestRedditClient(t, cache, time.Minute, "", "", WithRedditNegativeCacheTTL(ttl))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	for i := 0; i < 2; i++ {
+		post, err := client.GetPostInfo(ctx, "golang", "gone1")
+		if err != nil || post != nil {
+			t.Fatalf("call %d: expected missing post, got %+v, %v", i, post, err)
+		}
+	}
+	if len(transport.paths) != 1 {
+		t.Fatalf("expected second lookup within TTL to skip Reddit, got %d requests", len(transport.paths))
+	}
+	if _, ok, _ := cache.Get(ctx, "info:neg:gone1"); !ok {
+		t.Fatalf("expected tombstone under info:neg:gone1")
+	}
+
+	// Batched lookups share the tombstones
+	if posts, err := client.GetPostsInfo(ctx, []string{"gone1"}); err != nil || len(posts) != 0 {
+		t.Fatalf("expected no posts, got %v, %v", posts, err)
+	}
+	if len(transport.paths) != 1 {
+		t.Fatalf("expected batched lookup to skip known missing post, got %d requests", len(transport.paths))
+	}
+
+	time.Sleep(ttl + 20*time.Millisecond)
+	if _, err := client.GetPostInfo(ctx, "golang", "gone1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(transport.paths) != 2 {
+		t.Fatalf("expected lookup after TTL to reach Reddit, got %d requests", len(transport.paths))
+	}
+
+	// A zero TTL disables negative caching
+	transport = &bodyTransport{body: empty}
+	client = newTestRedditClient(t, &expiringCache{}, time.Minute, "", "", WithRedditNegativeCacheTTL(0))
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	_, _ = client.GetPostInfo(ctx, "golang", "gone1")
+	_, _ = client.GetPostInfo(ctx, "golang", "gone1")
+	if len(transport.paths) != 2 {
+		t.Fatalf("expected every lookup to reach Reddit when disabled, got %d requests", len(transport.paths))
+	}
+}
+
+const subredditRulesJSON = `{"rules": [
+	{"kind": "link", "description": "Posts must be about Go.", "short_name": "Stay on topic",
+		"violation_reason": "Off topic", "created_utc": 1500000000.0, "priority": 0,
+		"description_html": "&lt;p&gt;Posts must be about Go.&lt;/p&gt;"},
+	{"kind": "all", "description": "", "short_name": "Be civil",
+		"violation_reason": "Incivility", "created_utc": 1600000000.0, "priority": 1}
+], "site_rules": ["Spam", "Personal and confidential information"]}`
+
+func TestRedditClientGetSubredditRulesDecodes(t *testing.T) {
+	transport := &bodyTransport{body: subredditRulesJSON}
+	cache := &mapCache{store: make(map[string]string)}
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	rules, err := client.GetSubredditRules(context.Background(), "GoLang")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(transport.paths) != 1 || transport.paths[0] != "/r/GoLang/about/rules.json" {
+		t.Fatalf("unexpected request paths %v", transport.paths)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(rules))
+	}
+	first := rules[0]
+	if first.ShortName != "Stay on topic" || first.Description != "Posts must be about Go." ||
+		first.ViolationReason != "Off topic" || first.CreatedUTC != 1500000000 || first.Kind != "link" {
+		t.Fatalf("unexpected first rule %+v", first)
+	}
+	if rules[1].ShortName != "Be civil" || rules[1].Priority != 1 {
+		t.Fatalf("unexpected second rule %+v", rules[1])
+	}
+
+	if _, ok := cache.store["sr:rules:golang"]; !ok {
+		t.Fatalf("expected rules cached under sr:rules:golang")
+	}
+	if _, err := client.GetSubredditRules(context.Background(), "golang"); err != nil || len(transport.paths) != 1 {
+		t.Fatalf("expected cached rules, got %d requests, err %v", len(transport.paths), err)
+	}
+}
+
+func TestRedditClientGetSubredditLinkFlairsDecodes(t *testing.T) {
+	transport := &bodyTransport{body: `[
+		{"id": "f1a2", "text": "Discussion", "background_color": "#0079d3", "text_color": "light", "type": "text", "mod_only": false},
+		{"id": "b3c4", "text": "Show & Tell", "background_color": "", "text_color": "dark", "type": "text", "mod_only": false}
+	]`}
+	cache := &mapCache{store: make(map[string]string)}
+	client := newTestRedditClient(t, cache, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	flairs, err := client.GetSubredditLinkFlairs(context.Background(), "GoLang")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(transport.paths) != 1 || transport.paths[0] != "/r/GoLang/api/link_flair_v2.json" {
+		t.Fatalf("unexpected request paths %v", transport.paths)
+	}
+	if len(flairs) != 2 {
+		t.Fatalf("expected 2 flairs, got %d", len(flairs))
+	}
+	want := RedditFlair{ID: "f1a2", Text: "Discussion", BackgroundColor: "#0079d3", TextColor: "light"}
+	if flairs[0] != want {
+		t.Fatalf("unexpected first flair %+v", flairs[0])
+	}
+	if flairs[1].Text != "Show & Tell" || flairs[1].TextColor != "dark" {
+		t.Fatalf("unexpected second flair %+v", flairs[1])
+	}
+
+	if _, ok := cache.store["sr:flairs:golang"]; !ok {
+		t.Fatalf("expected flairs cached under sr:flairs:golang")
+	}
+	if _, err := client.GetSubredditLinkFlairs(context.Background(), "golang"); err != nil || len(transport.paths) != 1 {
+		t.Fatalf("expected cached flairs, got %d requests, err %v", len(transport.paths), err)
+	}
+}
+
+func TestRedditClientGetSubredditLinkFlairsUnavailable(t *testing.T) {
+	transport := &bodyTransport{status: http.StatusForbidden, body: `{"message": "Forbidden", "error": 403}`}
+	client := newTestRedditClient(t, &mapCache{store: make(map[string]string)}, time.Minute, "", "")
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	if _, err := client.GetSubredditLinkFlairs(context.Background(), "golang"); !errors.Is(err, ErrRedditFlairsUnavailable) {
+		t.Fatalf("expected ErrRedditFlairsUnavailable, got %v", err)
+	}
+}
+
+func TestRedditClientHTTPOptions(t *testing.T) {
+	transportOf := func(client *RedditClient) *http.Transport {
+		transport, ok := client.httpClient.Transport.(*http.Transport)
+		if !ok {
+			t.Fatalf("expected *http.Transport, got %T", client.httpClient.Transport)
+		}
+		return transport
+	}
+
+	defaults := newTestRedditClient(t, NoopCache{}, time.Minute, "", "")
+	if defaults.httpClient.Timeout != 10*time.Second {
+		t.Fatalf("expected default 10s timeout, got %v", defaults.httpClient.Timeout)
+	}
+	if got := transportOf(defaults).MaxIdleConnsPerHost; got != http.DefaultMaxIdleConnsPerHost {
+		t.Fatalf("expected default idle pool of %d, got %d", http.DefaultMaxIdleConnsPerHost, got)
+	}
+
+	tuned := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditHTTPOptions(RedditHTTPOptions{Timeout: 3 * time.Second, MaxIdleConnsPerHost: 16, DialTimeout: time.Second}))
+	if tuned.httpClient.Timeout != 3*time.Second {
+		t.Fatalf("expected configured 3s timeout, got %v", tuned.httpClient.Timeout)
+	}
+	if got := transportOf(tuned).MaxIdleConnsPerHost; got != 16 {
+		t.Fatalf("expected idle pool of 16, got %d", got)
+	}
+	if transportOf(tuned) == http.DefaultTransport {
+		t.Fatalf("expected a dedicated transport, not the shared default")
+	}
+
+	// Zero fields fall back to the defaults
+	partial := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditHTTPOptions(RedditHTTPOptions{MaxIdleConnsPerHost: 4}))
+	if partial.httpClient.Timeout != 10*time.Second || transportOf(partial).MaxIdleConnsPerHost != 4 {
+		t.Fatalf("unexpected partial options: timeout %v, idle %d", partial.httpClient.Timeout, transportOf(partial).MaxIdleConnsPerHost)
+	}
+}
+
+func TestRedditClientHTTPTimeoutAppliesToRequests(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	client := newTestRedditClient(t, NoopCache{}, time.Minute, "", "",
+		WithRedditRetryPolicy(RedditRetryPolicy{}),
+		WithRedditHTTPOptions(RedditHTTPOptions{Timeout: 50 * time.Millisecond}))
+	client.httpClient.Transport = &hostRewriteTransport{target: ts}
+
+	start := time.Now()
+	if _, err := client.GetFrontPage(context.Background(), "hot", "", 10, "", ""); err == nil {
+		t.Fatalf("expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected request to give up after the configured timeout, took %v", elapsed)
+	}
+}
+
+func TestRedditRemovalReason(t *testing.T) {
+	strPtr := func(v string) *string { return &v }
+
+	tests := []struct {
+		name string
+		post *RedditPost
+		want string
+	}{
+		{name: "visible post", post: &RedditPost{Title: "Go 1.23", Author: "gopher"}, want: ""},
+		{name: "missing post", post: nil, want: RedditRemovalUnavailable},
+		{name: "moderator category", post: &RedditPost{RemovedByCategory: "moderator"}, want: RedditRemovalModerator},
+		{name: "automod category", post: &RedditPost{RemovedByCategory: "automod_filtered"}, want: RedditRemovalModerator},
+		{name: "spam category", post: &RedditPost{RemovedByCategory: "spam"}, want: RedditRemovalSpam},
+		{name: "deleted category", post: &RedditPost{RemovedByCategory: "deleted"}, want: RedditRemovalDeletedByAuthor},
+		{name: "author category", post: &RedditPost{RemovedByCategory: " Author "}, want: RedditRemovalDeletedByAuthor},
+		{name: "copyright category", post: &RedditPost{RemovedByCategory: "copyright_takedown"}, want: RedditRemovalCopyright},
+		{name: "admin category", post: &RedditPost{RemovedByCategory: "anti_evil_ops"}, want: RedditRemovalAdmin},
+		{name: "unknown category", post: &RedditPost{RemovedByCategory: "something_new"}, want: RedditRemovalModerator},
+		{name: "quarantined", post: &RedditPost{Title: "Visible", Quarantine: true}, want: RedditRemovalQuarantined},
+		{name: "author blocked", post: &RedditPost{Title: "Visible", AuthorIsBlocked: true}, want: RedditRemovalAuthorBlocked},
+		{name: "removed by", post: &RedditPost{RemovedBy: strPtr("somemod")}, want: RedditRemovalModerator},
+		{name: "banned by", post: &RedditPost{BannedBy: strPtr("AutoModerator")}, want: RedditRemovalModerator},
+		{name: "empty removed by", post: &RedditPost{Title: "Visible", RemovedBy: strPtr(" ")}, want: ""},
+		{name: "removed selftext", post: &RedditPost{Title: "Visible", Selftext: "[removed]"}, want: RedditRemovalModerator},
+		{name: "removed by moderator title", post: &RedditPost{Title: "[ Removed by moderator ]"}, want: RedditRemovalModerator},
+		{name: "deleted title", post: &RedditPost{Title: "[deleted]"}, want: RedditRemovalDeletedByAuthor},
+		{name: "deleted author", post: &RedditPost{Title: "Visible", Author: "[deleted]"}, want: RedditRemovalDeletedByAuthor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RedditRemovalReason(tt.post); got != tt.want {
+				t.Fatalf("RedditRemovalReason() = %q, want %q", got, tt.want)
+			}
+			if got := IsRedditPostRemoved(tt.post); got != (tt.want != "") {
+				t.Fatalf("IsRedditPostRemoved() = %v, want %v", got, tt.want != "")
+			}
+		})
+	}
+}
@@ -0,0 +1,173 @@
+package services
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/websocket"
+)
+
+// SlideshowStore is the part of the slideshow repository the auto-advancer needs
+type SlideshowStore interface {
+	GetByID(ctx context.Context, id int) (*models.SlideshowSession, error)
+	UpdateCurrentIndex(ctx context.Context, sessionID int, index int) error
+}
+
+// SlideshowHub is the part of the websocket hub the auto-advancer needs
+type SlideshowHub interface {
+	BroadcastToUsers(userIDs []int, msgType string, payload interface{})
+	SlideshowViewers(slideshowID int) []websocket.SlideshowViewer
+	IsUserOnline(userID int) bool
+}
+
+// SlideshowService drives auto-advancing slideshows from the server, so every
+// participant moves to the next slide at the same moment instead of each
+// client running its own timer. One goroutine ticks per auto-advancing slideshow.
+type SlideshowService struct {
+	repo SlideshowStore
+	hub  SlideshowHub
+
+	mu      sync.Mutex
+	tickers map[int]chan struct{} // slideshow ID -> stop channel
+}
+
+// NewSlideshowService creates a new slideshow service
+func NewSlideshowService(repo SlideshowStore, hub SlideshowHub) *SlideshowService {
+	return &SlideshowService{
+		repo:    repo,
+		hub:     hub,
+		tickers: make(map[int]chan struct{}),
+	}
+}
+
+// StartAutoAdvance starts ticking a slideshow forward every interval and
+// broadcasting slideshow_navigate to the participants. A ticker already running
+// for the slideshow is replaced, so this also applies a new interval.
+func (s *SlideshowService) StartAutoAdvance(slideshowID int, participantIDs []int, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stop, ok := s.tickers[slideshowID]; ok {
+		close(stop)
+	}
+	stop := make(chan struct{})
+	s.tickers[slideshowID] = stop
+	go s.run(slideshowID, participantIDs, interval, stop)
+}
+
+// EnsureAutoAdvance starts ticking a slideshow unless it already is, e.g. when
+// a viewer rejoins a slideshow whose ticker stopped for lack of an audience
+func (s *SlideshowService) EnsureAutoAdvance(slideshowID int, participantIDs []int, interval time.Duration) {
+	if s.IsAutoAdvancing(slideshowID) {
+		return
+	}
+	s.StartAutoAdvance(slideshowID, participantIDs, interval)
+}
+
+// StopAutoAdvance stops a slideshow's ticker, if any
+func (s *SlideshowService) StopAutoAdvance(slideshowID int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if stop, ok := s.tickers[slideshowID]; ok {
+		close(stop)
+		delete(s.tickers, slideshowID)
+	}
+}
+
+// IsAutoAdvancing reports whether a ticker is running for the slideshow
+func (s *SlideshowService) IsAutoAdvancing(slideshowID int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.tickers[slideshowID]
+	return ok
+}
+
+func (s *SlideshowService) run(slideshowID int, participantIDs []int, interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if !s.advance(slideshowID, participantIDs) {
+				s.stopIfCurrent(slideshowID, stop)
+				return
+			}
+		}
+	}
+}
+
+// advance moves the slideshow one slide forward. Returns false once the
+// slideshow is gone, auto-advance was turned off, or nobody is watching.
+func (s *SlideshowService) advance(slideshowID int, participantIDs []int) bool {
+	if !s.hasAudience(slideshowID, participantIDs) {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	session, err := s.repo.GetByID(ctx, slideshowID)
+	if err != nil {
+		// Transient failure; try again on the next tick
+		log.Printf("Failed to load slideshow %d for auto-advance: %v", slideshowID, err)
+		return true
+	}
+	if session == nil || !session.AutoAdvance {
+		return false
+	}
+
+	next := session.CurrentIndex + 1
+	if session.TotalItems > 0 {
+		next %= session.TotalItems
+	}
+	if err := s.repo.UpdateCurrentIndex(ctx, slideshowID, next); err != nil {
+		log.Printf("Failed to auto-advance slideshow %d: %v", slideshowID, err)
+		return true
+	}
+
+	s.hub.BroadcastToUsers(participantIDs, "slideshow_navigate", map[string]interface{}{
+		"slideshow_id":  slideshowID,
+		"current_index": next,
+		"controller_id": session.ControllerUserID,
+		"auto_advance":  true,
+	})
+	return true
+}
+
+// hasAudience reports whether anyone could be watching the slideshow: a viewer
+// who joined it, or a participant with an open connection. Clients aren't
+// required to join, so connected participants count as viewers too.
+func (s *SlideshowService) hasAudience(slideshowID int, participantIDs []int) bool {
+	if len(s.hub.SlideshowViewers(slideshowID)) > 0 {
+		return true
+	}
+	for _, userID := range participantIDs {
+		if s.hub.IsUserOnline(userID) {
+			return true
+		}
+	}
+	return false
+}
+
+// stopIfCurrent forgets a ticker that stopped on its own, unless it has
+// already been replaced by a newer one
+func (s *SlideshowService) stopIfCurrent(slideshowID int, stop chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tickers[slideshowID] == stop {
+		close(stop)
+		delete(s.tickers, slideshowID)
+	}
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+	"github.com/omninudge/backend/internal/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSlideshowStore keeps slideshow sessions in memory
+type fakeSlideshowStore struct {
+	mu       sync.Mutex
+	sessions map[int]*models.SlideshowSession
+}
+
+func (f *fakeSlideshowStore) GetByID(ctx context.Context, id int) (*models.SlideshowSession, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	session, ok := f.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (f *fakeSlideshowStore) UpdateCurrentIndex(ctx context.Context, sessionID int, index int) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[sessionID].CurrentIndex = index
+	return nil
+}
+
+func (f *fakeSlideshowStore) setAutoAdvance(id int, enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.sessions[id].AutoAdvance = enabled
+}
+
+type recordedBroadcast struct {
+	userIDs []int
+	msgType string
+	payload map[string]interface{}
+}
+
+// fakeSlideshowHub records broadcasts and reports a fixed set of viewers and
+// connected users
+type fakeSlideshowHub struct {
+	mu         sync.Mutex
+	viewers    []websocket.SlideshowViewer
+	online     map[int]bool
+	broadcasts []recordedBroadcast
+}
+
+func (f *fakeSlideshowHub) BroadcastToUsers(userIDs []int, msgType string, payload interface{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.broadcasts = append(f.broadcasts, recordedBroadcast{userIDs, msgType, payload.(map[string]interface{})})
+}
+
+func (f *fakeSlideshowHub) SlideshowViewers(slideshowID int) []websocket.SlideshowViewer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.viewers
+}
+
+func (f *fakeSlideshowHub) IsUserOnline(userID int) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.online[userID]
+}
+
+func (f *fakeSlideshowHub) setOnline(userIDs ...int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.online = make(map[int]bool, len(userIDs))
+	for _, id := range userIDs {
+		f.online[id] = true
+	}
+}
+
+func (f *fakeSlideshowHub) setViewers(viewers ...websocket.SlideshowViewer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.viewers = viewers
+}
+
+func (f *fakeSlideshowHub) recorded() []recordedBroadcast {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]recordedBroadcast(nil), f.broadcasts...)
+}
+
+func setupSlideshowServiceTest() (*SlideshowService, *fakeSlideshowStore, *fakeSlideshowHub) {
+	store := &fakeSlideshowStore{sessions: map[int]*models.SlideshowSession{
+		1: {ID: 1, TotalItems: 3, ControllerUserID: 10, AutoAdvance: true, AutoAdvanceInterval: 5},
+	}}
+	hub := &fakeSlideshowHub{viewers: []websocket.SlideshowViewer{{UserID: 10}, {UserID: 20}}}
+	return NewSlideshowService(store, hub), store, hub
+}
+
+func TestSlideshowAutoAdvance_BroadcastsPeriodicNavigate(t *testing.T) {
+	service, store, hub := setupSlideshowServiceTest()
+	defer service.StopAutoAdvance(1)
+
+	service.StartAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+
+	require.Eventually(t, func() bool { return len(hub.recorded()) >= 4 }, time.Second, 5*time.Millisecond)
+
+	broadcasts := hub.recorded()
+	for i, b := range broadcasts[:4] {
+		assert.Equal(t, "slideshow_navigate", b.msgType)
+		assert.Equal(t, []int{10, 20}, b.userIDs)
+		assert.Equal(t, 1, b.payload["slideshow_id"])
+		assert.Equal(t, 10, b.payload["controller_id"])
+		// Index wraps around after the last of the 3 items
+		assert.Equal(t, (i+1)%3, b.payload["current_index"])
+	}
+
+	session, _ := store.GetByID(context.Background(), 1)
+	assert.Equal(t, broadcasts[len(broadcasts)-1].payload["current_index"], session.CurrentIndex)
+}
+
+func TestSlideshowAutoAdvance_StopHaltsBroadcasts(t *testing.T) {
+	service, _, hub := setupSlideshowServiceTest()
+
+	service.StartAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+	require.Eventually(t, func() bool { return len(hub.recorded()) >= 1 }, time.Second, 5*time.Millisecond)
+
+	service.StopAutoAdvance(1)
+	assert.False(t, service.IsAutoAdvancing(1))
+
+	// Let a tick that was already in flight finish
+	time.Sleep(30 * time.Millisecond)
+	count := len(hub.recorded())
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(t, hub.recorded(), count, "no broadcasts after stopping")
+}
+
+func TestSlideshowAutoAdvance_StopsWhenDisabledOrUnwatched(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		service, store, hub := setupSlideshowServiceTest()
+		store.setAutoAdvance(1, false)
+
+		service.StartAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+		require.Eventually(t, func() bool { return !service.IsAutoAdvancing(1) }, time.Second, 5*time.Millisecond)
+		assert.Empty(t, hub.recorded())
+	})
+
+	t.Run("no viewers", func(t *testing.T) {
+		service, _, hub := setupSlideshowServiceTest()
+		hub.setViewers()
+
+		service.StartAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+		require.Eventually(t, func() bool { return !service.IsAutoAdvancing(1) }, time.Second, 5*time.Millisecond)
+		assert.Empty(t, hub.recorded())
+
+		// A returning viewer resumes ticking
+		hub.setViewers(websocket.SlideshowViewer{UserID: 20})
+		service.EnsureAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+		defer service.StopAutoAdvance(1)
+		require.Eventually(t, func() bool { return len(hub.recorded()) >= 1 }, time.Second, 5*time.Millisecond)
+	})
+}
+
+func TestSlideshowAutoAdvance_ConnectedParticipantsCountAsViewers(t *testing.T) {
+	service, _, hub := setupSlideshowServiceTest()
+	defer service.StopAutoAdvance(1)
+
+	// Nobody joined explicitly, but one participant is connected
+	hub.setViewers()
+	hub.setOnline(20)
+
+	service.StartAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+	require.Eventually(t, func() bool { return len(hub.recorded()) >= 3 }, time.Second, 5*time.Millisecond)
+	assert.True(t, service.IsAutoAdvancing(1))
+
+	// Once they disconnect too, the ticker stops
+	hub.setOnline()
+	require.Eventually(t, func() bool { return !service.IsAutoAdvancing(1) }, time.Second, 5*time.Millisecond)
+}
+
+func TestSlideshowAutoAdvance_RestartReplacesTicker(t *testing.T) {
+	service, _, hub := setupSlideshowServiceTest()
+	defer service.StopAutoAdvance(1)
+
+	service.StartAutoAdvance(1, []int{10, 20}, time.Hour)
+	service.StartAutoAdvance(1, []int{10, 20}, 20*time.Millisecond)
+
+	require.Eventually(t, func() bool { return len(hub.recorded()) >= 2 }, time.Second, 5*time.Millisecond)
+	assert.True(t, service.IsAutoAdvancing(1))
+}
@@ -7,6 +7,7 @@ import (
 	"mime/multipart"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,6 +16,7 @@ import (
 // StorageService defines the interface for file storage operations
 type StorageService interface {
 	Upload(ctx context.Context, file multipart.File, header *multipart.FileHeader) (*UploadedFile, error)
+	Save(ctx context.Context, relPath string, r io.Reader) (*UploadedFile, error)
 	Delete(ctx context.Context, path string) error
 	GetURL(path string) string
 }
@@ -100,6 +102,40 @@ func (s *LocalStorageService) Upload(ctx context.Context, file multipart.File, h
 	}, nil
 }
 
+// Save writes r to relPath under the media directory, for generated files such as
+// transcoded renditions whose layout the caller controls (an HLS playlist and
+// its segments must share a directory).
+func (s *LocalStorageService) Save(ctx context.Context, relPath string, r io.Reader) (*UploadedFile, error) {
+	cleaned := filepath.Clean(relPath)
+	if cleaned == "." || filepath.IsAbs(cleaned) || strings.HasPrefix(cleaned, "..") {
+		return nil, fmt.Errorf("invalid storage path %q", relPath)
+	}
+
+	destPath := filepath.Join(s.uploadsDir, cleaned)
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	dst, err := os.Create(destPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer dst.Close()
+
+	written, err := io.Copy(dst, r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write file: %w", err)
+	}
+
+	storagePath := filepath.Join("media", cleaned)
+	return &UploadedFile{
+		Filename:    filepath.Base(cleaned),
+		StoragePath: storagePath,
+		URL:         s.GetURL(storagePath),
+		FileSize:    written,
+	}, nil
+}
+
 // Delete removes a file from the local filesystem
 func (s *LocalStorageService) Delete(ctx context.Context, path string) error {
 	fullPath := filepath.Join(s.basePath, path)
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/omninudge/backend/internal/config"
+)
+
+// CSSLimits bounds the size and complexity of a single theme.
+// A zero value disables that particular limit.
+type CSSLimits struct {
+	MaxCSSBytes  int `json:"max_css_bytes"`
+	MaxRules     int `json:"max_rules"`
+	MaxVariables int `json:"max_variables"`
+}
+
+// ThemeCSSLimits holds separate limits for private themes and for themes
+// shared publicly or listed in the marketplace, which are held to stricter caps.
+type ThemeCSSLimits struct {
+	Private CSSLimits
+	Public  CSSLimits
+}
+
+// DefaultThemeCSSLimits returns the limits used when none are configured.
+func DefaultThemeCSSLimits() ThemeCSSLimits {
+	return ThemeCSSLimits{
+		Private: CSSLimits{
+			MaxCSSBytes:  config.DefaultThemeMaxCSSBytes,
+			MaxRules:     config.DefaultThemeMaxCSSRules,
+			MaxVariables: config.DefaultThemeMaxCSSVariables,
+		},
+		Public: CSSLimits{
+			MaxCSSBytes:  config.DefaultThemePublicMaxCSSBytes,
+			MaxRules:     config.DefaultThemePublicMaxCSSRules,
+			MaxVariables: config.DefaultThemePublicMaxVariables,
+		},
+	}
+}
+
+// For returns the limits that apply to a theme with the given visibility.
+func (l ThemeCSSLimits) For(public bool) CSSLimits {
+	if public {
+		return l.Public
+	}
+	return l.Private
+}
+
+// CSSLimitError reports which limit a theme exceeded.
+type CSSLimitError struct {
+	Limit  string `json:"limit"`
+	Max    int    `json:"max"`
+	Actual int    `json:"actual"`
+}
+
+func (e *CSSLimitError) Error() string {
+	switch e.Limit {
+	case "custom_css_bytes":
+		return fmt.Sprintf("custom_css is %d bytes, exceeding the limit of %d bytes", e.Actual, e.Max)
+	case "custom_css_rules":
+		return fmt.Sprintf("custom_css has %d rules, exceeding the limit of %d rules", e.Actual, e.Max)
+	default:
+		return fmt.Sprintf("css_variables has %d entries, exceeding the limit of %d entries", e.Actual, e.Max)
+	}
+}
+
+// CountCSSRules counts rule blocks in css, including rules nested in at-rules
+// such as @media, so wrapping rules in a block does not hide them.
+func CountCSSRules(css string) int {
+	return strings.Count(css, "{")
+}
+
+// Check validates already-sanitized CSS and the number of CSS variables against
+// the limits. Callers should pass sanitized CSS so comments and whitespace
+// don't count against the user.
+func (l CSSLimits) Check(sanitizedCSS string, variableCount int) *CSSLimitError {
+	if l.MaxCSSBytes > 0 && len(sanitizedCSS) > l.MaxCSSBytes {
+		return &CSSLimitError{Limit: "custom_css_bytes", Max: l.MaxCSSBytes, Actual: len(sanitizedCSS)}
+	}
+	if rules := CountCSSRules(sanitizedCSS); l.MaxRules > 0 && rules > l.MaxRules {
+		return &CSSLimitError{Limit: "custom_css_rules", Max: l.MaxRules, Actual: rules}
+	}
+	if l.MaxVariables > 0 && variableCount > l.MaxVariables {
+		return &CSSLimitError{Limit: "css_variables", Max: l.MaxVariables, Actual: variableCount}
+	}
+	return nil
+}
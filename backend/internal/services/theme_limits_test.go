@@ -0,0 +1,50 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSSLimits_ByteLimitBoundary(t *testing.T) {
+	limits := CSSLimits{MaxCSSBytes: 32}
+
+	assert.Nil(t, limits.Check(strings.Repeat("a", 32), 0))
+
+	err := limits.Check(strings.Repeat("a", 33), 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "custom_css_bytes", err.Limit)
+	assert.Equal(t, 33, err.Actual)
+}
+
+func TestCSSLimits_RuleLimitBoundary(t *testing.T) {
+	limits := CSSLimits{MaxRules: 3}
+
+	assert.Nil(t, limits.Check("a { color: red; } b { color: red; } c { color: red; }", 0))
+
+	// Rules nested inside @media count individually, as does the block itself
+	err := limits.Check("@media (min-width: 1px) { a { color: red; } b { color: red; } c { color: red; } }", 0)
+	require.NotNil(t, err)
+	assert.Equal(t, "custom_css_rules", err.Limit)
+	assert.Equal(t, 4, err.Actual)
+}
+
+func TestCSSLimits_VariableLimitBoundary(t *testing.T) {
+	limits := CSSLimits{MaxVariables: 2}
+
+	assert.Nil(t, limits.Check("", 2))
+
+	err := limits.Check("", 3)
+	require.NotNil(t, err)
+	assert.Equal(t, "css_variables", err.Limit)
+}
+
+func TestThemeCSSLimits_PublicIsStricter(t *testing.T) {
+	limits := DefaultThemeCSSLimits()
+	css := strings.Repeat("a", limits.Public.MaxCSSBytes+1)
+
+	assert.Nil(t, limits.For(false).Check(css, 0))
+	assert.NotNil(t, limits.For(true).Check(css, 0))
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// TranscodeResult holds the renditions produced for one input file.
+type TranscodeResult struct {
+	// MP4Path is an H.264/AAC MP4 that plays in every browser
+	MP4Path string
+	// HLSDir holds index.m3u8 and its segments; empty when no HLS rendition was made
+	HLSDir string
+}
+
+// HLSPlaylistName is the playlist file inside TranscodeResult.HLSDir
+const HLSPlaylistName = "index.m3u8"
+
+// Transcoder converts uploaded videos into web-friendly renditions, writing
+// them into outputDir. Implementations can wrap ffmpeg or a hosted service;
+// NoopTranscoder is used when transcoding is disabled.
+type Transcoder interface {
+	Transcode(ctx context.Context, inputPath, outputDir string) (*TranscodeResult, error)
+}
+
+// NoopTranscoder leaves uploads untouched.
+type NoopTranscoder struct{}
+
+// Transcode implements Transcoder and always returns nil.
+func (NoopTranscoder) Transcode(ctx context.Context, inputPath, outputDir string) (*TranscodeResult, error) {
+	return nil, nil
+}
+
+// FFmpegTranscoder transcodes with a local ffmpeg binary.
+type FFmpegTranscoder struct {
+	binary string
+	hls    bool
+}
+
+// NewFFmpegTranscoder creates an ffmpeg-backed transcoder. When hls is true an
+// HLS rendition is produced alongside the MP4.
+func NewFFmpegTranscoder(binary string, hls bool) *FFmpegTranscoder {
+	if binary == "" {
+		binary = "ffmpeg"
+	}
+	return &FFmpegTranscoder{binary: binary, hls: hls}
+}
+
+// Available reports whether the ffmpeg binary can be found.
+func (t *FFmpegTranscoder) Available() bool {
+	_, err := exec.LookPath(t.binary)
+	return err == nil
+}
+
+// Transcode implements Transcoder.
+func (t *FFmpegTranscoder) Transcode(ctx context.Context, inputPath, outputDir string) (*TranscodeResult, error) {
+	result := &TranscodeResult{MP4Path: filepath.Join(outputDir, "video.mp4")}
+
+	// yuv420p and +faststart keep the MP4 playable (and streamable) in browsers
+	if err := t.run(ctx,
+		"-y", "-i", inputPath,
+		"-c:v", "libx264", "-preset", "veryfast", "-profile:v", "main", "-pix_fmt", "yuv420p",
+		"-c:a", "aac", "-b:a", "128k",
+		"-movflags", "+faststart",
+		result.MP4Path,
+	); err != nil {
+		return nil, err
+	}
+
+	if t.hls {
+		result.HLSDir = filepath.Join(outputDir, "hls")
+		if err := os.MkdirAll(result.HLSDir, 0o755); err != nil {
+			return nil, fmt.Errorf("failed to create HLS directory: %w", err)
+		}
+		// The MP4 is already H.264/AAC, so segmenting is a stream copy
+		if err := t.run(ctx,
+			"-y", "-i", result.MP4Path,
+			"-c", "copy", "-start_number", "0",
+			"-hls_time", "6", "-hls_playlist_type", "vod",
+			"-hls_segment_filename", filepath.Join(result.HLSDir, "segment_%03d.ts"),
+			filepath.Join(result.HLSDir, HLSPlaylistName),
+		); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+func (t *FFmpegTranscoder) run(ctx context.Context, args ...string) error {
+	cmd := exec.CommandContext(ctx, t.binary, append([]string{"-hide_banner", "-loglevel", "error"}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// videoExtensions catches containers http.DetectContentType doesn't recognize
+var videoExtensions = map[string]bool{
+	".mp4": true, ".m4v": true, ".mov": true, ".mkv": true, ".webm": true,
+	".avi": true, ".wmv": true, ".flv": true, ".3gp": true, ".mpg": true, ".mpeg": true,
+}
+
+// IsVideoType checks if a content type or file name is a video
+func IsVideoType(contentType, filename string) bool {
+	if strings.HasPrefix(contentType, "video/") {
+		return true
+	}
+	return videoExtensions[strings.ToLower(filepath.Ext(filename))]
+}
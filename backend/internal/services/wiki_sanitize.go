@@ -0,0 +1,171 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// wikiAllowedAttrs is the allowlist of tags and, per tag, attributes kept in
+// wiki HTML. It mirrors the allowlist the wiki page renderer applies client-side.
+var wikiAllowedAttrs = map[string]map[string]bool{
+	"a":          {"href": true, "title": true, "id": true, "name": true},
+	"img":        {"src": true, "alt": true, "title": true, "width": true, "height": true},
+	"span":       {"class": true, "id": true, "name": true},
+	"div":        {"class": true, "id": true, "name": true},
+	"p":          {"id": true, "name": true},
+	"ul":         {"id": true, "name": true},
+	"ol":         {"id": true, "name": true},
+	"li":         {"id": true, "name": true},
+	"table":      {"id": true, "name": true},
+	"thead":      {"id": true, "name": true},
+	"tbody":      {"id": true, "name": true},
+	"tr":         {"id": true, "name": true},
+	"td":         {"colspan": true, "rowspan": true, "id": true, "name": true},
+	"th":         {"colspan": true, "rowspan": true, "id": true, "name": true},
+	"h1":         {"id": true, "name": true},
+	"h2":         {"id": true, "name": true},
+	"h3":         {"id": true, "name": true},
+	"h4":         {"id": true, "name": true},
+	"h5":         {"id": true, "name": true},
+	"h6":         {"id": true, "name": true},
+	"strong":     {},
+	"em":         {},
+	"del":        {},
+	"sup":        {},
+	"blockquote": {},
+	"code":       {},
+	"pre":        {},
+	"hr":         {},
+	"br":         {},
+}
+
+// wikiDroppedTags are removed together with their content rather than unwrapped.
+var wikiDroppedTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"frame":    true,
+	"frameset": true,
+	"noscript": true,
+	"template": true,
+	"svg":      true,
+	"math":     true,
+	"form":     true,
+	"textarea": true,
+	"select":   true,
+	"button":   true,
+	"input":    true,
+	"link":     true,
+	"meta":     true,
+	"base":     true,
+}
+
+// SanitizeWikiHTML strips scripts, event handlers, comments and any tag or
+// attribute outside the wiki allowlist from Reddit's content_html. Reddit
+// returns that field entity-escaped, so escaped input is decoded first; the
+// result is plain (unescaped) HTML. Disallowed tags are unwrapped so their
+// text survives, except for wikiDroppedTags which are removed entirely.
+func SanitizeWikiHTML(input string) string {
+	if strings.TrimSpace(input) == "" {
+		return ""
+	}
+
+	source := input
+	if !strings.Contains(source, "<") && strings.Contains(source, "&lt;") {
+		source = html.UnescapeString(source)
+	}
+
+	parent := &html.Node{Type: html.ElementNode, Data: "div", DataAtom: atom.Div}
+	nodes, err := html.ParseFragment(strings.NewReader(source), parent)
+	if err != nil {
+		return html.EscapeString(source)
+	}
+
+	var b strings.Builder
+	for _, node := range nodes {
+		for _, clean := range sanitizeWikiNode(node) {
+			if err := html.Render(&b, clean); err != nil {
+				return ""
+			}
+		}
+	}
+	return b.String()
+}
+
+// sanitizeWikiPageHTML replaces the content_html field of a wiki page payload
+// with its sanitized form.
+func sanitizeWikiPageHTML(page map[string]interface{}) {
+	if page == nil {
+		return
+	}
+	if content, ok := page["content_html"].(string); ok {
+		page["content_html"] = SanitizeWikiHTML(content)
+	}
+}
+
+// sanitizeWikiNode returns the nodes that should replace n in the sanitized
+// tree: n itself with filtered attributes and children, n's sanitized children
+// when the tag is unwrapped, or nothing when it is dropped.
+func sanitizeWikiNode(n *html.Node) []*html.Node {
+	switch n.Type {
+	case html.TextNode:
+		return []*html.Node{{Type: html.TextNode, Data: n.Data}}
+	case html.ElementNode:
+		tag := strings.ToLower(n.Data)
+		if wikiDroppedTags[tag] {
+			return nil
+		}
+
+		var children []*html.Node
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			children = append(children, sanitizeWikiNode(child)...)
+		}
+
+		allowedAttrs, ok := wikiAllowedAttrs[tag]
+		if !ok {
+			return children
+		}
+
+		clean := &html.Node{Type: html.ElementNode, Data: tag, DataAtom: atom.Lookup([]byte(tag))}
+		for _, attr := range n.Attr {
+			key := strings.ToLower(attr.Key)
+			if attr.Namespace != "" || !allowedAttrs[key] {
+				continue
+			}
+			if (key == "href" || key == "src") && !isSafeWikiURL(attr.Val) {
+				continue
+			}
+			clean.Attr = append(clean.Attr, html.Attribute{Key: key, Val: attr.Val})
+		}
+		for _, child := range children {
+			clean.AppendChild(child)
+		}
+		return []*html.Node{clean}
+	default:
+		// Comments, doctypes and anything else are discarded.
+		return nil
+	}
+}
+
+// isSafeWikiURL allows relative links, fragments and http(s)/mailto URLs.
+func isSafeWikiURL(raw string) bool {
+	value := strings.TrimSpace(raw)
+	if value == "" {
+		return false
+	}
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(parsed.Scheme) {
+	case "", "http", "https", "mailto":
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,46 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeWikiHTML_StripsScriptsAndEventHandlers(t *testing.T) {
+	input := `<div class="md wiki"><p onclick="steal()">Hello <script>alert(1)</script><strong>world</strong></p>` +
+		`<img src="https://i.redd.it/x.png" onerror="alert(2)"><iframe src="https://evil.example"></iframe></div>`
+
+	sanitized := SanitizeWikiHTML(input)
+
+	assert.NotContains(t, sanitized, "<script")
+	assert.NotContains(t, sanitized, "alert(1)")
+	assert.NotContains(t, sanitized, "onclick")
+	assert.NotContains(t, sanitized, "onerror")
+	assert.NotContains(t, sanitized, "<iframe")
+	assert.Equal(t, `<div class="md wiki"><p>Hello <strong>world</strong></p><img src="https://i.redd.it/x.png"/></div>`, sanitized)
+}
+
+func TestSanitizeWikiHTML_KeepsLinksAndFormatting(t *testing.T) {
+	input := `<p><a href="https://www.reddit.com/r/golang" title="Go">Go</a> is <em>fun</em> and <code>fast</code></p>` +
+		`<ul><li><a href="/r/golang/wiki/faq">FAQ</a></li></ul><blockquote><p>quoted</p></blockquote>`
+
+	sanitized := SanitizeWikiHTML(input)
+
+	assert.Equal(t, input, sanitized)
+}
+
+func TestSanitizeWikiHTML_RemovesUnsafeURLsAndUnwrapsUnknownTags(t *testing.T) {
+	input := `<p><a href="javascript:alert(1)">click</a> <font color="red">red</font><!-- SC_OFF --></p>`
+
+	sanitized := SanitizeWikiHTML(input)
+
+	assert.Equal(t, `<p><a>click</a> red</p>`, sanitized)
+}
+
+func TestSanitizeWikiHTML_DecodesRedditEscapedHTML(t *testing.T) {
+	input := `&lt;!-- SC_OFF --&gt;&lt;div class="md wiki"&gt;&lt;p&gt;Rules &amp;amp; FAQ&lt;/p&gt;&lt;script&gt;alert(1)&lt;/script&gt;&lt;/div&gt;`
+
+	sanitized := SanitizeWikiHTML(input)
+
+	assert.Equal(t, `<div class="md wiki"><p>Rules &amp; FAQ</p></div>`, sanitized)
+}
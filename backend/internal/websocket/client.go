@@ -12,10 +12,10 @@ const (
 	// Time allowed to write a message to the peer
 	writeWait = 10 * time.Second
 
-	// Time allowed to read the next pong message from the peer
+	// Default time allowed to read the next pong message from the peer
 	pongWait = 45 * time.Second
 
-	// Send pings to peer with this period (must be less than pongWait)
+	// Default period between pings to the peer (must be less than pongWait)
 	pingPeriod = (pongWait * 9) / 10
 
 	// Maximum message size allowed from peer
@@ -40,6 +40,9 @@ type Client struct {
 
 	// Last typing event timestamp
 	lastTyping time.Time
+
+	// User IDs whose presence this connection is subscribed to; guarded by Hub.mu
+	watching map[int]struct{}
 }
 
 // Start begins read and write pumps for the client
@@ -55,10 +58,12 @@ func (c *Client) readPump() {
 		c.Conn.Close()
 	}()
 
-	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	// A connection that stops answering pings misses this deadline, which
+	// ends the loop and unregisters the client
+	c.Conn.SetReadDeadline(time.Now().Add(c.Hub.pongWait))
 	c.Conn.SetReadLimit(maxMessageSize)
 	c.Conn.SetPongHandler(func(string) error {
-		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.Conn.SetReadDeadline(time.Now().Add(c.Hub.pongWait))
 		return nil
 	})
 
@@ -116,6 +121,19 @@ func (c *Client) readPump() {
 				})
 			}
 
+		case "presence_subscribe":
+			var subscribeData struct {
+				UserIDs []int `json:"user_ids"`
+			}
+			if err := json.Unmarshal(incomingMsg.Payload, &subscribeData); err != nil {
+				log.Printf("Failed to parse presence subscription: %v", err)
+				continue
+			}
+			c.Hub.SubscribePresence(c, subscribeData.UserIDs)
+
+		case "presence_unsubscribe":
+			c.Hub.SubscribePresence(c, nil)
+
 		default:
 			log.Printf("Unknown message type: %s", incomingMsg.Type)
 		}
@@ -124,7 +142,7 @@ func (c *Client) readPump() {
 
 // writePump pumps messages from the hub to the WebSocket connection
 func (c *Client) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker(c.Hub.pingInterval)
 	defer func() {
 		ticker.Stop()
 		c.Conn.Close()
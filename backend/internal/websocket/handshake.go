@@ -0,0 +1,100 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultHelloTimeout is how long a new connection has to send its hello frame
+const DefaultHelloTimeout = 10 * time.Second
+
+// ErrInvalidHello is returned when the first frame on a connection isn't a hello
+var ErrInvalidHello = errors.New("first websocket message must be a hello")
+
+// NewOriginChecker returns an Upgrader.CheckOrigin func that accepts requests
+// without an Origin header (non-browser clients), same-origin requests, and
+// origins on the allowlist. Origins compare case-insensitively as scheme://host[:port].
+func NewOriginChecker(allowedOrigins []string) func(r *http.Request) bool {
+	allowed := make(map[string]struct{}, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		allowed[normalizeOrigin(origin)] = struct{}{}
+	}
+
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+
+		parsed, err := url.Parse(origin)
+		if err != nil || parsed.Host == "" {
+			return false
+		}
+		if strings.EqualFold(parsed.Host, r.Host) {
+			return true
+		}
+
+		_, ok := allowed[normalizeOrigin(origin)]
+		return ok
+	}
+}
+
+func normalizeOrigin(origin string) string {
+	return strings.TrimRight(strings.ToLower(strings.TrimSpace(origin)), "/")
+}
+
+// AwaitHello waits up to timeout for the connection's first frame, which must be
+// {"type":"hello"}. Connections that stay silent or open with anything else are
+// closed with a policy violation, so idle sockets can't hold a slot in the hub.
+// The read deadline is cleared again on success.
+func AwaitHello(conn *websocket.Conn, timeout time.Duration) error {
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(timeout))
+
+	err := readHello(conn)
+	if err != nil {
+		reason := "expected hello"
+		var netErr interface{ Timeout() bool }
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			reason = "hello timeout"
+		}
+		conn.WriteControl(
+			websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason),
+			time.Now().Add(writeWait),
+		)
+		conn.Close()
+		return err
+	}
+
+	conn.SetReadDeadline(time.Time{})
+	return nil
+}
+
+func readHello(conn *websocket.Conn) error {
+	messageType, data, err := conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if messageType != websocket.TextMessage {
+		return ErrInvalidHello
+	}
+
+	var hello struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &hello); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidHello, err)
+	}
+	if hello.Type != "hello" {
+		return ErrInvalidHello
+	}
+	return nil
+}
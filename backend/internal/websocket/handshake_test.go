@@ -0,0 +1,116 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helloServer upgrades every request and reports AwaitHello's result on the channel
+func helloServer(t *testing.T, timeout time.Duration) (string, <-chan error) {
+	t.Helper()
+	results := make(chan error, 1)
+	upgrader := websocket.Upgrader{CheckOrigin: NewOriginChecker(nil)}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			results <- err
+			return
+		}
+		err = AwaitHello(conn, timeout)
+		if err == nil {
+			conn.Close()
+		}
+		results <- err
+	}))
+	t.Cleanup(ts.Close)
+	return "ws" + strings.TrimPrefix(ts.URL, "http"), results
+}
+
+func TestAwaitHello_ReapsSilentConnection(t *testing.T) {
+	url, results := helloServer(t, 100*time.Millisecond)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	// Never send a hello; the server should give up and close the socket
+	start := time.Now()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = conn.ReadMessage()
+	require.Error(t, err)
+	assert.True(t, websocket.IsCloseError(err, websocket.ClosePolicyViolation), "got %v", err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	select {
+	case err := <-results:
+		require.Error(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("server never reported the stale connection")
+	}
+}
+
+func TestAwaitHello_RejectsOtherFirstMessage(t *testing.T) {
+	url, results := helloServer(t, time.Second)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "typing"}))
+
+	select {
+	case err := <-results:
+		assert.ErrorIs(t, err, ErrInvalidHello)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server never rejected the connection")
+	}
+}
+
+func TestAwaitHello_AcceptsHello(t *testing.T) {
+	url, results := helloServer(t, time.Second)
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.NoError(t, conn.WriteJSON(map[string]string{"type": "hello"}))
+
+	select {
+	case err := <-results:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server never accepted the hello")
+	}
+}
+
+func TestNewOriginChecker(t *testing.T) {
+	check := NewOriginChecker([]string{"https://app.example.com", "http://localhost:5173/"})
+
+	tests := []struct {
+		origin string
+		host   string
+		want   bool
+	}{
+		{"", "api.example.com", true},
+		{"https://app.example.com", "api.example.com", true},
+		{"HTTPS://APP.EXAMPLE.COM", "api.example.com", true},
+		{"http://localhost:5173", "localhost:8080", true},
+		{"https://api.example.com", "api.example.com", true},
+		{"https://evil.example.com", "api.example.com", false},
+		{"http://localhost:3000", "localhost:8080", false},
+		{"null", "api.example.com", false},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/ws", nil)
+		r.Host = tt.host
+		if tt.origin != "" {
+			r.Header.Set("Origin", tt.origin)
+		}
+		assert.Equal(t, tt.want, check(r), "origin %q on host %q", tt.origin, tt.host)
+	}
+}
@@ -3,12 +3,17 @@ package websocket
 import (
 	"log"
 	"sync"
+	"time"
 )
 
 // Hub maintains the set of active clients and broadcasts messages
 type Hub struct {
-	// Registered clients mapped by user ID
-	clients map[int]*Client
+	// Registered connections grouped by user ID; a user can be connected from
+	// several tabs or devices at once
+	clients map[int]map[*Client]struct{}
+
+	// Connections subscribed to each user's presence, keyed by watched user ID
+	watchers map[int]map[*Client]struct{}
 
 	// Inbound messages from clients
 	broadcast chan *Message
@@ -21,6 +26,16 @@ type Hub struct {
 
 	// Mutex to protect clients map
 	mu sync.RWMutex
+
+	// Active slideshow viewers: slideshow ID -> user ID -> last seen
+	slideshowViewers map[int]map[int]time.Time
+	viewersMu        sync.Mutex
+	onViewerLeft     SlideshowViewerLeftFunc
+
+	// How often clients are pinged, and how long they have to answer before
+	// the connection is treated as dead
+	pingInterval time.Duration
+	pongWait     time.Duration
 }
 
 // Message represents a WebSocket message to broadcast
@@ -33,61 +48,104 @@ type Message struct {
 // NewHub creates a new WebSocket hub
 func NewHub() *Hub {
 	return &Hub{
-		clients:    make(map[int]*Client),
-		broadcast:  make(chan *Message, 256),
-		register:   make(chan *Client),
-		unregister: make(chan *Client),
+		clients:          make(map[int]map[*Client]struct{}),
+		watchers:         make(map[int]map[*Client]struct{}),
+		slideshowViewers: make(map[int]map[int]time.Time),
+		broadcast:        make(chan *Message, 256),
+		register:         make(chan *Client),
+		unregister:       make(chan *Client),
+		pingInterval:     pingPeriod,
+		pongWait:         pongWait,
 	}
 }
 
+// SetPingInterval changes how often clients are pinged. A client that hasn't
+// answered within 10/9 of the interval is disconnected and unregistered. Call
+// it before any client connects.
+func (h *Hub) SetPingInterval(interval time.Duration) {
+	h.pingInterval = interval
+	h.pongWait = interval * 10 / 9
+}
+
 // Run starts the hub
 func (h *Hub) Run() {
+	sweep := time.NewTicker(slideshowViewerSweepInterval)
+	defer sweep.Stop()
+
 	for {
 		select {
+		case <-sweep.C:
+			h.expireSlideshowViewers()
+
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client.UserID] = client
+			conns, online := h.clients[client.UserID]
+			if !online {
+				conns = make(map[*Client]struct{})
+				h.clients[client.UserID] = conns
+			}
+			conns[client] = struct{}{}
 			h.mu.Unlock()
 			log.Printf("Client registered: user_id=%d", client.UserID)
 
-			// Broadcast user_online event to all other connected users
-			h.broadcastUserStatus(client.UserID, true)
+			// Tell presence subscribers the user came online
+			if !online {
+				h.broadcastUserStatus(client.UserID, true)
+			}
 
 		case client := <-h.unregister:
-			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
-				close(client.Send)
+			if h.removeClient(client) {
 				log.Printf("Client unregistered: user_id=%d", client.UserID)
-
-				// Broadcast user_offline event to all other connected users
-				h.mu.Unlock()
-				h.broadcastUserStatus(client.UserID, false)
-			} else {
-				h.mu.Unlock()
 			}
 
 		case message := <-h.broadcast:
 			h.mu.RLock()
-			client, ok := h.clients[message.RecipientID]
+			var recipients []*Client
+			for client := range h.clients[message.RecipientID] {
+				recipients = append(recipients, client)
+			}
 			h.mu.RUnlock()
 
-			if ok {
+			for _, client := range recipients {
 				select {
 				case client.Send <- message:
 					// Message sent successfully
 				default:
-					// Client's send channel is full, close it
-					h.mu.Lock()
-					close(client.Send)
-					delete(h.clients, client.UserID)
-					h.mu.Unlock()
+					// Client's send channel is full, drop the connection
+					h.removeClient(client)
 				}
 			}
 		}
 	}
 }
 
+// removeClient drops one connection and closes its send channel. When it was
+// the user's last connection, the user is reported offline and stops viewing
+// any slideshow. Returns false if the client was already removed.
+func (h *Hub) removeClient(client *Client) bool {
+	h.mu.Lock()
+	conns := h.clients[client.UserID]
+	if _, ok := conns[client]; !ok {
+		h.mu.Unlock()
+		return false
+	}
+	delete(conns, client)
+	close(client.Send)
+	h.unwatchAll(client)
+	lastConnection := len(conns) == 0
+	if lastConnection {
+		delete(h.clients, client.UserID)
+	}
+	h.mu.Unlock()
+
+	// Tell presence subscribers the user went offline
+	if lastConnection {
+		h.broadcastUserStatus(client.UserID, false)
+		h.leaveAllSlideshows(client.UserID)
+	}
+	return true
+}
+
 // Broadcast sends a message to a specific user
 func (h *Hub) Broadcast(message *Message) {
 	h.broadcast <- message
@@ -101,6 +159,13 @@ func (h *Hub) IsUserOnline(userID int) bool {
 	return ok
 }
 
+// ConnectionCount returns how many connections a user currently has open
+func (h *Hub) ConnectionCount(userID int) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients[userID])
+}
+
 // Register enqueues a client to be registered with the hub
 func (h *Hub) Register(client *Client) {
 	h.register <- client
@@ -129,31 +194,70 @@ func (h *Hub) BroadcastToUsers(userIDs []int, msgType string, payload interface{
 	}
 }
 
-// broadcastUserStatus broadcasts user online/offline status to all connected users
+// MaxPresenceSubscriptions caps how many users one connection can watch
+const MaxPresenceSubscriptions = 100
+
+// SubscribePresence replaces the set of users whose presence a connection
+// watches. The connection then receives a presence_changed event whenever one
+// of them comes online (first connection) or goes offline (last connection).
+// Lists longer than MaxPresenceSubscriptions are truncated.
+func (h *Hub) SubscribePresence(client *Client, userIDs []int) {
+	if len(userIDs) > MaxPresenceSubscriptions {
+		userIDs = userIDs[:MaxPresenceSubscriptions]
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// A client that has already been unregistered must not be re-added, since
+	// its send channel is closed
+	if _, ok := h.clients[client.UserID][client]; !ok {
+		return
+	}
+
+	h.unwatchAll(client)
+	client.watching = make(map[int]struct{}, len(userIDs))
+	for _, userID := range userIDs {
+		watchers, ok := h.watchers[userID]
+		if !ok {
+			watchers = make(map[*Client]struct{})
+			h.watchers[userID] = watchers
+		}
+		watchers[client] = struct{}{}
+		client.watching[userID] = struct{}{}
+	}
+}
+
+// unwatchAll drops all of a client's presence subscriptions. Callers hold h.mu.
+func (h *Hub) unwatchAll(client *Client) {
+	for userID := range client.watching {
+		delete(h.watchers[userID], client)
+		if len(h.watchers[userID]) == 0 {
+			delete(h.watchers, userID)
+		}
+	}
+	client.watching = nil
+}
+
+// broadcastUserStatus sends a presence_changed event to every connection
+// subscribed to the user's presence
 func (h *Hub) broadcastUserStatus(userID int, isOnline bool) {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
-	eventType := "user_offline"
-	if isOnline {
-		eventType = "user_online"
-	}
-
-	// Broadcast to all connected users except the user whose status changed
-	for id, client := range h.clients {
-		if id != userID {
-			select {
-			case client.Send <- &Message{
-				RecipientID: id,
-				Type:        eventType,
-				Payload: map[string]interface{}{
-					"user_id": userID,
-				},
-			}:
-				// Message sent successfully
-			default:
-				// Client's send channel is full, skip
-			}
+	for client := range h.watchers[userID] {
+		select {
+		case client.Send <- &Message{
+			RecipientID: client.UserID,
+			Type:        "presence_changed",
+			Payload: map[string]interface{}{
+				"user_id": userID,
+				"online":  isOnline,
+			},
+		}:
+			// Message sent successfully
+		default:
+			// Client's send channel is full, skip
 		}
 	}
 }
@@ -0,0 +1,195 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startTestHub runs a hub with the given ping interval behind a server that
+// registers each connection as the user named in ?user_id=
+func startTestHub(t *testing.T, pingInterval time.Duration) (*Hub, func(userID int) *websocket.Conn) {
+	t.Helper()
+	hub := NewHub()
+	hub.SetPingInterval(pingInterval)
+	go hub.Run()
+
+	upgrader := websocket.Upgrader{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := strconv.Atoi(r.URL.Query().Get("user_id"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		client := &Client{Hub: hub, Conn: conn, Send: make(chan *Message, 16), UserID: userID}
+		hub.Register(client)
+		client.Start()
+	}))
+	t.Cleanup(ts.Close)
+
+	dial := func(userID int) *websocket.Conn {
+		url := "ws" + strings.TrimPrefix(ts.URL, "http") + "?user_id=" + strconv.Itoa(userID)
+		conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+		require.NoError(t, err)
+		t.Cleanup(func() { conn.Close() })
+		require.Eventually(t, func() bool { return hub.IsUserOnline(userID) }, time.Second, 5*time.Millisecond)
+		return conn
+	}
+	return hub, dial
+}
+
+// keepAlive reads from conn in the background, which answers the server's pings
+func keepAlive(conn *websocket.Conn) {
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+}
+
+func TestHub_ReapsConnectionThatNeverPongs(t *testing.T) {
+	hub, dial := startTestHub(t, 50*time.Millisecond)
+
+	// Never reading means pings are never answered
+	dial(1)
+	keepAlive(dial(2))
+
+	require.Eventually(t, func() bool { return !hub.IsUserOnline(1) }, time.Second, 10*time.Millisecond,
+		"a silent connection should be unregistered after the pong deadline")
+
+	// A responsive connection outlives several ping intervals
+	time.Sleep(200 * time.Millisecond)
+	assert.True(t, hub.IsUserOnline(2))
+	assert.Equal(t, []int{2}, hub.GetOnlineUsers())
+}
+
+func TestHub_UserStaysOnlineUntilLastConnectionDrops(t *testing.T) {
+	hub, dial := startTestHub(t, 50*time.Millisecond)
+
+	alive := dial(3)
+	keepAlive(alive)
+	dial(3)
+	require.Eventually(t, func() bool { return hub.ConnectionCount(3) == 2 }, time.Second, 5*time.Millisecond)
+
+	// The dead tab is reaped but the user is still online through the live one
+	require.Eventually(t, func() bool { return hub.ConnectionCount(3) == 1 }, time.Second, 10*time.Millisecond)
+	assert.True(t, hub.IsUserOnline(3))
+
+	alive.Close()
+	require.Eventually(t, func() bool { return !hub.IsUserOnline(3) }, time.Second, 10*time.Millisecond)
+}
+
+func TestHub_BroadcastFansOutToRemainingConnections(t *testing.T) {
+	hub, dial := startTestHub(t, time.Minute)
+
+	first := dial(4)
+	second := dial(4)
+	require.Eventually(t, func() bool { return hub.ConnectionCount(4) == 2 }, time.Second, 5*time.Millisecond)
+
+	readType := func(conn *websocket.Conn) string {
+		t.Helper()
+		for {
+			var msg Message
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			require.NoError(t, conn.ReadJSON(&msg))
+			if msg.Type != "presence_changed" {
+				return msg.Type
+			}
+		}
+	}
+
+	hub.Broadcast(&Message{RecipientID: 4, Type: "new_message"})
+	assert.Equal(t, "new_message", readType(first))
+	assert.Equal(t, "new_message", readType(second))
+
+	// Closing one tab leaves the user online and reachable on the other
+	first.Close()
+	require.Eventually(t, func() bool { return hub.ConnectionCount(4) == 1 }, time.Second, 5*time.Millisecond)
+	assert.True(t, hub.IsUserOnline(4))
+
+	hub.Broadcast(&Message{RecipientID: 4, Type: "message_read"})
+	assert.Equal(t, "message_read", readType(second))
+}
+
+func (h *Hub) watcherCount(userID int) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.watchers[userID])
+}
+
+func TestHub_PresenceChangedSentOnlyToSubscribers(t *testing.T) {
+	hub, dial := startTestHub(t, time.Minute)
+
+	const watcherID, friendID, bystanderID = 5, 6, 7
+	watcher := dial(watcherID)
+	bystander := dial(bystanderID)
+	require.NoError(t, watcher.WriteJSON(map[string]interface{}{
+		"type":    "presence_subscribe",
+		"payload": map[string]interface{}{"user_ids": []int{friendID}},
+	}))
+	require.Eventually(t, func() bool { return hub.watcherCount(friendID) == 1 }, time.Second, 5*time.Millisecond)
+
+	readPresence := func() map[string]interface{} {
+		t.Helper()
+		var msg Message
+		watcher.SetReadDeadline(time.Now().Add(time.Second))
+		require.NoError(t, watcher.ReadJSON(&msg))
+		require.Equal(t, "presence_changed", msg.Type)
+		return msg.Payload.(map[string]interface{})
+	}
+
+	friend := dial(friendID)
+	payload := readPresence()
+	assert.EqualValues(t, friendID, payload["user_id"])
+	assert.Equal(t, true, payload["online"])
+
+	// A second tab doesn't change presence, closing the last one does
+	second := dial(friendID)
+	second.Close()
+	friend.Close()
+	payload = readPresence()
+	assert.EqualValues(t, friendID, payload["user_id"])
+	assert.Equal(t, false, payload["online"])
+
+	// Users who didn't subscribe hear nothing
+	bystander.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err := bystander.ReadMessage()
+	var netErr interface{ Timeout() bool }
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+
+	// Subscriptions are dropped with the watcher's connection
+	watcher.Close()
+	require.Eventually(t, func() bool { return hub.watcherCount(friendID) == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestHub_PresenceUnsubscribeStopsEvents(t *testing.T) {
+	hub, dial := startTestHub(t, time.Minute)
+
+	const watcherID, friendID = 5, 6
+	watcher := dial(watcherID)
+	require.NoError(t, watcher.WriteJSON(map[string]interface{}{
+		"type":    "presence_subscribe",
+		"payload": map[string]interface{}{"user_ids": []int{friendID}},
+	}))
+	require.Eventually(t, func() bool { return hub.watcherCount(friendID) == 1 }, time.Second, 5*time.Millisecond)
+
+	require.NoError(t, watcher.WriteJSON(map[string]interface{}{"type": "presence_unsubscribe"}))
+	require.Eventually(t, func() bool { return hub.watcherCount(friendID) == 0 }, time.Second, 5*time.Millisecond)
+
+	dial(friendID)
+	watcher.SetReadDeadline(time.Now().Add(100 * time.Millisecond))
+	_, _, err := watcher.ReadMessage()
+	var netErr interface{ Timeout() bool }
+	require.ErrorAs(t, err, &netErr)
+	assert.True(t, netErr.Timeout())
+}
@@ -0,0 +1,163 @@
+package websocket
+
+import (
+	"sort"
+	"time"
+)
+
+// SlideshowViewerTimeout is how long a viewer stays listed without refreshing
+// its join; clients re-join periodically as a heartbeat
+const SlideshowViewerTimeout = 90 * time.Second
+
+// slideshowViewerSweepInterval is how often the hub drops timed-out viewers
+const slideshowViewerSweepInterval = 30 * time.Second
+
+// SlideshowViewerLeftFunc is told about viewers that stop watching without
+// calling LeaveSlideshow: their heartbeat timed out or their last websocket
+// connection closed. It runs on its own goroutine.
+type SlideshowViewerLeftFunc func(slideshowID, userID int)
+
+// SlideshowViewer is a user currently watching a slideshow
+type SlideshowViewer struct {
+	UserID   int       `json:"user_id"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// JoinSlideshow marks a user as viewing a slideshow, or refreshes their
+// last-seen time if they already are. Returns true if the user wasn't an
+// active viewer before.
+func (h *Hub) JoinSlideshow(slideshowID, userID int) bool {
+	h.viewersMu.Lock()
+	defer h.viewersMu.Unlock()
+
+	now := time.Now()
+	viewers, ok := h.slideshowViewers[slideshowID]
+	if !ok {
+		viewers = make(map[int]time.Time)
+		h.slideshowViewers[slideshowID] = viewers
+	}
+	lastSeen, seen := viewers[userID]
+	viewers[userID] = now
+	return !seen || now.Sub(lastSeen) > SlideshowViewerTimeout
+}
+
+// LeaveSlideshow removes a user from a slideshow's viewers. Returns false if
+// the user wasn't an active viewer.
+func (h *Hub) LeaveSlideshow(slideshowID, userID int) bool {
+	h.viewersMu.Lock()
+	defer h.viewersMu.Unlock()
+
+	viewers := h.slideshowViewers[slideshowID]
+	lastSeen, ok := viewers[userID]
+	if !ok {
+		return false
+	}
+	delete(viewers, userID)
+	if len(viewers) == 0 {
+		delete(h.slideshowViewers, slideshowID)
+	}
+	return time.Since(lastSeen) <= SlideshowViewerTimeout
+}
+
+// SlideshowViewers returns a slideshow's active viewers ordered by user ID.
+// Viewers not seen within SlideshowViewerTimeout are dropped and reported to
+// the OnSlideshowViewerLeft callback.
+func (h *Hub) SlideshowViewers(slideshowID int) []SlideshowViewer {
+	h.viewersMu.Lock()
+
+	var expired []int
+	viewers := h.slideshowViewers[slideshowID]
+	result := make([]SlideshowViewer, 0, len(viewers))
+	for userID, lastSeen := range viewers {
+		if time.Since(lastSeen) > SlideshowViewerTimeout {
+			delete(viewers, userID)
+			expired = append(expired, userID)
+			continue
+		}
+		result = append(result, SlideshowViewer{UserID: userID, LastSeen: lastSeen})
+	}
+	if len(viewers) == 0 {
+		delete(h.slideshowViewers, slideshowID)
+	}
+	onLeft := h.onViewerLeft
+	h.viewersMu.Unlock()
+
+	for _, userID := range expired {
+		reportViewerLeft(onLeft, slideshowID, userID)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].UserID < result[j].UserID })
+	return result
+}
+
+// OnSlideshowViewerLeft registers the callback told about viewers who time
+// out or disconnect. Call it before the hub starts running.
+func (h *Hub) OnSlideshowViewerLeft(fn SlideshowViewerLeftFunc) {
+	h.viewersMu.Lock()
+	defer h.viewersMu.Unlock()
+	h.onViewerLeft = fn
+}
+
+// expireSlideshowViewers drops every viewer not seen within
+// SlideshowViewerTimeout and reports them as having left
+func (h *Hub) expireSlideshowViewers() {
+	h.viewersMu.Lock()
+	expired := make(map[int][]int)
+	for slideshowID, viewers := range h.slideshowViewers {
+		for userID, lastSeen := range viewers {
+			if time.Since(lastSeen) > SlideshowViewerTimeout {
+				delete(viewers, userID)
+				expired[slideshowID] = append(expired[slideshowID], userID)
+			}
+		}
+		if len(viewers) == 0 {
+			delete(h.slideshowViewers, slideshowID)
+		}
+	}
+	onLeft := h.onViewerLeft
+	h.viewersMu.Unlock()
+
+	for slideshowID, userIDs := range expired {
+		for _, userID := range userIDs {
+			reportViewerLeft(onLeft, slideshowID, userID)
+		}
+	}
+}
+
+// leaveAllSlideshows removes a disconnected user from every slideshow they
+// were watching and reports each departure
+func (h *Hub) leaveAllSlideshows(userID int) {
+	h.viewersMu.Lock()
+	var left []int
+	for slideshowID, viewers := range h.slideshowViewers {
+		if _, ok := viewers[userID]; !ok {
+			continue
+		}
+		delete(viewers, userID)
+		left = append(left, slideshowID)
+		if len(viewers) == 0 {
+			delete(h.slideshowViewers, slideshowID)
+		}
+	}
+	onLeft := h.onViewerLeft
+	h.viewersMu.Unlock()
+
+	for _, slideshowID := range left {
+		reportViewerLeft(onLeft, slideshowID, userID)
+	}
+}
+
+// reportViewerLeft runs the callback off the caller's goroutine, since the hub
+// calls it from Run and the callback typically broadcasts through the hub
+func reportViewerLeft(onLeft SlideshowViewerLeftFunc, slideshowID, userID int) {
+	if onLeft != nil {
+		go onLeft(slideshowID, userID)
+	}
+}
+
+// ClearSlideshowViewers forgets every viewer of a slideshow that has ended
+func (h *Hub) ClearSlideshowViewers(slideshowID int) {
+	h.viewersMu.Lock()
+	defer h.viewersMu.Unlock()
+	delete(h.slideshowViewers, slideshowID)
+}
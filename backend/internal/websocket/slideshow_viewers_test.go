@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHub_SlideshowViewers(t *testing.T) {
+	hub := NewHub()
+
+	assert.True(t, hub.JoinSlideshow(7, 2), "first join is a new viewer")
+	assert.True(t, hub.JoinSlideshow(7, 1))
+	assert.False(t, hub.JoinSlideshow(7, 2), "re-joining only refreshes last seen")
+	assert.True(t, hub.JoinSlideshow(8, 3))
+
+	viewers := hub.SlideshowViewers(7)
+	if assert.Len(t, viewers, 2) {
+		assert.Equal(t, 1, viewers[0].UserID)
+		assert.Equal(t, 2, viewers[1].UserID)
+	}
+
+	assert.True(t, hub.LeaveSlideshow(7, 2))
+	assert.False(t, hub.LeaveSlideshow(7, 2), "leaving twice reports nothing")
+	assert.Equal(t, []SlideshowViewer{{UserID: 1, LastSeen: viewers[0].LastSeen}}, hub.SlideshowViewers(7))
+
+	hub.ClearSlideshowViewers(7)
+	assert.Empty(t, hub.SlideshowViewers(7))
+	assert.Len(t, hub.SlideshowViewers(8), 1, "other slideshows are untouched")
+}
+
+func TestHub_StaleSlideshowViewersExpire(t *testing.T) {
+	hub := NewHub()
+	hub.JoinSlideshow(7, 1)
+	hub.JoinSlideshow(7, 2)
+
+	// Backdate user 1 past the timeout
+	hub.slideshowViewers[7][1] = time.Now().Add(-SlideshowViewerTimeout - time.Second)
+
+	viewers := hub.SlideshowViewers(7)
+	if assert.Len(t, viewers, 1) {
+		assert.Equal(t, 2, viewers[0].UserID)
+	}
+	assert.True(t, hub.JoinSlideshow(7, 1), "a viewer returning after expiry is new again")
+}
+
+// recordViewersLeft registers a callback on hub that collects "slideshow:user" departures
+func recordViewersLeft(hub *Hub) func() []string {
+	var mu sync.Mutex
+	var left []string
+	hub.OnSlideshowViewerLeft(func(slideshowID, userID int) {
+		mu.Lock()
+		defer mu.Unlock()
+		left = append(left, fmt.Sprintf("%d:%d", slideshowID, userID))
+	})
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), left...)
+	}
+}
+
+func TestHub_ExpiredSlideshowViewersAreReportedLeft(t *testing.T) {
+	hub := NewHub()
+	left := recordViewersLeft(hub)
+	hub.JoinSlideshow(7, 1)
+	hub.JoinSlideshow(7, 2)
+	hub.JoinSlideshow(8, 3)
+
+	hub.slideshowViewers[7][1] = time.Now().Add(-SlideshowViewerTimeout - time.Second)
+	hub.slideshowViewers[8][3] = time.Now().Add(-SlideshowViewerTimeout - time.Second)
+
+	// Listing a slideshow reports its own expired viewers; the sweep gets the rest
+	hub.SlideshowViewers(7)
+	require.Eventually(t, func() bool { return len(left()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"7:1"}, left())
+
+	hub.expireSlideshowViewers()
+	require.Eventually(t, func() bool { return len(left()) == 2 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "8:3", left()[1])
+	assert.Len(t, hub.SlideshowViewers(7), 1, "active viewers are kept")
+
+	// Explicit leaves are reported by the caller, not the callback
+	hub.LeaveSlideshow(7, 2)
+	time.Sleep(20 * time.Millisecond)
+	assert.Len(t, left(), 2)
+}
+
+func TestHub_LastDisconnectLeavesSlideshows(t *testing.T) {
+	hub, dial := startTestHub(t, time.Minute)
+	left := recordViewersLeft(hub)
+
+	first := dial(5)
+	second := dial(5)
+	require.Eventually(t, func() bool { return hub.ConnectionCount(5) == 2 }, time.Second, 5*time.Millisecond)
+	hub.JoinSlideshow(9, 5)
+
+	first.Close()
+	require.Eventually(t, func() bool { return hub.ConnectionCount(5) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Len(t, hub.SlideshowViewers(9), 1, "another open tab keeps the user watching")
+
+	second.Close()
+	require.Eventually(t, func() bool { return len(left()) == 1 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, []string{"9:5"}, left())
+	assert.Empty(t, hub.SlideshowViewers(9))
+}
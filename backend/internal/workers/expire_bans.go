@@ -0,0 +1,89 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/omninudge/backend/internal/models"
+)
+
+// expiredBanRepository is the subset of HubBanRepository the ban expiry worker needs
+type expiredBanRepository interface {
+	ExpireDueBans(ctx context.Context) ([]*models.HubBan, error)
+}
+
+// modLogWriter is the subset of ModLogRepository the ban expiry worker needs
+type modLogWriter interface {
+	Log(ctx context.Context, hubID, moderatorID int, action, targetType string, targetID int, details models.JSONB) (*models.ModLog, error)
+}
+
+// ExpireBansWorker periodically lifts temporary hub bans once their expires_at
+// has passed and records each one in the hub's mod log.
+type ExpireBansWorker struct {
+	bans     expiredBanRepository
+	modLog   modLogWriter
+	interval time.Duration
+}
+
+// NewExpireBansWorker creates a worker that checks for due bans every interval
+func NewExpireBansWorker(bans expiredBanRepository, modLog modLogWriter, interval time.Duration) *ExpireBansWorker {
+	return &ExpireBansWorker{
+		bans:     bans,
+		modLog:   modLog,
+		interval: interval,
+	}
+}
+
+// RunOnce lifts every due ban a single time and returns how many were lifted
+func (w *ExpireBansWorker) RunOnce(ctx context.Context) (int, error) {
+	expired, err := w.bans.ExpireDueBans(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, ban := range expired {
+		// No moderator acts on an expiry, so attribute it to the one who issued the ban
+		_, err := w.modLog.Log(ctx, ban.HubID, ban.BannedBy, "ban_expired", "user", ban.UserID, models.JSONB{
+			"reason":     ban.Reason,
+			"automatic":  true,
+			"expires_at": ban.ExpiresAt,
+		})
+		if err != nil {
+			log.Printf("Error logging expiry of ban %d: %v", ban.ID, err)
+		}
+	}
+
+	return len(expired), nil
+}
+
+// Run lifts due bans on startup and then every interval until ctx is cancelled
+func (w *ExpireBansWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("Ban expiry worker started (%s interval)", w.interval)
+
+	w.expire(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Ban expiry worker stopped")
+			return
+		case <-ticker.C:
+			w.expire(ctx)
+		}
+	}
+}
+
+func (w *ExpireBansWorker) expire(ctx context.Context) {
+	expired, err := w.RunOnce(ctx)
+	if err != nil {
+		log.Printf("Error expiring hub bans: %v", err)
+		return
+	}
+	if expired > 0 {
+		log.Printf("Lifted %d expired hub bans", expired)
+	}
+}
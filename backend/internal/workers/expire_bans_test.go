@@ -0,0 +1,64 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpireBansWorker_LiftsExpiredTemporaryBans(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	suffix := time.Now().UnixNano()
+	mod := &models.User{Username: fmt.Sprintf("banexpmod_%d", suffix), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, mod))
+	expiredUser := &models.User{Username: fmt.Sprintf("banexpired_%d", suffix), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, expiredUser))
+	activeUser := &models.User{Username: fmt.Sprintf("banactive_%d", suffix), PasswordHash: "test_hash"}
+	require.NoError(t, userRepo.Create(ctx, activeUser))
+
+	hub := &models.Hub{Name: fmt.Sprintf("banexphub_%d", suffix), CreatedBy: &mod.ID}
+	require.NoError(t, models.NewHubRepository(db.Pool).Create(ctx, hub))
+
+	banRepo := models.NewHubBanRepository(db.Pool)
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(24 * time.Hour)
+	_, err = banRepo.BanUser(ctx, hub.ID, expiredUser.ID, mod.ID, "cool off", "", "temporary", &past)
+	require.NoError(t, err)
+	_, err = banRepo.BanUser(ctx, hub.ID, activeUser.ID, mod.ID, "cool off", "", "temporary", &future)
+	require.NoError(t, err)
+
+	modLogRepo := models.NewModLogRepository(db.Pool)
+	worker := NewExpireBansWorker(banRepo, modLogRepo, time.Minute)
+	lifted, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, lifted, 1)
+
+	ban, err := banRepo.GetBanByUser(ctx, hub.ID, expiredUser.ID)
+	require.NoError(t, err)
+	assert.Nil(t, ban, "expired ban should be removed")
+
+	ban, err = banRepo.GetBanByUser(ctx, hub.ID, activeUser.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, ban, "ban that hasn't expired yet should be kept")
+
+	logs, err := modLogRepo.GetByHub(ctx, hub.ID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	assert.Equal(t, "ban_expired", logs[0].Action)
+	assert.Equal(t, expiredUser.ID, logs[0].TargetID)
+	assert.Equal(t, mod.ID, logs[0].ModeratorID)
+	assert.Equal(t, true, logs[0].Details["automatic"])
+}
@@ -0,0 +1,66 @@
+package workers
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// hotScoreRepository is the subset of PlatformPostRepository the hot score worker needs
+type hotScoreRepository interface {
+	RecomputeHotScores(ctx context.Context, sinceHours int) (int64, error)
+}
+
+// HotScoreWorker periodically recomputes hot_score for recent platform posts so
+// "hot" feeds stay in step with vote changes the trigger may have missed.
+type HotScoreWorker struct {
+	repo        hotScoreRepository
+	interval    time.Duration
+	windowHours int
+}
+
+// NewHotScoreWorker creates a worker that refreshes posts created in the last
+// windowHours hours every interval.
+func NewHotScoreWorker(repo hotScoreRepository, interval time.Duration, windowHours int) *HotScoreWorker {
+	return &HotScoreWorker{
+		repo:        repo,
+		interval:    interval,
+		windowHours: windowHours,
+	}
+}
+
+// RunOnce recomputes hot scores a single time and returns the number of posts updated
+func (w *HotScoreWorker) RunOnce(ctx context.Context) (int64, error) {
+	return w.repo.RecomputeHotScores(ctx, w.windowHours)
+}
+
+// Run recomputes hot scores on startup and then every interval until ctx is cancelled
+func (w *HotScoreWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	log.Printf("Hot score worker started (%s interval, %dh window)", w.interval, w.windowHours)
+
+	w.recompute(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Hot score worker stopped")
+			return
+		case <-ticker.C:
+			w.recompute(ctx)
+		}
+	}
+}
+
+func (w *HotScoreWorker) recompute(ctx context.Context) {
+	updated, err := w.RunOnce(ctx)
+	if err != nil {
+		log.Printf("Error recomputing hot scores: %v", err)
+		return
+	}
+	if updated > 0 {
+		log.Printf("Recomputed hot scores for %d posts", updated)
+	}
+}
@@ -0,0 +1,97 @@
+package workers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/omninudge/backend/internal/database"
+	"github.com/omninudge/backend/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectedHotScore mirrors the calculate_hot_score SQL function
+func expectedHotScore(score int, createdAt time.Time) float64 {
+	epoch := time.Date(2005, 12, 8, 7, 46, 43, 0, time.UTC)
+	order := math.Log10(math.Max(math.Abs(float64(score)), 1))
+	sign := 0.0
+	if score > 0 {
+		sign = 1
+	} else if score < 0 {
+		sign = -1
+	}
+	return order + sign*createdAt.Sub(epoch).Seconds()/45000.0
+}
+
+func TestHotScoreWorker_RecomputesRecentPosts(t *testing.T) {
+	db, err := database.NewTest()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx := context.Background()
+	require.NoError(t, db.Migrate(ctx))
+
+	userRepo := models.NewUserRepository(db.Pool)
+	user := &models.User{
+		Username:     fmt.Sprintf("hotscore_%d", time.Now().UnixNano()),
+		PasswordHash: "test_hash",
+	}
+	require.NoError(t, userRepo.Create(ctx, user))
+
+	hubRepo := models.NewHubRepository(db.Pool)
+	hubDesc := "Hot score hub"
+	hub := &models.Hub{
+		Name:        fmt.Sprintf("hothub_%d", time.Now().UnixNano()),
+		Description: &hubDesc,
+		CreatedBy:   &user.ID,
+	}
+	require.NoError(t, hubRepo.Create(ctx, hub))
+
+	postRepo := models.NewPlatformPostRepository(db.Pool)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	fixtures := []struct {
+		score     int
+		createdAt time.Time
+		inWindow  bool
+	}{
+		{score: 150, createdAt: now.Add(-2 * time.Hour), inWindow: true},
+		{score: 1, createdAt: now.Add(-30 * time.Minute), inWindow: true},
+		{score: -12, createdAt: now.Add(-24 * time.Hour), inWindow: true},
+		{score: 0, createdAt: now.Add(-5 * time.Hour), inWindow: true},
+		{score: 900, createdAt: now.Add(-10 * 24 * time.Hour), inWindow: false},
+	}
+
+	ids := make([]int, len(fixtures))
+	for i, fixture := range fixtures {
+		post := &models.PlatformPost{AuthorID: user.ID, HubID: &hub.ID, Title: fmt.Sprintf("Hot score post %d", i)}
+		require.NoError(t, postRepo.Create(ctx, post))
+		ids[i] = post.ID
+
+		_, err := db.Pool.Exec(ctx, `UPDATE platform_posts SET score = $2 WHERE id = $1`, post.ID, fixture.score)
+		require.NoError(t, err)
+		require.NoError(t, postRepo.UpdateCreatedAt(ctx, post.ID, fixture.createdAt))
+		// Simulate drift: hot_score is not covered by the update trigger
+		_, err = db.Pool.Exec(ctx, `UPDATE platform_posts SET hot_score = 0 WHERE id = $1`, post.ID)
+		require.NoError(t, err)
+	}
+
+	worker := NewHotScoreWorker(postRepo, time.Minute, 72)
+	updated, err := worker.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, updated, int64(3), "every drifted in-window post with a non-zero score should be rewritten")
+
+	for i, fixture := range fixtures {
+		var hotScore float64
+		require.NoError(t, db.Pool.QueryRow(ctx, `SELECT hot_score FROM platform_posts WHERE id = $1`, ids[i]).Scan(&hotScore))
+
+		if fixture.inWindow {
+			assert.InDelta(t, expectedHotScore(fixture.score, fixture.createdAt), hotScore, 1e-6, "post %d", i)
+		} else {
+			assert.Equal(t, 0.0, hotScore, "post %d is outside the window and should be left alone", i)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"log"
 	"time"
 
+	"github.com/omninudge/backend/internal/models"
 	"github.com/omninudge/backend/internal/services"
 )
 
@@ -12,6 +13,9 @@ import (
 type WorkerManager struct {
 	notificationService *services.NotificationService
 	baselineService     *services.BaselineCalculatorService
+	digestService       *services.DigestService
+	hotScoreWorker      *HotScoreWorker
+	expireBansWorker    *ExpireBansWorker
 }
 
 // NewWorkerManager creates a new worker manager
@@ -25,6 +29,21 @@ func NewWorkerManager(
 	}
 }
 
+// SetDigestService enables the digest email worker (called before Start)
+func (wm *WorkerManager) SetDigestService(digestService *services.DigestService) {
+	wm.digestService = digestService
+}
+
+// SetHotScoreWorker enables periodic hot score recomputation (called before Start)
+func (wm *WorkerManager) SetHotScoreWorker(worker *HotScoreWorker) {
+	wm.hotScoreWorker = worker
+}
+
+// SetExpireBansWorker enables automatic lifting of expired temporary bans (called before Start)
+func (wm *WorkerManager) SetExpireBansWorker(worker *ExpireBansWorker) {
+	wm.expireBansWorker = worker
+}
+
 // Start starts all background workers
 func (wm *WorkerManager) Start(ctx context.Context) {
 	log.Println("Starting background workers...")
@@ -38,6 +57,21 @@ func (wm *WorkerManager) Start(ctx context.Context) {
 	// Start vote activity cleanup (daily at 4 AM)
 	go wm.runVoteActivityCleanup(ctx)
 
+	// Start digest email sender (hourly, so quiet hours are honored per hour)
+	if wm.digestService != nil {
+		go wm.runDigestSender(ctx)
+	}
+
+	// Start hot score recomputation (configurable interval)
+	if wm.hotScoreWorker != nil {
+		go wm.hotScoreWorker.Run(ctx)
+	}
+
+	// Start temporary ban expiry
+	if wm.expireBansWorker != nil {
+		go wm.expireBansWorker.Run(ctx)
+	}
+
 	log.Println("All background workers started")
 }
 
@@ -49,9 +83,7 @@ func (wm *WorkerManager) runNotificationBatchProcessor(ctx context.Context) {
 	log.Println("Notification batch processor started (15-minute interval)")
 
 	// Run immediately on startup
-	if err := wm.notificationService.ProcessBatchedNotifications(ctx); err != nil {
-		log.Printf("Error processing notification batches: %v", err)
-	}
+	wm.processNotificationBatches(ctx)
 
 	for {
 		select {
@@ -60,9 +92,26 @@ func (wm *WorkerManager) runNotificationBatchProcessor(ctx context.Context) {
 			return
 		case <-ticker.C:
 			log.Println("Processing notification batches...")
-			if err := wm.notificationService.ProcessBatchedNotifications(ctx); err != nil {
-				log.Printf("Error processing notification batches: %v", err)
-			}
+			wm.processNotificationBatches(ctx)
+		}
+	}
+}
+
+// processNotificationBatches delivers due batches and flushes any hourly or
+// daily digests whose window has closed
+func (wm *WorkerManager) processNotificationBatches(ctx context.Context) {
+	if err := wm.notificationService.ProcessBatchedNotifications(ctx); err != nil {
+		log.Printf("Error processing notification batches: %v", err)
+	}
+
+	for _, cadence := range []string{models.NotificationCadenceHourly, models.NotificationCadenceDaily} {
+		sent, err := wm.notificationService.FlushDigests(ctx, cadence)
+		if err != nil {
+			log.Printf("Error flushing %s notification digests: %v", cadence, err)
+			continue
+		}
+		if sent > 0 {
+			log.Printf("Sent %d %s notification digests", sent, cadence)
 		}
 	}
 }
@@ -124,3 +173,28 @@ func (wm *WorkerManager) runVoteActivityCleanup(ctx context.Context) {
 		}
 	}
 }
+
+// runDigestSender emails digests to inactive users every hour
+func (wm *WorkerManager) runDigestSender(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	log.Println("Digest sender started (hourly)")
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Println("Digest sender stopped")
+			return
+		case <-ticker.C:
+			sent, err := wm.digestService.SendDigests(ctx)
+			if err != nil {
+				log.Printf("Error sending digests: %v", err)
+				continue
+			}
+			if sent > 0 {
+				log.Printf("Sent %d digest emails", sent)
+			}
+		}
+	}
+}
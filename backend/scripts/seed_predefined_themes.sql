@@ -286,6 +286,18 @@ INSERT INTO user_themes (
     '1.0.0'
 );
 
+-- ============================================================================
+-- Light/dark pairs (clients switch between them based on OS preference)
+-- ============================================================================
+UPDATE user_themes AS t
+SET paired_theme_id = p.id
+FROM user_themes AS p
+WHERE t.user_id = 0 AND p.user_id = 0
+  AND (t.theme_name, p.theme_name) IN (
+      ('OmniNudge Light', 'OmniNudge Dark'),
+      ('OmniNudge Dark', 'OmniNudge Light')
+  );
+
 -- Verify the themes were created
 SELECT
     id,
@@ -294,6 +306,7 @@ SELECT
     theme_type,
     is_public,
     version,
+    paired_theme_id,
     created_at
 FROM user_themes
 WHERE user_id = 0
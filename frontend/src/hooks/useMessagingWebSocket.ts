@@ -133,6 +133,8 @@ export function useMessagingWebSocket(options: UseMessagingWebSocketOptions = {}
       };
 
       socket.onopen = () => {
+        // The server closes connections that don't say hello within a few seconds
+        socket.send(JSON.stringify({ type: 'hello' }));
         console.log('WebSocket connected');
       };
     };
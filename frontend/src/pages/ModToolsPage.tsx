@@ -564,6 +564,7 @@ function getActionDescription(log: ModLog): string {
     unlock_post: 'unlocked post',
     pin_post: 'pinned post',
     unpin_post: 'unpinned post',
+    set_pin_order: 'reordered pinned post',
     create_removal_reason: 'created removal reason',
     update_removal_reason: 'updated removal reason',
     delete_removal_reason: 'deleted removal reason',
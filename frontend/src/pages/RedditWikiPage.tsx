@@ -111,7 +111,8 @@ export default function RedditWikiPage({ mode = 'view' }: RedditWikiPageProps =
 
   const sidebarDescriptionHtml = useMemo(() => {
     if (!subredditAbout?.description_html) return null;
-    return sanitizeWikiHtml(subredditAbout.description_html);
+    // Sidebar HTML comes straight from Reddit, entity-escaped and unsanitized
+    return sanitizeWikiHtml(decodeHtmlEntities(subredditAbout.description_html));
   }, [subredditAbout?.description_html]);
 
   const subredditIcon = useMemo(() => {
@@ -901,6 +902,9 @@ function processWikiContent(content?: string | null): { processedHtml: string |
     return { processedHtml: null, tocItems: [] };
   }
 
+  // The server already sanitizes content_html and returns it unescaped, so it
+  // isn't decoded again (that would turn escaped text back into markup). It is
+  // still run through the client allowlist in case any path serves it raw.
   const sanitized = sanitizeWikiHtml(content);
 
   if (typeof document === 'undefined') {
@@ -959,11 +963,8 @@ function slugifyHeading(value: string): string {
 function sanitizeWikiHtml(content: string): string {
   if (typeof document === 'undefined') return content;
 
-  // Decode HTML entities first
-  const decoded = decodeHtmlEntities(content);
-
   // Remove HTML comments
-  const cleaned = decoded.replace(/<!--[\s\S]*?-->/g, '');
+  const cleaned = content.replace(/<!--[\s\S]*?-->/g, '');
 
   const template = document.createElement('template');
   template.innerHTML = cleaned;